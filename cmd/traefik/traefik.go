@@ -24,11 +24,13 @@ import (
 	"github.com/traefik/traefik/v3/cmd"
 	"github.com/traefik/traefik/v3/cmd/healthcheck"
 	cmdVersion "github.com/traefik/traefik/v3/cmd/version"
+	"github.com/traefik/traefik/v3/pkg/api"
 	tcli "github.com/traefik/traefik/v3/pkg/cli"
 	"github.com/traefik/traefik/v3/pkg/collector"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/diagnostics"
 	"github.com/traefik/traefik/v3/pkg/logs"
 	"github.com/traefik/traefik/v3/pkg/metrics"
 	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
@@ -46,6 +48,7 @@ import (
 	"github.com/traefik/traefik/v3/pkg/tracing"
 	"github.com/traefik/traefik/v3/pkg/tracing/jaeger"
 	"github.com/traefik/traefik/v3/pkg/types"
+	"github.com/traefik/traefik/v3/pkg/upgrade"
 	"github.com/traefik/traefik/v3/pkg/version"
 )
 
@@ -62,7 +65,12 @@ Complete documentation is available at https://traefik.io`,
 		Configuration: tConfig,
 		Resources:     loaders,
 		Run: func(_ []string) error {
-			return runCmd(&tConfig.Configuration)
+			if err := runCmd(&tConfig.Configuration); err != nil {
+				return err
+			}
+
+			// the process started first waits for the processes it handed its listeners off to.
+			return upgrade.Supervise()
 		},
 	}
 
@@ -114,6 +122,10 @@ func runCmd(staticConfiguration *static.Configuration) error {
 
 	stats(staticConfiguration)
 
+	if err := upgrade.Inherit(); err != nil {
+		return err
+	}
+
 	svr, err := setupServer(staticConfiguration)
 	if err != nil {
 		return err
@@ -198,6 +210,10 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	metricRegistries := registerMetricClients(staticConfiguration.Metrics)
 	metricsRegistry := metrics.NewMultiRegistry(metricRegistries)
 
+	for _, p := range providerAggregator.NomadProviders() {
+		p.SetMetricsRegistry(metricsRegistry)
+	}
+
 	// Entrypoints
 
 	serverEntryPointsTCP, err := server.NewTCPEntryPoints(staticConfiguration.EntryPoints, staticConfiguration.HostResolver, metricsRegistry)
@@ -210,6 +226,19 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		return nil, err
 	}
 
+	var dynamicEntryPointsTCP *server.DynamicTCPEntryPoints
+	var dynamicEntryPointsUDP *server.DynamicUDPEntryPoints
+	if staticConfiguration.DynamicEntryPoints != nil {
+		// the TCP and UDP entrypoints share their names.
+		dynamicEntryPoints, err := server.NewDynamicEntryPoints(staticConfiguration.DynamicEntryPoints, staticConfiguration.EntryPoints)
+		if err != nil {
+			return nil, err
+		}
+
+		dynamicEntryPointsTCP = server.NewDynamicTCPEntryPoints(dynamicEntryPoints, staticConfiguration.HostResolver, metricsRegistry)
+		dynamicEntryPointsUDP = server.NewDynamicUDPEntryPoints(dynamicEntryPoints)
+	}
+
 	// Plugins
 
 	pluginBuilder, err := createPluginBuilder(staticConfiguration)
@@ -251,28 +280,58 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	// Service manager factory
 
 	var spiffeX509Source *workloadapi.X509Source
-	if staticConfiguration.Spiffe != nil && staticConfiguration.Spiffe.WorkloadAPIAddr != "" {
-		log.Info().Str("workloadAPIAddr", staticConfiguration.Spiffe.WorkloadAPIAddr).
-			Msg("Waiting on SPIFFE SVID delivery")
-
-		spiffeX509Source, err = workloadapi.NewX509Source(
-			ctx,
-			workloadapi.WithClientOptions(
-				workloadapi.WithAddr(
-					staticConfiguration.Spiffe.WorkloadAPIAddr,
+	if staticConfiguration.Spiffe != nil {
+		workloadAPIAddr := staticConfiguration.Spiffe.WorkloadAPIAddr
+		if workloadAPIAddr == "" {
+			// the address of the socket exposed to the workloads, e.g. by the SPIRE agent.
+			workloadAPIAddr = os.Getenv("SPIFFE_ENDPOINT_SOCKET")
+		}
+
+		if workloadAPIAddr == "" {
+			log.Error().Msg("SPIFFE is enabled, but neither the workloadAPIAddr nor the SPIFFE_ENDPOINT_SOCKET environment variable is set, the SPIFFE SVID source is not created")
+		} else {
+			log.Info().Str("workloadAPIAddr", workloadAPIAddr).
+				Msg("Waiting on SPIFFE SVID delivery")
+
+			spiffeX509Source, err = workloadapi.NewX509Source(
+				ctx,
+				workloadapi.WithClientOptions(
+					workloadapi.WithAddr(workloadAPIAddr),
 				),
-			),
-		)
-		if err != nil {
-			return nil, fmt.Errorf("unable to create SPIFFE x509 source: %w", err)
+			)
+			if err != nil {
+				return nil, fmt.Errorf("unable to create SPIFFE x509 source: %w", err)
+			}
+			log.Info().Msg("Successfully obtained SPIFFE SVID.")
 		}
-		log.Info().Msg("Successfully obtained SPIFFE SVID.")
 	}
 
 	roundTripperManager := service.NewRoundTripperManager(spiffeX509Source)
 	dialerManager := tcp.NewDialerManager(spiffeX509Source)
 	acmeHTTPHandler := getHTTPChallengeHandler(acmeProviders, httpChallengeProvider)
-	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, metricsRegistry, roundTripperManager, acmeHTTPHandler)
+	certResolvers := make(map[string]api.CertificateResolver, len(acmeProviders))
+	for _, p := range acmeProviders {
+		certResolvers[p.ResolverName] = p
+	}
+
+	var nomadProviders []api.NomadProvider
+	for _, p := range providerAggregator.NomadProviders() {
+		nomadProviders = append(nomadProviders, p)
+	}
+
+	diagnosticsResolvers := make(map[string]diagnostics.CertificateResolver, len(acmeProviders))
+	for _, p := range acmeProviders {
+		diagnosticsResolvers[p.ResolverName] = p
+	}
+
+	var diagnosticsNomadProviders []diagnostics.NomadProvider
+	for _, p := range providerAggregator.NomadProviders() {
+		diagnosticsNomadProviders = append(diagnosticsNomadProviders, p)
+	}
+
+	diagnosticsCollector := diagnostics.NewCollector(diagnosticsResolvers, diagnosticsNomadProviders)
+
+	managerFactory := service.NewManagerFactory(*staticConfiguration, routinesPool, metricsRegistry, roundTripperManager, acmeHTTPHandler, certResolvers, nomadProviders, diagnosticsCollector)
 
 	// Router factory
 
@@ -289,6 +348,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		providerAggregator,
 		getDefaultsEntrypoints(staticConfiguration),
 		"internal",
+		staticConfiguration.Providers.Precedence,
 	)
 
 	// TLS
@@ -297,8 +357,10 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		tlsManager.UpdateConfigs(ctx, conf.TLS.Stores, conf.TLS.Options, conf.TLS.Certificates)
 
 		gauge := metricsRegistry.TLSCertsNotAfterTimestampGauge()
+		sctGauge := metricsRegistry.TLSCertsMissingSCTGauge()
 		for _, certificate := range tlsManager.GetServerCertificates() {
 			appendCertMetric(gauge, certificate)
+			appendSCTMetric(sctGauge, certificate)
 		}
 	})
 
@@ -315,7 +377,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	})
 
 	// Switch router
-	watcher.AddListener(switchRouter(routerFactory, serverEntryPointsTCP, serverEntryPointsUDP))
+	watcher.AddListener(switchRouter(routerFactory, serverEntryPointsTCP, serverEntryPointsUDP, dynamicEntryPointsTCP, dynamicEntryPointsUDP))
 
 	// Metrics
 	if metricsRegistry.IsEpEnabled() || metricsRegistry.IsSvcEnabled() {
@@ -331,6 +393,9 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 	// TLS challenge
 	watcher.AddListener(tlsChallengeProvider.ListenConfiguration)
 
+	// Diagnostics
+	watcher.AddListener(diagnosticsCollector.ListenConfiguration)
+
 	// Certificate Resolvers
 
 	resolverNames := map[string]struct{}{}
@@ -364,7 +429,7 @@ func setupServer(staticConfiguration *static.Configuration) (*server.Server, err
 		}
 	})
 
-	return server.NewServer(routinesPool, serverEntryPointsTCP, serverEntryPointsUDP, watcher, chainBuilder, accessLog), nil
+	return server.NewServer(routinesPool, serverEntryPointsTCP, serverEntryPointsUDP, dynamicEntryPointsTCP, dynamicEntryPointsUDP, watcher, chainBuilder, accessLog, diagnosticsCollector, staticConfiguration.Upgrade), nil
 }
 
 func getHTTPChallengeHandler(acmeProviders []*acme.Provider, httpChallengeProvider http.Handler) http.Handler {
@@ -417,20 +482,46 @@ func getDefaultsEntrypoints(staticConfiguration *static.Configuration) []string
 	return defaultEntryPoints
 }
 
-func switchRouter(routerFactory *server.RouterFactory, serverEntryPointsTCP server.TCPEntryPoints, serverEntryPointsUDP server.UDPEntryPoints) func(conf dynamic.Configuration) {
+func switchRouter(routerFactory *server.RouterFactory, serverEntryPointsTCP server.TCPEntryPoints, serverEntryPointsUDP server.UDPEntryPoints,
+	dynamicEntryPointsTCP *server.DynamicTCPEntryPoints, dynamicEntryPointsUDP *server.DynamicUDPEntryPoints,
+) func(conf dynamic.Configuration) {
 	return func(conf dynamic.Configuration) {
+		if dynamicEntryPointsTCP != nil {
+			var entryPoints map[string]*dynamic.TCPEntryPoint
+			if conf.TCP != nil {
+				entryPoints = conf.TCP.EntryPoints
+			}
+
+			routerFactory.SetDynamicEntryPoints(dynamicEntryPointsTCP.Update(entryPoints))
+		}
+
+		if dynamicEntryPointsUDP != nil {
+			var entryPoints map[string]*dynamic.UDPEntryPoint
+			if conf.UDP != nil {
+				entryPoints = conf.UDP.EntryPoints
+			}
+
+			routerFactory.SetDynamicUDPEntryPoints(dynamicEntryPointsUDP.Update(entryPoints))
+		}
+
 		rtConf := runtime.NewConfig(conf)
 
 		routers, udpRouters := routerFactory.CreateRouters(rtConf)
 
 		serverEntryPointsTCP.Switch(routers)
 		serverEntryPointsUDP.Switch(udpRouters)
+		if dynamicEntryPointsTCP != nil {
+			dynamicEntryPointsTCP.Switch(routers)
+		}
+		if dynamicEntryPointsUDP != nil {
+			dynamicEntryPointsUDP.Switch(udpRouters)
+		}
 	}
 }
 
 // initACMEProvider creates and registers acme.Provider instances corresponding to the configured ACME certificate resolvers.
 func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.ProviderAggregator, tlsManager *traefiktls.Manager, httpChallengeProvider, tlsChallengeProvider challenge.Provider) []*acme.Provider {
-	localStores := map[string]*acme.LocalStore{}
+	stores := map[string]acme.Store{}
 
 	var resolvers []*acme.Provider
 	for name, resolver := range c.CertificatesResolvers {
@@ -438,13 +529,23 @@ func initACMEProvider(c *static.Configuration, providerAggregator *aggregator.Pr
 			continue
 		}
 
-		if localStores[resolver.ACME.Storage] == nil {
-			localStores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage)
+		if stores[resolver.ACME.Storage] == nil {
+			if strings.HasPrefix(resolver.ACME.Storage, acme.NomadStorageScheme) {
+				store, err := acme.NewNomadStore(resolver.ACME.Storage)
+				if err != nil {
+					log.Error().Err(err).Str("resolver", name).Msg("The ACME resolve is skipped from the resolvers list")
+					continue
+				}
+
+				stores[resolver.ACME.Storage] = store
+			} else {
+				stores[resolver.ACME.Storage] = acme.NewLocalStore(resolver.ACME.Storage)
+			}
 		}
 
 		p := &acme.Provider{
 			Configuration:         resolver.ACME,
-			Store:                 localStores[resolver.ACME.Storage],
+			Store:                 stores[resolver.ACME.Storage],
 			ResolverName:          name,
 			HTTPChallengeProvider: httpChallengeProvider,
 			TLSChallengeProvider:  tlsChallengeProvider,
@@ -568,6 +669,22 @@ func appendCertMetric(gauge gokitmetrics.Gauge, certificate *x509.Certificate) {
 	gauge.With(labels...).Set(notAfter)
 }
 
+func appendSCTMetric(gauge gokitmetrics.Gauge, certificate *x509.Certificate) {
+	labels := []string{
+		"cn", certificate.Subject.CommonName,
+		"serial", certificate.SerialNumber.String(),
+		"sans", strings.Join(certificate.DNSNames, ","),
+	}
+
+	var missing float64
+	if err := traefiktls.CheckSCTs(certificate, time.Now()); err != nil {
+		log.Debug().Err(err).Str("cn", certificate.Subject.CommonName).Msg("Certificate is missing valid Signed Certificate Timestamps")
+		missing = 1
+	}
+
+	gauge.With(labels...).Set(missing)
+}
+
 func setupAccessLog(conf *types.AccessLog) *accesslog.Handler {
 	if conf == nil {
 		return nil
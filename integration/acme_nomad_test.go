@@ -0,0 +1,308 @@
+package integration
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-check/check"
+	"github.com/hashicorp/nomad/api"
+	"github.com/miekg/dns"
+	"github.com/traefik/traefik/v3/integration/try"
+	"github.com/traefik/traefik/v3/pkg/testhelpers"
+	checker "github.com/vdemeester/shakers"
+)
+
+// AcmeNomadSuite runs replicas of Traefik sharing their ACME data in the Nomad Variables of a Nomad dev agent.
+type AcmeNomadSuite struct {
+	BaseSuite
+	pebbleIP      string
+	nomadClient   *api.Client
+	nomadURL      string
+	fakeDNSServer *dns.Server
+}
+
+// acmeNomadReplica is a Traefik replica of the AcmeNomadSuite.
+type acmeNomadReplica struct {
+	cmd       *exec.Cmd
+	output    *bytes.Buffer
+	file      string
+	portHTTP  string
+	portHTTPS string
+}
+
+func (s *AcmeNomadSuite) SetUpSuite(c *check.C) {
+	s.createComposeProject(c, "acme_nomad")
+	s.composeUp(c)
+
+	s.fakeDNSServer = startFakeDNSServer(s.getContainerIP(c, "traefik"))
+	s.pebbleIP = s.getComposeServiceIP(c, "pebble")
+	s.nomadURL = "http://" + net.JoinHostPort(s.getComposeServiceIP(c, "nomad"), "4646")
+
+	pebbleTransport, err := setupPebbleRootCA()
+	c.Assert(err, checker.IsNil)
+
+	// wait for pebble
+	req := testhelpers.MustNewRequest(http.MethodGet, s.getAcmeURL(), nil)
+
+	client := &http.Client{
+		Transport: pebbleTransport,
+	}
+
+	err = try.Do(5*time.Second, func() error {
+		resp, errGet := client.Do(req)
+		if errGet != nil {
+			return errGet
+		}
+		return try.StatusCodeIs(http.StatusOK)(resp)
+	})
+	c.Assert(err, checker.IsNil)
+
+	s.nomadClient, err = api.NewClient(&api.Config{Address: s.nomadURL})
+	c.Assert(err, checker.IsNil)
+
+	// wait for the dev agent to elect itself leader.
+	err = try.Do(30*time.Second, func() error {
+		leader, err := s.nomadClient.Status().Leader()
+		if err != nil || leader == "" {
+			return fmt.Errorf("leader not found: %w", err)
+		}
+		return nil
+	})
+	c.Assert(err, checker.IsNil)
+}
+
+func (s *AcmeNomadSuite) TearDownSuite(c *check.C) {
+	if s.fakeDNSServer != nil {
+		err := s.fakeDNSServer.Shutdown()
+		if err != nil {
+			c.Log(err)
+		}
+	}
+
+	s.composeDown(c)
+}
+
+func (s *AcmeNomadSuite) getAcmeURL() string {
+	return fmt.Sprintf("https://%s/dir", net.JoinHostPort(s.pebbleIP, "14000"))
+}
+
+// TestLeaderFailover kills the replica holding the lease of the resolver in the middle of its order,
+// and checks that the other replica takes over the lease and obtains the certificate, ordering it once.
+func (s *AcmeNomadSuite) TestLeaderFailover(c *check.C) {
+	backend := startTestServer("9010", http.StatusOK, "")
+	defer backend.Close()
+
+	replicas := []*acmeNomadReplica{
+		s.startReplica(c, ":5102", ":5101"),
+		s.startReplica(c, ":5202", ":5201"),
+	}
+	for _, replica := range replicas {
+		defer func(replica *acmeNomadReplica) {
+			s.killCmd(replica.cmd)
+			_ = replica.cmd.Wait()
+			_ = os.Remove(replica.file)
+			if c.Failed() || *showLog {
+				s.displayTraefikLog(c, replica.output)
+			}
+		}(replica)
+	}
+
+	// the HTTP challenges sent by pebble to the port 5002 are routed to the replicas as by a load balancer,
+	// and the first one is held until the replica which ordered it is killed.
+	balancer := &challengeBalancer{
+		challenged: make(chan struct{}),
+		killed:     make(chan struct{}),
+	}
+	for _, replica := range replicas {
+		balancer.backends = append(balancer.backends, "http://127.0.0.1"+replica.portHTTP)
+	}
+
+	listener, err := net.Listen("tcp", ":5002")
+	c.Assert(err, checker.IsNil)
+
+	server := &http.Server{Handler: balancer}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	var leader *acmeNomadReplica
+	err = try.Do(60*time.Second, func() error {
+		holder, err := s.leaseHolder()
+		if err != nil {
+			return err
+		}
+
+		for _, replica := range replicas {
+			if strings.HasSuffix(holder, "-"+strconv.Itoa(replica.cmd.Process.Pid)) {
+				leader = replica
+				return nil
+			}
+		}
+
+		return fmt.Errorf("lease held by the unknown holder %q", holder)
+	})
+	c.Assert(err, checker.IsNil)
+
+	select {
+	case <-balancer.challenged:
+	case <-time.After(60 * time.Second):
+		c.Fatal("no challenge received")
+	}
+
+	s.killCmd(leader.cmd)
+	close(balancer.killed)
+
+	survivor := replicas[0]
+	if survivor == leader {
+		survivor = replicas[1]
+	}
+
+	// the survivor takes over the lease once expired.
+	err = try.Do(90*time.Second, func() error {
+		holder, err := s.leaseHolder()
+		if err != nil {
+			return err
+		}
+
+		if !strings.HasSuffix(holder, "-"+strconv.Itoa(survivor.cmd.Process.Pid)) {
+			return fmt.Errorf("lease still held by %q", holder)
+		}
+		return nil
+	})
+	c.Assert(err, checker.IsNil)
+
+	// the survivor obtains the certificate, saves it, and serves it.
+	err = try.Do(90*time.Second, func() error {
+		var variables []struct{ Path string }
+		if _, err := s.nomadClient.Raw().Query("/v1/vars?prefix=traefik/acme/default/certificates/", &variables, nil); err != nil {
+			return err
+		}
+		if len(variables) != 1 {
+			return fmt.Errorf("%d certificates saved", len(variables))
+		}
+		return nil
+	})
+	c.Assert(err, checker.IsNil)
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+				ServerName:         acmeDomain,
+			},
+			DisableKeepAlives: true,
+		},
+	}
+
+	req := testhelpers.MustNewRequest(http.MethodGet, "https://127.0.0.1"+survivor.portHTTPS+"/", nil)
+	req.Host = acmeDomain
+
+	err = try.Do(30*time.Second, func() error {
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if cn := resp.TLS.PeerCertificates[0].Subject.CommonName; cn != acmeDomain {
+			return fmt.Errorf("domain %s found instead of %s", cn, acmeDomain)
+		}
+		return nil
+	})
+	c.Assert(err, checker.IsNil)
+
+	s.killCmd(survivor.cmd)
+	_ = survivor.cmd.Wait()
+
+	// the survivor ordered the certificate once, after acquiring the lease.
+	output := survivor.output.String()
+	c.Assert(strings.Count(output, "Loading ACME certificates [traefik.acme.wtf]"), checker.Equals, 1)
+	c.Assert(strings.Index(output, "Acquired the lease of the resolver") < strings.Index(output, "Loading ACME certificates"), checker.True)
+}
+
+// startReplica starts a Traefik replica, with its web and websecure entry points on the ports.
+func (s *AcmeNomadSuite) startReplica(c *check.C, portHTTP, portHTTPS string) *acmeNomadReplica {
+	file := s.adaptFile(c, "fixtures/acme/acme_nomad.toml", struct {
+		PortHTTP  string
+		PortHTTPS string
+		CAServer  string
+		Domain    string
+	}{
+		PortHTTP:  portHTTP,
+		PortHTTPS: portHTTPS,
+		CAServer:  s.getAcmeURL(),
+		Domain:    acmeDomain,
+	})
+
+	cmd, output := s.cmdTraefik(withConfigFile(file))
+	cmd.Env = append(os.Environ(), "NOMAD_ADDR="+s.nomadURL)
+
+	err := cmd.Start()
+	c.Assert(err, checker.IsNil)
+
+	return &acmeNomadReplica{cmd: cmd, output: output, file: file, portHTTP: portHTTP, portHTTPS: portHTTPS}
+}
+
+// leaseHolder returns the holder of the lease of the resolver.
+func (s *AcmeNomadSuite) leaseHolder() (string, error) {
+	var variable struct{ Items map[string]string }
+	if _, err := s.nomadClient.Raw().Query("/v1/var/traefik/acme/default/lease", &variable, nil); err != nil {
+		return "", err
+	}
+
+	if variable.Items["holder"] == "" {
+		return "", errors.New("lease not held")
+	}
+
+	return variable.Items["holder"], nil
+}
+
+// challengeBalancer routes the HTTP challenges to the first replica answering them.
+// It holds the first challenge until killed is closed.
+type challengeBalancer struct {
+	backends   []string
+	challenged chan struct{}
+	killed     chan struct{}
+	once       sync.Once
+}
+
+func (b *challengeBalancer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	b.once.Do(func() {
+		close(b.challenged)
+		<-b.killed
+	})
+
+	// the replicas not knowing the challenge look it up for a while before responding with a 404.
+	client := &http.Client{Timeout: 5 * time.Second}
+
+	for _, backend := range b.backends {
+		backendReq := testhelpers.MustNewRequest(http.MethodGet, backend+req.URL.Path, nil)
+		backendReq.Host = req.Host
+
+		resp, err := client.Do(backendReq)
+		if err != nil {
+			continue
+		}
+
+		body, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil || resp.StatusCode != http.StatusOK {
+			continue
+		}
+
+		_, _ = rw.Write(body)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNotFound)
+}
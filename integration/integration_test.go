@@ -72,6 +72,7 @@ func Test(t *testing.T) {
 	check.Suite(&AccessLogSuite{})
 	if !useVPN {
 		check.Suite(&AcmeSuite{})
+		check.Suite(&AcmeNomadSuite{})
 	}
 	check.Suite(&ConsulCatalogSuite{})
 	check.Suite(&ConsulSuite{})
@@ -91,6 +92,7 @@ func Test(t *testing.T) {
 	}
 	check.Suite(&KeepAliveSuite{})
 	check.Suite(&LogRotationSuite{})
+	check.Suite(&NomadSuite{})
 	if !useVPN {
 		check.Suite(&ProxyProtocolSuite{})
 	}
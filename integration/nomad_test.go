@@ -0,0 +1,225 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-check/check"
+	"github.com/hashicorp/nomad/api"
+	"github.com/traefik/traefik/v3/integration/try"
+	checker "github.com/vdemeester/shakers"
+)
+
+// NomadSuite runs the services in a Nomad dev agent, the allocations registering
+// the Nomad services which point to the containers of the compose project.
+type NomadSuite struct {
+	BaseSuite
+	nomadClient *api.Client
+	nomadURL    string
+}
+
+func (s *NomadSuite) SetUpSuite(c *check.C) {
+	s.createComposeProject(c, "nomad")
+	s.composeUp(c)
+
+	s.nomadURL = "http://" + net.JoinHostPort(s.getComposeServiceIP(c, "nomad"), "4646")
+
+	var err error
+	s.nomadClient, err = api.NewClient(&api.Config{Address: s.nomadURL})
+	c.Assert(err, checker.IsNil)
+
+	// Wait for the dev agent to elect itself leader, and for its client to be ready.
+	err = try.Do(30*time.Second, func() error {
+		leader, err := s.nomadClient.Status().Leader()
+		if err != nil || leader == "" {
+			return fmt.Errorf("leader not found: %w", err)
+		}
+
+		nodes, _, err := s.nomadClient.Nodes().List(nil)
+		if err != nil {
+			return err
+		}
+
+		for _, node := range nodes {
+			if node.Status == "ready" {
+				return nil
+			}
+		}
+
+		return errors.New("no ready Nomad client")
+	})
+	c.Assert(err, checker.IsNil)
+}
+
+func (s *NomadSuite) TearDownTest(c *check.C) {
+	// the job may already have been deregistered by the test.
+	_, _, _ = s.nomadClient.Jobs().Deregister("udp", true, nil)
+
+	// the service registrations are removed once the allocations are stopped.
+	err := try.Do(30*time.Second, func() error {
+		services, _, err := s.nomadClient.Services().Get("whoamiudp", nil)
+		if err != nil {
+			return err
+		}
+		if len(services) > 0 {
+			return fmt.Errorf("%d services still registered", len(services))
+		}
+		return nil
+	})
+	c.Assert(err, checker.IsNil)
+}
+
+// registerUDPJob registers a job with a group per instance of the whoamiudp service,
+// each instance pointing to the address of one of the whoamiudp containers.
+func (s *NomadSuite) registerUDPJob(c *check.C, addresses ...string) {
+	job := api.NewServiceJob("udp", "udp", "global", 50)
+	job.Datacenters = []string{"dc1"}
+
+	for i, address := range addresses {
+		task := api.NewTask("sleep", "raw_exec").
+			SetConfig("command", "/bin/sh").
+			SetConfig("args", []string{"-c", "sleep 3600"})
+
+		group := api.NewTaskGroup(fmt.Sprintf("instance%d", i+1), 1).AddTask(task)
+		group.Services = []*api.Service{{
+			Name:     "whoamiudp",
+			Provider: "nomad",
+			Address:  address,
+			Tags: []string{
+				"traefik.enable=true",
+				"traefik.udp.routers.whoamiudp.entrypoints=udp",
+				"traefik.udp.routers.whoamiudp.service=whoamiudp",
+				"traefik.udp.services.whoamiudp.loadbalancer.server.port=8080",
+			},
+		}}
+
+		job.AddTaskGroup(group)
+	}
+
+	_, _, err := s.nomadClient.Jobs().Register(job, nil)
+	c.Assert(err, checker.IsNil)
+
+	err = try.Do(30*time.Second, func() error {
+		services, _, err := s.nomadClient.Services().Get("whoamiudp", nil)
+		if err != nil {
+			return err
+		}
+		if len(services) != len(addresses) {
+			return fmt.Errorf("%d services registered, expected %d", len(services), len(addresses))
+		}
+		return nil
+	})
+	c.Assert(err, checker.IsNil)
+}
+
+func (s *NomadSuite) startTraefik(c *check.C) func() {
+	file := s.adaptFile(c, "fixtures/nomad/udp.toml", struct {
+		NomadAddress string
+	}{
+		NomadAddress: s.nomadURL,
+	})
+
+	cmd, display := s.traefikCmd(withConfigFile(file))
+
+	err := cmd.Start()
+	c.Assert(err, checker.IsNil)
+
+	return func() {
+		s.killCmd(cmd)
+		display(c)
+		_ = os.Remove(file)
+	}
+}
+
+// countWhoUDP sends n requests to the UDP entrypoint, and counts the responses of each whoamiudp container.
+func countWhoUDP(c *check.C, n int) map[string]int {
+	calls := make(map[string]int)
+	for i := 0; i < n; i++ {
+		out, err := guessWhoUDP("127.0.0.1:8093")
+		c.Assert(err, checker.IsNil)
+
+		switch {
+		case strings.Contains(out, "whoamiudp1"):
+			calls["whoamiudp1"]++
+		case strings.Contains(out, "whoamiudp2"):
+			calls["whoamiudp2"]++
+		default:
+			calls["unknown"]++
+		}
+	}
+
+	return calls
+}
+
+func (s *NomadSuite) TestUDP(c *check.C) {
+	whoami1IP := s.getComposeServiceIP(c, "whoamiudp1")
+
+	s.registerUDPJob(c, whoami1IP)
+
+	stop := s.startTraefik(c)
+	defer stop()
+
+	err := try.GetRequest("http://127.0.0.1:8080/api/udp/services/whoamiudp@nomad", 10*time.Second,
+		try.StatusCodeIs(http.StatusOK),
+		try.BodyContains(net.JoinHostPort(whoami1IP, "8080")))
+	c.Assert(err, checker.IsNil)
+
+	c.Assert(countWhoUDP(c, 2), checker.DeepEquals, map[string]int{"whoamiudp1": 2})
+}
+
+func (s *NomadSuite) TestUDPLoadBalancing(c *check.C) {
+	whoami1IP := s.getComposeServiceIP(c, "whoamiudp1")
+	whoami2IP := s.getComposeServiceIP(c, "whoamiudp2")
+
+	s.registerUDPJob(c, whoami1IP, whoami2IP)
+
+	stop := s.startTraefik(c)
+	defer stop()
+
+	err := try.GetRequest("http://127.0.0.1:8080/api/udp/services/whoamiudp@nomad", 10*time.Second,
+		try.StatusCodeIs(http.StatusOK),
+		try.BodyContains(net.JoinHostPort(whoami1IP, "8080"), net.JoinHostPort(whoami2IP, "8080")))
+	c.Assert(err, checker.IsNil)
+
+	// each request comes from a new client address, and is therefore a new UDP session.
+	c.Assert(countWhoUDP(c, 4), checker.DeepEquals, map[string]int{"whoamiudp1": 2, "whoamiudp2": 2})
+}
+
+func (s *NomadSuite) TestUDPInstanceRemoval(c *check.C) {
+	whoami1IP := s.getComposeServiceIP(c, "whoamiudp1")
+	whoami2IP := s.getComposeServiceIP(c, "whoamiudp2")
+
+	s.registerUDPJob(c, whoami1IP, whoami2IP)
+
+	stop := s.startTraefik(c)
+	defer stop()
+
+	err := try.GetRequest("http://127.0.0.1:8080/api/udp/services/whoamiudp@nomad", 10*time.Second,
+		try.StatusCodeIs(http.StatusOK),
+		try.BodyContains(net.JoinHostPort(whoami1IP, "8080"), net.JoinHostPort(whoami2IP, "8080")))
+	c.Assert(err, checker.IsNil)
+
+	// the second instance is removed from the job, its allocation is stopped and its service deregistered.
+	s.registerUDPJob(c, whoami1IP)
+
+	err = try.GetRequest("http://127.0.0.1:8080/api/udp/services/whoamiudp@nomad", 10*time.Second,
+		try.StatusCodeIs(http.StatusOK),
+		try.BodyContains(net.JoinHostPort(whoami1IP, "8080")),
+		try.BodyNotContains(net.JoinHostPort(whoami2IP, "8080")))
+	c.Assert(err, checker.IsNil)
+
+	c.Assert(countWhoUDP(c, 4), checker.DeepEquals, map[string]int{"whoamiudp1": 4})
+
+	// the service is removed with its last instance.
+	_, _, err = s.nomadClient.Jobs().Deregister("udp", true, nil)
+	c.Assert(err, checker.IsNil)
+
+	err = try.GetRequest("http://127.0.0.1:8080/api/udp/services/whoamiudp@nomad", 30*time.Second,
+		try.StatusCodeIs(http.StatusNotFound))
+	c.Assert(err, checker.IsNil)
+}
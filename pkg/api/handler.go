@@ -51,12 +51,26 @@ type Handler struct {
 
 	// runtimeConfiguration is the data set used to create all the data representations exposed by the API.
 	runtimeConfiguration *runtime.Configuration
+
+	// certResolvers are the certificate resolvers which can be paused and resumed through the API, indexed by name.
+	certResolvers map[string]CertificateResolver
+
+	// nomadProviders are the Nomad providers whose discovered services and configuration errors are exposed by the API.
+	nomadProviders []NomadProvider
+
+	// diagnostics serves the diagnostic bundle, when the debug endpoints are enabled.
+	diagnostics http.Handler
 }
 
 // NewBuilder returns a http.Handler builder based on runtime.Configuration.
-func NewBuilder(staticConfig static.Configuration) func(*runtime.Configuration) http.Handler {
+func NewBuilder(staticConfig static.Configuration, certResolvers map[string]CertificateResolver, nomadProviders []NomadProvider, diagnostics http.Handler) func(*runtime.Configuration) http.Handler {
 	return func(configuration *runtime.Configuration) http.Handler {
-		return New(staticConfig, configuration).createRouter()
+		handler := New(staticConfig, configuration)
+		handler.certResolvers = certResolvers
+		handler.nomadProviders = nomadProviders
+		handler.diagnostics = diagnostics
+
+		return handler.createRouter()
 	}
 }
 
@@ -80,6 +94,10 @@ func (h Handler) createRouter() *mux.Router {
 
 	if h.staticConfig.API.Debug {
 		DebugHandler{}.Append(router)
+
+		if h.diagnostics != nil {
+			router.Methods(http.MethodGet).Path("/api/diagnostics").Handler(h.diagnostics)
+		}
 	}
 
 	router.Methods(http.MethodGet).Path("/api/rawdata").HandlerFunc(h.getRuntimeConfiguration)
@@ -96,6 +114,17 @@ func (h Handler) createRouter() *mux.Router {
 	router.Methods(http.MethodGet).Path("/api/http/services/{serviceID}").HandlerFunc(h.getService)
 	router.Methods(http.MethodGet).Path("/api/http/middlewares").HandlerFunc(h.getMiddlewares)
 	router.Methods(http.MethodGet).Path("/api/http/middlewares/{middlewareID}").HandlerFunc(h.getMiddleware)
+	router.Methods(http.MethodPost).Path("/api/http/preview").HandlerFunc(h.previewRouting)
+
+	if h.staticConfig.API.PurgeCaches {
+		router.Methods(http.MethodDelete).Path("/api/http/middlewares/{middlewareID}/cache").HandlerFunc(h.purgeCache)
+	}
+
+	if h.staticConfig.API.ManageMaintenance {
+		router.Methods(http.MethodGet).Path("/api/http/middlewares/{middlewareID}/maintenance").HandlerFunc(h.getMaintenance)
+		router.Methods(http.MethodPut).Path("/api/http/middlewares/{middlewareID}/maintenance").HandlerFunc(h.setMaintenance)
+		router.Methods(http.MethodDelete).Path("/api/http/middlewares/{middlewareID}/maintenance").HandlerFunc(h.resetMaintenance)
+	}
 
 	router.Methods(http.MethodGet).Path("/api/tcp/routers").HandlerFunc(h.getTCPRouters)
 	router.Methods(http.MethodGet).Path("/api/tcp/routers/{routerID}").HandlerFunc(h.getTCPRouter)
@@ -109,6 +138,19 @@ func (h Handler) createRouter() *mux.Router {
 	router.Methods(http.MethodGet).Path("/api/udp/services").HandlerFunc(h.getUDPServices)
 	router.Methods(http.MethodGet).Path("/api/udp/services/{serviceID}").HandlerFunc(h.getUDPService)
 
+	router.Methods(http.MethodGet).Path("/api/certresolvers").HandlerFunc(h.getCertResolvers)
+	router.Methods(http.MethodGet).Path("/api/certresolvers/{resolverID}").HandlerFunc(h.getCertResolver)
+
+	if h.staticConfig.API.ManageCertResolvers {
+		router.Methods(http.MethodPut).Path("/api/certresolvers/{resolverID}/pause").HandlerFunc(h.pauseCertResolver)
+		router.Methods(http.MethodPut).Path("/api/certresolvers/{resolverID}/resume").HandlerFunc(h.resumeCertResolver)
+		router.Methods(http.MethodDelete).Path("/api/certresolvers/{resolverID}/certificates/{domain}").HandlerFunc(h.removeCertificate)
+	}
+
+	router.Methods(http.MethodGet).Path("/api/nomad/errors").HandlerFunc(h.getNomadErrors)
+	router.Methods(http.MethodGet).Path("/api/nomad/lint").HandlerFunc(h.getNomadLints)
+	router.Methods(http.MethodGet).Path("/api/nomad/services").HandlerFunc(h.getNomadServices)
+
 	version.Handler{}.Append(router)
 
 	return router
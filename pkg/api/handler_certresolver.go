@@ -0,0 +1,174 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/provider/acme"
+)
+
+// CertificateResolver is a certificate resolver which can be paused and resumed at runtime,
+// and whose certificates can be removed and revoked.
+type CertificateResolver interface {
+	Paused() bool
+	Pause() error
+	Resume() error
+	RemoveCertificate(domain string, revoke bool, reason *uint) error
+	StoreStatus() acme.StoreStatus
+}
+
+type certResolverRepresentation struct {
+	Name   string           `json:"name"`
+	Paused bool             `json:"paused"`
+	Store  acme.StoreStatus `json:"store"`
+}
+
+func (h Handler) getCertResolvers(rw http.ResponseWriter, request *http.Request) {
+	results := make([]certResolverRepresentation, 0, len(h.certResolvers))
+
+	for name, resolver := range h.certResolvers {
+		results = append(results, certResolverRepresentation{
+			Name:   name,
+			Paused: resolver.Paused(),
+			Store:  resolver.StoreStatus(),
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) getCertResolver(rw http.ResponseWriter, request *http.Request) {
+	resolverID := mux.Vars(request)["resolverID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	resolver, ok := h.certResolvers[resolverID]
+	if !ok {
+		writeError(rw, fmt.Sprintf("certificate resolver not found: %s", resolverID), http.StatusNotFound)
+		return
+	}
+
+	h.writeCertResolver(rw, request, resolverID, resolver)
+}
+
+func (h Handler) pauseCertResolver(rw http.ResponseWriter, request *http.Request) {
+	h.updateCertResolver(rw, request, CertificateResolver.Pause)
+}
+
+func (h Handler) resumeCertResolver(rw http.ResponseWriter, request *http.Request) {
+	h.updateCertResolver(rw, request, CertificateResolver.Resume)
+}
+
+func (h Handler) updateCertResolver(rw http.ResponseWriter, request *http.Request, update func(CertificateResolver) error) {
+	resolverID := mux.Vars(request)["resolverID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	resolver, ok := h.certResolvers[resolverID]
+	if !ok {
+		writeError(rw, fmt.Sprintf("certificate resolver not found: %s", resolverID), http.StatusNotFound)
+		return
+	}
+
+	if err := update(resolver); err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Str("resolver", resolverID).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeCertResolver(rw, request, resolverID, resolver)
+}
+
+func (h Handler) writeCertResolver(rw http.ResponseWriter, request *http.Request, name string, resolver CertificateResolver) {
+	result := certResolverRepresentation{
+		Name:   name,
+		Paused: resolver.Paused(),
+		Store:  resolver.StoreStatus(),
+	}
+
+	err := json.NewEncoder(rw).Encode(result)
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) removeCertificate(rw http.ResponseWriter, request *http.Request) {
+	resolverID := mux.Vars(request)["resolverID"]
+	domain := mux.Vars(request)["domain"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	resolver, ok := h.certResolvers[resolverID]
+	if !ok {
+		writeError(rw, fmt.Sprintf("certificate resolver not found: %s", resolverID), http.StatusNotFound)
+		return
+	}
+
+	query := request.URL.Query()
+
+	var revoke bool
+	if value := query.Get("revoke"); value != "" {
+		var err error
+		revoke, err = strconv.ParseBool(value)
+		if err != nil {
+			writeError(rw, fmt.Sprintf("invalid revoke parameter: %s", value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var reason *uint
+	if value := query.Get("reason"); value != "" {
+		if !revoke {
+			writeError(rw, "the reason parameter requires revoke=true", http.StatusBadRequest)
+			return
+		}
+
+		// the reason codes of RFC 5280, 7 being unused.
+		code, err := strconv.ParseUint(value, 10, 8)
+		if err != nil || code > 10 || code == 7 {
+			writeError(rw, fmt.Sprintf("invalid revocation reason code: %s", value), http.StatusBadRequest)
+			return
+		}
+
+		reasonCode := uint(code)
+		reason = &reasonCode
+	}
+
+	err := resolver.RemoveCertificate(domain, revoke, reason)
+	if errors.Is(err, acme.ErrCertificateNotFound) {
+		writeError(rw, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Str("resolver", resolverID).Str("domain", domain).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,321 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/provider/acme"
+)
+
+type fakeCertResolver struct {
+	paused bool
+	err    error
+	store  acme.StoreStatus
+
+	// domains are the main domains of the certificates, and whether they are revoked.
+	domains map[string]bool
+	reason  *uint
+}
+
+func (r *fakeCertResolver) Paused() bool {
+	return r.paused
+}
+
+func (r *fakeCertResolver) Pause() error {
+	if r.err != nil {
+		return r.err
+	}
+	r.paused = true
+	return nil
+}
+
+func (r *fakeCertResolver) Resume() error {
+	if r.err != nil {
+		return r.err
+	}
+	r.paused = false
+	return nil
+}
+
+func (r *fakeCertResolver) StoreStatus() acme.StoreStatus {
+	return r.store
+}
+
+func (r *fakeCertResolver) RemoveCertificate(domain string, revoke bool, reason *uint) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if _, ok := r.domains[domain]; !ok {
+		return fmt.Errorf("%w: %s", acme.ErrCertificateNotFound, domain)
+	}
+
+	r.domains[domain] = revoke
+	r.reason = reason
+	return nil
+}
+
+func TestHandler_CertResolvers(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		method         string
+		path           string
+		resolvers      map[string]CertificateResolver
+		readOnly       bool
+		expectedStatus int
+		expectedBody   string
+		expectedPaused map[string]bool
+	}{
+		{
+			desc:           "no resolvers",
+			method:         http.MethodGet,
+			path:           "/api/certresolvers",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			desc:   "list resolvers",
+			method: http.MethodGet,
+			path:   "/api/certresolvers",
+			resolvers: map[string]CertificateResolver{
+				"le":      &fakeCertResolver{},
+				"staging": &fakeCertResolver{paused: true},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"name":"le","paused":false,"store":{"healthy":false,"certificates":0}},{"name":"staging","paused":true,"store":{"healthy":false,"certificates":0}}]` + "\n",
+		},
+		{
+			desc:   "get resolver",
+			method: http.MethodGet,
+			path:   "/api/certresolvers/staging",
+			resolvers: map[string]CertificateResolver{
+				"staging": &fakeCertResolver{paused: true},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"staging","paused":true,"store":{"healthy":false,"certificates":0}}` + "\n",
+		},
+		{
+			desc:   "get resolver with store status",
+			method: http.MethodGet,
+			path:   "/api/certresolvers/le",
+			resolvers: map[string]CertificateResolver{
+				"le": &fakeCertResolver{store: acme.StoreStatus{
+					Backend:      acme.StoreBackendNomad,
+					Location:     "nomad://traefik/acme",
+					Healthy:      true,
+					Certificates: 2,
+				}},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"le","paused":false,"store":{"backend":"nomad","location":"nomad://traefik/acme","healthy":true,"certificates":2}}` + "\n",
+		},
+		{
+			desc:           "get unknown resolver",
+			method:         http.MethodGet,
+			path:           "/api/certresolvers/unknown",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"certificate resolver not found: unknown"}` + "\n",
+		},
+		{
+			desc:   "pause resolver",
+			method: http.MethodPut,
+			path:   "/api/certresolvers/le/pause",
+			resolvers: map[string]CertificateResolver{
+				"le": &fakeCertResolver{},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"le","paused":true,"store":{"healthy":false,"certificates":0}}` + "\n",
+			expectedPaused: map[string]bool{"le": true},
+		},
+		{
+			desc:   "resume resolver",
+			method: http.MethodPut,
+			path:   "/api/certresolvers/le/resume",
+			resolvers: map[string]CertificateResolver{
+				"le": &fakeCertResolver{paused: true},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"name":"le","paused":false,"store":{"healthy":false,"certificates":0}}` + "\n",
+			expectedPaused: map[string]bool{"le": false},
+		},
+		{
+			desc:   "pause resolver failure",
+			method: http.MethodPut,
+			path:   "/api/certresolvers/le/pause",
+			resolvers: map[string]CertificateResolver{
+				"le": &fakeCertResolver{err: errors.New("store failure")},
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"store failure"}` + "\n",
+			expectedPaused: map[string]bool{"le": false},
+		},
+		{
+			desc:           "pause unknown resolver",
+			method:         http.MethodPut,
+			path:           "/api/certresolvers/unknown/pause",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"certificate resolver not found: unknown"}` + "\n",
+		},
+		{
+			desc:           "pause without management enabled",
+			method:         http.MethodPut,
+			path:           "/api/certresolvers/le/pause",
+			resolvers:      map[string]CertificateResolver{"le": &fakeCertResolver{}},
+			readOnly:       true,
+			expectedStatus: http.StatusNotFound,
+			expectedPaused: map[string]bool{"le": false},
+		},
+		{
+			desc:           "pause with wrong method",
+			method:         http.MethodGet,
+			path:           "/api/certresolvers/le/pause",
+			resolvers:      map[string]CertificateResolver{"le": &fakeCertResolver{}},
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedPaused: map[string]bool{"le": false},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{ManageCertResolvers: !test.readOnly}}, test.resolvers, nil, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequest(test.method, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+
+			for name, paused := range test.expectedPaused {
+				assert.Equal(t, paused, test.resolvers[name].Paused())
+			}
+		})
+	}
+}
+
+func TestHandler_RemoveCertificate(t *testing.T) {
+	uintPtr := func(v uint) *uint { return &v }
+
+	testCases := []struct {
+		desc            string
+		path            string
+		err             error
+		expectedStatus  int
+		expectedBody    string
+		expectedRevoked *bool
+		expectedReason  *uint
+	}{
+		{
+			desc:            "remove certificate",
+			path:            "/api/certresolvers/le/certificates/example.com",
+			expectedStatus:  http.StatusNoContent,
+			expectedRevoked: func() *bool { b := false; return &b }(),
+		},
+		{
+			desc:            "revoke certificate",
+			path:            "/api/certresolvers/le/certificates/example.com?revoke=true",
+			expectedStatus:  http.StatusNoContent,
+			expectedRevoked: func() *bool { b := true; return &b }(),
+		},
+		{
+			desc:            "revoke certificate with reason",
+			path:            "/api/certresolvers/le/certificates/example.com?revoke=true&reason=1",
+			expectedStatus:  http.StatusNoContent,
+			expectedRevoked: func() *bool { b := true; return &b }(),
+			expectedReason:  uintPtr(1),
+		},
+		{
+			desc:           "reason without revoke",
+			path:           "/api/certresolvers/le/certificates/example.com?reason=1",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"the reason parameter requires revoke=true"}` + "\n",
+		},
+		{
+			desc:           "invalid reason",
+			path:           "/api/certresolvers/le/certificates/example.com?revoke=true&reason=7",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"invalid revocation reason code: 7"}` + "\n",
+		},
+		{
+			desc:           "invalid revoke",
+			path:           "/api/certresolvers/le/certificates/example.com?revoke=maybe",
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"invalid revoke parameter: maybe"}` + "\n",
+		},
+		{
+			desc:           "unknown certificate",
+			path:           "/api/certresolvers/le/certificates/unknown.com",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"certificate not found: unknown.com"}` + "\n",
+		},
+		{
+			desc:           "unknown resolver",
+			path:           "/api/certresolvers/unknown/certificates/example.com",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"certificate resolver not found: unknown"}` + "\n",
+		},
+		{
+			desc:           "revocation failure",
+			path:           "/api/certresolvers/le/certificates/example.com?revoke=true",
+			err:            errors.New("unable to revoke certificate"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"message":"unable to revoke certificate"}` + "\n",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			resolver := &fakeCertResolver{err: test.err, domains: map[string]bool{"example.com": false}}
+
+			handler := NewBuilder(static.Configuration{API: &static.API{ManageCertResolvers: true}}, map[string]CertificateResolver{"le": resolver}, nil, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequest(http.MethodDelete, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+
+			if test.expectedRevoked != nil {
+				assert.Equal(t, *test.expectedRevoked, resolver.domains["example.com"])
+			}
+			assert.Equal(t, test.expectedReason, resolver.reason)
+		})
+	}
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -10,6 +11,8 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/middlewares/cache"
+	"github.com/traefik/traefik/v3/pkg/middlewares/maintenance"
 	"github.com/traefik/traefik/v3/pkg/tls"
 )
 
@@ -33,19 +36,21 @@ func newRouterRepresentation(name string, rt *runtime.RouterInfo) routerRepresen
 
 type serviceRepresentation struct {
 	*runtime.ServiceInfo
-	ServerStatus map[string]string `json:"serverStatus,omitempty"`
-	Name         string            `json:"name,omitempty"`
-	Provider     string            `json:"provider,omitempty"`
-	Type         string            `json:"type,omitempty"`
+	ServerStatus      map[string]string                   `json:"serverStatus,omitempty"`
+	MirrorComparisons map[string]runtime.MirrorComparison `json:"mirrorComparisons,omitempty"`
+	Name              string                              `json:"name,omitempty"`
+	Provider          string                              `json:"provider,omitempty"`
+	Type              string                              `json:"type,omitempty"`
 }
 
 func newServiceRepresentation(name string, si *runtime.ServiceInfo) serviceRepresentation {
 	return serviceRepresentation{
-		ServiceInfo:  si,
-		Name:         name,
-		Provider:     getProviderName(name),
-		ServerStatus: si.GetAllStatus(),
-		Type:         strings.ToLower(extractType(si.Service)),
+		ServiceInfo:       si,
+		Name:              name,
+		Provider:          getProviderName(name),
+		ServerStatus:      si.GetAllStatus(),
+		MirrorComparisons: si.GetMirrorComparisons(),
+		Type:              strings.ToLower(extractType(si.Service)),
 	}
 }
 
@@ -218,6 +223,92 @@ func (h Handler) getMiddleware(rw http.ResponseWriter, request *http.Request) {
 	}
 }
 
+type purgeRepresentation struct {
+	Purged int `json:"purged"`
+}
+
+func (h Handler) purgeCache(rw http.ResponseWriter, request *http.Request) {
+	middlewareID := mux.Vars(request)["middlewareID"]
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	purged, err := cache.Purge(request.Context(), middlewareID, request.URL.Query().Get("prefix"))
+	if errors.Is(err, cache.ErrNotFound) {
+		writeError(rw, fmt.Sprintf("cache middleware not found: %s", middlewareID), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Str("middleware", middlewareID).Msg("Unable to purge the cache")
+		writeError(rw, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	err = json.NewEncoder(rw).Encode(purgeRepresentation{Purged: purged})
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type maintenanceRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+func (h Handler) getMaintenance(rw http.ResponseWriter, request *http.Request) {
+	middlewareID := mux.Vars(request)["middlewareID"]
+
+	state, err := maintenance.Get(middlewareID)
+	writeMaintenance(rw, request, middlewareID, state, err)
+}
+
+func (h Handler) setMaintenance(rw http.ResponseWriter, request *http.Request) {
+	middlewareID := mux.Vars(request)["middlewareID"]
+
+	var body maintenanceRequest
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		rw.Header().Set("Content-Type", "application/json")
+		writeError(rw, fmt.Sprintf("invalid maintenance request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	state, err := maintenance.Set(middlewareID, body.Enabled)
+	if err == nil {
+		log.Ctx(request.Context()).Info().Str("middleware", middlewareID).Bool("enabled", body.Enabled).Msg("Maintenance mode set through the API")
+	}
+
+	writeMaintenance(rw, request, middlewareID, state, err)
+}
+
+func (h Handler) resetMaintenance(rw http.ResponseWriter, request *http.Request) {
+	middlewareID := mux.Vars(request)["middlewareID"]
+
+	state, err := maintenance.Reset(middlewareID)
+	if err == nil {
+		log.Ctx(request.Context()).Info().Str("middleware", middlewareID).Msg("Maintenance mode reset through the API")
+	}
+
+	writeMaintenance(rw, request, middlewareID, state, err)
+}
+
+func writeMaintenance(rw http.ResponseWriter, request *http.Request, middlewareID string, state maintenance.State, err error) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	if errors.Is(err, maintenance.ErrNotFound) {
+		writeError(rw, fmt.Sprintf("maintenance middleware not found: %s", middlewareID), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(rw).Encode(state)
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func keepRouter(name string, item *runtime.RouterInfo, criterion *searchCriterion) bool {
 	if criterion == nil {
 		return true
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -16,6 +17,8 @@ import (
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/middlewares/cache"
+	"github.com/traefik/traefik/v3/pkg/middlewares/maintenance"
 )
 
 func Bool(v bool) *bool { return &v }
@@ -977,6 +980,171 @@ func TestHandler_HTTP(t *testing.T) {
 	}
 }
 
+func TestHandler_PurgeCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("Cache-Control", "max-age=60")
+		_, _ = rw.Write([]byte("foo"))
+	})
+
+	middleware, err := cache.New(ctx, next, dynamic.Cache{MaxSize: 1024, MaxEntrySize: 1024}, "purge-cache@file")
+	require.NoError(t, err)
+
+	for _, target := range []string{"http://example.com/static/foo", "http://example.com/static/bar", "http://example.com/other"} {
+		middleware.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	}
+
+	testCases := []struct {
+		desc           string
+		path           string
+		disabled       bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "purge disabled",
+			path:           "/api/http/middlewares/purge-cache@file/cache",
+			disabled:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "unknown middleware",
+			path:           "/api/http/middlewares/unknown@file/cache",
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"cache middleware not found: unknown@file"}` + "\n",
+		},
+		{
+			desc:           "purge prefix",
+			path:           "/api/http/middlewares/purge-cache@file/cache?prefix=http://example.com/static/",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"purged":2}` + "\n",
+		},
+		{
+			desc:           "purge all",
+			path:           "/api/http/middlewares/purge-cache@file/cache",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"purged":1}` + "\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			handler := New(static.Configuration{API: &static.API{PurgeCaches: !test.disabled}}, &runtime.Configuration{})
+			server := httptest.NewServer(handler.createRouter())
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequest(http.MethodDelete, server.URL+test.path, nil)
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestHandler_Maintenance(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	config := dynamic.Maintenance{}
+	config.SetDefaults()
+
+	_, err := maintenance.New(ctx, http.NotFoundHandler(), config, "maintenance@file")
+	require.NoError(t, err)
+
+	testCases := []struct {
+		desc           string
+		method         string
+		path           string
+		body           string
+		disabled       bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "maintenance disabled",
+			method:         http.MethodPut,
+			path:           "/api/http/middlewares/maintenance@file/maintenance",
+			body:           `{"enabled":true}`,
+			disabled:       true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			desc:           "unknown middleware",
+			method:         http.MethodPut,
+			path:           "/api/http/middlewares/unknown@file/maintenance",
+			body:           `{"enabled":true}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"maintenance middleware not found: unknown@file"}` + "\n",
+		},
+		{
+			desc:           "invalid body",
+			method:         http.MethodPut,
+			path:           "/api/http/middlewares/maintenance@file/maintenance",
+			body:           `enabled`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "get configured state",
+			method:         http.MethodGet,
+			path:           "/api/http/middlewares/maintenance@file/maintenance",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"enabled":false,"overridden":false}` + "\n",
+		},
+		{
+			desc:           "enable",
+			method:         http.MethodPut,
+			path:           "/api/http/middlewares/maintenance@file/maintenance",
+			body:           `{"enabled":true}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"enabled":true,"overridden":true}` + "\n",
+		},
+		{
+			desc:           "reset",
+			method:         http.MethodDelete,
+			path:           "/api/http/middlewares/maintenance@file/maintenance",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"enabled":false,"overridden":false}` + "\n",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			handler := New(static.Configuration{API: &static.API{ManageMaintenance: !test.disabled}}, &runtime.Configuration{})
+			server := httptest.NewServer(handler.createRouter())
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequest(test.method, server.URL+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+		})
+	}
+}
+
 func generateHTTPRouters(nbRouters int) map[string]*runtime.RouterInfo {
 	routers := make(map[string]*runtime.RouterInfo, nbRouters)
 	for i := 0; i < nbRouters; i++ {
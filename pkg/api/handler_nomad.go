@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
+)
+
+// NomadProvider is a Nomad provider reporting the services it discovers, their configuration errors and unknown tags,
+// and the service instances backing the servers.
+type NomadProvider interface {
+	ConfigurationErrors() []nomad.ConfigurationError
+	TagLints() []nomad.TagLint
+	Instance(serverURL string) (nomad.Instance, bool)
+	DiscoveredServices() []nomad.DiscoveredService
+	Status() nomad.Status
+}
+
+func (h Handler) getNomadErrors(rw http.ResponseWriter, request *http.Request) {
+	results := make([]nomad.ConfigurationError, 0)
+	for _, p := range h.nomadProviders {
+		results = append(results, p.ConfigurationErrors()...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Namespace != results[j].Namespace {
+			return results[i].Namespace < results[j].Namespace
+		}
+		if results[i].ServiceName != results[j].ServiceName {
+			return results[i].ServiceName < results[j].ServiceName
+		}
+		return results[i].ServiceID < results[j].ServiceID
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) getNomadLints(rw http.ResponseWriter, request *http.Request) {
+	results := make([]nomad.TagLint, 0)
+	for _, p := range h.nomadProviders {
+		results = append(results, p.TagLints()...)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Namespace != results[j].Namespace {
+			return results[i].Namespace < results[j].Namespace
+		}
+		if results[i].ServiceName != results[j].ServiceName {
+			return results[i].ServiceName < results[j].ServiceName
+		}
+		return results[i].Tag < results[j].Tag
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h Handler) getNomadServices(rw http.ResponseWriter, request *http.Request) {
+	criterion := newSearchCriterion(request.URL.Query())
+
+	results := make([]nomad.DiscoveredService, 0)
+	for _, p := range h.nomadProviders {
+		for _, service := range p.DiscoveredServices() {
+			if criterion == nil || criterion.searchIn(service.ServiceName, service.Namespace, service.Job) {
+				results = append(results, service)
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Namespace != results[j].Namespace {
+			return results[i].Namespace < results[j].Namespace
+		}
+		if results[i].ServiceName != results[j].ServiceName {
+			return results[i].ServiceName < results[j].ServiceName
+		}
+		return results[i].ServiceID < results[j].ServiceID
+	})
+
+	rw.Header().Set("Content-Type", "application/json")
+
+	pageInfo, err := pagination(request, len(results))
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rw.Header().Set(nextPageHeader, strconv.Itoa(pageInfo.nextPage))
+
+	err = json.NewEncoder(rw).Encode(results[pageInfo.startIndex:pageInfo.endIndex])
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
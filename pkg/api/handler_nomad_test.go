@@ -0,0 +1,295 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
+)
+
+type fakeNomadProvider []nomad.ConfigurationError
+
+func (p fakeNomadProvider) ConfigurationErrors() []nomad.ConfigurationError {
+	return p
+}
+
+func (p fakeNomadProvider) TagLints() []nomad.TagLint {
+	return nil
+}
+
+func (p fakeNomadProvider) Instance(string) (nomad.Instance, bool) {
+	return nomad.Instance{}, false
+}
+
+func (p fakeNomadProvider) DiscoveredServices() []nomad.DiscoveredService {
+	return nil
+}
+
+func (p fakeNomadProvider) Status() nomad.Status {
+	return nomad.Status{}
+}
+
+type fakeNomadServices []nomad.DiscoveredService
+
+func (p fakeNomadServices) ConfigurationErrors() []nomad.ConfigurationError {
+	return nil
+}
+
+func (p fakeNomadServices) TagLints() []nomad.TagLint {
+	return nil
+}
+
+func (p fakeNomadServices) Instance(string) (nomad.Instance, bool) {
+	return nomad.Instance{}, false
+}
+
+func (p fakeNomadServices) DiscoveredServices() []nomad.DiscoveredService {
+	return p
+}
+
+func (p fakeNomadServices) Status() nomad.Status {
+	return nomad.Status{}
+}
+
+type fakeNomadLints []nomad.TagLint
+
+func (p fakeNomadLints) ConfigurationErrors() []nomad.ConfigurationError {
+	return nil
+}
+
+func (p fakeNomadLints) TagLints() []nomad.TagLint {
+	return p
+}
+
+func (p fakeNomadLints) Instance(string) (nomad.Instance, bool) {
+	return nomad.Instance{}, false
+}
+
+func (p fakeNomadLints) DiscoveredServices() []nomad.DiscoveredService {
+	return nil
+}
+
+func (p fakeNomadLints) Status() nomad.Status {
+	return nomad.Status{}
+}
+
+func TestHandler_NomadErrors(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		path           string
+		providers      []NomadProvider
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "no providers",
+			path:           "/api/nomad/errors",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			desc: "errors of all the providers",
+			path: "/api/nomad/errors",
+			providers: []NomadProvider{
+				fakeNomadProvider{
+					{ServiceName: "whoami", ServiceID: "id2", Namespace: "prod", Message: "invalid"},
+				},
+				fakeNomadProvider{
+					{
+						ServiceName: "whoami",
+						ServiceID:   "id1",
+						Namespace:   "dev",
+						Message:     "invalid",
+						Tags:        []nomad.TagError{{Tag: "traefik.http.routers.whoami.priority", Value: "high", Message: "invalid syntax"}},
+						Fallback:    true,
+					},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"serviceName":"whoami","serviceID":"id1","namespace":"dev","message":"invalid","tags":[{"tag":"traefik.http.routers.whoami.priority","value":"high","message":"invalid syntax"}],"fallback":true},` +
+				`{"serviceName":"whoami","serviceID":"id2","namespace":"prod","message":"invalid"}]` + "\n",
+		},
+		{
+			desc: "page out of range",
+			path: "/api/nomad/errors?page=2",
+			providers: []NomadProvider{
+				fakeNomadProvider{{ServiceName: "whoami", ServiceID: "id1", Message: "invalid"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}}, nil, test.providers, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestHandler_NomadLints(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		path           string
+		providers      []NomadProvider
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "no providers",
+			path:           "/api/nomad/lint",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			desc: "lints of all the providers",
+			path: "/api/nomad/lint",
+			providers: []NomadProvider{
+				fakeNomadLints{
+					{ServiceName: "whoami", Namespace: "prod", Tag: "traefik.http.routers.whoami.something", Message: "unknown tag"},
+				},
+				fakeNomadLints{
+					{
+						ServiceName: "whoami",
+						Namespace:   "dev",
+						Tag:         "treafik.enable",
+						Message:     "unknown tag, did you mean traefik.enable?",
+						Suggestion:  "traefik.enable",
+					},
+				},
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"serviceName":"whoami","namespace":"dev","tag":"treafik.enable","message":"unknown tag, did you mean traefik.enable?","suggestion":"traefik.enable"},` +
+				`{"serviceName":"whoami","namespace":"prod","tag":"traefik.http.routers.whoami.something","message":"unknown tag"}]` + "\n",
+		},
+		{
+			desc: "page out of range",
+			path: "/api/nomad/lint?page=2",
+			providers: []NomadProvider{
+				fakeNomadLints{{ServiceName: "whoami", Tag: "treafik.enable", Message: "unknown tag"}},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}}, nil, test.providers, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+		})
+	}
+}
+
+func TestHandler_NomadServices(t *testing.T) {
+	whoami := nomad.DiscoveredService{
+		Instance: nomad.Instance{ServiceName: "whoami", ServiceID: "id1", Namespace: "prod", Job: "web", AllocID: "alloc1"},
+		Tags:     []string{"traefik.http.routers.whoami.rule=Host(`whoami.example.com`)"},
+		Routers:  []nomad.Resource{{Protocol: "http", Name: "whoami@nomad"}},
+		Services: []nomad.Resource{{Protocol: "http", Name: "whoami@nomad"}},
+	}
+	redis := nomad.DiscoveredService{
+		Instance: nomad.Instance{ServiceName: "redis", ServiceID: "id2", Namespace: "dev", Job: "cache"},
+		Tags:     []string{"traefik.tcp.routers.redis.rule=HostSNI(`*`)", "traefik.tcp.routers.redis.priority=high"},
+		Error:    &nomad.ConfigurationError{ServiceName: "redis", ServiceID: "id2", Namespace: "dev", Message: "invalid"},
+	}
+
+	testCases := []struct {
+		desc           string
+		path           string
+		providers      []NomadProvider
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "no providers",
+			path:           "/api/nomad/services",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "[]\n",
+		},
+		{
+			desc:           "services of all the providers",
+			path:           "/api/nomad/services",
+			providers:      []NomadProvider{fakeNomadServices{whoami}, fakeNomadServices{redis}},
+			expectedStatus: http.StatusOK,
+			expectedBody: `[{"serviceName":"redis","serviceID":"id2","namespace":"dev","job":"cache","tags":["traefik.tcp.routers.redis.rule=HostSNI(` + "`*`" + `)","traefik.tcp.routers.redis.priority=high"],"error":{"serviceName":"redis","serviceID":"id2","namespace":"dev","message":"invalid"}},` +
+				`{"serviceName":"whoami","serviceID":"id1","namespace":"prod","job":"web","allocID":"alloc1","tags":["traefik.http.routers.whoami.rule=Host(` + "`whoami.example.com`" + `)"],"routers":[{"protocol":"http","name":"whoami@nomad"}],"services":[{"protocol":"http","name":"whoami@nomad"}]}]` + "\n",
+		},
+		{
+			desc:           "search by job",
+			path:           "/api/nomad/services?search=web",
+			providers:      []NomadProvider{fakeNomadServices{whoami, redis}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"serviceName":"whoami","serviceID":"id1","namespace":"prod","job":"web","allocID":"alloc1","tags":["traefik.http.routers.whoami.rule=Host(` + "`whoami.example.com`" + `)"],"routers":[{"protocol":"http","name":"whoami@nomad"}],"services":[{"protocol":"http","name":"whoami@nomad"}]}]` + "\n",
+		},
+		{
+			desc:           "page out of range",
+			path:           "/api/nomad/services?page=2",
+			providers:      []NomadProvider{fakeNomadServices{whoami}},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}}, nil, test.providers, nil)(&runtime.Configuration{})
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			resp, err := http.DefaultClient.Get(server.URL + test.path)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+		})
+	}
+}
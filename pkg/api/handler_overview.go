@@ -2,8 +2,10 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"reflect"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
@@ -30,12 +32,23 @@ type features struct {
 	// TODO add certificates resolvers
 }
 
+// degradedProvider is a provider which cannot reach its backend, and keeps serving its last known configuration,
+// or whose discovered services have a configuration which cannot be applied.
+type degradedProvider struct {
+	Name string `json:"name"`
+	// Reason is either discovery, when the backend cannot be reached, or configuration.
+	Reason    string    `json:"reason"`
+	Since     time.Time `json:"since"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
 type overview struct {
-	HTTP      schemeOverview `json:"http"`
-	TCP       schemeOverview `json:"tcp"`
-	UDP       schemeOverview `json:"udp"`
-	Features  features       `json:"features,omitempty"`
-	Providers []string       `json:"providers,omitempty"`
+	HTTP              schemeOverview     `json:"http"`
+	TCP               schemeOverview     `json:"tcp"`
+	UDP               schemeOverview     `json:"udp"`
+	Features          features           `json:"features,omitempty"`
+	Providers         []string           `json:"providers,omitempty"`
+	DegradedProviders []degradedProvider `json:"degradedProviders,omitempty"`
 }
 
 func (h Handler) getOverview(rw http.ResponseWriter, request *http.Request) {
@@ -54,8 +67,9 @@ func (h Handler) getOverview(rw http.ResponseWriter, request *http.Request) {
 			Routers:  getUDPRouterSection(h.runtimeConfiguration.UDPRouters),
 			Services: getUDPServiceSection(h.runtimeConfiguration.UDPServices),
 		},
-		Features:  getFeatures(h.staticConfig),
-		Providers: getProviders(h.staticConfig),
+		Features:          getFeatures(h.staticConfig),
+		Providers:         getProviders(h.staticConfig),
+		DegradedProviders: h.getDegradedProviders(),
 	}
 
 	rw.Header().Set("Content-Type", "application/json")
@@ -287,3 +301,30 @@ func getTracing(conf static.Configuration) string {
 
 	return ""
 }
+
+func (h Handler) getDegradedProviders() []degradedProvider {
+	var degraded []degradedProvider
+	for _, p := range h.nomadProviders {
+		status := p.Status()
+
+		if status.Degraded {
+			degraded = append(degraded, degradedProvider{
+				Name:      status.Name,
+				Reason:    "discovery",
+				Since:     status.DegradedSince,
+				LastError: status.LastError,
+			})
+		}
+
+		if status.ConfigurationDegraded {
+			degraded = append(degraded, degradedProvider{
+				Name:      status.Name,
+				Reason:    "configuration",
+				Since:     status.ConfigurationDegradedSince,
+				LastError: fmt.Sprintf("%d service instances with configuration errors", status.ConfigurationErrors),
+			})
+		}
+	}
+
+	return degraded
+}
@@ -7,6 +7,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,11 +19,22 @@ import (
 	"github.com/traefik/traefik/v3/pkg/provider/hub"
 	"github.com/traefik/traefik/v3/pkg/provider/kubernetes/crd"
 	"github.com/traefik/traefik/v3/pkg/provider/kubernetes/ingress"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
 	"github.com/traefik/traefik/v3/pkg/provider/rest"
 	"github.com/traefik/traefik/v3/pkg/tracing/jaeger"
 	"github.com/traefik/traefik/v3/pkg/types"
 )
 
+// fakeNomadStatus is a Nomad provider reporting the given synchronization status.
+type fakeNomadStatus struct {
+	fakeNomadProvider
+	status nomad.Status
+}
+
+func (p fakeNomadStatus) Status() nomad.Status {
+	return p.status
+}
+
 func TestHandler_Overview(t *testing.T) {
 	type expected struct {
 		statusCode int
@@ -30,11 +42,12 @@ func TestHandler_Overview(t *testing.T) {
 	}
 
 	testCases := []struct {
-		desc       string
-		path       string
-		confStatic static.Configuration
-		confDyn    runtime.Configuration
-		expected   expected
+		desc           string
+		path           string
+		confStatic     static.Configuration
+		confDyn        runtime.Configuration
+		nomadProviders []NomadProvider
+		expected       expected
 	}{
 		{
 			desc:       "without data in the dynamic configuration",
@@ -270,6 +283,31 @@ func TestHandler_Overview(t *testing.T) {
 				jsonFile:   "testdata/overview-features.json",
 			},
 		},
+		{
+			desc:       "with degraded providers",
+			path:       "/api/overview",
+			confStatic: static.Configuration{API: &static.API{}, Global: &static.Global{}},
+			confDyn:    runtime.Configuration{},
+			nomadProviders: []NomadProvider{
+				fakeNomadStatus{status: nomad.Status{Name: "nomad"}},
+				fakeNomadStatus{status: nomad.Status{
+					Name:          "nomad-eu",
+					LastError:     "connection refused",
+					Degraded:      true,
+					DegradedSince: time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC),
+				}},
+				fakeNomadStatus{status: nomad.Status{
+					Name:                       "nomad-us",
+					ConfigurationErrors:        2,
+					ConfigurationDegraded:      true,
+					ConfigurationDegradedSince: time.Date(2024, time.March, 1, 13, 0, 0, 0, time.UTC),
+				}},
+			},
+			expected: expected{
+				statusCode: http.StatusOK,
+				jsonFile:   "testdata/overview-degraded.json",
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -278,6 +316,7 @@ func TestHandler_Overview(t *testing.T) {
 			t.Parallel()
 
 			handler := New(test.confStatic, &test.confDyn)
+			handler.nomadProviders = test.nomadProviders
 			server := httptest.NewServer(handler.createRouter())
 
 			resp, err := http.DefaultClient.Get(server.URL + test.path)
@@ -0,0 +1,276 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/middlewares/requestdecorator"
+	httpmuxer "github.com/traefik/traefik/v3/pkg/muxer/http"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
+	"github.com/traefik/traefik/v3/pkg/server/provider"
+)
+
+// previewRequest is the description of the request whose routing is previewed.
+type previewRequest struct {
+	EntryPoint string            `json:"entryPoint"`
+	Method     string            `json:"method,omitempty"`
+	Host       string            `json:"host"`
+	Path       string            `json:"path,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	ClientIP   string            `json:"clientIP,omitempty"`
+	TLS        bool              `json:"tls,omitempty"`
+}
+
+// previewRepresentation describes how a request would be handled,
+// from the router matching it to the servers it could be forwarded to.
+type previewRepresentation struct {
+	Router      string          `json:"router"`
+	Rule        string          `json:"rule"`
+	Priority    int             `json:"priority"`
+	Middlewares []string        `json:"middlewares,omitempty"`
+	Service     string          `json:"service"`
+	Servers     []previewServer `json:"servers,omitempty"`
+}
+
+type previewServer struct {
+	Service string          `json:"service"`
+	URL     string          `json:"url"`
+	Status  string          `json:"status,omitempty"`
+	Nomad   *nomad.Instance `json:"nomad,omitempty"`
+}
+
+// previewRouting reports which router, middlewares, service and servers would handle the described request,
+// without forwarding any request to the servers.
+func (h Handler) previewRouting(rw http.ResponseWriter, request *http.Request) {
+	rw.Header().Set("Content-Type", "application/json")
+
+	var preview previewRequest
+	if err := json.NewDecoder(request.Body).Decode(&preview); err != nil {
+		writeError(rw, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	req, err := preview.build(request.Context())
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	routerName, err := h.matchRouter(request.Context(), preview.EntryPoint, preview.TLS, req)
+	if err != nil {
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if routerName == "" {
+		writeError(rw, fmt.Sprintf("no router of the entrypoint %s matches the request", preview.EntryPoint), http.StatusNotFound)
+		return
+	}
+
+	err = json.NewEncoder(rw).Encode(h.newPreviewRepresentation(request.Context(), routerName))
+	if err != nil {
+		log.Ctx(request.Context()).Error().Err(err).Send()
+		writeError(rw, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (p previewRequest) build(ctx context.Context) (*http.Request, error) {
+	if p.EntryPoint == "" {
+		return nil, errors.New("the entrypoint is required")
+	}
+
+	if p.Host == "" {
+		return nil, errors.New("the host is required")
+	}
+
+	method := p.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	path := p.Path
+	if path == "" {
+		path = "/"
+	}
+
+	scheme := "http"
+	if p.TLS {
+		scheme = "https"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, scheme+"://"+p.Host+path, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	for name, value := range p.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if p.TLS {
+		req.TLS = &tls.ConnectionState{ServerName: req.URL.Hostname()}
+	}
+
+	req.RemoteAddr = net.JoinHostPort("127.0.0.1", "0")
+	if p.ClientIP != "" {
+		if net.ParseIP(p.ClientIP) == nil {
+			return nil, fmt.Errorf("invalid client IP %q", p.ClientIP)
+		}
+
+		req.RemoteAddr = net.JoinHostPort(p.ClientIP, "0")
+	}
+
+	return req, nil
+}
+
+// matchRouter returns the name of the router of the entrypoint matching the request, as the router manager would route it.
+func (h Handler) matchRouter(ctx context.Context, entryPoint string, secure bool, req *http.Request) (string, error) {
+	muxer, err := httpmuxer.NewMuxer()
+	if err != nil {
+		return "", err
+	}
+
+	// the routers are added in a predictable order, so that the routers with the same priority are matched the same way.
+	names := make([]string, 0, len(h.runtimeConfiguration.Routers))
+	for name := range h.runtimeConfiguration.Routers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var matched string
+	for _, name := range names {
+		rt := h.runtimeConfiguration.Routers[name]
+		if rt.Status == runtime.StatusDisabled || secure != (rt.TLS != nil) || !contains(rt.Using, entryPoint) {
+			continue
+		}
+
+		name := name
+		err := muxer.AddRoute(rt.Rule, routerPriority(rt), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+			matched = name
+		}))
+		if err != nil {
+			log.Ctx(ctx).Debug().Err(err).Str("router", name).Msg("Unable to preview the router")
+		}
+	}
+
+	// the request decorator provides the canonical host, as it does on the entrypoints.
+	requestdecorator.New(h.staticConfig.HostResolver).ServeHTTP(noopResponseWriter{}, req, muxer.ServeHTTP)
+
+	return matched, nil
+}
+
+func (h Handler) newPreviewRepresentation(ctx context.Context, routerName string) previewRepresentation {
+	rt := h.runtimeConfiguration.Routers[routerName]
+	ctxRouter := provider.AddInContext(ctx, routerName)
+
+	result := previewRepresentation{
+		Router:   routerName,
+		Rule:     rt.Rule,
+		Priority: routerPriority(rt),
+		Service:  provider.GetQualifiedName(ctxRouter, rt.Service),
+	}
+
+	for _, middleware := range rt.Middlewares {
+		result.Middlewares = append(result.Middlewares, provider.GetQualifiedName(ctxRouter, middleware))
+	}
+
+	result.Servers = h.previewServers(ctx, result.Service, make(map[string]struct{}))
+
+	return result
+}
+
+// previewServers returns the servers the service, and the services it references, could forward the requests to.
+func (h Handler) previewServers(ctx context.Context, serviceName string, visited map[string]struct{}) []previewServer {
+	if _, ok := visited[serviceName]; ok {
+		return nil
+	}
+	visited[serviceName] = struct{}{}
+
+	service, ok := h.runtimeConfiguration.Services[serviceName]
+	if !ok || service.Service == nil {
+		return nil
+	}
+
+	ctxService := provider.AddInContext(ctx, serviceName)
+
+	var children []string
+	switch {
+	case service.LoadBalancer != nil:
+		status := service.GetAllStatus()
+
+		servers := make([]previewServer, 0, len(service.LoadBalancer.Servers))
+		for _, server := range service.LoadBalancer.Servers {
+			servers = append(servers, previewServer{
+				Service: serviceName,
+				URL:     server.URL,
+				Status:  status[server.URL],
+				Nomad:   h.nomadInstance(server.URL),
+			})
+		}
+
+		return servers
+
+	case service.Weighted != nil:
+		for _, child := range service.Weighted.Services {
+			children = append(children, child.Name)
+		}
+
+	case service.Failover != nil:
+		children = append(children, service.Failover.Service, service.Failover.Fallback)
+
+	case service.Mirroring != nil:
+		// the mirrors receive a copy of the request, but their responses are discarded.
+		children = append(children, service.Mirroring.Service)
+	}
+
+	var servers []previewServer
+	for _, child := range children {
+		servers = append(servers, h.previewServers(ctx, provider.GetQualifiedName(ctxService, child), visited)...)
+	}
+
+	return servers
+}
+
+func (h Handler) nomadInstance(serverURL string) *nomad.Instance {
+	for _, p := range h.nomadProviders {
+		if instance, ok := p.Instance(serverURL); ok {
+			return &instance
+		}
+	}
+
+	return nil
+}
+
+type noopResponseWriter struct{}
+
+func (noopResponseWriter) Header() http.Header { return http.Header{} }
+
+func (noopResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+
+func (noopResponseWriter) WriteHeader(int) {}
+
+func routerPriority(rt *runtime.RouterInfo) int {
+	if rt.Priority == 0 {
+		return httpmuxer.GetRulePriority(rt.Rule)
+	}
+
+	return rt.Priority
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
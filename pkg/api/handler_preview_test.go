@@ -0,0 +1,192 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
+)
+
+type fakeNomadInstances map[string]nomad.Instance
+
+func (p fakeNomadInstances) ConfigurationErrors() []nomad.ConfigurationError {
+	return nil
+}
+
+func (p fakeNomadInstances) TagLints() []nomad.TagLint {
+	return nil
+}
+
+func (p fakeNomadInstances) Instance(serverURL string) (nomad.Instance, bool) {
+	instance, ok := p[serverURL]
+	return instance, ok
+}
+
+func (p fakeNomadInstances) DiscoveredServices() []nomad.DiscoveredService {
+	return nil
+}
+
+func (p fakeNomadInstances) Status() nomad.Status {
+	return nomad.Status{}
+}
+
+func TestHandler_PreviewRouting(t *testing.T) {
+	newRouter := func(rule, service string, entryPoints ...string) *runtime.RouterInfo {
+		return &runtime.RouterInfo{
+			Router: &dynamic.Router{EntryPoints: entryPoints, Rule: rule, Service: service},
+			Using:  entryPoints,
+			Status: runtime.StatusEnabled,
+		}
+	}
+
+	newLoadBalancer := func(urls ...string) *runtime.ServiceInfo {
+		lb := &dynamic.ServersLoadBalancer{}
+		for _, u := range urls {
+			lb.Servers = append(lb.Servers, dynamic.Server{URL: u})
+		}
+
+		return &runtime.ServiceInfo{Service: &dynamic.Service{LoadBalancer: lb}, Status: runtime.StatusEnabled}
+	}
+
+	rtConf := func() *runtime.Configuration {
+		api := newRouter("Host(`example.com`) && PathPrefix(`/api`)", "api", "web")
+		api.Middlewares = []string{"auth@file", "strip"}
+
+		secure := newRouter("Host(`example.com`)", "web", "web")
+		secure.TLS = &dynamic.RouterTLSConfig{}
+
+		disabled := newRouter("PathPrefix(`/`)", "web", "web")
+		disabled.Status = runtime.StatusDisabled
+
+		apiLocal := newLoadBalancer("http://10.0.0.1:80")
+		apiLocal.UpdateServerStatus("http://10.0.0.1:80", runtime.StatusUp)
+
+		return &runtime.Configuration{
+			Routers: map[string]*runtime.RouterInfo{
+				"api@nomad":      api,
+				"web@nomad":      newRouter("Host(`example.com`)", "web", "web"),
+				"secure@file":    secure,
+				"disabled@file":  disabled,
+				"internal@nomad": newRouter("Host(`example.com`) && PathPrefix(`/internal`)", "web", "internal"),
+			},
+			Services: map[string]*runtime.ServiceInfo{
+				"api@nomad": {
+					Service: &dynamic.Service{Weighted: &dynamic.WeightedRoundRobin{
+						Services: []dynamic.WRRService{{Name: "api-local"}, {Name: "api-remote"}},
+					}},
+				},
+				"api-local@nomad":  apiLocal,
+				"api-remote@nomad": newLoadBalancer("http://10.0.0.2:80"),
+				"web@nomad":        newLoadBalancer("http://10.0.0.3:80"),
+			},
+		}
+	}
+
+	nomadProviders := []NomadProvider{
+		fakeNomadInstances{
+			"http://10.0.0.1:80": {ServiceName: "api", ServiceID: "id1", Namespace: "default", AllocID: "alloc1", Datacenter: "dc1"},
+		},
+	}
+
+	testCases := []struct {
+		desc           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "router with weighted service",
+			method:         http.MethodPost,
+			body:           `{"entryPoint":"web","host":"example.com","path":"/api/users"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"router":"api@nomad","rule":"Host(` + "`example.com`" + `) \u0026\u0026 PathPrefix(` + "`/api`" + `)","priority":41,` +
+				`"middlewares":["auth@file","strip@nomad"],"service":"api@nomad","servers":[` +
+				`{"service":"api-local@nomad","url":"http://10.0.0.1:80","status":"UP","nomad":{"serviceName":"api","serviceID":"id1","namespace":"default","allocID":"alloc1","datacenter":"dc1"}},` +
+				`{"service":"api-remote@nomad","url":"http://10.0.0.2:80"}]}` + "\n",
+		},
+		{
+			desc:           "lower priority router",
+			method:         http.MethodPost,
+			body:           `{"entryPoint":"web","method":"POST","host":"example.com","path":"/","headers":{"X-Test":"1"}}`,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"router":"web@nomad","rule":"Host(` + "`example.com`" + `)","priority":19,` +
+				`"service":"web@nomad","servers":[{"service":"web@nomad","url":"http://10.0.0.3:80"}]}` + "\n",
+		},
+		{
+			desc:           "TLS router",
+			method:         http.MethodPost,
+			body:           `{"entryPoint":"web","host":"example.com","tls":true}`,
+			expectedStatus: http.StatusOK,
+			expectedBody: `{"router":"secure@file","rule":"Host(` + "`example.com`" + `)","priority":19,` +
+				`"service":"web@file"}` + "\n",
+		},
+		{
+			desc:           "no matching router",
+			method:         http.MethodPost,
+			body:           `{"entryPoint":"web","host":"other.com"}`,
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"message":"no router of the entrypoint web matches the request"}` + "\n",
+		},
+		{
+			desc:           "missing host",
+			method:         http.MethodPost,
+			body:           `{"entryPoint":"web"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"the host is required"}` + "\n",
+		},
+		{
+			desc:           "invalid client IP",
+			method:         http.MethodPost,
+			body:           `{"entryPoint":"web","host":"example.com","clientIP":"foo"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"message":"invalid client IP \"foo\""}` + "\n",
+		},
+		{
+			desc:           "invalid body",
+			method:         http.MethodPost,
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "wrong method",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewBuilder(static.Configuration{API: &static.API{}}, nil, nomadProviders, nil)(rtConf())
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			req, err := http.NewRequest(test.method, server.URL+"/api/http/preview", strings.NewReader(test.body))
+			require.NoError(t, err)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, string(body))
+			}
+		})
+	}
+}
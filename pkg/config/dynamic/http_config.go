@@ -22,6 +22,18 @@ const (
 	DefaultFlushInterval = ptypes.Duration(100 * time.Millisecond)
 )
 
+// Load-balancing strategies of the ServersLoadBalancer.
+const (
+	// BalancerStrategyWRR is the weighted round robin strategy, the default one.
+	BalancerStrategyWRR = "wrr"
+	// BalancerStrategyLeastConn picks the server with the fewest in-flight requests relative to its weight.
+	BalancerStrategyLeastConn = "leastconn"
+	// BalancerStrategyP2C picks the least loaded of two random servers (power of two choices).
+	BalancerStrategyP2C = "p2c"
+	// BalancerStrategyRandom picks a random server, proportionally to its weight.
+	BalancerStrategyRandom = "random"
+)
+
 // +k8s:deepcopy-gen=true
 
 // HTTPConfiguration contains all the HTTP configuration parameters.
@@ -61,6 +73,7 @@ type Router struct {
 	Rule        string           `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty"`
 	Priority    int              `json:"priority,omitempty" toml:"priority,omitempty,omitzero" yaml:"priority,omitempty" export:"true"`
 	TLS         *RouterTLSConfig `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	Timeouts    *RouterTimeouts  `json:"timeouts,omitempty" toml:"timeouts,omitempty" yaml:"timeouts,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -74,6 +87,17 @@ type RouterTLSConfig struct {
 
 // +k8s:deepcopy-gen=true
 
+// RouterTimeouts holds the timeouts of the requests handled by a router.
+// They can only shorten the forwarding timeouts of the servers transport of the service, the shortest one applying.
+type RouterTimeouts struct {
+	DialTimeout           ptypes.Duration `description:"The amount of time to wait until a connection to a backend server can be established. If zero, no timeout exists." json:"dialTimeout,omitempty" toml:"dialTimeout,omitempty" yaml:"dialTimeout,omitempty" export:"true"`
+	ResponseHeaderTimeout ptypes.Duration `description:"The amount of time to wait for the response headers, from the moment the router handles the request. If zero, no timeout exists." json:"responseHeaderTimeout,omitempty" toml:"responseHeaderTimeout,omitempty" yaml:"responseHeaderTimeout,omitempty" export:"true"`
+	IdleTimeout           ptypes.Duration `description:"The maximum amount of time between two writes of the response body, after which the request is aborted. If zero, no timeout exists." json:"idleTimeout,omitempty" toml:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty" export:"true"`
+	RequestTimeout        ptypes.Duration `description:"The maximum duration of a request, including the transfer of the response body. If zero, no timeout exists." json:"requestTimeout,omitempty" toml:"requestTimeout,omitempty" yaml:"requestTimeout,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Mirroring holds the Mirroring configuration.
 type Mirroring struct {
 	Service     string          `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
@@ -103,6 +127,9 @@ type Failover struct {
 type MirrorService struct {
 	Name    string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
 	Percent int    `json:"percent,omitempty" toml:"percent,omitempty" yaml:"percent,omitempty" export:"true"`
+	// Rule restricts the mirrored requests to the ones matching it, with the syntax of the router rules.
+	// The percentage applies to the matching requests.
+	Rule string `json:"rule,omitempty" toml:"rule,omitempty" yaml:"rule,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -139,6 +166,25 @@ func (w *WRRService) SetDefaults() {
 type Sticky struct {
 	// Cookie defines the sticky cookie configuration.
 	Cookie *Cookie `json:"cookie,omitempty" toml:"cookie,omitempty" yaml:"cookie,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// Hash defines the sticky configuration based on the consistent hashing of the requests.
+	Hash *StickyHash `json:"hash,omitempty" toml:"hash,omitempty" yaml:"hash,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// StickyHash holds the sticky configuration based on the consistent hashing of a request attribute onto the servers (ketama ring).
+// The requests with the same attribute value are forwarded to the same server while it is up,
+// and the servers changes only move the requests of the removed servers, or a share of the requests to the added ones.
+type StickyHash struct {
+	// Header defines the name of the header whose value is hashed.
+	Header string `json:"header,omitempty" toml:"header,omitempty" yaml:"header,omitempty" export:"true"`
+	// Cookie defines the name of the cookie whose value is hashed.
+	Cookie string `json:"cookie,omitempty" toml:"cookie,omitempty" yaml:"cookie,omitempty" export:"true"`
+	// IPStrategy defines how the client IP, which is hashed when neither Header nor Cookie is set, is found.
+	IPStrategy *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// VirtualNodes defines the number of points of each server on the hash ring, multiplied by its weight.
+	// Default: 160.
+	VirtualNodes int `json:"virtualNodes,omitempty" toml:"virtualNodes,omitempty" yaml:"virtualNodes,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -170,6 +216,14 @@ type ServersLoadBalancer struct {
 	PassHostHeader     *bool               `json:"passHostHeader" toml:"passHostHeader" yaml:"passHostHeader" export:"true"`
 	ResponseForwarding *ResponseForwarding `json:"responseForwarding,omitempty" toml:"responseForwarding,omitempty" yaml:"responseForwarding,omitempty" export:"true"`
 	ServersTransport   string              `json:"serversTransport,omitempty" toml:"serversTransport,omitempty" yaml:"serversTransport,omitempty" export:"true"`
+	// RetryOnConnectionRefused retries the idempotent requests on another server
+	// when the chosen server refuses the connection, e.g. because its instance just stopped.
+	RetryOnConnectionRefused bool `json:"retryOnConnectionRefused,omitempty" toml:"retryOnConnectionRefused,omitempty" yaml:"retryOnConnectionRefused,omitempty" export:"true"`
+	// PassiveHealthCheck ejects the servers failing the proxied requests for a cooldown period,
+	// independently of the active health check.
+	PassiveHealthCheck *PassiveServerHealthCheck `json:"passiveHealthCheck,omitempty" toml:"passiveHealthCheck,omitempty" yaml:"passiveHealthCheck,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// Strategy is the load-balancing strategy among the servers: wrr (default), leastconn, p2c or random.
+	Strategy string `json:"strategy,omitempty" toml:"strategy,omitempty" yaml:"strategy,omitempty" export:"true"`
 }
 
 // Mergeable tells if the given service is mergeable.
@@ -220,6 +274,7 @@ func (r *ResponseForwarding) SetDefaults() {
 // Server holds the server configuration.
 type Server struct {
 	URL    string `json:"url,omitempty" toml:"url,omitempty" yaml:"url,omitempty" label:"-"`
+	Weight *int   `json:"weight,omitempty" toml:"weight,omitempty" yaml:"weight,omitempty" label:"-" export:"true"`
 	Scheme string `toml:"-" json:"-" yaml:"-" file:"-"`
 	Port   string `toml:"-" json:"-" yaml:"-" file:"-"`
 }
@@ -257,6 +312,25 @@ func (h *ServerHealthCheck) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// PassiveServerHealthCheck holds the configuration of the passive health check of the servers,
+// tracking the 5xx responses and the connection errors of the proxied requests.
+type PassiveServerHealthCheck struct {
+	MaxFailedAttempts  int             `json:"maxFailedAttempts,omitempty" toml:"maxFailedAttempts,omitempty" yaml:"maxFailedAttempts,omitempty" export:"true"`
+	FailureWindow      ptypes.Duration `json:"failureWindow,omitempty" toml:"failureWindow,omitempty" yaml:"failureWindow,omitempty" export:"true"`
+	Cooldown           ptypes.Duration `json:"cooldown,omitempty" toml:"cooldown,omitempty" yaml:"cooldown,omitempty" export:"true"`
+	MaxEjectionPercent int             `json:"maxEjectionPercent,omitempty" toml:"maxEjectionPercent,omitempty" yaml:"maxEjectionPercent,omitempty" export:"true"`
+}
+
+// SetDefaults Default values for a PassiveServerHealthCheck.
+func (p *PassiveServerHealthCheck) SetDefaults() {
+	p.MaxFailedAttempts = 5
+	p.FailureWindow = ptypes.Duration(10 * time.Second)
+	p.Cooldown = ptypes.Duration(30 * time.Second)
+	p.MaxEjectionPercent = 50
+}
+
+// +k8s:deepcopy-gen=true
+
 // HealthCheck controls healthcheck awareness and propagation at the services level.
 type HealthCheck struct{}
 
@@ -264,15 +338,18 @@ type HealthCheck struct{}
 
 // ServersTransport options to configure communication between Traefik and the servers.
 type ServersTransport struct {
-	ServerName          string                     `description:"Defines the serverName used to contact the server." json:"serverName,omitempty" toml:"serverName,omitempty" yaml:"serverName,omitempty"`
-	InsecureSkipVerify  bool                       `description:"Disables SSL certificate verification." json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" export:"true"`
-	RootCAs             []traefiktls.FileOrContent `description:"Defines a list of CA secret used to validate self-signed certificate" json:"rootCAs,omitempty" toml:"rootCAs,omitempty" yaml:"rootCAs,omitempty"`
-	Certificates        traefiktls.Certificates    `description:"Defines a list of secret storing client certificates for mTLS." json:"certificates,omitempty" toml:"certificates,omitempty" yaml:"certificates,omitempty" export:"true"`
-	MaxIdleConnsPerHost int                        `description:"If non-zero, controls the maximum idle (keep-alive) to keep per-host. If zero, DefaultMaxIdleConnsPerHost is used" json:"maxIdleConnsPerHost,omitempty" toml:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty" export:"true"`
-	ForwardingTimeouts  *ForwardingTimeouts        `description:"Defines the timeouts for requests forwarded to the backend servers." json:"forwardingTimeouts,omitempty" toml:"forwardingTimeouts,omitempty" yaml:"forwardingTimeouts,omitempty" export:"true"`
-	DisableHTTP2        bool                       `description:"Disables HTTP/2 for connections with backend servers." json:"disableHTTP2,omitempty" toml:"disableHTTP2,omitempty" yaml:"disableHTTP2,omitempty" export:"true"`
-	PeerCertURI         string                     `description:"Defines the URI used to match against SAN URI during the peer certificate verification." json:"peerCertURI,omitempty" toml:"peerCertURI,omitempty" yaml:"peerCertURI,omitempty" export:"true"`
-	Spiffe              *Spiffe                    `description:"Defines the SPIFFE configuration." json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	ServerName                 string                     `description:"Defines the serverName used to contact the server." json:"serverName,omitempty" toml:"serverName,omitempty" yaml:"serverName,omitempty"`
+	InsecureSkipVerify         bool                       `description:"Disables SSL certificate verification." json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" export:"true"`
+	RootCAs                    []traefiktls.FileOrContent `description:"Defines a list of CA secret used to validate self-signed certificate" json:"rootCAs,omitempty" toml:"rootCAs,omitempty" yaml:"rootCAs,omitempty"`
+	Certificates               traefiktls.Certificates    `description:"Defines a list of secret storing client certificates for mTLS." json:"certificates,omitempty" toml:"certificates,omitempty" yaml:"certificates,omitempty" export:"true"`
+	MaxIdleConnsPerHost        int                        `description:"If non-zero, controls the maximum idle (keep-alive) to keep per-host. If zero, DefaultMaxIdleConnsPerHost is used" json:"maxIdleConnsPerHost,omitempty" toml:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty" export:"true"`
+	MaxIdleConns               int                        `description:"If non-zero, controls the maximum idle (keep-alive) connections to keep across all hosts. If zero, there is no limit." json:"maxIdleConns,omitempty" toml:"maxIdleConns,omitempty" yaml:"maxIdleConns,omitempty" export:"true"`
+	MaxConnsPerHost            int                        `description:"If non-zero, limits the number of connections per host, including the connections being dialed, active, and idle. The requests beyond the limit wait for a connection. If zero, there is no limit." json:"maxConnsPerHost,omitempty" toml:"maxConnsPerHost,omitempty" yaml:"maxConnsPerHost,omitempty" export:"true"`
+	ForwardingTimeouts         *ForwardingTimeouts        `description:"Defines the timeouts for requests forwarded to the backend servers." json:"forwardingTimeouts,omitempty" toml:"forwardingTimeouts,omitempty" yaml:"forwardingTimeouts,omitempty" export:"true"`
+	DisableHTTP2               bool                       `description:"Disables HTTP/2 for connections with backend servers." json:"disableHTTP2,omitempty" toml:"disableHTTP2,omitempty" yaml:"disableHTTP2,omitempty" export:"true"`
+	StrictMaxConcurrentStreams bool                       `description:"Limits the concurrent HTTP/2 requests to a server to the concurrent streams it allows on a single connection, the requests beyond the limit waiting for a stream instead of opening new connections." json:"strictMaxConcurrentStreams,omitempty" toml:"strictMaxConcurrentStreams,omitempty" yaml:"strictMaxConcurrentStreams,omitempty" export:"true"`
+	PeerCertURI                string                     `description:"Defines the URI used to match against SAN URI during the peer certificate verification." json:"peerCertURI,omitempty" toml:"peerCertURI,omitempty" yaml:"peerCertURI,omitempty" export:"true"`
+	Spiffe                     *Spiffe                    `description:"Defines the SPIFFE configuration." json:"spiffe,omitempty" toml:"spiffe,omitempty" yaml:"spiffe,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -1,6 +1,7 @@
 package dynamic
 
 import (
+	"net/http"
 	"time"
 
 	ptypes "github.com/traefik/paerser/types"
@@ -27,6 +28,8 @@ type Middleware struct {
 	BasicAuth         *BasicAuth         `json:"basicAuth,omitempty" toml:"basicAuth,omitempty" yaml:"basicAuth,omitempty" export:"true"`
 	DigestAuth        *DigestAuth        `json:"digestAuth,omitempty" toml:"digestAuth,omitempty" yaml:"digestAuth,omitempty" export:"true"`
 	ForwardAuth       *ForwardAuth       `json:"forwardAuth,omitempty" toml:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty" export:"true"`
+	APIKeyAuth        *APIKeyAuth        `json:"apiKeyAuth,omitempty" toml:"apiKeyAuth,omitempty" yaml:"apiKeyAuth,omitempty" export:"true"`
+	OIDC              *OIDC              `json:"oidc,omitempty" toml:"oidc,omitempty" yaml:"oidc,omitempty" export:"true"`
 	InFlightReq       *InFlightReq       `json:"inFlightReq,omitempty" toml:"inFlightReq,omitempty" yaml:"inFlightReq,omitempty" export:"true"`
 	Buffering         *Buffering         `json:"buffering,omitempty" toml:"buffering,omitempty" yaml:"buffering,omitempty" export:"true"`
 	CircuitBreaker    *CircuitBreaker    `json:"circuitBreaker,omitempty" toml:"circuitBreaker,omitempty" yaml:"circuitBreaker,omitempty" export:"true"`
@@ -35,12 +38,48 @@ type Middleware struct {
 	Retry             *Retry             `json:"retry,omitempty" toml:"retry,omitempty" yaml:"retry,omitempty" export:"true"`
 	ContentType       *ContentType       `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 	GrpcWeb           *GrpcWeb           `json:"grpcWeb,omitempty" toml:"grpcWeb,omitempty" yaml:"grpcWeb,omitempty" export:"true"`
+	RequestLimits     *RequestLimits     `json:"requestLimits,omitempty" toml:"requestLimits,omitempty" yaml:"requestLimits,omitempty" export:"true"`
+	GeoIP             *GeoIP             `json:"geoIP,omitempty" toml:"geoIP,omitempty" yaml:"geoIP,omitempty" export:"true"`
+	Cache             *Cache             `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	RewriteBody       *RewriteBody       `json:"rewriteBody,omitempty" toml:"rewriteBody,omitempty" yaml:"rewriteBody,omitempty" export:"true"`
+	Priority          *Priority          `json:"priority,omitempty" toml:"priority,omitempty" yaml:"priority,omitempty" export:"true"`
+	RequestID         *RequestID         `json:"requestID,omitempty" toml:"requestID,omitempty" yaml:"requestID,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	Maintenance       *Maintenance       `json:"maintenance,omitempty" toml:"maintenance,omitempty" yaml:"maintenance,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	WAF               *WAF               `json:"waf,omitempty" toml:"waf,omitempty" yaml:"waf,omitempty" export:"true"`
+	BotDetection      *BotDetection      `json:"botDetection,omitempty" toml:"botDetection,omitempty" yaml:"botDetection,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
 
 	Plugin map[string]PluginConf `json:"plugin,omitempty" toml:"plugin,omitempty" yaml:"plugin,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
 
+// GeoIP holds the geoIP middleware configuration.
+// This middleware accepts / refuses requests, and adds headers with their country and autonomous system,
+// based on the country and the autonomous system of the client IP.
+type GeoIP struct {
+	// DatabaseFile defines the path of the MaxMind database (MMDB) file, e.g. a GeoLite2 Country database.
+	// The database is reloaded when the file changes.
+	DatabaseFile string `json:"databaseFile,omitempty" toml:"databaseFile,omitempty" yaml:"databaseFile,omitempty"`
+	// ASNDatabaseFile defines the path of the MaxMind database (MMDB) file of the autonomous systems, e.g. a GeoLite2 ASN database.
+	// The database is reloaded when the file changes.
+	ASNDatabaseFile string `json:"asnDatabaseFile,omitempty" toml:"asnDatabaseFile,omitempty" yaml:"asnDatabaseFile,omitempty"`
+	// AllowedCountries defines the ISO 3166-1 alpha-2 codes of the countries whose requests are accepted, all the others being refused.
+	AllowedCountries []string `json:"allowedCountries,omitempty" toml:"allowedCountries,omitempty" yaml:"allowedCountries,omitempty" export:"true"`
+	// BlockedCountries defines the ISO 3166-1 alpha-2 codes of the countries whose requests are refused.
+	BlockedCountries []string `json:"blockedCountries,omitempty" toml:"blockedCountries,omitempty" yaml:"blockedCountries,omitempty" export:"true"`
+	// AllowUnknown accepts the requests whose country is unknown, e.g. from a private network, despite the AllowedCountries.
+	AllowUnknown bool `json:"allowUnknown,omitempty" toml:"allowUnknown,omitempty" yaml:"allowUnknown,omitempty" export:"true"`
+	// CountryHeader defines the name of the header set with the country code of the accepted requests, empty for none.
+	CountryHeader string `json:"countryHeader,omitempty" toml:"countryHeader,omitempty" yaml:"countryHeader,omitempty" export:"true"`
+	// ASNHeader defines the name of the header set with the autonomous system number of the accepted requests, empty for none.
+	ASNHeader string `json:"asnHeader,omitempty" toml:"asnHeader,omitempty" yaml:"asnHeader,omitempty" export:"true"`
+	// ASNOrganizationHeader defines the name of the header set with the autonomous system organization of the accepted requests, empty for none.
+	ASNOrganizationHeader string      `json:"asnOrganizationHeader,omitempty" toml:"asnOrganizationHeader,omitempty" yaml:"asnOrganizationHeader,omitempty" export:"true"`
+	IPStrategy            *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // GrpcWeb holds the gRPC web middleware configuration.
 // This middleware converts a gRPC web request to an HTTP/2 gRPC request.
 type GrpcWeb struct {
@@ -69,6 +108,105 @@ type AddPrefix struct {
 
 // +k8s:deepcopy-gen=true
 
+// APIKeyAuth holds the API key auth middleware configuration.
+// This middleware restricts access to your services to requests carrying a known API key,
+// the keys and their rate limits being read from a Nomad Variable.
+type APIKeyAuth struct {
+	// HeaderName defines the request header carrying the API key.
+	// Default: X-API-Key.
+	HeaderName string `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty" export:"true"`
+	// RemoveHeader defines whether to remove the API key header before forwarding the request to your service.
+	// Default: false.
+	RemoveHeader bool `json:"removeHeader,omitempty" toml:"removeHeader,omitempty" yaml:"removeHeader,omitempty" export:"true"`
+	// HeaderField defines a header field to store the name of the authenticated API key.
+	HeaderField string `json:"headerField,omitempty" toml:"headerField,omitempty" yaml:"headerField,omitempty" export:"true"`
+	// Nomad defines the Nomad Variable holding the API keys.
+	Nomad *APIKeyAuthNomad `json:"nomad,omitempty" toml:"nomad,omitempty" yaml:"nomad,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an APIKeyAuth.
+func (a *APIKeyAuth) SetDefaults() {
+	a.HeaderName = "X-API-Key"
+}
+
+// +k8s:deepcopy-gen=true
+
+// APIKeyAuthNomad holds the Nomad Variable configuration of the API key auth middleware.
+type APIKeyAuthNomad struct {
+	// Address is the address of the Nomad server.
+	// Default: the NOMAD_ADDR environment variable, or http://127.0.0.1:4646.
+	Address string `json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	// Token is the ACL token used to read the Nomad Variable.
+	// Default: the NOMAD_TOKEN environment variable.
+	Token string `json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" loggable:"false"`
+	// Namespace is the namespace of the Nomad Variable.
+	// Default: default.
+	Namespace string `json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	// Region is the region of the Nomad Variable.
+	Region string `json:"region,omitempty" toml:"region,omitempty" yaml:"region,omitempty" export:"true"`
+	// Path is the path of the Nomad Variable.
+	// Each item of the Variable declares an API key, the item name being the key name and the item value the key.
+	// The <name>.average, <name>.period and <name>.burst items optionally define the rate limit of the <name> key.
+	Path string `json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty" export:"true"`
+	// RefreshInterval defines the interval between two reads of the Nomad Variable.
+	// Default: 30s.
+	RefreshInterval ptypes.Duration `json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an APIKeyAuthNomad.
+func (a *APIKeyAuthNomad) SetDefaults() {
+	a.Namespace = "default"
+	a.RefreshInterval = ptypes.Duration(30 * time.Second)
+}
+
+// +k8s:deepcopy-gen=true
+
+// OIDC holds the OpenID Connect auth middleware configuration.
+// This middleware authenticates the users with an OpenID Connect provider, using the authorization code flow,
+// and keeps them authenticated with an encrypted session cookie.
+type OIDC struct {
+	// Issuer defines the URL of the OpenID Connect provider, whose configuration is discovered at <issuer>/.well-known/openid-configuration.
+	Issuer string `json:"issuer,omitempty" toml:"issuer,omitempty" yaml:"issuer,omitempty"`
+	// ClientID defines the client identifier registered with the OpenID Connect provider.
+	ClientID string `json:"clientID,omitempty" toml:"clientID,omitempty" yaml:"clientID,omitempty"`
+	// ClientSecret defines the client secret registered with the OpenID Connect provider.
+	ClientSecret string `json:"clientSecret,omitempty" toml:"clientSecret,omitempty" yaml:"clientSecret,omitempty" loggable:"false"`
+	// Scopes defines the scopes requested to the OpenID Connect provider.
+	// Default: openid, profile, email.
+	Scopes []string `json:"scopes,omitempty" toml:"scopes,omitempty" yaml:"scopes,omitempty" export:"true"`
+	// RedirectURL defines the URL where the OpenID Connect provider redirects the users after their authentication.
+	// Default: the CallbackPath on the host and scheme of the request.
+	RedirectURL string `json:"redirectURL,omitempty" toml:"redirectURL,omitempty" yaml:"redirectURL,omitempty"`
+	// CallbackPath defines the path handled by the middleware to complete the authentication.
+	// Default: /oauth2/callback.
+	CallbackPath string `json:"callbackPath,omitempty" toml:"callbackPath,omitempty" yaml:"callbackPath,omitempty" export:"true"`
+	// LogoutPath defines a path handled by the middleware to remove the session cookie, empty for none.
+	LogoutPath string `json:"logoutPath,omitempty" toml:"logoutPath,omitempty" yaml:"logoutPath,omitempty" export:"true"`
+	// SessionSecret defines the secret used to encrypt the session cookie.
+	SessionSecret string `json:"sessionSecret,omitempty" toml:"sessionSecret,omitempty" yaml:"sessionSecret,omitempty" loggable:"false"`
+	// SessionCookieName defines the name of the session cookie.
+	// Default: traefik_oidc.
+	SessionCookieName string `json:"sessionCookieName,omitempty" toml:"sessionCookieName,omitempty" yaml:"sessionCookieName,omitempty" export:"true"`
+	// SessionMaxAge defines the duration after which the users have to authenticate again,
+	// even though their tokens can still be refreshed.
+	// Default: 24h.
+	SessionMaxAge ptypes.Duration `json:"sessionMaxAge,omitempty" toml:"sessionMaxAge,omitempty" yaml:"sessionMaxAge,omitempty" export:"true"`
+	// ClaimsHeaders defines the request headers set with the claims of the ID token, indexed by header name.
+	ClaimsHeaders map[string]string `json:"claimsHeaders,omitempty" toml:"claimsHeaders,omitempty" yaml:"claimsHeaders,omitempty" export:"true"`
+	// TLS defines the configuration used to secure the connection to the OpenID Connect provider.
+	TLS *types.ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on an OIDC.
+func (o *OIDC) SetDefaults() {
+	o.Scopes = []string{"openid", "profile", "email"}
+	o.CallbackPath = "/oauth2/callback"
+	o.SessionCookieName = "traefik_oidc"
+	o.SessionMaxAge = ptypes.Duration(24 * time.Hour)
+}
+
+// +k8s:deepcopy-gen=true
+
 // BasicAuth holds the basic auth middleware configuration.
 // This middleware restricts access to your services to known users.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/basicauth/
@@ -92,6 +230,67 @@ type BasicAuth struct {
 
 // +k8s:deepcopy-gen=true
 
+// BotDetection holds the bot detection middleware configuration.
+// This middleware blocks or tarpits the requests of the automated clients detected by their headers,
+// and optionally has the other clients solve a proof-of-work challenge before forwarding their requests.
+type BotDetection struct {
+	// Action defines what happens to the requests of the detected bots:
+	// block (default) refuses them, and tarpit refuses them after the TarpitDelay.
+	Action string `json:"action,omitempty" toml:"action,omitempty" yaml:"action,omitempty" export:"true"`
+	// TarpitDelay defines how long the requests of the detected bots are held with the tarpit action.
+	// Default: 10s.
+	TarpitDelay ptypes.Duration `json:"tarpitDelay,omitempty" toml:"tarpitDelay,omitempty" yaml:"tarpitDelay,omitempty" export:"true"`
+	// BlockedUserAgents defines the case-insensitive regular expressions matching the User-Agent of the bots.
+	// The requests without User-Agent are always considered coming from a bot.
+	// Default: the user agents of well-known vulnerability scanners.
+	BlockedUserAgents []string `json:"blockedUserAgents,omitempty" toml:"blockedUserAgents,omitempty" yaml:"blockedUserAgents,omitempty" export:"true"`
+	// AllowedUserAgents defines the case-insensitive regular expressions matching the User-Agent of the clients
+	// whose requests are forwarded without detection nor challenge, e.g. the monitoring probes.
+	AllowedUserAgents []string `json:"allowedUserAgents,omitempty" toml:"allowedUserAgents,omitempty" yaml:"allowedUserAgents,omitempty" export:"true"`
+	// RequiredHeaders defines the headers sent by all the browsers, the requests missing one of them being considered coming from a bot.
+	RequiredHeaders []string `json:"requiredHeaders,omitempty" toml:"requiredHeaders,omitempty" yaml:"requiredHeaders,omitempty" export:"true"`
+	// IPStrategy defines how the IP of the client, to which the challenges and the cookies are bound, is determined.
+	IPStrategy *IPStrategy `json:"ipStrategy,omitempty" toml:"ipStrategy,omitempty" yaml:"ipStrategy,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	// Challenge defines the proof-of-work challenge the clients have to solve before their requests are forwarded.
+	Challenge *BotChallenge `json:"challenge,omitempty" toml:"challenge,omitempty" yaml:"challenge,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// SetDefaults sets the default values on a BotDetection.
+func (b *BotDetection) SetDefaults() {
+	b.Action = "block"
+	b.TarpitDelay = ptypes.Duration(10 * time.Second)
+	b.BlockedUserAgents = []string{"sqlmap", "nikto", "nmap", "masscan", "zgrab", "nuclei", "gobuster", "dirbuster", "wpscan", "acunetix", "netsparker"}
+}
+
+// +k8s:deepcopy-gen=true
+
+// BotChallenge holds the proof-of-work challenge configuration of the bot detection middleware.
+// The clients solving the challenge get a signed cookie, their requests being forwarded until it expires.
+type BotChallenge struct {
+	// Secret defines the secret used to sign the challenges and the cookies,
+	// to keep them valid across configuration changes and restarts, and to share them between several routers and Traefik instances.
+	// Default: a random secret, generated for each middleware instance.
+	Secret string `json:"secret,omitempty" toml:"secret,omitempty" yaml:"secret,omitempty" loggable:"false"`
+	// Difficulty defines the number of leading zero bits of the hash of the solution, each one doubling the work of the clients.
+	// Default: 16.
+	Difficulty int `json:"difficulty,omitempty" toml:"difficulty,omitempty" yaml:"difficulty,omitempty" export:"true"`
+	// TTL defines how long the cookie of a solved challenge is valid.
+	// Default: 1h.
+	TTL ptypes.Duration `json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty" export:"true"`
+	// CookieName defines the name of the cookie of a solved challenge.
+	// Default: traefik_bot.
+	CookieName string `json:"cookieName,omitempty" toml:"cookieName,omitempty" yaml:"cookieName,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a BotChallenge.
+func (b *BotChallenge) SetDefaults() {
+	b.Difficulty = 16
+	b.TTL = ptypes.Duration(time.Hour)
+	b.CookieName = "traefik_bot"
+}
+
+// +k8s:deepcopy-gen=true
+
 // Buffering holds the buffering middleware configuration.
 // This middleware retries or limits the size of requests that can be forwarded to backends.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/buffering/#maxrequestbodybytes
@@ -114,6 +313,49 @@ type Buffering struct {
 	// It is a logical combination of functions with operators AND (&&) and OR (||).
 	// More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/buffering/#retryexpression
 	RetryExpression string `json:"retryExpression,omitempty" toml:"retryExpression,omitempty" yaml:"retryExpression,omitempty" export:"true"`
+	// RequestOnly restricts the buffering to the request bodies, the responses being streamed to the client.
+	// The request bodies larger than MemRequestBodyBytes are spooled to temporary files in the SpoolDirectory.
+	// The response buffering options and the retry expression do not apply.
+	RequestOnly bool `json:"requestOnly,omitempty" toml:"requestOnly,omitempty" yaml:"requestOnly,omitempty" export:"true"`
+	// SpoolDirectory defines the directory of the temporary files of the request bodies, with the RequestOnly option.
+	// Default: the temporary directory of the system.
+	SpoolDirectory string `json:"spoolDirectory,omitempty" toml:"spoolDirectory,omitempty" yaml:"spoolDirectory,omitempty" export:"true"`
+}
+
+// Defaults of the sizes of the Cache middleware.
+const (
+	DefaultCacheMaxSize      = 64 << 20
+	DefaultCacheMaxEntrySize = 1 << 20
+)
+
+// +k8s:deepcopy-gen=true
+
+// Cache holds the cache middleware configuration.
+// This middleware stores the cacheable responses, and serves them to the next requests while they are fresh.
+type Cache struct {
+	// MaxSize defines the maximum size (in bytes) of the responses kept in memory, the least recently used ones being evicted first.
+	// It does not apply to the responses stored in Redis.
+	// Default: 67108864 (64Mi).
+	MaxSize int64 `json:"maxSize,omitempty" toml:"maxSize,omitempty" yaml:"maxSize,omitempty" export:"true"`
+	// MaxEntrySize defines the maximum body size (in bytes) of a stored response, the larger responses not being stored.
+	// Default: 1048576 (1Mi).
+	MaxEntrySize int64 `json:"maxEntrySize,omitempty" toml:"maxEntrySize,omitempty" yaml:"maxEntrySize,omitempty" export:"true"`
+	// DefaultTTL defines the freshness lifetime of the cacheable responses without expiration time (max-age, s-maxage, or Expires).
+	// Default: 0 (these responses are not stored).
+	DefaultTTL ptypes.Duration `json:"defaultTTL,omitempty" toml:"defaultTTL,omitempty" yaml:"defaultTTL,omitempty" export:"true"`
+	// StaleWhileRevalidate defines how long a stale response is served while it is revalidated in the background,
+	// for the responses without stale-while-revalidate directive.
+	// Default: 0 (the stale responses are not served).
+	StaleWhileRevalidate ptypes.Duration `json:"staleWhileRevalidate,omitempty" toml:"staleWhileRevalidate,omitempty" yaml:"staleWhileRevalidate,omitempty" export:"true"`
+	// Redis defines the Redis server storing the responses, to share them between several Traefik instances.
+	// If not set, the responses are kept in memory.
+	Redis *Redis `json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+}
+
+// SetDefaults sets the default values on a Cache.
+func (c *Cache) SetDefaults() {
+	c.MaxSize = DefaultCacheMaxSize
+	c.MaxEntrySize = DefaultCacheMaxEntrySize
 }
 
 // +k8s:deepcopy-gen=true
@@ -151,15 +393,28 @@ func (c *CircuitBreaker) SetDefaults() {
 // +k8s:deepcopy-gen=true
 
 // Compress holds the compress middleware configuration.
-// This middleware compresses responses before sending them to the client, using gzip compression.
+// This middleware compresses responses before sending them to the client, using gzip, Brotli or Zstandard compression.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/compress/
 type Compress struct {
 	// ExcludedContentTypes defines the list of content types to compare the Content-Type header of the incoming requests and responses before compressing.
 	// `application/grpc` is always excluded.
 	ExcludedContentTypes []string `json:"excludedContentTypes,omitempty" toml:"excludedContentTypes,omitempty" yaml:"excludedContentTypes,omitempty" export:"true"`
+	// IncludedContentTypes defines the list of content types to compare the Content-Type header of the responses before compressing.
+	// When set, only the responses with one of these content types are compressed.
+	// It cannot be used along with excludedContentTypes.
+	IncludedContentTypes []string `json:"includedContentTypes,omitempty" toml:"includedContentTypes,omitempty" yaml:"includedContentTypes,omitempty" export:"true"`
 	// MinResponseBodyBytes defines the minimum amount of bytes a response body must have to be compressed.
 	// Default: 1024.
 	MinResponseBodyBytes int `json:"minResponseBodyBytes,omitempty" toml:"minResponseBodyBytes,omitempty" yaml:"minResponseBodyBytes,omitempty" export:"true"`
+	// Encodings defines the list of the compression algorithms of the responses, in order of preference, among br, zstd and gzip.
+	// Default: br, zstd, gzip.
+	Encodings []string `json:"encodings,omitempty" toml:"encodings,omitempty" yaml:"encodings,omitempty" export:"true"`
+	// GzipLevel defines the gzip compression level, from 1 (fastest) to 9 (smallest).
+	GzipLevel int `json:"gzipLevel,omitempty" toml:"gzipLevel,omitempty" yaml:"gzipLevel,omitempty" export:"true"`
+	// BrotliLevel defines the Brotli compression level, from 0 (fastest) to 11 (smallest). Default: 6.
+	BrotliLevel int `json:"brotliLevel,omitempty" toml:"brotliLevel,omitempty" yaml:"brotliLevel,omitempty" export:"true"`
+	// ZstdLevel defines the Zstandard compression level, from 1 (fastest) to 22 (smallest). Default: 3.
+	ZstdLevel int `json:"zstdLevel,omitempty" toml:"zstdLevel,omitempty" yaml:"zstdLevel,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -221,8 +476,46 @@ type ForwardAuth struct {
 	// AuthRequestHeaders defines the list of the headers to copy from the request to the authentication server.
 	// If not set or empty then all request headers are passed.
 	AuthRequestHeaders []string `json:"authRequestHeaders,omitempty" toml:"authRequestHeaders,omitempty" yaml:"authRequestHeaders,omitempty" export:"true"`
+	// ForwardBody defines whether to send the request body to the authentication server.
+	ForwardBody bool `json:"forwardBody,omitempty" toml:"forwardBody,omitempty" yaml:"forwardBody,omitempty" export:"true"`
+	// MaxBodySize defines the maximum size (in bytes) of the request body sent to the authentication server, the larger bodies being truncated.
+	// Default: 1048576 (1Mi).
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+	// Timeout defines the maximum duration of the calls to the authentication server.
+	// Default: 30s.
+	Timeout ptypes.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	// FailureMode defines what happens to the request when the authentication server cannot be reached, or answers with a 5XX status code:
+	// closed (default) refuses the request, and open forwards it to the service without authentication.
+	FailureMode string `json:"failureMode,omitempty" toml:"failureMode,omitempty" yaml:"failureMode,omitempty" export:"true"`
+	// Cache defines the cache of the accepted requests, which are not authenticated again by the authentication server until the decision expires.
+	Cache *ForwardAuthCache `json:"cache,omitempty" toml:"cache,omitempty" yaml:"cache,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// ForwardAuthCache holds the configuration of the cache of the decisions of the authentication server.
+// Only the accepted requests are cached, with the headers of the authentication response.
+type ForwardAuthCache struct {
+	// TTL defines how long an accepted request is not authenticated again.
+	TTL ptypes.Duration `json:"ttl,omitempty" toml:"ttl,omitempty" yaml:"ttl,omitempty" export:"true"`
+	// Key defines the request attributes identifying the requests sharing a decision:
+	// host, method, path, query, clientip, header:<name>, and cookie:<name>.
+	// Default: host, method, path, header:Authorization, and header:Cookie.
+	Key []string `json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty" export:"true"`
+	// MaxEntries defines the maximum number of cached decisions.
+	// Default: 10000.
+	MaxEntries int `json:"maxEntries,omitempty" toml:"maxEntries,omitempty" yaml:"maxEntries,omitempty" export:"true"`
 }
 
+// DefaultForwardAuthMaxBodySize is the default maximum size of the request bodies sent to the authentication server.
+const DefaultForwardAuthMaxBodySize = 1 << 20
+
+// DefaultForwardAuthTimeout is the default maximum duration of the calls to the authentication server.
+const DefaultForwardAuthTimeout = 30 * time.Second
+
+// DefaultForwardAuthCacheMaxEntries is the default maximum number of decisions kept by the forward auth cache.
+const DefaultForwardAuthCacheMaxEntries = 10000
+
 // +k8s:deepcopy-gen=true
 
 // Headers holds the headers middleware configuration.
@@ -403,6 +696,32 @@ type InFlightReq struct {
 
 // +k8s:deepcopy-gen=true
 
+// Maintenance holds the maintenance middleware configuration.
+// This middleware responds with a static page instead of forwarding the requests, while the maintenance mode is enabled,
+// which can also be toggled through the API.
+type Maintenance struct {
+	// Enabled enables the maintenance mode, unless it is disabled through the API.
+	Enabled bool `json:"enabled,omitempty" toml:"enabled,omitempty" yaml:"enabled,omitempty" export:"true"`
+	// StatusCode defines the status code of the responses.
+	StatusCode int `json:"statusCode,omitempty" toml:"statusCode,omitempty" yaml:"statusCode,omitempty" export:"true"`
+	// ContentType defines the content type of the responses.
+	ContentType string `json:"contentType,omitempty" toml:"contentType,omitempty" yaml:"contentType,omitempty" export:"true"`
+	// Body defines the body of the responses.
+	Body string `json:"body,omitempty" toml:"body,omitempty" yaml:"body,omitempty" export:"true"`
+	// File defines the path of the file holding the body of the responses, read when the middleware is created.
+	File string `json:"file,omitempty" toml:"file,omitempty" yaml:"file,omitempty"`
+	// RetryAfter defines the duration set in the Retry-After header of the responses, none when zero.
+	RetryAfter ptypes.Duration `json:"retryAfter,omitempty" toml:"retryAfter,omitempty" yaml:"retryAfter,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (m *Maintenance) SetDefaults() {
+	m.StatusCode = http.StatusServiceUnavailable
+	m.ContentType = "text/html; charset=utf-8"
+}
+
+// +k8s:deepcopy-gen=true
+
 // PassTLSClientCert holds the pass TLS client cert middleware configuration.
 // This middleware adds the selected data from the passed client TLS certificate to a header.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/passtlsclientcert/
@@ -411,6 +730,27 @@ type PassTLSClientCert struct {
 	PEM bool `json:"pem,omitempty" toml:"pem,omitempty" yaml:"pem,omitempty" export:"true"`
 	// Info selects the specific client certificate details you want to add to the X-Forwarded-Tls-Client-Cert-Info header.
 	Info *TLSClientCertificateInfo `json:"info,omitempty" toml:"info,omitempty" yaml:"info,omitempty" export:"true"`
+	// SpiffeID sets the X-Forwarded-Tls-Client-Cert-Spiffe-Id header with the SPIFFE ID of the client certificate.
+	SpiffeID bool `json:"spiffeID,omitempty" toml:"spiffeID,omitempty" yaml:"spiffeID,omitempty" export:"true"`
+	// SANHeaders sets the X-Forwarded-Tls-Client-Cert-Dns-Sans, X-Forwarded-Tls-Client-Cert-Email-Sans,
+	// X-Forwarded-Tls-Client-Cert-Ip-Sans and X-Forwarded-Tls-Client-Cert-Uri-Sans headers with the SANs of the client certificate.
+	SANHeaders bool `json:"sanHeaders,omitempty" toml:"sanHeaders,omitempty" yaml:"sanHeaders,omitempty" export:"true"`
+	// Encoding defines the encoding of the info, SANs and SPIFFE ID headers values: query, path or none.
+	// Default: query.
+	Encoding string `json:"encoding,omitempty" toml:"encoding,omitempty" yaml:"encoding,omitempty" export:"true"`
+	// Allowed rejects the requests without a client certificate, or whose client certificate is not allowed.
+	Allowed *TLSClientCertificateAllowed `json:"allowed,omitempty" toml:"allowed,omitempty" yaml:"allowed,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TLSClientCertificateAllowed holds the client certificates allowed by the pass TLS client cert middleware.
+// A client certificate is allowed when its common name or one of its SANs is listed, or when both lists are empty.
+type TLSClientCertificateAllowed struct {
+	// CommonNames defines the allowed subject common names.
+	CommonNames []string `json:"commonNames,omitempty" toml:"commonNames,omitempty" yaml:"commonNames,omitempty" export:"true"`
+	// SANs defines the allowed DNS names, email addresses, IP addresses and URIs, e.g. SPIFFE IDs.
+	SANs []string `json:"sans,omitempty" toml:"sans,omitempty" yaml:"sans,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -424,6 +764,44 @@ type SourceCriterion struct {
 	RequestHeaderName string `json:"requestHeaderName,omitempty" toml:"requestHeaderName,omitempty" yaml:"requestHeaderName,omitempty" export:"true"`
 	// RequestHost defines whether to consider the request Host as the source.
 	RequestHost bool `json:"requestHost,omitempty" toml:"requestHost,omitempty" yaml:"requestHost,omitempty" export:"true"`
+	// RequestQueryParameterName defines the name of the query parameter used to group incoming requests.
+	RequestQueryParameterName string `json:"requestQueryParameterName,omitempty" toml:"requestQueryParameterName,omitempty" yaml:"requestQueryParameterName,omitempty" export:"true"`
+	// RequestJWTClaim defines the name of the claim, of the bearer token of the Authorization header, used to group incoming requests.
+	// The token signature is not verified, the token has to be authenticated by a previous middleware.
+	RequestJWTClaim string `json:"requestJWTClaim,omitempty" toml:"requestJWTClaim,omitempty" yaml:"requestJWTClaim,omitempty" export:"true"`
+	// RequestClientCertCN defines whether to consider the common name of the client certificate as the source.
+	RequestClientCertCN bool `json:"requestClientCertCN,omitempty" toml:"requestClientCertCN,omitempty" yaml:"requestClientCertCN,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// Priority holds the priority middleware configuration.
+// This middleware classifies the requests into priority classes,
+// and sheds the low priority ones with a 429 Too Many Requests when the concurrency or latency budget is exceeded.
+type Priority struct {
+	// Classes defines the priority classes, the class of a request being the first one it matches.
+	// The requests matching no class have a priority of 0.
+	Classes []PriorityClass `json:"classes,omitempty" toml:"classes,omitempty" yaml:"classes,omitempty" export:"true"`
+	// MaxInFlight defines the maximum number of requests processed concurrently.
+	// Each priority, from the lowest one, is only allowed its share of them, the highest priority being allowed all of them.
+	MaxInFlight int64 `json:"maxInFlight,omitempty" toml:"maxInFlight,omitempty" yaml:"maxInFlight,omitempty" export:"true"`
+	// MaxLatency defines the average latency of the responses above which only the requests of the highest priority are processed.
+	MaxLatency ptypes.Duration `json:"maxLatency,omitempty" toml:"maxLatency,omitempty" yaml:"maxLatency,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// PriorityClass holds a priority class of the priority middleware.
+// A request matches the class when it matches all of its headers, and one of its path prefixes.
+type PriorityClass struct {
+	// Name defines the name of the class, reported in the logs.
+	Name string `json:"name,omitempty" toml:"name,omitempty" yaml:"name,omitempty" export:"true"`
+	// Priority defines the priority of the requests of the class, the higher the more important.
+	Priority int `json:"priority,omitempty" toml:"priority,omitempty" yaml:"priority,omitempty" export:"true"`
+	// Headers defines the regular expressions the values of the headers of the requests must match, indexed by header name.
+	Headers map[string]string `json:"headers,omitempty" toml:"headers,omitempty" yaml:"headers,omitempty" export:"true"`
+	// PathPrefixes defines the path prefixes of the requests, any path matching when empty.
+	PathPrefixes []string `json:"pathPrefixes,omitempty" toml:"pathPrefixes,omitempty" yaml:"pathPrefixes,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -449,6 +827,10 @@ type RateLimit struct {
 	// If several strategies are defined at the same time, an error will be raised.
 	// If none are set, the default is to use the request's remote address field (as an ipStrategy).
 	SourceCriterion *SourceCriterion `json:"sourceCriterion,omitempty" toml:"sourceCriterion,omitempty" yaml:"sourceCriterion,omitempty" export:"true"`
+
+	// Redis defines the Redis server storing the token buckets, to share them between several Traefik instances.
+	// If not set, the token buckets are kept in memory.
+	Redis *Redis `json:"redis,omitempty" toml:"redis,omitempty" yaml:"redis,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // SetDefaults sets the default values on a RateLimit.
@@ -459,6 +841,31 @@ func (r *RateLimit) SetDefaults() {
 
 // +k8s:deepcopy-gen=true
 
+// Redis holds the configuration of the Redis server of a middleware, storing the token buckets of a RateLimit middleware,
+// or the responses of a Cache middleware.
+type Redis struct {
+	// Endpoints defines the addresses of the Redis server, or of the Redis cluster nodes.
+	Endpoints []string `json:"endpoints,omitempty" toml:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	// TLS defines the TLS configuration used for the connection to Redis.
+	TLS *types.ClientTLS `json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	// Username defines the username used for the connection to Redis.
+	Username string `json:"username,omitempty" toml:"username,omitempty" yaml:"username,omitempty" loggable:"false"`
+	// Password defines the password used for the connection to Redis.
+	Password string `json:"password,omitempty" toml:"password,omitempty" yaml:"password,omitempty" loggable:"false"`
+	// DB defines the database selected after connecting to Redis.
+	DB int `json:"db,omitempty" toml:"db,omitempty" yaml:"db,omitempty" export:"true"`
+	// Timeout defines the maximum duration of the Redis operations, after which the middleware handles the request as if Redis was unavailable.
+	Timeout ptypes.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a Redis.
+func (r *Redis) SetDefaults() {
+	r.Endpoints = []string{"127.0.0.1:6379"}
+	r.Timeout = ptypes.Duration(500 * time.Millisecond)
+}
+
+// +k8s:deepcopy-gen=true
+
 // RedirectRegex holds the redirect regex middleware configuration.
 // This middleware redirects a request using regex matching and replacement.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/redirectregex/#regex
@@ -509,9 +916,48 @@ type ReplacePathRegex struct {
 
 // +k8s:deepcopy-gen=true
 
+// RequestID holds the request ID middleware configuration.
+// This middleware sets a unique ID on each request, keeping the one received from the client if any,
+// and reports it in the response, the access logs and the traces.
+type RequestID struct {
+	// HeaderName defines the name of the request header holding the request ID.
+	HeaderName string `json:"headerName,omitempty" toml:"headerName,omitempty" yaml:"headerName,omitempty" export:"true"`
+	// ResponseHeaderName defines the name of the response header set with the request ID, empty for none.
+	ResponseHeaderName string `json:"responseHeaderName,omitempty" toml:"responseHeaderName,omitempty" yaml:"responseHeaderName,omitempty" export:"true"`
+	// Format defines the format of the generated request IDs: uuid or ulid.
+	Format string `json:"format,omitempty" toml:"format,omitempty" yaml:"format,omitempty" export:"true"`
+	// Overwrite replaces the request IDs received from the clients with generated ones.
+	Overwrite bool `json:"overwrite,omitempty" toml:"overwrite,omitempty" yaml:"overwrite,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *RequestID) SetDefaults() {
+	r.HeaderName = "X-Request-Id"
+	r.ResponseHeaderName = "X-Request-Id"
+	r.Format = "uuid"
+}
+
+// +k8s:deepcopy-gen=true
+
+// RequestLimits holds the request limits middleware configuration.
+// This middleware limits the size of the request bodies and the duration of the requests, without buffering them.
+type RequestLimits struct {
+	// MaxBodyBytes defines the maximum size of the request body, in bytes.
+	// The requests with a larger body get a 413 Request Entity Too Large response.
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty" toml:"maxBodyBytes,omitempty" yaml:"maxBodyBytes,omitempty" export:"true"`
+	// Timeout defines the maximum duration of a request, including the transfer of the response body.
+	// The requests whose response headers are not received in time get a 504 Gateway Timeout response.
+	Timeout ptypes.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+	// WebSocketMaxLifetime defines the maximum lifetime of the WebSocket connections, which are then closed with a close frame.
+	// It overrides the maximum lifetime of the entry point.
+	WebSocketMaxLifetime ptypes.Duration `json:"webSocketMaxLifetime,omitempty" toml:"webSocketMaxLifetime,omitempty" yaml:"webSocketMaxLifetime,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
 // Retry holds the retry middleware configuration.
 // This middleware reissues requests a given number of times to a backend server if that server does not reply.
-// As soon as the server answers, the middleware stops retrying, regardless of the response status.
+// As soon as the server answers, the middleware stops retrying, unless the response status is one of the retried statuses.
 // More info: https://doc.traefik.io/traefik/v3.0/middlewares/http/retry/
 type Retry struct {
 	// Attempts defines how many times the request should be retried.
@@ -522,6 +968,71 @@ type Retry struct {
 	// The value of initialInterval should be provided in seconds or as a valid duration format,
 	// see https://pkg.go.dev/time#ParseDuration.
 	InitialInterval ptypes.Duration `json:"initialInterval,omitempty" toml:"initialInterval,omitempty" yaml:"initialInterval,omitempty" export:"true"`
+	// MaxInterval defines the maximum wait time between two attempts.
+	// When set, the wait time doubles after each attempt until it reaches maxInterval,
+	// instead of growing to twice the initialInterval.
+	MaxInterval ptypes.Duration `json:"maxInterval,omitempty" toml:"maxInterval,omitempty" yaml:"maxInterval,omitempty" export:"true"`
+	// Status defines the ranges of the response status codes, e.g. 502-504, for which the request is retried,
+	// although the backend server answered.
+	Status []string `json:"status,omitempty" toml:"status,omitempty" yaml:"status,omitempty" export:"true"`
+	// IdempotentOnly defines whether only the requests with an idempotent method, or an Idempotency-Key header, are retried.
+	IdempotentOnly bool `json:"idempotentOnly,omitempty" toml:"idempotentOnly,omitempty" yaml:"idempotentOnly,omitempty" export:"true"`
+	// Budget limits the retries to a percentage of the requests.
+	Budget *RetryBudget `json:"budget,omitempty" toml:"budget,omitempty" yaml:"budget,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// RetryBudget holds the retry budget configuration, limiting the retries to a percentage of the requests over a sliding window,
+// so that the retries do not overload the backend servers when they fail.
+type RetryBudget struct {
+	// Percent defines the maximum percentage of the requests which are retried over the window.
+	Percent int `json:"percent,omitempty" toml:"percent,omitempty" yaml:"percent,omitempty" export:"true"`
+	// MinRetries defines the number of retries allowed over the window regardless of the percentage,
+	// for the routers receiving few requests.
+	MinRetries int `json:"minRetries,omitempty" toml:"minRetries,omitempty" yaml:"minRetries,omitempty" export:"true"`
+	// Window defines the duration over which the requests and the retries are counted, 10s by default.
+	Window ptypes.Duration `json:"window,omitempty" toml:"window,omitempty" yaml:"window,omitempty" export:"true"`
+}
+
+// DefaultRetryBudgetWindow is the default duration over which the retry budget counts the requests and the retries.
+const DefaultRetryBudgetWindow = 10 * time.Second
+
+// DefaultRetryMaxBufferedBodySize is the maximum size of the request bodies buffered by the retry middleware,
+// to resend them when retrying on the response status. The requests with a larger body are not retried on the response status.
+const DefaultRetryMaxBufferedBodySize = 1 << 20
+
+// DefaultRewriteBodyMaxBodySize is the default maximum size of the bodies rewritten by the rewrite body middleware.
+const DefaultRewriteBodyMaxBodySize = 1 << 20
+
+// +k8s:deepcopy-gen=true
+
+// RewriteBody holds the rewrite body middleware configuration.
+// This middleware rewrites the bodies of the responses, e.g. the absolute URLs embedded by an application served under another host or path.
+type RewriteBody struct {
+	// Rewrites defines the rewrites, applied in order to the bodies.
+	Rewrites []BodyRewrite `json:"rewrites,omitempty" toml:"rewrites,omitempty" yaml:"rewrites,omitempty" export:"true"`
+	// ContentTypes defines the media types of the rewritten responses, e.g. text/html or text/*, the textual ones when empty.
+	ContentTypes []string `json:"contentTypes,omitempty" toml:"contentTypes,omitempty" yaml:"contentTypes,omitempty" export:"true"`
+	// MaxBodySize defines the maximum size of a rewritten body, in bytes.
+	// The responses with a larger body are forwarded unchanged.
+	MaxBodySize int64 `json:"maxBodySize,omitempty" toml:"maxBodySize,omitempty" yaml:"maxBodySize,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (r *RewriteBody) SetDefaults() {
+	r.MaxBodySize = DefaultRewriteBodyMaxBodySize
+}
+
+// +k8s:deepcopy-gen=true
+
+// BodyRewrite holds a rewrite of the rewrite body middleware.
+type BodyRewrite struct {
+	// Regex defines the regular expression matching the parts of the body to replace.
+	Regex string `json:"regex,omitempty" toml:"regex,omitempty" yaml:"regex,omitempty" export:"true"`
+	// Replacement defines the replacement, which can include the captured variables, e.g. $1,
+	// and is a Go template executed with the request, e.g. {{ .Host }}.
+	Replacement string `json:"replacement,omitempty" toml:"replacement,omitempty" yaml:"replacement,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -610,3 +1121,16 @@ type TLSClientCertificateSubjectDNInfo struct {
 
 // Users holds a list of users.
 type Users []string
+
+// +k8s:deepcopy-gen=true
+
+// WAF holds the web application firewall middleware configuration.
+// This middleware inspects the requests with the Coraza engine, and blocks the ones matching its SecLang rules.
+type WAF struct {
+	// CRS loads the OWASP Core Rule Set embedded in Traefik, with its recommended configuration, before the directives.
+	CRS bool `json:"crs,omitempty" toml:"crs,omitempty" yaml:"crs,omitempty" export:"true"`
+	// Directives defines the SecLang directives, which can load rule files with the Include directive.
+	Directives []string `json:"directives,omitempty" toml:"directives,omitempty" yaml:"directives,omitempty" export:"true"`
+	// DetectionOnly reports the requests matching the rules in the access logs, without blocking them.
+	DetectionOnly bool `json:"detectionOnly,omitempty" toml:"detectionOnly,omitempty" yaml:"detectionOnly,omitempty" export:"true"`
+}
@@ -17,6 +17,15 @@ type TCPConfiguration struct {
 	Services          map[string]*TCPService          `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty" export:"true"`
 	Middlewares       map[string]*TCPMiddleware       `json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty" export:"true"`
 	ServersTransports map[string]*TCPServersTransport `json:"serversTransports,omitempty" toml:"serversTransports,omitempty" yaml:"serversTransports,omitempty" label:"-" export:"true"`
+	EntryPoints       map[string]*TCPEntryPoint       `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPEntryPoint holds the configuration of an entrypoint declared by a provider,
+// listening on a port of the range allowed by the static configuration.
+type TCPEntryPoint struct {
+	Port int `json:"port,omitempty" toml:"port,omitempty" yaml:"port,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -1,11 +1,34 @@
 package dynamic
 
+import (
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+)
+
 // +k8s:deepcopy-gen=true
 
 // TCPMiddleware holds the TCPMiddleware configuration.
 type TCPMiddleware struct {
-	InFlightConn *TCPInFlightConn `json:"inFlightConn,omitempty" toml:"inFlightConn,omitempty" yaml:"inFlightConn,omitempty" export:"true"`
-	IPAllowList  *TCPIPAllowList  `json:"ipAllowList,omitempty" toml:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty" export:"true"`
+	IdleTimeout   *TCPIdleTimeout   `json:"idleTimeout,omitempty" toml:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	InFlightConn  *TCPInFlightConn  `json:"inFlightConn,omitempty" toml:"inFlightConn,omitempty" yaml:"inFlightConn,omitempty" export:"true"`
+	IPAllowList   *TCPIPAllowList   `json:"ipAllowList,omitempty" toml:"ipAllowList,omitempty" yaml:"ipAllowList,omitempty" export:"true"`
+	ProxyProtocol *TCPProxyProtocol `json:"proxyProtocol,omitempty" toml:"proxyProtocol,omitempty" yaml:"proxyProtocol,omitempty" label:"allowEmpty" file:"allowEmpty" kv:"allowEmpty" export:"true"`
+	RateLimit     *TCPRateLimit     `json:"rateLimit,omitempty" toml:"rateLimit,omitempty" yaml:"rateLimit,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPIdleTimeout holds the TCP IdleTimeout middleware configuration.
+// This middleware closes the connections on which no data is exchanged, in either direction, for a given time.
+type TCPIdleTimeout struct {
+	// Timeout defines how long a connection can be idle before being closed.
+	Timeout ptypes.Duration `json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a TCPIdleTimeout.
+func (i *TCPIdleTimeout) SetDefaults() {
+	i.Timeout = ptypes.Duration(180 * time.Second)
 }
 
 // +k8s:deepcopy-gen=true
@@ -28,3 +51,41 @@ type TCPIPAllowList struct {
 	// SourceRange defines the allowed IPs (or ranges of allowed IPs by using CIDR notation).
 	SourceRange []string `json:"sourceRange,omitempty" toml:"sourceRange,omitempty" yaml:"sourceRange,omitempty"`
 }
+
+// +k8s:deepcopy-gen=true
+
+// TCPProxyProtocol holds the TCP ProxyProtocol middleware configuration.
+// This middleware sends the PROXY protocol header to the servers of the service, unless the service sets its own.
+type TCPProxyProtocol struct {
+	// Version defines the PROXY protocol version to use, 1 or 2.
+	Version int `json:"version,omitempty" toml:"version,omitempty" yaml:"version,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a TCPProxyProtocol.
+func (p *TCPProxyProtocol) SetDefaults() {
+	p.Version = 2
+}
+
+// +k8s:deepcopy-gen=true
+
+// TCPRateLimit holds the TCP RateLimit middleware configuration.
+// This middleware limits the rate of the new connections for one IP, and closes the connections beyond it.
+type TCPRateLimit struct {
+	// Average is the maximum rate, by default in connections/s, allowed for one IP.
+	// The rate is actually defined by dividing Average by Period.
+	Average int64 `json:"average,omitempty" toml:"average,omitempty" yaml:"average,omitempty" export:"true"`
+
+	// Period, in combination with Average, defines the actual maximum rate, such as:
+	// r = Average / Period. It defaults to a second.
+	Period ptypes.Duration `json:"period,omitempty" toml:"period,omitempty" yaml:"period,omitempty" export:"true"`
+
+	// Burst is the maximum number of connections allowed to be opened in the same arbitrarily small period of time.
+	// It defaults to 1.
+	Burst int64 `json:"burst,omitempty" toml:"burst,omitempty" yaml:"burst,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values on a TCPRateLimit.
+func (r *TCPRateLimit) SetDefaults() {
+	r.Burst = 1
+	r.Period = ptypes.Duration(time.Second)
+}
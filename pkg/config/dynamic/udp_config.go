@@ -2,14 +2,25 @@ package dynamic
 
 import (
 	"reflect"
+
+	ptypes "github.com/traefik/paerser/types"
 )
 
 // +k8s:deepcopy-gen=true
 
 // UDPConfiguration contains all the UDP configuration parameters.
 type UDPConfiguration struct {
-	Routers  map[string]*UDPRouter  `json:"routers,omitempty" toml:"routers,omitempty" yaml:"routers,omitempty" export:"true"`
-	Services map[string]*UDPService `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty" export:"true"`
+	Routers     map[string]*UDPRouter     `json:"routers,omitempty" toml:"routers,omitempty" yaml:"routers,omitempty" export:"true"`
+	Services    map[string]*UDPService    `json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty" export:"true"`
+	EntryPoints map[string]*UDPEntryPoint `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
+}
+
+// +k8s:deepcopy-gen=true
+
+// UDPEntryPoint holds the configuration of a UDP entrypoint declared by a provider,
+// listening on a port of the range allowed by the static configuration.
+type UDPEntryPoint struct {
+	Port int `json:"port,omitempty" toml:"port,omitempty" yaml:"port,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
@@ -45,15 +56,18 @@ func (w *UDPWRRService) SetDefaults() {
 
 // UDPRouter defines the configuration for an UDP router.
 type UDPRouter struct {
-	EntryPoints []string `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
-	Service     string   `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
+	EntryPoints    []string        `json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
+	Service        string          `json:"service,omitempty" toml:"service,omitempty" yaml:"service,omitempty" export:"true"`
+	SessionTimeout ptypes.Duration `json:"sessionTimeout,omitempty" toml:"sessionTimeout,omitempty" yaml:"sessionTimeout,omitempty" export:"true"`
+	MaxSessions    int             `json:"maxSessions,omitempty" toml:"maxSessions,omitempty" yaml:"maxSessions,omitempty" export:"true"`
 }
 
 // +k8s:deepcopy-gen=true
 
 // UDPServersLoadBalancer defines the configuration for a load-balancer of UDP servers.
 type UDPServersLoadBalancer struct {
-	Servers []UDPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
+	Servers    []UDPServer `json:"servers,omitempty" toml:"servers,omitempty" yaml:"servers,omitempty" label-slice-as-struct:"server" export:"true"`
+	SourceHash bool        `json:"sourceHash,omitempty" toml:"sourceHash,omitempty" yaml:"sourceHash,omitempty" export:"true"`
 }
 
 // Mergeable reports whether the given load-balancer can be merged with the receiver.
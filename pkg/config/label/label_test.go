@@ -14,170 +14,187 @@ import (
 
 func TestDecodeConfiguration(t *testing.T) {
 	labels := map[string]string{
-		"traefik.http.middlewares.Middleware0.addprefix.prefix":                                    "foobar",
-		"traefik.http.middlewares.Middleware1.basicauth.headerfield":                               "foobar",
-		"traefik.http.middlewares.Middleware1.basicauth.realm":                                     "foobar",
-		"traefik.http.middlewares.Middleware1.basicauth.removeheader":                              "true",
-		"traefik.http.middlewares.Middleware1.basicauth.users":                                     "foobar, fiibar",
-		"traefik.http.middlewares.Middleware1.basicauth.usersfile":                                 "foobar",
-		"traefik.http.middlewares.Middleware2.buffering.maxrequestbodybytes":                       "42",
-		"traefik.http.middlewares.Middleware2.buffering.maxresponsebodybytes":                      "42",
-		"traefik.http.middlewares.Middleware2.buffering.memrequestbodybytes":                       "42",
-		"traefik.http.middlewares.Middleware2.buffering.memresponsebodybytes":                      "42",
-		"traefik.http.middlewares.Middleware2.buffering.retryexpression":                           "foobar",
-		"traefik.http.middlewares.Middleware3.chain.middlewares":                                   "foobar, fiibar",
-		"traefik.http.middlewares.Middleware4.circuitbreaker.expression":                           "foobar",
-		"traefik.HTTP.Middlewares.Middleware4.circuitbreaker.checkperiod":                          "1s",
-		"traefik.HTTP.Middlewares.Middleware4.circuitbreaker.fallbackduration":                     "1s",
-		"traefik.HTTP.Middlewares.Middleware4.circuitbreaker.recoveryduration":                     "1s",
-		"traefik.http.middlewares.Middleware5.digestauth.headerfield":                              "foobar",
-		"traefik.http.middlewares.Middleware5.digestauth.realm":                                    "foobar",
-		"traefik.http.middlewares.Middleware5.digestauth.removeheader":                             "true",
-		"traefik.http.middlewares.Middleware5.digestauth.users":                                    "foobar, fiibar",
-		"traefik.http.middlewares.Middleware5.digestauth.usersfile":                                "foobar",
-		"traefik.http.middlewares.Middleware6.errors.query":                                        "foobar",
-		"traefik.http.middlewares.Middleware6.errors.service":                                      "foobar",
-		"traefik.http.middlewares.Middleware6.errors.status":                                       "foobar, fiibar",
-		"traefik.http.middlewares.Middleware7.forwardauth.address":                                 "foobar",
-		"traefik.http.middlewares.Middleware7.forwardauth.authresponseheaders":                     "foobar, fiibar",
-		"traefik.http.middlewares.Middleware7.forwardauth.authrequestheaders":                      "foobar, fiibar",
-		"traefik.http.middlewares.Middleware7.forwardauth.tls.ca":                                  "foobar",
-		"traefik.http.middlewares.Middleware7.forwardauth.tls.cert":                                "foobar",
-		"traefik.http.middlewares.Middleware7.forwardauth.tls.insecureskipverify":                  "true",
-		"traefik.http.middlewares.Middleware7.forwardauth.tls.key":                                 "foobar",
-		"traefik.http.middlewares.Middleware7.forwardauth.trustforwardheader":                      "true",
-		"traefik.http.middlewares.Middleware8.headers.accesscontrolallowcredentials":               "true",
-		"traefik.http.middlewares.Middleware8.headers.allowedhosts":                                "foobar, fiibar",
-		"traefik.http.middlewares.Middleware8.headers.accesscontrolallowheaders":                   "X-foobar, X-fiibar",
-		"traefik.http.middlewares.Middleware8.headers.accesscontrolallowmethods":                   "GET, PUT",
-		"traefik.http.middlewares.Middleware8.headers.accesscontrolalloworiginList":                "foobar, fiibar",
-		"traefik.http.middlewares.Middleware8.headers.accesscontrolalloworiginListRegex":           "foobar, fiibar",
-		"traefik.http.middlewares.Middleware8.headers.accesscontrolexposeheaders":                  "X-foobar, X-fiibar",
-		"traefik.http.middlewares.Middleware8.headers.accesscontrolmaxage":                         "200",
-		"traefik.http.middlewares.Middleware8.headers.addvaryheader":                               "true",
-		"traefik.http.middlewares.Middleware8.headers.browserxssfilter":                            "true",
-		"traefik.http.middlewares.Middleware8.headers.contentsecuritypolicy":                       "foobar",
-		"traefik.http.middlewares.Middleware8.headers.contenttypenosniff":                          "true",
-		"traefik.http.middlewares.Middleware8.headers.custombrowserxssvalue":                       "foobar",
-		"traefik.http.middlewares.Middleware8.headers.customframeoptionsvalue":                     "foobar",
-		"traefik.http.middlewares.Middleware8.headers.customrequestheaders.name0":                  "foobar",
-		"traefik.http.middlewares.Middleware8.headers.customrequestheaders.name1":                  "foobar",
-		"traefik.http.middlewares.Middleware8.headers.customresponseheaders.name0":                 "foobar",
-		"traefik.http.middlewares.Middleware8.headers.customresponseheaders.name1":                 "foobar",
-		"traefik.http.middlewares.Middleware8.headers.forcestsheader":                              "true",
-		"traefik.http.middlewares.Middleware8.headers.framedeny":                                   "true",
-		"traefik.http.middlewares.Middleware8.headers.hostsproxyheaders":                           "foobar, fiibar",
-		"traefik.http.middlewares.Middleware8.headers.isdevelopment":                               "true",
-		"traefik.http.middlewares.Middleware8.headers.publickey":                                   "foobar",
-		"traefik.http.middlewares.Middleware8.headers.referrerpolicy":                              "foobar",
-		"traefik.http.middlewares.Middleware8.headers.permissionspolicy":                           "foobar",
-		"traefik.http.middlewares.Middleware8.headers.sslproxyheaders.name0":                       "foobar",
-		"traefik.http.middlewares.Middleware8.headers.sslproxyheaders.name1":                       "foobar",
-		"traefik.http.middlewares.Middleware8.headers.stsincludesubdomains":                        "true",
-		"traefik.http.middlewares.Middleware8.headers.stspreload":                                  "true",
-		"traefik.http.middlewares.Middleware8.headers.stsseconds":                                  "42",
-		"traefik.http.middlewares.Middleware9.ipallowlist.ipstrategy.depth":                        "42",
-		"traefik.http.middlewares.Middleware9.ipallowlist.ipstrategy.excludedips":                  "foobar, fiibar",
-		"traefik.http.middlewares.Middleware9.ipallowlist.sourcerange":                             "foobar, fiibar",
-		"traefik.http.middlewares.Middleware10.inflightreq.amount":                                 "42",
-		"traefik.http.middlewares.Middleware10.inflightreq.sourcecriterion.ipstrategy.depth":       "42",
-		"traefik.http.middlewares.Middleware10.inflightreq.sourcecriterion.ipstrategy.excludedips": "foobar, fiibar",
-		"traefik.http.middlewares.Middleware10.inflightreq.sourcecriterion.requestheadername":      "foobar",
-		"traefik.http.middlewares.Middleware10.inflightreq.sourcecriterion.requesthost":            "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.notafter":                    "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.notbefore":                   "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.sans":                        "true",
-		"traefik.http.middlewares.Middleware11.passTLSClientCert.info.serialNumber":                "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.commonname":          "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.country":             "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.domaincomponent":     "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.locality":            "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.organization":        "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.organizationalunit":  "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.province":            "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.serialnumber":        "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.commonname":           "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.country":              "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.domaincomponent":      "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.locality":             "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.organization":         "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.province":             "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.serialnumber":         "true",
-		"traefik.http.middlewares.Middleware11.passtlsclientcert.pem":                              "true",
-		"traefik.http.middlewares.Middleware12.ratelimit.average":                                  "42",
-		"traefik.http.middlewares.Middleware12.ratelimit.period":                                   "1s",
-		"traefik.http.middlewares.Middleware12.ratelimit.burst":                                    "42",
-		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.requestheadername":        "foobar",
-		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.requesthost":              "true",
-		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.ipstrategy.depth":         "42",
-		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.ipstrategy.excludedips":   "foobar, foobar",
-		"traefik.http.middlewares.Middleware13.redirectregex.permanent":                            "true",
-		"traefik.http.middlewares.Middleware13.redirectregex.regex":                                "foobar",
-		"traefik.http.middlewares.Middleware13.redirectregex.replacement":                          "foobar",
-		"traefik.http.middlewares.Middleware13b.redirectscheme.scheme":                             "https",
-		"traefik.http.middlewares.Middleware13b.redirectscheme.port":                               "80",
-		"traefik.http.middlewares.Middleware13b.redirectscheme.permanent":                          "true",
-		"traefik.http.middlewares.Middleware14.replacepath.path":                                   "foobar",
-		"traefik.http.middlewares.Middleware15.replacepathregex.regex":                             "foobar",
-		"traefik.http.middlewares.Middleware15.replacepathregex.replacement":                       "foobar",
-		"traefik.http.middlewares.Middleware16.retry.attempts":                                     "42",
-		"traefik.http.middlewares.Middleware16.retry.initialinterval":                              "1s",
-		"traefik.http.middlewares.Middleware17.stripprefix.prefixes":                               "foobar, fiibar",
-		"traefik.http.middlewares.Middleware18.stripprefixregex.regex":                             "foobar, fiibar",
-		"traefik.http.middlewares.Middleware19.compress.minresponsebodybytes":                      "42",
-		"traefik.http.middlewares.Middleware20.plugin.tomato.aaa":                                  "foo1",
-		"traefik.http.middlewares.Middleware20.plugin.tomato.bbb":                                  "foo2",
-		"traefik.http.routers.Router0.entrypoints":                                                 "foobar, fiibar",
-		"traefik.http.routers.Router0.middlewares":                                                 "foobar, fiibar",
-		"traefik.http.routers.Router0.priority":                                                    "42",
-		"traefik.http.routers.Router0.rule":                                                        "foobar",
-		"traefik.http.routers.Router0.tls":                                                         "true",
-		"traefik.http.routers.Router0.service":                                                     "foobar",
-		"traefik.http.routers.Router1.entrypoints":                                                 "foobar, fiibar",
-		"traefik.http.routers.Router1.middlewares":                                                 "foobar, fiibar",
-		"traefik.http.routers.Router1.priority":                                                    "42",
-		"traefik.http.routers.Router1.rule":                                                        "foobar",
-		"traefik.http.routers.Router1.service":                                                     "foobar",
+		"traefik.http.middlewares.Middleware0.addprefix.prefix":                                     "foobar",
+		"traefik.http.middlewares.Middleware1.basicauth.headerfield":                                "foobar",
+		"traefik.http.middlewares.Middleware1.basicauth.realm":                                      "foobar",
+		"traefik.http.middlewares.Middleware1.basicauth.removeheader":                               "true",
+		"traefik.http.middlewares.Middleware1.basicauth.users":                                      "foobar, fiibar",
+		"traefik.http.middlewares.Middleware1.basicauth.usersfile":                                  "foobar",
+		"traefik.http.middlewares.Middleware2.buffering.maxrequestbodybytes":                        "42",
+		"traefik.http.middlewares.Middleware2.buffering.maxresponsebodybytes":                       "42",
+		"traefik.http.middlewares.Middleware2.buffering.memrequestbodybytes":                        "42",
+		"traefik.http.middlewares.Middleware2.buffering.memresponsebodybytes":                       "42",
+		"traefik.http.middlewares.Middleware2.buffering.retryexpression":                            "foobar",
+		"traefik.http.middlewares.Middleware2.buffering.requestonly":                                "true",
+		"traefik.http.middlewares.Middleware2.buffering.spooldirectory":                             "foobar",
+		"traefik.http.middlewares.Middleware3.chain.middlewares":                                    "foobar, fiibar",
+		"traefik.http.middlewares.Middleware4.circuitbreaker.expression":                            "foobar",
+		"traefik.HTTP.Middlewares.Middleware4.circuitbreaker.checkperiod":                           "1s",
+		"traefik.HTTP.Middlewares.Middleware4.circuitbreaker.fallbackduration":                      "1s",
+		"traefik.HTTP.Middlewares.Middleware4.circuitbreaker.recoveryduration":                      "1s",
+		"traefik.http.middlewares.Middleware5.digestauth.headerfield":                               "foobar",
+		"traefik.http.middlewares.Middleware5.digestauth.realm":                                     "foobar",
+		"traefik.http.middlewares.Middleware5.digestauth.removeheader":                              "true",
+		"traefik.http.middlewares.Middleware5.digestauth.users":                                     "foobar, fiibar",
+		"traefik.http.middlewares.Middleware5.digestauth.usersfile":                                 "foobar",
+		"traefik.http.middlewares.Middleware6.errors.query":                                         "foobar",
+		"traefik.http.middlewares.Middleware6.errors.service":                                       "foobar",
+		"traefik.http.middlewares.Middleware6.errors.status":                                        "foobar, fiibar",
+		"traefik.http.middlewares.Middleware7.forwardauth.address":                                  "foobar",
+		"traefik.http.middlewares.Middleware7.forwardauth.authresponseheaders":                      "foobar, fiibar",
+		"traefik.http.middlewares.Middleware7.forwardauth.authrequestheaders":                       "foobar, fiibar",
+		"traefik.http.middlewares.Middleware7.forwardauth.tls.ca":                                   "foobar",
+		"traefik.http.middlewares.Middleware7.forwardauth.tls.cert":                                 "foobar",
+		"traefik.http.middlewares.Middleware7.forwardauth.tls.insecureskipverify":                   "true",
+		"traefik.http.middlewares.Middleware7.forwardauth.tls.key":                                  "foobar",
+		"traefik.http.middlewares.Middleware7.forwardauth.trustforwardheader":                       "true",
+		"traefik.http.middlewares.Middleware8.headers.accesscontrolallowcredentials":                "true",
+		"traefik.http.middlewares.Middleware8.headers.allowedhosts":                                 "foobar, fiibar",
+		"traefik.http.middlewares.Middleware8.headers.accesscontrolallowheaders":                    "X-foobar, X-fiibar",
+		"traefik.http.middlewares.Middleware8.headers.accesscontrolallowmethods":                    "GET, PUT",
+		"traefik.http.middlewares.Middleware8.headers.accesscontrolalloworiginList":                 "foobar, fiibar",
+		"traefik.http.middlewares.Middleware8.headers.accesscontrolalloworiginListRegex":            "foobar, fiibar",
+		"traefik.http.middlewares.Middleware8.headers.accesscontrolexposeheaders":                   "X-foobar, X-fiibar",
+		"traefik.http.middlewares.Middleware8.headers.accesscontrolmaxage":                          "200",
+		"traefik.http.middlewares.Middleware8.headers.addvaryheader":                                "true",
+		"traefik.http.middlewares.Middleware8.headers.browserxssfilter":                             "true",
+		"traefik.http.middlewares.Middleware8.headers.contentsecuritypolicy":                        "foobar",
+		"traefik.http.middlewares.Middleware8.headers.contenttypenosniff":                           "true",
+		"traefik.http.middlewares.Middleware8.headers.custombrowserxssvalue":                        "foobar",
+		"traefik.http.middlewares.Middleware8.headers.customframeoptionsvalue":                      "foobar",
+		"traefik.http.middlewares.Middleware8.headers.customrequestheaders.name0":                   "foobar",
+		"traefik.http.middlewares.Middleware8.headers.customrequestheaders.name1":                   "foobar",
+		"traefik.http.middlewares.Middleware8.headers.customresponseheaders.name0":                  "foobar",
+		"traefik.http.middlewares.Middleware8.headers.customresponseheaders.name1":                  "foobar",
+		"traefik.http.middlewares.Middleware8.headers.forcestsheader":                               "true",
+		"traefik.http.middlewares.Middleware8.headers.framedeny":                                    "true",
+		"traefik.http.middlewares.Middleware8.headers.hostsproxyheaders":                            "foobar, fiibar",
+		"traefik.http.middlewares.Middleware8.headers.isdevelopment":                                "true",
+		"traefik.http.middlewares.Middleware8.headers.publickey":                                    "foobar",
+		"traefik.http.middlewares.Middleware8.headers.referrerpolicy":                               "foobar",
+		"traefik.http.middlewares.Middleware8.headers.permissionspolicy":                            "foobar",
+		"traefik.http.middlewares.Middleware8.headers.sslproxyheaders.name0":                        "foobar",
+		"traefik.http.middlewares.Middleware8.headers.sslproxyheaders.name1":                        "foobar",
+		"traefik.http.middlewares.Middleware8.headers.stsincludesubdomains":                         "true",
+		"traefik.http.middlewares.Middleware8.headers.stspreload":                                   "true",
+		"traefik.http.middlewares.Middleware8.headers.stsseconds":                                   "42",
+		"traefik.http.middlewares.Middleware9.ipallowlist.ipstrategy.depth":                         "42",
+		"traefik.http.middlewares.Middleware9.ipallowlist.ipstrategy.excludedips":                   "foobar, fiibar",
+		"traefik.http.middlewares.Middleware9.ipallowlist.sourcerange":                              "foobar, fiibar",
+		"traefik.http.middlewares.Middleware10.inflightreq.amount":                                  "42",
+		"traefik.http.middlewares.Middleware10.inflightreq.sourcecriterion.ipstrategy.depth":        "42",
+		"traefik.http.middlewares.Middleware10.inflightreq.sourcecriterion.ipstrategy.excludedips":  "foobar, fiibar",
+		"traefik.http.middlewares.Middleware10.inflightreq.sourcecriterion.requestheadername":       "foobar",
+		"traefik.http.middlewares.Middleware10.inflightreq.sourcecriterion.requesthost":             "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.notafter":                     "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.notbefore":                    "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.sans":                         "true",
+		"traefik.http.middlewares.Middleware11.passTLSClientCert.info.serialNumber":                 "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.commonname":           "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.country":              "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.domaincomponent":      "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.locality":             "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.organization":         "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.organizationalunit":   "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.province":             "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.subject.serialnumber":         "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.commonname":            "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.country":               "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.domaincomponent":       "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.locality":              "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.organization":          "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.province":              "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.info.issuer.serialnumber":          "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.pem":                               "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.spiffeid":                          "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.sanheaders":                        "true",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.encoding":                          "path",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.allowed.commonnames":               "foobar, fiibar",
+		"traefik.http.middlewares.Middleware11.passtlsclientcert.allowed.sans":                      "foobar, fiibar",
+		"traefik.http.middlewares.Middleware12.ratelimit.average":                                   "42",
+		"traefik.http.middlewares.Middleware12.ratelimit.period":                                    "1s",
+		"traefik.http.middlewares.Middleware12.ratelimit.burst":                                     "42",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.requestheadername":         "foobar",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.requesthost":               "true",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.requestqueryparametername": "foobar",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.requestjwtclaim":           "foobar",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.requestclientcertcn":       "true",
+		"traefik.http.middlewares.Middleware12.ratelimit.redis.endpoints":                           "foobar, foobar",
+		"traefik.http.middlewares.Middleware12.ratelimit.redis.db":                                  "42",
+		"traefik.http.middlewares.Middleware12.ratelimit.redis.timeout":                             "1s",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.ipstrategy.depth":          "42",
+		"traefik.http.middlewares.Middleware12.ratelimit.sourcecriterion.ipstrategy.excludedips":    "foobar, foobar",
+		"traefik.http.middlewares.Middleware13.redirectregex.permanent":                             "true",
+		"traefik.http.middlewares.Middleware13.redirectregex.regex":                                 "foobar",
+		"traefik.http.middlewares.Middleware13.redirectregex.replacement":                           "foobar",
+		"traefik.http.middlewares.Middleware13b.redirectscheme.scheme":                              "https",
+		"traefik.http.middlewares.Middleware13b.redirectscheme.port":                                "80",
+		"traefik.http.middlewares.Middleware13b.redirectscheme.permanent":                           "true",
+		"traefik.http.middlewares.Middleware14.replacepath.path":                                    "foobar",
+		"traefik.http.middlewares.Middleware15.replacepathregex.regex":                              "foobar",
+		"traefik.http.middlewares.Middleware15.replacepathregex.replacement":                        "foobar",
+		"traefik.http.middlewares.Middleware16.retry.attempts":                                      "42",
+		"traefik.http.middlewares.Middleware16.retry.initialinterval":                               "1s",
+		"traefik.http.middlewares.Middleware17.stripprefix.prefixes":                                "foobar, fiibar",
+		"traefik.http.middlewares.Middleware18.stripprefixregex.regex":                              "foobar, fiibar",
+		"traefik.http.middlewares.Middleware19.compress.minresponsebodybytes":                       "42",
+		"traefik.http.middlewares.Middleware20.plugin.tomato.aaa":                                   "foo1",
+		"traefik.http.middlewares.Middleware20.plugin.tomato.bbb":                                   "foo2",
+		"traefik.http.routers.Router0.entrypoints":                                                  "foobar, fiibar",
+		"traefik.http.routers.Router0.middlewares":                                                  "foobar, fiibar",
+		"traefik.http.routers.Router0.priority":                                                     "42",
+		"traefik.http.routers.Router0.rule":                                                         "foobar",
+		"traefik.http.routers.Router0.tls":                                                          "true",
+		"traefik.http.routers.Router0.service":                                                      "foobar",
+		"traefik.http.routers.Router1.entrypoints":                                                  "foobar, fiibar",
+		"traefik.http.routers.Router1.middlewares":                                                  "foobar, fiibar",
+		"traefik.http.routers.Router1.priority":                                                     "42",
+		"traefik.http.routers.Router1.rule":                                                         "foobar",
+		"traefik.http.routers.Router1.service":                                                      "foobar",
 
-		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name0":        "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name1":        "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.hostname":             "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.interval":             "1s",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.path":                 "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.method":               "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.status":               "401",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.port":                 "42",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.scheme":               "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.mode":                 "foobar",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.timeout":              "1s",
-		"traefik.http.services.Service0.loadbalancer.healthcheck.followredirects":      "true",
-		"traefik.http.services.Service0.loadbalancer.passhostheader":                   "true",
-		"traefik.http.services.Service0.loadbalancer.responseforwarding.flushinterval": "1s",
-		"traefik.http.services.Service0.loadbalancer.server.scheme":                    "foobar",
-		"traefik.http.services.Service0.loadbalancer.server.port":                      "8080",
-		"traefik.http.services.Service0.loadbalancer.sticky.cookie.name":               "foobar",
-		"traefik.http.services.Service0.loadbalancer.sticky.cookie.secure":             "true",
-		"traefik.http.services.Service0.loadbalancer.serversTransport":                 "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name0":        "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name1":        "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.hostname":             "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.interval":             "1s",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.path":                 "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.method":               "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.status":               "401",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.port":                 "42",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.scheme":               "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.mode":                 "foobar",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.timeout":              "1s",
-		"traefik.http.services.Service1.loadbalancer.healthcheck.followredirects":      "true",
-		"traefik.http.services.Service1.loadbalancer.passhostheader":                   "true",
-		"traefik.http.services.Service1.loadbalancer.responseforwarding.flushinterval": "1s",
-		"traefik.http.services.Service1.loadbalancer.server.scheme":                    "foobar",
-		"traefik.http.services.Service1.loadbalancer.server.port":                      "8080",
-		"traefik.http.services.Service1.loadbalancer.sticky":                           "false",
-		"traefik.http.services.Service1.loadbalancer.sticky.cookie.name":               "fui",
-		"traefik.http.services.Service1.loadbalancer.serversTransport":                 "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name0":            "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.headers.name1":            "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.hostname":                 "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.interval":                 "1s",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.path":                     "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.method":                   "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.status":                   "401",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.port":                     "42",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.scheme":                   "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.mode":                     "foobar",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.timeout":                  "1s",
+		"traefik.http.services.Service0.loadbalancer.healthcheck.followredirects":          "true",
+		"traefik.http.services.Service0.loadbalancer.passhostheader":                       "true",
+		"traefik.http.services.Service0.loadbalancer.responseforwarding.flushinterval":     "1s",
+		"traefik.http.services.Service0.loadbalancer.retryonconnectionrefused":             "true",
+		"traefik.http.services.Service0.loadbalancer.passivehealthcheck.maxfailedattempts": "3",
+		"traefik.http.services.Service0.loadbalancer.strategy":                             "leastconn",
+		"traefik.http.services.Service0.loadbalancer.server.scheme":                        "foobar",
+		"traefik.http.services.Service0.loadbalancer.server.port":                          "8080",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.name":                   "foobar",
+		"traefik.http.services.Service0.loadbalancer.sticky.cookie.secure":                 "true",
+		"traefik.http.services.Service0.loadbalancer.serversTransport":                     "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name0":            "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.headers.name1":            "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.hostname":                 "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.interval":                 "1s",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.path":                     "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.method":                   "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.status":                   "401",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.port":                     "42",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.scheme":                   "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.mode":                     "foobar",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.timeout":                  "1s",
+		"traefik.http.services.Service1.loadbalancer.healthcheck.followredirects":          "true",
+		"traefik.http.services.Service1.loadbalancer.passhostheader":                       "true",
+		"traefik.http.services.Service1.loadbalancer.responseforwarding.flushinterval":     "1s",
+		"traefik.http.services.Service1.loadbalancer.server.scheme":                        "foobar",
+		"traefik.http.services.Service1.loadbalancer.server.port":                          "8080",
+		"traefik.http.services.Service1.loadbalancer.sticky":                               "false",
+		"traefik.http.services.Service1.loadbalancer.sticky.cookie.name":                   "fui",
+		"traefik.http.services.Service1.loadbalancer.serversTransport":                     "foobar",
 
+		"traefik.tcp.entrypoints.EntryPoint0.port":                         "42",
 		"traefik.tcp.middlewares.Middleware0.ipallowlist.sourcerange":      "foobar, fiibar",
 		"traefik.tcp.middlewares.Middleware2.inflightconn.amount":          "42",
 		"traefik.tcp.routers.Router0.rule":                                 "foobar",
@@ -199,11 +216,15 @@ func TestDecodeConfiguration(t *testing.T) {
 		"traefik.tcp.services.Service1.loadbalancer.proxyProtocol":         "true",
 		"traefik.tcp.services.Service1.loadbalancer.serversTransport":      "foo",
 
+		"traefik.udp.entrypoints.EntryPoint0.port":               "42",
 		"traefik.udp.routers.Router0.entrypoints":                "foobar, fiibar",
 		"traefik.udp.routers.Router0.service":                    "foobar",
+		"traefik.udp.routers.Router0.sessiontimeout":             "1s",
+		"traefik.udp.routers.Router0.maxsessions":                "42",
 		"traefik.udp.routers.Router1.entrypoints":                "foobar, fiibar",
 		"traefik.udp.routers.Router1.service":                    "foobar",
 		"traefik.udp.services.Service0.loadbalancer.server.Port": "42",
+		"traefik.udp.services.Service0.loadbalancer.sourcehash":  "true",
 		"traefik.udp.services.Service1.loadbalancer.server.Port": "42",
 	}
 
@@ -212,6 +233,11 @@ func TestDecodeConfiguration(t *testing.T) {
 
 	expected := &dynamic.Configuration{
 		TCP: &dynamic.TCPConfiguration{
+			EntryPoints: map[string]*dynamic.TCPEntryPoint{
+				"EntryPoint0": {
+					Port: 42,
+				},
+			},
 			Routers: map[string]*dynamic.TCPRouter{
 				"Router0": {
 					EntryPoints: []string{
@@ -278,13 +304,20 @@ func TestDecodeConfiguration(t *testing.T) {
 			},
 		},
 		UDP: &dynamic.UDPConfiguration{
+			EntryPoints: map[string]*dynamic.UDPEntryPoint{
+				"EntryPoint0": {
+					Port: 42,
+				},
+			},
 			Routers: map[string]*dynamic.UDPRouter{
 				"Router0": {
 					EntryPoints: []string{
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
+					Service:        "foobar",
+					SessionTimeout: ptypes.Duration(time.Second),
+					MaxSessions:    42,
 				},
 				"Router1": {
 					EntryPoints: []string{
@@ -302,6 +335,7 @@ func TestDecodeConfiguration(t *testing.T) {
 								Port: "42",
 							},
 						},
+						SourceHash: true,
 					},
 				},
 				"Service1": {
@@ -378,7 +412,14 @@ func TestDecodeConfiguration(t *testing.T) {
 				},
 				"Middleware11": {
 					PassTLSClientCert: &dynamic.PassTLSClientCert{
-						PEM: true,
+						PEM:        true,
+						SpiffeID:   true,
+						SANHeaders: true,
+						Encoding:   "path",
+						Allowed: &dynamic.TLSClientCertificateAllowed{
+							CommonNames: []string{"foobar", "fiibar"},
+							SANs:        []string{"foobar", "fiibar"},
+						},
 						Info: &dynamic.TLSClientCertificateInfo{
 							NotAfter:     true,
 							NotBefore:    true,
@@ -416,8 +457,16 @@ func TestDecodeConfiguration(t *testing.T) {
 								Depth:       42,
 								ExcludedIPs: []string{"foobar", "foobar"},
 							},
-							RequestHeaderName: "foobar",
-							RequestHost:       true,
+							RequestHeaderName:         "foobar",
+							RequestHost:               true,
+							RequestQueryParameterName: "foobar",
+							RequestJWTClaim:           "foobar",
+							RequestClientCertCN:       true,
+						},
+						Redis: &dynamic.Redis{
+							Endpoints: []string{"foobar", "foobar"},
+							DB:        42,
+							Timeout:   ptypes.Duration(time.Second),
 						},
 					},
 				},
@@ -480,6 +529,8 @@ func TestDecodeConfiguration(t *testing.T) {
 						MaxResponseBodyBytes: 42,
 						MemResponseBodyBytes: 42,
 						RetryExpression:      "foobar",
+						RequestOnly:          true,
+						SpoolDirectory:       "foobar",
 					},
 				},
 				"Middleware3": {
@@ -661,7 +712,15 @@ func TestDecodeConfiguration(t *testing.T) {
 						ResponseForwarding: &dynamic.ResponseForwarding{
 							FlushInterval: ptypes.Duration(time.Second),
 						},
-						ServersTransport: "foobar",
+						ServersTransport:         "foobar",
+						RetryOnConnectionRefused: true,
+						PassiveHealthCheck: &dynamic.PassiveServerHealthCheck{
+							MaxFailedAttempts:  3,
+							FailureWindow:      ptypes.Duration(10 * time.Second),
+							Cooldown:           ptypes.Duration(30 * time.Second),
+							MaxEjectionPercent: 50,
+						},
+						Strategy: "leastconn",
 					},
 				},
 				"Service1": {
@@ -707,6 +766,11 @@ func TestDecodeConfiguration(t *testing.T) {
 func TestEncodeConfiguration(t *testing.T) {
 	configuration := &dynamic.Configuration{
 		TCP: &dynamic.TCPConfiguration{
+			EntryPoints: map[string]*dynamic.TCPEntryPoint{
+				"EntryPoint0": {
+					Port: 42,
+				},
+			},
 			Routers: map[string]*dynamic.TCPRouter{
 				"Router0": {
 					EntryPoints: []string{
@@ -771,13 +835,20 @@ func TestEncodeConfiguration(t *testing.T) {
 			},
 		},
 		UDP: &dynamic.UDPConfiguration{
+			EntryPoints: map[string]*dynamic.UDPEntryPoint{
+				"EntryPoint0": {
+					Port: 42,
+				},
+			},
 			Routers: map[string]*dynamic.UDPRouter{
 				"Router0": {
 					EntryPoints: []string{
 						"foobar",
 						"fiibar",
 					},
-					Service: "foobar",
+					Service:        "foobar",
+					SessionTimeout: ptypes.Duration(time.Second),
+					MaxSessions:    42,
 				},
 				"Router1": {
 					EntryPoints: []string{
@@ -795,6 +866,7 @@ func TestEncodeConfiguration(t *testing.T) {
 								Port: "42",
 							},
 						},
+						SourceHash: true,
 					},
 				},
 				"Service1": {
@@ -871,7 +943,14 @@ func TestEncodeConfiguration(t *testing.T) {
 				},
 				"Middleware11": {
 					PassTLSClientCert: &dynamic.PassTLSClientCert{
-						PEM: true,
+						PEM:        true,
+						SpiffeID:   true,
+						SANHeaders: true,
+						Encoding:   "path",
+						Allowed: &dynamic.TLSClientCertificateAllowed{
+							CommonNames: []string{"foobar", "fiibar"},
+							SANs:        []string{"foobar", "fiibar"},
+						},
 						Info: &dynamic.TLSClientCertificateInfo{
 							NotAfter:     true,
 							NotBefore:    true,
@@ -908,8 +987,16 @@ func TestEncodeConfiguration(t *testing.T) {
 								Depth:       42,
 								ExcludedIPs: []string{"foobar", "foobar"},
 							},
-							RequestHeaderName: "foobar",
-							RequestHost:       true,
+							RequestHeaderName:         "foobar",
+							RequestHost:               true,
+							RequestQueryParameterName: "foobar",
+							RequestJWTClaim:           "foobar",
+							RequestClientCertCN:       true,
+						},
+						Redis: &dynamic.Redis{
+							Endpoints: []string{"foobar", "foobar"},
+							DB:        42,
+							Timeout:   ptypes.Duration(time.Second),
 						},
 					},
 				},
@@ -972,6 +1059,8 @@ func TestEncodeConfiguration(t *testing.T) {
 						MaxResponseBodyBytes: 42,
 						MemResponseBodyBytes: 42,
 						RetryExpression:      "foobar",
+						RequestOnly:          true,
+						SpoolDirectory:       "foobar",
 					},
 				},
 				"Middleware20": {
@@ -1190,119 +1279,141 @@ func TestEncodeConfiguration(t *testing.T) {
 	require.NoError(t, err)
 
 	expected := map[string]string{
-		"traefik.HTTP.Middlewares.Middleware0.AddPrefix.Prefix":                                    "foobar",
-		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.HeaderField":                               "foobar",
-		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.Realm":                                     "foobar",
-		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.RemoveHeader":                              "true",
-		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.Users":                                     "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.UsersFile":                                 "foobar",
-		"traefik.HTTP.Middlewares.Middleware2.Buffering.MaxRequestBodyBytes":                       "42",
-		"traefik.HTTP.Middlewares.Middleware2.Buffering.MaxResponseBodyBytes":                      "42",
-		"traefik.HTTP.Middlewares.Middleware2.Buffering.MemRequestBodyBytes":                       "42",
-		"traefik.HTTP.Middlewares.Middleware2.Buffering.MemResponseBodyBytes":                      "42",
-		"traefik.HTTP.Middlewares.Middleware2.Buffering.RetryExpression":                           "foobar",
-		"traefik.HTTP.Middlewares.Middleware3.Chain.Middlewares":                                   "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.Expression":                           "foobar",
-		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.CheckPeriod":                          "1000000000",
-		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.FallbackDuration":                     "1000000000",
-		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.RecoveryDuration":                     "1000000000",
-		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.HeaderField":                              "foobar",
-		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.Realm":                                    "foobar",
-		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.RemoveHeader":                             "true",
-		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.Users":                                    "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.UsersFile":                                "foobar",
-		"traefik.HTTP.Middlewares.Middleware6.Errors.Query":                                        "foobar",
-		"traefik.HTTP.Middlewares.Middleware6.Errors.Service":                                      "foobar",
-		"traefik.HTTP.Middlewares.Middleware6.Errors.Status":                                       "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.Address":                                 "foobar",
-		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.AuthResponseHeaders":                     "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.AuthRequestHeaders":                      "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.CA":                                  "foobar",
-		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.Cert":                                "foobar",
-		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.InsecureSkipVerify":                  "true",
-		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.Key":                                 "foobar",
-		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TrustForwardHeader":                      "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowCredentials":               "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowHeaders":                   "X-foobar, X-fiibar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowMethods":                   "GET, PUT",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowOriginList":                "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowOriginListRegex":           "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlExposeHeaders":                  "X-foobar, X-fiibar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlMaxAge":                         "200",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.AddVaryHeader":                               "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.AllowedHosts":                                "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.BrowserXSSFilter":                            "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.ContentSecurityPolicy":                       "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.ContentTypeNosniff":                          "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomBrowserXSSValue":                       "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomFrameOptionsValue":                     "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomRequestHeaders.name0":                  "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomRequestHeaders.name1":                  "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomResponseHeaders.name0":                 "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomResponseHeaders.name1":                 "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.ForceSTSHeader":                              "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.FrameDeny":                                   "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.HostsProxyHeaders":                           "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.IsDevelopment":                               "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.PublicKey":                                   "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.ReferrerPolicy":                              "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.PermissionsPolicy":                           "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.SSLProxyHeaders.name0":                       "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.SSLProxyHeaders.name1":                       "foobar",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.STSIncludeSubdomains":                        "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.STSPreload":                                  "true",
-		"traefik.HTTP.Middlewares.Middleware8.Headers.STSSeconds":                                  "42",
-		"traefik.HTTP.Middlewares.Middleware9.IPAllowList.IPStrategy.Depth":                        "42",
-		"traefik.HTTP.Middlewares.Middleware9.IPAllowList.IPStrategy.ExcludedIPs":                  "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware9.IPAllowList.SourceRange":                             "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.Amount":                                 "42",
-		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.SourceCriterion.IPStrategy.Depth":       "42",
-		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.SourceCriterion.IPStrategy.ExcludedIPs": "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.SourceCriterion.RequestHeaderName":      "foobar",
-		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.SourceCriterion.RequestHost":            "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.NotAfter":                    "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.NotBefore":                   "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Sans":                        "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.SerialNumber":                "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.Country":             "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.Province":            "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.Locality":            "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.Organization":        "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.OrganizationalUnit":  "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.CommonName":          "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.SerialNumber":        "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.DomainComponent":     "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.Country":              "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.Province":             "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.Locality":             "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.Organization":         "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.CommonName":           "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.SerialNumber":         "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.DomainComponent":      "true",
-		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.PEM":                              "true",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Average":                                  "42",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Period":                                   "1000000000",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Burst":                                    "42",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestHeaderName":        "foobar",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestHost":              "true",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.IPStrategy.Depth":         "42",
-		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.IPStrategy.ExcludedIPs":   "foobar, foobar",
-		"traefik.HTTP.Middlewares.Middleware13.RedirectRegex.Regex":                                "foobar",
-		"traefik.HTTP.Middlewares.Middleware13.RedirectRegex.Replacement":                          "foobar",
-		"traefik.HTTP.Middlewares.Middleware13.RedirectRegex.Permanent":                            "true",
-		"traefik.HTTP.Middlewares.Middleware13b.RedirectScheme.Scheme":                             "https",
-		"traefik.HTTP.Middlewares.Middleware13b.RedirectScheme.Port":                               "80",
-		"traefik.HTTP.Middlewares.Middleware13b.RedirectScheme.Permanent":                          "true",
-		"traefik.HTTP.Middlewares.Middleware14.ReplacePath.Path":                                   "foobar",
-		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Regex":                             "foobar",
-		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Replacement":                       "foobar",
-		"traefik.HTTP.Middlewares.Middleware16.Retry.Attempts":                                     "42",
-		"traefik.HTTP.Middlewares.Middleware16.Retry.InitialInterval":                              "1000000000",
-		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.Prefixes":                               "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware18.StripPrefixRegex.Regex":                             "foobar, fiibar",
-		"traefik.HTTP.Middlewares.Middleware19.Compress.MinResponseBodyBytes":                      "42",
-		"traefik.HTTP.Middlewares.Middleware20.Plugin.tomato.aaa":                                  "foo1",
-		"traefik.HTTP.Middlewares.Middleware20.Plugin.tomato.bbb":                                  "foo2",
+		"traefik.HTTP.Middlewares.Middleware0.AddPrefix.Prefix":                                     "foobar",
+		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.HeaderField":                                "foobar",
+		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.Realm":                                      "foobar",
+		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.RemoveHeader":                               "true",
+		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.Users":                                      "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware1.BasicAuth.UsersFile":                                  "foobar",
+		"traefik.HTTP.Middlewares.Middleware2.Buffering.MaxRequestBodyBytes":                        "42",
+		"traefik.HTTP.Middlewares.Middleware2.Buffering.MaxResponseBodyBytes":                       "42",
+		"traefik.HTTP.Middlewares.Middleware2.Buffering.MemRequestBodyBytes":                        "42",
+		"traefik.HTTP.Middlewares.Middleware2.Buffering.MemResponseBodyBytes":                       "42",
+		"traefik.HTTP.Middlewares.Middleware2.Buffering.RetryExpression":                            "foobar",
+		"traefik.HTTP.Middlewares.Middleware2.Buffering.RequestOnly":                                "true",
+		"traefik.HTTP.Middlewares.Middleware2.Buffering.SpoolDirectory":                             "foobar",
+		"traefik.HTTP.Middlewares.Middleware3.Chain.Middlewares":                                    "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.Expression":                            "foobar",
+		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.CheckPeriod":                           "1000000000",
+		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.FallbackDuration":                      "1000000000",
+		"traefik.HTTP.Middlewares.Middleware4.CircuitBreaker.RecoveryDuration":                      "1000000000",
+		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.HeaderField":                               "foobar",
+		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.Realm":                                     "foobar",
+		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.RemoveHeader":                              "true",
+		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.Users":                                     "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware5.DigestAuth.UsersFile":                                 "foobar",
+		"traefik.HTTP.Middlewares.Middleware6.Errors.Query":                                         "foobar",
+		"traefik.HTTP.Middlewares.Middleware6.Errors.Service":                                       "foobar",
+		"traefik.HTTP.Middlewares.Middleware6.Errors.Status":                                        "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.Address":                                  "foobar",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.AuthResponseHeaders":                      "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.AuthRequestHeaders":                       "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.ForwardBody":                              "false",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.MaxBodySize":                              "0",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.CA":                                   "foobar",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.Cert":                                 "foobar",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.InsecureSkipVerify":                   "true",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TLS.Key":                                  "foobar",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.Timeout":                                  "0",
+		"traefik.HTTP.Middlewares.Middleware7.ForwardAuth.TrustForwardHeader":                       "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowCredentials":                "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowHeaders":                    "X-foobar, X-fiibar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowMethods":                    "GET, PUT",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowOriginList":                 "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlAllowOriginListRegex":            "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlExposeHeaders":                   "X-foobar, X-fiibar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.AccessControlMaxAge":                          "200",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.AddVaryHeader":                                "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.AllowedHosts":                                 "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.BrowserXSSFilter":                             "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.ContentSecurityPolicy":                        "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.ContentTypeNosniff":                           "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomBrowserXSSValue":                        "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomFrameOptionsValue":                      "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomRequestHeaders.name0":                   "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomRequestHeaders.name1":                   "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomResponseHeaders.name0":                  "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.CustomResponseHeaders.name1":                  "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.ForceSTSHeader":                               "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.FrameDeny":                                    "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.HostsProxyHeaders":                            "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.IsDevelopment":                                "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.PublicKey":                                    "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.ReferrerPolicy":                               "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.PermissionsPolicy":                            "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.SSLProxyHeaders.name0":                        "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.SSLProxyHeaders.name1":                        "foobar",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.STSIncludeSubdomains":                         "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.STSPreload":                                   "true",
+		"traefik.HTTP.Middlewares.Middleware8.Headers.STSSeconds":                                   "42",
+		"traefik.HTTP.Middlewares.Middleware9.IPAllowList.IPStrategy.Depth":                         "42",
+		"traefik.HTTP.Middlewares.Middleware9.IPAllowList.IPStrategy.ExcludedIPs":                   "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware9.IPAllowList.SourceRange":                              "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.Amount":                                  "42",
+		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.SourceCriterion.IPStrategy.Depth":        "42",
+		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.SourceCriterion.IPStrategy.ExcludedIPs":  "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.SourceCriterion.RequestHeaderName":       "foobar",
+		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.SourceCriterion.RequestHost":             "true",
+		"traefik.HTTP.Middlewares.Middleware10.InFlightReq.SourceCriterion.RequestClientCertCN":     "false",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.NotAfter":                     "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.NotBefore":                    "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Sans":                         "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.SerialNumber":                 "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.Country":              "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.Province":             "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.Locality":             "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.Organization":         "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.OrganizationalUnit":   "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.CommonName":           "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.SerialNumber":         "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Subject.DomainComponent":      "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.Country":               "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.Province":              "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.Locality":              "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.Organization":          "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.CommonName":            "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.SerialNumber":          "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Info.Issuer.DomainComponent":       "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.PEM":                               "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.SpiffeID":                          "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.SANHeaders":                        "true",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Encoding":                          "path",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Allowed.CommonNames":               "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware11.PassTLSClientCert.Allowed.SANs":                      "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Average":                                   "42",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Period":                                    "1000000000",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Burst":                                     "42",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestHeaderName":         "foobar",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestHost":               "true",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestQueryParameterName": "foobar",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestJWTClaim":           "foobar",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.RequestClientCertCN":       "true",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Redis.Endpoints":                           "foobar, foobar",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Redis.DB":                                  "42",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.Redis.Timeout":                             "1000000000",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.IPStrategy.Depth":          "42",
+		"traefik.HTTP.Middlewares.Middleware12.RateLimit.SourceCriterion.IPStrategy.ExcludedIPs":    "foobar, foobar",
+		"traefik.HTTP.Middlewares.Middleware13.RedirectRegex.Regex":                                 "foobar",
+		"traefik.HTTP.Middlewares.Middleware13.RedirectRegex.Replacement":                           "foobar",
+		"traefik.HTTP.Middlewares.Middleware13.RedirectRegex.Permanent":                             "true",
+		"traefik.HTTP.Middlewares.Middleware13b.RedirectScheme.Scheme":                              "https",
+		"traefik.HTTP.Middlewares.Middleware13b.RedirectScheme.Port":                                "80",
+		"traefik.HTTP.Middlewares.Middleware13b.RedirectScheme.Permanent":                           "true",
+		"traefik.HTTP.Middlewares.Middleware14.ReplacePath.Path":                                    "foobar",
+		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Regex":                              "foobar",
+		"traefik.HTTP.Middlewares.Middleware15.ReplacePathRegex.Replacement":                        "foobar",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.Attempts":                                      "42",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.IdempotentOnly":                                "false",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.InitialInterval":                               "1000000000",
+		"traefik.HTTP.Middlewares.Middleware16.Retry.MaxInterval":                                   "0",
+		"traefik.HTTP.Middlewares.Middleware17.StripPrefix.Prefixes":                                "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware18.StripPrefixRegex.Regex":                              "foobar, fiibar",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.BrotliLevel":                                "0",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.GzipLevel":                                  "0",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.MinResponseBodyBytes":                       "42",
+		"traefik.HTTP.Middlewares.Middleware19.Compress.ZstdLevel":                                  "0",
+		"traefik.HTTP.Middlewares.Middleware20.Plugin.tomato.aaa":                                   "foo1",
+		"traefik.HTTP.Middlewares.Middleware20.Plugin.tomato.bbb":                                   "foo2",
 
 		"traefik.HTTP.Routers.Router0.EntryPoints": "foobar, fiibar",
 		"traefik.HTTP.Routers.Router0.Middlewares": "foobar, fiibar",
@@ -1328,6 +1439,7 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Services.Service0.LoadBalancer.HealthCheck.Timeout":              "1000000000",
 		"traefik.HTTP.Services.Service0.LoadBalancer.PassHostHeader":                   "true",
 		"traefik.HTTP.Services.Service0.LoadBalancer.ResponseForwarding.FlushInterval": "1000000000",
+		"traefik.HTTP.Services.Service0.LoadBalancer.RetryOnConnectionRefused":         "false",
 		"traefik.HTTP.Services.Service0.LoadBalancer.server.Port":                      "8080",
 		"traefik.HTTP.Services.Service0.LoadBalancer.server.Scheme":                    "foobar",
 		"traefik.HTTP.Services.Service0.LoadBalancer.Sticky.Cookie.Name":               "foobar",
@@ -1346,10 +1458,12 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.HTTP.Services.Service1.LoadBalancer.HealthCheck.Timeout":              "1000000000",
 		"traefik.HTTP.Services.Service1.LoadBalancer.PassHostHeader":                   "true",
 		"traefik.HTTP.Services.Service1.LoadBalancer.ResponseForwarding.FlushInterval": "1000000000",
+		"traefik.HTTP.Services.Service1.LoadBalancer.RetryOnConnectionRefused":         "false",
 		"traefik.HTTP.Services.Service1.LoadBalancer.server.Port":                      "8080",
 		"traefik.HTTP.Services.Service1.LoadBalancer.server.Scheme":                    "foobar",
 		"traefik.HTTP.Services.Service1.LoadBalancer.ServersTransport":                 "foobar",
 
+		"traefik.TCP.EntryPoints.EntryPoint0.Port":                    "42",
 		"traefik.TCP.Middlewares.Middleware0.IPAllowList.SourceRange": "foobar, fiibar",
 		"traefik.TCP.Middlewares.Middleware2.InFlightConn.Amount":     "42",
 		"traefik.TCP.Routers.Router0.Rule":                            "foobar",
@@ -1371,12 +1485,19 @@ func TestEncodeConfiguration(t *testing.T) {
 		"traefik.TCP.Services.Service1.LoadBalancer.server.TLS":       "false",
 		"traefik.TCP.Services.Service1.LoadBalancer.ServersTransport": "foo",
 
+		"traefik.UDP.EntryPoints.EntryPoint0.Port":               "42",
 		"traefik.UDP.Routers.Router0.EntryPoints":                "foobar, fiibar",
+		"traefik.UDP.Routers.Router0.MaxSessions":                "42",
 		"traefik.UDP.Routers.Router0.Service":                    "foobar",
+		"traefik.UDP.Routers.Router0.SessionTimeout":             "1000000000",
 		"traefik.UDP.Routers.Router1.EntryPoints":                "foobar, fiibar",
+		"traefik.UDP.Routers.Router1.MaxSessions":                "0",
 		"traefik.UDP.Routers.Router1.Service":                    "foobar",
+		"traefik.UDP.Routers.Router1.SessionTimeout":             "0",
 		"traefik.UDP.Services.Service0.LoadBalancer.server.Port": "42",
+		"traefik.UDP.Services.Service0.LoadBalancer.SourceHash":  "true",
 		"traefik.UDP.Services.Service1.LoadBalancer.server.Port": "42",
+		"traefik.UDP.Services.Service1.LoadBalancer.SourceHash":  "false",
 	}
 
 	for key, val := range expected {
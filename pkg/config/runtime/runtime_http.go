@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/logs"
 )
@@ -144,6 +147,87 @@ type ServiceInfo struct {
 
 	serverStatusMu sync.RWMutex
 	serverStatus   map[string]string // keyed by server URL
+
+	mirrorComparisonsMu sync.RWMutex
+	mirrorComparisons   map[string]*mirrorComparison // keyed by mirror service name
+}
+
+// MirrorComparison holds the comparison of the responses of a mirror with the ones of the mirrored service.
+type MirrorComparison struct {
+	// Compared is the number of mirrored requests whose responses were compared.
+	Compared uint64 `json:"compared"`
+	// StatusDivergences is the number of mirrored requests whose responses have different status codes.
+	StatusDivergences uint64 `json:"statusDivergences"`
+	// DivergentStatuses counts the divergences by status codes of the service and of the mirror, e.g. "200 -> 503".
+	DivergentStatuses map[string]uint64 `json:"divergentStatuses,omitempty"`
+	// MeanLatencyDelta is the mean of the latency of the mirror minus the one of the service, positive when the mirror is slower.
+	MeanLatencyDelta ptypes.Duration `json:"meanLatencyDelta"`
+	// MaxLatencyDelta is the largest latency delta.
+	MaxLatencyDelta ptypes.Duration `json:"maxLatencyDelta"`
+}
+
+type mirrorComparison struct {
+	MirrorComparison
+	latencyDeltaSum time.Duration
+}
+
+// RecordMirrorComparison records the comparison of the response of a mirror to a request with the response of the service.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *ServiceInfo) RecordMirrorComparison(mirror string, status, mirrorStatus int, latencyDelta time.Duration) {
+	s.mirrorComparisonsMu.Lock()
+	defer s.mirrorComparisonsMu.Unlock()
+
+	if s.mirrorComparisons == nil {
+		s.mirrorComparisons = make(map[string]*mirrorComparison)
+	}
+
+	c, ok := s.mirrorComparisons[mirror]
+	if !ok {
+		c = &mirrorComparison{}
+		s.mirrorComparisons[mirror] = c
+	}
+
+	c.Compared++
+	if status != mirrorStatus {
+		c.StatusDivergences++
+		if c.DivergentStatuses == nil {
+			c.DivergentStatuses = make(map[string]uint64)
+		}
+		c.DivergentStatuses[strconv.Itoa(status)+" -> "+strconv.Itoa(mirrorStatus)]++
+	}
+
+	c.latencyDeltaSum += latencyDelta
+	if c.Compared == 1 || ptypes.Duration(latencyDelta) > c.MaxLatencyDelta {
+		c.MaxLatencyDelta = ptypes.Duration(latencyDelta)
+	}
+}
+
+// GetMirrorComparisons returns the comparisons of the responses of the mirrors, keyed by mirror service name.
+// It is the responsibility of the caller to check that s is not nil.
+func (s *ServiceInfo) GetMirrorComparisons() map[string]MirrorComparison {
+	s.mirrorComparisonsMu.RLock()
+	defer s.mirrorComparisonsMu.RUnlock()
+
+	if len(s.mirrorComparisons) == 0 {
+		return nil
+	}
+
+	comparisons := make(map[string]MirrorComparison, len(s.mirrorComparisons))
+	for name, c := range s.mirrorComparisons {
+		comparison := c.MirrorComparison
+		comparison.MeanLatencyDelta = ptypes.Duration(c.latencyDeltaSum / time.Duration(c.Compared))
+
+		comparison.DivergentStatuses = make(map[string]uint64, len(c.DivergentStatuses))
+		for statuses, count := range c.DivergentStatuses {
+			comparison.DivergentStatuses[statuses] = count
+		}
+		if len(comparison.DivergentStatuses) == 0 {
+			comparison.DivergentStatuses = nil
+		}
+
+		comparisons[name] = comparison
+	}
+	return comparisons
 }
 
 // AddError adds err to s.Err, if it does not already exist.
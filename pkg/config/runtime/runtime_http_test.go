@@ -3,8 +3,10 @@ package runtime
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 )
 
@@ -217,3 +219,29 @@ func TestGetRoutersByEntryPoints(t *testing.T) {
 		})
 	}
 }
+
+func TestServiceInfo_MirrorComparisons(t *testing.T) {
+	si := &ServiceInfo{}
+	assert.Nil(t, si.GetMirrorComparisons())
+
+	si.RecordMirrorComparison("canary", 200, 200, 10*time.Millisecond)
+	si.RecordMirrorComparison("canary", 200, 503, -20*time.Millisecond)
+	si.RecordMirrorComparison("canary", 200, 503, 40*time.Millisecond)
+	si.RecordMirrorComparison("shadow", 404, 404, -time.Millisecond)
+
+	expected := map[string]MirrorComparison{
+		"canary": {
+			Compared:          3,
+			StatusDivergences: 2,
+			DivergentStatuses: map[string]uint64{"200 -> 503": 2},
+			MeanLatencyDelta:  ptypes.Duration(10 * time.Millisecond),
+			MaxLatencyDelta:   ptypes.Duration(40 * time.Millisecond),
+		},
+		"shadow": {
+			Compared:         1,
+			MeanLatencyDelta: ptypes.Duration(-time.Millisecond),
+			MaxLatencyDelta:  ptypes.Duration(-time.Millisecond),
+		},
+	}
+	assert.Equal(t, expected, si.GetMirrorComparisons())
+}
@@ -3,7 +3,9 @@ package static
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v3/pkg/types"
@@ -56,11 +58,42 @@ func (ep *EntryPoint) SetDefaults() {
 	ep.HTTP2.SetDefaults()
 }
 
+// DynamicEntryPoints holds the configuration of the TCP and UDP entrypoints declared by the providers.
+type DynamicEntryPoints struct {
+	PortRange string `description:"Range of the ports the dynamic entrypoints are allowed to listen on (e.g. 10000-10100)." json:"portRange,omitempty" toml:"portRange,omitempty" yaml:"portRange,omitempty" export:"true"`
+	Host      string `description:"Host the dynamic entrypoints listen on. All the interfaces are used when empty." json:"host,omitempty" toml:"host,omitempty" yaml:"host,omitempty" export:"true"`
+}
+
+// Ports returns the first and the last ports of the range.
+func (d *DynamicEntryPoints) Ports() (int, int, error) {
+	first, last, found := strings.Cut(d.PortRange, "-")
+	if !found {
+		last = first
+	}
+
+	minPort, err := strconv.Atoi(strings.TrimSpace(first))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q: %w", d.PortRange, err)
+	}
+
+	maxPort, err := strconv.Atoi(strings.TrimSpace(last))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q: %w", d.PortRange, err)
+	}
+
+	if minPort < 1 || maxPort > math.MaxUint16 || minPort > maxPort {
+		return 0, 0, fmt.Errorf("invalid port range %q", d.PortRange)
+	}
+
+	return minPort, maxPort, nil
+}
+
 // HTTPConfig is the HTTP configuration of an entry point.
 type HTTPConfig struct {
-	Redirections *Redirections `description:"Set of redirection" json:"redirections,omitempty" toml:"redirections,omitempty" yaml:"redirections,omitempty" export:"true"`
-	Middlewares  []string      `description:"Default middlewares for the routers linked to the entry point." json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty" export:"true"`
-	TLS          *TLSConfig    `description:"Default TLS configuration for the routers linked to the entry point." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	Redirections   *Redirections `description:"Set of redirection" json:"redirections,omitempty" toml:"redirections,omitempty" yaml:"redirections,omitempty" export:"true"`
+	Middlewares    []string      `description:"Default middlewares for the routers linked to the entry point." json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty" export:"true"`
+	TLS            *TLSConfig    `description:"Default TLS configuration for the routers linked to the entry point." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	DefaultService string        `description:"Service, with its provider namespace, handling the requests matching no router of the entry point." json:"defaultService,omitempty" toml:"defaultService,omitempty" yaml:"defaultService,omitempty" export:"true"`
 }
 
 // HTTP2Config is the HTTP2 configuration of an entry point.
@@ -115,6 +148,8 @@ type ForwardedHeaders struct {
 type ProxyProtocol struct {
 	Insecure   bool     `description:"Trust all." json:"insecure,omitempty" toml:"insecure,omitempty" yaml:"insecure,omitempty" export:"true"`
 	TrustedIPs []string `description:"Trust only selected IPs." json:"trustedIPs,omitempty" toml:"trustedIPs,omitempty" yaml:"trustedIPs,omitempty"`
+	Required   bool     `description:"Rejects the connections from the untrusted IPs, and the connections without PROXY protocol header." json:"required,omitempty" toml:"required,omitempty" yaml:"required,omitempty" export:"true"`
+	TLVHeaders bool     `description:"Sets the TLVs of the PROXY protocol v2 headers as request headers." json:"tlvHeaders,omitempty" toml:"tlvHeaders,omitempty" yaml:"tlvHeaders,omitempty" export:"true"`
 }
 
 // EntryPoints holds the HTTP entry point list.
@@ -124,6 +159,8 @@ type EntryPoints map[string]*EntryPoint
 type EntryPointsTransport struct {
 	LifeCycle          *LifeCycle          `description:"Timeouts influencing the server life cycle." json:"lifeCycle,omitempty" toml:"lifeCycle,omitempty" yaml:"lifeCycle,omitempty" export:"true"`
 	RespondingTimeouts *RespondingTimeouts `description:"Timeouts for incoming requests to the Traefik instance." json:"respondingTimeouts,omitempty" toml:"respondingTimeouts,omitempty" yaml:"respondingTimeouts,omitempty" export:"true"`
+	InFlightRequests   *InFlightRequests   `description:"Limits the number of requests handled at the same time by the entry point." json:"inFlightRequests,omitempty" toml:"inFlightRequests,omitempty" yaml:"inFlightRequests,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	WebSockets         *WebSockets         `description:"Limits the lifetime of the WebSocket connections, and closes them with a close frame when the entry point shuts down." json:"webSockets,omitempty" toml:"webSockets,omitempty" yaml:"webSockets,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -134,6 +171,31 @@ func (t *EntryPointsTransport) SetDefaults() {
 	t.RespondingTimeouts.SetDefaults()
 }
 
+// InFlightRequests limits the number of requests handled at the same time by an entry point,
+// the requests over the limit waiting in a queue for a slot to be released.
+type InFlightRequests struct {
+	Amount       int64           `description:"Maximum number of requests handled at the same time." json:"amount,omitempty" toml:"amount,omitempty" yaml:"amount,omitempty" export:"true"`
+	QueueSize    int64           `description:"Maximum number of requests waiting for a slot, the requests over it being refused." json:"queueSize,omitempty" toml:"queueSize,omitempty" yaml:"queueSize,omitempty" export:"true"`
+	QueueTimeout ptypes.Duration `description:"Maximum duration a request waits for a slot before being refused." json:"queueTimeout,omitempty" toml:"queueTimeout,omitempty" yaml:"queueTimeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (i *InFlightRequests) SetDefaults() {
+	i.QueueTimeout = ptypes.Duration(5 * time.Second)
+}
+
+// WebSockets configures the closing of the WebSocket connections of an entry point,
+// with a close frame sent between two frames of the server, rather than by cutting the connections off.
+type WebSockets struct {
+	MaxLifetime  ptypes.Duration `description:"Maximum lifetime of the WebSocket connections, after which they are closed. Not limited when zero." json:"maxLifetime,omitempty" toml:"maxLifetime,omitempty" yaml:"maxLifetime,omitempty" export:"true"`
+	CloseTimeout ptypes.Duration `description:"Duration the clients have to close the WebSocket connections after receiving the close frame, before they are cut off." json:"closeTimeout,omitempty" toml:"closeTimeout,omitempty" yaml:"closeTimeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (w *WebSockets) SetDefaults() {
+	w.CloseTimeout = ptypes.Duration(10 * time.Second)
+}
+
 // UDPConfig is the UDP configuration of an entry point.
 type UDPConfig struct {
 	Timeout ptypes.Duration `description:"Timeout defines how long to wait on an idle session before releasing the related resources." json:"timeout,omitempty" toml:"timeout,omitempty" yaml:"timeout,omitempty"`
@@ -65,3 +65,69 @@ func TestEntryPointProtocol(t *testing.T) {
 		})
 	}
 }
+
+func TestDynamicEntryPointsPorts(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		portRange   string
+		expectedMin int
+		expectedMax int
+		expectedErr bool
+	}{
+		{
+			desc:        "range",
+			portRange:   "10000-10100",
+			expectedMin: 10000,
+			expectedMax: 10100,
+		},
+		{
+			desc:        "range with spaces",
+			portRange:   "10000 - 10100",
+			expectedMin: 10000,
+			expectedMax: 10100,
+		},
+		{
+			desc:        "single port",
+			portRange:   "10000",
+			expectedMin: 10000,
+			expectedMax: 10000,
+		},
+		{
+			desc:        "empty",
+			expectedErr: true,
+		},
+		{
+			desc:        "reversed range",
+			portRange:   "10100-10000",
+			expectedErr: true,
+		},
+		{
+			desc:        "out of bounds",
+			portRange:   "0-70000",
+			expectedErr: true,
+		},
+		{
+			desc:        "not a number",
+			portRange:   "10000-foo",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			d := DynamicEntryPoints{PortRange: test.portRange}
+			minPort, maxPort, err := d.Ports()
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, test.expectedMin, minPort)
+			require.Equal(t, test.expectedMax, maxPort)
+		})
+	}
+}
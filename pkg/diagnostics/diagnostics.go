@@ -0,0 +1,217 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/provider/acme"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
+	"github.com/traefik/traefik/v3/pkg/redactor"
+	"github.com/traefik/traefik/v3/pkg/version"
+)
+
+// CertificateResolver is a certificate resolver whose state is part of the bundle.
+type CertificateResolver interface {
+	Paused() bool
+	CertificatesSummary() []acme.CertificateSummary
+}
+
+// NomadProvider is a Nomad provider whose synchronization state is part of the bundle.
+type NomadProvider interface {
+	Status() nomad.Status
+}
+
+type certResolverRepresentation struct {
+	Name         string                    `json:"name"`
+	Paused       bool                      `json:"paused"`
+	Certificates []acme.CertificateSummary `json:"certificates"`
+}
+
+// Collector gathers the diagnostic bundle of the running instance for support cases:
+// the dynamic configuration, the state of the Nomad providers and of the certificate resolvers, and the goroutines.
+type Collector struct {
+	certResolvers  map[string]CertificateResolver
+	nomadProviders []NomadProvider
+
+	configurationMu sync.RWMutex
+	configuration   dynamic.Configuration
+}
+
+// NewCollector creates a new Collector.
+func NewCollector(certResolvers map[string]CertificateResolver, nomadProviders []NomadProvider) *Collector {
+	return &Collector{
+		certResolvers:  certResolvers,
+		nomadProviders: nomadProviders,
+	}
+}
+
+// ListenConfiguration keeps the last dynamic configuration, to be added to the bundle.
+func (c *Collector) ListenConfiguration(configuration dynamic.Configuration) {
+	c.configurationMu.Lock()
+	c.configuration = configuration
+	c.configurationMu.Unlock()
+}
+
+// ServeHTTP serves the bundle as a tar.gz attachment.
+func (c *Collector) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var buf bytes.Buffer
+	if err := c.Write(&buf); err != nil {
+		log.Ctx(req.Context()).Error().Err(err).Msg("Unable to create the diagnostic bundle")
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/gzip")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", bundleName(time.Now())))
+
+	if _, err := buf.WriteTo(rw); err != nil {
+		log.Ctx(req.Context()).Error().Err(err).Send()
+	}
+}
+
+// WriteFile writes the bundle to a new file in the directory, and returns its path.
+func (c *Collector) WriteFile(dir string) (string, error) {
+	path := filepath.Join(dir, bundleName(time.Now()))
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", fmt.Errorf("creating bundle file: %w", err)
+	}
+
+	if err = c.Write(file); err != nil {
+		_ = file.Close()
+		_ = os.Remove(path)
+		return "", err
+	}
+
+	if err = file.Close(); err != nil {
+		return "", fmt.Errorf("closing bundle file: %w", err)
+	}
+
+	return path, nil
+}
+
+// Write writes the bundle, as a tar.gz archive, to w.
+func (c *Collector) Write(w io.Writer) error {
+	configuration, err := c.redactedConfiguration()
+	if err != nil {
+		return fmt.Errorf("redacting configuration: %w", err)
+	}
+
+	nomadStatuses, err := json.MarshalIndent(c.nomadStatuses(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding nomad statuses: %w", err)
+	}
+
+	certResolvers, err := json.MarshalIndent(c.certResolverStates(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding certificate resolvers: %w", err)
+	}
+
+	var goroutines bytes.Buffer
+	if err = pprof.Lookup("goroutine").WriteTo(&goroutines, 2); err != nil {
+		return fmt.Errorf("dumping goroutines: %w", err)
+	}
+
+	versionInfo, err := json.MarshalIndent(map[string]string{
+		"version":   version.Version,
+		"codename":  version.Codename,
+		"buildDate": version.BuildDate,
+		"goVersion": runtime.Version(),
+		"os":        runtime.GOOS,
+		"arch":      runtime.GOARCH,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding version: %w", err)
+	}
+
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{name: "version.json", content: versionInfo},
+		{name: "configuration.json", content: []byte(configuration)},
+		{name: "nomad.json", content: nomadStatuses},
+		{name: "acme.json", content: certResolvers},
+		{name: "goroutines.txt", content: goroutines.Bytes()},
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	now := time.Now()
+	for _, f := range files {
+		header := &tar.Header{
+			Name:    f.name,
+			Mode:    0o600,
+			Size:    int64(len(f.content)),
+			ModTime: now,
+		}
+
+		if err = tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("writing %s header: %w", f.name, err)
+		}
+
+		if _, err = tw.Write(f.content); err != nil {
+			return fmt.Errorf("writing %s: %w", f.name, err)
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return fmt.Errorf("closing archive: %w", err)
+	}
+
+	return gz.Close()
+}
+
+func (c *Collector) redactedConfiguration() (string, error) {
+	c.configurationMu.RLock()
+	configuration := c.configuration
+	c.configurationMu.RUnlock()
+
+	return redactor.RemoveCredentials(&configuration)
+}
+
+func (c *Collector) nomadStatuses() []nomad.Status {
+	statuses := make([]nomad.Status, 0, len(c.nomadProviders))
+	for _, p := range c.nomadProviders {
+		statuses = append(statuses, p.Status())
+	}
+
+	return statuses
+}
+
+func (c *Collector) certResolverStates() []certResolverRepresentation {
+	states := make([]certResolverRepresentation, 0, len(c.certResolvers))
+	for name, resolver := range c.certResolvers {
+		states = append(states, certResolverRepresentation{
+			Name:         name,
+			Paused:       resolver.Paused(),
+			Certificates: resolver.CertificatesSummary(),
+		})
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Name < states[j].Name
+	})
+
+	return states
+}
+
+func bundleName(t time.Time) string {
+	return fmt.Sprintf("traefik-diagnostics-%s.tar.gz", t.UTC().Format("20060102T150405.000000000Z"))
+}
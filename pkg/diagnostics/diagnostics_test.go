@@ -0,0 +1,148 @@
+package diagnostics
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/provider/acme"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+type fakeCertResolver struct {
+	paused       bool
+	certificates []acme.CertificateSummary
+}
+
+func (r fakeCertResolver) Paused() bool {
+	return r.paused
+}
+
+func (r fakeCertResolver) CertificatesSummary() []acme.CertificateSummary {
+	return r.certificates
+}
+
+type fakeNomadProvider nomad.Status
+
+func (p fakeNomadProvider) Status() nomad.Status {
+	return nomad.Status(p)
+}
+
+func TestCollector_Write(t *testing.T) {
+	notAfter := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	collector := NewCollector(
+		map[string]CertificateResolver{
+			"le": fakeCertResolver{
+				paused: true,
+				certificates: []acme.CertificateSummary{
+					{Domain: types.Domain{Main: "foo.test"}, Store: "default", NotAfter: notAfter},
+				},
+			},
+		},
+		[]NomadProvider{
+			fakeNomadProvider{Name: "nomad", LastError: "connection refused", Services: 2},
+		},
+	)
+
+	collector.ListenConfiguration(dynamic.Configuration{
+		HTTP: &dynamic.HTTPConfiguration{
+			Middlewares: map[string]*dynamic.Middleware{
+				"auth": {BasicAuth: &dynamic.BasicAuth{Users: []string{"user:secret"}}},
+			},
+		},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, collector.Write(&buf))
+
+	files := readBundle(t, &buf)
+
+	assert.Contains(t, files, "version.json")
+	assert.Contains(t, files["goroutines.txt"], "goroutine")
+
+	assert.Contains(t, files["configuration.json"], `"auth"`)
+	assert.NotContains(t, files["configuration.json"], "user:secret")
+
+	var statuses []nomad.Status
+	require.NoError(t, json.Unmarshal([]byte(files["nomad.json"]), &statuses))
+	assert.Equal(t, []nomad.Status{{Name: "nomad", LastError: "connection refused", Services: 2}}, statuses)
+
+	var resolvers []certResolverRepresentation
+	require.NoError(t, json.Unmarshal([]byte(files["acme.json"]), &resolvers))
+	assert.Equal(t, []certResolverRepresentation{
+		{
+			Name:   "le",
+			Paused: true,
+			Certificates: []acme.CertificateSummary{
+				{Domain: types.Domain{Main: "foo.test"}, Store: "default", NotAfter: notAfter},
+			},
+		},
+	}, resolvers)
+}
+
+func TestCollector_WriteFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path, err := NewCollector(nil, nil).WriteFile(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, dir, filepath.Dir(path))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = file.Close() })
+
+	files := readBundle(t, file)
+	assert.Equal(t, "[]", files["nomad.json"])
+	assert.Equal(t, "[]", files["acme.json"])
+}
+
+func TestCollector_ServeHTTP(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewCollector(nil, nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/diagnostics", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/gzip", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Header().Get("Content-Disposition"), "attachment; filename=\"traefik-diagnostics-")
+
+	files := readBundle(t, recorder.Body)
+	assert.Len(t, files, 5)
+}
+
+func readBundle(t *testing.T, r io.Reader) map[string]string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(r)
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+
+		files[header.Name] = string(content)
+	}
+
+	return files
+}
@@ -2,6 +2,7 @@ package healthcheck
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -16,8 +17,10 @@ import (
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -50,8 +53,15 @@ type ServiceHealthChecker struct {
 
 	metrics metricsHealthCheck
 
-	client  *http.Client
-	targets map[string]*url.URL
+	client    *http.Client
+	tlsConfig *tls.Config
+	targets   map[string]*url.URL
+}
+
+// tlsConfigGetter is implemented by the round trippers wrapping an HTTP transport,
+// to give access to its TLS configuration.
+type tlsConfigGetter interface {
+	GetTLSClientConfig() *tls.Config
 }
 
 func NewServiceHealthChecker(ctx context.Context, metrics metricsHealthCheck, config *dynamic.ServerHealthCheck, service StatusSetter, info *runtime.ServiceInfo, transport http.RoundTripper, targets map[string]*url.URL) *ServiceHealthChecker {
@@ -85,14 +95,28 @@ func NewServiceHealthChecker(ctx context.Context, metrics metricsHealthCheck, co
 	}
 
 	return &ServiceHealthChecker{
-		balancer: service,
-		info:     info,
-		config:   config,
-		interval: interval,
-		timeout:  timeout,
-		targets:  targets,
-		client:   client,
-		metrics:  metrics,
+		balancer:  service,
+		info:      info,
+		config:    config,
+		interval:  interval,
+		timeout:   timeout,
+		targets:   targets,
+		client:    client,
+		tlsConfig: getTLSClientConfig(transport),
+		metrics:   metrics,
+	}
+}
+
+// getTLSClientConfig returns the TLS configuration of the transport,
+// used to connect to the gRPC servers over TLS with the same settings as the proxied requests.
+func getTLSClientConfig(transport http.RoundTripper) *tls.Config {
+	switch t := transport.(type) {
+	case *http.Transport:
+		return t.TLSClientConfig
+	case tlsConfigGetter:
+		return t.GetTLSClientConfig()
+	default:
+		return nil
 	}
 }
 
@@ -229,10 +253,25 @@ func (shc *ServiceHealthChecker) checkHealthGRPC(ctx context.Context, serverURL
 
 	serverAddr := net.JoinHostPort(u.Hostname(), port)
 
+	scheme := u.Scheme
+	if shc.config.Scheme != "" {
+		scheme = shc.config.Scheme
+	}
+
 	var opts []grpc.DialOption
-	switch shc.config.Scheme {
+	switch scheme {
 	case "http", "h2c", "":
 		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	default:
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(shc.tlsConfig)))
+	}
+
+	if shc.config.Hostname != "" {
+		opts = append(opts, grpc.WithAuthority(shc.config.Hostname))
+	}
+
+	if len(shc.config.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(shc.config.Headers))
 	}
 
 	conn, err := grpc.DialContext(ctx, serverAddr, opts...)
@@ -15,7 +15,9 @@ import (
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/testhelpers"
+	"google.golang.org/grpc"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
 )
 
 func TestServiceHealthChecker_newRequest(t *testing.T) {
@@ -245,6 +247,54 @@ func TestServiceHealthChecker_checkHealthHTTP_NotFollowingRedirects(t *testing.T
 	assert.False(t, redirectServerCalled, "HTTP redirect must not be followed")
 }
 
+type metadataHealthServer struct {
+	healthpb.UnimplementedHealthServer
+
+	authority string
+	headers   metadata.MD
+}
+
+func (s *metadataHealthServer) Check(ctx context.Context, _ *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	s.headers, _ = metadata.FromIncomingContext(ctx)
+	if authority := s.headers.Get(":authority"); len(authority) > 0 {
+		s.authority = authority[0]
+	}
+
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func TestServiceHealthChecker_checkHealthGRPC_TLS(t *testing.T) {
+	healthServer := &metadataHealthServer{}
+
+	grpcServer := grpc.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	server := httptest.NewUnstartedServer(grpcServer)
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(dynamic.DefaultHealthCheckTimeout))
+	defer cancel()
+
+	config := &dynamic.ServerHealthCheck{
+		Mode:     modeGRPC,
+		Hostname: "example.com",
+		Headers:  map[string]string{"X-Foo": "bar"},
+		Interval: dynamic.DefaultHealthCheckInterval,
+		Timeout:  dynamic.DefaultHealthCheckTimeout,
+	}
+
+	// The transport of the test server client trusts its certificate, valid for example.com.
+	healthChecker := NewServiceHealthChecker(ctx, nil, config, nil, nil, server.Client().Transport, nil)
+
+	err := healthChecker.checkHealthGRPC(ctx, testhelpers.MustParseURL(server.URL))
+	require.NoError(t, err)
+
+	assert.Equal(t, "example.com", healthServer.authority)
+	assert.Equal(t, []string{"bar"}, healthServer.headers.Get("X-Foo"))
+}
+
 func TestServiceHealthChecker_Launch(t *testing.T) {
 	testCases := []struct {
 		desc                  string
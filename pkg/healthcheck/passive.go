@@ -0,0 +1,224 @@
+package healthcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+)
+
+// EjectionSetter should be implemented by a service whose children can be ejected by the passive health check.
+// The ejection is tracked apart from the status set by the active health check,
+// a child being up only when it is neither reported down nor ejected.
+type EjectionSetter interface {
+	// SetEjected ejects, or restores, the given child, and reports whether the child is up afterward.
+	SetEjected(ctx context.Context, childName string, ejected bool) bool
+}
+
+// PassiveServiceHealthChecker ejects the servers of a load-balancer failing the proxied requests,
+// that is responding with a 5xx status code or failing the connection,
+// MaxFailedAttempts times within the FailureWindow, and restores them after the Cooldown.
+// At most MaxEjectionPercent of the servers are ejected at the same time.
+type PassiveServiceHealthChecker struct {
+	balancer EjectionSetter
+	info     *runtime.ServiceInfo
+	metrics  metricsHealthCheck
+
+	maxFailedAttempts  int
+	failureWindow      time.Duration
+	cooldown           time.Duration
+	maxEjectionPercent int
+
+	mu       sync.Mutex
+	servers  int
+	failures map[string][]time.Time
+	ejected  map[string]*time.Timer
+
+	// now is the clock of the checker, replaced in tests.
+	now func() time.Time
+}
+
+// NewPassiveServiceHealthChecker creates a passive health checker of the servers of the balancer.
+func NewPassiveServiceHealthChecker(ctx context.Context, metrics metricsHealthCheck, config *dynamic.PassiveServerHealthCheck, balancer EjectionSetter, info *runtime.ServiceInfo) *PassiveServiceHealthChecker {
+	logger := log.Ctx(ctx)
+
+	defaults := &dynamic.PassiveServerHealthCheck{}
+	defaults.SetDefaults()
+
+	maxFailedAttempts := config.MaxFailedAttempts
+	if maxFailedAttempts <= 0 {
+		logger.Error().Msg("Passive health check maximum failed attempts smaller than one")
+		maxFailedAttempts = defaults.MaxFailedAttempts
+	}
+
+	failureWindow := time.Duration(config.FailureWindow)
+	if failureWindow <= 0 {
+		logger.Error().Msg("Passive health check failure window smaller than or equal to zero")
+		failureWindow = time.Duration(defaults.FailureWindow)
+	}
+
+	cooldown := time.Duration(config.Cooldown)
+	if cooldown <= 0 {
+		logger.Error().Msg("Passive health check cooldown smaller than or equal to zero")
+		cooldown = time.Duration(defaults.Cooldown)
+	}
+
+	maxEjectionPercent := config.MaxEjectionPercent
+	if maxEjectionPercent <= 0 || maxEjectionPercent > 100 {
+		logger.Error().Msg("Passive health check maximum ejection percentage not between 1 and 100")
+		maxEjectionPercent = defaults.MaxEjectionPercent
+	}
+
+	return &PassiveServiceHealthChecker{
+		balancer:           balancer,
+		info:               info,
+		metrics:            metrics,
+		maxFailedAttempts:  maxFailedAttempts,
+		failureWindow:      failureWindow,
+		cooldown:           cooldown,
+		maxEjectionPercent: maxEjectionPercent,
+		failures:           make(map[string][]time.Time),
+		ejected:            make(map[string]*time.Timer),
+		now:                time.Now,
+	}
+}
+
+// WrapHandler wraps the handler of a server of the balancer, to record the failures of its requests.
+func (p *PassiveServiceHealthChecker) WrapHandler(ctx context.Context, name string, target *url.URL, next http.Handler) http.Handler {
+	p.mu.Lock()
+	p.servers++
+	p.mu.Unlock()
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		recorder := &statusRecorder{ResponseWriter: rw}
+		next.ServeHTTP(recorder, req)
+
+		// no status means that the server refused the connection and the request was retried on another server.
+		if recorder.status == 0 || recorder.status >= http.StatusInternalServerError {
+			p.recordFailure(ctx, name, target)
+		}
+	})
+}
+
+func (p *PassiveServiceHealthChecker) recordFailure(ctx context.Context, name string, target *url.URL) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.ejected[name]; ok {
+		return
+	}
+
+	now := p.now()
+
+	// only the failures within the window are kept.
+	failures := p.failures[name][:0]
+	for _, failure := range p.failures[name] {
+		if now.Sub(failure) < p.failureWindow {
+			failures = append(failures, failure)
+		}
+	}
+	failures = append(failures, now)
+
+	if len(failures) < p.maxFailedAttempts {
+		p.failures[name] = failures
+		return
+	}
+
+	delete(p.failures, name)
+
+	// the failing servers are kept in the rotation once the maximum share of servers is ejected,
+	// not to overload the remaining ones.
+	if (len(p.ejected)+1)*100 > p.servers*p.maxEjectionPercent {
+		log.Ctx(ctx).Warn().Str("targetURL", target.String()).
+			Msgf("Not ejecting the server after %d failed requests within %s, %d%% of the servers being ejected at most", len(failures), p.failureWindow, p.maxEjectionPercent)
+		return
+	}
+
+	log.Ctx(ctx).Warn().Str("targetURL", target.String()).
+		Msgf("Ejecting the server for %s after %d failed requests within %s", p.cooldown, len(failures), p.failureWindow)
+
+	p.balancer.SetEjected(ctx, name, true)
+	p.updateStatus(name, target, false)
+
+	p.ejected[name] = time.AfterFunc(p.cooldown, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		delete(p.ejected, name)
+
+		log.Ctx(ctx).Info().Str("targetURL", target.String()).Msg("Restoring the ejected server")
+
+		// the server stays down when the active health check reports it down.
+		if p.balancer.SetEjected(ctx, name, false) {
+			p.updateStatus(name, target, true)
+		}
+	})
+}
+
+func (p *PassiveServiceHealthChecker) updateStatus(name string, target *url.URL, up bool) {
+	status, gaugeValue := runtime.StatusDown, 0.0
+	if up {
+		status, gaugeValue = runtime.StatusUp, 1.0
+	}
+
+	if p.info != nil {
+		p.info.UpdateServerStatus(target.String(), status)
+	}
+
+	if p.metrics != nil {
+		p.metrics.ServiceServerUpGauge().
+			With("service", name, "url", target.String()).
+			Set(gaugeValue)
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader captures the status code of the response.
+func (s *statusRecorder) WriteHeader(status int) {
+	// the informational responses are followed by the final response.
+	if status >= http.StatusOK || status == http.StatusSwitchingProtocols {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+// Hijack hijacks the connection.
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", s.ResponseWriter)
+	}
+
+	// the response of a connection upgrade is written on the hijacked connection.
+	if s.status == 0 {
+		s.status = http.StatusSwitchingProtocols
+	}
+
+	return hijacker.Hijack()
+}
+
+// Flush sends any buffered data to the client.
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,227 @@
+package healthcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/testhelpers"
+)
+
+type statusBalancer struct {
+	mu     sync.Mutex
+	status map[string]bool
+	// down are the children reported down by the active health check.
+	down map[string]bool
+}
+
+func (b *statusBalancer) SetEjected(_ context.Context, childName string, ejected bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.status[childName] = !ejected && !b.down[childName]
+	return b.status[childName]
+}
+
+func (b *statusBalancer) get(childName string) (bool, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	up, ok := b.status[childName]
+	return up, ok
+}
+
+func TestPassiveServiceHealthChecker(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		handler       http.HandlerFunc
+		requests      int
+		interval      time.Duration
+		expectEjected bool
+	}{
+		{
+			desc: "successful requests",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("ok"))
+			},
+			requests: 5,
+		},
+		{
+			desc: "client errors",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusNotFound)
+			},
+			requests: 5,
+		},
+		{
+			desc: "not enough server errors",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusBadGateway)
+			},
+			requests: 2,
+		},
+		{
+			desc: "server errors",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+			},
+			requests:      3,
+			expectEjected: true,
+		},
+		{
+			desc:          "connection refused and retried",
+			handler:       func(rw http.ResponseWriter, req *http.Request) {},
+			requests:      3,
+			expectEjected: true,
+		},
+		{
+			desc: "server errors outside of the failure window",
+			handler: func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusInternalServerError)
+			},
+			requests: 5,
+			interval: 6 * time.Second,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer := &statusBalancer{status: make(map[string]bool)}
+			info := &runtime.ServiceInfo{}
+			gauge := &testhelpers.CollectingGauge{}
+
+			config := &dynamic.PassiveServerHealthCheck{
+				MaxFailedAttempts:  3,
+				FailureWindow:      ptypes.Duration(10 * time.Second),
+				Cooldown:           ptypes.Duration(time.Hour),
+				MaxEjectionPercent: 100,
+			}
+
+			checker := NewPassiveServiceHealthChecker(context.Background(), &MetricsMock{gauge}, config, balancer, info)
+
+			now := time.Now()
+			checker.now = func() time.Time { return now }
+
+			target := testhelpers.MustParseURL("http://127.0.0.1:8080")
+			handler := checker.WrapHandler(context.Background(), "server", target, test.handler)
+
+			for i := 0; i < test.requests; i++ {
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.bar", nil))
+				now = now.Add(test.interval)
+			}
+
+			up, ok := balancer.get("server")
+			if !test.expectEjected {
+				assert.False(t, ok)
+				return
+			}
+
+			require.True(t, ok)
+			assert.False(t, up)
+			assert.Equal(t, map[string]string{target.String(): runtime.StatusDown}, info.GetAllStatus())
+			assert.Equal(t, float64(0), gauge.GaugeValue)
+		})
+	}
+}
+
+func TestPassiveServiceHealthChecker_cooldown(t *testing.T) {
+	balancer := &statusBalancer{status: make(map[string]bool)}
+
+	config := &dynamic.PassiveServerHealthCheck{
+		MaxFailedAttempts:  1,
+		FailureWindow:      ptypes.Duration(10 * time.Second),
+		Cooldown:           ptypes.Duration(100 * time.Millisecond),
+		MaxEjectionPercent: 100,
+	}
+
+	checker := NewPassiveServiceHealthChecker(context.Background(), nil, config, balancer, nil)
+
+	handler := checker.WrapHandler(context.Background(), "server", testhelpers.MustParseURL("http://127.0.0.1:8080"), http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.bar", nil))
+
+	up, ok := balancer.get("server")
+	require.True(t, ok)
+	assert.False(t, up)
+
+	assert.Eventually(t, func() bool {
+		up, _ := balancer.get("server")
+		return up
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestPassiveServiceHealthChecker_cooldownActiveDown(t *testing.T) {
+	balancer := &statusBalancer{status: make(map[string]bool), down: map[string]bool{"server": true}}
+	info := &runtime.ServiceInfo{}
+
+	config := &dynamic.PassiveServerHealthCheck{
+		MaxFailedAttempts:  1,
+		FailureWindow:      ptypes.Duration(10 * time.Second),
+		Cooldown:           ptypes.Duration(10 * time.Millisecond),
+		MaxEjectionPercent: 100,
+	}
+
+	checker := NewPassiveServiceHealthChecker(context.Background(), nil, config, balancer, info)
+
+	target := testhelpers.MustParseURL("http://127.0.0.1:8080")
+	handler := checker.WrapHandler(context.Background(), "server", target, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.bar", nil))
+
+	assert.Eventually(t, func() bool {
+		checker.mu.Lock()
+		defer checker.mu.Unlock()
+
+		return len(checker.ejected) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// the server reported down by the active health check is not restored at the end of the cooldown.
+	up, ok := balancer.get("server")
+	require.True(t, ok)
+	assert.False(t, up)
+	assert.Equal(t, map[string]string{target.String(): runtime.StatusDown}, info.GetAllStatus())
+}
+
+func TestPassiveServiceHealthChecker_maxEjectionPercent(t *testing.T) {
+	balancer := &statusBalancer{status: make(map[string]bool)}
+
+	config := &dynamic.PassiveServerHealthCheck{
+		MaxFailedAttempts:  1,
+		FailureWindow:      ptypes.Duration(10 * time.Second),
+		Cooldown:           ptypes.Duration(time.Hour),
+		MaxEjectionPercent: 50,
+	}
+
+	checker := NewPassiveServiceHealthChecker(context.Background(), nil, config, balancer, nil)
+
+	failing := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusInternalServerError)
+	})
+
+	var handlers []http.Handler
+	for _, name := range []string{"server1", "server2", "server3", "server4"} {
+		handlers = append(handlers, checker.WrapHandler(context.Background(), name, testhelpers.MustParseURL("http://"+name), failing))
+	}
+
+	for _, handler := range handlers {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://foo.bar", nil))
+	}
+
+	// only half of the servers are ejected.
+	assert.Equal(t, map[string]bool{"server1": false, "server2": false}, balancer.status)
+}
@@ -22,6 +22,13 @@ const (
 	ddLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
 	ddOpenConnsName               = "open.connections"
 
+	ddProviderDiscoveryUpName    = "provider.discovery.up"
+	ddProviderConfigErrorsName   = "provider.config.errors"
+	ddProviderDriftName          = "provider.drift.total"
+	ddProviderAPIReqsName        = "provider.api.request.total"
+	ddProviderAPIReqDurationName = "provider.api.request.duration"
+	ddProviderAPIRetriesName     = "provider.api.retries.total"
+
 	ddTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
 
 	ddEntryPointReqsName        = "entrypoint.request.total"
@@ -36,6 +43,10 @@ const (
 	ddRouterReqsBytesName    = "router.requests.bytes.total"
 	ddRouterRespsBytesName   = "router.responses.bytes.total"
 
+	ddMiddlewareReqsDurationName = "middleware.request.duration"
+	ddMiddlewareReqsSpooledName  = "middleware.requests.spooled.total"
+	ddMiddlewareBotReqsName      = "middleware.bot.requests.total"
+
 	ddServiceReqsName         = "service.request.total"
 	ddServiceReqsTLSName      = "service.request.tls.total"
 	ddServiceReqsDurationName = "service.request.duration"
@@ -63,9 +74,16 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 		configReloadsCounter:           datadogClient.NewCounter(ddConfigReloadsName, 1.0),
 		lastConfigReloadSuccessGauge:   datadogClient.NewGauge(ddLastConfigReloadSuccessName),
 		openConnectionsGauge:           datadogClient.NewGauge(ddOpenConnsName),
+		providerDiscoveryUpGauge:       datadogClient.NewGauge(ddProviderDiscoveryUpName),
+		providerConfigErrorsGauge:      datadogClient.NewGauge(ddProviderConfigErrorsName),
+		providerDriftCounter:           datadogClient.NewCounter(ddProviderDriftName, 1.0),
+		providerAPIReqsCounter:         datadogClient.NewCounter(ddProviderAPIReqsName, 1.0),
+		providerAPIRetriesCounter:      datadogClient.NewCounter(ddProviderAPIRetriesName, 1.0),
 		tlsCertsNotAfterTimestampGauge: datadogClient.NewGauge(ddTLSCertsNotAfterTimestampName),
 	}
 
+	registry.providerAPIReqDurationHistogram, _ = NewHistogramWithScale(datadogClient.NewHistogram(ddProviderAPIReqDurationName, 1.0), time.Second)
+
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
 		registry.entryPointReqsCounter = NewCounterWithNoopHeaders(datadogClient.NewCounter(ddEntryPointReqsName, 1.0))
@@ -84,6 +102,13 @@ func RegisterDatadog(ctx context.Context, config *types.Datadog) Registry {
 		registry.routerRespsBytesCounter = datadogClient.NewCounter(ddRouterRespsBytesName, 1.0)
 	}
 
+	if config.AddMiddlewaresLabels {
+		registry.middlewareEnabled = config.AddMiddlewaresLabels
+		registry.middlewareReqDurationHistogram, _ = NewHistogramWithScale(datadogClient.NewHistogram(ddMiddlewareReqsDurationName, 1.0), time.Second)
+		registry.middlewareReqsSpooledCounter = datadogClient.NewCounter(ddMiddlewareReqsSpooledName, 1.0)
+		registry.middlewareBotReqsCounter = datadogClient.NewCounter(ddMiddlewareBotReqsName, 1.0)
+	}
+
 	if config.AddServicesLabels {
 		registry.svcEnabled = config.AddServicesLabels
 		registry.serviceReqsCounter = NewCounterWithNoopHeaders(datadogClient.NewCounter(ddServiceReqsName, 1.0))
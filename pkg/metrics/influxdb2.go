@@ -28,6 +28,13 @@ const (
 	influxDBLastConfigReloadSuccessName = "traefik.config.reload.lastSuccessTimestamp"
 	influxDBOpenConnsName               = "traefik.open.connections"
 
+	influxDBProviderDiscoveryUpName    = "traefik.provider.discovery.up"
+	influxDBProviderConfigErrorsName   = "traefik.provider.config.errors"
+	influxDBProviderDriftName          = "traefik.provider.drift.total"
+	influxDBProviderAPIReqsName        = "traefik.provider.api.request.total"
+	influxDBProviderAPIReqDurationName = "traefik.provider.api.request.duration"
+	influxDBProviderAPIRetriesName     = "traefik.provider.api.retries.total"
+
 	influxDBTLSCertsNotAfterTimestampName = "traefik.tls.certs.notAfterTimestamp"
 
 	influxDBEntryPointReqsName        = "traefik.entrypoint.requests.total"
@@ -42,6 +49,10 @@ const (
 	influxDBRouterReqsBytesName    = "traefik.router.requests.bytes.total"
 	influxDBRouterRespsBytesName   = "traefik.router.responses.bytes.total"
 
+	influxDBMiddlewareReqsDurationName = "traefik.middleware.request.duration"
+	influxDBMiddlewareReqsSpooledName  = "traefik.middleware.requests.spooled.total"
+	influxDBMiddlewareBotReqsName      = "traefik.middleware.bot.requests.total"
+
 	influxDBServiceReqsName         = "traefik.service.requests.total"
 	influxDBServiceReqsTLSName      = "traefik.service.requests.tls.total"
 	influxDBServiceReqsDurationName = "traefik.service.request.duration"
@@ -82,9 +93,16 @@ func RegisterInfluxDB2(ctx context.Context, config *types.InfluxDB2) Registry {
 		configReloadsCounter:           influxDB2Store.NewCounter(influxDBConfigReloadsName),
 		lastConfigReloadSuccessGauge:   influxDB2Store.NewGauge(influxDBLastConfigReloadSuccessName),
 		openConnectionsGauge:           influxDB2Store.NewGauge(influxDBOpenConnsName),
+		providerDiscoveryUpGauge:       influxDB2Store.NewGauge(influxDBProviderDiscoveryUpName),
+		providerConfigErrorsGauge:      influxDB2Store.NewGauge(influxDBProviderConfigErrorsName),
+		providerDriftCounter:           influxDB2Store.NewCounter(influxDBProviderDriftName),
+		providerAPIReqsCounter:         influxDB2Store.NewCounter(influxDBProviderAPIReqsName),
+		providerAPIRetriesCounter:      influxDB2Store.NewCounter(influxDBProviderAPIRetriesName),
 		tlsCertsNotAfterTimestampGauge: influxDB2Store.NewGauge(influxDBTLSCertsNotAfterTimestampName),
 	}
 
+	registry.providerAPIReqDurationHistogram, _ = NewHistogramWithScale(influxDB2Store.NewHistogram(influxDBProviderAPIReqDurationName), time.Second)
+
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
 		registry.entryPointReqsCounter = NewCounterWithNoopHeaders(influxDB2Store.NewCounter(influxDBEntryPointReqsName))
@@ -103,6 +121,13 @@ func RegisterInfluxDB2(ctx context.Context, config *types.InfluxDB2) Registry {
 		registry.routerRespsBytesCounter = influxDB2Store.NewCounter(influxDBRouterRespsBytesName)
 	}
 
+	if config.AddMiddlewaresLabels {
+		registry.middlewareEnabled = config.AddMiddlewaresLabels
+		registry.middlewareReqDurationHistogram, _ = NewHistogramWithScale(influxDB2Store.NewHistogram(influxDBMiddlewareReqsDurationName), time.Second)
+		registry.middlewareReqsSpooledCounter = influxDB2Store.NewCounter(influxDBMiddlewareReqsSpooledName)
+		registry.middlewareBotReqsCounter = influxDB2Store.NewCounter(influxDBMiddlewareBotReqsName)
+	}
+
 	if config.AddServicesLabels {
 		registry.svcEnabled = config.AddServicesLabels
 		registry.serviceReqsCounter = NewCounterWithNoopHeaders(influxDB2Store.NewCounter(influxDBServiceReqsName))
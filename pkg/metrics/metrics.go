@@ -16,6 +16,8 @@ type Registry interface {
 	IsEpEnabled() bool
 	// IsRouterEnabled shows whether metrics instrumentation is enabled on routers.
 	IsRouterEnabled() bool
+	// IsMiddlewareEnabled shows whether metrics instrumentation is enabled on the middlewares of the routers.
+	IsMiddlewareEnabled() bool
 	// IsSvcEnabled shows whether metrics instrumentation is enabled on services.
 	IsSvcEnabled() bool
 
@@ -25,9 +27,19 @@ type Registry interface {
 	LastConfigReloadSuccessGauge() metrics.Gauge
 	OpenConnectionsGauge() metrics.Gauge
 
+	// provider metrics
+
+	ProviderDiscoveryUpGauge() metrics.Gauge
+	ProviderConfigErrorsGauge() metrics.Gauge
+	ProviderDriftCounter() metrics.Counter
+	ProviderAPIReqsCounter() metrics.Counter
+	ProviderAPIReqDurationHistogram() ScalableHistogram
+	ProviderAPIRetriesCounter() metrics.Counter
+
 	// TLS
 
 	TLSCertsNotAfterTimestampGauge() metrics.Gauge
+	TLSCertsMissingSCTGauge() metrics.Gauge
 
 	// entry point metrics
 
@@ -45,6 +57,12 @@ type Registry interface {
 	RouterReqsBytesCounter() metrics.Counter
 	RouterRespsBytesCounter() metrics.Counter
 
+	// middleware metrics
+
+	MiddlewareReqDurationHistogram() ScalableHistogram
+	MiddlewareReqsSpooledCounter() metrics.Counter
+	MiddlewareBotReqsCounter() metrics.Counter
+
 	// service metrics
 
 	ServiceReqsCounter() CounterWithHeaders
@@ -69,7 +87,14 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var configReloadsCounter []metrics.Counter
 	var lastConfigReloadSuccessGauge []metrics.Gauge
 	var openConnectionsGauge []metrics.Gauge
+	var providerDiscoveryUpGauge []metrics.Gauge
+	var providerConfigErrorsGauge []metrics.Gauge
+	var providerDriftCounter []metrics.Counter
+	var providerAPIReqsCounter []metrics.Counter
+	var providerAPIReqDurationHistogram []ScalableHistogram
+	var providerAPIRetriesCounter []metrics.Counter
 	var tlsCertsNotAfterTimestampGauge []metrics.Gauge
+	var tlsCertsMissingSCTGauge []metrics.Gauge
 	var entryPointReqsCounter []CounterWithHeaders
 	var entryPointReqsTLSCounter []metrics.Counter
 	var entryPointReqDurationHistogram []ScalableHistogram
@@ -80,6 +105,9 @@ func NewMultiRegistry(registries []Registry) Registry {
 	var routerReqDurationHistogram []ScalableHistogram
 	var routerReqsBytesCounter []metrics.Counter
 	var routerRespsBytesCounter []metrics.Counter
+	var middlewareReqDurationHistogram []ScalableHistogram
+	var middlewareReqsSpooledCounter []metrics.Counter
+	var middlewareBotReqsCounter []metrics.Counter
 	var serviceReqsCounter []CounterWithHeaders
 	var serviceReqsTLSCounter []metrics.Counter
 	var serviceReqDurationHistogram []ScalableHistogram
@@ -98,9 +126,30 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.OpenConnectionsGauge() != nil {
 			openConnectionsGauge = append(openConnectionsGauge, r.OpenConnectionsGauge())
 		}
+		if r.ProviderDiscoveryUpGauge() != nil {
+			providerDiscoveryUpGauge = append(providerDiscoveryUpGauge, r.ProviderDiscoveryUpGauge())
+		}
+		if r.ProviderConfigErrorsGauge() != nil {
+			providerConfigErrorsGauge = append(providerConfigErrorsGauge, r.ProviderConfigErrorsGauge())
+		}
+		if r.ProviderDriftCounter() != nil {
+			providerDriftCounter = append(providerDriftCounter, r.ProviderDriftCounter())
+		}
+		if r.ProviderAPIReqsCounter() != nil {
+			providerAPIReqsCounter = append(providerAPIReqsCounter, r.ProviderAPIReqsCounter())
+		}
+		if r.ProviderAPIReqDurationHistogram() != nil {
+			providerAPIReqDurationHistogram = append(providerAPIReqDurationHistogram, r.ProviderAPIReqDurationHistogram())
+		}
+		if r.ProviderAPIRetriesCounter() != nil {
+			providerAPIRetriesCounter = append(providerAPIRetriesCounter, r.ProviderAPIRetriesCounter())
+		}
 		if r.TLSCertsNotAfterTimestampGauge() != nil {
 			tlsCertsNotAfterTimestampGauge = append(tlsCertsNotAfterTimestampGauge, r.TLSCertsNotAfterTimestampGauge())
 		}
+		if r.TLSCertsMissingSCTGauge() != nil {
+			tlsCertsMissingSCTGauge = append(tlsCertsMissingSCTGauge, r.TLSCertsMissingSCTGauge())
+		}
 		if r.EntryPointReqsCounter() != nil {
 			entryPointReqsCounter = append(entryPointReqsCounter, r.EntryPointReqsCounter())
 		}
@@ -131,6 +180,15 @@ func NewMultiRegistry(registries []Registry) Registry {
 		if r.RouterRespsBytesCounter() != nil {
 			routerRespsBytesCounter = append(routerRespsBytesCounter, r.RouterRespsBytesCounter())
 		}
+		if r.MiddlewareReqDurationHistogram() != nil {
+			middlewareReqDurationHistogram = append(middlewareReqDurationHistogram, r.MiddlewareReqDurationHistogram())
+		}
+		if r.MiddlewareReqsSpooledCounter() != nil {
+			middlewareReqsSpooledCounter = append(middlewareReqsSpooledCounter, r.MiddlewareReqsSpooledCounter())
+		}
+		if r.MiddlewareBotReqsCounter() != nil {
+			middlewareBotReqsCounter = append(middlewareBotReqsCounter, r.MiddlewareBotReqsCounter())
+		}
 		if r.ServiceReqsCounter() != nil {
 			serviceReqsCounter = append(serviceReqsCounter, r.ServiceReqsCounter())
 		}
@@ -155,58 +213,80 @@ func NewMultiRegistry(registries []Registry) Registry {
 	}
 
 	return &standardRegistry{
-		epEnabled:                      len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0,
-		svcEnabled:                     len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
-		routerEnabled:                  len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0,
-		configReloadsCounter:           multi.NewCounter(configReloadsCounter...),
-		lastConfigReloadSuccessGauge:   multi.NewGauge(lastConfigReloadSuccessGauge...),
-		openConnectionsGauge:           multi.NewGauge(openConnectionsGauge...),
-		tlsCertsNotAfterTimestampGauge: multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
-		entryPointReqsCounter:          NewMultiCounterWithHeaders(entryPointReqsCounter...),
-		entryPointReqsTLSCounter:       multi.NewCounter(entryPointReqsTLSCounter...),
-		entryPointReqDurationHistogram: MultiHistogram(entryPointReqDurationHistogram),
-		entryPointReqsBytesCounter:     multi.NewCounter(entryPointReqsBytesCounter...),
-		entryPointRespsBytesCounter:    multi.NewCounter(entryPointRespsBytesCounter...),
-		routerReqsCounter:              NewMultiCounterWithHeaders(routerReqsCounter...),
-		routerReqsTLSCounter:           multi.NewCounter(routerReqsTLSCounter...),
-		routerReqDurationHistogram:     MultiHistogram(routerReqDurationHistogram),
-		routerReqsBytesCounter:         multi.NewCounter(routerReqsBytesCounter...),
-		routerRespsBytesCounter:        multi.NewCounter(routerRespsBytesCounter...),
-		serviceReqsCounter:             NewMultiCounterWithHeaders(serviceReqsCounter...),
-		serviceReqsTLSCounter:          multi.NewCounter(serviceReqsTLSCounter...),
-		serviceReqDurationHistogram:    MultiHistogram(serviceReqDurationHistogram),
-		serviceRetriesCounter:          multi.NewCounter(serviceRetriesCounter...),
-		serviceServerUpGauge:           multi.NewGauge(serviceServerUpGauge...),
-		serviceReqsBytesCounter:        multi.NewCounter(serviceReqsBytesCounter...),
-		serviceRespsBytesCounter:       multi.NewCounter(serviceRespsBytesCounter...),
+		epEnabled:                       len(entryPointReqsCounter) > 0 || len(entryPointReqDurationHistogram) > 0,
+		svcEnabled:                      len(serviceReqsCounter) > 0 || len(serviceReqDurationHistogram) > 0 || len(serviceRetriesCounter) > 0 || len(serviceServerUpGauge) > 0,
+		routerEnabled:                   len(routerReqsCounter) > 0 || len(routerReqDurationHistogram) > 0,
+		middlewareEnabled:               len(middlewareReqDurationHistogram) > 0 || len(middlewareReqsSpooledCounter) > 0 || len(middlewareBotReqsCounter) > 0,
+		configReloadsCounter:            multi.NewCounter(configReloadsCounter...),
+		lastConfigReloadSuccessGauge:    multi.NewGauge(lastConfigReloadSuccessGauge...),
+		openConnectionsGauge:            multi.NewGauge(openConnectionsGauge...),
+		providerDiscoveryUpGauge:        multi.NewGauge(providerDiscoveryUpGauge...),
+		providerConfigErrorsGauge:       multi.NewGauge(providerConfigErrorsGauge...),
+		providerDriftCounter:            multi.NewCounter(providerDriftCounter...),
+		providerAPIReqsCounter:          multi.NewCounter(providerAPIReqsCounter...),
+		providerAPIReqDurationHistogram: MultiHistogram(providerAPIReqDurationHistogram),
+		providerAPIRetriesCounter:       multi.NewCounter(providerAPIRetriesCounter...),
+		tlsCertsNotAfterTimestampGauge:  multi.NewGauge(tlsCertsNotAfterTimestampGauge...),
+		tlsCertsMissingSCTGauge:         multi.NewGauge(tlsCertsMissingSCTGauge...),
+		entryPointReqsCounter:           NewMultiCounterWithHeaders(entryPointReqsCounter...),
+		entryPointReqsTLSCounter:        multi.NewCounter(entryPointReqsTLSCounter...),
+		entryPointReqDurationHistogram:  MultiHistogram(entryPointReqDurationHistogram),
+		entryPointReqsBytesCounter:      multi.NewCounter(entryPointReqsBytesCounter...),
+		entryPointRespsBytesCounter:     multi.NewCounter(entryPointRespsBytesCounter...),
+		routerReqsCounter:               NewMultiCounterWithHeaders(routerReqsCounter...),
+		routerReqsTLSCounter:            multi.NewCounter(routerReqsTLSCounter...),
+		routerReqDurationHistogram:      MultiHistogram(routerReqDurationHistogram),
+		routerReqsBytesCounter:          multi.NewCounter(routerReqsBytesCounter...),
+		routerRespsBytesCounter:         multi.NewCounter(routerRespsBytesCounter...),
+		middlewareReqDurationHistogram:  MultiHistogram(middlewareReqDurationHistogram),
+		middlewareReqsSpooledCounter:    multi.NewCounter(middlewareReqsSpooledCounter...),
+		middlewareBotReqsCounter:        multi.NewCounter(middlewareBotReqsCounter...),
+		serviceReqsCounter:              NewMultiCounterWithHeaders(serviceReqsCounter...),
+		serviceReqsTLSCounter:           multi.NewCounter(serviceReqsTLSCounter...),
+		serviceReqDurationHistogram:     MultiHistogram(serviceReqDurationHistogram),
+		serviceRetriesCounter:           multi.NewCounter(serviceRetriesCounter...),
+		serviceServerUpGauge:            multi.NewGauge(serviceServerUpGauge...),
+		serviceReqsBytesCounter:         multi.NewCounter(serviceReqsBytesCounter...),
+		serviceRespsBytesCounter:        multi.NewCounter(serviceRespsBytesCounter...),
 	}
 }
 
 type standardRegistry struct {
-	epEnabled                      bool
-	routerEnabled                  bool
-	svcEnabled                     bool
-	configReloadsCounter           metrics.Counter
-	lastConfigReloadSuccessGauge   metrics.Gauge
-	openConnectionsGauge           metrics.Gauge
-	tlsCertsNotAfterTimestampGauge metrics.Gauge
-	entryPointReqsCounter          CounterWithHeaders
-	entryPointReqsTLSCounter       metrics.Counter
-	entryPointReqDurationHistogram ScalableHistogram
-	entryPointReqsBytesCounter     metrics.Counter
-	entryPointRespsBytesCounter    metrics.Counter
-	routerReqsCounter              CounterWithHeaders
-	routerReqsTLSCounter           metrics.Counter
-	routerReqDurationHistogram     ScalableHistogram
-	routerReqsBytesCounter         metrics.Counter
-	routerRespsBytesCounter        metrics.Counter
-	serviceReqsCounter             CounterWithHeaders
-	serviceReqsTLSCounter          metrics.Counter
-	serviceReqDurationHistogram    ScalableHistogram
-	serviceRetriesCounter          metrics.Counter
-	serviceServerUpGauge           metrics.Gauge
-	serviceReqsBytesCounter        metrics.Counter
-	serviceRespsBytesCounter       metrics.Counter
+	epEnabled                       bool
+	routerEnabled                   bool
+	middlewareEnabled               bool
+	svcEnabled                      bool
+	configReloadsCounter            metrics.Counter
+	lastConfigReloadSuccessGauge    metrics.Gauge
+	openConnectionsGauge            metrics.Gauge
+	providerDiscoveryUpGauge        metrics.Gauge
+	providerConfigErrorsGauge       metrics.Gauge
+	providerDriftCounter            metrics.Counter
+	providerAPIReqsCounter          metrics.Counter
+	providerAPIReqDurationHistogram ScalableHistogram
+	providerAPIRetriesCounter       metrics.Counter
+	tlsCertsNotAfterTimestampGauge  metrics.Gauge
+	tlsCertsMissingSCTGauge         metrics.Gauge
+	entryPointReqsCounter           CounterWithHeaders
+	entryPointReqsTLSCounter        metrics.Counter
+	entryPointReqDurationHistogram  ScalableHistogram
+	entryPointReqsBytesCounter      metrics.Counter
+	entryPointRespsBytesCounter     metrics.Counter
+	routerReqsCounter               CounterWithHeaders
+	routerReqsTLSCounter            metrics.Counter
+	routerReqDurationHistogram      ScalableHistogram
+	routerReqsBytesCounter          metrics.Counter
+	routerRespsBytesCounter         metrics.Counter
+	middlewareReqDurationHistogram  ScalableHistogram
+	middlewareReqsSpooledCounter    metrics.Counter
+	middlewareBotReqsCounter        metrics.Counter
+	serviceReqsCounter              CounterWithHeaders
+	serviceReqsTLSCounter           metrics.Counter
+	serviceReqDurationHistogram     ScalableHistogram
+	serviceRetriesCounter           metrics.Counter
+	serviceServerUpGauge            metrics.Gauge
+	serviceReqsBytesCounter         metrics.Counter
+	serviceRespsBytesCounter        metrics.Counter
 }
 
 func (r *standardRegistry) IsEpEnabled() bool {
@@ -217,6 +297,10 @@ func (r *standardRegistry) IsRouterEnabled() bool {
 	return r.routerEnabled
 }
 
+func (r *standardRegistry) IsMiddlewareEnabled() bool {
+	return r.middlewareEnabled
+}
+
 func (r *standardRegistry) IsSvcEnabled() bool {
 	return r.svcEnabled
 }
@@ -233,10 +317,38 @@ func (r *standardRegistry) OpenConnectionsGauge() metrics.Gauge {
 	return r.openConnectionsGauge
 }
 
+func (r *standardRegistry) ProviderDiscoveryUpGauge() metrics.Gauge {
+	return r.providerDiscoveryUpGauge
+}
+
+func (r *standardRegistry) ProviderConfigErrorsGauge() metrics.Gauge {
+	return r.providerConfigErrorsGauge
+}
+
+func (r *standardRegistry) ProviderDriftCounter() metrics.Counter {
+	return r.providerDriftCounter
+}
+
+func (r *standardRegistry) ProviderAPIReqsCounter() metrics.Counter {
+	return r.providerAPIReqsCounter
+}
+
+func (r *standardRegistry) ProviderAPIReqDurationHistogram() ScalableHistogram {
+	return r.providerAPIReqDurationHistogram
+}
+
+func (r *standardRegistry) ProviderAPIRetriesCounter() metrics.Counter {
+	return r.providerAPIRetriesCounter
+}
+
 func (r *standardRegistry) TLSCertsNotAfterTimestampGauge() metrics.Gauge {
 	return r.tlsCertsNotAfterTimestampGauge
 }
 
+func (r *standardRegistry) TLSCertsMissingSCTGauge() metrics.Gauge {
+	return r.tlsCertsMissingSCTGauge
+}
+
 func (r *standardRegistry) EntryPointReqsCounter() CounterWithHeaders {
 	return r.entryPointReqsCounter
 }
@@ -277,6 +389,18 @@ func (r *standardRegistry) RouterRespsBytesCounter() metrics.Counter {
 	return r.routerRespsBytesCounter
 }
 
+func (r *standardRegistry) MiddlewareReqDurationHistogram() ScalableHistogram {
+	return r.middlewareReqDurationHistogram
+}
+
+func (r *standardRegistry) MiddlewareReqsSpooledCounter() metrics.Counter {
+	return r.middlewareReqsSpooledCounter
+}
+
+func (r *standardRegistry) MiddlewareBotReqsCounter() metrics.Counter {
+	return r.middlewareBotReqsCounter
+}
+
 func (r *standardRegistry) ServiceReqsCounter() CounterWithHeaders {
 	return r.serviceReqsCounter
 }
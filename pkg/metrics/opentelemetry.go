@@ -55,12 +55,21 @@ func RegisterOpenTelemetry(ctx context.Context, config *types.OpenTelemetry) Reg
 	reg := &standardRegistry{
 		epEnabled:                      config.AddEntryPointsLabels,
 		routerEnabled:                  config.AddRoutersLabels,
+		middlewareEnabled:              config.AddMiddlewaresLabels,
 		svcEnabled:                     config.AddServicesLabels,
 		configReloadsCounter:           newOTLPCounterFrom(meter, configReloadsTotalName, "Config reloads"),
 		lastConfigReloadSuccessGauge:   newOTLPGaugeFrom(meter, configLastReloadSuccessName, "Last config reload success", unit.Milliseconds),
 		openConnectionsGauge:           newOTLPGaugeFrom(meter, openConnectionsName, "How many open connections exist, by entryPoint and protocol", unit.Dimensionless),
+		providerDiscoveryUpGauge:       newOTLPGaugeFrom(meter, providerDiscoveryUpName, "Whether the last refresh of the services of a provider succeeded, partitioned by provider", unit.Dimensionless),
+		providerConfigErrorsGauge:      newOTLPGaugeFrom(meter, providerConfigErrorsName, "How many services of a provider have a configuration which cannot be applied, on the last refresh, partitioned by provider", unit.Dimensionless),
+		providerDriftCounter:           newOTLPCounterFrom(meter, providerDriftTotalName, "How many times the services of a provider drifted from the watched ones, partitioned by provider"),
+		providerAPIReqsCounter:         newOTLPCounterFrom(meter, providerAPIReqsTotalName, "How many requests a provider sent to its API, partitioned by provider, method, and status code"),
+		providerAPIRetriesCounter:      newOTLPCounterFrom(meter, providerAPIRetriesTotalName, "How many times a provider retried to reach its API after a failure, partitioned by provider"),
 		tlsCertsNotAfterTimestampGauge: newOTLPGaugeFrom(meter, tlsCertsNotAfterTimestampName, "Certificate expiration timestamp", unit.Milliseconds),
 	}
+	reg.providerAPIReqDurationHistogram, _ = NewHistogramWithScale(newOTLPHistogramFrom(meter, providerAPIReqDurationName,
+		"How long the requests of a provider to its API took, partitioned by provider, method, and status code",
+		unit.Milliseconds), time.Second)
 
 	if config.AddEntryPointsLabels {
 		reg.entryPointReqsCounter = NewCounterWithNoopHeaders(newOTLPCounterFrom(meter, entryPointReqsTotalName,
@@ -82,6 +91,16 @@ func RegisterOpenTelemetry(ctx context.Context, config *types.OpenTelemetry) Reg
 			unit.Milliseconds), time.Second)
 	}
 
+	if config.AddMiddlewaresLabels {
+		reg.middlewareReqDurationHistogram, _ = NewHistogramWithScale(newOTLPHistogramFrom(meter, middlewareReqDurationName,
+			"How long the middleware of a router spent processing the request, excluding the time spent in the next handlers, partitioned by middleware, router, and provider.",
+			unit.Milliseconds), time.Second)
+		reg.middlewareReqsSpooledCounter = newOTLPCounterFrom(meter, middlewareReqsSpooledTotalName,
+			"How many request bodies are spooled to disk by a buffering middleware, partitioned by middleware.")
+		reg.middlewareBotReqsCounter = newOTLPCounterFrom(meter, middlewareBotReqsTotalName,
+			"How many requests are blocked, tarpitted, or challenged by a bot detection middleware, and how many challenges are solved, partitioned by middleware and result.")
+	}
+
 	if config.AddServicesLabels {
 		reg.serviceReqsCounter = NewCounterWithNoopHeaders(newOTLPCounterFrom(meter, serviceReqsTotalName,
 			"How many HTTP requests processed on a service, partitioned by status code, protocol, and method."))
@@ -26,9 +26,20 @@ const (
 	configLastReloadSuccessName = metricConfigPrefix + "last_reload_success"
 	openConnectionsName         = MetricNamePrefix + "open_connections"
 
+	// provider level.
+	metricProviderPrefix     = MetricNamePrefix + "provider_"
+	providerDiscoveryUpName  = metricProviderPrefix + "discovery_up"
+	providerConfigErrorsName = metricProviderPrefix + "config_errors"
+	providerDriftTotalName   = metricProviderPrefix + "drift_total"
+
+	providerAPIReqsTotalName    = metricProviderPrefix + "api_requests_total"
+	providerAPIReqDurationName  = metricProviderPrefix + "api_request_duration_seconds"
+	providerAPIRetriesTotalName = metricProviderPrefix + "api_retries_total"
+
 	// TLS.
 	metricsTLSPrefix              = MetricNamePrefix + "tls_"
 	tlsCertsNotAfterTimestampName = metricsTLSPrefix + "certs_not_after"
+	tlsCertsMissingSCTName        = metricsTLSPrefix + "certs_missing_sct"
 
 	// entry point.
 	metricEntryPointPrefix        = MetricNamePrefix + "entrypoint_"
@@ -46,6 +57,12 @@ const (
 	routerReqsBytesTotalName  = metricRouterPrefix + "requests_bytes_total"
 	routerRespsBytesTotalName = metricRouterPrefix + "responses_bytes_total"
 
+	// middleware level.
+	metricMiddlewarePrefix         = MetricNamePrefix + "middleware_"
+	middlewareReqDurationName      = metricMiddlewarePrefix + "request_duration_seconds"
+	middlewareReqsSpooledTotalName = metricMiddlewarePrefix + "requests_spooled_total"
+	middlewareBotReqsTotalName     = metricMiddlewarePrefix + "bot_requests_total"
+
 	// service level.
 	metricServicePrefix        = MetricNamePrefix + "service_"
 	serviceReqsTotalName       = metricServicePrefix + "requests_total"
@@ -122,27 +139,71 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		Name: tlsCertsNotAfterTimestampName,
 		Help: "Certificate expiration timestamp",
 	}, []string{"cn", "serial", "sans"})
+	tlsCertsMissingSCT := newGaugeFrom(stdprometheus.GaugeOpts{
+		Name: tlsCertsMissingSCTName,
+		Help: "Whether the certificate is missing valid embedded Signed Certificate Timestamps",
+	}, []string{"cn", "serial", "sans"})
 	openConnections := newGaugeFrom(stdprometheus.GaugeOpts{
 		Name: openConnectionsName,
 		Help: "How many open connections exist, by entryPoint and protocol",
 	}, []string{"entrypoint", "protocol"})
+	providerDiscoveryUp := newGaugeFrom(stdprometheus.GaugeOpts{
+		Name: providerDiscoveryUpName,
+		Help: "Whether the last refresh of the services of a provider succeeded, partitioned by provider",
+	}, []string{"provider"})
+	providerConfigErrors := newGaugeFrom(stdprometheus.GaugeOpts{
+		Name: providerConfigErrorsName,
+		Help: "How many services of a provider have a configuration which cannot be applied, on the last refresh, partitioned by provider",
+	}, []string{"provider"})
+	providerDrift := newCounterFrom(stdprometheus.CounterOpts{
+		Name: providerDriftTotalName,
+		Help: "How many times the services of a provider drifted from the watched ones, partitioned by provider",
+	}, []string{"provider"})
+	providerAPIReqs := newCounterFrom(stdprometheus.CounterOpts{
+		Name: providerAPIReqsTotalName,
+		Help: "How many requests a provider sent to its API, partitioned by provider, method, and status code",
+	}, []string{"provider", "method", "code"})
+	providerAPIReqDurations := newHistogramFrom(stdprometheus.HistogramOpts{
+		Name:    providerAPIReqDurationName,
+		Help:    "How long the requests of a provider to its API took, partitioned by provider, method, and status code",
+		Buckets: buckets,
+	}, []string{"provider", "method", "code"})
+	providerAPIRetries := newCounterFrom(stdprometheus.CounterOpts{
+		Name: providerAPIRetriesTotalName,
+		Help: "How many times a provider retried to reach its API after a failure, partitioned by provider",
+	}, []string{"provider"})
 
 	promState.vectors = []vector{
 		configReloads.cv,
 		lastConfigReloadSuccess.gv,
 		tlsCertsNotAfterTimestamp.gv,
+		tlsCertsMissingSCT.gv,
 		openConnections.gv,
+		providerDiscoveryUp.gv,
+		providerConfigErrors.gv,
+		providerDrift.cv,
+		providerAPIReqs.cv,
+		providerAPIReqDurations.hv,
+		providerAPIRetries.cv,
 	}
 
 	reg := &standardRegistry{
 		epEnabled:                      config.AddEntryPointsLabels,
 		routerEnabled:                  config.AddRoutersLabels,
+		middlewareEnabled:              config.AddMiddlewaresLabels,
 		svcEnabled:                     config.AddServicesLabels,
 		configReloadsCounter:           configReloads,
 		lastConfigReloadSuccessGauge:   lastConfigReloadSuccess,
 		tlsCertsNotAfterTimestampGauge: tlsCertsNotAfterTimestamp,
+		tlsCertsMissingSCTGauge:        tlsCertsMissingSCT,
 		openConnectionsGauge:           openConnections,
+		providerDiscoveryUpGauge:       providerDiscoveryUp,
+		providerConfigErrorsGauge:      providerConfigErrors,
+		providerDriftCounter:           providerDrift,
+		providerAPIReqsCounter:         providerAPIReqs,
+		providerAPIRetriesCounter:      providerAPIRetries,
 	}
+	reg.providerAPIReqDurationHistogram, _ = NewHistogramWithScale(providerAPIReqDurations, time.Second)
 
 	if config.AddEntryPointsLabels {
 		entryPointReqs := newCounterWithHeadersFrom(stdprometheus.CounterOpts{
@@ -219,6 +280,32 @@ func initStandardRegistry(config *types.Prometheus) Registry {
 		reg.routerRespsBytesCounter = routerRespsBytesTotal
 	}
 
+	if config.AddMiddlewaresLabels {
+		middlewareReqDurations := newHistogramFrom(stdprometheus.HistogramOpts{
+			Name:    middlewareReqDurationName,
+			Help:    "How long the middleware of a router spent processing the request, excluding the time spent in the next handlers, partitioned by middleware, router, and provider.",
+			Buckets: buckets,
+		}, []string{"middleware", "router", "provider"})
+		middlewareReqsSpooled := newCounterFrom(stdprometheus.CounterOpts{
+			Name: middlewareReqsSpooledTotalName,
+			Help: "How many request bodies are spooled to disk by a buffering middleware, partitioned by middleware.",
+		}, []string{"middleware"})
+		middlewareBotReqs := newCounterFrom(stdprometheus.CounterOpts{
+			Name: middlewareBotReqsTotalName,
+			Help: "How many requests are blocked, tarpitted, or challenged by a bot detection middleware, and how many challenges are solved, partitioned by middleware and result.",
+		}, []string{"middleware", "result"})
+
+		promState.vectors = append(promState.vectors,
+			middlewareReqDurations.hv,
+			middlewareReqsSpooled.cv,
+			middlewareBotReqs.cv,
+		)
+
+		reg.middlewareReqDurationHistogram, _ = NewHistogramWithScale(middlewareReqDurations, time.Second)
+		reg.middlewareReqsSpooledCounter = middlewareReqsSpooled
+		reg.middlewareBotReqsCounter = middlewareBotReqs
+	}
+
 	if config.AddServicesLabels {
 		serviceReqs := newCounterWithHeadersFrom(stdprometheus.CounterOpts{
 			Name: serviceReqsTotalName,
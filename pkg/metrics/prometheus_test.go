@@ -95,13 +95,14 @@ func TestPrometheus(t *testing.T) {
 	prometheusRegistry := RegisterPrometheus(context.Background(), &types.Prometheus{
 		AddEntryPointsLabels: true,
 		AddRoutersLabels:     true,
+		AddMiddlewaresLabels: true,
 		AddServicesLabels:    true,
 		HeaderLabels:         map[string]string{"useragent": "User-Agent"},
 	})
 	defer promRegistry.Unregister(promState)
 
-	if !prometheusRegistry.IsEpEnabled() || !prometheusRegistry.IsRouterEnabled() || !prometheusRegistry.IsSvcEnabled() {
-		t.Errorf("PrometheusRegistry should return true for IsEnabled(), IsRouterEnabled() and IsSvcEnabled()")
+	if !prometheusRegistry.IsEpEnabled() || !prometheusRegistry.IsRouterEnabled() || !prometheusRegistry.IsMiddlewareEnabled() || !prometheusRegistry.IsSvcEnabled() {
+		t.Errorf("PrometheusRegistry should return true for IsEnabled(), IsRouterEnabled(), IsMiddlewareEnabled() and IsSvcEnabled()")
 	}
 
 	prometheusRegistry.ConfigReloadsCounter().Add(1)
@@ -111,11 +112,43 @@ func TestPrometheus(t *testing.T) {
 		With("entrypoint", "test", "protocol", "TCP").
 		Set(1)
 
+	prometheusRegistry.
+		ProviderDiscoveryUpGauge().
+		With("provider", "nomad").
+		Set(1)
+
+	prometheusRegistry.
+		ProviderConfigErrorsGauge().
+		With("provider", "nomad").
+		Set(2)
+
+	prometheusRegistry.
+		ProviderDriftCounter().
+		With("provider", "nomad").
+		Add(1)
+	prometheusRegistry.
+		ProviderAPIReqsCounter().
+		With("provider", "nomad", "method", http.MethodGet, "code", strconv.Itoa(http.StatusOK)).
+		Add(1)
+	prometheusRegistry.
+		ProviderAPIReqDurationHistogram().
+		With("provider", "nomad", "method", http.MethodGet, "code", strconv.Itoa(http.StatusOK)).
+		Observe(1)
+	prometheusRegistry.
+		ProviderAPIRetriesCounter().
+		With("provider", "nomad").
+		Add(1)
+
 	prometheusRegistry.
 		TLSCertsNotAfterTimestampGauge().
 		With("cn", "value", "serial", "value", "sans", "value").
 		Set(float64(time.Now().Unix()))
 
+	prometheusRegistry.
+		TLSCertsMissingSCTGauge().
+		With("cn", "value", "serial", "value", "sans", "value").
+		Set(1)
+
 	prometheusRegistry.
 		EntryPointReqsCounter().
 		With(map[string][]string{"User-Agent": {"foobar"}}, "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http", "entrypoint", "http").
@@ -154,6 +187,21 @@ func TestPrometheus(t *testing.T) {
 		With("router", "demo", "service", "service1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http").
 		Add(1)
 
+	prometheusRegistry.
+		MiddlewareReqDurationHistogram().
+		With("middleware", "auth@file", "router", "demo", "provider", "nomad").
+		Observe(10000)
+
+	prometheusRegistry.
+		MiddlewareReqsSpooledCounter().
+		With("middleware", "buffering@file").
+		Add(1)
+
+	prometheusRegistry.
+		MiddlewareBotReqsCounter().
+		With("middleware", "bots@file", "result", "challenged").
+		Add(1)
+
 	prometheusRegistry.
 		ServiceReqsCounter().
 		With(map[string][]string{"User-Agent": {"foobar"}}, "service", "service1", "code", strconv.Itoa(http.StatusOK), "method", http.MethodGet, "protocol", "http").
@@ -208,6 +256,52 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildGaugeAssert(t, openConnectionsName, 1),
 		},
+		{
+			name: providerDiscoveryUpName,
+			labels: map[string]string{
+				"provider": "nomad",
+			},
+			assert: buildGaugeAssert(t, providerDiscoveryUpName, 1),
+		},
+		{
+			name: providerConfigErrorsName,
+			labels: map[string]string{
+				"provider": "nomad",
+			},
+			assert: buildGaugeAssert(t, providerConfigErrorsName, 2),
+		},
+		{
+			name: providerDriftTotalName,
+			labels: map[string]string{
+				"provider": "nomad",
+			},
+			assert: buildCounterAssert(t, providerDriftTotalName, 1),
+		},
+		{
+			name: providerAPIReqsTotalName,
+			labels: map[string]string{
+				"provider": "nomad",
+				"method":   http.MethodGet,
+				"code":     "200",
+			},
+			assert: buildCounterAssert(t, providerAPIReqsTotalName, 1),
+		},
+		{
+			name: providerAPIReqDurationName,
+			labels: map[string]string{
+				"provider": "nomad",
+				"method":   http.MethodGet,
+				"code":     "200",
+			},
+			assert: buildHistogramAssert(t, providerAPIReqDurationName, 1),
+		},
+		{
+			name: providerAPIRetriesTotalName,
+			labels: map[string]string{
+				"provider": "nomad",
+			},
+			assert: buildCounterAssert(t, providerAPIRetriesTotalName, 1),
+		},
 		{
 			name: tlsCertsNotAfterTimestampName,
 			labels: map[string]string{
@@ -217,6 +311,15 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildTimestampAssert(t, tlsCertsNotAfterTimestampName),
 		},
+		{
+			name: tlsCertsMissingSCTName,
+			labels: map[string]string{
+				"cn":     "value",
+				"serial": "value",
+				"sans":   "value",
+			},
+			assert: buildGaugeAssert(t, tlsCertsMissingSCTName, 1),
+		},
 		{
 			name: entryPointReqsTotalName,
 			labels: map[string]string{
@@ -313,6 +416,30 @@ func TestPrometheus(t *testing.T) {
 			},
 			assert: buildCounterAssert(t, routerRespsBytesTotalName, 1),
 		},
+		{
+			name: middlewareReqDurationName,
+			labels: map[string]string{
+				"middleware": "auth@file",
+				"router":     "demo",
+				"provider":   "nomad",
+			},
+			assert: buildHistogramAssert(t, middlewareReqDurationName, 1),
+		},
+		{
+			name: middlewareReqsSpooledTotalName,
+			labels: map[string]string{
+				"middleware": "buffering@file",
+			},
+			assert: buildCounterAssert(t, middlewareReqsSpooledTotalName, 1),
+		},
+		{
+			name: middlewareBotReqsTotalName,
+			labels: map[string]string{
+				"middleware": "bots@file",
+				"result":     "challenged",
+			},
+			assert: buildCounterAssert(t, middlewareBotReqsTotalName, 1),
+		},
 		{
 			name: serviceReqsTotalName,
 			labels: map[string]string{
@@ -21,6 +21,13 @@ const (
 	statsdLastConfigReloadSuccessName = "config.reload.lastSuccessTimestamp"
 	statsdOpenConnectionsName         = "open.connections"
 
+	statsdProviderDiscoveryUpName    = "provider.discovery.up"
+	statsdProviderConfigErrorsName   = "provider.config.errors"
+	statsdProviderDriftName          = "provider.drift.total"
+	statsdProviderAPIReqsName        = "provider.api.request.total"
+	statsdProviderAPIReqDurationName = "provider.api.request.duration"
+	statsdProviderAPIRetriesName     = "provider.api.retries.total"
+
 	statsdTLSCertsNotAfterTimestampName = "tls.certs.notAfterTimestamp"
 
 	statsdEntryPointReqsName        = "entrypoint.request.total"
@@ -35,6 +42,10 @@ const (
 	statsdRouterReqsBytesName    = "router.requests.bytes.total"
 	statsdRouterRespsBytesName   = "router.responses.bytes.total"
 
+	statsdMiddlewareReqsDurationName = "middleware.request.duration"
+	statsdMiddlewareReqsSpooledName  = "middleware.requests.spooled.total"
+	statsdMiddlewareBotReqsName      = "middleware.bot.requests.total"
+
 	statsdServiceReqsName         = "service.request.total"
 	statsdServiceReqsTLSName      = "service.request.tls.total"
 	statsdServiceReqsDurationName = "service.request.duration"
@@ -62,8 +73,15 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 		lastConfigReloadSuccessGauge:   statsdClient.NewGauge(statsdLastConfigReloadSuccessName),
 		tlsCertsNotAfterTimestampGauge: statsdClient.NewGauge(statsdTLSCertsNotAfterTimestampName),
 		openConnectionsGauge:           statsdClient.NewGauge(statsdOpenConnectionsName),
+		providerDiscoveryUpGauge:       statsdClient.NewGauge(statsdProviderDiscoveryUpName),
+		providerConfigErrorsGauge:      statsdClient.NewGauge(statsdProviderConfigErrorsName),
+		providerDriftCounter:           statsdClient.NewCounter(statsdProviderDriftName, 1.0),
+		providerAPIReqsCounter:         statsdClient.NewCounter(statsdProviderAPIReqsName, 1.0),
+		providerAPIRetriesCounter:      statsdClient.NewCounter(statsdProviderAPIRetriesName, 1.0),
 	}
 
+	registry.providerAPIReqDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdProviderAPIReqDurationName, 1.0), time.Millisecond)
+
 	if config.AddEntryPointsLabels {
 		registry.epEnabled = config.AddEntryPointsLabels
 		registry.entryPointReqsCounter = NewCounterWithNoopHeaders(statsdClient.NewCounter(statsdEntryPointReqsName, 1.0))
@@ -82,6 +100,13 @@ func RegisterStatsd(ctx context.Context, config *types.Statsd) Registry {
 		registry.routerRespsBytesCounter = statsdClient.NewCounter(statsdRouterRespsBytesName, 1.0)
 	}
 
+	if config.AddMiddlewaresLabels {
+		registry.middlewareEnabled = config.AddMiddlewaresLabels
+		registry.middlewareReqDurationHistogram, _ = NewHistogramWithScale(statsdClient.NewTiming(statsdMiddlewareReqsDurationName, 1.0), time.Millisecond)
+		registry.middlewareReqsSpooledCounter = statsdClient.NewCounter(statsdMiddlewareReqsSpooledName, 1.0)
+		registry.middlewareBotReqsCounter = statsdClient.NewCounter(statsdMiddlewareBotReqsName, 1.0)
+	}
+
 	if config.AddServicesLabels {
 		registry.svcEnabled = config.AddServicesLabels
 		registry.serviceReqsCounter = NewCounterWithNoopHeaders(statsdClient.NewCounter(statsdServiceReqsName, 1.0))
@@ -77,6 +77,14 @@ const (
 	TLSCipher = "TLSCipher"
 	// TLSClientSubject is the string representation of the TLS client certificate's Subject.
 	TLSClientSubject = "TLSClientSubject"
+
+	// RequestID is the map key used for the ID of the request, set by the request ID middleware.
+	RequestID = "RequestID"
+
+	// WAFMatchedRules is the map key used for the ID and the message of the rules of the WAF middleware matched by the request.
+	WAFMatchedRules = "WAFMatchedRules"
+	// WAFInterruptingRule is the map key used for the ID of the rule of the WAF middleware which blocked the request.
+	WAFInterruptingRule = "WAFInterruptingRule"
 )
 
 // These are written out in the default case when no config is provided to specify keys of interest.
@@ -121,6 +129,9 @@ func init() {
 	allCoreKeys[TLSVersion] = struct{}{}
 	allCoreKeys[TLSCipher] = struct{}{}
 	allCoreKeys[TLSClientSubject] = struct{}{}
+	allCoreKeys[RequestID] = struct{}{}
+	allCoreKeys[WAFMatchedRules] = struct{}{}
+	allCoreKeys[WAFInterruptingRule] = struct{}{}
 }
 
 // CoreLogData holds the fields computed from the request/response.
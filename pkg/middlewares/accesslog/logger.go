@@ -16,6 +16,7 @@ import (
 	"time"
 
 	"github.com/containous/alice"
+	"github.com/natefinch/lumberjack"
 	"github.com/rs/zerolog/log"
 	"github.com/sirupsen/logrus"
 	ptypes "github.com/traefik/paerser/types"
@@ -60,6 +61,9 @@ type Handler struct {
 	config         *types.AccessLog
 	logger         *logrus.Logger
 	file           io.WriteCloser
+	rotator        *lumberjack.Logger
+	fifo           bool
+	stopRotation   chan struct{}
 	mu             sync.Mutex
 	httpCodeRanges types.HTTPCodeRanges
 	logHandlerChan chan handlerParams
@@ -78,13 +82,43 @@ func WrapHandler(handler *Handler) alice.Constructor {
 // NewHandler creates a new Handler.
 func NewHandler(config *types.AccessLog) (*Handler, error) {
 	var file io.WriteCloser = noopCloser{os.Stdout}
+	var rotator *lumberjack.Logger
+	var fifo bool
+
 	if len(config.FilePath) > 0 {
-		f, err := openAccessLogFile(config.FilePath)
-		if err != nil {
-			return nil, fmt.Errorf("error opening access log file: %w", err)
+		fifo = isNamedPipe(config.FilePath)
+
+		switch {
+		case fifo:
+			if config.Rotated() {
+				log.Warn().Msgf("Access log file %s is a named pipe, rotation is ignored.", config.FilePath)
+			}
+
+			f, err := openAccessLogPipe(config.FilePath)
+			if err != nil {
+				return nil, fmt.Errorf("error opening access log pipe: %w", err)
+			}
+			file = f
+
+		case config.Rotated():
+			rotator = &lumberjack.Logger{
+				Filename:   config.FilePath,
+				MaxSize:    config.MaxSize,
+				MaxAge:     config.MaxAge,
+				MaxBackups: config.MaxBackups,
+				Compress:   config.Compress,
+			}
+			file = rotator
+
+		default:
+			f, err := openAccessLogFile(config.FilePath)
+			if err != nil {
+				return nil, fmt.Errorf("error opening access log file: %w", err)
+			}
+			file = f
 		}
-		file = f
 	}
+
 	logHandlerChan := make(chan handlerParams, config.BufferingSize)
 
 	var formatter logrus.Formatter
@@ -121,6 +155,8 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		config:         config,
 		logger:         logger,
 		file:           file,
+		rotator:        rotator,
+		fifo:           fifo,
 		logHandlerChan: logHandlerChan,
 	}
 
@@ -142,9 +178,40 @@ func NewHandler(config *types.AccessLog) (*Handler, error) {
 		}()
 	}
 
+	if rotator != nil && config.RotationInterval > 0 {
+		logHandler.stopRotation = make(chan struct{})
+
+		logHandler.wg.Add(1)
+		go func() {
+			defer logHandler.wg.Done()
+			logHandler.rotateEvery(time.Duration(config.RotationInterval))
+		}()
+	}
+
 	return logHandler, nil
 }
 
+// isNamedPipe returns whether the file at the given path is a named pipe (FIFO).
+func isNamedPipe(filePath string) bool {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeNamedPipe != 0
+}
+
+// openAccessLogPipe opens the named pipe in read-write mode,
+// so that opening it does not block until a reader is attached.
+func openAccessLogPipe(filePath string) (*os.File, error) {
+	pipe, err := os.OpenFile(filePath, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("error opening named pipe %s: %w", filePath, err)
+	}
+
+	return pipe, nil
+}
+
 func openAccessLogFile(filePath string) (*os.File, error) {
 	dir := filepath.Dir(filePath)
 
@@ -255,17 +322,25 @@ func (h *Handler) ServeHTTP(rw http.ResponseWriter, req *http.Request, next http
 
 // Close closes the Logger (i.e. the file, drain logHandlerChan, etc).
 func (h *Handler) Close() error {
+	if h.stopRotation != nil {
+		close(h.stopRotation)
+	}
 	close(h.logHandlerChan)
 	h.wg.Wait()
 	return h.file.Close()
 }
 
 // Rotate closes and reopens the log file to allow for rotation by an external source.
+// When the log file is rotated by Traefik, the current file is rotated right away instead.
 func (h *Handler) Rotate() error {
-	if h.config.FilePath == "" {
+	if h.config.FilePath == "" || h.fifo {
 		return nil
 	}
 
+	if h.rotator != nil {
+		return h.rotator.Rotate()
+	}
+
 	if h.file != nil {
 		defer func(f io.Closer) { _ = f.Close() }(h.file)
 	}
@@ -281,6 +356,22 @@ func (h *Handler) Rotate() error {
 	return nil
 }
 
+func (h *Handler) rotateEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stopRotation:
+			return
+		case <-ticker.C:
+			if err := h.rotator.Rotate(); err != nil {
+				log.Error().Err(err).Msg("Error rotating access log file")
+			}
+		}
+	}
+}
+
 func silentSplitHostPort(value string) (host, port string) {
 	host, port, err := net.SplitHostPort(value)
 	if err != nil {
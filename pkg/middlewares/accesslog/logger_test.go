@@ -107,6 +107,80 @@ func TestLogRotation(t *testing.T) {
 	close(writeDone)
 }
 
+func TestLogRotationInterval(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "access.log")
+
+	config := &types.AccessLog{
+		FilePath:         fileName,
+		Format:           CommonFormat,
+		RotationInterval: ptypes.Duration(50 * time.Millisecond),
+	}
+	logHandler, err := NewHandler(config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := logHandler.Close()
+		require.NoError(t, err)
+	})
+
+	handler, err := alice.New(capture.Wrap, WrapHandler(logHandler)).
+		Then(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	assert.Eventually(t, func() bool {
+		entries, err := os.ReadDir(dir)
+		return err == nil && len(entries) > 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+
+	var count int
+	for _, entry := range entries {
+		count += lineCount(t, filepath.Join(dir, entry.Name()))
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestLogRotationMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "access.log")
+
+	config := &types.AccessLog{FilePath: fileName, Format: CommonFormat, MaxSize: 1, MaxBackups: 1}
+	logHandler, err := NewHandler(config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := logHandler.Close()
+		require.NoError(t, err)
+	})
+
+	handler, err := alice.New(capture.Wrap, WrapHandler(logHandler)).
+		Then(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	// the rotation requested by an external source rotates the file right away.
+	err = logHandler.Rotate()
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	assert.Equal(t, 1, lineCount(t, fileName))
+}
+
 func lineCount(t *testing.T, fileName string) int {
 	t.Helper()
 	fileContents, err := os.ReadFile(fileName)
@@ -0,0 +1,55 @@
+//go:build !windows
+
+package accesslog
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/containous/alice"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/middlewares/capture"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+func TestNewLogHandlerOutputNamedPipe(t *testing.T) {
+	pipeName := filepath.Join(t.TempDir(), "access.fifo")
+	require.NoError(t, syscall.Mkfifo(pipeName, 0o600))
+
+	// rotation is ignored for named pipes.
+	config := &types.AccessLog{FilePath: pipeName, Format: CommonFormat, MaxSize: 1}
+	logHandler, err := NewHandler(config)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := logHandler.Close()
+		require.NoError(t, err)
+	})
+
+	reader, err := os.Open(pipeName)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = reader.Close() })
+
+	handler, err := alice.New(capture.Wrap, WrapHandler(logHandler)).
+		Then(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusOK)
+		}))
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost/pipe", nil))
+
+	require.NoError(t, logHandler.Rotate())
+
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	require.NoError(t, err)
+	assert.Contains(t, line, `"GET /pipe HTTP/1.1"`)
+
+	info, err := os.Stat(pipeName)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&os.ModeNamedPipe)
+}
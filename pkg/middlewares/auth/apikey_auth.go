@@ -0,0 +1,325 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+	"golang.org/x/time/rate"
+)
+
+const (
+	apiKeyTypeName               = "APIKeyAuth"
+	defaultAPIKeyHeaderName      = "X-API-Key"
+	defaultAPIKeyRefreshInterval = 30 * time.Second
+)
+
+// keySource returns the API keys items, indexed by item name.
+type keySource interface {
+	Items(ctx context.Context) (map[string]string, error)
+}
+
+// apiKey is an API key definition, along with its rate limiter.
+type apiKey struct {
+	name    string
+	average int64
+	period  time.Duration
+	burst   int64
+	limiter *rate.Limiter // nil when the key is not rate limited.
+}
+
+type apiKeyAuth struct {
+	next            http.Handler
+	name            string
+	headerName      string
+	headerField     string
+	removeHeader    bool
+	source          keySource
+	refreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[[sha256.Size]byte]*apiKey // indexed by the hash of the API key.
+	lastRefresh time.Time
+	refreshing  atomic.Bool
+}
+
+// NewAPIKey creates an apiKeyAuth middleware.
+func NewAPIKey(ctx context.Context, next http.Handler, authConfig dynamic.APIKeyAuth, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, apiKeyTypeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if authConfig.Nomad == nil || authConfig.Nomad.Path == "" {
+		return nil, errors.New("the path of the Nomad Variable holding the API keys is required")
+	}
+
+	source, err := newNomadVariableSource(*authConfig.Nomad)
+	if err != nil {
+		return nil, fmt.Errorf("creating Nomad client: %w", err)
+	}
+
+	a := newAPIKeyAuth(next, authConfig, source, name)
+
+	// A failure to read the keys must not prevent the creation of the middleware,
+	// all the requests are rejected until the keys are successfully read.
+	if err := a.refresh(ctx); err != nil {
+		logger.Error().Err(err).Msg("Unable to read the API keys")
+	}
+
+	return a, nil
+}
+
+func newAPIKeyAuth(next http.Handler, authConfig dynamic.APIKeyAuth, source keySource, name string) *apiKeyAuth {
+	headerName := authConfig.HeaderName
+	if headerName == "" {
+		headerName = defaultAPIKeyHeaderName
+	}
+
+	refreshInterval := defaultAPIKeyRefreshInterval
+	if authConfig.Nomad != nil && authConfig.Nomad.RefreshInterval > 0 {
+		refreshInterval = time.Duration(authConfig.Nomad.RefreshInterval)
+	}
+
+	return &apiKeyAuth{
+		next:            next,
+		name:            name,
+		headerName:      headerName,
+		headerField:     authConfig.HeaderField,
+		removeHeader:    authConfig.RemoveHeader,
+		source:          source,
+		refreshInterval: refreshInterval,
+		keys:            make(map[[sha256.Size]byte]*apiKey),
+	}
+}
+
+func (a *apiKeyAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return a.name, tracing.SpanKindNoneEnum
+}
+
+func (a *apiKeyAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), a.name, apiKeyTypeName)
+
+	a.refreshIfStale(logger.WithContext(context.Background()))
+
+	key := a.lookup(req.Header.Get(a.headerName))
+	if key == nil {
+		logger.Debug().Msg("Authentication failed")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	logData := accesslog.GetLogData(req)
+	if logData != nil {
+		logData.Core[accesslog.ClientUsername] = key.name
+	}
+
+	if key.limiter != nil && !key.limiter.Allow() {
+		logger.Debug().Msgf("Rate limit exceeded for API key %s", key.name)
+		tracing.SetErrorWithEvent(req, "Rate limit exceeded")
+
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	logger.Debug().Msg("Authentication succeeded")
+
+	if a.headerField != "" {
+		req.Header[a.headerField] = []string{key.name}
+	}
+
+	if a.removeHeader {
+		logger.Debug().Msg("Removing API key header")
+		req.Header.Del(a.headerName)
+	}
+
+	a.next.ServeHTTP(rw, req)
+}
+
+func (a *apiKeyAuth) lookup(value string) *apiKey {
+	if value == "" {
+		return nil
+	}
+
+	// Keys are looked up by hash, so that the lookup duration does not depend on the key value.
+	hash := sha256.Sum256([]byte(value))
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.keys[hash]
+}
+
+// refreshIfStale reads the keys in the background when they are older than the refresh interval.
+func (a *apiKeyAuth) refreshIfStale(ctx context.Context) {
+	a.mu.RLock()
+	stale := time.Since(a.lastRefresh) >= a.refreshInterval
+	a.mu.RUnlock()
+
+	if !stale || !a.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer a.refreshing.Store(false)
+
+		if err := a.refresh(ctx); err != nil {
+			middlewares.GetLogger(ctx, a.name, apiKeyTypeName).Error().Err(err).Msg("Unable to refresh the API keys")
+		}
+	}()
+}
+
+// refresh reads the keys from the source.
+// The previous keys are kept when they cannot be read,
+// and the rate limiters of the unchanged keys are kept across refreshes.
+func (a *apiKeyAuth) refresh(ctx context.Context) error {
+	items, err := a.source.Items(ctx)
+	if err == nil {
+		var keys map[[sha256.Size]byte]*apiKey
+		keys, err = parseAPIKeys(items)
+		if err == nil {
+			a.mu.Lock()
+			for hash, key := range keys {
+				if old, ok := a.keys[hash]; ok && old.sameLimit(key) {
+					key.limiter = old.limiter
+				}
+			}
+			a.keys = keys
+			a.mu.Unlock()
+		}
+	}
+
+	a.mu.Lock()
+	a.lastRefresh = time.Now()
+	a.mu.Unlock()
+
+	return err
+}
+
+func (k *apiKey) sameLimit(other *apiKey) bool {
+	return k.name == other.name && k.average == other.average && k.period == other.period && k.burst == other.burst
+}
+
+// parseAPIKeys parses the Variable items into API keys, indexed by the hash of the key.
+// Each item declares a key, unless its name ends with .average, .period or .burst,
+// in which case it defines the rate limit of the key declared by the item name prefix.
+func parseAPIKeys(items map[string]string) (map[[sha256.Size]byte]*apiKey, error) {
+	keys := make(map[string]*apiKey)
+	secrets := make(map[string]string)
+
+	for name, value := range items {
+		if isRateLimitItem(name) {
+			continue
+		}
+		if value == "" {
+			return nil, fmt.Errorf("empty API key %s", name)
+		}
+
+		keys[name] = &apiKey{name: name, period: time.Second, burst: 1}
+		secrets[name] = value
+	}
+
+	for name, value := range items {
+		if !isRateLimitItem(name) {
+			continue
+		}
+
+		i := strings.LastIndex(name, ".")
+		keyName, setting := name[:i], name[i+1:]
+
+		key, ok := keys[keyName]
+		if !ok {
+			return nil, fmt.Errorf("rate limit %s defined for unknown API key %s", setting, keyName)
+		}
+
+		var err error
+		switch setting {
+		case "average":
+			key.average, err = strconv.ParseInt(value, 10, 64)
+		case "burst":
+			key.burst, err = strconv.ParseInt(value, 10, 64)
+		case "period":
+			key.period, err = time.ParseDuration(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate limit %s for API key %s: %w", setting, keyName, err)
+		}
+	}
+
+	hashed := make(map[[sha256.Size]byte]*apiKey, len(keys))
+	for name, key := range keys {
+		if key.period <= 0 {
+			return nil, fmt.Errorf("invalid rate limit period for API key %s: %v", name, key.period)
+		}
+		if key.burst < 1 {
+			key.burst = 1
+		}
+
+		if key.average > 0 {
+			limit := rate.Limit(float64(key.average*int64(time.Second)) / float64(key.period))
+			key.limiter = rate.NewLimiter(limit, int(key.burst))
+		}
+
+		hash := sha256.Sum256([]byte(secrets[name]))
+		if other, ok := hashed[hash]; ok {
+			return nil, fmt.Errorf("API keys %s and %s have the same value", other.name, name)
+		}
+		hashed[hash] = key
+	}
+
+	return hashed, nil
+}
+
+func isRateLimitItem(name string) bool {
+	return strings.HasSuffix(name, ".average") || strings.HasSuffix(name, ".period") || strings.HasSuffix(name, ".burst")
+}
+
+// nomadVariableSource reads the API keys from a Nomad Variable.
+type nomadVariableSource struct {
+	client *api.Client
+	path   string
+}
+
+func newNomadVariableSource(config dynamic.APIKeyAuthNomad) (*nomadVariableSource, error) {
+	client, err := api.NewClient(&api.Config{
+		Address:   config.Address,
+		Namespace: config.Namespace,
+		Region:    config.Region,
+		SecretID:  config.Token,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &nomadVariableSource{
+		client: client,
+		path:   strings.Trim(config.Path, "/"),
+	}, nil
+}
+
+func (s *nomadVariableSource) Items(ctx context.Context) (map[string]string, error) {
+	opts := (&api.QueryOptions{}).WithContext(ctx)
+
+	// The Nomad API client in use does not provide the Variables endpoints yet.
+	var variable struct {
+		Items map[string]string
+	}
+	if _, err := s.client.Raw().Query("/v1/var/"+s.path, &variable, opts); err != nil {
+		return nil, fmt.Errorf("reading Nomad Variable %s: %w", s.path, err)
+	}
+
+	return variable.Items, nil
+}
@@ -0,0 +1,331 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+type fakeKeySource struct {
+	mu    sync.Mutex
+	items map[string]string
+	err   error
+	calls int
+}
+
+func (f *fakeKeySource) Items(_ context.Context) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	return f.items, f.err
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             dynamic.APIKeyAuth
+		items              map[string]string
+		sourceErr          error
+		headers            map[string]string
+		expectedStatus     int
+		expectedHeaders    map[string]string
+		expectedNotHeaders []string
+	}{
+		{
+			desc:           "valid key",
+			items:          map[string]string{"partner": "secret"},
+			headers:        map[string]string{"X-API-Key": "secret"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "missing key",
+			items:          map[string]string{"partner": "secret"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "unknown key",
+			items:          map[string]string{"partner": "secret"},
+			headers:        map[string]string{"X-API-Key": "other"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "keys not readable",
+			sourceErr:      errors.New("permission denied"),
+			headers:        map[string]string{"X-API-Key": "secret"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			desc:           "custom header name",
+			config:         dynamic.APIKeyAuth{HeaderName: "X-Token"},
+			items:          map[string]string{"partner": "secret"},
+			headers:        map[string]string{"X-Token": "secret"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:            "header field",
+			config:          dynamic.APIKeyAuth{HeaderField: "X-Partner"},
+			items:           map[string]string{"partner": "secret", "other": "secret2"},
+			headers:         map[string]string{"X-API-Key": "secret2"},
+			expectedStatus:  http.StatusOK,
+			expectedHeaders: map[string]string{"X-Partner": "other", "X-API-Key": "secret2"},
+		},
+		{
+			desc:               "remove header",
+			config:             dynamic.APIKeyAuth{RemoveHeader: true},
+			items:              map[string]string{"partner": "secret"},
+			headers:            map[string]string{"X-API-Key": "secret"},
+			expectedStatus:     http.StatusOK,
+			expectedNotHeaders: []string{"X-API-Key"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var forwarded http.Header
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req.Header
+			})
+
+			source := &fakeKeySource{items: test.items, err: test.sourceErr}
+			a := newAPIKeyAuth(next, test.config, source, "apikey")
+			_ = a.refresh(context.Background())
+
+			req := httptest.NewRequest(http.MethodGet, "http://foo.bar", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+
+			rw := httptest.NewRecorder()
+			a.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+
+			for k, v := range test.expectedHeaders {
+				assert.Equal(t, v, forwarded.Get(k))
+			}
+			for _, k := range test.expectedNotHeaders {
+				assert.Empty(t, forwarded.Get(k))
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuth_rateLimit(t *testing.T) {
+	source := &fakeKeySource{items: map[string]string{
+		"partner":         "secret",
+		"partner.average": "1",
+		"partner.period":  "1h",
+		"partner.burst":   "2",
+		"other":           "secret2",
+	}}
+
+	a := newAPIKeyAuth(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), dynamic.APIKeyAuth{}, source, "apikey")
+	require.NoError(t, a.refresh(context.Background()))
+
+	serve := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://foo.bar", nil)
+		req.Header.Set("X-API-Key", key)
+
+		rw := httptest.NewRecorder()
+		a.ServeHTTP(rw, req)
+
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("secret"))
+	assert.Equal(t, http.StatusOK, serve("secret"))
+	assert.Equal(t, http.StatusTooManyRequests, serve("secret"))
+
+	// other keys are not limited.
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, http.StatusOK, serve("secret2"))
+	}
+
+	// the limiter of an unchanged key survives a refresh.
+	require.NoError(t, a.refresh(context.Background()))
+	assert.Equal(t, http.StatusTooManyRequests, serve("secret"))
+
+	// the limiter of a key whose limit changed is reset.
+	source.mu.Lock()
+	source.items = map[string]string{
+		"partner":         "secret",
+		"partner.average": "1",
+		"partner.period":  "1h",
+		"partner.burst":   "3",
+	}
+	source.mu.Unlock()
+
+	require.NoError(t, a.refresh(context.Background()))
+	assert.Equal(t, http.StatusOK, serve("secret"))
+	assert.Equal(t, http.StatusUnauthorized, serve("secret2"))
+}
+
+func TestAPIKeyAuth_refresh(t *testing.T) {
+	source := &fakeKeySource{items: map[string]string{"partner": "secret"}}
+
+	config := dynamic.APIKeyAuth{
+		Nomad: &dynamic.APIKeyAuthNomad{RefreshInterval: ptypes.Duration(time.Millisecond)},
+	}
+	a := newAPIKeyAuth(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), config, source, "apikey")
+	require.NoError(t, a.refresh(context.Background()))
+
+	serve := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "http://foo.bar", nil)
+		req.Header.Set("X-API-Key", key)
+
+		rw := httptest.NewRecorder()
+		a.ServeHTTP(rw, req)
+
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("secret"))
+
+	// keys are rotated.
+	source.mu.Lock()
+	source.items = map[string]string{"partner": "rotated"}
+	source.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		return serve("rotated") == http.StatusOK
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, http.StatusUnauthorized, serve("secret"))
+
+	// the previous keys are kept when the source is unavailable.
+	source.mu.Lock()
+	source.err = errors.New("connection refused")
+	calls := source.calls
+	source.mu.Unlock()
+
+	assert.Eventually(t, func() bool {
+		serve("rotated")
+
+		source.mu.Lock()
+		defer source.mu.Unlock()
+		return source.calls > calls
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, http.StatusOK, serve("rotated"))
+}
+
+func Test_parseAPIKeys(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		items       map[string]string
+		expectedErr string
+		expected    map[string]apiKey
+	}{
+		{
+			desc:     "no items",
+			items:    map[string]string{},
+			expected: map[string]apiKey{},
+		},
+		{
+			desc: "keys with and without rate limit",
+			items: map[string]string{
+				"partner":         "secret",
+				"partner.average": "100",
+				"partner.period":  "1m",
+				"partner.burst":   "10",
+				"other":           "secret2",
+			},
+			expected: map[string]apiKey{
+				"secret":  {name: "partner", average: 100, period: time.Minute, burst: 10},
+				"secret2": {name: "other", period: time.Second, burst: 1},
+			},
+		},
+		{
+			desc:        "empty key",
+			items:       map[string]string{"partner": ""},
+			expectedErr: "empty API key partner",
+		},
+		{
+			desc:        "rate limit of unknown key",
+			items:       map[string]string{"partner.average": "100"},
+			expectedErr: "rate limit average defined for unknown API key partner",
+		},
+		{
+			desc:        "invalid average",
+			items:       map[string]string{"partner": "secret", "partner.average": "a lot"},
+			expectedErr: `invalid rate limit average for API key partner: strconv.ParseInt: parsing "a lot": invalid syntax`,
+		},
+		{
+			desc:        "invalid period",
+			items:       map[string]string{"partner": "secret", "partner.period": "0s"},
+			expectedErr: "invalid rate limit period for API key partner: 0s",
+		},
+		{
+			desc:        "duplicated key",
+			items:       map[string]string{"partner": "secret", "other": "secret"},
+			expectedErr: "have the same value",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			keys, err := parseAPIKeys(test.items)
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, keys, len(test.expected))
+			for secret, expected := range test.expected {
+				key, ok := keys[sha256.Sum256([]byte(secret))]
+				require.True(t, ok)
+
+				assert.Equal(t, expected.name, key.name)
+				assert.Equal(t, expected.average, key.average)
+				assert.Equal(t, expected.period, key.period)
+				assert.Equal(t, expected.burst, key.burst)
+				assert.Equal(t, expected.average > 0, key.limiter != nil)
+			}
+		})
+	}
+}
+
+func TestNomadVariableSource(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/var/traefik/apikeys" || req.URL.Query().Get("namespace") != "partners" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = fmt.Fprint(rw, `{"Namespace": "partners", "Path": "traefik/apikeys", "Items": {"partner": "secret"}}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	source, err := newNomadVariableSource(dynamic.APIKeyAuthNomad{
+		Address:   ts.URL,
+		Namespace: "partners",
+		Path:      "/traefik/apikeys",
+	})
+	require.NoError(t, err)
+
+	items, err := source.Items(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"partner": "secret"}, items)
+
+	source.path = "traefik/missing"
+	_, err = source.Items(context.Background())
+	require.Error(t, err)
+}
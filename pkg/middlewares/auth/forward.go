@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -21,9 +22,15 @@ import (
 )
 
 const (
-	xForwardedURI     = "X-Forwarded-Uri"
-	xForwardedMethod  = "X-Forwarded-Method"
-	forwardedTypeName = "ForwardedAuthType"
+	xForwardedURI           = "X-Forwarded-Uri"
+	xForwardedMethod        = "X-Forwarded-Method"
+	xForwardedBodyTruncated = "X-Forwarded-Body-Truncated"
+	forwardedTypeName       = "ForwardedAuthType"
+)
+
+const (
+	failureModeClosed = "closed"
+	failureModeOpen   = "open"
 )
 
 // hopHeaders Hop-by-hop headers to be removed in the authentication request.
@@ -47,6 +54,10 @@ type forwardAuth struct {
 	client                   http.Client
 	trustForwardHeader       bool
 	authRequestHeaders       []string
+	forwardBody              bool
+	maxBodySize              int64
+	failOpen                 bool
+	cache                    *forwardAuthCache
 }
 
 // NewForward creates a forward auth middleware.
@@ -60,6 +71,25 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 		name:                name,
 		trustForwardHeader:  config.TrustForwardHeader,
 		authRequestHeaders:  config.AuthRequestHeaders,
+		forwardBody:         config.ForwardBody,
+		maxBodySize:         config.MaxBodySize,
+	}
+
+	if fa.maxBodySize <= 0 {
+		fa.maxBodySize = dynamic.DefaultForwardAuthMaxBodySize
+	}
+
+	switch config.FailureMode {
+	case "", failureModeClosed:
+	case failureModeOpen:
+		fa.failOpen = true
+	default:
+		return nil, fmt.Errorf("unknown failure mode %q, must be %s or %s", config.FailureMode, failureModeClosed, failureModeOpen)
+	}
+
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		timeout = dynamic.DefaultForwardAuthTimeout
 	}
 
 	// Ensure our request client does not follow redirects
@@ -67,7 +97,15 @@ func NewForward(ctx context.Context, next http.Handler, config dynamic.ForwardAu
 		CheckRedirect: func(r *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
-		Timeout: 30 * time.Second,
+		Timeout: timeout,
+	}
+
+	if config.Cache != nil {
+		cache, err := newForwardAuthCache(config.Cache)
+		if err != nil {
+			return nil, err
+		}
+		fa.cache = cache
 	}
 
 	if config.TLS != nil {
@@ -99,6 +137,16 @@ func (fa *forwardAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
 func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	logger := middlewares.GetLogger(req.Context(), fa.name, forwardedTypeName)
 
+	var cacheKey string
+	if fa.cache != nil {
+		cacheKey = fa.cache.key(req)
+		if header, ok := fa.cache.get(cacheKey); ok {
+			logger.Debug().Msg("Request accepted by a cached decision")
+			fa.serveAuthenticated(rw, req, header)
+			return
+		}
+	}
+
 	forwardReq, err := http.NewRequest(http.MethodGet, fa.address, nil)
 	tracing.LogRequest(tracing.GetSpan(req), forwardReq)
 	if err != nil {
@@ -116,12 +164,29 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	writeHeader(req, forwardReq, fa.trustForwardHeader, fa.authRequestHeaders)
 
+	if fa.forwardBody {
+		if err := fa.writeBody(req, forwardReq); err != nil {
+			logMessage := fmt.Sprintf("Error reading request body. Cause: %s", err)
+			logger.Debug().Msg(logMessage)
+			tracing.SetErrorWithEvent(req, logMessage)
+
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+	}
+
 	forwardResponse, forwardErr := fa.client.Do(forwardReq)
 	if forwardErr != nil {
 		logMessage := fmt.Sprintf("Error calling %s. Cause: %s", fa.address, forwardErr)
 		logger.Debug().Msg(logMessage)
 		tracing.SetErrorWithEvent(req, logMessage)
 
+		if fa.failOpen {
+			logger.Warn().Msgf("Authentication server %s unavailable, forwarding the request without authentication", fa.address)
+			fa.serveAuthenticated(rw, req, http.Header{})
+			return
+		}
+
 		rw.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -137,6 +202,12 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	}
 	defer forwardResponse.Body.Close()
 
+	if fa.failOpen && forwardResponse.StatusCode >= http.StatusInternalServerError {
+		logger.Warn().Msgf("Authentication server %s failed with status code %d, forwarding the request without authentication", fa.address, forwardResponse.StatusCode)
+		fa.serveAuthenticated(rw, req, http.Header{})
+		return
+	}
+
 	// Pass the forward response's body and selected headers if it
 	// didn't return a response within the range of [200, 300).
 	if forwardResponse.StatusCode < http.StatusOK || forwardResponse.StatusCode >= http.StatusMultipleChoices {
@@ -171,11 +242,21 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if fa.cache != nil {
+		fa.cache.set(cacheKey, forwardResponse.Header.Clone())
+	}
+
+	fa.serveAuthenticated(rw, req, forwardResponse.Header)
+}
+
+// serveAuthenticated forwards the request to the service, with the selected headers of the authentication response.
+// The selected headers sent by the client are removed, even when the request is forwarded without authentication.
+func (fa *forwardAuth) serveAuthenticated(rw http.ResponseWriter, req *http.Request, authHeader http.Header) {
 	for _, headerName := range fa.authResponseHeaders {
 		headerKey := http.CanonicalHeaderKey(headerName)
 		req.Header.Del(headerKey)
-		if len(forwardResponse.Header[headerKey]) > 0 {
-			req.Header[headerKey] = append([]string(nil), forwardResponse.Header[headerKey]...)
+		if len(authHeader[headerKey]) > 0 {
+			req.Header[headerKey] = append([]string(nil), authHeader[headerKey]...)
 		}
 	}
 
@@ -186,7 +267,7 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			}
 		}
 
-		for headerKey, headerValues := range forwardResponse.Header {
+		for headerKey, headerValues := range authHeader {
 			if fa.authResponseHeadersRegex.MatchString(headerKey) {
 				req.Header[headerKey] = append([]string(nil), headerValues...)
 			}
@@ -197,6 +278,36 @@ func (fa *forwardAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	fa.next.ServeHTTP(rw, req)
 }
 
+// writeBody sends at most maxBodySize bytes of the request body to the authentication server,
+// the whole body being still forwarded to the service.
+func (fa *forwardAuth) writeBody(req, forwardReq *http.Request) error {
+	forwardReq.Header.Del(xForwardedBodyTruncated)
+
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(req.Body, fa.maxBodySize+1))
+	if err != nil {
+		return err
+	}
+
+	req.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+
+	if int64(len(body)) > fa.maxBodySize {
+		body = body[:fa.maxBodySize]
+		forwardReq.Header.Set(xForwardedBodyTruncated, "true")
+	}
+
+	forwardReq.Body = io.NopCloser(bytes.NewReader(body))
+	forwardReq.ContentLength = int64(len(body))
+
+	return nil
+}
+
 func writeHeader(req, forwardReq *http.Request, trustForwardHeader bool, allowedHeaders []string) {
 	utils.CopyHeaders(forwardReq.Header, req.Header)
 	utils.RemoveHeaders(forwardReq.Header, hopHeaders...)
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// defaultCacheKey is the request attributes identifying the requests sharing a decision, when none is configured.
+var defaultCacheKey = []string{"host", "method", "path", "header:Authorization", "header:Cookie"}
+
+// keyAttribute is a request attribute of a cache key, the name being the one of the header or cookie.
+type keyAttribute struct {
+	kind string
+	name string
+}
+
+type cacheEntry struct {
+	header  http.Header
+	expires time.Time
+}
+
+// forwardAuthCache keeps the decisions of the authentication server accepting requests, until they expire.
+// The keys are hashed, not to keep the credentials of the requests in memory.
+type forwardAuthCache struct {
+	ttl        time.Duration
+	attributes []keyAttribute
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+func newForwardAuthCache(config *dynamic.ForwardAuthCache) (*forwardAuthCache, error) {
+	if config.TTL <= 0 {
+		return nil, fmt.Errorf("invalid cache TTL %s, must be positive", time.Duration(config.TTL))
+	}
+
+	key := config.Key
+	if len(key) == 0 {
+		key = defaultCacheKey
+	}
+
+	c := &forwardAuthCache{
+		ttl:        time.Duration(config.TTL),
+		maxEntries: config.MaxEntries,
+		now:        time.Now,
+		entries:    make(map[string]cacheEntry),
+	}
+
+	if c.maxEntries <= 0 {
+		c.maxEntries = dynamic.DefaultForwardAuthCacheMaxEntries
+	}
+
+	for _, attribute := range key {
+		kind, name, _ := strings.Cut(strings.TrimSpace(attribute), ":")
+		kind = strings.ToLower(kind)
+
+		switch kind {
+		case "host", "method", "path", "query", "clientip":
+			if name != "" {
+				return nil, fmt.Errorf("invalid cache key attribute %q", attribute)
+			}
+		case "header":
+			if name == "" {
+				return nil, fmt.Errorf("invalid cache key attribute %q, missing header name", attribute)
+			}
+			name = http.CanonicalHeaderKey(name)
+		case "cookie":
+			if name == "" {
+				return nil, fmt.Errorf("invalid cache key attribute %q, missing cookie name", attribute)
+			}
+		default:
+			return nil, fmt.Errorf("unknown cache key attribute %q", attribute)
+		}
+
+		c.attributes = append(c.attributes, keyAttribute{kind: kind, name: name})
+	}
+
+	return c, nil
+}
+
+// key returns the hash of the request attributes.
+func (c *forwardAuthCache) key(req *http.Request) string {
+	hash := sha256.New()
+
+	for _, attribute := range c.attributes {
+		var value string
+
+		switch attribute.kind {
+		case "host":
+			value = req.Host
+		case "method":
+			value = req.Method
+		case "path":
+			value = req.URL.Path
+		case "query":
+			value = req.URL.RawQuery
+		case "clientip":
+			value = req.RemoteAddr
+			if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+				value = host
+			}
+		case "header":
+			value = strings.Join(req.Header.Values(attribute.name), "\x00")
+		case "cookie":
+			if cookie, err := req.Cookie(attribute.name); err == nil {
+				value = cookie.Value
+			}
+		}
+
+		// the attributes are separated, so that the values of two attributes cannot be confused.
+		fmt.Fprintf(hash, "%d:%s", len(value), value)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// get returns the headers of the authentication response of the cached decision.
+func (c *forwardAuthCache) get(key string) (http.Header, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return entry.header, true
+}
+
+// set caches the decision, evicting the expired ones, or else any one, when the cache is full.
+func (c *forwardAuthCache) set(key string, header http.Header) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+
+		for k := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = cacheEntry{header: header, expires: now.Add(c.ttl)}
+}
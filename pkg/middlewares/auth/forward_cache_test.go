@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func Test_forwardAuthCache_key(t *testing.T) {
+	cache, err := newForwardAuthCache(&dynamic.ForwardAuthCache{
+		TTL: ptypes.Duration(time.Minute),
+		Key: []string{"clientip", "header:x-api-key", "cookie:session"},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(remoteAddr, apiKey, session string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/path?query", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Api-Key", apiKey)
+		req.AddCookie(&http.Cookie{Name: "session", Value: session})
+		return req
+	}
+
+	key := cache.key(newRequest("10.0.0.1:1234", "key", "s1"))
+
+	// the port of the client is not part of its IP.
+	assert.Equal(t, key, cache.key(newRequest("10.0.0.1:5678", "key", "s1")))
+
+	assert.NotEqual(t, key, cache.key(newRequest("10.0.0.2:1234", "key", "s1")))
+	assert.NotEqual(t, key, cache.key(newRequest("10.0.0.1:1234", "other", "s1")))
+	assert.NotEqual(t, key, cache.key(newRequest("10.0.0.1:1234", "key", "s2")))
+
+	// the values of two attributes cannot be confused.
+	assert.NotEqual(t, cache.key(newRequest("10.0.0.1:1234", "ab", "c")), cache.key(newRequest("10.0.0.1:1234", "a", "bc")))
+}
+
+func Test_forwardAuthCache_expiry(t *testing.T) {
+	cache, err := newForwardAuthCache(&dynamic.ForwardAuthCache{TTL: ptypes.Duration(time.Minute), MaxEntries: 2})
+	require.NoError(t, err)
+
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	cache.set("a", http.Header{"X-Auth-User": {"a"}})
+
+	header, ok := cache.get("a")
+	require.True(t, ok)
+	assert.Equal(t, "a", header.Get("X-Auth-User"))
+
+	now = now.Add(time.Minute)
+
+	_, ok = cache.get("a")
+	assert.False(t, ok)
+
+	// the cache does not grow over its maximum number of entries.
+	cache.set("a", http.Header{})
+	cache.set("b", http.Header{})
+	cache.set("c", http.Header{})
+	assert.Len(t, cache.entries, 2)
+
+	_, ok = cache.get("c")
+	assert.True(t, ok)
+}
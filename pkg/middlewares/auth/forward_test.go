@@ -6,12 +6,16 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	tracingMiddleware "github.com/traefik/traefik/v3/pkg/middlewares/tracing"
 	"github.com/traefik/traefik/v3/pkg/testhelpers"
@@ -245,6 +249,262 @@ func TestForwardAuthFailResponseHeaders(t *testing.T) {
 	assert.Equal(t, "Forbidden\n", string(body))
 }
 
+func TestNewForward_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.ForwardAuth
+	}{
+		{
+			desc:   "unknown failure mode",
+			config: dynamic.ForwardAuth{Address: "http://auth", FailureMode: "ajar"},
+		},
+		{
+			desc:   "missing cache TTL",
+			config: dynamic.ForwardAuth{Address: "http://auth", Cache: &dynamic.ForwardAuthCache{}},
+		},
+		{
+			desc: "unknown cache key attribute",
+			config: dynamic.ForwardAuth{Address: "http://auth", Cache: &dynamic.ForwardAuthCache{
+				TTL: ptypes.Duration(time.Minute),
+				Key: []string{"body"},
+			}},
+		},
+		{
+			desc: "cache key header without name",
+			config: dynamic.ForwardAuth{Address: "http://auth", Cache: &dynamic.ForwardAuthCache{
+				TTL: ptypes.Duration(time.Minute),
+				Key: []string{"header:"},
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewForward(context.Background(), http.NotFoundHandler(), test.config, "authTest")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestForwardAuthForwardBody(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		maxBodySize       int64
+		body              string
+		expectedAuthBody  string
+		expectedTruncated string
+	}{
+		{
+			desc:             "whole body",
+			body:             "user=admin",
+			expectedAuthBody: "user=admin",
+		},
+		{
+			desc:              "truncated body",
+			maxBodySize:       4,
+			body:              "user=admin",
+			expectedAuthBody:  "user",
+			expectedTruncated: "true",
+		},
+		{
+			desc:             "body of the maximum size",
+			maxBodySize:      10,
+			body:             "user=admin",
+			expectedAuthBody: "user=admin",
+		},
+		{
+			desc: "no body",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				assert.Equal(t, test.expectedAuthBody, string(body))
+				assert.Equal(t, test.expectedTruncated, r.Header.Get(xForwardedBodyTruncated))
+			}))
+			t.Cleanup(server.Close)
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+
+				// the service still receives the whole body.
+				assert.Equal(t, test.body, string(body))
+			})
+
+			auth := dynamic.ForwardAuth{
+				Address:     server.URL,
+				ForwardBody: true,
+				MaxBodySize: test.maxBodySize,
+			}
+			middleware, err := NewForward(context.Background(), next, auth, "authTest")
+			require.NoError(t, err)
+
+			ts := httptest.NewServer(middleware)
+			t.Cleanup(ts.Close)
+
+			req := testhelpers.MustNewRequest(http.MethodPost, ts.URL, strings.NewReader(test.body))
+			// the header sent by the client is not forwarded.
+			req.Header.Set(xForwardedBodyTruncated, "false")
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestForwardAuthFailureMode(t *testing.T) {
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unavailable.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Auth-User", "user@example.com")
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+	}))
+	t.Cleanup(failing.Close)
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	}))
+	t.Cleanup(slow.Close)
+
+	testCases := []struct {
+		desc         string
+		address      string
+		failureMode  string
+		expectedCode int
+	}{
+		{
+			desc:         "unavailable server with closed failure mode",
+			address:      unavailable.URL,
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			desc:         "unavailable server with open failure mode",
+			address:      unavailable.URL,
+			failureMode:  "open",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "failing server with closed failure mode",
+			address:      failing.URL,
+			failureMode:  "closed",
+			expectedCode: http.StatusBadGateway,
+		},
+		{
+			desc:         "failing server with open failure mode",
+			address:      failing.URL,
+			failureMode:  "open",
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "timeout with closed failure mode",
+			address:      slow.URL,
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			desc:         "timeout with open failure mode",
+			address:      slow.URL,
+			failureMode:  "open",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// the headers selected from the authentication response are not trusted from the client.
+				assert.Empty(t, r.Header.Get("X-Auth-User"))
+			})
+
+			auth := dynamic.ForwardAuth{
+				Address:             test.address,
+				AuthResponseHeaders: []string{"X-Auth-User"},
+				Timeout:             ptypes.Duration(100 * time.Millisecond),
+				FailureMode:         test.failureMode,
+			}
+			middleware, err := NewForward(context.Background(), next, auth, "authTest")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			req.Header.Set("X-Auth-User", "admin@example.com")
+
+			recorder := httptest.NewRecorder()
+			middleware.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+		})
+	}
+}
+
+func TestForwardAuthCache(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		if r.Header.Get("Authorization") != "Bearer valid" {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("X-Auth-User", "user@example.com")
+	}))
+	t.Cleanup(server.Close)
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "user@example.com", r.Header.Get("X-Auth-User"))
+	})
+
+	auth := dynamic.ForwardAuth{
+		Address:             server.URL,
+		AuthResponseHeaders: []string{"X-Auth-User"},
+		Cache:               &dynamic.ForwardAuthCache{TTL: ptypes.Duration(time.Minute)},
+	}
+	middleware, err := NewForward(context.Background(), next, auth, "authTest")
+	require.NoError(t, err)
+
+	serve := func(path, authorization string) int {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil)
+		req.Header.Set("Authorization", authorization)
+		// the cached headers are set again on each request.
+		req.Header.Set("X-Auth-User", "admin@example.com")
+
+		recorder := httptest.NewRecorder()
+		middleware.ServeHTTP(recorder, req)
+
+		return recorder.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("/", "Bearer valid"))
+	assert.Equal(t, http.StatusOK, serve("/", "Bearer valid"))
+	assert.Equal(t, int32(1), calls.Load())
+
+	// the path is part of the default key.
+	assert.Equal(t, http.StatusOK, serve("/other", "Bearer valid"))
+	assert.Equal(t, int32(2), calls.Load())
+
+	// the refused requests are not cached.
+	assert.Equal(t, http.StatusForbidden, serve("/", "Bearer invalid"))
+	assert.Equal(t, http.StatusForbidden, serve("/", "Bearer invalid"))
+	assert.Equal(t, int32(4), calls.Load())
+}
+
 func Test_writeHeader(t *testing.T) {
 	testCases := []struct {
 		name                      string
@@ -0,0 +1,567 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rs/zerolog"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+	"golang.org/x/oauth2"
+)
+
+const (
+	oidcTypeName             = "OIDC"
+	defaultOIDCCallbackPath  = "/oauth2/callback"
+	defaultOIDCCookieName    = "traefik_oidc"
+	defaultOIDCSessionMaxAge = 24 * time.Hour
+	// oidcStateMaxAge is the time given to the users to authenticate with the provider.
+	oidcStateMaxAge = 10 * time.Minute
+	// oidcMaxCookieSize is the size above which the browsers may refuse a cookie.
+	oidcMaxCookieSize      = 4096
+	minSessionSecretLength = 16
+)
+
+type oidcAuth struct {
+	next          http.Handler
+	name          string
+	provider      *oidcProvider
+	client        *http.Client
+	clientID      string
+	clientSecret  string
+	scopes        []string
+	redirectURL   string
+	callbackPath  string
+	logoutPath    string
+	cookieName    string
+	sessionMaxAge time.Duration
+	claimsHeaders map[string]string
+	codec         *cookieCodec
+	now           func() time.Time
+}
+
+// NewOIDC creates an OpenID Connect auth middleware.
+func NewOIDC(ctx context.Context, next http.Handler, config dynamic.OIDC, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, oidcTypeName).Debug().Msg("Creating middleware")
+
+	if config.Issuer == "" || config.ClientID == "" {
+		return nil, errors.New("the issuer and the client ID are required")
+	}
+
+	if len(config.SessionSecret) < minSessionSecretLength {
+		return nil, fmt.Errorf("the session secret must be at least %d characters long", minSessionSecretLength)
+	}
+
+	codec, err := newCookieCodec(config.SessionSecret)
+	if err != nil {
+		return nil, fmt.Errorf("creating session cookie codec: %w", err)
+	}
+
+	client := &http.Client{
+		// The provider endpoints are usually public, and must be answered directly.
+		CheckRedirect: func(r *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Timeout: 30 * time.Second,
+	}
+
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create client TLS configuration: %w", err)
+		}
+
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = tlsConfig
+		client.Transport = tr
+	}
+
+	o := &oidcAuth{
+		next:          next,
+		name:          name,
+		provider:      newOIDCProvider(config.Issuer, config.ClientID, client),
+		client:        client,
+		clientID:      config.ClientID,
+		clientSecret:  config.ClientSecret,
+		scopes:        config.Scopes,
+		redirectURL:   config.RedirectURL,
+		callbackPath:  config.CallbackPath,
+		logoutPath:    config.LogoutPath,
+		cookieName:    config.SessionCookieName,
+		sessionMaxAge: time.Duration(config.SessionMaxAge),
+		claimsHeaders: make(map[string]string, len(config.ClaimsHeaders)),
+		codec:         codec,
+		now:           time.Now,
+	}
+
+	if len(o.scopes) == 0 {
+		o.scopes = []string{"openid", "profile", "email"}
+	}
+	if o.callbackPath == "" {
+		o.callbackPath = defaultOIDCCallbackPath
+	}
+	if o.cookieName == "" {
+		o.cookieName = defaultOIDCCookieName
+	}
+	if o.sessionMaxAge <= 0 {
+		o.sessionMaxAge = defaultOIDCSessionMaxAge
+	}
+
+	for header, claim := range config.ClaimsHeaders {
+		o.claimsHeaders[http.CanonicalHeaderKey(header)] = claim
+	}
+
+	return o, nil
+}
+
+func (o *oidcAuth) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return o.name, tracing.SpanKindNoneEnum
+}
+
+func (o *oidcAuth) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), o.name, oidcTypeName)
+
+	if req.URL.Path == o.callbackPath {
+		o.callback(logger, rw, req)
+		return
+	}
+
+	if o.logoutPath != "" && req.URL.Path == o.logoutPath {
+		o.logout(logger, rw, req)
+		return
+	}
+
+	session, err := o.getSession(req)
+	if err != nil {
+		logger.Debug().Err(err).Msg("No valid session")
+		o.authenticate(logger, rw, req)
+		return
+	}
+
+	if o.now().Unix() >= session.Expiry {
+		if session.RefreshToken == "" {
+			logger.Debug().Msg("Session expired")
+			o.authenticate(logger, rw, req)
+			return
+		}
+
+		if err := o.refresh(req.Context(), session); err != nil {
+			logger.Debug().Err(err).Msg("Unable to refresh the session")
+			o.authenticate(logger, rw, req)
+			return
+		}
+
+		if err := o.setSession(rw, req, session); err != nil {
+			logger.Error().Err(err).Msg("Unable to set the session cookie")
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	logger.Debug().Msg("Authentication succeeded")
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		if subject, ok := session.Claims["sub"].(string); ok {
+			logData.Core[accesslog.ClientUsername] = subject
+		}
+	}
+
+	o.removeSessionCookie(req)
+
+	for header, claim := range o.claimsHeaders {
+		req.Header.Del(header)
+
+		if value, ok := claimValue(session.Claims[claim]); ok {
+			req.Header.Set(header, value)
+		}
+	}
+
+	o.next.ServeHTTP(rw, req)
+}
+
+// authenticate redirects the users to the provider for them to authenticate,
+// or rejects the request when it cannot be replayed after the redirections.
+func (o *oidcAuth) authenticate(logger *zerolog.Logger, rw http.ResponseWriter, req *http.Request) {
+	// The headers of the unauthenticated requests must not reach the services, even when they are rejected.
+	for header := range o.claimsHeaders {
+		req.Header.Del(header)
+	}
+
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	metadata, err := o.provider.getMetadata(req.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to get the OpenID Connect provider metadata")
+		tracing.SetErrorWithEvent(req, "Unable to get the OpenID Connect provider metadata")
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+
+	state := &oidcState{
+		State:      randomString(),
+		Nonce:      randomString(),
+		Verifier:   randomString(),
+		RequestURI: localRedirectURI(req.URL.RequestURI()),
+	}
+
+	value, err := o.codec.encode(o.stateCookieName(), state)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to encode the state cookie")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(rw, o.cookie(req, o.stateCookieName(), value, oidcStateMaxAge))
+
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	authURL := o.oauth2Config(req, metadata).AuthCodeURL(state.State,
+		oauth2.SetAuthURLParam("nonce", state.Nonce),
+		oauth2.SetAuthURLParam("code_challenge", base64.RawURLEncoding.EncodeToString(challenge[:])),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	http.Redirect(rw, req, authURL, http.StatusFound)
+}
+
+// callback completes the authentication of the users redirected by the provider,
+// and redirects them to the request which started the authentication.
+func (o *oidcAuth) callback(logger *zerolog.Logger, rw http.ResponseWriter, req *http.Request) {
+	cookie, err := req.Cookie(o.stateCookieName())
+	if err != nil {
+		logger.Debug().Msg("Missing state cookie")
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	// The state is used once.
+	http.SetCookie(rw, o.cookie(req, o.stateCookieName(), "", -1))
+
+	state := &oidcState{}
+	if err := o.codec.decode(o.stateCookieName(), cookie.Value, state); err != nil {
+		logger.Debug().Err(err).Msg("Invalid state cookie")
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	query := req.URL.Query()
+	if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state.State)) != 1 {
+		logger.Debug().Msg("Invalid state")
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if errCode := query.Get("error"); errCode != "" {
+		logger.Debug().Msgf("Authentication refused by the provider: %s %s", errCode, query.Get("error_description"))
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	metadata, err := o.provider.getMetadata(req.Context())
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to get the OpenID Connect provider metadata")
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+
+	ctx := context.WithValue(req.Context(), oauth2.HTTPClient, o.client)
+	token, err := o.oauth2Config(req, metadata).Exchange(ctx, query.Get("code"),
+		oauth2.SetAuthURLParam("code_verifier", state.Verifier))
+	if err != nil {
+		logger.Debug().Err(err).Msg("Unable to exchange the authorization code")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	session := &oidcSession{Created: o.now().Unix()}
+	if err := o.updateSession(req.Context(), session, token, state.Nonce); err != nil {
+		logger.Debug().Err(err).Msg("Invalid ID token")
+		tracing.SetErrorWithEvent(req, "Authentication failed")
+		http.Error(rw, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if err := o.setSession(rw, req, session); err != nil {
+		logger.Error().Err(err).Msg("Unable to set the session cookie")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	logger.Debug().Msg("Authentication succeeded")
+
+	http.Redirect(rw, req, localRedirectURI(state.RequestURI), http.StatusFound)
+}
+
+// localRedirectURI returns the given request URI when it is a path on the same host, and the root path otherwise,
+// so that the users are never redirected to another host after the authentication, e.g. by a request to //evil.example/.
+func localRedirectURI(uri string) string {
+	if !strings.HasPrefix(uri, "/") || strings.HasPrefix(uri, "//") || strings.HasPrefix(uri, "/\\") {
+		return "/"
+	}
+
+	return uri
+}
+
+// logout removes the session cookie,
+// and redirects the users to the provider for them to log out when the provider supports it.
+// Only the POST requests from the same origin log the users out, so that other sites cannot log them out.
+func (o *oidcAuth) logout(logger *zerolog.Logger, rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		rw.Header().Set("Allow", http.MethodPost)
+		http.Error(rw, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if !sameOrigin(req) {
+		logger.Debug().Msgf("Cross-origin logout request from %s", req.Header.Get("Origin"))
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	http.SetCookie(rw, o.cookie(req, o.cookieName, "", -1))
+
+	metadata, err := o.provider.getMetadata(req.Context())
+	if err != nil {
+		logger.Debug().Err(err).Msg("Unable to get the OpenID Connect provider metadata")
+	}
+
+	if metadata == nil || metadata.EndSessionEndpoint == "" {
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.WriteHeader(http.StatusOK)
+		_, _ = rw.Write([]byte("Logged out\n"))
+		return
+	}
+
+	endSessionURL, err := url.Parse(metadata.EndSessionEndpoint)
+	if err != nil {
+		logger.Error().Err(err).Msg("Invalid end session endpoint")
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+
+	query := endSessionURL.Query()
+	query.Set("client_id", o.clientID)
+	endSessionURL.RawQuery = query.Encode()
+
+	http.Redirect(rw, req, endSessionURL.String(), http.StatusFound)
+}
+
+// sameOrigin reports whether the request is sent from the origin of the requested host,
+// the requests without the Origin header being sent from the same origin.
+func sameOrigin(req *http.Request) bool {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	return originURL.Scheme == requestScheme(req) && strings.EqualFold(originURL.Host, req.Host)
+}
+
+// refresh refreshes the tokens of the session.
+func (o *oidcAuth) refresh(ctx context.Context, session *oidcSession) error {
+	metadata, err := o.provider.getMetadata(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, o.client)
+	config := o.oauth2Config(nil, metadata)
+	token, err := config.TokenSource(ctx, &oauth2.Token{RefreshToken: session.RefreshToken}).Token()
+	if err != nil {
+		return err
+	}
+
+	return o.updateSession(ctx, session, token, "")
+}
+
+// updateSession updates the session with the tokens returned by the provider.
+func (o *oidcAuth) updateSession(ctx context.Context, session *oidcSession, token *oauth2.Token, nonce string) error {
+	session.RefreshToken = token.RefreshToken
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		if nonce != "" {
+			return errors.New("no ID token in the token response")
+		}
+
+		// The refreshed tokens do not have to hold an ID token, the claims are then kept.
+		if token.Expiry.IsZero() {
+			return errors.New("no ID token nor expiry time in the token response")
+		}
+		session.Expiry = token.Expiry.Unix()
+		return nil
+	}
+
+	idToken, claims, err := o.provider.verify(ctx, rawIDToken, nonce)
+	if err != nil {
+		return err
+	}
+
+	session.Expiry = idToken.Expiry.Time().Unix()
+	session.Claims = map[string]interface{}{"sub": idToken.Subject}
+	for _, claim := range o.claimsHeaders {
+		if value, ok := claims[claim]; ok {
+			session.Claims[claim] = value
+		}
+	}
+
+	return nil
+}
+
+func (o *oidcAuth) getSession(req *http.Request) (*oidcSession, error) {
+	cookie, err := req.Cookie(o.cookieName)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &oidcSession{}
+	if err := o.codec.decode(o.cookieName, cookie.Value, session); err != nil {
+		return nil, err
+	}
+
+	if o.now().Sub(time.Unix(session.Created, 0)) >= o.sessionMaxAge {
+		return nil, errors.New("session max age exceeded")
+	}
+
+	return session, nil
+}
+
+func (o *oidcAuth) setSession(rw http.ResponseWriter, req *http.Request, session *oidcSession) error {
+	value, err := o.codec.encode(o.cookieName, session)
+	if err != nil {
+		return err
+	}
+
+	if len(value) > oidcMaxCookieSize {
+		middlewares.GetLogger(req.Context(), o.name, oidcTypeName).Warn().
+			Msgf("Session cookie of %d bytes, it may be refused by the browsers", len(value))
+	}
+
+	maxAge := time.Unix(session.Created, 0).Add(o.sessionMaxAge).Sub(o.now())
+	http.SetCookie(rw, o.cookie(req, o.cookieName, value, maxAge))
+
+	return nil
+}
+
+// removeSessionCookie removes the session cookie from the request forwarded to the service.
+func (o *oidcAuth) removeSessionCookie(req *http.Request) {
+	cookies := req.Cookies()
+	req.Header.Del("Cookie")
+
+	for _, cookie := range cookies {
+		if cookie.Name != o.cookieName {
+			req.AddCookie(cookie)
+		}
+	}
+}
+
+func (o *oidcAuth) stateCookieName() string {
+	return o.cookieName + "_state"
+}
+
+// cookie returns a cookie with the given value, removing the cookie when maxAge is negative.
+func (o *oidcAuth) cookie(req *http.Request, name, value string, maxAge time.Duration) *http.Cookie {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   requestScheme(req) == "https",
+		// Lax allows the cookies to be sent with the redirection from the provider.
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(maxAge.Seconds()),
+	}
+
+	if maxAge < 0 {
+		cookie.MaxAge = -1
+	}
+
+	return cookie
+}
+
+// oauth2Config returns the OAuth 2.0 configuration of the middleware,
+// whose redirect URL is computed from the request when not configured.
+func (o *oidcAuth) oauth2Config(req *http.Request, metadata *oidcMetadata) *oauth2.Config {
+	redirectURL := o.redirectURL
+	if redirectURL == "" && req != nil {
+		redirectURL = requestScheme(req) + "://" + req.Host + o.callbackPath
+	}
+
+	return &oauth2.Config{
+		ClientID:     o.clientID,
+		ClientSecret: o.clientSecret,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  metadata.AuthorizationEndpoint,
+			TokenURL: metadata.TokenEndpoint,
+		},
+		RedirectURL: redirectURL,
+		Scopes:      o.scopes,
+	}
+}
+
+// requestScheme returns the scheme of the request as sent by the client.
+func requestScheme(req *http.Request) string {
+	if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+		return proto
+	}
+
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// claimValue returns the header value of a claim.
+func claimValue(claim interface{}) (string, bool) {
+	switch value := claim.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for _, v := range value {
+			if s, ok := claimValue(v); ok {
+				values = append(values, s)
+			}
+		}
+		return strings.Join(values, ","), true
+	case map[string]interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", false
+		}
+		return string(data), true
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+func randomString() string {
+	data := make([]byte, 32)
+	_, _ = rand.Read(data)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
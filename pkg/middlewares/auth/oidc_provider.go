@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+const (
+	oidcDiscoveryPath = "/.well-known/openid-configuration"
+	// oidcKeysRefreshInterval is the minimum interval between two reads of the provider keys,
+	// when an ID token is signed with an unknown key.
+	oidcKeysRefreshInterval = time.Minute
+	// oidcLeeway is the clock skew tolerated when validating the ID tokens.
+	oidcLeeway = time.Minute
+)
+
+// oidcMetadata holds the OpenID Connect provider metadata used by the middleware.
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	EndSessionEndpoint    string `json:"end_session_endpoint"`
+}
+
+// oidcProvider discovers the metadata and keys of an OpenID Connect provider, and verifies its ID tokens.
+// The metadata are discovered on first use, so that an unavailable provider does not prevent the creation of the middleware.
+type oidcProvider struct {
+	issuer   string
+	clientID string
+	client   *http.Client
+	now      func() time.Time
+
+	mu          sync.Mutex
+	metadata    *oidcMetadata
+	keys        *jose.JSONWebKeySet
+	keysFetched time.Time
+}
+
+func newOIDCProvider(issuer, clientID string, client *http.Client) *oidcProvider {
+	return &oidcProvider{
+		issuer:   strings.TrimSuffix(issuer, "/"),
+		clientID: clientID,
+		client:   client,
+		now:      time.Now,
+	}
+}
+
+// getMetadata returns the provider metadata, discovering them if needed.
+func (p *oidcProvider) getMetadata(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	metadata := &oidcMetadata{}
+	if err := p.getJSON(ctx, p.issuer+oidcDiscoveryPath, metadata); err != nil {
+		return nil, fmt.Errorf("discovering provider metadata: %w", err)
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("issuer %q of the provider metadata does not match %q", metadata.Issuer, p.issuer)
+	}
+
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, errors.New("incomplete provider metadata")
+	}
+
+	p.metadata = metadata
+	return metadata, nil
+}
+
+// getKey returns the provider key having the given ID,
+// reading the provider keys again when the key is unknown.
+func (p *oidcProvider) getKey(ctx context.Context, keyID string) (*jose.JSONWebKey, error) {
+	metadata, err := p.getMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.keys != nil {
+		if keys := p.keys.Key(keyID); len(keys) > 0 {
+			return &keys[0], nil
+		}
+
+		if p.now().Sub(p.keysFetched) < oidcKeysRefreshInterval {
+			return nil, fmt.Errorf("unknown key %q", keyID)
+		}
+	}
+
+	keys := &jose.JSONWebKeySet{}
+	if err := p.getJSON(ctx, metadata.JWKSURI, keys); err != nil {
+		return nil, fmt.Errorf("reading provider keys: %w", err)
+	}
+
+	p.keys = keys
+	p.keysFetched = p.now()
+
+	if keys := p.keys.Key(keyID); len(keys) > 0 {
+		return &keys[0], nil
+	}
+	return nil, fmt.Errorf("unknown key %q", keyID)
+}
+
+// verify verifies the signature and the claims of the given ID token,
+// and returns its claims, indexed by claim name.
+// The nonce is not verified when empty, as the ID tokens returned by a refresh do not have to hold one.
+func (p *oidcProvider) verify(ctx context.Context, rawIDToken, nonce string) (*jwt.Claims, map[string]interface{}, error) {
+	metadata, err := p.getMetadata(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	token, err := jwt.ParseSigned(rawIDToken)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing ID token: %w", err)
+	}
+
+	if len(token.Headers) != 1 {
+		return nil, nil, errors.New("ID token must have exactly one signature")
+	}
+
+	key, err := p.getKey(ctx, token.Headers[0].KeyID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	claims := &jwt.Claims{}
+	extra := make(map[string]interface{})
+	if err := token.Claims(key, claims, &extra); err != nil {
+		return nil, nil, fmt.Errorf("verifying ID token: %w", err)
+	}
+
+	if claims.Expiry == nil {
+		return nil, nil, errors.New("ID token has no expiry time")
+	}
+
+	expected := jwt.Expected{
+		Issuer:   metadata.Issuer,
+		Audience: jwt.Audience{p.clientID},
+		Time:     p.now(),
+	}
+	if err := claims.ValidateWithLeeway(expected, oidcLeeway); err != nil {
+		return nil, nil, fmt.Errorf("validating ID token: %w", err)
+	}
+
+	if nonce != "" && extra["nonce"] != nonce {
+		return nil, nil, errors.New("invalid ID token nonce")
+	}
+
+	return claims, extra, nil
+}
+
+func (p *oidcProvider) getJSON(ctx context.Context, url string, value interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d from %s", resp.StatusCode, url)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(value)
+}
@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// oidcSession is the content of the session cookie of an authenticated user.
+type oidcSession struct {
+	// Claims holds the claims of the ID token used by the middleware, indexed by claim name.
+	Claims       map[string]interface{} `json:"claims,omitempty"`
+	RefreshToken string                 `json:"refreshToken,omitempty"`
+	// Expiry is the expiry time of the ID token, as a Unix time.
+	Expiry int64 `json:"expiry"`
+	// Created is the authentication time of the user, as a Unix time.
+	Created int64 `json:"created"`
+}
+
+// oidcState is the content of the state cookie of a user being authenticated.
+type oidcState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	// RequestURI is the URI of the request which started the authentication.
+	RequestURI string `json:"requestURI"`
+}
+
+// cookieCodec encrypts and authenticates the values of the cookies with AES-GCM.
+// The cookie name is authenticated along with the value, so that a value cannot be replayed in another cookie.
+type cookieCodec struct {
+	aead cipher.AEAD
+}
+
+func newCookieCodec(secret string) (*cookieCodec, error) {
+	key := sha256.Sum256([]byte(secret))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cookieCodec{aead: aead}, nil
+}
+
+func (c *cookieCodec) encode(name string, value interface{}) (string, error) {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(c.aead.Seal(nonce, nonce, plaintext, []byte(name))), nil
+}
+
+func (c *cookieCodec) decode(name, encoded string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return err
+	}
+
+	if len(data) < c.aead.NonceSize() {
+		return errors.New("cookie value too short")
+	}
+
+	plaintext, err := c.aead.Open(nil, data[:c.aead.NonceSize()], data[c.aead.NonceSize():], []byte(name))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(plaintext, value)
+}
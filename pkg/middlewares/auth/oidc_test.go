@@ -0,0 +1,534 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+const oidcTestSecret = "0123456789abcdef0123456789abcdef"
+
+// fakeOIDCProvider is an OpenID Connect provider issuing the tokens of a single user.
+type fakeOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// audience of the ID tokens, the client ID by default.
+	audience string
+	// nonce and challenge of the last authorization request.
+	nonce     string
+	challenge string
+	// grants received by the token endpoint.
+	grants []string
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &fakeOIDCProvider{key: key, audience: "client"}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(oidcDiscoveryPath, func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(oidcMetadata{
+			Issuer:                p.server.URL,
+			AuthorizationEndpoint: p.server.URL + "/authorize",
+			TokenEndpoint:         p.server.URL + "/token",
+			JWKSURI:               p.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(rw http.ResponseWriter, req *http.Request) {
+		_ = json.NewEncoder(rw).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "key", Algorithm: string(jose.RS256), Use: "sig"},
+		}})
+	})
+	mux.HandleFunc("/token", func(rw http.ResponseWriter, req *http.Request) {
+		if err := req.ParseForm(); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		grant := req.PostForm.Get("grant_type")
+		p.grants = append(p.grants, grant)
+
+		nonce := p.nonce
+		switch grant {
+		case "authorization_code":
+			verifier := sha256.Sum256([]byte(req.PostForm.Get("code_verifier")))
+			if req.PostForm.Get("code") != "code" || base64.RawURLEncoding.EncodeToString(verifier[:]) != p.challenge {
+				http.Error(rw, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+		case "refresh_token":
+			if req.PostForm.Get("refresh_token") != "refresh" {
+				http.Error(rw, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+				return
+			}
+			nonce = ""
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(map[string]interface{}{
+			"access_token":  "access",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+			"refresh_token": "refresh",
+			"id_token":      p.idToken(t, nonce),
+		})
+	})
+
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+
+	return p
+}
+
+func (p *fakeOIDCProvider) idToken(t *testing.T, nonce string) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: p.key}, (&jose.SignerOptions{}).WithHeader("kid", "key"))
+	require.NoError(t, err)
+
+	claims := jwt.Claims{
+		Issuer:   p.server.URL,
+		Subject:  "user",
+		Audience: jwt.Audience{p.audience},
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(time.Now()),
+	}
+	extra := map[string]interface{}{
+		"nonce":  nonce,
+		"email":  "user@example.com",
+		"groups": []string{"admin", "dev"},
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).Claims(extra).CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func newTestOIDC(t *testing.T, provider *fakeOIDCProvider, next http.Handler) *oidcAuth {
+	t.Helper()
+
+	handler, err := NewOIDC(context.Background(), next, dynamic.OIDC{
+		Issuer:        provider.server.URL,
+		ClientID:      "client",
+		ClientSecret:  "secret",
+		LogoutPath:    "/logout",
+		SessionSecret: oidcTestSecret,
+		ClaimsHeaders: map[string]string{
+			"X-Auth-Email":  "email",
+			"X-Auth-Groups": "groups",
+		},
+	}, "oidcTest")
+	require.NoError(t, err)
+
+	return handler.(*oidcAuth)
+}
+
+func TestOIDC(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+
+	var forwarded *http.Request
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		forwarded = req
+	})
+
+	middleware := newTestOIDC(t, provider, next)
+
+	// The unauthenticated users are redirected to the provider.
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/foo?bar=baz", nil)
+	req.Header.Set("X-Auth-Email", "spoofed@example.com")
+	rw := httptest.NewRecorder()
+	middleware.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusFound, rw.Code)
+	assert.Nil(t, forwarded)
+
+	location, err := url.Parse(rw.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, provider.server.URL+"/authorize", location.Scheme+"://"+location.Host+location.Path)
+
+	query := location.Query()
+	assert.Equal(t, "client", query.Get("client_id"))
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "openid profile email", query.Get("scope"))
+	assert.Equal(t, "http://example.com/oauth2/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	provider.nonce = query.Get("nonce")
+	provider.challenge = query.Get("code_challenge")
+
+	stateCookies := rw.Result().Cookies()
+	require.Len(t, stateCookies, 1)
+	assert.Equal(t, "traefik_oidc_state", stateCookies[0].Name)
+
+	// The provider redirects the users to the callback.
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/oauth2/callback?code=code&state="+query.Get("state"), nil)
+	req.AddCookie(stateCookies[0])
+	rw = httptest.NewRecorder()
+	middleware.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusFound, rw.Code)
+	assert.Equal(t, "/foo?bar=baz", rw.Header().Get("Location"))
+	assert.Equal(t, []string{"authorization_code"}, provider.grants)
+
+	var sessionCookie *http.Cookie
+	for _, cookie := range rw.Result().Cookies() {
+		if cookie.Name == "traefik_oidc" {
+			sessionCookie = cookie
+		}
+	}
+	require.NotNil(t, sessionCookie)
+	assert.True(t, sessionCookie.HttpOnly)
+
+	// The authenticated users reach the service, with the claims headers.
+	req = httptest.NewRequest(http.MethodPost, "http://example.com/foo", nil)
+	req.Header.Set("X-Auth-Email", "spoofed@example.com")
+	req.AddCookie(sessionCookie)
+	req.AddCookie(&http.Cookie{Name: "other", Value: "value"})
+	rw = httptest.NewRecorder()
+	middleware.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	require.NotNil(t, forwarded)
+	assert.Equal(t, "user@example.com", forwarded.Header.Get("X-Auth-Email"))
+	assert.Equal(t, "admin,dev", forwarded.Header.Get("X-Auth-Groups"))
+	assert.Equal(t, "other=value", forwarded.Header.Get("Cookie"))
+
+	// The users log out.
+	req = httptest.NewRequest(http.MethodPost, "http://example.com/logout", nil)
+	req.AddCookie(sessionCookie)
+	rw = httptest.NewRecorder()
+	middleware.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	cookies := rw.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "traefik_oidc", cookies[0].Name)
+	assert.Equal(t, -1, cookies[0].MaxAge)
+}
+
+func TestOIDC_unauthenticated(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+
+	testCases := []struct {
+		desc          string
+		method        string
+		cookie        *http.Cookie
+		expStatusCode int
+	}{
+		{
+			desc:          "GET without session",
+			method:        http.MethodGet,
+			expStatusCode: http.StatusFound,
+		},
+		{
+			desc:          "POST without session",
+			method:        http.MethodPost,
+			expStatusCode: http.StatusUnauthorized,
+		},
+		{
+			desc:          "tampered session",
+			method:        http.MethodPost,
+			cookie:        &http.Cookie{Name: "traefik_oidc", Value: "dGFtcGVyZWQgc2Vzc2lvbiBjb29raWUgdmFsdWU"},
+			expStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				t.Error("the request must not be forwarded")
+			})
+			middleware := newTestOIDC(t, provider, next)
+
+			req := httptest.NewRequest(test.method, "http://example.com/foo", nil)
+			if test.cookie != nil {
+				req.AddCookie(test.cookie)
+			}
+			rw := httptest.NewRecorder()
+			middleware.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expStatusCode, rw.Code)
+		})
+	}
+}
+
+func TestOIDC_logout(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		method        string
+		origin        string
+		expStatusCode int
+	}{
+		{
+			desc:          "POST",
+			method:        http.MethodPost,
+			expStatusCode: http.StatusOK,
+		},
+		{
+			desc:          "POST from the same origin",
+			method:        http.MethodPost,
+			origin:        "http://example.com",
+			expStatusCode: http.StatusOK,
+		},
+		{
+			desc:          "POST from another origin",
+			method:        http.MethodPost,
+			origin:        "http://evil.example",
+			expStatusCode: http.StatusForbidden,
+		},
+		{
+			desc:          "GET",
+			method:        http.MethodGet,
+			expStatusCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			middleware := newTestOIDC(t, newFakeOIDCProvider(t), http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				t.Error("the request must not be forwarded")
+			}))
+
+			req := httptest.NewRequest(test.method, "http://example.com/logout", nil)
+			if test.origin != "" {
+				req.Header.Set("Origin", test.origin)
+			}
+			rw := httptest.NewRecorder()
+			middleware.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expStatusCode, rw.Code)
+
+			// the session cookie is only removed on logout.
+			assert.Equal(t, test.expStatusCode == http.StatusOK, len(rw.Result().Cookies()) == 1)
+		})
+	}
+}
+
+func TestOIDC_callback(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		state         string
+		stateCookie   bool
+		requestURI    string
+		audience      string
+		nonce         string
+		expStatusCode int
+		expLocation   string
+	}{
+		{
+			desc:          "valid callback",
+			state:         "state",
+			stateCookie:   true,
+			nonce:         "nonce",
+			expStatusCode: http.StatusFound,
+			expLocation:   "/foo",
+		},
+		{
+			desc:          "protocol-relative request URI",
+			state:         "state",
+			stateCookie:   true,
+			requestURI:    "//evil.example/",
+			nonce:         "nonce",
+			expStatusCode: http.StatusFound,
+			expLocation:   "/",
+		},
+		{
+			desc:          "absolute request URI",
+			state:         "state",
+			stateCookie:   true,
+			requestURI:    "https://evil.example/",
+			nonce:         "nonce",
+			expStatusCode: http.StatusFound,
+			expLocation:   "/",
+		},
+		{
+			desc:          "backslash request URI",
+			state:         "state",
+			stateCookie:   true,
+			requestURI:    "/\\evil.example/",
+			nonce:         "nonce",
+			expStatusCode: http.StatusFound,
+			expLocation:   "/",
+		},
+		{
+			desc:          "missing state cookie",
+			state:         "state",
+			nonce:         "nonce",
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "invalid state",
+			state:         "other",
+			stateCookie:   true,
+			nonce:         "nonce",
+			expStatusCode: http.StatusBadRequest,
+		},
+		{
+			desc:          "invalid nonce",
+			state:         "state",
+			stateCookie:   true,
+			nonce:         "other",
+			expStatusCode: http.StatusUnauthorized,
+		},
+		{
+			desc:          "invalid audience",
+			state:         "state",
+			stateCookie:   true,
+			audience:      "other",
+			nonce:         "nonce",
+			expStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			provider := newFakeOIDCProvider(t)
+			if test.audience != "" {
+				provider.audience = test.audience
+			}
+			provider.nonce = test.nonce
+
+			challenge := sha256.Sum256([]byte("verifier"))
+			provider.challenge = base64.RawURLEncoding.EncodeToString(challenge[:])
+
+			middleware := newTestOIDC(t, provider, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+
+			requestURI := test.requestURI
+			if requestURI == "" {
+				requestURI = "/foo"
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/oauth2/callback?code=code&state="+test.state, nil)
+			if test.stateCookie {
+				value, err := middleware.codec.encode("traefik_oidc_state", oidcState{
+					State:      "state",
+					Nonce:      "nonce",
+					Verifier:   "verifier",
+					RequestURI: requestURI,
+				})
+				require.NoError(t, err)
+				req.AddCookie(&http.Cookie{Name: "traefik_oidc_state", Value: value})
+			}
+			rw := httptest.NewRecorder()
+			middleware.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expStatusCode, rw.Code)
+			if test.expLocation != "" {
+				assert.Equal(t, test.expLocation, rw.Header().Get("Location"))
+			}
+		})
+	}
+}
+
+func TestOIDC_refresh(t *testing.T) {
+	provider := newFakeOIDCProvider(t)
+
+	var forwarded *http.Request
+	middleware := newTestOIDC(t, provider, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		forwarded = req
+	}))
+
+	value, err := middleware.codec.encode("traefik_oidc", oidcSession{
+		Claims:       map[string]interface{}{"sub": "user"},
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Minute).Unix(),
+		Created:      time.Now().Add(-time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/foo", nil)
+	req.AddCookie(&http.Cookie{Name: "traefik_oidc", Value: value})
+	rw := httptest.NewRecorder()
+	middleware.ServeHTTP(rw, req)
+
+	require.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, []string{"refresh_token"}, provider.grants)
+
+	require.NotNil(t, forwarded)
+	assert.Equal(t, "user@example.com", forwarded.Header.Get("X-Auth-Email"))
+
+	cookies := rw.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	session := &oidcSession{}
+	require.NoError(t, middleware.codec.decode("traefik_oidc", cookies[0].Value, session))
+	assert.Greater(t, session.Expiry, time.Now().Unix())
+	assert.Equal(t, time.Now().Add(-time.Hour).Unix(), session.Created, "the refresh does not extend the session")
+}
+
+func TestNewOIDC_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.OIDC
+	}{
+		{
+			desc:   "missing issuer",
+			config: dynamic.OIDC{ClientID: "client", SessionSecret: oidcTestSecret},
+		},
+		{
+			desc:   "missing client ID",
+			config: dynamic.OIDC{Issuer: "https://issuer.example.com", SessionSecret: oidcTestSecret},
+		},
+		{
+			desc:   "short session secret",
+			config: dynamic.OIDC{Issuer: "https://issuer.example.com", ClientID: "client", SessionSecret: "short"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := NewOIDC(context.Background(), http.NotFoundHandler(), test.config, "oidcTest")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCookieCodec(t *testing.T) {
+	codec, err := newCookieCodec(oidcTestSecret)
+	require.NoError(t, err)
+
+	value, err := codec.encode("name", oidcState{State: "state"})
+	require.NoError(t, err)
+
+	state := oidcState{}
+	require.NoError(t, codec.decode("name", value, &state))
+	assert.Equal(t, "state", state.State)
+
+	assert.Error(t, codec.decode("other", value, &state), "the value is bound to the cookie name")
+
+	otherCodec, err := newCookieCodec("fedcba9876543210fedcba9876543210")
+	require.NoError(t, err)
+	assert.Error(t, otherCodec.decode("name", value, &state))
+}
@@ -0,0 +1,236 @@
+package botdetection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "BotDetection"
+)
+
+const (
+	actionBlock  = "block"
+	actionTarpit = "tarpit"
+)
+
+// Results of the requests, reported in the metrics.
+const (
+	resultBlocked    = "blocked"
+	resultTarpitted  = "tarpitted"
+	resultChallenged = "challenged"
+	resultPassed     = "passed"
+	resultFailed     = "failed"
+)
+
+// botDetection refuses the requests of the clients detected as bots by their headers,
+// and has the other clients solve a proof-of-work challenge, when configured.
+type botDetection struct {
+	next http.Handler
+	name string
+
+	// tarpitDelay is how long the requests of the bots are held before being refused, zero to refuse them at once.
+	tarpitDelay time.Duration
+
+	blockedUserAgents []*regexp.Regexp
+	allowedUserAgents []*regexp.Regexp
+	requiredHeaders   []string
+
+	challenge *challenge
+
+	counter gokitmetrics.Counter
+}
+
+// New creates a bot detection middleware.
+// The metrics registry records the blocked, tarpitted, and challenged requests, and the solved challenges, it may be nil.
+func New(ctx context.Context, next http.Handler, config dynamic.BotDetection, metricsRegistry metrics.Registry, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	b := &botDetection{
+		next:            next,
+		name:            name,
+		requiredHeaders: config.RequiredHeaders,
+	}
+
+	switch config.Action {
+	case actionBlock:
+	case actionTarpit:
+		if config.TarpitDelay <= 0 {
+			return nil, errors.New("tarpitDelay must be positive")
+		}
+		b.tarpitDelay = time.Duration(config.TarpitDelay)
+	default:
+		return nil, fmt.Errorf("unknown action %q, must be %s or %s", config.Action, actionBlock, actionTarpit)
+	}
+
+	var err error
+	b.blockedUserAgents, err = compileAll(config.BlockedUserAgents)
+	if err != nil {
+		return nil, fmt.Errorf("compiling blocked user agents: %w", err)
+	}
+
+	b.allowedUserAgents, err = compileAll(config.AllowedUserAgents)
+	if err != nil {
+		return nil, fmt.Errorf("compiling allowed user agents: %w", err)
+	}
+
+	if config.Challenge != nil {
+		strategy, err := config.IPStrategy.Get()
+		if err != nil {
+			return nil, err
+		}
+
+		b.challenge, err = newChallenge(config.Challenge, strategy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if metricsRegistry != nil && metricsRegistry.IsMiddlewareEnabled() {
+		b.counter = metricsRegistry.MiddlewareBotReqsCounter()
+	}
+
+	return b, nil
+}
+
+func (b *botDetection) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return b.name, tracing.SpanKindNoneEnum
+}
+
+func (b *botDetection) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if matchAny(b.allowedUserAgents, req.UserAgent()) {
+		b.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if reason := b.detect(req); reason != "" {
+		b.refuse(rw, req, reason)
+		return
+	}
+
+	if b.challenge == nil {
+		b.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if solution := req.Header.Get(solutionHeader); solution != "" {
+		b.verify(rw, req, solution)
+		return
+	}
+
+	if b.challenge.passed(req) {
+		b.next.ServeHTTP(rw, req)
+		return
+	}
+
+	tracing.LogEventf(req, "Responding with the bot challenge")
+	b.count(resultChallenged)
+
+	b.challenge.serve(rw, req)
+}
+
+// detect returns why the request is coming from a bot, or an empty string.
+func (b *botDetection) detect(req *http.Request) string {
+	userAgent := req.UserAgent()
+	if userAgent == "" {
+		return "missing User-Agent"
+	}
+
+	if matchAny(b.blockedUserAgents, userAgent) {
+		return "blocked User-Agent"
+	}
+
+	for _, header := range b.requiredHeaders {
+		if req.Header.Get(header) == "" {
+			return "missing " + header + " header"
+		}
+	}
+
+	return ""
+}
+
+// refuse refuses the request of a bot, after holding it for the tarpit delay if any.
+func (b *botDetection) refuse(rw http.ResponseWriter, req *http.Request, reason string) {
+	logger := middlewares.GetLogger(req.Context(), b.name, typeName)
+
+	if b.tarpitDelay <= 0 {
+		logger.Debug().Msgf("Refusing the request of a bot: %s", reason)
+		tracing.SetErrorWithEvent(req, "Refusing the request of a bot: %s", reason)
+		b.count(resultBlocked)
+
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	logger.Debug().Msgf("Tarpitting the request of a bot: %s", reason)
+	tracing.SetErrorWithEvent(req, "Tarpitting the request of a bot: %s", reason)
+	b.count(resultTarpitted)
+
+	timer := time.NewTimer(b.tarpitDelay)
+	defer timer.Stop()
+
+	select {
+	case <-req.Context().Done():
+		return
+	case <-timer.C:
+	}
+
+	http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+// verify checks the solution of a challenge sent by the challenge page, and sets the cookie of the solved challenge.
+func (b *botDetection) verify(rw http.ResponseWriter, req *http.Request, solution string) {
+	cookie, err := b.challenge.solve(req, solution)
+	if err != nil {
+		middlewares.GetLogger(req.Context(), b.name, typeName).Debug().Err(err).Msg("Invalid challenge solution")
+		tracing.SetErrorWithEvent(req, "Invalid challenge solution: %v", err)
+		b.count(resultFailed)
+
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	b.count(resultPassed)
+
+	http.SetCookie(rw, cookie)
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (b *botDetection) count(result string) {
+	if b.counter != nil {
+		b.counter.With("middleware", b.name, "result", result).Add(1)
+	}
+}
+
+func compileAll(exprs []string) ([]*regexp.Regexp, error) {
+	var res []*regexp.Regexp
+	for _, expr := range exprs {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+func matchAny(res []*regexp.Regexp, value string) bool {
+	for _, re := range res {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,456 @@
+package botdetection
+
+import (
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/ip"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+)
+
+const browserUserAgent = "Mozilla/5.0 (X11; Linux x86_64; rv:109.0) Gecko/20100101 Firefox/115.0"
+
+// collectingCounter is a metrics.Counter implementation that enables access to the counted values by labels.
+type collectingCounter struct {
+	values          map[string]float64
+	lastLabelValues []string
+}
+
+func (c *collectingCounter) With(labelValues ...string) gokitmetrics.Counter {
+	c.lastLabelValues = labelValues
+	return c
+}
+
+func (c *collectingCounter) Add(delta float64) {
+	c.values[c.lastLabelValues[len(c.lastLabelValues)-1]] += delta
+}
+
+type botRegistry struct {
+	metrics.Registry
+	counter *collectingCounter
+}
+
+func (r *botRegistry) IsMiddlewareEnabled() bool {
+	return true
+}
+
+func (r *botRegistry) MiddlewareBotReqsCounter() gokitmetrics.Counter {
+	return r.counter
+}
+
+func newConfig(apply func(*dynamic.BotDetection)) dynamic.BotDetection {
+	config := dynamic.BotDetection{}
+	config.SetDefaults()
+	if apply != nil {
+		apply(&config)
+	}
+	return config
+}
+
+func newChallengeConfig() *dynamic.BotChallenge {
+	challenge := &dynamic.BotChallenge{Secret: "secret"}
+	challenge.SetDefaults()
+	challenge.Difficulty = 8
+	return challenge
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config func(*dynamic.BotDetection)
+	}{
+		{
+			desc:   "unknown action",
+			config: func(c *dynamic.BotDetection) { c.Action = "drop" },
+		},
+		{
+			desc:   "tarpit without delay",
+			config: func(c *dynamic.BotDetection) { c.Action = actionTarpit; c.TarpitDelay = 0 },
+		},
+		{
+			desc:   "invalid blocked user agent",
+			config: func(c *dynamic.BotDetection) { c.BlockedUserAgents = []string{"("} },
+		},
+		{
+			desc:   "invalid allowed user agent",
+			config: func(c *dynamic.BotDetection) { c.AllowedUserAgents = []string{"("} },
+		},
+		{
+			desc: "challenge difficulty too high",
+			config: func(c *dynamic.BotDetection) {
+				c.Challenge = newChallengeConfig()
+				c.Challenge.Difficulty = 33
+			},
+		},
+		{
+			desc: "challenge without ttl",
+			config: func(c *dynamic.BotDetection) {
+				c.Challenge = newChallengeConfig()
+				c.Challenge.TTL = 0
+			},
+		},
+		{
+			desc: "challenge without cookie name",
+			config: func(c *dynamic.BotDetection) {
+				c.Challenge = newChallengeConfig()
+				c.Challenge.CookieName = ""
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), newConfig(test.config), nil, "invalid@file")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBotDetection_detection(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         func(*dynamic.BotDetection)
+		headers        map[string]string
+		expectedStatus int
+	}{
+		{
+			desc:           "browser",
+			headers:        map[string]string{"User-Agent": browserUserAgent},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "missing user agent",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "default blocked user agent",
+			headers:        map[string]string{"User-Agent": "sqlmap/1.7 (https://sqlmap.org)"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "case-insensitive blocked user agent",
+			config:         func(c *dynamic.BotDetection) { c.BlockedUserAgents = []string{"^curl/"} },
+			headers:        map[string]string{"User-Agent": "Curl/8.0"},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "missing required header",
+			config:         func(c *dynamic.BotDetection) { c.RequiredHeaders = []string{"Accept-Language"} },
+			headers:        map[string]string{"User-Agent": browserUserAgent},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "required header",
+			config:         func(c *dynamic.BotDetection) { c.RequiredHeaders = []string{"Accept-Language"} },
+			headers:        map[string]string{"User-Agent": browserUserAgent, "Accept-Language": "en"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc: "allowed user agent",
+			config: func(c *dynamic.BotDetection) {
+				c.AllowedUserAgents = []string{"^probe/"}
+				c.RequiredHeaders = []string{"Accept-Language"}
+				c.Challenge = newChallengeConfig()
+			},
+			headers:        map[string]string{"User-Agent": "probe/1.0"},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			handler, err := New(context.Background(), next, newConfig(test.config), nil, "bots@file")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+func TestBotDetection_tarpit(t *testing.T) {
+	config := newConfig(func(c *dynamic.BotDetection) {
+		c.Action = actionTarpit
+		c.TarpitDelay = ptypes.Duration(100 * time.Millisecond)
+	})
+
+	handler, err := New(context.Background(), http.NotFoundHandler(), config, nil, "bots@file")
+	require.NoError(t, err)
+
+	start := time.Now()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// the request of a client going away is released at once.
+	config.TarpitDelay = ptypes.Duration(time.Hour)
+
+	handler, err = New(context.Background(), http.NotFoundHandler(), config, nil, "bots@file")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/", nil).WithContext(ctx))
+}
+
+func TestBotDetection_challenge(t *testing.T) {
+	counter := &collectingCounter{values: map[string]float64{}}
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("service"))
+	})
+
+	config := newConfig(func(c *dynamic.BotDetection) { c.Challenge = newChallengeConfig() })
+
+	handler, err := New(context.Background(), next, config, &botRegistry{counter: counter}, "bots@file")
+	require.NoError(t, err)
+
+	newRequest := func(userAgent string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.RemoteAddr = "10.0.0.1:42000"
+		return req
+	}
+
+	// the client without cookie gets the challenge page.
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, newRequest(browserUserAgent))
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+	matches := regexp.MustCompile(`var challenge = "([^"]+)", difficulty = +(\d+)`).FindStringSubmatch(recorder.Body.String())
+	require.Len(t, matches, 3)
+	assert.Equal(t, "8", matches[2])
+
+	solution := solve(t, matches[1], 8)
+
+	// a solution from another user agent, or another IP, is refused.
+	req := newRequest("Other/1.0")
+	req.Header.Set(solutionHeader, solution)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	req = newRequest(browserUserAgent)
+	req.RemoteAddr = "10.0.0.2:42000"
+	req.Header.Set(solutionHeader, solution)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// the solution is accepted, and the cookie set.
+	req = newRequest(browserUserAgent)
+	req.Header.Set(solutionHeader, solution)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "traefik_bot", cookies[0].Name)
+	assert.True(t, cookies[0].HttpOnly)
+
+	// the requests with the cookie are forwarded.
+	req = newRequest(browserUserAgent)
+	req.AddCookie(cookies[0])
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "service", recorder.Body.String())
+
+	// the cookie is bound to the user agent.
+	req = newRequest("Other/1.0")
+	req.AddCookie(cookies[0])
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// the cookie is bound to the IP of the client.
+	req = newRequest(browserUserAgent)
+	req.RemoteAddr = "10.0.0.2:42000"
+	req.AddCookie(cookies[0])
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// a new connection of the same client is accepted.
+	req = newRequest(browserUserAgent)
+	req.RemoteAddr = "10.0.0.1:42001"
+	req.AddCookie(cookies[0])
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	assert.Equal(t, map[string]float64{
+		resultChallenged: 3,
+		resultPassed:     1,
+		resultFailed:     2,
+	}, counter.values)
+}
+
+func TestBotDetection_challengeIPStrategy(t *testing.T) {
+	config := newConfig(func(c *dynamic.BotDetection) {
+		c.Challenge = newChallengeConfig()
+		c.IPStrategy = &dynamic.IPStrategy{Depth: 1}
+	})
+
+	handler, err := New(context.Background(), http.NotFoundHandler(), config, nil, "bots@file")
+	require.NoError(t, err)
+
+	newRequest := func(remoteAddr, forwardedFor string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+		req.Header.Set("User-Agent", browserUserAgent)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.RemoteAddr = remoteAddr
+		return req
+	}
+
+	b := handler.(*botDetection)
+
+	token, err := b.challenge.sign("challenge", time.Now().Add(challengeTTL), newRequest("10.0.0.1:42000", "203.0.113.1"))
+	require.NoError(t, err)
+
+	cookie, err := b.challenge.solve(newRequest("10.0.0.1:42000", "203.0.113.1"), solve(t, token, 8))
+	require.NoError(t, err)
+
+	// the cookie is bound to the IP of the client given by the strategy, rather than to the IP of the proxy in front of Traefik.
+	req := newRequest("10.0.0.2:42000", "203.0.113.1")
+	req.AddCookie(cookie)
+	assert.True(t, b.challenge.passed(req))
+
+	req = newRequest("10.0.0.1:42000", "203.0.113.2")
+	req.AddCookie(cookie)
+	assert.False(t, b.challenge.passed(req))
+}
+
+func TestChallenge_solve(t *testing.T) {
+	c, err := newChallenge(newChallengeConfig(), &ip.RemoteAddrStrategy{})
+	require.NoError(t, err)
+
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/", nil)
+	req.Header.Set("User-Agent", browserUserAgent)
+
+	token, err := c.sign("challenge", now.Add(challengeTTL), req)
+	require.NoError(t, err)
+
+	// each challenge is unique.
+	other, err := c.sign("challenge", now.Add(challengeTTL), req)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	_, err = c.solve(req, "malformed")
+	assert.Error(t, err)
+
+	// a solution whose hash does not have enough leading zero bits.
+	for nonce := 0; ; nonce++ {
+		solution := token + "." + strconv.Itoa(nonce)
+		if leadingZeroBits(sha256.Sum256([]byte(solution))) < 8 {
+			_, err = c.solve(req, solution)
+			assert.Error(t, err)
+			break
+		}
+	}
+
+	// a pass cookie is not a challenge.
+	pass, err := c.sign("pass", now.Add(challengeTTL), req)
+	require.NoError(t, err)
+
+	_, err = c.solve(req, solve(t, pass, 8))
+	assert.Error(t, err)
+
+	solution := solve(t, token, 8)
+
+	cookie, err := c.solve(req, solution)
+	require.NoError(t, err)
+
+	req.AddCookie(cookie)
+	assert.True(t, c.passed(req))
+
+	// the cookie expires after the TTL, and the challenge after the challenge TTL.
+	now = now.Add(time.Hour)
+	assert.False(t, c.passed(req))
+
+	_, err = c.solve(req, solution)
+	assert.Error(t, err)
+}
+
+func TestChallenge_randomSecret(t *testing.T) {
+	config := newChallengeConfig()
+	config.Secret = ""
+
+	c1, err := newChallenge(config, &ip.RemoteAddrStrategy{})
+	require.NoError(t, err)
+
+	c2, err := newChallenge(config, &ip.RemoteAddrStrategy{})
+	require.NoError(t, err)
+
+	// each middleware instance generates its own secret.
+	assert.Len(t, c1.secret, secretLength)
+	assert.Len(t, c2.secret, secretLength)
+	assert.NotEqual(t, c1.secret, c2.secret)
+}
+
+// solve finds the nonce of the challenge, as the challenge page does.
+func solve(t *testing.T, challenge string, difficulty int) string {
+	t.Helper()
+
+	for nonce := 0; nonce < 1<<24; nonce++ {
+		solution := challenge + "." + strconv.Itoa(nonce)
+		if leadingZeroBits(sha256.Sum256([]byte(solution))) >= difficulty {
+			return solution
+		}
+	}
+
+	t.Fatal("no solution found")
+	return ""
+}
@@ -0,0 +1,304 @@
+package botdetection
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"math/bits"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/ip"
+)
+
+// solutionHeader is the header of the request sent by the challenge page with the solution of the challenge.
+const solutionHeader = "X-Traefik-Bot-Solution"
+
+// challengeTTL is how long a challenge can be solved once served.
+const challengeTTL = 5 * time.Minute
+
+// maxDifficulty is the maximum number of leading zero bits, beyond which the browsers would not solve the challenges in time.
+const maxDifficulty = 32
+
+// maxNonceLength bounds the nonce of the solutions, which the browsers find by counting.
+const maxNonceLength = 20
+
+// saltLength is the length of the random salt of the tokens, which makes each challenge unique.
+const saltLength = 16
+
+// secretLength is the length of the random secret generated when none is configured.
+const secretLength = 32
+
+// challenge is a proof-of-work challenge: the client has to find a nonce such that the SHA-256 hash
+// of the signed challenge and the nonce has a given number of leading zero bits.
+// The challenges and the cookies are signed with an HMAC, bound to the User-Agent and the IP of the client,
+// so that no state is kept for them.
+type challenge struct {
+	secret     []byte
+	difficulty int
+	ttl        time.Duration
+	cookieName string
+	strategy   ip.Strategy
+	now        func() time.Time
+}
+
+func newChallenge(config *dynamic.BotChallenge, strategy ip.Strategy) (*challenge, error) {
+	if config.Difficulty < 1 || config.Difficulty > maxDifficulty {
+		return nil, fmt.Errorf("invalid challenge difficulty %d, must be between 1 and %d", config.Difficulty, maxDifficulty)
+	}
+
+	if config.TTL <= 0 {
+		return nil, errors.New("challenge ttl must be positive")
+	}
+
+	if config.CookieName == "" {
+		return nil, errors.New("challenge cookieName must be set")
+	}
+
+	c := &challenge{
+		secret:     []byte(config.Secret),
+		difficulty: config.Difficulty,
+		ttl:        time.Duration(config.TTL),
+		cookieName: config.CookieName,
+		strategy:   strategy,
+		now:        time.Now,
+	}
+
+	// the random secret is kept by the middleware instance,
+	// so the cookies are not valid anymore once the middleware is built again for a new configuration.
+	if config.Secret == "" {
+		c.secret = make([]byte, secretLength)
+		if _, err := rand.Read(c.secret); err != nil {
+			return nil, fmt.Errorf("generating challenge secret: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// sign returns the <expiry>.<salt>.<signature> token of the given kind, bound to the User-Agent and the IP of the client.
+// The random salt makes each token unique, so that a solution cannot be computed ahead of the challenge.
+func (c *challenge) sign(kind string, expiry time.Time, req *http.Request) (string, error) {
+	random := make([]byte, saltLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("generating token salt: %w", err)
+	}
+
+	expires := strconv.FormatInt(expiry.Unix(), 10)
+	salt := base64.RawURLEncoding.EncodeToString(random)
+
+	return expires + "." + salt + "." + c.signature(kind, expires, salt, req), nil
+}
+
+func (c *challenge) signature(kind, expires, salt string, req *http.Request) string {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(strings.Join([]string{kind, expires, salt, req.UserAgent(), c.strategy.GetIP(req)}, "\x00")))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// checkToken checks the signature and the expiry of a token of the given kind.
+func (c *challenge) checkToken(kind, token string, req *http.Request) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return errors.New("malformed token")
+	}
+
+	expires, salt, signature := parts[0], parts[1], parts[2]
+
+	if !hmac.Equal([]byte(signature), []byte(c.signature(kind, expires, salt, req))) {
+		return errors.New("invalid signature")
+	}
+
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expiry: %w", err)
+	}
+
+	if !c.now().Before(time.Unix(unix, 0)) {
+		return errors.New("expired token")
+	}
+
+	return nil
+}
+
+// passed reports whether the request has the cookie of a solved challenge.
+func (c *challenge) passed(req *http.Request) bool {
+	cookie, err := req.Cookie(c.cookieName)
+	if err != nil {
+		return false
+	}
+
+	return c.checkToken("pass", cookie.Value, req) == nil
+}
+
+// solve checks the <challenge>.<nonce> solution, and returns the cookie of the solved challenge.
+func (c *challenge) solve(req *http.Request, solution string) (*http.Cookie, error) {
+	i := strings.LastIndex(solution, ".")
+	if i < 0 {
+		return nil, errors.New("malformed solution")
+	}
+
+	token, nonce := solution[:i], solution[i+1:]
+	if nonce == "" || len(nonce) > maxNonceLength {
+		return nil, errors.New("malformed nonce")
+	}
+
+	if err := c.checkToken("challenge", token, req); err != nil {
+		return nil, err
+	}
+
+	if leadingZeroBits(sha256.Sum256([]byte(solution))) < c.difficulty {
+		return nil, errors.New("insufficient proof of work")
+	}
+
+	expiry := c.now().Add(c.ttl)
+
+	value, err := c.sign("pass", expiry, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Cookie{
+		Name:     c.cookieName,
+		Value:    value,
+		Path:     "/",
+		Expires:  expiry,
+		Secure:   req.TLS != nil,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}, nil
+}
+
+// serve responds with the challenge page, which solves the challenge and reloads the page.
+func (c *challenge) serve(rw http.ResponseWriter, req *http.Request) {
+	token, err := c.sign("challenge", c.now().Add(challengeTTL), req)
+	if err != nil {
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	var page bytes.Buffer
+	err = challengePage.Execute(&page, map[string]interface{}{
+		"Challenge":  token,
+		"Difficulty": c.difficulty,
+		"Header":     solutionHeader,
+	})
+	if err != nil {
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.Header().Set("Content-Length", strconv.Itoa(page.Len()))
+
+	rw.WriteHeader(http.StatusForbidden)
+
+	if req.Method != http.MethodHead {
+		_, _ = rw.Write(page.Bytes())
+	}
+}
+
+func leadingZeroBits(hash [sha256.Size]byte) int {
+	var n int
+	for _, b := range hash {
+		if b != 0 {
+			return n + bits.LeadingZeros8(b)
+		}
+		n += 8
+	}
+	return n
+}
+
+// challengePage computes the SHA-256 hashes itself, as the Web Crypto API is only available to secure contexts,
+// and the hashes are computed by batches, not to freeze the page.
+var challengePage = template.Must(template.New("challenge").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Checking your browser</title></head>
+<body>
+<h1>Checking your browser</h1>
+<p id="status">Please wait, this page reloads automatically.</p>
+<noscript><p>JavaScript is required to access this page.</p></noscript>
+<script>
+(function () {
+  var challenge = {{.Challenge}}, difficulty = {{.Difficulty}}, header = {{.Header}};
+  var K = [
+    0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+    0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+    0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+    0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+    0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+    0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+    0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+    0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2
+  ];
+
+  // sha256 returns the hash of an ASCII string, as 8 words.
+  function sha256(s) {
+    var H = [0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19];
+    var l = s.length, n = ((l + 8) >> 6) + 1, w = [], W = [], i, j;
+    for (i = 0; i < n * 16; i++) w[i] = 0;
+    for (i = 0; i < l; i++) w[i >> 2] |= s.charCodeAt(i) << (24 - (i % 4) * 8);
+    w[l >> 2] |= 0x80 << (24 - (l % 4) * 8);
+    w[n * 16 - 1] = l * 8;
+    for (i = 0; i < w.length; i += 16) {
+      var a = H[0], b = H[1], c = H[2], d = H[3], e = H[4], f = H[5], g = H[6], h = H[7];
+      for (j = 0; j < 64; j++) {
+        if (j < 16) {
+          W[j] = w[i + j];
+        } else {
+          var x = W[j - 15], y = W[j - 2];
+          W[j] = (((x >>> 7) | (x << 25)) ^ ((x >>> 18) | (x << 14)) ^ (x >>> 3)) + W[j - 16] +
+            (((y >>> 17) | (y << 15)) ^ ((y >>> 19) | (y << 13)) ^ (y >>> 10)) + W[j - 7] | 0;
+        }
+        var t1 = h + (((e >>> 6) | (e << 26)) ^ ((e >>> 11) | (e << 21)) ^ ((e >>> 25) | (e << 7))) +
+          ((e & f) ^ (~e & g)) + K[j] + W[j] | 0;
+        var t2 = (((a >>> 2) | (a << 30)) ^ ((a >>> 13) | (a << 19)) ^ ((a >>> 22) | (a << 10))) +
+          ((a & b) ^ (a & c) ^ (b & c)) | 0;
+        h = g; g = f; f = e; e = d + t1 | 0; d = c; c = b; b = a; a = t1 + t2 | 0;
+      }
+      H[0] = H[0] + a | 0; H[1] = H[1] + b | 0; H[2] = H[2] + c | 0; H[3] = H[3] + d | 0;
+      H[4] = H[4] + e | 0; H[5] = H[5] + f | 0; H[6] = H[6] + g | 0; H[7] = H[7] + h | 0;
+    }
+    return H;
+  }
+
+  function leadingZeroBits(H) {
+    for (var i = 0, n = 0; i < H.length; i++, n += 32) {
+      if (H[i] !== 0) return n + Math.clz32(H[i]);
+    }
+    return n;
+  }
+
+  var nonce = 0;
+  function work() {
+    for (var end = nonce + 5000; nonce < end; nonce++) {
+      if (leadingZeroBits(sha256(challenge + "." + nonce)) >= difficulty) {
+        var headers = {};
+        headers[header] = challenge + "." + nonce;
+        fetch(location.href, {headers: headers, credentials: "same-origin"}).then(function (resp) {
+          if (resp.ok) {
+            location.reload();
+          } else {
+            document.getElementById("status").textContent = "Your browser could not be verified.";
+          }
+        });
+        return;
+      }
+    }
+    setTimeout(work, 0);
+  }
+  work();
+})();
+</script>
+</body>
+</html>
+`))
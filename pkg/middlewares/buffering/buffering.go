@@ -8,6 +8,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/logs"
+	"github.com/traefik/traefik/v3/pkg/metrics"
 	"github.com/traefik/traefik/v3/pkg/middlewares"
 	"github.com/traefik/traefik/v3/pkg/tracing"
 	oxybuffer "github.com/vulcand/oxy/v2/buffer"
@@ -23,9 +24,15 @@ type buffer struct {
 }
 
 // New creates a buffering middleware.
-func New(ctx context.Context, next http.Handler, config dynamic.Buffering, name string) (http.Handler, error) {
+// The metrics registry records the request bodies spooled to disk with the RequestOnly option, it may be nil.
+func New(ctx context.Context, next http.Handler, config dynamic.Buffering, metricsRegistry metrics.Registry, name string) (http.Handler, error) {
 	logger := middlewares.GetLogger(ctx, name, typeName)
 	logger.Debug().Msg("Creating middleware")
+
+	if config.RequestOnly {
+		return newRequestBuffer(ctx, next, config, metricsRegistry, name)
+	}
+
 	logger.Debug().Msgf("Setting up buffering: request limits: %d (mem), %d (max), response limits: %d (mem), %d (max) with retry: '%s'",
 		config.MemRequestBodyBytes, config.MaxRequestBodyBytes, config.MemResponseBodyBytes, config.MaxResponseBodyBytes, config.RetryExpression)
 
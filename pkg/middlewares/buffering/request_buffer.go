@@ -0,0 +1,175 @@
+package buffering
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+)
+
+var errBodyTooLarge = errors.New("request body too large")
+
+// requestBuffer buffers the request bodies before forwarding them, and streams the responses.
+// The bodies larger than the memory threshold are spooled to temporary files.
+type requestBuffer struct {
+	name string
+	next http.Handler
+
+	memBytes  int64
+	maxBytes  int64
+	directory string
+
+	spooledCounter gokitmetrics.Counter
+}
+
+func newRequestBuffer(ctx context.Context, next http.Handler, config dynamic.Buffering, metricsRegistry metrics.Registry, name string) (*requestBuffer, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msgf("Setting up request buffering: request limits: %d (mem), %d (max), spool directory: '%s'",
+		config.MemRequestBodyBytes, config.MaxRequestBodyBytes, config.SpoolDirectory)
+
+	if config.MemRequestBodyBytes < 0 {
+		return nil, fmt.Errorf("mem bytes should be >= 0 got %d", config.MemRequestBodyBytes)
+	}
+
+	if config.MaxRequestBodyBytes < 0 {
+		return nil, fmt.Errorf("max bytes should be >= 0 got %d", config.MaxRequestBodyBytes)
+	}
+
+	if config.SpoolDirectory != "" {
+		if info, err := os.Stat(config.SpoolDirectory); err != nil {
+			return nil, fmt.Errorf("invalid spool directory: %w", err)
+		} else if !info.IsDir() {
+			return nil, fmt.Errorf("invalid spool directory: %s is not a directory", config.SpoolDirectory)
+		}
+	}
+
+	b := &requestBuffer{
+		name:      name,
+		next:      next,
+		memBytes:  config.MemRequestBodyBytes,
+		maxBytes:  config.MaxRequestBodyBytes,
+		directory: config.SpoolDirectory,
+	}
+
+	if metricsRegistry != nil && metricsRegistry.IsMiddlewareEnabled() {
+		b.spooledCounter = metricsRegistry.MiddlewareReqsSpooledCounter()
+	}
+
+	return b, nil
+}
+
+func (b *requestBuffer) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return b.name, tracing.SpanKindNoneEnum
+}
+
+func (b *requestBuffer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Body == nil || req.Body == http.NoBody {
+		b.next.ServeHTTP(rw, req)
+		return
+	}
+
+	if b.maxBytes > 0 && req.ContentLength > b.maxBytes {
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body, size, err := b.bufferBody(req.Body)
+	if err != nil {
+		logger := middlewares.GetLogger(req.Context(), b.name, typeName)
+
+		if errors.Is(err, errBodyTooLarge) {
+			logger.Debug().Err(err).Send()
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		logger.Error().Err(err).Msg("Error while buffering the request body")
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+	defer func() { _ = body.Close() }()
+
+	// the buffered body is forwarded with its length, instead of being chunked.
+	req.Body = body
+	req.ContentLength = size
+	req.TransferEncoding = nil
+
+	b.next.ServeHTTP(rw, req)
+}
+
+// bufferBody reads the body in memory up to the memory threshold, and spools it to a temporary file beyond,
+// the returned body being closed removes the file.
+func (b *requestBuffer) bufferBody(body io.Reader) (io.ReadCloser, int64, error) {
+	// reads one more byte than the threshold, to know whether the body exceeds it.
+	var mem bytes.Buffer
+	n, err := io.CopyN(&mem, body, b.memBytes+1)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, 0, err
+	}
+
+	if b.maxBytes > 0 && n > b.maxBytes {
+		return nil, 0, errBodyTooLarge
+	}
+
+	if n <= b.memBytes {
+		return io.NopCloser(&mem), n, nil
+	}
+
+	file, err := os.CreateTemp(b.directory, "traefik-buffer-")
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating spool file: %w", err)
+	}
+
+	spooled := &spooledBody{File: file}
+
+	rest := body
+	if b.maxBytes > 0 {
+		// reads one more byte than the maximum, to know whether the body exceeds it.
+		rest = io.LimitReader(body, b.maxBytes-n+1)
+	}
+
+	written, err := io.Copy(file, io.MultiReader(&mem, rest))
+	if err != nil {
+		_ = spooled.Close()
+		return nil, 0, err
+	}
+
+	if b.maxBytes > 0 && written > b.maxBytes {
+		_ = spooled.Close()
+		return nil, 0, errBodyTooLarge
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		_ = spooled.Close()
+		return nil, 0, err
+	}
+
+	if b.spooledCounter != nil {
+		b.spooledCounter.With("middleware", b.name).Add(1)
+	}
+
+	return spooled, written, nil
+}
+
+// spooledBody is a request body spooled to a temporary file, removed when the body is closed.
+type spooledBody struct {
+	*os.File
+}
+
+func (s *spooledBody) Close() error {
+	err := s.File.Close()
+	if removeErr := os.Remove(s.File.Name()); removeErr != nil && !errors.Is(removeErr, os.ErrNotExist) {
+		return removeErr
+	}
+	return err
+}
@@ -0,0 +1,166 @@
+package buffering
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+)
+
+// collectingCounter is a metrics.Counter implementation that enables access to the counted value and LastLabelValues.
+type collectingCounter struct {
+	Value           float64
+	LastLabelValues []string
+}
+
+// With is there to satisfy the metrics.Counter interface.
+func (c *collectingCounter) With(labelValues ...string) gokitmetrics.Counter {
+	c.LastLabelValues = labelValues
+	return c
+}
+
+// Add is there to satisfy the metrics.Counter interface.
+func (c *collectingCounter) Add(delta float64) {
+	c.Value += delta
+}
+
+type spoolRegistry struct {
+	metrics.Registry
+	counter *collectingCounter
+}
+
+func (r *spoolRegistry) IsMiddlewareEnabled() bool {
+	return true
+}
+
+func (r *spoolRegistry) MiddlewareReqsSpooledCounter() gokitmetrics.Counter {
+	return r.counter
+}
+
+func TestRequestBuffer(t *testing.T) {
+	payload := bytes.Repeat([]byte("a"), 100)
+
+	testCases := []struct {
+		desc            string
+		config          dynamic.Buffering
+		chunked         bool
+		expectedCode    int
+		expectedSpooled bool
+	}{
+		{
+			desc:         "body buffered in memory",
+			config:       dynamic.Buffering{MemRequestBodyBytes: 100},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:            "body spooled to disk",
+			config:          dynamic.Buffering{MemRequestBodyBytes: 10},
+			expectedCode:    http.StatusOK,
+			expectedSpooled: true,
+		},
+		{
+			desc:            "chunked body spooled to disk",
+			config:          dynamic.Buffering{MemRequestBodyBytes: 10, MaxRequestBodyBytes: 100},
+			chunked:         true,
+			expectedCode:    http.StatusOK,
+			expectedSpooled: true,
+		},
+		{
+			desc:         "body larger than the maximum",
+			config:       dynamic.Buffering{MemRequestBodyBytes: 10, MaxRequestBodyBytes: 50},
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:         "chunked body larger than the maximum in memory",
+			config:       dynamic.Buffering{MemRequestBodyBytes: 100, MaxRequestBodyBytes: 50},
+			chunked:      true,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:         "chunked body larger than the maximum on disk",
+			config:       dynamic.Buffering{MemRequestBodyBytes: 10, MaxRequestBodyBytes: 50},
+			chunked:      true,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			spoolDir := t.TempDir()
+
+			test.config.RequestOnly = true
+			test.config.SpoolDirectory = spoolDir
+
+			var expectedFiles int
+			if test.expectedSpooled {
+				expectedFiles = 1
+			}
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, int64(len(payload)), req.ContentLength)
+
+				files, err := os.ReadDir(spoolDir)
+				require.NoError(t, err)
+				assert.Len(t, files, expectedFiles)
+
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				assert.Equal(t, payload, body)
+
+				rw.WriteHeader(http.StatusOK)
+			})
+
+			registry := &spoolRegistry{Registry: metrics.NewVoidRegistry(), counter: &collectingCounter{}}
+
+			buffMiddleware, err := New(context.Background(), next, test.config, registry, "foo")
+			require.NoError(t, err)
+
+			var body io.Reader = bytes.NewReader(payload)
+			if test.chunked {
+				// hides the length of the body.
+				body = io.MultiReader(body)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", body)
+			if test.chunked {
+				req.ContentLength = -1
+			}
+
+			recorder := httptest.NewRecorder()
+			buffMiddleware.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedCode, recorder.Code)
+
+			if test.expectedSpooled {
+				assert.Equal(t, 1.0, registry.counter.Value)
+				assert.Equal(t, []string{"middleware", "foo"}, registry.counter.LastLabelValues)
+			} else {
+				assert.Zero(t, registry.counter.Value)
+			}
+
+			// the spool files are removed once the request is served.
+			files, err := os.ReadDir(spoolDir)
+			require.NoError(t, err)
+			assert.Empty(t, files)
+		})
+	}
+}
+
+func TestRequestBuffer_invalidSpoolDirectory(t *testing.T) {
+	config := dynamic.Buffering{RequestOnly: true, SpoolDirectory: "/does/not/exist"}
+
+	_, err := New(context.Background(), http.NotFoundHandler(), config, nil, "foo")
+	require.ErrorContains(t, err, "invalid spool directory")
+}
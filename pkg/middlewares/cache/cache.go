@@ -0,0 +1,469 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/capture"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "Cache"
+
+	// cacheName is the name of the cache in the Cache-Status header (RFC 9211).
+	cacheName = "Traefik"
+)
+
+// revalidationTimeout is the maximum duration of the background revalidations of the stale responses.
+var revalidationTimeout = time.Minute
+
+// cache is a middleware storing the cacheable responses, and serving them to the next requests while they are fresh.
+type cache struct {
+	ctx         context.Context
+	next        http.Handler
+	revalidator http.Handler // next, capturing the responses of the background revalidations
+	name        string
+	store       store
+
+	maxEntrySize         int64
+	defaultTTL           time.Duration
+	staleWhileRevalidate time.Duration
+
+	revalidating sync.Map // keys of the responses being revalidated
+}
+
+// New creates a cache middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Cache, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	if config.MaxSize < 0 {
+		return nil, errors.New("the maximum size must be positive")
+	}
+	if config.MaxSize == 0 {
+		config.MaxSize = dynamic.DefaultCacheMaxSize
+	}
+
+	if config.MaxEntrySize < 0 {
+		return nil, errors.New("the maximum entry size must be positive")
+	}
+	if config.MaxEntrySize == 0 {
+		config.MaxEntrySize = dynamic.DefaultCacheMaxEntrySize
+	}
+
+	if config.DefaultTTL < 0 {
+		return nil, errors.New("the default TTL must be positive")
+	}
+
+	if config.StaleWhileRevalidate < 0 {
+		return nil, errors.New("the stale-while-revalidate duration must be positive")
+	}
+
+	s, err := getStore(ctx, name, config)
+	if err != nil {
+		return nil, err
+	}
+
+	revalidator, err := capture.Wrap(next)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cache{
+		ctx:                  ctx,
+		next:                 next,
+		revalidator:          revalidator,
+		name:                 name,
+		store:                s,
+		maxEntrySize:         config.MaxEntrySize,
+		defaultTTL:           time.Duration(config.DefaultTTL),
+		staleWhileRevalidate: time.Duration(config.StaleWhileRevalidate),
+	}, nil
+}
+
+func (c *cache) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return c.name, tracing.SpanKindNoneEnum
+}
+
+func (c *cache) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead || req.Header.Get("Upgrade") != "" {
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	reqCC := parseCacheControl(req.Header.Values("Cache-Control"))
+	if reqCC.has("no-store") {
+		rw.Header().Set("Cache-Status", cacheName+"; fwd=bypass")
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	ctx := req.Context()
+	logger := middlewares.GetLogger(ctx, c.name, typeName)
+
+	url := requestURL(req)
+	credentials := req.Header.Get("Authorization") != ""
+
+	// the Pragma header is only considered without Cache-Control header (RFC 7234 section 5.4).
+	noCache := reqCC.has("no-cache") || len(reqCC) == 0 && strings.Contains(strings.ToLower(req.Header.Get("Pragma")), "no-cache")
+
+	fwd := "request"
+	if !noCache {
+		now := time.Now()
+
+		e, key, err := c.lookup(ctx, url, req.Header)
+		if err != nil {
+			logger.Debug().Err(err).Msg("Unable to get the cached response")
+		}
+
+		switch {
+		case e == nil && key == url:
+			fwd = "uri-miss"
+
+		case e == nil:
+			fwd = "vary-miss"
+
+		case credentials && !e.Shared:
+			fwd = "request"
+
+		default:
+			age := e.age(now)
+			maxAge, hasMaxAge := reqCC.duration("max-age")
+
+			if age < e.Lifetime && (!hasMaxAge || age <= maxAge) {
+				c.serve(rw, req, e, now, "hit")
+				return
+			}
+
+			if !hasMaxAge && age < e.Lifetime+e.StaleWhileRevalidate {
+				c.serve(rw, req, e, now, "hit; detail=stale")
+				c.revalidate(req, url, key, e, credentials)
+				return
+			}
+
+			fwd = "stale"
+		}
+
+		if reqCC.has("only-if-cached") {
+			rw.Header().Set("Cache-Status", cacheName+"; fwd=miss; detail=only-if-cached")
+			http.Error(rw, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+			return
+		}
+	}
+
+	if req.Method == http.MethodHead {
+		rw.Header().Set("Cache-Status", cacheName+"; fwd="+fwd)
+		c.next.ServeHTTP(rw, req)
+		return
+	}
+
+	rec := &recorder{rw: rw, cacheStatus: cacheName + "; fwd=" + fwd, maxBodySize: c.maxEntrySize}
+	c.next.ServeHTTP(rec, req)
+
+	if e := c.newEntry(rec, credentials); e != nil {
+		if err := c.storeEntry(ctx, url, req.Header, e); err != nil {
+			logger.Debug().Err(err).Msg("Unable to store the response")
+		}
+	}
+}
+
+// lookup returns the stored response of the request, along with its key, the key of the URL when not found.
+func (c *cache) lookup(ctx context.Context, url string, header http.Header) (*entry, string, error) {
+	e, err := c.store.Get(ctx, url)
+	if err != nil || e == nil || len(e.Vary) == 0 {
+		return e, url, err
+	}
+
+	key := variantKey(url, e.Vary, header)
+
+	e, err = c.store.Get(ctx, key)
+	if e != nil && len(e.Vary) > 0 {
+		// a response stored under a variant key does not vary itself.
+		e = nil
+	}
+
+	return e, key, err
+}
+
+// serve writes the stored response, or a 304 Not Modified response when the request is conditional, and its validators match.
+func (c *cache) serve(rw http.ResponseWriter, req *http.Request, e *entry, now time.Time, status string) {
+	header := rw.Header()
+	for name, values := range e.Header {
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set("Age", strconv.FormatInt(int64(e.age(now)/time.Second), 10))
+	header.Set("Cache-Status", cacheName+"; "+status)
+
+	if e.Status == http.StatusOK && notModified(req.Header, e.Header) {
+		header.Del("Content-Length")
+		header.Del("Content-Type")
+		rw.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	rw.WriteHeader(e.Status)
+
+	if req.Method != http.MethodHead {
+		_, _ = rw.Write(e.Body)
+	}
+}
+
+// revalidate refreshes the stale response in the background, with a conditional request when the response has validators.
+// There is at most one revalidation of a response at a time.
+func (c *cache) revalidate(req *http.Request, url, key string, e *entry, credentials bool) {
+	if _, running := c.revalidating.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, revalidationTimeout)
+
+	// the request context is not used, as the client request completes before the revalidation.
+	revalidation := req.Clone(ctx)
+	revalidation.Body = http.NoBody
+	revalidation.ContentLength = 0
+	for _, name := range []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range"} {
+		revalidation.Header.Del(name)
+	}
+	if etag := e.Header.Get("ETag"); etag != "" {
+		revalidation.Header.Set("If-None-Match", etag)
+	}
+	if lastModified := e.Header.Get("Last-Modified"); lastModified != "" {
+		revalidation.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	go func() {
+		defer cancel()
+		defer c.revalidating.Delete(key)
+
+		logger := middlewares.GetLogger(ctx, c.name, typeName)
+
+		rec := &recorder{header: make(http.Header), maxBodySize: c.maxEntrySize}
+		c.revalidator.ServeHTTP(rec, revalidation)
+
+		if rec.status == http.StatusNotModified {
+			// the stored response is fresh again, with the headers of the 304 response (RFC 7234 section 4.3.4).
+			header := e.Header.Clone()
+			for name, values := range rec.header {
+				header[name] = values
+			}
+
+			rec = &recorder{header: header, status: e.Status, received: rec.received, maxBodySize: c.maxEntrySize}
+			rec.body.Write(e.Body)
+		}
+
+		updated := c.newEntry(rec, credentials)
+		if updated == nil {
+			logger.Debug().Int("status", rec.status).Msg("Revalidated response not cacheable, keeping the stale response")
+			return
+		}
+
+		if err := c.storeEntry(ctx, url, revalidation.Header, updated); err != nil {
+			logger.Debug().Err(err).Msg("Unable to store the revalidated response")
+		}
+	}()
+}
+
+// newEntry returns the entry of the recorded response, nil when the response cannot be stored.
+func (c *cache) newEntry(rec *recorder, credentials bool) *entry {
+	if rec.status == 0 || rec.overflow {
+		return nil
+	}
+
+	if _, ok := cacheableStatus[rec.status]; !ok {
+		return nil
+	}
+
+	header := rec.header
+	if header.Get("Set-Cookie") != "" {
+		return nil
+	}
+
+	if _, all := varyNames(header); all {
+		return nil
+	}
+
+	cc := parseCacheControl(header.Values("Cache-Control"))
+	if cc.has("no-store") || cc.has("private") || cc.has("no-cache") {
+		return nil
+	}
+
+	// the responses to the requests with credentials are only stored when explicitly allowed (RFC 7234 section 3.2).
+	shared := cc.has("public") || cc.has("s-maxage") || cc.has("must-revalidate")
+	if credentials && !shared {
+		return nil
+	}
+
+	lifetime := freshnessLifetime(header, cc, rec.received, c.defaultTTL)
+	if lifetime <= 0 {
+		return nil
+	}
+
+	staleWhileRevalidate := c.staleWhileRevalidate
+	if value, ok := cc.duration("stale-while-revalidate"); ok {
+		staleWhileRevalidate = value
+	}
+	if cc.has("must-revalidate") || cc.has("proxy-revalidate") {
+		staleWhileRevalidate = 0
+	}
+
+	header = header.Clone()
+	for _, name := range []string{"Age", "Cache-Status", "Connection", "Keep-Alive", "Transfer-Encoding", "Trailer"} {
+		header.Del(name)
+	}
+
+	return &entry{
+		Status:               rec.status,
+		Header:               header,
+		Body:                 rec.body.Bytes(),
+		Stored:               rec.received,
+		InitialAge:           initialAge(rec.header),
+		Lifetime:             lifetime,
+		StaleWhileRevalidate: staleWhileRevalidate,
+		Shared:               shared,
+	}
+}
+
+// storeEntry stores the response under the key of its URL, or of its variant along with the list of its Vary headers.
+func (c *cache) storeEntry(ctx context.Context, url string, reqHeader http.Header, e *entry) error {
+	ttl := e.ttl(time.Now())
+
+	names, _ := varyNames(e.Header)
+	if len(names) == 0 {
+		return c.store.Set(ctx, url, e, ttl)
+	}
+
+	if err := c.store.Set(ctx, url, &entry{Vary: names, Stored: e.Stored}, ttl); err != nil {
+		return err
+	}
+
+	return c.store.Set(ctx, variantKey(url, names, reqHeader), e, ttl)
+}
+
+// requestURL returns the URL of the request, which is the key of its responses.
+func requestURL(req *http.Request) string {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + req.Host + req.URL.RequestURI()
+}
+
+// variantKey returns the key of the responses of the URL for the values of the request headers they vary on.
+func variantKey(url string, names []string, header http.Header) string {
+	var b strings.Builder
+	b.WriteString(url)
+	for _, name := range names {
+		b.WriteString("\n")
+		b.WriteString(name)
+		b.WriteString(":")
+		b.WriteString(strings.Join(header.Values(name), ","))
+	}
+
+	return b.String()
+}
+
+// notModified returns whether the validators of the conditional request match the response (RFC 7232 section 6).
+func notModified(reqHeader, header http.Header) bool {
+	if ifNoneMatch := reqHeader.Get("If-None-Match"); ifNoneMatch != "" {
+		return etagMatch(ifNoneMatch, header.Get("ETag"))
+	}
+
+	ifModifiedSince, err := http.ParseTime(reqHeader.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+
+	return !lastModified.After(ifModifiedSince)
+}
+
+// recorder records the response, which is also written to the client unless it is revalidated in the background.
+type recorder struct {
+	rw          http.ResponseWriter // nil for the background revalidations
+	cacheStatus string
+
+	header      http.Header
+	status      int
+	received    time.Time
+	body        bytes.Buffer
+	maxBodySize int64
+	overflow    bool // whether the body exceeds the max size, and is not recorded anymore
+}
+
+func (r *recorder) Header() http.Header {
+	if r.rw != nil {
+		return r.rw.Header()
+	}
+
+	return r.header
+}
+
+func (r *recorder) WriteHeader(code int) {
+	if r.status != 0 {
+		return
+	}
+
+	// the informational responses are forwarded, the final response following.
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		if r.rw != nil {
+			r.rw.WriteHeader(code)
+		}
+		return
+	}
+
+	r.status = code
+	r.received = time.Now()
+
+	if r.rw == nil {
+		return
+	}
+
+	r.header = r.rw.Header().Clone()
+	r.rw.Header().Set("Cache-Status", r.cacheStatus)
+	r.rw.WriteHeader(code)
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if !r.overflow {
+		if int64(r.body.Len()+len(p)) > r.maxBodySize {
+			r.overflow = true
+			r.body = bytes.Buffer{}
+		} else {
+			r.body.Write(p)
+		}
+	}
+
+	if r.rw == nil {
+		return len(p), nil
+	}
+
+	return r.rw.Write(p)
+}
+
+func (r *recorder) Flush() {
+	if r.status == 0 {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := r.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
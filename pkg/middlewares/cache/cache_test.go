@@ -0,0 +1,420 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func newTestCache(t *testing.T, config dynamic.Cache, next http.Handler) http.Handler {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	handler, err := New(ctx, next, config, t.Name())
+	require.NoError(t, err)
+
+	// the store outlives the middleware, and would be reused when running the test again.
+	t.Cleanup(func() { _, _ = Purge(context.Background(), t.Name(), "") })
+
+	return handler
+}
+
+func serve(handler http.Handler, method, target string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	return rw
+}
+
+// countingHandler returns a handler writing the response headers and a body with the number of calls.
+func countingHandler(calls *atomic.Int32, header http.Header) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		count := calls.Add(1)
+		for name, values := range header {
+			rw.Header()[name] = values
+		}
+		_, _ = rw.Write([]byte("response " + strconv.Itoa(int(count))))
+	})
+}
+
+func TestCache_hit(t *testing.T) {
+	var calls atomic.Int32
+	handler := newTestCache(t, dynamic.Cache{}, countingHandler(&calls, http.Header{"Cache-Control": {"max-age=60"}}))
+
+	rw := serve(handler, http.MethodGet, "http://example.com/foo", nil)
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "response 1", rw.Body.String())
+	assert.Equal(t, "Traefik; fwd=uri-miss", rw.Header().Get("Cache-Status"))
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", nil)
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "response 1", rw.Body.String())
+	assert.Equal(t, "Traefik; hit", rw.Header().Get("Cache-Status"))
+	assert.Equal(t, "0", rw.Header().Get("Age"))
+	assert.Equal(t, "max-age=60", rw.Header().Get("Cache-Control"))
+
+	rw = serve(handler, http.MethodHead, "http://example.com/foo", nil)
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Empty(t, rw.Body.String())
+	assert.Equal(t, "Traefik; hit", rw.Header().Get("Cache-Status"))
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo?bar", nil)
+	assert.Equal(t, "response 2", rw.Body.String())
+
+	rw = serve(handler, http.MethodGet, "http://example.org/foo", nil)
+	assert.Equal(t, "response 3", rw.Body.String())
+
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestCache_notStored(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		config     dynamic.Cache
+		reqHeader  http.Header
+		respHeader http.Header
+		status     int
+		body       string
+	}{
+		{
+			desc:       "no expiration time",
+			respHeader: http.Header{},
+		},
+		{
+			desc:       "no-store",
+			respHeader: http.Header{"Cache-Control": {"max-age=60, no-store"}},
+		},
+		{
+			desc:       "private",
+			respHeader: http.Header{"Cache-Control": {"private, max-age=60"}},
+		},
+		{
+			desc:       "no-cache",
+			respHeader: http.Header{"Cache-Control": {"no-cache"}},
+			config:     dynamic.Cache{DefaultTTL: ptypes.Duration(time.Minute)},
+		},
+		{
+			desc:       "cookie",
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"session=foo"}},
+		},
+		{
+			desc:       "vary on all",
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}},
+		},
+		{
+			desc:       "expired",
+			respHeader: http.Header{"Expires": {"Thu, 01 Jan 1970 00:00:00 GMT"}},
+		},
+		{
+			desc:       "status not cacheable",
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}},
+			status:     http.StatusInternalServerError,
+		},
+		{
+			desc:       "too large",
+			config:     dynamic.Cache{MaxEntrySize: 4},
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}},
+		},
+		{
+			desc:       "request with credentials",
+			reqHeader:  http.Header{"Authorization": {"Basic Zm9vOmJhcg=="}},
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}},
+		},
+		{
+			desc:       "request with no-store",
+			reqHeader:  http.Header{"Cache-Control": {"no-store"}},
+			respHeader: http.Header{"Cache-Control": {"max-age=60"}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var calls atomic.Int32
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				calls.Add(1)
+				for name, values := range test.respHeader {
+					rw.Header()[name] = values
+				}
+				if test.status != 0 {
+					rw.WriteHeader(test.status)
+				}
+				_, _ = rw.Write([]byte("response"))
+			})
+
+			handler := newTestCache(t, test.config, next)
+
+			serve(handler, http.MethodGet, "http://example.com/foo", test.reqHeader)
+			rw := serve(handler, http.MethodGet, "http://example.com/foo", test.reqHeader)
+
+			assert.Equal(t, "response", rw.Body.String())
+			assert.NotEqual(t, "Traefik; hit", rw.Header().Get("Cache-Status"))
+			assert.Equal(t, int32(2), calls.Load())
+		})
+	}
+}
+
+func TestCache_defaultTTL(t *testing.T) {
+	var calls atomic.Int32
+	handler := newTestCache(t, dynamic.Cache{DefaultTTL: ptypes.Duration(time.Minute)}, countingHandler(&calls, nil))
+
+	serve(handler, http.MethodGet, "http://example.com/foo", nil)
+	rw := serve(handler, http.MethodGet, "http://example.com/foo", nil)
+
+	assert.Equal(t, "response 1", rw.Body.String())
+	assert.Equal(t, "Traefik; hit", rw.Header().Get("Cache-Status"))
+}
+
+func TestCache_sharedWithCredentials(t *testing.T) {
+	var calls atomic.Int32
+	handler := newTestCache(t, dynamic.Cache{}, countingHandler(&calls, http.Header{"Cache-Control": {"public, max-age=60"}}))
+
+	credentials := http.Header{"Authorization": {"Basic Zm9vOmJhcg=="}}
+
+	serve(handler, http.MethodGet, "http://example.com/foo", credentials)
+	rw := serve(handler, http.MethodGet, "http://example.com/foo", credentials)
+
+	assert.Equal(t, "response 1", rw.Body.String())
+	assert.Equal(t, "Traefik; hit", rw.Header().Get("Cache-Status"))
+}
+
+func TestCache_vary(t *testing.T) {
+	var calls atomic.Int32
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		count := calls.Add(1)
+		rw.Header().Set("Cache-Control", "max-age=60")
+		rw.Header().Set("Vary", "Accept-Language")
+		_, _ = rw.Write([]byte(req.Header.Get("Accept-Language") + " " + strconv.Itoa(int(count))))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{}, next)
+
+	english := http.Header{"Accept-Language": {"en"}}
+	french := http.Header{"Accept-Language": {"fr"}}
+
+	rw := serve(handler, http.MethodGet, "http://example.com/foo", english)
+	assert.Equal(t, "en 1", rw.Body.String())
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", french)
+	assert.Equal(t, "fr 2", rw.Body.String())
+	assert.Equal(t, "Traefik; fwd=vary-miss", rw.Header().Get("Cache-Status"))
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", english)
+	assert.Equal(t, "en 1", rw.Body.String())
+	assert.Equal(t, "Traefik; hit", rw.Header().Get("Cache-Status"))
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", french)
+	assert.Equal(t, "fr 2", rw.Body.String())
+	assert.Equal(t, "Traefik; hit", rw.Header().Get("Cache-Status"))
+}
+
+func TestCache_requestDirectives(t *testing.T) {
+	var calls atomic.Int32
+	handler := newTestCache(t, dynamic.Cache{}, countingHandler(&calls, http.Header{"Cache-Control": {"max-age=60"}, "Age": {"30"}}))
+
+	rw := serve(handler, http.MethodGet, "http://example.com/foo", http.Header{"Cache-Control": {"only-if-cached"}})
+	assert.Equal(t, http.StatusGatewayTimeout, rw.Code)
+
+	serve(handler, http.MethodGet, "http://example.com/foo", nil)
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", http.Header{"Cache-Control": {"max-age=10"}})
+	assert.Equal(t, "response 2", rw.Body.String(), "the stored response is older than the max age")
+	assert.Equal(t, "Traefik; fwd=stale", rw.Header().Get("Cache-Status"))
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", http.Header{"Cache-Control": {"no-cache"}})
+	assert.Equal(t, "response 3", rw.Body.String())
+	assert.Equal(t, "Traefik; fwd=request", rw.Header().Get("Cache-Status"))
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", nil)
+	assert.Equal(t, "response 3", rw.Body.String(), "the response to the no-cache request is stored")
+	assert.Equal(t, "Traefik; hit", rw.Header().Get("Cache-Status"))
+	assert.Equal(t, "30", rw.Header().Get("Age"))
+}
+
+func TestCache_conditional(t *testing.T) {
+	var calls atomic.Int32
+	handler := newTestCache(t, dynamic.Cache{}, countingHandler(&calls, http.Header{
+		"Cache-Control": {"max-age=60"},
+		"Etag":          {`"v1"`},
+		"Last-Modified": {"Mon, 02 Jan 2023 15:04:05 GMT"},
+	}))
+
+	serve(handler, http.MethodGet, "http://example.com/foo", nil)
+
+	rw := serve(handler, http.MethodGet, "http://example.com/foo", http.Header{"If-None-Match": {`"v0", W/"v1"`}})
+	assert.Equal(t, http.StatusNotModified, rw.Code)
+	assert.Empty(t, rw.Body.String())
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", http.Header{"If-None-Match": {`"v0"`}})
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", http.Header{"If-Modified-Since": {"Mon, 02 Jan 2023 15:04:05 GMT"}})
+	assert.Equal(t, http.StatusNotModified, rw.Code)
+
+	rw = serve(handler, http.MethodGet, "http://example.com/foo", http.Header{"If-Modified-Since": {"Sun, 01 Jan 2023 15:04:05 GMT"}})
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestCache_staleWhileRevalidate(t *testing.T) {
+	var calls atomic.Int32
+	var revalidations atomic.Value
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		count := calls.Add(1)
+
+		rw.Header().Set("Cache-Control", "max-age=1")
+		rw.Header().Set("Etag", `"v1"`)
+
+		if req.Header.Get("If-None-Match") == `"v1"` {
+			revalidations.Store(req.Header.Get("If-None-Match"))
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		// the first response is already stale.
+		rw.Header().Set("Age", "1")
+		_, _ = rw.Write([]byte("response " + strconv.Itoa(int(count))))
+	})
+
+	handler := newTestCache(t, dynamic.Cache{StaleWhileRevalidate: ptypes.Duration(time.Minute)}, next)
+
+	serve(handler, http.MethodGet, "http://example.com/foo", nil)
+
+	rw := serve(handler, http.MethodGet, "http://example.com/foo", nil)
+	assert.Equal(t, "response 1", rw.Body.String())
+	assert.Equal(t, "Traefik; hit; detail=stale", rw.Header().Get("Cache-Status"))
+
+	assert.Eventually(t, func() bool {
+		rw = serve(handler, http.MethodGet, "http://example.com/foo", nil)
+		return rw.Header().Get("Cache-Status") == "Traefik; hit"
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, "response 1", rw.Body.String(), "the stored response is fresh again once revalidated")
+	assert.Equal(t, `"v1"`, revalidations.Load())
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCache_mustRevalidate(t *testing.T) {
+	var calls atomic.Int32
+	handler := newTestCache(t, dynamic.Cache{StaleWhileRevalidate: ptypes.Duration(time.Minute)}, countingHandler(&calls, http.Header{
+		"Cache-Control": {"max-age=1, must-revalidate"},
+		"Age":           {"1"},
+	}))
+
+	serve(handler, http.MethodGet, "http://example.com/foo", nil)
+	rw := serve(handler, http.MethodGet, "http://example.com/foo", nil)
+
+	// the response is not kept past its freshness lifetime, as it must not be served stale.
+	assert.Equal(t, "response 2", rw.Body.String())
+	assert.Equal(t, "Traefik; fwd=uri-miss", rw.Header().Get("Cache-Status"))
+}
+
+func TestPurge(t *testing.T) {
+	var calls atomic.Int32
+	handler := newTestCache(t, dynamic.Cache{}, countingHandler(&calls, http.Header{"Cache-Control": {"max-age=60"}}))
+
+	serve(handler, http.MethodGet, "http://example.com/static/foo", nil)
+	serve(handler, http.MethodGet, "http://example.com/static/bar", nil)
+	serve(handler, http.MethodGet, "http://example.com/other", nil)
+
+	purged, err := Purge(context.Background(), t.Name(), "http://example.com/static/")
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	rw := serve(handler, http.MethodGet, "http://example.com/static/foo", nil)
+	assert.Equal(t, "Traefik; fwd=uri-miss", rw.Header().Get("Cache-Status"))
+
+	rw = serve(handler, http.MethodGet, "http://example.com/other", nil)
+	assert.Equal(t, "Traefik; hit", rw.Header().Get("Cache-Status"))
+
+	purged, err = Purge(context.Background(), t.Name(), "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	_, err = Purge(context.Background(), "unknown@file", "")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGetStore_release(t *testing.T) {
+	delay := storeReleaseDelay
+	storeReleaseDelay = 10 * time.Millisecond
+	t.Cleanup(func() { storeReleaseDelay = delay })
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	store1, err := getStore(ctx1, t.Name(), dynamic.Cache{MaxSize: 42})
+	require.NoError(t, err)
+
+	// the middlewares of the next configuration share the store.
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	store2, err := getStore(ctx2, t.Name(), dynamic.Cache{MaxSize: 42})
+	require.NoError(t, err)
+	assert.Same(t, store1, store2)
+
+	cancel1()
+	time.Sleep(50 * time.Millisecond)
+
+	_, err = Purge(context.Background(), t.Name(), "")
+	require.NoError(t, err)
+
+	cancel2()
+	assert.Eventually(t, func() bool {
+		_, err := Purge(context.Background(), t.Name(), "")
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestMemoryStore_eviction(t *testing.T) {
+	s := newMemoryStore(100)
+
+	e := func(body string) *entry {
+		return &entry{Status: http.StatusOK, Body: []byte(body)}
+	}
+
+	require.NoError(t, s.Set(context.Background(), "a", e(strings.Repeat("a", 40)), time.Minute))
+	require.NoError(t, s.Set(context.Background(), "b", e(strings.Repeat("b", 40)), time.Minute))
+
+	// a is used more recently than b.
+	got, err := s.Get(context.Background(), "a")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+
+	require.NoError(t, s.Set(context.Background(), "c", e(strings.Repeat("c", 40)), time.Minute))
+
+	got, err = s.Get(context.Background(), "b")
+	require.NoError(t, err)
+	assert.Nil(t, got, "the least recently used entry is evicted")
+
+	got, err = s.Get(context.Background(), "a")
+	require.NoError(t, err)
+	assert.NotNil(t, got)
+
+	require.NoError(t, s.Set(context.Background(), "d", e(strings.Repeat("d", 200)), time.Minute))
+	got, err = s.Get(context.Background(), "d")
+	require.NoError(t, err)
+	assert.Nil(t, got, "the entries larger than the store are not kept")
+
+	require.NoError(t, s.Set(context.Background(), "e", e("e"), -time.Second))
+	got, err = s.Get(context.Background(), "e")
+	require.NoError(t, err)
+	assert.Nil(t, got, "the expired entries are not returned")
+}
@@ -0,0 +1,144 @@
+package cache
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cacheableStatus are the status codes of the responses which can be stored, as they are cacheable by default (RFC 7231 section 6.1),
+// along with the 308 Permanent Redirect (RFC 7538).
+var cacheableStatus = map[int]struct{}{
+	http.StatusOK:                   {},
+	http.StatusNonAuthoritativeInfo: {},
+	http.StatusNoContent:            {},
+	http.StatusMultipleChoices:      {},
+	http.StatusMovedPermanently:     {},
+	http.StatusPermanentRedirect:    {},
+	http.StatusNotFound:             {},
+	http.StatusMethodNotAllowed:     {},
+	http.StatusGone:                 {},
+	http.StatusRequestURITooLong:    {},
+	http.StatusNotImplemented:       {},
+}
+
+// cacheControl holds the directives of Cache-Control headers, indexed by lowercase name.
+type cacheControl map[string]string
+
+func parseCacheControl(values []string) cacheControl {
+	cc := cacheControl{}
+	for _, value := range values {
+		for _, directive := range strings.Split(value, ",") {
+			directive = strings.TrimSpace(directive)
+			if directive == "" {
+				continue
+			}
+
+			name, arg, _ := strings.Cut(directive, "=")
+			cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(arg), `"`)
+		}
+	}
+
+	return cc
+}
+
+func (c cacheControl) has(name string) bool {
+	_, ok := c[name]
+	return ok
+}
+
+// duration returns the delta-seconds argument of the directive, and whether the directive has a valid one.
+func (c cacheControl) duration(name string) (time.Duration, bool) {
+	arg, ok := c[name]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// freshnessLifetime returns the freshness lifetime of a response (RFC 7234 section 4.2.1), for a shared cache,
+// the default TTL applying to the responses without explicit expiration time.
+func freshnessLifetime(header http.Header, cc cacheControl, now time.Time, defaultTTL time.Duration) time.Duration {
+	if lifetime, ok := cc.duration("s-maxage"); ok {
+		return lifetime
+	}
+
+	if lifetime, ok := cc.duration("max-age"); ok {
+		return lifetime
+	}
+
+	if expires := header.Get("Expires"); expires != "" {
+		expiresAt, err := http.ParseTime(expires)
+		if err != nil {
+			// an invalid date represents a time in the past.
+			return 0
+		}
+
+		date := now
+		if value, err := http.ParseTime(header.Get("Date")); err == nil {
+			date = value
+		}
+
+		if lifetime := expiresAt.Sub(date); lifetime > 0 {
+			return lifetime
+		}
+		return 0
+	}
+
+	return defaultTTL
+}
+
+// initialAge returns the age of a response when received, from its Age header.
+func initialAge(header http.Header) time.Duration {
+	seconds, err := strconv.ParseInt(header.Get("Age"), 10, 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// varyNames returns the canonical names of the request headers listed by the Vary header, sorted,
+// and whether the response varies on all of them, which makes it uncacheable.
+func varyNames(header http.Header) ([]string, bool) {
+	var names []string
+	for _, value := range header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil, true
+			}
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names, false
+}
+
+// etagMatch returns whether one of the entity tags of an If-None-Match header matches the entity tag, using the weak comparison.
+func etagMatch(ifNoneMatch, etag string) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
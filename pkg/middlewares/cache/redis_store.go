@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares/redisclient"
+)
+
+const redisKeyPrefix = "traefik:cache:"
+
+// redisScanCount is the number of keys scanned at once when purging the responses.
+const redisScanCount = 1000
+
+// redisCmdable is the subset of the Redis commands used by the store.
+type redisCmdable interface {
+	Get(ctx context.Context, key string) *redis.StringCmd
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd
+	Del(ctx context.Context, keys ...string) *redis.IntCmd
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+}
+
+// redisStore keeps the entries in Redis, to share them between several Traefik instances.
+type redisStore struct {
+	client  redisCmdable
+	key     string
+	timeout time.Duration
+
+	// forEachNode calls fn for each node of the Redis cluster, as the keys are only scanned on a single node.
+	forEachNode func(ctx context.Context, fn func(ctx context.Context, node redisCmdable) error) error
+}
+
+func newRedisStore(ctx context.Context, config *dynamic.Redis, name string) (*redisStore, error) {
+	client, err := redisclient.Get(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisStore{
+		client:  client,
+		key:     redisKeyPrefix + name + ":",
+		timeout: time.Duration(config.Timeout),
+		forEachNode: func(ctx context.Context, fn func(ctx context.Context, node redisCmdable) error) error {
+			return redisclient.ForEachNode(ctx, client, func(ctx context.Context, node redis.Cmdable) error {
+				return fn(ctx, node)
+			})
+		},
+	}, nil
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (*entry, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	data, err := s.client.Get(ctx, s.key+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("unmarshaling cache entry: %w", err)
+	}
+
+	return &e, nil
+}
+
+func (s *redisStore) Set(ctx context.Context, key string, e *entry, ttl time.Duration) error {
+	// Redis expires the keys with a precision of one millisecond.
+	if ttl < time.Millisecond {
+		return nil
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshaling cache entry: %w", err)
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return s.client.Set(ctx, s.key+key, data, ttl).Err()
+}
+
+func (s *redisStore) Purge(ctx context.Context, prefix string) (int, error) {
+	match := s.key + escapeGlob(prefix) + "*"
+
+	// the nodes of a cluster are scanned concurrently.
+	var purged atomic.Int64
+	err := s.forEachNode(ctx, func(ctx context.Context, node redisCmdable) error {
+		var cursor uint64
+		for {
+			keys, next, err := node.Scan(ctx, cursor, match, redisScanCount).Result()
+			if err != nil {
+				return err
+			}
+
+			// the keys are deleted one by one, as the keys of a cluster node belong to different hash slots.
+			for _, key := range keys {
+				count, err := node.Del(ctx, key).Result()
+				if err != nil {
+					return err
+				}
+				purged.Add(count)
+			}
+
+			if next == 0 {
+				return nil
+			}
+			cursor = next
+		}
+	})
+
+	return int(purged.Load()), err
+}
+
+func (s *redisStore) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, s.timeout)
+}
+
+// escapeGlob escapes the special characters of the Redis glob-style patterns.
+func escapeGlob(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteRune('\\')
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is an in-memory Redis node, whose scans return a single key at a time.
+type fakeRedis struct {
+	mu    sync.Mutex
+	data  map[string]string
+	ttls  map[string]time.Duration
+	order []string // keys in the scan order, kept when deleted for the cursors to remain valid
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{data: map[string]string{}, ttls: map[string]time.Duration{}}
+}
+
+func (f *fakeRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	value, ok := f.data[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (f *fakeRedis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.set(key, string(value.([]byte)))
+	f.ttls[key] = expiration
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (f *fakeRedis) set(key, value string) {
+	if _, ok := f.data[key]; !ok {
+		f.order = append(f.order, key)
+	}
+	f.data[key] = value
+}
+
+func (f *fakeRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var count int64
+	for _, key := range keys {
+		if _, ok := f.data[key]; ok {
+			delete(f.data, key)
+			count++
+		}
+	}
+	return redis.NewIntResult(count, nil)
+}
+
+// Scan only supports the patterns made of a literal prefix followed by a star, as used by the store.
+func (f *fakeRedis) Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	prefix := strings.TrimSuffix(match, "*")
+	prefix = strings.NewReplacer(`\\`, `\`, `\*`, "*", `\?`, "?", `\[`, "[", `\]`, "]").Replace(prefix)
+
+	var keys []string
+	for ; cursor < uint64(len(f.order)); cursor++ {
+		key := f.order[cursor]
+		if _, ok := f.data[key]; ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+			cursor++
+			break
+		}
+	}
+
+	if cursor >= uint64(len(f.order)) {
+		cursor = 0
+	}
+
+	return redis.NewScanCmdResult(keys, cursor, nil)
+}
+
+func TestRedisStore(t *testing.T) {
+	nodes := []*fakeRedis{newFakeRedis(), newFakeRedis()}
+
+	s := &redisStore{
+		client: nodes[0],
+		key:    redisKeyPrefix + "test@file:",
+		forEachNode: func(ctx context.Context, fn func(ctx context.Context, node redisCmdable) error) error {
+			for _, node := range nodes {
+				if err := fn(ctx, node); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	ctx := context.Background()
+
+	got, err := s.Get(ctx, "http://example.com/foo")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	stored := time.Date(2023, time.January, 2, 15, 4, 5, 0, time.UTC)
+	e := &entry{
+		Status:   http.StatusOK,
+		Header:   http.Header{"Content-Type": {"text/plain"}},
+		Body:     []byte("foo"),
+		Stored:   stored,
+		Lifetime: time.Minute,
+	}
+
+	require.NoError(t, s.Set(ctx, "http://example.com/foo", e, time.Minute))
+	assert.Equal(t, time.Minute, nodes[0].ttls["traefik:cache:test@file:http://example.com/foo"])
+
+	got, err = s.Get(ctx, "http://example.com/foo")
+	require.NoError(t, err)
+	assert.Equal(t, e, got)
+
+	// the entries expiring within a millisecond are not stored.
+	require.NoError(t, s.Set(ctx, "http://example.com/bar", e, time.Microsecond))
+	got, err = s.Get(ctx, "http://example.com/bar")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	require.NoError(t, s.Set(ctx, "http://example.com/static/[foo]", e, time.Minute))
+	require.NoError(t, s.Set(ctx, "http://example.com/static/foo", e, time.Minute))
+	nodes[1].set("traefik:cache:test@file:http://example.com/static/bar", "{}")
+	nodes[1].set("traefik:cache:other@file:http://example.com/static/bar", "{}")
+
+	purged, err := s.Purge(ctx, "http://example.com/static/[")
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged, "the special characters of the prefix are escaped")
+
+	purged, err = s.Purge(ctx, "http://example.com/static/")
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	purged, err = s.Purge(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	assert.Len(t, nodes[1].data, 1, "the responses of the other middlewares are kept")
+}
@@ -0,0 +1,277 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// ErrNotFound is returned when purging the responses of a middleware which is not a cache middleware.
+var ErrNotFound = errors.New("cache middleware not found")
+
+// entry is a stored response, or the list of the request headers the responses of a URL vary on.
+// The entries are not modified once stored, as they are shared by the requests.
+type entry struct {
+	// Vary holds the names of the request headers the responses vary on, for the entry of a URL whose responses are stored per variant.
+	Vary []string `json:"vary,omitempty"`
+
+	Status int         `json:"status,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   []byte      `json:"body,omitempty"`
+
+	Stored               time.Time     `json:"stored"`                         // time the response was received
+	InitialAge           time.Duration `json:"initialAge,omitempty"`           // age of the response when received
+	Lifetime             time.Duration `json:"lifetime,omitempty"`             // freshness lifetime
+	StaleWhileRevalidate time.Duration `json:"staleWhileRevalidate,omitempty"` // duration the response is served stale, while revalidated
+	Shared               bool          `json:"shared,omitempty"`               // whether the response is served to the requests with credentials
+}
+
+func (e *entry) age(now time.Time) time.Duration {
+	return e.InitialAge + now.Sub(e.Stored)
+}
+
+// ttl returns the duration the entry is kept, until it cannot be served stale anymore.
+func (e *entry) ttl(now time.Time) time.Duration {
+	return e.Lifetime + e.StaleWhileRevalidate - e.age(now)
+}
+
+func (e *entry) size(key string) int64 {
+	size := len(key) + len(e.Body)
+	for name, values := range e.Header {
+		size += len(name)
+		for _, value := range values {
+			size += len(value)
+		}
+	}
+	for _, name := range e.Vary {
+		size += len(name)
+	}
+
+	return int64(size)
+}
+
+// store keeps the entries until their TTL elapses.
+type store interface {
+	// Get returns the entry of the key, nil when there is none.
+	Get(ctx context.Context, key string) (*entry, error)
+	Set(ctx context.Context, key string, e *entry, ttl time.Duration) error
+	// Purge removes the entries whose key starts with the prefix, and returns how many were removed.
+	Purge(ctx context.Context, prefix string) (int, error)
+}
+
+// storeReleaseDelay is the delay before dropping a store no middleware uses anymore,
+// for the middlewares of the next configuration to reuse it.
+var storeReleaseDelay = 30 * time.Second
+
+// stores holds the stores shared by the middlewares having the same name and configuration,
+// as the middlewares are built again on each configuration change, and for each router using them.
+var (
+	storesMu sync.Mutex
+	stores   = map[string]*sharedStore{}
+)
+
+// sharedStore is a store counting the middlewares using it.
+type sharedStore struct {
+	store
+
+	name         string
+	cancel       context.CancelFunc
+	refs         int
+	releaseTimer *time.Timer
+}
+
+// getStore returns the store shared by the middlewares with the same name and configuration.
+// The store is released once the context, which is canceled when the middleware is replaced by a new configuration, is done.
+func getStore(ctx context.Context, name string, config dynamic.Cache) (store, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling cache configuration: %w", err)
+	}
+	key := name + "\n" + string(data)
+
+	storesMu.Lock()
+	defer storesMu.Unlock()
+
+	shared, ok := stores[key]
+	if !ok {
+		// the store outlives the middleware creating it, and keeps its Redis client until it is dropped.
+		storeCtx, cancel := context.WithCancel(context.Background())
+
+		var s store
+		if config.Redis != nil {
+			s, err = newRedisStore(storeCtx, config.Redis, name)
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+		} else {
+			s = newMemoryStore(config.MaxSize)
+		}
+
+		shared = &sharedStore{store: s, name: name, cancel: cancel}
+		stores[key] = shared
+	}
+
+	shared.refs++
+	if shared.releaseTimer != nil {
+		shared.releaseTimer.Stop()
+		shared.releaseTimer = nil
+	}
+
+	if done := ctx.Done(); done != nil {
+		go func() {
+			<-done
+			releaseStore(key, shared)
+		}()
+	}
+
+	return shared, nil
+}
+
+// releaseStore releases a reference to the store, and drops it after the release delay when no middleware uses it anymore.
+func releaseStore(key string, shared *sharedStore) {
+	storesMu.Lock()
+	defer storesMu.Unlock()
+
+	shared.refs--
+	if shared.refs > 0 {
+		return
+	}
+
+	shared.releaseTimer = time.AfterFunc(storeReleaseDelay, func() {
+		storesMu.Lock()
+		defer storesMu.Unlock()
+
+		if shared.refs == 0 && stores[key] == shared {
+			delete(stores, key)
+			shared.cancel()
+		}
+	})
+}
+
+// Purge removes the responses stored by the cache middleware whose URL starts with the prefix, all of them when empty,
+// and returns how many were removed.
+// The URL of a response is made of the scheme, the host, and the request URI, e.g. "https://example.com/static/".
+func Purge(ctx context.Context, name, prefix string) (int, error) {
+	storesMu.Lock()
+	var matching []store
+	for _, shared := range stores {
+		if shared.name == name {
+			matching = append(matching, shared.store)
+		}
+	}
+	storesMu.Unlock()
+
+	if len(matching) == 0 {
+		return 0, ErrNotFound
+	}
+
+	var purged int
+	for _, s := range matching {
+		count, err := s.Purge(ctx, prefix)
+		purged += count
+		if err != nil {
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// memoryStore keeps the entries in memory, evicting the least recently used ones above its maximum size.
+type memoryStore struct {
+	maxSize int64
+
+	mu      sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	lru     *list.List // most recently used first
+}
+
+type memoryItem struct {
+	key     string
+	entry   *entry
+	size    int64
+	expires time.Time
+}
+
+func newMemoryStore(maxSize int64) *memoryStore {
+	return &memoryStore{
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (s *memoryStore) Get(_ context.Context, key string) (*entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elt, ok := s.entries[key]
+	if !ok {
+		return nil, nil
+	}
+
+	item := elt.Value.(*memoryItem)
+	if !time.Now().Before(item.expires) {
+		s.remove(elt)
+		return nil, nil
+	}
+
+	s.lru.MoveToFront(elt)
+
+	return item.entry, nil
+}
+
+func (s *memoryStore) Set(_ context.Context, key string, e *entry, ttl time.Duration) error {
+	size := e.size(key)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elt, ok := s.entries[key]; ok {
+		s.remove(elt)
+	}
+
+	if size > s.maxSize {
+		return nil
+	}
+
+	for s.size+size > s.maxSize {
+		s.remove(s.lru.Back())
+	}
+
+	s.entries[key] = s.lru.PushFront(&memoryItem{key: key, entry: e, size: size, expires: time.Now().Add(ttl)})
+	s.size += size
+
+	return nil
+}
+
+func (s *memoryStore) Purge(_ context.Context, prefix string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var purged int
+	for key, elt := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.remove(elt)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (s *memoryStore) remove(elt *list.Element) {
+	item := s.lru.Remove(elt).(*memoryItem)
+	delete(s.entries, item.key)
+	s.size -= item.size
+}
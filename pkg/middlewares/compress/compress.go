@@ -1,17 +1,19 @@
 package compress
 
 import (
+	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"mime"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/klauspost/compress/gzhttp"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/middlewares"
-	"github.com/traefik/traefik/v3/pkg/middlewares/compress/brotli"
 	"github.com/traefik/traefik/v3/pkg/tracing"
 )
 
@@ -21,21 +23,44 @@ const typeName = "Compress"
 // See https://github.com/klauspost/compress/blob/9559b037e79ad673c71f6ef7c732c00949014cd2/gzhttp/compress.go#L47.
 const DefaultMinSize = 1024
 
+const (
+	brotliName = "br"
+	gzipName   = "gzip"
+	zstdName   = "zstd"
+)
+
+// defaultEncodings are the encodings of the responses, in order of preference.
+var defaultEncodings = []string{brotliName, zstdName, gzipName}
+
+const (
+	// defaultZstdLevel is the default Zstandard compression level, the one of the zstd command.
+	defaultZstdLevel = 3
+	// zstdWindowSize is the window size of the Zstandard encoder,
+	// the largest one the clients are required to support, see https://www.rfc-editor.org/rfc/rfc8878#section-3.1.1.1.2.
+	zstdWindowSize = 8 << 20
+)
+
 // Compress is a middleware that allows to compress the response.
 type compress struct {
 	next     http.Handler
 	name     string
 	excludes []string
+	includes []string
 	minSize  int
 
-	brotliHandler http.Handler
-	gzipHandler   http.Handler
+	// encodings are the encodings of the responses, in order of preference.
+	encodings []string
+	handlers  map[string]http.Handler
 }
 
 // New creates a new compress middleware.
 func New(ctx context.Context, next http.Handler, conf dynamic.Compress, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
 
+	if len(conf.ExcludedContentTypes) > 0 && len(conf.IncludedContentTypes) > 0 {
+		return nil, errors.New("excludedContentTypes and includedContentTypes options are mutually exclusive")
+	}
+
 	excludes := []string{"application/grpc"}
 	for _, v := range conf.ExcludedContentTypes {
 		mediaType, _, err := mime.ParseMediaType(v)
@@ -46,27 +71,58 @@ func New(ctx context.Context, next http.Handler, conf dynamic.Compress, name str
 		excludes = append(excludes, mediaType)
 	}
 
+	var includes []string
+	for _, v := range conf.IncludedContentTypes {
+		mediaType, _, err := mime.ParseMediaType(v)
+		if err != nil {
+			return nil, err
+		}
+
+		includes = append(includes, mediaType)
+	}
+
 	minSize := DefaultMinSize
 	if conf.MinResponseBodyBytes > 0 {
 		minSize = conf.MinResponseBodyBytes
 	}
 
-	c := &compress{
-		next:     next,
-		name:     name,
-		excludes: excludes,
-		minSize:  minSize,
+	encodings := defaultEncodings
+	if len(conf.Encodings) > 0 {
+		encodings = conf.Encodings
 	}
 
-	var err error
-	c.brotliHandler, err = c.newBrotliHandler()
-	if err != nil {
-		return nil, err
+	c := &compress{
+		next:      next,
+		name:      name,
+		excludes:  excludes,
+		includes:  includes,
+		minSize:   minSize,
+		encodings: encodings,
+		handlers:  make(map[string]http.Handler),
 	}
 
-	c.gzipHandler, err = c.newGzipHandler()
-	if err != nil {
-		return nil, err
+	for _, encoding := range encodings {
+		if _, ok := c.handlers[encoding]; ok {
+			return nil, fmt.Errorf("duplicate encoding %q", encoding)
+		}
+
+		var handler http.Handler
+		var err error
+		switch encoding {
+		case gzipName:
+			handler, err = c.newGzipHandler(conf.GzipLevel)
+		case brotliName:
+			handler, err = c.newCompressionHandler(brotliName, conf.BrotliLevel)
+		case zstdName:
+			handler, err = c.newCompressionHandler(zstdName, conf.ZstdLevel)
+		default:
+			err = fmt.Errorf("unsupported encoding %q, it must be one of %s, %s or %s", encoding, brotliName, zstdName, gzipName)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		c.handlers[encoding] = handler
 	}
 
 	return c, nil
@@ -92,22 +148,21 @@ func (c *compress) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Client allows us to do whatever we want, so we br compress.
+	// Client allows us to do whatever we want, so we compress with the preferred encoding.
 	// See https://www.rfc-editor.org/rfc/rfc9110.html#section-12.5.3
 	acceptEncoding, ok := req.Header["Accept-Encoding"]
 	if !ok {
-		c.brotliHandler.ServeHTTP(rw, req)
-		return
-	}
-
-	if encodingAccepts(acceptEncoding, "br") {
-		c.brotliHandler.ServeHTTP(rw, req)
+		c.handlers[c.encodings[0]].ServeHTTP(rw, req)
 		return
 	}
 
-	if encodingAccepts(acceptEncoding, "gzip") {
-		c.gzipHandler.ServeHTTP(rw, req)
-		return
+	// The encodings are chosen in order of preference, among the ones accepted by the client.
+	accepted := parseAcceptEncoding(acceptEncoding)
+	for _, encoding := range c.encodings {
+		if accepted.accepts(encoding) {
+			c.handlers[encoding].ServeHTTP(rw, req)
+			return
+		}
 	}
 
 	c.next.ServeHTTP(rw, req)
@@ -117,10 +172,20 @@ func (c *compress) GetTracingInformation() (string, ext.SpanKindEnum) {
 	return c.name, tracing.SpanKindNoneEnum
 }
 
-func (c *compress) newGzipHandler() (http.Handler, error) {
+func (c *compress) newGzipHandler(level int) (http.Handler, error) {
+	contentTypes := gzhttp.ExceptContentTypes(c.excludes)
+	if len(c.includes) > 0 {
+		contentTypes = gzhttp.ContentTypes(c.includes)
+	}
+
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+
 	wrapper, err := gzhttp.NewWrapper(
-		gzhttp.ExceptContentTypes(c.excludes),
+		contentTypes,
 		gzhttp.MinSize(c.minSize),
+		gzhttp.CompressionLevel(level),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("new gzip wrapper: %w", err)
@@ -129,34 +194,61 @@ func (c *compress) newGzipHandler() (http.Handler, error) {
 	return wrapper(c.next), nil
 }
 
-func (c *compress) newBrotliHandler() (http.Handler, error) {
-	cfg := brotli.Config{
+func (c *compress) newCompressionHandler(encoding string, level int) (http.Handler, error) {
+	cfg := Config{
 		ExcludedContentTypes: c.excludes,
+		IncludedContentTypes: c.includes,
 		MinSize:              c.minSize,
+		Level:                level,
 	}
 
-	wrapper, err := brotli.NewWrapper(cfg)
+	wrapper, err := NewWrapper(cfg, encoding)
 	if err != nil {
-		return nil, fmt.Errorf("new brotli wrapper: %w", err)
+		return nil, fmt.Errorf("new %s wrapper: %w", encoding, err)
 	}
 
 	return wrapper(c.next), nil
 }
 
-func encodingAccepts(acceptEncoding []string, typ string) bool {
+// acceptedEncodings are the encodings of an Accept-Encoding header, with their quality value.
+type acceptedEncodings map[string]float64
+
+func parseAcceptEncoding(acceptEncoding []string) acceptedEncodings {
+	accepted := make(acceptedEncodings)
 	for _, ae := range acceptEncoding {
 		for _, e := range strings.Split(ae, ",") {
 			parsed := strings.Split(strings.TrimSpace(e), ";")
-			if len(parsed) == 0 {
+			if len(parsed) == 0 || parsed[0] == "" {
 				continue
 			}
-			if parsed[0] == typ || parsed[0] == "*" {
-				return true
+
+			quality := 1.0
+			for _, param := range parsed[1:] {
+				name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+				if !ok || name != "q" {
+					continue
+				}
+
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
 			}
+
+			accepted[strings.ToLower(parsed[0])] = quality
 		}
 	}
 
-	return false
+	return accepted
+}
+
+// accepts reports whether the encoding is accepted, either explicitly or with the * wildcard, with a non-zero quality value.
+func (a acceptedEncodings) accepts(encoding string) bool {
+	if quality, ok := a[encoding]; ok {
+		return quality > 0
+	}
+
+	quality, ok := a["*"]
+	return ok && quality > 0
 }
 
 func contains(values []string, val string) bool {
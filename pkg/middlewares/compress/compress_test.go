@@ -70,6 +70,26 @@ func TestNegotiation(t *testing.T) {
 			acceptEncHeader: "gzip, br",
 			expEncoding:     "br",
 		},
+		{
+			desc:            "zstd accept header",
+			acceptEncHeader: "zstd",
+			expEncoding:     "zstd",
+		},
+		{
+			desc:            "multi accept header list, prefer zstd to gzip",
+			acceptEncHeader: "gzip, zstd",
+			expEncoding:     "zstd",
+		},
+		{
+			desc:            "br not acceptable",
+			acceptEncHeader: "br;q=0, gzip",
+			expEncoding:     "gzip",
+		},
+		{
+			desc:            "br and zstd not acceptable",
+			acceptEncHeader: "br;q=0, zstd;q=0.0, gzip;q=0.5",
+			expEncoding:     "gzip",
+		},
 	}
 
 	for _, test := range testCases {
@@ -97,6 +117,100 @@ func TestNegotiation(t *testing.T) {
 	}
 }
 
+func TestNegotiation_encodings(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		encodings       []string
+		acceptEncHeader string
+		expEncoding     string
+	}{
+		{
+			desc:        "no accept header",
+			encodings:   []string{"zstd", "gzip"},
+			expEncoding: "zstd",
+		},
+		{
+			desc:            "prefer zstd",
+			encodings:       []string{"zstd", "br", "gzip"},
+			acceptEncHeader: "gzip, br, zstd",
+			expEncoding:     "zstd",
+		},
+		{
+			desc:            "encoding not enabled",
+			encodings:       []string{"gzip"},
+			acceptEncHeader: "br, zstd",
+			expEncoding:     "",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
+			if test.acceptEncHeader != "" {
+				req.Header.Add(acceptEncodingHeader, test.acceptEncHeader)
+			}
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				_, _ = rw.Write(generateBytes(10))
+			})
+			handler, err := New(context.Background(), next, dynamic.Compress{MinResponseBodyBytes: 1, Encodings: test.encodings}, "testing")
+			require.NoError(t, err)
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			assert.Equal(t, test.expEncoding, rw.Header().Get(contentEncodingHeader))
+		})
+	}
+}
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc string
+		conf dynamic.Compress
+	}{
+		{
+			desc: "unknown encoding",
+			conf: dynamic.Compress{Encodings: []string{"deflate"}},
+		},
+		{
+			desc: "duplicate encoding",
+			conf: dynamic.Compress{Encodings: []string{"gzip", "gzip"}},
+		},
+		{
+			desc: "included and excluded content types",
+			conf: dynamic.Compress{ExcludedContentTypes: []string{"text/plain"}, IncludedContentTypes: []string{"text/html"}},
+		},
+		{
+			desc: "invalid brotli level",
+			conf: dynamic.Compress{BrotliLevel: 12},
+		},
+		{
+			desc: "invalid zstd level",
+			conf: dynamic.Compress{ZstdLevel: 23},
+		},
+		{
+			desc: "invalid gzip level",
+			conf: dynamic.Compress{GzipLevel: 10},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.conf, "testing")
+			assert.Error(t, err)
+		})
+	}
+}
+
 func TestShouldCompressWhenNoContentEncodingHeader(t *testing.T) {
 	req := testhelpers.MustNewRequest(http.MethodGet, "http://localhost", nil)
 	req.Header.Add(acceptEncodingHeader, gzipValue)
@@ -1,4 +1,4 @@
-package brotli
+package compress
 
 import (
 	"bufio"
@@ -7,8 +7,10 @@ import (
 	"mime"
 	"net"
 	"net/http"
+	"sync"
 
 	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 )
 
 const (
@@ -19,56 +21,141 @@ const (
 	contentType     = "Content-Type"
 )
 
-// Config is the Brotli handler configuration.
+// Config is the configuration of the Brotli and Zstandard handlers.
 type Config struct {
 	// ExcludedContentTypes is the list of content types for which we should not compress.
 	ExcludedContentTypes []string
+	// IncludedContentTypes is the list of content types for which we should compress, all the others when empty.
+	IncludedContentTypes []string
 	// MinSize is the minimum size (in bytes) required to enable compression.
 	MinSize int
+	// Level is the compression level of the encoding, its default one when zero.
+	Level int
 }
 
-// NewWrapper returns a new Brotli compressing wrapper.
-func NewWrapper(cfg Config) (func(http.Handler) http.HandlerFunc, error) {
+// compressor is the writer compressing the response body in an encoding.
+type compressor interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// NewWrapper returns a new compressing wrapper for the encoding, br or zstd.
+func NewWrapper(cfg Config, encoding string) (func(http.Handler) http.HandlerFunc, error) {
 	if cfg.MinSize < 0 {
 		return nil, fmt.Errorf("minimum size must be greater than or equal to zero")
 	}
 
-	var contentTypes []parsedContentType
-	for _, v := range cfg.ExcludedContentTypes {
-		mediaType, params, err := mime.ParseMediaType(v)
-		if err != nil {
-			return nil, fmt.Errorf("parsing media type: %w", err)
-		}
+	excludedContentTypes, err := parseContentTypes(cfg.ExcludedContentTypes)
+	if err != nil {
+		return nil, err
+	}
 
-		contentTypes = append(contentTypes, parsedContentType{mediaType, params})
+	includedContentTypes, err := parseContentTypes(cfg.IncludedContentTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	newCompressor, err := newCompressorFunc(encoding, cfg.Level)
+	if err != nil {
+		return nil, err
 	}
 
 	return func(h http.Handler) http.HandlerFunc {
 		return func(rw http.ResponseWriter, r *http.Request) {
 			rw.Header().Add(vary, acceptEncoding)
 
-			brw := &responseWriter{
+			crw := &responseWriter{
 				rw:                   rw,
-				bw:                   brotli.NewWriter(rw),
+				newCompressor:        newCompressor,
+				encoding:             encoding,
 				minSize:              cfg.MinSize,
 				statusCode:           http.StatusOK,
-				excludedContentTypes: contentTypes,
+				excludedContentTypes: excludedContentTypes,
+				includedContentTypes: includedContentTypes,
 			}
-			defer brw.close()
+			defer crw.close()
 
-			h.ServeHTTP(brw, r)
+			h.ServeHTTP(crw, r)
 		}
 	}, nil
 }
 
+func parseContentTypes(values []string) ([]parsedContentType, error) {
+	var contentTypes []parsedContentType
+	for _, v := range values {
+		mediaType, params, err := mime.ParseMediaType(v)
+		if err != nil {
+			return nil, fmt.Errorf("parsing media type: %w", err)
+		}
+
+		contentTypes = append(contentTypes, parsedContentType{mediaType, params})
+	}
+
+	return contentTypes, nil
+}
+
+// newCompressorFunc returns the function creating the compressors of the encoding, at the level.
+// The compressors are only created once the response is known to be compressed.
+func newCompressorFunc(encoding string, level int) (func(io.Writer) (compressor, func()), error) {
+	switch encoding {
+	case brotliName:
+		if level == 0 {
+			level = brotli.DefaultCompression
+		}
+		if level < brotli.BestSpeed || level > brotli.BestCompression {
+			return nil, fmt.Errorf("invalid brotli compression level %d, it must be between %d and %d", level, brotli.BestSpeed, brotli.BestCompression)
+		}
+
+		return func(w io.Writer) (compressor, func()) {
+			return brotli.NewWriterLevel(w, level), func() {}
+		}, nil
+
+	case zstdName:
+		if level == 0 {
+			level = defaultZstdLevel
+		}
+		if level < 1 || level > 22 {
+			return nil, fmt.Errorf("invalid zstd compression level %d, it must be between 1 and 22", level)
+		}
+
+		// the encoders are reused between the responses, as they allocate their window.
+		pool := &sync.Pool{}
+		encoderLevel := zstd.EncoderLevelFromZstd(level)
+
+		return func(w io.Writer) (compressor, func()) {
+			if encoder, ok := pool.Get().(*zstd.Encoder); ok {
+				encoder.Reset(w)
+				return encoder, func() { pool.Put(encoder) }
+			}
+
+			// the options are valid, NewWriter does not fail.
+			encoder, _ := zstd.NewWriter(w,
+				zstd.WithEncoderLevel(encoderLevel),
+				zstd.WithEncoderConcurrency(1),
+				zstd.WithLowerEncoderMem(true),
+				zstd.WithWindowSize(zstdWindowSize),
+			)
+			return encoder, func() { pool.Put(encoder) }
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+}
+
 // TODO: check whether we want to implement content-type sniffing (as gzip does)
 // TODO: check whether we should support Accept-Ranges (as gzip does, see https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Accept-Ranges)
 type responseWriter struct {
 	rw http.ResponseWriter
-	bw *brotli.Writer
+	// bw is the compressor, created once the compression starts.
+	bw            compressor
+	release       func()
+	newCompressor func(io.Writer) (compressor, func())
+	encoding      string
 
 	minSize              int
 	excludedContentTypes []parsedContentType
+	includedContentTypes []parsedContentType
 
 	buf                 []byte
 	hijacked            bool
@@ -121,19 +208,20 @@ func (r *responseWriter) Write(p []byte) (int, error) {
 		return r.rw.Write(p)
 	}
 
-	// Disable compression according to user wishes in excludedContentTypes.
+	// Disable compression according to user wishes in excludedContentTypes and includedContentTypes.
 	if ct := r.rw.Header().Get(contentType); ct != "" {
 		mediaType, params, err := mime.ParseMediaType(ct)
 		if err != nil {
 			return 0, fmt.Errorf("parsing media type: %w", err)
 		}
 
-		for _, excludedContentType := range r.excludedContentTypes {
-			if excludedContentType.equals(mediaType, params) {
-				r.compressionDisabled = true
-				return r.rw.Write(p)
-			}
+		if !r.compressible(mediaType, params) {
+			r.compressionDisabled = true
+			return r.rw.Write(p)
 		}
+	} else if len(r.includedContentTypes) > 0 {
+		r.compressionDisabled = true
+		return r.rw.Write(p)
 	}
 
 	// We buffer until we know whether to compress (i.e. when we reach minSize received).
@@ -149,10 +237,12 @@ func (r *responseWriter) Write(p []byte) (int, error) {
 	// Since we know we are going to compress we will never be able to know the actual length.
 	r.rw.Header().Del(contentLength)
 
-	r.rw.Header().Set(contentEncoding, "br")
+	r.rw.Header().Set(contentEncoding, r.encoding)
 	r.rw.WriteHeader(r.statusCode)
 	r.headersSent = true
 
+	r.bw, r.release = r.newCompressor(r.rw)
+
 	// Start with sending what we have previously buffered, before actually writing
 	// the bytes in argument.
 	n, err := r.bw.Write(r.buf)
@@ -275,7 +365,7 @@ func (r *responseWriter) close() error {
 
 	if len(r.buf) == 0 {
 		// If we got here we know compression has started, so we can safely flush on bw.
-		return r.bw.Close()
+		return r.closeCompressor()
 	}
 
 	// There is still data in the buffer, because we never reached minSize (to
@@ -295,14 +385,42 @@ func (r *responseWriter) close() error {
 	// We flush it to the compressed writer.
 	n, err := r.bw.Write(r.buf)
 	if err != nil {
-		r.bw.Close()
+		_ = r.closeCompressor()
 		return err
 	}
 	if n < len(r.buf) {
-		r.bw.Close()
+		_ = r.closeCompressor()
 		return io.ErrShortWrite
 	}
-	return r.bw.Close()
+	return r.closeCompressor()
+}
+
+// closeCompressor closes the compressor, and releases it for another response.
+func (r *responseWriter) closeCompressor() error {
+	err := r.bw.Close()
+	r.release()
+	return err
+}
+
+// compressible reports whether the responses with the content type are compressed.
+func (r *responseWriter) compressible(mediaType string, params map[string]string) bool {
+	for _, excludedContentType := range r.excludedContentTypes {
+		if excludedContentType.equals(mediaType, params) {
+			return false
+		}
+	}
+
+	if len(r.includedContentTypes) == 0 {
+		return true
+	}
+
+	for _, includedContentType := range r.includedContentTypes {
+		if includedContentType.equals(mediaType, params) {
+			return true
+		}
+	}
+
+	return false
 }
 
 // parsedContentType is the parsed representation of one of the inputs to ContentTypes.
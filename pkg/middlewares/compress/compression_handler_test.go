@@ -1,4 +1,4 @@
-package brotli
+package compress
 
 import (
 	"bytes"
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -389,6 +390,97 @@ func Test_ExcludedContentTypes(t *testing.T) {
 	}
 }
 
+func Test_IncludedContentTypes(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		contentType          string
+		includedContentTypes []string
+		expCompression       bool
+	}{
+		{
+			desc:                 "MIME match",
+			contentType:          "application/json; charset=utf-8",
+			includedContentTypes: []string{"application/json"},
+			expCompression:       true,
+		},
+		{
+			desc:                 "MIME no match",
+			contentType:          "text/xml",
+			includedContentTypes: []string{"application/json"},
+			expCompression:       false,
+		},
+		{
+			desc:                 "no content type",
+			contentType:          "",
+			includedContentTypes: []string{"application/json"},
+			expCompression:       false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cfg := Config{
+				MinSize:              1024,
+				IncludedContentTypes: test.includedContentTypes,
+			}
+			h := mustNewWrapper(t, cfg)(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if test.contentType != "" {
+					rw.Header().Set(contentType, test.contentType)
+				}
+
+				_, err := rw.Write(bigTestBody)
+				require.NoError(t, err)
+			}))
+
+			req, _ := http.NewRequest(http.MethodGet, "/whatever", nil)
+			req.Header.Set(acceptEncoding, "br")
+
+			rw := httptest.NewRecorder()
+			h.ServeHTTP(rw, req)
+
+			if test.expCompression {
+				assert.Equal(t, "br", rw.Header().Get(contentEncoding))
+			} else {
+				assert.Empty(t, rw.Header().Get(contentEncoding))
+				assert.Equal(t, bigTestBody, rw.Body.Bytes())
+			}
+		})
+	}
+}
+
+func Test_Zstd(t *testing.T) {
+	w, err := NewWrapper(Config{MinSize: 1024, Level: 19}, zstdName)
+	require.NoError(t, err)
+
+	h := w(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, err := rw.Write(bigTestBody)
+		require.NoError(t, err)
+	}))
+
+	// the second response reuses the encoder of the first one.
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, "/whatever", nil)
+		req.Header.Set(acceptEncoding, "zstd")
+
+		rw := httptest.NewRecorder()
+		h.ServeHTTP(rw, req)
+
+		assert.Equal(t, "zstd", rw.Header().Get(contentEncoding))
+		assert.Less(t, rw.Body.Len(), len(bigTestBody))
+
+		decoder, err := zstd.NewReader(rw.Body)
+		require.NoError(t, err)
+
+		got, err := io.ReadAll(decoder)
+		decoder.Close()
+		require.NoError(t, err)
+		assert.Equal(t, bigTestBody, got)
+	}
+}
+
 func Test_FlushExcludedContentTypes(t *testing.T) {
 	testCases := []struct {
 		desc                 string
@@ -512,7 +604,7 @@ func Test_FlushExcludedContentTypes(t *testing.T) {
 func mustNewWrapper(t *testing.T, cfg Config) func(http.Handler) http.HandlerFunc {
 	t.Helper()
 
-	w, err := NewWrapper(cfg)
+	w, err := NewWrapper(cfg, brotliName)
 	require.NoError(t, err)
 
 	return w
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/vulcand/oxy/v2/utils"
@@ -15,21 +17,11 @@ import (
 // It defaults to a RemoteAddrStrategy IPStrategy if need be.
 // It returns an error if more than one source criterion is provided.
 func GetSourceExtractor(ctx context.Context, sourceMatcher *dynamic.SourceCriterion) (utils.SourceExtractor, error) {
-	if sourceMatcher != nil {
-		if sourceMatcher.IPStrategy != nil && sourceMatcher.RequestHeaderName != "" {
-			return nil, errors.New("iPStrategy and RequestHeaderName are mutually exclusive")
-		}
-		if sourceMatcher.IPStrategy != nil && sourceMatcher.RequestHost {
-			return nil, errors.New("iPStrategy and RequestHost are mutually exclusive")
-		}
-		if sourceMatcher.RequestHeaderName != "" && sourceMatcher.RequestHost {
-			return nil, errors.New("requestHost and RequestHeaderName are mutually exclusive")
-		}
+	if criteria := sourceCriteria(sourceMatcher); len(criteria) > 1 {
+		return nil, fmt.Errorf("%s and %s are mutually exclusive", criteria[0], criteria[1])
 	}
 
-	if sourceMatcher == nil ||
-		sourceMatcher.IPStrategy == nil &&
-			sourceMatcher.RequestHeaderName == "" && !sourceMatcher.RequestHost {
+	if !HasSourceCriterion(sourceMatcher) {
 		sourceMatcher = &dynamic.SourceCriterion{
 			IPStrategy: &dynamic.IPStrategy{},
 		}
@@ -58,5 +50,90 @@ func GetSourceExtractor(ctx context.Context, sourceMatcher *dynamic.SourceCriter
 		return utils.NewExtractor("request.host")
 	}
 
+	if sourceMatcher.RequestQueryParameterName != "" {
+		logger.Debug().Msg("Using RequestQueryParameterName")
+		name := sourceMatcher.RequestQueryParameterName
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			return req.URL.Query().Get(name), 1, nil
+		}), nil
+	}
+
+	if sourceMatcher.RequestJWTClaim != "" {
+		logger.Debug().Msg("Using RequestJWTClaim")
+		claim := sourceMatcher.RequestJWTClaim
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			return getJWTClaim(req, claim), 1, nil
+		}), nil
+	}
+
+	if sourceMatcher.RequestClientCertCN {
+		logger.Debug().Msg("Using RequestClientCertCN")
+		return utils.ExtractorFunc(func(req *http.Request) (string, int64, error) {
+			if req.TLS == nil || len(req.TLS.PeerCertificates) == 0 {
+				return "", 1, nil
+			}
+			return req.TLS.PeerCertificates[0].Subject.CommonName, 1, nil
+		}), nil
+	}
+
 	return nil, errors.New("no SourceCriterion criterion defined")
 }
+
+// HasSourceCriterion returns whether at least one criterion of the given sourceMatcher is defined.
+func HasSourceCriterion(sourceMatcher *dynamic.SourceCriterion) bool {
+	return len(sourceCriteria(sourceMatcher)) > 0
+}
+
+// sourceCriteria returns the names of the criteria defined by the given sourceMatcher.
+func sourceCriteria(sourceMatcher *dynamic.SourceCriterion) []string {
+	if sourceMatcher == nil {
+		return nil
+	}
+
+	var criteria []string
+	if sourceMatcher.IPStrategy != nil {
+		criteria = append(criteria, "iPStrategy")
+	}
+	if sourceMatcher.RequestHeaderName != "" {
+		criteria = append(criteria, "RequestHeaderName")
+	}
+	if sourceMatcher.RequestHost {
+		criteria = append(criteria, "RequestHost")
+	}
+	if sourceMatcher.RequestQueryParameterName != "" {
+		criteria = append(criteria, "RequestQueryParameterName")
+	}
+	if sourceMatcher.RequestJWTClaim != "" {
+		criteria = append(criteria, "RequestJWTClaim")
+	}
+	if sourceMatcher.RequestClientCertCN {
+		criteria = append(criteria, "RequestClientCertCN")
+	}
+
+	return criteria
+}
+
+// getJWTClaim returns the value of the claim of the bearer token of the Authorization header,
+// or an empty string if the request has no such token or claim.
+// The token signature is not verified.
+func getJWTClaim(req *http.Request, claim string) string {
+	token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return ""
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(strings.TrimSpace(token), claims); err != nil {
+		return ""
+	}
+
+	value, ok := claims[claim]
+	if !ok || value == nil {
+		return ""
+	}
+
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestGetSourceExtractor(t *testing.T) {
+	// {"alg":"none"}.{"sub":"foo","tenant":42}.
+	const token = "eyJhbGciOiJub25lIn0.eyJzdWIiOiJmb28iLCJ0ZW5hbnQiOjQyfQ."
+
+	testCases := []struct {
+		desc           string
+		sourceMatcher  *dynamic.SourceCriterion
+		request        func(req *http.Request)
+		expectedSource string
+		expectedError  string
+	}{
+		{
+			desc:           "default to the remote address",
+			expectedSource: "192.0.2.1",
+		},
+		{
+			desc:           "request header",
+			sourceMatcher:  &dynamic.SourceCriterion{RequestHeaderName: "X-Foo"},
+			request:        func(req *http.Request) { req.Header.Set("X-Foo", "bar") },
+			expectedSource: "bar",
+		},
+		{
+			desc:           "request query parameter",
+			sourceMatcher:  &dynamic.SourceCriterion{RequestQueryParameterName: "key"},
+			request:        func(req *http.Request) { req.URL.RawQuery = "key=bar&other=baz" },
+			expectedSource: "bar",
+		},
+		{
+			desc:           "request JWT claim",
+			sourceMatcher:  &dynamic.SourceCriterion{RequestJWTClaim: "sub"},
+			request:        func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) },
+			expectedSource: "foo",
+		},
+		{
+			desc:           "request JWT number claim",
+			sourceMatcher:  &dynamic.SourceCriterion{RequestJWTClaim: "tenant"},
+			request:        func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) },
+			expectedSource: "42",
+		},
+		{
+			desc:          "request JWT missing claim",
+			sourceMatcher: &dynamic.SourceCriterion{RequestJWTClaim: "email"},
+			request:       func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) },
+		},
+		{
+			desc:          "request JWT without bearer token",
+			sourceMatcher: &dynamic.SourceCriterion{RequestJWTClaim: "sub"},
+			request:       func(req *http.Request) { req.Header.Set("Authorization", "Basic Zm9vOmJhcg==") },
+		},
+		{
+			desc:          "request JWT invalid token",
+			sourceMatcher: &dynamic.SourceCriterion{RequestJWTClaim: "sub"},
+			request:       func(req *http.Request) { req.Header.Set("Authorization", "Bearer foo") },
+		},
+		{
+			desc:          "request client certificate common name",
+			sourceMatcher: &dynamic.SourceCriterion{RequestClientCertCN: true},
+			request: func(req *http.Request) {
+				req.TLS = &tls.ConnectionState{
+					PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "client.example.com"}}},
+				}
+			},
+			expectedSource: "client.example.com",
+		},
+		{
+			desc:          "request client certificate common name without certificate",
+			sourceMatcher: &dynamic.SourceCriterion{RequestClientCertCN: true},
+		},
+		{
+			desc:          "mutually exclusive criteria",
+			sourceMatcher: &dynamic.SourceCriterion{RequestHost: true, RequestJWTClaim: "sub"},
+			expectedError: "RequestHost and RequestJWTClaim are mutually exclusive",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			extractor, err := GetSourceExtractor(context.Background(), test.sourceMatcher)
+			if test.expectedError != "" {
+				assert.EqualError(t, err, test.expectedError)
+				return
+			}
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.RemoteAddr = "192.0.2.1:1234"
+			if test.request != nil {
+				test.request(req)
+			}
+
+			source, amount, err := extractor.Extract(req)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedSource, source)
+			assert.Equal(t, int64(1), amount)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/rs/zerolog/log"
+)
+
+// reloadInterval is the minimum duration between two checks of the changes of a database file.
+var reloadInterval = 10 * time.Second
+
+// databases are the opened databases, indexed by file path,
+// shared by the middlewares to not reopen them each time the middlewares are built.
+var (
+	databasesMu sync.Mutex
+	databases   = make(map[string]*database)
+)
+
+// record is the part of the database records used by the middleware,
+// the country being in the country databases, and the autonomous system in the ASN databases.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	ASN          uint   `maxminddb:"autonomous_system_number"`
+	Organization string `maxminddb:"autonomous_system_organization"`
+}
+
+// database is a MaxMind database, reloaded when its file changes.
+type database struct {
+	path string
+
+	reader  atomic.Pointer[maxminddb.Reader]
+	modTime time.Time
+	// checked is the time of the last check of the changes of the file, in Unix nanoseconds.
+	checked atomic.Int64
+}
+
+func openDatabase(path string) (*database, error) {
+	databasesMu.Lock()
+	defer databasesMu.Unlock()
+
+	if db, ok := databases[path]; ok {
+		return db, nil
+	}
+
+	db := &database{path: path}
+	if err := db.reload(); err != nil {
+		return nil, err
+	}
+	db.checked.Store(time.Now().UnixNano())
+
+	databases[path] = db
+
+	return db, nil
+}
+
+// reload reads the database file when it changed since its last read.
+// The database is read in memory, so that the file can be overwritten.
+func (d *database) reload() error {
+	info, err := os.Stat(d.path)
+	if err != nil {
+		return fmt.Errorf("reading database file: %w", err)
+	}
+
+	if d.reader.Load() != nil && info.ModTime().Equal(d.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(d.path)
+	if err != nil {
+		return fmt.Errorf("reading database file: %w", err)
+	}
+
+	reader, err := maxminddb.FromBytes(data)
+	if err != nil {
+		return fmt.Errorf("opening database %s: %w", d.path, err)
+	}
+
+	d.reader.Store(reader)
+	d.modTime = info.ModTime()
+
+	return nil
+}
+
+// checkReload reloads the database when its file changed, at most once per reload interval.
+// The previous database is kept when the file cannot be read, e.g. while it is being written.
+func (d *database) checkReload(ctx context.Context) {
+	now := time.Now().UnixNano()
+
+	last := d.checked.Load()
+	if now-last < int64(reloadInterval) || !d.checked.CompareAndSwap(last, now) {
+		return
+	}
+
+	if err := d.reload(); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Error while reloading the GeoIP database, keeping the previous one")
+	}
+}
+
+// lookup returns the record of the IP, empty when the IP is unknown.
+func (d *database) lookup(ctx context.Context, ip net.IP) (record, error) {
+	d.checkReload(ctx)
+
+	var r record
+	if ip == nil {
+		return r, nil
+	}
+
+	err := d.reader.Load().Lookup(ip, &r)
+	return r, err
+}
@@ -0,0 +1,191 @@
+package geoip
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/ip"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "GeoIP"
+)
+
+// geoIP is a middleware that accepts / refuses requests, and adds headers with their country and autonomous system,
+// based on the country and the autonomous system of the client IP.
+type geoIP struct {
+	next     http.Handler
+	name     string
+	db       *database
+	asnDB    *database
+	strategy ip.Strategy
+
+	allowed               map[string]struct{}
+	blocked               map[string]struct{}
+	allowUnknown          bool
+	countryHeader         string
+	asnHeader             string
+	asnOrganizationHeader string
+}
+
+// New builds a new GeoIP middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.GeoIP, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.DatabaseFile == "" && config.ASNDatabaseFile == "" {
+		return nil, errors.New("databaseFile is empty, GeoIP not created")
+	}
+
+	if config.DatabaseFile == "" && (len(config.AllowedCountries) > 0 || len(config.BlockedCountries) > 0 || config.CountryHeader != "") {
+		return nil, errors.New("databaseFile is empty, the countries cannot be looked up")
+	}
+
+	if config.ASNDatabaseFile == "" && (config.ASNHeader != "" || config.ASNOrganizationHeader != "") {
+		return nil, errors.New("asnDatabaseFile is empty, the autonomous systems cannot be looked up")
+	}
+
+	var db, asnDB *database
+	if config.DatabaseFile != "" {
+		var err error
+		db, err = openDatabase(config.DatabaseFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.ASNDatabaseFile != "" {
+		var err error
+		asnDB, err = openDatabase(config.ASNDatabaseFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	strategy, err := config.IPStrategy.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug().Msgf("Setting up GeoIP with allowed countries: %s, blocked countries: %s", config.AllowedCountries, config.BlockedCountries)
+
+	return &geoIP{
+		next:                  next,
+		name:                  name,
+		db:                    db,
+		asnDB:                 asnDB,
+		strategy:              strategy,
+		allowed:               countrySet(config.AllowedCountries),
+		blocked:               countrySet(config.BlockedCountries),
+		allowUnknown:          config.AllowUnknown,
+		countryHeader:         config.CountryHeader,
+		asnHeader:             config.ASNHeader,
+		asnOrganizationHeader: config.ASNOrganizationHeader,
+	}, nil
+}
+
+func (g *geoIP) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return g.name, tracing.SpanKindNoneEnum
+}
+
+func (g *geoIP) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), g.name, typeName)
+	ctx := logger.WithContext(req.Context())
+
+	clientIP := g.strategy.GetIP(req)
+	parsedIP := net.ParseIP(clientIP)
+
+	var country string
+	if g.db != nil {
+		r, err := g.db.lookup(ctx, parsedIP)
+		if err != nil {
+			logger.Debug().Err(err).Msgf("Unable to look up the country of IP %s", clientIP)
+		}
+		country = r.Country.ISOCode
+	}
+
+	if !g.accepts(country) {
+		msg := fmt.Sprintf("Rejecting IP %s from country %q", clientIP, country)
+		logger.Debug().Msg(msg)
+		tracing.SetErrorWithEvent(req, msg)
+		reject(ctx, rw)
+		return
+	}
+
+	setHeader(req, g.countryHeader, country)
+
+	if g.asnDB != nil {
+		r, err := g.asnDB.lookup(ctx, parsedIP)
+		if err != nil {
+			logger.Debug().Err(err).Msgf("Unable to look up the autonomous system of IP %s", clientIP)
+		}
+
+		var asn string
+		if r.ASN != 0 {
+			asn = strconv.FormatUint(uint64(r.ASN), 10)
+		}
+
+		setHeader(req, g.asnHeader, asn)
+		setHeader(req, g.asnOrganizationHeader, r.Organization)
+	}
+
+	g.next.ServeHTTP(rw, req)
+}
+
+// setHeader sets the header, when its name is not empty.
+// The header sent by the client is not forwarded, even when the value is unknown.
+func setHeader(req *http.Request, name, value string) {
+	if name == "" {
+		return
+	}
+
+	req.Header.Del(name)
+	if value != "" {
+		req.Header.Set(name, value)
+	}
+}
+
+func (g *geoIP) accepts(country string) bool {
+	if country == "" {
+		return len(g.allowed) == 0 || g.allowUnknown
+	}
+
+	if _, ok := g.blocked[country]; ok {
+		return false
+	}
+
+	if len(g.allowed) == 0 {
+		return true
+	}
+
+	_, ok := g.allowed[country]
+	return ok
+}
+
+func countrySet(countries []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(countries))
+	for _, country := range countries {
+		set[strings.ToUpper(strings.TrimSpace(country))] = struct{}{}
+	}
+	return set
+}
+
+func reject(ctx context.Context, rw http.ResponseWriter) {
+	statusCode := http.StatusForbidden
+
+	rw.WriteHeader(statusCode)
+	_, err := rw.Write([]byte(http.StatusText(statusCode)))
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Send()
+	}
+}
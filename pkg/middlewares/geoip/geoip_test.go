@@ -0,0 +1,307 @@
+package geoip
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// The test database maps 1.2.3.0/24 to FR, 2.3.4.0/24 to US, and 5.6.0.0/16 to DE.
+const testDatabase = "./fixtures/GeoLite2-Country-Test.mmdb"
+
+// The test ASN database maps 1.2.3.0/24 to AS64500 "Example France", and 2.3.4.0/24 to AS64501 "Example US".
+const testASNDatabase = "./fixtures/GeoLite2-ASN-Test.mmdb"
+
+func TestNewGeoIP(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        dynamic.GeoIP
+		expectedError string
+	}{
+		{
+			desc:          "missing database file",
+			config:        dynamic.GeoIP{AllowedCountries: []string{"FR"}},
+			expectedError: "databaseFile is empty",
+		},
+		{
+			desc:          "invalid database file",
+			config:        dynamic.GeoIP{DatabaseFile: "./geoip.go"},
+			expectedError: "opening database",
+		},
+		{
+			desc:          "not existing database file",
+			config:        dynamic.GeoIP{DatabaseFile: "./fixtures/missing.mmdb"},
+			expectedError: "reading database file",
+		},
+		{
+			desc:          "countries without database file",
+			config:        dynamic.GeoIP{ASNDatabaseFile: testASNDatabase, CountryHeader: "X-Country-Code"},
+			expectedError: "countries cannot be looked up",
+		},
+		{
+			desc:          "ASN header without ASN database file",
+			config:        dynamic.GeoIP{DatabaseFile: testDatabase, ASNHeader: "X-ASN"},
+			expectedError: "autonomous systems cannot be looked up",
+		},
+		{
+			desc:          "invalid ASN database file",
+			config:        dynamic.GeoIP{ASNDatabaseFile: "./geoip.go"},
+			expectedError: "opening database",
+		},
+		{
+			desc:   "valid database file",
+			config: dynamic.GeoIP{DatabaseFile: testDatabase},
+		},
+		{
+			desc:   "valid ASN database file",
+			config: dynamic.GeoIP{ASNDatabaseFile: testASNDatabase, ASNHeader: "X-ASN"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "geoip")
+			if test.expectedError != "" {
+				require.ErrorContains(t, err, test.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGeoIP_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          dynamic.GeoIP
+		remoteAddr      string
+		xForwardedFor   string
+		expected        int
+		expectedCountry string
+	}{
+		{
+			desc:            "allowed country",
+			config:          dynamic.GeoIP{AllowedCountries: []string{"FR", "de"}},
+			remoteAddr:      "1.2.3.4:123",
+			expected:        http.StatusOK,
+			expectedCountry: "FR",
+		},
+		{
+			desc:       "not allowed country",
+			config:     dynamic.GeoIP{AllowedCountries: []string{"FR", "de"}},
+			remoteAddr: "2.3.4.5:123",
+			expected:   http.StatusForbidden,
+		},
+		{
+			desc:       "blocked country",
+			config:     dynamic.GeoIP{BlockedCountries: []string{"US"}},
+			remoteAddr: "2.3.4.5:123",
+			expected:   http.StatusForbidden,
+		},
+		{
+			desc:            "not blocked country",
+			config:          dynamic.GeoIP{BlockedCountries: []string{"US"}},
+			remoteAddr:      "5.6.7.8:123",
+			expected:        http.StatusOK,
+			expectedCountry: "DE",
+		},
+		{
+			desc:       "blocked country takes precedence over the allowed one",
+			config:     dynamic.GeoIP{AllowedCountries: []string{"US"}, BlockedCountries: []string{"US"}},
+			remoteAddr: "2.3.4.5:123",
+			expected:   http.StatusForbidden,
+		},
+		{
+			desc:       "unknown country with allowed countries",
+			config:     dynamic.GeoIP{AllowedCountries: []string{"FR"}},
+			remoteAddr: "10.0.0.1:123",
+			expected:   http.StatusForbidden,
+		},
+		{
+			desc:       "allowed unknown country",
+			config:     dynamic.GeoIP{AllowedCountries: []string{"FR"}, AllowUnknown: true},
+			remoteAddr: "10.0.0.1:123",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:       "unknown country with blocked countries",
+			config:     dynamic.GeoIP{BlockedCountries: []string{"FR"}},
+			remoteAddr: "10.0.0.1:123",
+			expected:   http.StatusOK,
+		},
+		{
+			desc:            "country of the forwarded IP",
+			config:          dynamic.GeoIP{AllowedCountries: []string{"FR"}, IPStrategy: &dynamic.IPStrategy{Depth: 1}},
+			remoteAddr:      "10.0.0.1:123",
+			xForwardedFor:   "2.3.4.5, 1.2.3.4",
+			expected:        http.StatusOK,
+			expectedCountry: "FR",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.config.DatabaseFile = testDatabase
+			test.config.CountryHeader = "X-Country-Code"
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, test.expectedCountry, req.Header.Get("X-Country-Code"))
+			})
+
+			handler, err := New(context.Background(), next, test.config, "geoip")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.xForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", test.xForwardedFor)
+			}
+			// the header sent by the client is not forwarded.
+			req.Header.Set("X-Country-Code", "XX")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
+
+func TestGeoIP_ServeHTTP_asn(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		config               dynamic.GeoIP
+		remoteAddr           string
+		expected             int
+		expectedCountry      string
+		expectedASN          string
+		expectedOrganization string
+	}{
+		{
+			desc:                 "known autonomous system",
+			config:               dynamic.GeoIP{ASNDatabaseFile: testASNDatabase},
+			remoteAddr:           "1.2.3.4:123",
+			expected:             http.StatusOK,
+			expectedASN:          "64500",
+			expectedOrganization: "Example France",
+		},
+		{
+			desc:       "unknown autonomous system",
+			config:     dynamic.GeoIP{ASNDatabaseFile: testASNDatabase},
+			remoteAddr: "5.6.7.8:123",
+			expected:   http.StatusOK,
+		},
+		{
+			desc: "country and autonomous system",
+			config: dynamic.GeoIP{
+				DatabaseFile:     testDatabase,
+				ASNDatabaseFile:  testASNDatabase,
+				AllowedCountries: []string{"US"},
+				CountryHeader:    "X-Country-Code",
+			},
+			remoteAddr:           "2.3.4.5:123",
+			expected:             http.StatusOK,
+			expectedCountry:      "US",
+			expectedASN:          "64501",
+			expectedOrganization: "Example US",
+		},
+		{
+			desc: "refused country",
+			config: dynamic.GeoIP{
+				DatabaseFile:     testDatabase,
+				ASNDatabaseFile:  testASNDatabase,
+				BlockedCountries: []string{"FR"},
+			},
+			remoteAddr: "1.2.3.4:123",
+			expected:   http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			test.config.ASNHeader = "X-ASN"
+			test.config.ASNOrganizationHeader = "X-ASN-Organization"
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, test.expectedCountry, req.Header.Get("X-Country-Code"))
+				assert.Equal(t, test.expectedASN, req.Header.Get("X-ASN"))
+				assert.Equal(t, test.expectedOrganization, req.Header.Get("X-ASN-Organization"))
+			})
+
+			handler, err := New(context.Background(), next, test.config, "geoip")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.RemoteAddr = test.remoteAddr
+			// the headers sent by the client are not forwarded.
+			req.Header.Set("X-ASN", "1")
+			req.Header.Set("X-ASN-Organization", "spoofed")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expected, recorder.Code)
+		})
+	}
+}
+
+func TestGeoIP_reload(t *testing.T) {
+	reloadInterval = 0
+	t.Cleanup(func() { reloadInterval = 10 * time.Second })
+
+	data, err := os.ReadFile(testDatabase)
+	require.NoError(t, err)
+	require.Equal(t, 1, bytes.Count(data, []byte("FR")))
+
+	databaseFile := filepath.Join(t.TempDir(), "GeoLite2-Country.mmdb")
+	require.NoError(t, os.WriteFile(databaseFile, data, 0o600))
+
+	config := dynamic.GeoIP{DatabaseFile: databaseFile, AllowedCountries: []string{"FR"}}
+	handler, err := New(context.Background(), http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), config, "geoip")
+	require.NoError(t, err)
+
+	assertCode := func(expected int) {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+		req.RemoteAddr = "1.2.3.4:123"
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+
+		assert.Equal(t, expected, recorder.Code)
+	}
+
+	assertCode(http.StatusOK)
+
+	// an invalid database is ignored.
+	require.NoError(t, os.WriteFile(databaseFile, []byte("invalid"), 0o600))
+	require.NoError(t, os.Chtimes(databaseFile, time.Now(), time.Now().Add(time.Second)))
+
+	assertCode(http.StatusOK)
+
+	// 1.2.3.0/24 is then located in Italy.
+	require.NoError(t, os.WriteFile(databaseFile, bytes.Replace(data, []byte("FR"), []byte("IT"), 1), 0o600))
+	require.NoError(t, os.Chtimes(databaseFile, time.Now(), time.Now().Add(2*time.Second)))
+
+	assertCode(http.StatusForbidden)
+}
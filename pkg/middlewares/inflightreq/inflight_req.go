@@ -31,9 +31,7 @@ func New(ctx context.Context, next http.Handler, config dynamic.InFlightReq, nam
 
 	ctxLog := logger.WithContext(ctx)
 
-	if config.SourceCriterion == nil ||
-		config.SourceCriterion.IPStrategy == nil &&
-			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost {
+	if !middlewares.HasSourceCriterion(config.SourceCriterion) {
 		config.SourceCriterion = &dynamic.SourceCriterion{
 			RequestHost: true,
 		}
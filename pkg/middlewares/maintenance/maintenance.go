@@ -0,0 +1,214 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "Maintenance"
+)
+
+// defaultBody is the body of the responses when neither a body nor a file is configured.
+const defaultBody = `<!DOCTYPE html>
+<html>
+<head><title>Maintenance</title></head>
+<body><h1>Service under maintenance</h1><p>Please try again later.</p></body>
+</html>
+`
+
+// ErrNotFound is returned when toggling the maintenance mode of a middleware which is not a maintenance middleware.
+var ErrNotFound = errors.New("maintenance middleware not found")
+
+// The maintenance mode set through the API outlives the middlewares,
+// as they are built again on each configuration change, and for each router using them.
+var (
+	stateMu    sync.RWMutex
+	refs       = map[string]int{}  // number of middlewares using each name
+	configured = map[string]bool{} // maintenance mode of the dynamic configuration, indexed by middleware name
+	overrides  = map[string]bool{} // maintenance mode set through the API, indexed by middleware name
+)
+
+// State is the maintenance mode of a middleware.
+type State struct {
+	Enabled bool `json:"enabled"`
+	// Overridden reports whether the maintenance mode is set through the API, rather than by the dynamic configuration.
+	Overridden bool `json:"overridden"`
+}
+
+// Get returns the maintenance mode of the middleware.
+func Get(name string) (State, error) {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+
+	return state(name)
+}
+
+// Set overrides the maintenance mode of the dynamic configuration of the middleware, until reset.
+func Set(name string, enabled bool) (State, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if refs[name] == 0 {
+		return State{}, ErrNotFound
+	}
+
+	overrides[name] = enabled
+
+	return state(name)
+}
+
+// Reset removes the maintenance mode set through the API, the one of the dynamic configuration applying again.
+func Reset(name string) (State, error) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	if refs[name] == 0 {
+		return State{}, ErrNotFound
+	}
+
+	delete(overrides, name)
+
+	return state(name)
+}
+
+// state must be called with the lock held.
+func state(name string) (State, error) {
+	if refs[name] == 0 {
+		return State{}, ErrNotFound
+	}
+
+	if enabled, ok := overrides[name]; ok {
+		return State{Enabled: enabled, Overridden: true}, nil
+	}
+
+	return State{Enabled: configured[name]}, nil
+}
+
+// register counts the middleware until the context, which is canceled when the middleware is replaced by a new configuration, is done.
+func register(ctx context.Context, name string, enabled bool) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	refs[name]++
+	configured[name] = enabled
+
+	if done := ctx.Done(); done != nil {
+		go func() {
+			<-done
+
+			stateMu.Lock()
+			defer stateMu.Unlock()
+
+			refs[name]--
+			if refs[name] <= 0 {
+				delete(refs, name)
+				delete(configured, name)
+			}
+		}()
+	}
+}
+
+// maintenance responds with a static response instead of forwarding the requests, while the maintenance mode is enabled.
+type maintenance struct {
+	next http.Handler
+	name string
+
+	statusCode  int
+	contentType string
+	body        []byte
+	retryAfter  string
+}
+
+// New creates a maintenance middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Maintenance, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	if config.StatusCode < 200 || config.StatusCode > 599 {
+		return nil, fmt.Errorf("invalid status code %d", config.StatusCode)
+	}
+
+	if config.Body != "" && config.File != "" {
+		return nil, errors.New("body and file are mutually exclusive")
+	}
+
+	if config.RetryAfter < 0 {
+		return nil, errors.New("retryAfter must be positive")
+	}
+
+	m := &maintenance{
+		next:        next,
+		name:        name,
+		statusCode:  config.StatusCode,
+		contentType: config.ContentType,
+		body:        []byte(config.Body),
+	}
+
+	if config.File != "" {
+		body, err := os.ReadFile(config.File)
+		if err != nil {
+			return nil, fmt.Errorf("reading maintenance page: %w", err)
+		}
+		m.body = body
+	}
+
+	if len(m.body) == 0 {
+		m.body = []byte(defaultBody)
+	}
+
+	if config.RetryAfter > 0 {
+		m.retryAfter = strconv.Itoa(int(time.Duration(config.RetryAfter).Round(time.Second).Seconds()))
+	}
+
+	register(ctx, name, config.Enabled)
+
+	return m, nil
+}
+
+func (m *maintenance) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return m.name, tracing.SpanKindNoneEnum
+}
+
+func (m *maintenance) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if !m.enabled() {
+		m.next.ServeHTTP(rw, req)
+		return
+	}
+
+	tracing.LogEventf(req, "Responding with the maintenance page")
+
+	rw.Header().Set("Content-Type", m.contentType)
+	rw.Header().Set("Cache-Control", "no-store")
+	if m.retryAfter != "" {
+		rw.Header().Set("Retry-After", m.retryAfter)
+	}
+	rw.Header().Set("Content-Length", strconv.Itoa(len(m.body)))
+
+	rw.WriteHeader(m.statusCode)
+
+	if req.Method != http.MethodHead {
+		_, _ = rw.Write(m.body)
+	}
+}
+
+func (m *maintenance) enabled() bool {
+	stateMu.RLock()
+	defer stateMu.RUnlock()
+
+	if enabled, ok := overrides[m.name]; ok {
+		return enabled
+	}
+
+	return configured[m.name]
+}
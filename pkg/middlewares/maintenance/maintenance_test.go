@@ -0,0 +1,204 @@
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config func(*dynamic.Maintenance)
+	}{
+		{
+			desc:   "invalid status code",
+			config: func(c *dynamic.Maintenance) { c.StatusCode = 42 },
+		},
+		{
+			desc:   "body and file",
+			config: func(c *dynamic.Maintenance) { c.Body = "foo"; c.File = "foo.html" },
+		},
+		{
+			desc:   "missing file",
+			config: func(c *dynamic.Maintenance) { c.File = filepath.Join(t.TempDir(), "missing.html") },
+		},
+		{
+			desc:   "negative retry after",
+			config: func(c *dynamic.Maintenance) { c.RetryAfter = ptypes.Duration(-time.Second) },
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.Maintenance{}
+			config.SetDefaults()
+			test.config(&config)
+
+			_, err := New(context.Background(), http.NotFoundHandler(), config, "invalid@file")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	page := filepath.Join(t.TempDir(), "maintenance.json")
+	require.NoError(t, os.WriteFile(page, []byte(`{"message":"maintenance"}`), 0o600))
+
+	testCases := []struct {
+		desc                string
+		config              func(*dynamic.Maintenance)
+		method              string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+		expectedRetryAfter  string
+	}{
+		{
+			desc:           "disabled",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "service",
+		},
+		{
+			desc:                "default page",
+			config:              func(c *dynamic.Maintenance) { c.Enabled = true },
+			expectedStatus:      http.StatusServiceUnavailable,
+			expectedContentType: "text/html; charset=utf-8",
+			expectedBody:        defaultBody,
+		},
+		{
+			desc: "configured body",
+			config: func(c *dynamic.Maintenance) {
+				c.Enabled = true
+				c.StatusCode = http.StatusOK
+				c.ContentType = "text/plain"
+				c.Body = "back soon"
+				c.RetryAfter = ptypes.Duration(90 * time.Second)
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/plain",
+			expectedBody:        "back soon",
+			expectedRetryAfter:  "90",
+		},
+		{
+			desc: "file",
+			config: func(c *dynamic.Maintenance) {
+				c.Enabled = true
+				c.ContentType = "application/json"
+				c.File = page
+			},
+			expectedStatus:      http.StatusServiceUnavailable,
+			expectedContentType: "application/json",
+			expectedBody:        `{"message":"maintenance"}`,
+		},
+		{
+			desc:                "HEAD request",
+			config:              func(c *dynamic.Maintenance) { c.Enabled = true },
+			method:              http.MethodHead,
+			expectedStatus:      http.StatusServiceUnavailable,
+			expectedContentType: "text/html; charset=utf-8",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.Maintenance{}
+			config.SetDefaults()
+			if test.config != nil {
+				test.config(&config)
+			}
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, _ = rw.Write([]byte("service"))
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			t.Cleanup(cancel)
+
+			handler, err := New(ctx, next, config, "test-"+test.desc+"@file")
+			require.NoError(t, err)
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, httptest.NewRequest(method, "http://example.com", nil))
+
+			assert.Equal(t, test.expectedStatus, rw.Code)
+			assert.Equal(t, test.expectedBody, rw.Body.String())
+			if test.expectedContentType != "" {
+				assert.Equal(t, test.expectedContentType, rw.Header().Get("Content-Type"))
+				assert.Equal(t, "no-store", rw.Header().Get("Cache-Control"))
+			}
+			assert.Equal(t, test.expectedRetryAfter, rw.Header().Get("Retry-After"))
+		})
+	}
+}
+
+func TestMaintenance_override(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	config := dynamic.Maintenance{}
+	config.SetDefaults()
+
+	_, err := Set("override@file", true)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	handler, err := New(ctx, next, config, "override@file")
+	require.NoError(t, err)
+
+	serve := func() int {
+		rw := httptest.NewRecorder()
+		handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+		return rw.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve())
+
+	state, err := Set("override@file", true)
+	require.NoError(t, err)
+	assert.Equal(t, State{Enabled: true, Overridden: true}, state)
+	assert.Equal(t, http.StatusServiceUnavailable, serve())
+
+	// the override outlives the middlewares, built again on each configuration change.
+	cancel()
+	config.Enabled = false
+	handler, err = New(context.Background(), next, config, "override@file")
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		stateMu.RLock()
+		defer stateMu.RUnlock()
+		return refs["override@file"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve())
+
+	state, err = Reset("override@file")
+	require.NoError(t, err)
+	assert.Equal(t, State{}, state)
+	assert.Equal(t, http.StatusOK, serve())
+
+	state, err = Get("override@file")
+	require.NoError(t, err)
+	assert.Equal(t, State{}, state)
+}
@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/alice"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+)
+
+const nameMiddleware = "metrics-middleware"
+
+type downstreamKey struct{}
+
+// middlewareMetrics records the time spent by a middleware of a router processing the request,
+// that is the time spent in the middleware handler minus the time spent in the next handlers.
+type middlewareMetrics struct {
+	handler              http.Handler
+	reqDurationHistogram metrics.ScalableHistogram
+	labels               []string
+}
+
+// downstreamTimer measures the time spent in the next handlers of the middleware,
+// which may be called several times (e.g. retry), or not at all (e.g. forwardAuth denying the request).
+type downstreamTimer struct {
+	next http.Handler
+}
+
+// WrapMiddlewareHandler wraps the chain of a middleware of a router to alice.Constructor,
+// recording the request duration of the middleware itself.
+func WrapMiddlewareHandler(ctx context.Context, registry metrics.Registry, routerName, middlewareName string, chain *alice.Chain) alice.Constructor {
+	return func(next http.Handler) (http.Handler, error) {
+		middlewares.GetLogger(ctx, nameMiddleware, typeName).Debug().Msg("Creating middleware")
+
+		handler, err := chain.Then(&downstreamTimer{next: next})
+		if err != nil {
+			return nil, err
+		}
+
+		return &middlewareMetrics{
+			handler:              handler,
+			reqDurationHistogram: registry.MiddlewareReqDurationHistogram(),
+			labels:               []string{"middleware", middlewareName, "router", routerName, "provider", getProviderName(routerName)},
+		}, nil
+	}
+}
+
+func (m *middlewareMetrics) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	var downstream time.Duration
+
+	start := time.Now()
+	m.handler.ServeHTTP(rw, req.WithContext(context.WithValue(req.Context(), downstreamKey{}, &downstream)))
+
+	// the start is moved forward by the time spent in the next handlers, so that only the time of the middleware remains.
+	m.reqDurationHistogram.With(m.labels...).ObserveFromStart(start.Add(downstream))
+}
+
+func (t *downstreamTimer) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	downstream, ok := req.Context().Value(downstreamKey{}).(*time.Duration)
+	if !ok {
+		t.next.ServeHTTP(rw, req)
+		return
+	}
+
+	start := time.Now()
+	t.next.ServeHTTP(rw, req)
+	*downstream += time.Since(start)
+}
+
+// getProviderName returns the name of the provider of the qualified element name.
+func getProviderName(qualifiedName string) string {
+	if i := strings.LastIndex(qualifiedName, "@"); i >= 0 {
+		return qualifiedName[i+1:]
+	}
+	return ""
+}
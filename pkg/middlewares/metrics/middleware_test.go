@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/alice"
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+)
+
+// collectingHistogram is a metrics.Histogram implementation that enables access to the observed values and LastLabelValues.
+type collectingHistogram struct {
+	Values          []float64
+	LastLabelValues []string
+}
+
+// With is there to satisfy the metrics.Histogram interface.
+func (h *collectingHistogram) With(labelValues ...string) gokitmetrics.Histogram {
+	h.LastLabelValues = labelValues
+	return h
+}
+
+// Observe is there to satisfy the metrics.Histogram interface.
+func (h *collectingHistogram) Observe(value float64) {
+	h.Values = append(h.Values, value)
+}
+
+type middlewareRegistry struct {
+	metrics.Registry
+	histogram metrics.ScalableHistogram
+}
+
+func (r *middlewareRegistry) MiddlewareReqDurationHistogram() metrics.ScalableHistogram {
+	return r.histogram
+}
+
+func TestWrapMiddlewareHandler(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		nextCalls    int
+		wantStatus   int
+		wantDuration time.Duration
+	}{
+		{
+			desc:         "next handler not called",
+			wantStatus:   http.StatusUnauthorized,
+			wantDuration: 20 * time.Millisecond,
+		},
+		{
+			desc:         "next handler called once",
+			nextCalls:    1,
+			wantStatus:   http.StatusOK,
+			wantDuration: 20 * time.Millisecond,
+		},
+		{
+			desc:         "next handler called twice",
+			nextCalls:    2,
+			wantStatus:   http.StatusOK,
+			wantDuration: 20 * time.Millisecond,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			histogram := &collectingHistogram{}
+			scalableHistogram, err := metrics.NewHistogramWithScale(histogram, time.Millisecond)
+			require.NoError(t, err)
+
+			registry := &middlewareRegistry{Registry: metrics.NewVoidRegistry(), histogram: scalableHistogram}
+
+			chain := alice.New(func(next http.Handler) (http.Handler, error) {
+				return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+					time.Sleep(20 * time.Millisecond)
+
+					if test.nextCalls == 0 {
+						rw.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+
+					for i := 0; i < test.nextCalls; i++ {
+						next.ServeHTTP(rw, req)
+					}
+				}), nil
+			})
+
+			backend := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				time.Sleep(50 * time.Millisecond)
+			})
+
+			handler, err := WrapMiddlewareHandler(context.Background(), registry, "router@nomad", "auth@file", &chain)(backend)
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "http://foo.bar", nil))
+
+			assert.Equal(t, test.wantStatus, recorder.Code)
+			assert.Equal(t, []string{"middleware", "auth@file", "router", "router@nomad", "provider", "nomad"}, histogram.LastLabelValues)
+
+			require.Len(t, histogram.Values, 1)
+			assert.InDelta(t, float64(test.wantDuration.Milliseconds()), histogram.Values[0], 15)
+		})
+	}
+}
+
+func Test_getProviderName(t *testing.T) {
+	assert.Equal(t, "nomad", getProviderName("router@nomad"))
+	assert.Equal(t, "", getProviderName("router"))
+}
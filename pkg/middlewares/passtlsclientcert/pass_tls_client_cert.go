@@ -21,8 +21,20 @@ import (
 const typeName = "PassClientTLSCert"
 
 const (
-	xForwardedTLSClientCert     = "X-Forwarded-Tls-Client-Cert"
-	xForwardedTLSClientCertInfo = "X-Forwarded-Tls-Client-Cert-Info"
+	xForwardedTLSClientCert          = "X-Forwarded-Tls-Client-Cert"
+	xForwardedTLSClientCertInfo      = "X-Forwarded-Tls-Client-Cert-Info"
+	xForwardedTLSClientCertSpiffeID  = "X-Forwarded-Tls-Client-Cert-Spiffe-Id"
+	xForwardedTLSClientCertDNSSANs   = "X-Forwarded-Tls-Client-Cert-Dns-Sans"
+	xForwardedTLSClientCertEmailSANs = "X-Forwarded-Tls-Client-Cert-Email-Sans"
+	xForwardedTLSClientCertIPSANs    = "X-Forwarded-Tls-Client-Cert-Ip-Sans"
+	xForwardedTLSClientCertURISANs   = "X-Forwarded-Tls-Client-Cert-Uri-Sans"
+)
+
+// The encodings of the headers values.
+const (
+	encodingQuery = "query"
+	encodingPath  = "path"
+	encodingNone  = "none"
 )
 
 const (
@@ -120,23 +132,88 @@ func newTLSClientCertificateInfo(info *dynamic.TLSClientCertificateInfo) *tlsCli
 	}
 }
 
+// allowedCertificates holds the common names and SANs of the allowed client certificates.
+type allowedCertificates struct {
+	commonNames map[string]struct{}
+	sans        map[string]struct{}
+}
+
+func newAllowedCertificates(allowed *dynamic.TLSClientCertificateAllowed) *allowedCertificates {
+	if allowed == nil {
+		return nil
+	}
+
+	a := &allowedCertificates{
+		commonNames: make(map[string]struct{}, len(allowed.CommonNames)),
+		sans:        make(map[string]struct{}, len(allowed.SANs)),
+	}
+	for _, commonName := range allowed.CommonNames {
+		a.commonNames[commonName] = struct{}{}
+	}
+	for _, san := range allowed.SANs {
+		a.sans[san] = struct{}{}
+	}
+
+	return a
+}
+
+// allows reports whether the common name or one of the SANs of the certificate is allowed.
+// All the certificates are allowed when no common name nor SAN is listed.
+func (a *allowedCertificates) allows(cert *x509.Certificate) bool {
+	if len(a.commonNames) == 0 && len(a.sans) == 0 {
+		return true
+	}
+
+	if _, ok := a.commonNames[cert.Subject.CommonName]; ok {
+		return true
+	}
+
+	for _, san := range getSANs(cert) {
+		if _, ok := a.sans[san]; ok {
+			return true
+		}
+	}
+
+	return false
+}
+
 // passTLSClientCert is a middleware that helps setup a few tls info features.
 type passTLSClientCert struct {
-	next http.Handler
-	name string
-	pem  bool                      // pass the sanitized pem to the backend in a specific header
-	info *tlsClientCertificateInfo // pass selected information from the client certificate
+	next       http.Handler
+	name       string
+	pem        bool                      // pass the sanitized pem to the backend in a specific header
+	info       *tlsClientCertificateInfo // pass selected information from the client certificate
+	spiffeID   bool                      // pass the SPIFFE ID of the client certificate
+	sanHeaders bool                      // pass the SANs of the client certificate, in a header by type
+	encode     func(string) string       // encode the values of the headers, but the pem one
+	allowed    *allowedCertificates      // reject the requests without an allowed client certificate
 }
 
 // New constructs a new PassTLSClientCert instance from supplied frontend header struct.
 func New(ctx context.Context, next http.Handler, config dynamic.PassTLSClientCert, name string) (http.Handler, error) {
 	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
 
+	var encode func(string) string
+	switch config.Encoding {
+	case "", encodingQuery:
+		encode = url.QueryEscape
+	case encodingPath:
+		encode = url.PathEscape
+	case encodingNone:
+		encode = func(value string) string { return value }
+	default:
+		return nil, fmt.Errorf("unknown encoding %q, must be one of %s, %s or %s", config.Encoding, encodingQuery, encodingPath, encodingNone)
+	}
+
 	return &passTLSClientCert{
-		next: next,
-		name: name,
-		pem:  config.PEM,
-		info: newTLSClientCertificateInfo(config.Info),
+		next:       next,
+		name:       name,
+		pem:        config.PEM,
+		info:       newTLSClientCertificateInfo(config.Info),
+		spiffeID:   config.SpiffeID,
+		sanHeaders: config.SANHeaders,
+		encode:     encode,
+		allowed:    newAllowedCertificates(config.Allowed),
 	}, nil
 }
 
@@ -148,6 +225,16 @@ func (p *passTLSClientCert) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 	logger := middlewares.GetLogger(req.Context(), p.name, typeName)
 	ctx := logger.WithContext(req.Context())
 
+	hasCert := req.TLS != nil && len(req.TLS.PeerCertificates) > 0
+
+	if p.allowed != nil && (!hasCert || !p.allowed.allows(req.TLS.PeerCertificates[0])) {
+		logger.Debug().Msg("Client certificate missing or not allowed")
+		tracing.SetErrorWithEvent(req, "Client certificate missing or not allowed")
+
+		http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
 	if p.pem {
 		if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
 			req.Header.Set(xForwardedTLSClientCert, getCertificates(ctx, req.TLS.PeerCertificates))
@@ -159,15 +246,78 @@ func (p *passTLSClientCert) ServeHTTP(rw http.ResponseWriter, req *http.Request)
 	if p.info != nil {
 		if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
 			headerContent := p.getCertInfo(ctx, req.TLS.PeerCertificates)
-			req.Header.Set(xForwardedTLSClientCertInfo, url.QueryEscape(headerContent))
+			req.Header.Set(xForwardedTLSClientCertInfo, p.encode(headerContent))
 		} else {
 			logger.Warn().Msg("Tried to extract a certificate on a request without mutual TLS")
 		}
 	}
 
+	if p.spiffeID {
+		// The header must not be forged by the clients.
+		req.Header.Del(xForwardedTLSClientCertSpiffeID)
+
+		if hasCert {
+			if spiffeID := getSpiffeID(req.TLS.PeerCertificates[0]); spiffeID != "" {
+				req.Header.Set(xForwardedTLSClientCertSpiffeID, p.encode(spiffeID))
+			}
+		}
+	}
+
+	if p.sanHeaders {
+		var cert *x509.Certificate
+		if hasCert {
+			cert = req.TLS.PeerCertificates[0]
+		}
+		p.setSANHeaders(req.Header, cert)
+	}
+
 	p.next.ServeHTTP(rw, req)
 }
 
+// setSANHeaders sets a header by type of SANs of the certificate, removing the headers of the missing types.
+// The values are encoded one by one, and separated by commas.
+func (p *passTLSClientCert) setSANHeaders(header http.Header, cert *x509.Certificate) {
+	var dnsNames, emails, ips, uris []string
+	if cert != nil {
+		dnsNames = cert.DNSNames
+		emails = cert.EmailAddresses
+		for _, ip := range cert.IPAddresses {
+			ips = append(ips, ip.String())
+		}
+		for _, uri := range cert.URIs {
+			uris = append(uris, uri.String())
+		}
+	}
+
+	for name, values := range map[string][]string{
+		xForwardedTLSClientCertDNSSANs:   dnsNames,
+		xForwardedTLSClientCertEmailSANs: emails,
+		xForwardedTLSClientCertIPSANs:    ips,
+		xForwardedTLSClientCertURISANs:   uris,
+	} {
+		header.Del(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		encoded := make([]string, 0, len(values))
+		for _, value := range values {
+			encoded = append(encoded, p.encode(value))
+		}
+		header.Set(name, strings.Join(encoded, subFieldSeparator))
+	}
+}
+
+// getSpiffeID returns the SPIFFE ID of the certificate, i.e. its spiffe URI SAN, or an empty string.
+func getSpiffeID(cert *x509.Certificate) string {
+	for _, uri := range cert.URIs {
+		if strings.EqualFold(uri.Scheme, "spiffe") {
+			return uri.String()
+		}
+	}
+	return ""
+}
+
 // getCertInfo Build a string with the wanted client certificates information
 // - the `,` is used to separate certificates
 // - the `;` is used to separate root fields
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/pem"
 	"net"
 	"net/http"
@@ -565,6 +566,180 @@ func TestPassTLSClientCert_certInfo(t *testing.T) {
 	}
 }
 
+func TestPassTLSClientCert_spiffeIDAndSANs(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:        pkix.Name{CommonName: "backend"},
+		DNSNames:       []string{"backend.example.com", "backend.internal"},
+		EmailAddresses: []string{"ops@example.com"},
+		IPAddresses:    []net.IP{net.ParseIP("10.0.0.1")},
+		URIs: []*url.URL{
+			{Scheme: "spiffe", Host: "example.org", Path: "/ns/default/sa/backend"},
+			{Scheme: "https", Host: "example.com", Path: "/a b"},
+		},
+	}
+
+	testCases := []struct {
+		desc            string
+		cert            *x509.Certificate
+		config          dynamic.PassTLSClientCert
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:   "SPIFFE ID",
+			cert:   cert,
+			config: dynamic.PassTLSClientCert{SpiffeID: true},
+			expectedHeaders: map[string]string{
+				xForwardedTLSClientCertSpiffeID: "spiffe%3A%2F%2Fexample.org%2Fns%2Fdefault%2Fsa%2Fbackend",
+			},
+		},
+		{
+			desc:   "SPIFFE ID without encoding",
+			cert:   cert,
+			config: dynamic.PassTLSClientCert{SpiffeID: true, Encoding: "none"},
+			expectedHeaders: map[string]string{
+				xForwardedTLSClientCertSpiffeID: "spiffe://example.org/ns/default/sa/backend",
+			},
+		},
+		{
+			desc:   "no SPIFFE ID",
+			cert:   &x509.Certificate{DNSNames: []string{"backend.example.com"}},
+			config: dynamic.PassTLSClientCert{SpiffeID: true},
+			expectedHeaders: map[string]string{
+				xForwardedTLSClientCertSpiffeID: "",
+			},
+		},
+		{
+			desc:   "SANs headers",
+			cert:   cert,
+			config: dynamic.PassTLSClientCert{SANHeaders: true, Encoding: "path"},
+			expectedHeaders: map[string]string{
+				xForwardedTLSClientCertDNSSANs:   "backend.example.com,backend.internal",
+				xForwardedTLSClientCertEmailSANs: "ops@example.com",
+				xForwardedTLSClientCertIPSANs:    "10.0.0.1",
+				xForwardedTLSClientCertURISANs:   "spiffe:%2F%2Fexample.org%2Fns%2Fdefault%2Fsa%2Fbackend,https:%2F%2Fexample.com%2Fa%2520b",
+			},
+		},
+		{
+			desc:   "SANs headers without TLS",
+			config: dynamic.PassTLSClientCert{SANHeaders: true, SpiffeID: true},
+			expectedHeaders: map[string]string{
+				xForwardedTLSClientCertDNSSANs:  "",
+				xForwardedTLSClientCertURISANs:  "",
+				xForwardedTLSClientCertSpiffeID: "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tlsClientHeaders, err := New(context.Background(), next, test.config, "foo")
+			require.NoError(t, err)
+
+			res := httptest.NewRecorder()
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://example.com/foo", nil)
+			// The headers sent by the clients are replaced.
+			for name := range test.expectedHeaders {
+				req.Header.Set(name, "forged")
+			}
+
+			if test.cert != nil {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{test.cert}}
+			}
+
+			tlsClientHeaders.ServeHTTP(res, req)
+
+			assert.Equal(t, http.StatusOK, res.Code)
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, req.Header.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestPassTLSClientCert_allowed(t *testing.T) {
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "backend"},
+		DNSNames: []string{"backend.example.com"},
+		URIs:     []*url.URL{{Scheme: "spiffe", Host: "example.org", Path: "/ns/default/sa/backend"}},
+	}
+
+	testCases := []struct {
+		desc           string
+		cert           *x509.Certificate
+		allowed        *dynamic.TLSClientCertificateAllowed
+		expectedStatus int
+	}{
+		{
+			desc:           "no allowlist without TLS",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "certificate required without TLS",
+			allowed:        &dynamic.TLSClientCertificateAllowed{},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			desc:           "certificate required",
+			cert:           cert,
+			allowed:        &dynamic.TLSClientCertificateAllowed{},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "allowed common name",
+			cert:           cert,
+			allowed:        &dynamic.TLSClientCertificateAllowed{CommonNames: []string{"frontend", "backend"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "allowed SPIFFE ID",
+			cert:           cert,
+			allowed:        &dynamic.TLSClientCertificateAllowed{SANs: []string{"spiffe://example.org/ns/default/sa/backend"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "allowed DNS name",
+			cert:           cert,
+			allowed:        &dynamic.TLSClientCertificateAllowed{CommonNames: []string{"frontend"}, SANs: []string{"backend.example.com"}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "not allowed",
+			cert:           cert,
+			allowed:        &dynamic.TLSClientCertificateAllowed{CommonNames: []string{"frontend"}, SANs: []string{"frontend.example.com"}},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			tlsClientHeaders, err := New(context.Background(), next, dynamic.PassTLSClientCert{Allowed: test.allowed}, "foo")
+			require.NoError(t, err)
+
+			res := httptest.NewRecorder()
+			req := testhelpers.MustNewRequest(http.MethodGet, "http://example.com/foo", nil)
+
+			if test.cert != nil {
+				req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{test.cert}}
+			}
+
+			tlsClientHeaders.ServeHTTP(res, req)
+
+			assert.Equal(t, test.expectedStatus, res.Code)
+		})
+	}
+}
+
+func TestNew_invalidEncoding(t *testing.T) {
+	_, err := New(context.Background(), next, dynamic.PassTLSClientCert{Encoding: "base64"}, "foo")
+	assert.Error(t, err)
+}
+
 func Test_sanitize(t *testing.T) {
 	testCases := []struct {
 		desc       string
@@ -0,0 +1,231 @@
+package priority
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "Priority"
+)
+
+// defaultClassName is the name of the class of the requests matching no class.
+const defaultClassName = "default"
+
+// latencyWeight is the weight of the latency of a response in the average latency.
+const latencyWeight = 0.1
+
+// latencyExpiry is the duration after which the average latency is forgotten when no response was observed,
+// for the low priority requests not to be shed forever when only they are received.
+const latencyExpiry = time.Second
+
+type class struct {
+	name         string
+	rank         int
+	headers      map[string]*regexp.Regexp
+	pathPrefixes []string
+}
+
+func (c class) match(req *http.Request) bool {
+	for name, re := range c.headers {
+		if !re.MatchString(req.Header.Get(name)) {
+			return false
+		}
+	}
+
+	if len(c.pathPrefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range c.pathPrefixes {
+		if strings.HasPrefix(req.URL.Path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// priority sheds the requests of the low priority classes when the concurrency or latency budget is exceeded.
+type priority struct {
+	next http.Handler
+	name string
+
+	classes      []class
+	defaultClass class
+	// ranks is the number of distinct priorities, including the one of the default class.
+	ranks int
+
+	maxInFlight int64
+	maxLatency  time.Duration
+
+	inFlight atomic.Int64
+
+	latencyMu  sync.Mutex
+	latency    time.Duration // average latency of the responses
+	observedAt time.Time     // time of the last observed response
+}
+
+// New creates a priority middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.Priority, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	if config.MaxInFlight < 0 {
+		return nil, errors.New("maxInFlight must be positive")
+	}
+
+	if config.MaxLatency < 0 {
+		return nil, errors.New("maxLatency must be positive")
+	}
+
+	// the priorities are ranked, for each of them to be allowed its share of the in-flight requests.
+	priorities := []int{0}
+	for _, c := range config.Classes {
+		priorities = append(priorities, c.Priority)
+	}
+	sort.Ints(priorities)
+
+	ranks := make(map[int]int)
+	for _, prio := range priorities {
+		if _, ok := ranks[prio]; !ok {
+			ranks[prio] = len(ranks)
+		}
+	}
+
+	p := &priority{
+		next:         next,
+		name:         name,
+		defaultClass: class{name: defaultClassName, rank: ranks[0]},
+		ranks:        len(ranks),
+		maxInFlight:  config.MaxInFlight,
+		maxLatency:   time.Duration(config.MaxLatency),
+	}
+
+	for i, c := range config.Classes {
+		className := c.Name
+		if className == "" {
+			className = fmt.Sprintf("class-%d", i)
+		}
+
+		headers := make(map[string]*regexp.Regexp, len(c.Headers))
+		for header, value := range c.Headers {
+			re, err := regexp.Compile(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression of the header %s of the class %s: %w", header, className, err)
+			}
+			headers[header] = re
+		}
+
+		p.classes = append(p.classes, class{
+			name:         className,
+			rank:         ranks[c.Priority],
+			headers:      headers,
+			pathPrefixes: c.PathPrefixes,
+		})
+	}
+
+	return p, nil
+}
+
+func (p *priority) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return p.name, tracing.SpanKindNoneEnum
+}
+
+func (p *priority) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	c := p.classify(req)
+
+	inFlight := p.inFlight.Add(1)
+	defer p.inFlight.Add(-1)
+
+	if reason := p.shed(c, inFlight); reason != "" {
+		logger := middlewares.GetLogger(req.Context(), p.name, typeName)
+		logger.Debug().Str("class", c.name).Msgf("Shedding request: %s", reason)
+
+		tracing.SetErrorWithEvent(req, "Shedding request of the class %s: %s", c.name, reason)
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+
+	start := time.Now()
+	p.next.ServeHTTP(rw, req)
+	p.observe(time.Since(start))
+}
+
+func (p *priority) classify(req *http.Request) class {
+	for _, c := range p.classes {
+		if c.match(req) {
+			return c
+		}
+	}
+
+	return p.defaultClass
+}
+
+// shed returns the reason why the request of the class is shed, empty when it is processed.
+func (p *priority) shed(c class, inFlight int64) string {
+	if c.rank == p.ranks-1 {
+		if p.maxInFlight > 0 && inFlight > p.maxInFlight {
+			return "maximum number of in-flight requests reached"
+		}
+		return ""
+	}
+
+	if p.maxLatency > 0 && p.averageLatency() > p.maxLatency {
+		return "latency budget exceeded"
+	}
+
+	// each priority is allowed a share of the in-flight requests growing with its rank.
+	if p.maxInFlight > 0 {
+		limit := p.maxInFlight * int64(c.rank+1) / int64(p.ranks)
+		if limit < 1 {
+			limit = 1
+		}
+
+		if inFlight > limit {
+			return "share of the in-flight requests of the class reached"
+		}
+	}
+
+	return ""
+}
+
+func (p *priority) observe(latency time.Duration) {
+	if p.maxLatency <= 0 {
+		return
+	}
+
+	p.latencyMu.Lock()
+	defer p.latencyMu.Unlock()
+
+	now := time.Now()
+	if p.latency == 0 || now.Sub(p.observedAt) > latencyExpiry {
+		p.latency = latency
+	} else {
+		p.latency = time.Duration(latencyWeight*float64(latency) + (1-latencyWeight)*float64(p.latency))
+	}
+	p.observedAt = now
+}
+
+func (p *priority) averageLatency() time.Duration {
+	p.latencyMu.Lock()
+	defer p.latencyMu.Unlock()
+
+	if time.Since(p.observedAt) > latencyExpiry {
+		return 0
+	}
+
+	return p.latency
+}
@@ -0,0 +1,217 @@
+package priority
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.Priority
+	}{
+		{
+			desc:   "negative max in-flight",
+			config: dynamic.Priority{MaxInFlight: -1},
+		},
+		{
+			desc:   "negative max latency",
+			config: dynamic.Priority{MaxLatency: ptypes.Duration(-time.Second)},
+		},
+		{
+			desc: "invalid header regular expression",
+			config: dynamic.Priority{Classes: []dynamic.PriorityClass{
+				{Name: "gold", Priority: 1, Headers: map[string]string{"X-Tier": "("}},
+			}},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "priority")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPriority_classify(t *testing.T) {
+	config := dynamic.Priority{
+		Classes: []dynamic.PriorityClass{
+			{Name: "checkout", Priority: 10, PathPrefixes: []string{"/checkout", "/cart"}},
+			{Name: "gold", Priority: 5, Headers: map[string]string{"X-Tier": "^gold$"}},
+			{Name: "batch", Priority: -1, Headers: map[string]string{"X-Batch": ".+"}, PathPrefixes: []string{"/api"}},
+		},
+	}
+
+	handler, err := New(context.Background(), http.NotFoundHandler(), config, "priority")
+	require.NoError(t, err)
+
+	p := handler.(*priority)
+	assert.Equal(t, 4, p.ranks)
+
+	testCases := []struct {
+		desc         string
+		path         string
+		headers      map[string]string
+		expectedName string
+		expectedRank int
+	}{
+		{
+			desc:         "path prefix",
+			path:         "/cart/items",
+			expectedName: "checkout",
+			expectedRank: 3,
+		},
+		{
+			desc:         "header",
+			path:         "/",
+			headers:      map[string]string{"X-Tier": "gold"},
+			expectedName: "gold",
+			expectedRank: 2,
+		},
+		{
+			desc:         "first matching class",
+			path:         "/checkout",
+			headers:      map[string]string{"X-Tier": "gold"},
+			expectedName: "checkout",
+			expectedRank: 3,
+		},
+		{
+			desc:         "headers and path prefix",
+			path:         "/api/reports",
+			headers:      map[string]string{"X-Batch": "nightly"},
+			expectedName: "batch",
+			expectedRank: 0,
+		},
+		{
+			desc:         "header without the path prefix",
+			path:         "/reports",
+			headers:      map[string]string{"X-Batch": "nightly"},
+			expectedName: defaultClassName,
+			expectedRank: 1,
+		},
+		{
+			desc:         "no matching class",
+			path:         "/",
+			headers:      map[string]string{"X-Tier": "silver"},
+			expectedName: defaultClassName,
+			expectedRank: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "http://example.com"+test.path, nil)
+		for name, value := range test.headers {
+			req.Header.Set(name, value)
+		}
+
+		c := p.classify(req)
+		assert.Equal(t, test.expectedName, c.name, test.desc)
+		assert.Equal(t, test.expectedRank, c.rank, test.desc)
+	}
+}
+
+func TestPriority_maxInFlight(t *testing.T) {
+	release := make(chan struct{})
+	var started sync.WaitGroup
+
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		started.Done()
+		<-release
+	})
+
+	config := dynamic.Priority{
+		Classes:     []dynamic.PriorityClass{{Name: "critical", Priority: 1, PathPrefixes: []string{"/critical"}}},
+		MaxInFlight: 4,
+	}
+
+	handler, err := New(context.Background(), next, config, "priority")
+	require.NoError(t, err)
+
+	// the default class is allowed half of the in-flight requests.
+	var done sync.WaitGroup
+	started.Add(2)
+	for i := 0; i < 2; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+		}()
+	}
+	started.Wait()
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+
+	// the critical class is allowed all of them.
+	started.Add(2)
+	for i := 0; i < 2; i++ {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com/critical", nil))
+		}()
+	}
+	started.Wait()
+
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com/critical", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+
+	close(release)
+	done.Wait()
+
+	started.Add(1)
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
+
+func TestPriority_maxLatency(t *testing.T) {
+	delay := 50 * time.Millisecond
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(delay)
+	})
+
+	config := dynamic.Priority{
+		Classes:    []dynamic.PriorityClass{{Name: "critical", Priority: 1, PathPrefixes: []string{"/critical"}}},
+		MaxLatency: ptypes.Duration(10 * time.Millisecond),
+	}
+
+	handler, err := New(context.Background(), next, config, "priority")
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	// the latency budget is exceeded, only the critical requests are processed.
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	assert.Equal(t, http.StatusTooManyRequests, rw.Code)
+
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com/critical", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	// the average latency is forgotten when no response is observed.
+	delay = 0
+	time.Sleep(latencyExpiry + 100*time.Millisecond)
+
+	rw = httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com/", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+}
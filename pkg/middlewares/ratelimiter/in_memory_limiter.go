@@ -0,0 +1,67 @@
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mailgun/ttlmap"
+	"golang.org/x/time/rate"
+)
+
+// inMemoryLimiter keeps the token buckets in memory.
+type inMemoryLimiter struct {
+	rate     rate.Limit // reqs/s
+	burst    int64
+	maxDelay time.Duration
+	// each rate limiter for a given source is stored in the buckets ttlmap.
+	// To keep this ttlmap constrained in size,
+	// each ratelimiter is "garbage collected" when it is considered expired.
+	// It is considered expired after it hasn't been used for ttl seconds.
+	ttl int
+
+	buckets *ttlmap.TtlMap // actual buckets, keyed by source.
+}
+
+func newInMemoryLimiter(rate rate.Limit, burst int64, maxDelay time.Duration, ttl int) (*inMemoryLimiter, error) {
+	buckets, err := ttlmap.NewConcurrent(maxSources)
+	if err != nil {
+		return nil, err
+	}
+
+	return &inMemoryLimiter{
+		rate:     rate,
+		burst:    burst,
+		maxDelay: maxDelay,
+		ttl:      ttl,
+		buckets:  buckets,
+	}, nil
+}
+
+func (l *inMemoryLimiter) Allow(_ context.Context, source string) (*time.Duration, error) {
+	var bucket *rate.Limiter
+	if rlSource, exists := l.buckets.Get(source); exists {
+		bucket = rlSource.(*rate.Limiter)
+	} else {
+		bucket = rate.NewLimiter(l.rate, int(l.burst))
+	}
+
+	// We Set even in the case where the source already exists,
+	// because we want to update the expiryTime everytime we get the source,
+	// as the expiryTime is supposed to reflect the activity (or lack thereof) on that source.
+	if err := l.buckets.Set(source, bucket, l.ttl); err != nil {
+		return nil, fmt.Errorf("setting bucket: %w", err)
+	}
+
+	res := bucket.Reserve()
+	if !res.OK() {
+		return nil, nil
+	}
+
+	delay := res.Delay()
+	if delay > l.maxDelay {
+		res.Cancel()
+	}
+
+	return &delay, nil
+}
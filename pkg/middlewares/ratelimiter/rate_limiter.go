@@ -8,7 +8,6 @@ import (
 	"net/http"
 	"time"
 
-	"github.com/mailgun/ttlmap"
 	"github.com/opentracing/opentracing-go/ext"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
@@ -23,6 +22,14 @@ const (
 	maxSources = 65536
 )
 
+// limiter holds the token buckets of the traffic sources.
+type limiter interface {
+	// Allow reserves a token in the bucket of the source, and returns the delay to wait before the token is available.
+	// The token is not reserved when the delay exceeds maxDelay.
+	// A nil delay means that the request is not allowed.
+	Allow(ctx context.Context, source string) (*time.Duration, error)
+}
+
 // rateLimiter implements rate limiting and traffic shaping with a set of token buckets;
 // one for each traffic source. The same parameters are applied to all the buckets.
 type rateLimiter struct {
@@ -31,16 +38,11 @@ type rateLimiter struct {
 	burst int64
 	// maxDelay is the maximum duration we're willing to wait for a bucket reservation to become effective, in nanoseconds.
 	// For now it is somewhat arbitrarily set to 1/(2*rate).
-	maxDelay time.Duration
-	// each rate limiter for a given source is stored in the buckets ttlmap.
-	// To keep this ttlmap constrained in size,
-	// each ratelimiter is "garbage collected" when it is considered expired.
-	// It is considered expired after it hasn't been used for ttl seconds.
-	ttl           int
+	maxDelay      time.Duration
 	sourceMatcher utils.SourceExtractor
 	next          http.Handler
 
-	buckets *ttlmap.TtlMap // actual buckets, keyed by source.
+	limiter limiter
 }
 
 // New returns a rate limiter middleware.
@@ -50,9 +52,7 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 
 	ctxLog := logger.WithContext(ctx)
 
-	if config.SourceCriterion == nil ||
-		config.SourceCriterion.IPStrategy == nil &&
-			config.SourceCriterion.RequestHeaderName == "" && !config.SourceCriterion.RequestHost {
+	if !middlewares.HasSourceCriterion(config.SourceCriterion) {
 		config.SourceCriterion = &dynamic.SourceCriterion{
 			IPStrategy: &dynamic.IPStrategy{},
 		}
@@ -63,11 +63,6 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		return nil, err
 	}
 
-	buckets, err := ttlmap.NewConcurrent(maxSources)
-	if err != nil {
-		return nil, err
-	}
-
 	burst := config.Burst
 	if burst < 1 {
 		burst = 1
@@ -110,6 +105,16 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		ttl += int(1 / rtl)
 	}
 
+	var limiter limiter
+	if config.Redis != nil {
+		limiter, err = newRedisLimiter(ctxLog, config.Redis, name, rate.Limit(rtl), burst, maxDelay, ttl)
+	} else {
+		limiter, err = newInMemoryLimiter(rate.Limit(rtl), burst, maxDelay, ttl)
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	return &rateLimiter{
 		name:          name,
 		rate:          rate.Limit(rtl),
@@ -117,8 +122,7 @@ func New(ctx context.Context, next http.Handler, config dynamic.RateLimit, name
 		maxDelay:      maxDelay,
 		next:          next,
 		sourceMatcher: sourceMatcher,
-		buckets:       buckets,
-		ttl:           ttl,
+		limiter:       limiter,
 	}, nil
 }
 
@@ -141,36 +145,24 @@ func (rl *rateLimiter) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		logger.Info().Msgf("ignoring token bucket amount > 1: %d", amount)
 	}
 
-	var bucket *rate.Limiter
-	if rlSource, exists := rl.buckets.Get(source); exists {
-		bucket = rlSource.(*rate.Limiter)
-	} else {
-		bucket = rate.NewLimiter(rl.rate, int(rl.burst))
-	}
-
-	// We Set even in the case where the source already exists,
-	// because we want to update the expiryTime everytime we get the source,
-	// as the expiryTime is supposed to reflect the activity (or lack thereof) on that source.
-	if err := rl.buckets.Set(source, bucket, rl.ttl); err != nil {
+	delay, err := rl.limiter.Allow(ctx, source)
+	if err != nil {
 		logger.Error().Err(err).Msg("Could not insert/update bucket")
 		http.Error(rw, "could not insert/update bucket", http.StatusInternalServerError)
 		return
 	}
 
-	res := bucket.Reserve()
-	if !res.OK() {
+	if delay == nil {
 		http.Error(rw, "No bursty traffic allowed", http.StatusTooManyRequests)
 		return
 	}
 
-	delay := res.Delay()
-	if delay > rl.maxDelay {
-		res.Cancel()
-		rl.serveDelayError(ctx, rw, delay)
+	if *delay > rl.maxDelay {
+		rl.serveDelayError(ctx, rw, *delay)
 		return
 	}
 
-	time.Sleep(delay)
+	time.Sleep(*delay)
 	rl.next.ServeHTTP(rw, req)
 }
 
@@ -0,0 +1,131 @@
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares/redisclient"
+	"golang.org/x/time/rate"
+)
+
+const redisKeyPrefix = "traefik:ratelimit:"
+
+// allowScript refills the token bucket of KEYS[1] since the last request, and reserves a token,
+// unless the delay before the token is available exceeds the max delay.
+// ARGV holds the rate in tokens/s, the burst, the max delay in microseconds, and the TTL of the bucket in seconds.
+// The time is the one of the Redis server, so that the clocks of the Traefik instances do not have to be synchronized.
+// It returns whether the token is reserved, and the delay in microseconds.
+var allowScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local maxDelay = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+-- the writes following TIME are replicated as effects, which is the default as of Redis 5.
+redis.replicate_commands()
+local time = redis.call("TIME")
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens = tonumber(bucket[1])
+local last = tonumber(bucket[2])
+if tokens == nil or last == nil then
+  tokens = burst
+  last = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - last) * rate / 1000000) - 1
+
+local delay = 0
+if tokens < 0 then
+  delay = math.ceil(-tokens * 1000000 / rate)
+end
+
+if delay > maxDelay then
+  return {0, delay}
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", tostring(now))
+redis.call("EXPIRE", KEYS[1], ttl)
+
+return {1, delay}
+`)
+
+// redisLimiter keeps the token buckets in Redis, to share them between several Traefik instances.
+type redisLimiter struct {
+	client   redis.Scripter
+	key      string
+	rate     rate.Limit // reqs/s
+	burst    int64
+	maxDelay time.Duration
+	ttl      int
+	timeout  time.Duration
+
+	// unavailable is whether the last request to Redis failed, to only log the changes of availability.
+	unavailable atomic.Bool
+}
+
+func newRedisLimiter(ctx context.Context, config *dynamic.Redis, name string, rate rate.Limit, burst int64, maxDelay time.Duration, ttl int) (*redisLimiter, error) {
+	client, err := redisclient.Get(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &redisLimiter{
+		client:   client,
+		key:      redisKeyPrefix + name + ":",
+		rate:     rate,
+		burst:    burst,
+		maxDelay: maxDelay,
+		ttl:      ttl,
+		timeout:  time.Duration(config.Timeout),
+	}, nil
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, source string) (*time.Duration, error) {
+	if l.rate == rate.Inf {
+		var delay time.Duration
+		return &delay, nil
+	}
+
+	if l.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+	}
+
+	args := []interface{}{
+		float64(l.rate),
+		l.burst,
+		l.maxDelay.Microseconds(),
+		l.ttl,
+	}
+
+	result, err := allowScript.Run(ctx, l.client, []string{l.key + source}, args...).Int64Slice()
+	if err == nil && len(result) != 2 {
+		err = fmt.Errorf("unexpected result: %v", result)
+	}
+	if err != nil {
+		// The requests are allowed while Redis is unavailable, rather than refused.
+		if l.unavailable.CompareAndSwap(false, true) {
+			log.Ctx(ctx).Warn().Err(err).Msg("Unable to get the token bucket from Redis, allowing the requests until Redis is available again")
+		} else {
+			log.Ctx(ctx).Debug().Err(err).Msg("Unable to get the token bucket from Redis, allowing the request")
+		}
+
+		var delay time.Duration
+		return &delay, nil
+	}
+
+	if l.unavailable.CompareAndSwap(true, false) {
+		log.Ctx(ctx).Info().Msg("Redis is available again, rate limiting the requests")
+	}
+
+	delay := time.Duration(result[1]) * time.Microsecond
+	return &delay, nil
+}
@@ -0,0 +1,140 @@
+package ratelimiter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+type fakeScripter struct {
+	result []interface{}
+	err    error
+
+	keys []string
+	args []interface{}
+}
+
+func (s *fakeScripter) Eval(_ context.Context, _ string, keys []string, args ...interface{}) *redis.Cmd {
+	s.keys = keys
+	s.args = args
+	return redis.NewCmdResult(s.result, s.err)
+}
+
+func (s *fakeScripter) EvalSha(ctx context.Context, _ string, keys []string, args ...interface{}) *redis.Cmd {
+	return s.Eval(ctx, "", keys, args...)
+}
+
+func (s *fakeScripter) ScriptExists(_ context.Context, _ ...string) *redis.BoolSliceCmd {
+	return redis.NewBoolSliceResult([]bool{true}, nil)
+}
+
+func (s *fakeScripter) ScriptLoad(_ context.Context, _ string) *redis.StringCmd {
+	return redis.NewStringResult("", nil)
+}
+
+func TestRedisLimiter_Allow(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		rate          rate.Limit
+		result        []interface{}
+		err           error
+		expectedDelay time.Duration
+		expectedCall  bool
+	}{
+		{
+			desc:          "token reserved",
+			rate:          10,
+			result:        []interface{}{int64(1), int64(0)},
+			expectedCall:  true,
+			expectedDelay: 0,
+		},
+		{
+			desc:          "token reserved with a delay",
+			rate:          10,
+			result:        []interface{}{int64(1), int64(20000)},
+			expectedCall:  true,
+			expectedDelay: 20 * time.Millisecond,
+		},
+		{
+			desc:          "token not reserved",
+			rate:          10,
+			result:        []interface{}{int64(0), int64(90000)},
+			expectedCall:  true,
+			expectedDelay: 90 * time.Millisecond,
+		},
+		{
+			desc:          "Redis unavailable",
+			rate:          10,
+			err:           errors.New("connection refused"),
+			expectedCall:  true,
+			expectedDelay: 0,
+		},
+		{
+			desc:          "no rate limiting",
+			rate:          rate.Inf,
+			expectedDelay: 0,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			client := &fakeScripter{result: test.result, err: test.err}
+
+			limiter := &redisLimiter{
+				client:   client,
+				key:      redisKeyPrefix + "foo@file:",
+				rate:     test.rate,
+				burst:    5,
+				maxDelay: 50 * time.Millisecond,
+				ttl:      2,
+			}
+
+			delay, err := limiter.Allow(context.Background(), "127.0.0.1")
+			require.NoError(t, err)
+			require.NotNil(t, delay)
+
+			assert.Equal(t, test.expectedDelay, *delay)
+
+			if !test.expectedCall {
+				assert.Nil(t, client.keys)
+				return
+			}
+
+			assert.Equal(t, []string{"traefik:ratelimit:foo@file:127.0.0.1"}, client.keys)
+			assert.Equal(t, []interface{}{float64(10), int64(5), int64(50000), 2}, client.args)
+		})
+	}
+}
+
+func TestRedisLimiter_availability(t *testing.T) {
+	client := &fakeScripter{err: errors.New("connection refused")}
+
+	limiter := &redisLimiter{
+		client:   client,
+		key:      redisKeyPrefix + "foo@file:",
+		rate:     10,
+		burst:    5,
+		maxDelay: 50 * time.Millisecond,
+		ttl:      2,
+	}
+
+	_, err := limiter.Allow(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, limiter.unavailable.Load())
+
+	client.err = nil
+	client.result = []interface{}{int64(1), int64(0)}
+
+	_, err = limiter.Allow(context.Background(), "127.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, limiter.unavailable.Load())
+}
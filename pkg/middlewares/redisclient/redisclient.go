@@ -0,0 +1,137 @@
+// Package redisclient shares the Redis clients of the middlewares having the same Redis configuration.
+package redisclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// closeDelay is the delay before closing a Redis client no middleware uses anymore,
+// for the middlewares of the next configuration to reuse it, and the requests in flight to complete.
+var closeDelay = 30 * time.Second
+
+// clients holds the Redis clients shared by the middlewares having the same Redis configuration,
+// as the middlewares are built again on each configuration change.
+var (
+	clientsMu sync.Mutex
+	clients   = map[string]*sharedClient{}
+)
+
+// sharedClient is a Redis client counting the middlewares using it.
+type sharedClient struct {
+	redis.UniversalClient
+
+	refs       int
+	closeTimer *time.Timer
+}
+
+// Get returns the Redis client shared by the middlewares with the same configuration.
+// The client is released once the context, which is canceled when the middleware is replaced by a new configuration, is done,
+// and closed once no middleware uses it anymore.
+func Get(ctx context.Context, config *dynamic.Redis) (redis.UniversalClient, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, errors.New("no Redis endpoints defined")
+	}
+
+	key, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling Redis configuration: %w", err)
+	}
+
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	client, ok := clients[string(key)]
+	if !ok {
+		client, err = newClient(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		clients[string(key)] = client
+	}
+
+	client.refs++
+	if client.closeTimer != nil {
+		client.closeTimer.Stop()
+		client.closeTimer = nil
+	}
+
+	if done := ctx.Done(); done != nil {
+		go func() {
+			<-done
+			release(string(key), client)
+		}()
+	}
+
+	return client, nil
+}
+
+func newClient(ctx context.Context, config *dynamic.Redis) (*sharedClient, error) {
+	options := &redis.UniversalOptions{
+		Addrs:    config.Endpoints,
+		Username: config.Username,
+		Password: config.Password,
+		DB:       config.DB,
+	}
+
+	if config.TLS != nil {
+		var err error
+		options.TLSConfig, err = config.TLS.CreateTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("creating Redis client TLS configuration: %w", err)
+		}
+	}
+
+	return &sharedClient{UniversalClient: redis.NewUniversalClient(options)}, nil
+}
+
+// release releases a reference to the client, and closes it after the close delay when no middleware uses it anymore.
+func release(key string, client *sharedClient) {
+	clientsMu.Lock()
+	defer clientsMu.Unlock()
+
+	client.refs--
+	if client.refs > 0 {
+		return
+	}
+
+	client.closeTimer = time.AfterFunc(closeDelay, func() {
+		clientsMu.Lock()
+		defer clientsMu.Unlock()
+
+		// the client is used again by the middlewares of a new configuration.
+		if client.refs > 0 || clients[key] != client {
+			return
+		}
+
+		delete(clients, key)
+
+		if err := client.Close(); err != nil {
+			log.Debug().Err(err).Msg("Unable to close the Redis client")
+		}
+	})
+}
+
+// ForEachNode calls fn for each master node of a Redis cluster, or once for a Redis server,
+// e.g. to scan the keys of all the nodes.
+func ForEachNode(ctx context.Context, client redis.UniversalClient, fn func(ctx context.Context, node redis.Cmdable) error) error {
+	if shared, ok := client.(*sharedClient); ok {
+		client = shared.UniversalClient
+	}
+
+	if cluster, ok := client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return fn(ctx, node)
+		})
+	}
+
+	return fn(ctx, client)
+}
@@ -0,0 +1,50 @@
+package redisclient
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestGet_release(t *testing.T) {
+	delay := closeDelay
+	closeDelay = 10 * time.Millisecond
+	t.Cleanup(func() { closeDelay = delay })
+
+	config := &dynamic.Redis{Endpoints: []string{"127.0.0.1:6379"}, DB: 42}
+
+	key, err := json.Marshal(config)
+	require.NoError(t, err)
+
+	isCached := func() bool {
+		clientsMu.Lock()
+		defer clientsMu.Unlock()
+
+		_, ok := clients[string(key)]
+		return ok
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	client1, err := Get(ctx1, config)
+	require.NoError(t, err)
+
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	client2, err := Get(ctx2, config)
+	require.NoError(t, err)
+
+	// the middlewares with the same configuration share the client.
+	assert.Same(t, client1, client2)
+
+	cancel1()
+	time.Sleep(50 * time.Millisecond)
+	assert.True(t, isCached())
+
+	// the client is closed once the last middleware using it is replaced.
+	cancel2()
+	assert.Eventually(t, func() bool { return !isCached() }, time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,174 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "RequestID"
+)
+
+const (
+	formatUUID = "uuid"
+	formatULID = "ulid"
+)
+
+// maxIDLength is the maximum length of the request IDs received from the clients which are kept.
+const maxIDLength = 128
+
+// spanTag is the tag of the traces holding the request ID.
+const spanTag = "http.request_id"
+
+// crockford is the Crockford's Base32 alphabet of the ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+type requestID struct {
+	next               http.Handler
+	name               string
+	headerName         string
+	responseHeaderName string
+	generate           func() (string, error)
+	overwrite          bool
+}
+
+// New creates a request ID middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.RequestID, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	if config.HeaderName == "" {
+		return nil, errors.New("empty header name")
+	}
+
+	r := &requestID{
+		next:               next,
+		name:               name,
+		headerName:         http.CanonicalHeaderKey(config.HeaderName),
+		responseHeaderName: config.ResponseHeaderName,
+		overwrite:          config.Overwrite,
+	}
+
+	switch config.Format {
+	case "", formatUUID:
+		r.generate = newUUID
+	case formatULID:
+		r.generate = newULID
+	default:
+		return nil, fmt.Errorf("unknown request ID format %q, must be %s or %s", config.Format, formatUUID, formatULID)
+	}
+
+	return r, nil
+}
+
+func (r *requestID) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return r.name, tracing.SpanKindNoneEnum
+}
+
+func (r *requestID) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	id := req.Header.Get(r.headerName)
+	if r.overwrite || !valid(id) {
+		var err error
+		id, err = r.generate()
+		if err != nil {
+			logger := middlewares.GetLogger(req.Context(), r.name, typeName)
+			logger.Error().Err(err).Msg("Unable to generate request ID")
+
+			r.next.ServeHTTP(rw, req)
+			return
+		}
+
+		req.Header.Set(r.headerName, id)
+	}
+
+	if r.responseHeaderName != "" {
+		rw.Header().Set(r.responseHeaderName, id)
+	}
+
+	if logData := accesslog.GetLogData(req); logData != nil {
+		logData.Core[accesslog.RequestID] = id
+	}
+
+	if span := tracing.GetSpan(req); span != nil {
+		span.SetTag(spanTag, id)
+	}
+
+	r.next.ServeHTTP(rw, req)
+}
+
+// valid reports whether the request ID received from a client is kept,
+// which it is when it is not too long and only made of visible ASCII characters, not to inject anything in the logs.
+func valid(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newUUID() (string, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+// newULID returns a ULID, made of the current time in milliseconds and 80 random bits,
+// which sorts the request IDs in the order of the requests.
+func newULID() (string, error) {
+	var data [16]byte
+
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(data[:6], ms[2:])
+
+	if _, err := rand.Read(data[6:]); err != nil {
+		return "", err
+	}
+
+	return encodeULID(data), nil
+}
+
+// encodeULID encodes the bits of a ULID in Crockford's Base32.
+func encodeULID(data [16]byte) string {
+	// the 128 bits are encoded as 26 characters of 5 bits, the first one only holding 3 bits.
+	var id [26]byte
+	for i := 25; i >= 0; i-- {
+		shift := uint(25-i) * 5
+
+		var value uint
+		for bit := uint(0); bit < 5; bit++ {
+			pos := shift + bit
+			if pos >= 128 {
+				break
+			}
+
+			if data[15-pos/8]&(1<<(pos%8)) != 0 {
+				value |= 1 << bit
+			}
+		}
+
+		id[i] = crockford[value]
+	}
+
+	return string(id[:])
+}
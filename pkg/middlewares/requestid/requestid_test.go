@@ -0,0 +1,167 @@
+package requestid
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
+)
+
+var (
+	uuidRegexp = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulidRegexp = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+)
+
+func TestNew_invalidConfig(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.RequestID{Format: "uuid"}, "requestid")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), http.NotFoundHandler(), dynamic.RequestID{HeaderName: "X-Request-Id", Format: "snowflake"}, "requestid")
+	assert.Error(t, err)
+}
+
+func TestRequestID(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             func(*dynamic.RequestID)
+		incoming           string
+		expectedKept       bool
+		expectedRegexp     *regexp.Regexp
+		expectedHeaderName string
+	}{
+		{
+			desc:           "generated UUID",
+			expectedRegexp: uuidRegexp,
+		},
+		{
+			desc:           "generated ULID",
+			config:         func(c *dynamic.RequestID) { c.Format = "ulid" },
+			expectedRegexp: ulidRegexp,
+		},
+		{
+			desc:         "incoming ID kept",
+			incoming:     "client-id-42",
+			expectedKept: true,
+		},
+		{
+			desc:           "incoming ID overwritten",
+			config:         func(c *dynamic.RequestID) { c.Overwrite = true },
+			incoming:       "client-id-42",
+			expectedRegexp: uuidRegexp,
+		},
+		{
+			desc:           "too long incoming ID replaced",
+			incoming:       strings.Repeat("a", maxIDLength+1),
+			expectedRegexp: uuidRegexp,
+		},
+		{
+			desc:           "incoming ID with spaces replaced",
+			incoming:       "client id",
+			expectedRegexp: uuidRegexp,
+		},
+		{
+			desc:               "custom header names",
+			config:             func(c *dynamic.RequestID) { c.HeaderName = "x-correlation-id"; c.ResponseHeaderName = "X-Trace" },
+			expectedRegexp:     uuidRegexp,
+			expectedHeaderName: "X-Correlation-Id",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			config := dynamic.RequestID{}
+			config.SetDefaults()
+			if test.config != nil {
+				test.config(&config)
+			}
+
+			headerName := test.expectedHeaderName
+			if headerName == "" {
+				headerName = "X-Request-Id"
+			}
+
+			var forwarded string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				forwarded = req.Header.Get(headerName)
+			})
+
+			handler, err := New(context.Background(), next, config, "requestid")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+			if test.incoming != "" {
+				req.Header.Set(headerName, test.incoming)
+			}
+
+			logData := &accesslog.LogData{Core: accesslog.CoreLogData{}}
+			req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			if test.expectedKept {
+				assert.Equal(t, test.incoming, forwarded)
+			} else {
+				assert.Regexp(t, test.expectedRegexp, forwarded)
+			}
+
+			assert.Equal(t, forwarded, rw.Header().Get(config.ResponseHeaderName))
+			assert.Equal(t, forwarded, logData.Core[accesslog.RequestID])
+		})
+	}
+}
+
+func TestRequestID_noResponseHeader(t *testing.T) {
+	config := dynamic.RequestID{}
+	config.SetDefaults()
+	config.ResponseHeaderName = ""
+
+	handler, err := New(context.Background(), http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}), config, "requestid")
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+
+	assert.Empty(t, rw.Header().Get("X-Request-Id"))
+}
+
+func Test_encodeULID(t *testing.T) {
+	var data [16]byte
+	assert.Equal(t, "00000000000000000000000000", encodeULID(data))
+
+	// the timestamp of the ULID specification example.
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], 1469918176385)
+	copy(data[:6], ms[2:])
+	assert.Equal(t, "01ARYZ6S410000000000000000", encodeULID(data))
+
+	for i := range data {
+		data[i] = 0xff
+	}
+	assert.Equal(t, "7ZZZZZZZZZZZZZZZZZZZZZZZZZ", encodeULID(data))
+}
+
+func Test_newULID_sorted(t *testing.T) {
+	previous, err := newULID()
+	require.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		id, err := newULID()
+		require.NoError(t, err)
+
+		// the IDs of different milliseconds are sorted, the ones of the same millisecond share their time prefix.
+		assert.LessOrEqual(t, previous[:10], id[:10])
+		previous = id
+	}
+}
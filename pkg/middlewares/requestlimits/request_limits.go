@@ -0,0 +1,125 @@
+package requestlimits
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+	"golang.org/x/net/http/httpguts"
+)
+
+const (
+	typeName = "RequestLimits"
+)
+
+// requestLimits is a middleware limiting the size of the request bodies, the duration of the requests,
+// and the lifetime of the WebSocket connections.
+type requestLimits struct {
+	next                 http.Handler
+	name                 string
+	maxBodyBytes         int64
+	timeout              time.Duration
+	webSocketMaxLifetime time.Duration
+}
+
+// New creates a request limits middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.RequestLimits, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	if config.MaxBodyBytes < 0 {
+		return nil, errors.New("the maximum body size must be positive")
+	}
+
+	if config.Timeout < 0 {
+		return nil, errors.New("the timeout must be positive")
+	}
+
+	if config.WebSocketMaxLifetime < 0 {
+		return nil, errors.New("the WebSocket max lifetime must be positive")
+	}
+
+	return &requestLimits{
+		next:                 next,
+		name:                 name,
+		maxBodyBytes:         config.MaxBodyBytes,
+		timeout:              time.Duration(config.Timeout),
+		webSocketMaxLifetime: time.Duration(config.WebSocketMaxLifetime),
+	}, nil
+}
+
+func (r *requestLimits) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return r.name, tracing.SpanKindNoneEnum
+}
+
+func (r *requestLimits) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	if r.maxBodyBytes > 0 && req.Body != nil && req.Body != http.NoBody {
+		if req.ContentLength > r.maxBodyBytes {
+			middlewares.GetLogger(req.Context(), r.name, typeName).Debug().
+				Msgf("Request body of %d bytes larger than the maximum of %d bytes", req.ContentLength, r.maxBodyBytes)
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		// the size of the chunked bodies is only known while they are forwarded.
+		req.Body = http.MaxBytesReader(rw, req.Body, r.maxBodyBytes)
+	}
+
+	if r.timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), r.timeout)
+		defer cancel()
+
+		req = req.WithContext(ctx)
+	}
+
+	if r.webSocketMaxLifetime > 0 && isWebSocketUpgrade(req) {
+		rw = &lifetimeResponseWriter{ResponseWriter: rw, lifetime: r.webSocketMaxLifetime}
+	}
+
+	r.next.ServeHTTP(rw, req)
+}
+
+// lifetimeResponseWriter limits the lifetime of the connection hijacked by a WebSocket upgrade,
+// when the entry point tracks the WebSocket connections.
+type lifetimeResponseWriter struct {
+	http.ResponseWriter
+
+	lifetime time.Duration
+}
+
+func (w *lifetimeResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *lifetimeResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if limiter, ok := conn.(interface{ SetMaxLifetime(time.Duration) }); ok {
+		limiter.SetMaxLifetime(w.lifetime)
+	}
+
+	return conn, rw, nil
+}
+
+func isWebSocketUpgrade(req *http.Request) bool {
+	return httpguts.HeaderValuesContainsToken(req.Header["Connection"], "Upgrade") &&
+		strings.EqualFold(req.Header.Get("Upgrade"), "websocket")
+}
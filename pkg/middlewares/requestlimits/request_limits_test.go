@@ -0,0 +1,182 @@
+package requestlimits
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestNew(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {})
+
+	_, err := New(context.Background(), next, dynamic.RequestLimits{MaxBodyBytes: -1}, "limits")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.RequestLimits{Timeout: ptypes.Duration(-time.Second)}, "limits")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), next, dynamic.RequestLimits{WebSocketMaxLifetime: ptypes.Duration(-time.Second)}, "limits")
+	assert.Error(t, err)
+}
+
+func TestRequestLimits_maxBodyBytes(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		body           string
+		chunked        bool
+		maxBodyBytes   int64
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			desc:           "no limit",
+			body:           "0123456789",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "0123456789",
+		},
+		{
+			desc:           "body within the limit",
+			body:           "0123456789",
+			maxBodyBytes:   10,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "0123456789",
+		},
+		{
+			desc:           "content length over the limit",
+			body:           "0123456789",
+			maxBodyBytes:   5,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc:           "chunked body over the limit",
+			body:           "0123456789",
+			chunked:        true,
+			maxBodyBytes:   5,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					rw.WriteHeader(http.StatusRequestEntityTooLarge)
+					return
+				}
+				require.NoError(t, err)
+
+				_, _ = rw.Write(body)
+			})
+
+			handler, err := New(context.Background(), next, dynamic.RequestLimits{MaxBodyBytes: test.maxBodyBytes}, "limits")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(test.body))
+			if test.chunked {
+				req.ContentLength = -1
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequestLimits_timeout(t *testing.T) {
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, ok := req.Context().Deadline()
+		require.True(t, ok)
+
+		<-req.Context().Done()
+		assert.ErrorIs(t, req.Context().Err(), context.DeadlineExceeded)
+	})
+
+	handler, err := New(context.Background(), next, dynamic.RequestLimits{Timeout: ptypes.Duration(10 * time.Millisecond)}, "limits")
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://localhost", nil))
+}
+
+func TestRequestLimits_webSocketMaxLifetime(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		upgrade          string
+		expectedLifetime time.Duration
+	}{
+		{
+			desc:             "WebSocket upgrade",
+			upgrade:          "websocket",
+			expectedLifetime: time.Minute,
+		},
+		{
+			desc:    "other upgrade",
+			upgrade: "h2c",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				_, _, err := rw.(http.Hijacker).Hijack()
+				require.NoError(t, err)
+			})
+
+			handler, err := New(context.Background(), next, dynamic.RequestLimits{WebSocketMaxLifetime: ptypes.Duration(time.Minute)}, "limits")
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodGet, "http://localhost", nil)
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", test.upgrade)
+
+			conn := &lifetimeConn{}
+			handler.ServeHTTP(&hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: conn}, req)
+
+			assert.Equal(t, test.expectedLifetime, conn.lifetime)
+		})
+	}
+}
+
+// hijackRecorder is a response recorder hijacking the given connection.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+
+	conn net.Conn
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.conn, nil, nil
+}
+
+// lifetimeConn records the lifetime set by the middleware, as a connection tracked by the entry point.
+type lifetimeConn struct {
+	net.Conn
+
+	lifetime time.Duration
+}
+
+func (c *lifetimeConn) SetMaxLifetime(lifetime time.Duration) {
+	c.lifetime = lifetime
+}
@@ -0,0 +1,93 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// budgetBuckets is the number of buckets the window of a budget is split into.
+const budgetBuckets = 10
+
+// budget limits the retries to a percentage of the requests, counted over a sliding window.
+// The window is split into buckets, the oldest of which is dropped as time passes.
+type budget struct {
+	percent    int
+	minRetries int
+	bucketSize time.Duration
+
+	mu       sync.Mutex
+	requests [budgetBuckets]int
+	retries  [budgetBuckets]int
+	// last is the index, since the epoch, of the last bucket counted in.
+	last int64
+}
+
+func newBudget(percent, minRetries int, window time.Duration) *budget {
+	bucketSize := window / budgetBuckets
+	if bucketSize <= 0 {
+		bucketSize = 1
+	}
+
+	return &budget{
+		percent:    percent,
+		minRetries: minRetries,
+		bucketSize: bucketSize,
+	}
+}
+
+// request counts a request in the budget.
+func (b *budget) request() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.requests[b.rotate(time.Now())]++
+}
+
+// allowRetry reports whether the budget allows one more retry, which it counts if so.
+func (b *budget) allowRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	current := b.rotate(time.Now())
+
+	var requests, retries int
+	for i := 0; i < budgetBuckets; i++ {
+		requests += b.requests[i]
+		retries += b.retries[i]
+	}
+
+	allowed := requests * b.percent / 100
+	if allowed < b.minRetries {
+		allowed = b.minRetries
+	}
+
+	if retries >= allowed {
+		return false
+	}
+
+	b.retries[current]++
+
+	return true
+}
+
+// rotate resets the buckets which left the window, and returns the index of the current bucket.
+func (b *budget) rotate(now time.Time) int {
+	index := now.UnixNano() / int64(b.bucketSize)
+
+	// the buckets left behind are reset, at most once each.
+	from := b.last + 1
+	if index-from >= budgetBuckets {
+		from = index - budgetBuckets + 1
+	}
+
+	for i := from; i <= index; i++ {
+		b.requests[i%budgetBuckets] = 0
+		b.retries[i%budgetBuckets] = 0
+	}
+
+	if index > b.last {
+		b.last = index
+	}
+
+	return int(b.last % budgetBuckets)
+}
@@ -2,13 +2,16 @@ package retry
 
 import (
 	"bufio"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"net"
 	"net/http"
 	"net/http/httptrace"
+	"strconv"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -16,6 +19,7 @@ import (
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/middlewares"
 	"github.com/traefik/traefik/v3/pkg/tracing"
+	"github.com/traefik/traefik/v3/pkg/types"
 )
 
 // Compile time validation that the response writer implements http interfaces correctly.
@@ -38,6 +42,10 @@ type Listeners []Listener
 type retry struct {
 	attempts        int
 	initialInterval time.Duration
+	maxInterval     time.Duration
+	statuses        types.HTTPCodeRanges
+	idempotentOnly  bool
+	budget          *budget // nil without
 	next            http.Handler
 	listener        Listener
 	name            string
@@ -51,13 +59,44 @@ func New(ctx context.Context, next http.Handler, config dynamic.Retry, listener
 		return nil, fmt.Errorf("incorrect (or empty) value for attempt (%d)", config.Attempts)
 	}
 
-	return &retry{
+	if config.MaxInterval < 0 {
+		return nil, fmt.Errorf("incorrect value for maxInterval (%s)", config.MaxInterval)
+	}
+
+	statuses, err := types.NewHTTPCodeRanges(config.Status)
+	if err != nil {
+		return nil, fmt.Errorf("creating HTTP code ranges: %w", err)
+	}
+
+	r := &retry{
 		attempts:        config.Attempts,
 		initialInterval: time.Duration(config.InitialInterval),
+		maxInterval:     time.Duration(config.MaxInterval),
+		statuses:        statuses,
+		idempotentOnly:  config.IdempotentOnly,
 		next:            next,
 		listener:        listener,
 		name:            name,
-	}, nil
+	}
+
+	if config.Budget != nil {
+		if config.Budget.Percent < 0 || config.Budget.Percent > 100 {
+			return nil, fmt.Errorf("incorrect value for the budget percent (%d), it must be between 0 and 100", config.Budget.Percent)
+		}
+
+		if config.Budget.MinRetries < 0 || config.Budget.Window < 0 {
+			return nil, errors.New("the budget minRetries and window must be positive")
+		}
+
+		window := time.Duration(config.Budget.Window)
+		if window == 0 {
+			window = dynamic.DefaultRetryBudgetWindow
+		}
+
+		r.budget = newBudget(config.Budget.Percent, config.Budget.MinRetries, window)
+	}
+
+	return r, nil
 }
 
 func (r *retry) GetTracingInformation() (string, ext.SpanKindEnum) {
@@ -65,7 +104,11 @@ func (r *retry) GetTracingInformation() (string, ext.SpanKindEnum) {
 }
 
 func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	if r.attempts == 1 {
+	if r.budget != nil {
+		r.budget.request()
+	}
+
+	if r.attempts == 1 || (r.idempotentOnly && !isIdempotent(req)) {
 		r.next.ServeHTTP(rw, req)
 		return
 	}
@@ -77,19 +120,66 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 	// cf https://github.com/traefik/traefik/issues/1008
 	req.Body = io.NopCloser(closableBody)
 
+	// the requests reaching the backend are only retried on the response status when their body can be sent again.
+	statusRetries := len(r.statuses) > 0
+	var bufferedBody []byte
+	if statusRetries && req.ContentLength != 0 {
+		var err error
+		bufferedBody, err = io.ReadAll(io.LimitReader(closableBody, dynamic.DefaultRetryMaxBufferedBodySize+1))
+		if err != nil || len(bufferedBody) > dynamic.DefaultRetryMaxBufferedBodySize {
+			statusRetries = false
+			req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(bufferedBody), closableBody))
+			bufferedBody = nil
+		}
+	}
+
 	attempts := 1
 
+	backOff := &retryAfterBackOff{BackOff: r.newBackOff()}
+
+	allowRetry := func(code int, headers http.Header, reachedBackend bool) bool {
+		var retryAfter time.Duration
+		if reachedBackend {
+			if !statusRetries || !r.statuses.Contains(code) {
+				return false
+			}
+
+			var ok bool
+			if retryAfter, ok = parseRetryAfter(headers); ok && retryAfter > r.maxRetryAfter() {
+				return false
+			}
+		}
+
+		if r.budget != nil && !r.budget.allowRetry() {
+			return false
+		}
+
+		backOff.retryAfter = retryAfter
+
+		return true
+	}
+
 	operation := func() error {
+		if bufferedBody != nil {
+			req.Body = io.NopCloser(bytes.NewReader(bufferedBody))
+		}
+
 		shouldRetry := attempts < r.attempts
-		retryResponseWriter := newResponseWriter(rw, shouldRetry)
+		retryResponseWriter := newResponseWriter(rw, shouldRetry, allowRetry)
+
+		// Disable retries when the backend already received request data,
+		// unless the request is retried on the response status.
+		markReached := retryResponseWriter.DisableRetries
+		if statusRetries {
+			markReached = retryResponseWriter.ReachedBackend
+		}
 
-		// Disable retries when the backend already received request data
 		trace := &httptrace.ClientTrace{
 			WroteHeaders: func() {
-				retryResponseWriter.DisableRetries()
+				markReached()
 			},
 			WroteRequest: func(httptrace.WroteRequestInfo) {
-				retryResponseWriter.DisableRetries()
+				markReached()
 			},
 		}
 		newCtx := httptrace.WithClientTrace(req.Context(), trace)
@@ -107,15 +197,13 @@ func (r *retry) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 
 	logger := middlewares.GetLogger(req.Context(), r.name, typeName)
 
-	backOff := backoff.WithContext(r.newBackOff(), req.Context())
-
 	notify := func(err error, d time.Duration) {
 		logger.Debug().Msgf("New attempt %d for request: %v", attempts, req.URL)
 
 		r.listener.Retried(req, attempts)
 	}
 
-	err := backoff.RetryNotify(operation, backOff, notify)
+	err := backoff.RetryNotify(operation, backoff.WithContext(backOff, req.Context()), notify)
 	if err != nil {
 		logger.Debug().Err(err).Msg("Final retry attempt failed")
 	}
@@ -129,16 +217,80 @@ func (r *retry) newBackOff() backoff.BackOff {
 	b := backoff.NewExponentialBackOff()
 	b.InitialInterval = r.initialInterval
 
-	// calculate the multiplier for the given number of attempts
-	// so that applying the multiplier for the given number of attempts will not exceed 2 times the initial interval
-	// it allows to control the progression along the attempts
-	b.Multiplier = math.Pow(2, 1/float64(r.attempts-1))
+	if r.maxInterval > 0 {
+		b.Multiplier = 2
+		b.MaxInterval = r.maxInterval
+	} else {
+		// calculate the multiplier for the given number of attempts
+		// so that applying the multiplier for the given number of attempts will not exceed 2 times the initial interval
+		// it allows to control the progression along the attempts
+		b.Multiplier = math.Pow(2, 1/float64(r.attempts-1))
+	}
 
 	// according to docs, b.Reset() must be called before using
 	b.Reset()
 	return b
 }
 
+// maxRetryAfter returns the longest Retry-After delay waited for before retrying, the responses asking for longer being forwarded.
+func (r *retry) maxRetryAfter() time.Duration {
+	if r.maxInterval > 0 {
+		return r.maxInterval
+	}
+	return 2 * r.initialInterval
+}
+
+// retryAfterBackOff is a backoff waiting for the Retry-After delay of the retried response, when longer than the backoff interval.
+type retryAfterBackOff struct {
+	backoff.BackOff
+	retryAfter time.Duration
+}
+
+func (b *retryAfterBackOff) NextBackOff() time.Duration {
+	next := b.BackOff.NextBackOff()
+	if next != backoff.Stop && b.retryAfter > next {
+		next = b.retryAfter
+	}
+	b.retryAfter = 0
+
+	return next
+}
+
+// parseRetryAfter returns the delay of the Retry-After header, given in seconds or as an HTTP date.
+func parseRetryAfter(headers http.Header) (time.Duration, bool) {
+	value := headers.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if delay := time.Until(date); delay > 0 {
+		return delay, true
+	}
+	return 0, true
+}
+
+// isIdempotent reports whether the request can be sent more than once with the same effect,
+// as its method is idempotent, or it carries an idempotency key, as considered by the Go HTTP transport.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	_, hasKey := req.Header["Idempotency-Key"]
+	_, hasXKey := req.Header["X-Idempotency-Key"]
+	return hasKey || hasXKey
+}
+
 // Retried exists to implement the Listener interface. It calls Retried on each of its slice entries.
 func (l Listeners) Retried(req *http.Request, attempt int) {
 	for _, listener := range l {
@@ -146,11 +298,12 @@ func (l Listeners) Retried(req *http.Request, attempt int) {
 	}
 }
 
-func newResponseWriter(rw http.ResponseWriter, shouldRetry bool) *responseWriter {
+func newResponseWriter(rw http.ResponseWriter, shouldRetry bool, allowRetry func(code int, headers http.Header, reachedBackend bool) bool) *responseWriter {
 	return &responseWriter{
 		responseWriter: rw,
 		headers:        make(http.Header),
 		shouldRetry:    shouldRetry,
+		allowRetry:     allowRetry,
 	}
 }
 
@@ -158,7 +311,11 @@ type responseWriter struct {
 	responseWriter http.ResponseWriter
 	headers        http.Header
 	shouldRetry    bool
-	written        bool
+	// reachedBackend is whether the backend server received the request, which is then only retried on its response status.
+	reachedBackend bool
+	// allowRetry reports whether the response, with the status code and headers, is retried.
+	allowRetry func(code int, headers http.Header, reachedBackend bool) bool
+	written    bool
 }
 
 func (r *responseWriter) ShouldRetry() bool {
@@ -169,6 +326,10 @@ func (r *responseWriter) DisableRetries() {
 	r.shouldRetry = false
 }
 
+func (r *responseWriter) ReachedBackend() {
+	r.reachedBackend = true
+}
+
 func (r *responseWriter) Header() http.Header {
 	if r.written {
 		return r.responseWriter.Header()
@@ -184,15 +345,20 @@ func (r *responseWriter) Write(buf []byte) (int, error) {
 }
 
 func (r *responseWriter) WriteHeader(code int) {
-	if r.ShouldRetry() && code == http.StatusServiceUnavailable {
+	if r.ShouldRetry() && !r.reachedBackend && code == http.StatusServiceUnavailable {
 		// We get a 503 HTTP Status Code when there is no backend server in the pool
 		// to which the request could be sent.  Also, note that r.ShouldRetry()
 		// will never return true in case there was a connection established to
-		// the backend server and so we can be sure that the 503 was produced
+		// the backend server, unless r.reachedBackend records it for the retries
+		// on the response status, and so we can be sure that the 503 was produced
 		// inside Traefik already and we don't have to retry in this cases.
 		r.DisableRetries()
 	}
 
+	if r.ShouldRetry() && r.allowRetry != nil && !r.allowRetry(code, r.headers, r.reachedBackend) {
+		r.DisableRetries()
+	}
+
 	if r.ShouldRetry() {
 		return
 	}
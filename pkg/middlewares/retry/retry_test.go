@@ -211,6 +211,199 @@ func TestMultipleRetriesShouldNotLooseHeaders(t *testing.T) {
 	}
 }
 
+func TestRetry_status(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             dynamic.Retry
+		method             string
+		headers            map[string]string
+		body               string
+		retryAfter         string
+		wantRetryAttempts  int
+		wantResponseStatus int
+	}{
+		{
+			desc:               "retry on status",
+			config:             dynamic.Retry{Attempts: 2, Status: []string{"503"}},
+			method:             http.MethodGet,
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusOK,
+		},
+		{
+			desc:               "no retry on other status",
+			config:             dynamic.Retry{Attempts: 2, Status: []string{"500-502"}},
+			method:             http.MethodGet,
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:               "no retry when retry after is too long",
+			config:             dynamic.Retry{Attempts: 2, Status: []string{"503"}, InitialInterval: ptypes.Duration(time.Millisecond)},
+			method:             http.MethodGet,
+			retryAfter:         "120",
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:               "retry on status with the body",
+			config:             dynamic.Retry{Attempts: 2, Status: []string{"503"}},
+			method:             http.MethodPost,
+			body:               "foobar",
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusOK,
+		},
+		{
+			desc:               "no retry of non idempotent request",
+			config:             dynamic.Retry{Attempts: 2, Status: []string{"503"}, IdempotentOnly: true},
+			method:             http.MethodPost,
+			body:               "foobar",
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:               "retry of request with idempotency key",
+			config:             dynamic.Retry{Attempts: 2, Status: []string{"503"}, IdempotentOnly: true},
+			method:             http.MethodPost,
+			headers:            map[string]string{"Idempotency-Key": "8e03978e-40d5-43e8-bc93-6894a57f9324"},
+			body:               "foobar",
+			wantRetryAttempts:  1,
+			wantResponseStatus: http.StatusOK,
+		},
+		{
+			desc:               "no retry when the budget is exhausted",
+			config:             dynamic.Retry{Attempts: 2, Status: []string{"503"}, Budget: &dynamic.RetryBudget{Percent: 0}},
+			method:             http.MethodGet,
+			wantRetryAttempts:  0,
+			wantResponseStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var bodies []string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				bodies = append(bodies, string(body))
+
+				// calls WroteHeaders on httptrace.
+				_ = req.Write(io.Discard)
+
+				if len(bodies) > 1 {
+					rw.WriteHeader(http.StatusOK)
+					return
+				}
+
+				if test.retryAfter != "" {
+					rw.Header().Set("Retry-After", test.retryAfter)
+				}
+				rw.WriteHeader(http.StatusServiceUnavailable)
+			})
+
+			retryListener := &countingRetryListener{}
+			retry, err := New(context.Background(), next, test.config, retryListener, "traefikTest")
+			require.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(test.method, "http://localhost:3000/ok", strings.NewReader(test.body))
+			for name, value := range test.headers {
+				req.Header.Set(name, value)
+			}
+
+			retry.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.wantResponseStatus, recorder.Code)
+			assert.Equal(t, test.wantRetryAttempts, retryListener.timesCalled)
+			for _, body := range bodies {
+				assert.Equal(t, test.body, body)
+			}
+		})
+	}
+}
+
+func TestRetry_connectionErrorNotIdempotent(t *testing.T) {
+	var attempts int
+	next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		attempts++
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	retry, err := New(context.Background(), next, dynamic.Retry{Attempts: 3, IdempotentOnly: true}, &countingRetryListener{}, "traefikTest")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest(http.MethodPatch, "http://localhost:3000/ok", nil))
+
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, 1, attempts)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{desc: "none"},
+		{desc: "seconds", value: "3", expected: 3 * time.Second, ok: true},
+		{desc: "past date", value: "Wed, 21 Oct 2015 07:28:00 GMT", ok: true},
+		{desc: "invalid", value: "soon"},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			headers := http.Header{}
+			if test.value != "" {
+				headers.Set("Retry-After", test.value)
+			}
+
+			delay, ok := parseRetryAfter(headers)
+			assert.Equal(t, test.ok, ok)
+			assert.Equal(t, test.expected, delay)
+		})
+	}
+}
+
+func TestRetry_newBackOff(t *testing.T) {
+	r := &retry{attempts: 5, initialInterval: 100 * time.Millisecond, maxInterval: 250 * time.Millisecond}
+
+	backOff := &retryAfterBackOff{BackOff: r.newBackOff()}
+
+	// the intervals are randomized by 50% around 100ms, 200ms, then capped at 250ms.
+	for _, expected := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond, 250 * time.Millisecond} {
+		next := backOff.NextBackOff()
+		assert.GreaterOrEqual(t, next, expected/2)
+		assert.LessOrEqual(t, next, expected*3/2)
+	}
+
+	backOff.retryAfter = 2 * time.Second
+	assert.Equal(t, 2*time.Second, backOff.NextBackOff())
+	assert.LessOrEqual(t, backOff.NextBackOff(), 375*time.Millisecond)
+}
+
+func TestBudget(t *testing.T) {
+	b := newBudget(50, 1, time.Minute)
+
+	// the minimum retries are allowed without requests.
+	assert.True(t, b.allowRetry())
+	assert.False(t, b.allowRetry())
+
+	for i := 0; i < 4; i++ {
+		b.request()
+	}
+
+	// 50% of the 4 requests, including the retry already made.
+	assert.True(t, b.allowRetry())
+	assert.False(t, b.allowRetry())
+}
+
 // countingRetryListener is a Listener implementation to count the times the Retried fn is called.
 type countingRetryListener struct {
 	timesCalled int
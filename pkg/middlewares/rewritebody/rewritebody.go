@@ -0,0 +1,312 @@
+package rewritebody
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "RewriteBody"
+)
+
+// rewriteBody is a middleware that rewrites the bodies of the responses.
+type rewriteBody struct {
+	next        http.Handler
+	name        string
+	rewrites    []rewrite
+	mediaTypes  []string
+	maxBodySize int64
+}
+
+type rewrite struct {
+	regex       *regexp.Regexp
+	replacement *template.Template
+}
+
+// New builds a new RewriteBody middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.RewriteBody, name string) (http.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if len(config.Rewrites) == 0 {
+		return nil, errors.New("rewrites are empty, RewriteBody not created")
+	}
+
+	r := &rewriteBody{
+		next:        next,
+		name:        name,
+		maxBodySize: config.MaxBodySize,
+	}
+	if r.maxBodySize <= 0 {
+		r.maxBodySize = dynamic.DefaultRewriteBodyMaxBodySize
+	}
+
+	for i, rw := range config.Rewrites {
+		regex, err := regexp.Compile(rw.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("compiling the regex of the rewrite %d: %w", i, err)
+		}
+
+		replacement, err := template.New(fmt.Sprintf("%s-%d", name, i)).Parse(rw.Replacement)
+		if err != nil {
+			return nil, fmt.Errorf("parsing the replacement of the rewrite %d: %w", i, err)
+		}
+
+		r.rewrites = append(r.rewrites, rewrite{regex: regex, replacement: replacement})
+	}
+
+	for _, contentType := range config.ContentTypes {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			return nil, fmt.Errorf("parsing the content type %q: %w", contentType, err)
+		}
+		r.mediaTypes = append(r.mediaTypes, mediaType)
+	}
+
+	return r, nil
+}
+
+func (r *rewriteBody) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return r.name, tracing.SpanKindNoneEnum
+}
+
+func (r *rewriteBody) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	// the upgraded connections, e.g. WebSockets, have no body to rewrite.
+	if req.Header.Get("Upgrade") != "" {
+		r.next.ServeHTTP(rw, req)
+		return
+	}
+
+	// the compressed bodies are not rewritten, the service is asked for uncompressed ones.
+	req.Header.Del("Accept-Encoding")
+
+	w := &responseWriter{
+		rw:          rw,
+		rewriter:    r,
+		req:         req,
+		maxBodySize: r.maxBodySize,
+	}
+
+	r.next.ServeHTTP(w, req)
+
+	w.finish()
+}
+
+// rewritable reports whether the body of a response, with the given status code and headers, is rewritten.
+func (r *rewriteBody) rewritable(req *http.Request, code int, header http.Header) bool {
+	if req.Method == http.MethodHead || code == http.StatusNoContent || code == http.StatusNotModified {
+		return false
+	}
+
+	if encoding := header.Get("Content-Encoding"); encoding != "" && !strings.EqualFold(encoding, "identity") {
+		return false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+
+	if len(r.mediaTypes) == 0 {
+		return isTextual(mediaType)
+	}
+
+	for _, allowed := range r.mediaTypes {
+		if allowed == mediaType || strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(allowed, "*")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isTextual reports whether the media type is a textual one, rewritten when no content types are configured.
+func isTextual(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+
+	switch mediaType {
+	case "application/javascript", "application/json", "application/xml":
+		return true
+	}
+
+	return false
+}
+
+// rewrite applies the rewrites, in order, to the body.
+func (r *rewriteBody) rewrite(req *http.Request, body []byte) ([]byte, error) {
+	data := newTemplateData(req)
+
+	for _, rw := range r.rewrites {
+		var replacement strings.Builder
+		if err := rw.replacement.Execute(&replacement, data); err != nil {
+			return nil, fmt.Errorf("executing the replacement of %s: %w", rw.regex, err)
+		}
+
+		body = rw.regex.ReplaceAll(body, []byte(replacement.String()))
+	}
+
+	return body, nil
+}
+
+// templateData is the request data the replacements are executed with.
+// The values are escaped, for their $ signs not to be expanded as captured variables.
+type templateData struct {
+	Host   string
+	Scheme string
+	Path   string
+
+	header http.Header
+}
+
+func newTemplateData(req *http.Request) templateData {
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	if proto := req.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	return templateData{
+		Host:   escape(req.Host),
+		Scheme: escape(scheme),
+		Path:   escape(req.URL.Path),
+		header: req.Header,
+	}
+}
+
+// Header returns the value of a header of the request, e.g. {{ .Header "X-Forwarded-Prefix" }}.
+func (d templateData) Header(name string) string {
+	return escape(d.header.Get(name))
+}
+
+func escape(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
+}
+
+// responseWriter buffers the rewritable bodies, and forwards the others unchanged.
+type responseWriter struct {
+	rw       http.ResponseWriter
+	rewriter *rewriteBody
+	req      *http.Request
+
+	status      int
+	buffering   bool
+	body        bytes.Buffer
+	maxBodySize int64
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.rw.Header()
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+
+	// the informational responses are forwarded, the final response following.
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.rw.WriteHeader(code)
+		return
+	}
+
+	w.status = code
+	w.buffering = w.rewriter.rewritable(w.req, code, w.rw.Header())
+
+	if w.buffering {
+		if length, err := strconv.ParseInt(w.rw.Header().Get("Content-Length"), 10, 64); err == nil && length > w.maxBodySize {
+			w.buffering = false
+		}
+	}
+
+	// the headers of a rewritten response are written with its body, once its length is known.
+	if !w.buffering {
+		w.rw.WriteHeader(code)
+	}
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if !w.buffering {
+		return w.rw.Write(p)
+	}
+
+	if int64(w.body.Len()+len(p)) > w.maxBodySize {
+		// the body is too large to be rewritten, it is forwarded unchanged.
+		w.buffering = false
+		w.rw.WriteHeader(w.status)
+		if _, err := w.rw.Write(w.body.Bytes()); err != nil {
+			return 0, err
+		}
+		w.body = bytes.Buffer{}
+
+		return w.rw.Write(p)
+	}
+
+	return w.body.Write(p)
+}
+
+// Flush only flushes the bodies which are not rewritten, the rewritten ones being written at once.
+func (w *responseWriter) Flush() {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.buffering {
+		return
+	}
+
+	if flusher, ok := w.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// finish rewrites the buffered body, and writes it along with the headers.
+func (w *responseWriter) finish() {
+	if !w.buffering {
+		return
+	}
+
+	body, err := w.rewriter.rewrite(w.req, w.body.Bytes())
+	if err != nil {
+		logger := middlewares.GetLogger(w.req.Context(), w.rewriter.name, typeName)
+		logger.Error().Err(err).Msg("Error while rewriting the body, forwarding it unchanged")
+
+		body = w.body.Bytes()
+	}
+
+	header := w.rw.Header()
+	if !bytes.Equal(body, w.body.Bytes()) {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+
+		// the rewritten body is not byte-for-byte the one identified by a strong ETag anymore.
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+	}
+
+	w.rw.WriteHeader(w.status)
+	_, _ = w.rw.Write(body)
+}
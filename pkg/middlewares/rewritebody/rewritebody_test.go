@@ -0,0 +1,254 @@
+package rewritebody
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestNew_invalidConfig(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		config dynamic.RewriteBody
+	}{
+		{
+			desc: "no rewrites",
+		},
+		{
+			desc: "invalid regex",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "(", Replacement: "foo"}},
+			},
+		},
+		{
+			desc: "invalid template",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "foo", Replacement: "{{ .Host"}},
+			},
+		},
+		{
+			desc: "invalid content type",
+			config: dynamic.RewriteBody{
+				Rewrites:     []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+				ContentTypes: []string{"text/"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), http.NotFoundHandler(), test.config, "test")
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRewriteBody(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		config          dynamic.RewriteBody
+		method          string
+		reqHeader       map[string]string
+		status          int
+		header          map[string]string
+		body            string
+		expectedBody    string
+		expectedHeaders map[string]string
+	}{
+		{
+			desc: "absolute URLs",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{
+					Regex:       `http://legacy\.internal(/[^"]*)?`,
+					Replacement: `{{ .Scheme }}://{{ .Host }}/legacy$1`,
+				}},
+			},
+			header:       map[string]string{"Content-Type": "text/html; charset=utf-8"},
+			body:         `<a href="http://legacy.internal/foo">foo</a><a href="http://legacy.internal">home</a>`,
+			expectedBody: `<a href="http://example.com/legacy/foo">foo</a><a href="http://example.com/legacy">home</a>`,
+			expectedHeaders: map[string]string{
+				"Content-Length": strconv.Itoa(len(`<a href="http://example.com/legacy/foo">foo</a><a href="http://example.com/legacy">home</a>`)),
+			},
+		},
+		{
+			desc: "rewrites in order",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{
+					{Regex: "foo", Replacement: "bar"},
+					{Regex: "bar", Replacement: "baz"},
+				},
+			},
+			header:       map[string]string{"Content-Type": "application/json"},
+			body:         `{"foo":"bar"}`,
+			expectedBody: `{"baz":"baz"}`,
+		},
+		{
+			desc: "request headers",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: `href="/`, Replacement: `href="{{ .Header "X-Forwarded-Prefix" }}/`}},
+			},
+			reqHeader:    map[string]string{"X-Forwarded-Prefix": "/$1app"},
+			header:       map[string]string{"Content-Type": "text/html"},
+			body:         `<a href="/foo">`,
+			expectedBody: `<a href="/$1app/foo">`,
+		},
+		{
+			desc: "forwarded scheme",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: `http://legacy\.internal`, Replacement: `{{ .Scheme }}://{{ .Host }}`}},
+			},
+			reqHeader:    map[string]string{"X-Forwarded-Proto": "https"},
+			header:       map[string]string{"Content-Type": "text/html"},
+			body:         `http://legacy.internal/foo`,
+			expectedBody: `https://example.com/foo`,
+		},
+		{
+			desc: "strong ETag weakened",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+			},
+			header:          map[string]string{"Content-Type": "text/plain", "ETag": `"foo"`},
+			body:            "foo",
+			expectedBody:    "bar",
+			expectedHeaders: map[string]string{"ETag": `W/"foo"`},
+		},
+		{
+			desc: "not textual",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+			},
+			header:       map[string]string{"Content-Type": "application/octet-stream"},
+			body:         "foo",
+			expectedBody: "foo",
+		},
+		{
+			desc: "configured content types",
+			config: dynamic.RewriteBody{
+				Rewrites:     []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+				ContentTypes: []string{"application/octet-stream", "image/*"},
+			},
+			header:       map[string]string{"Content-Type": "image/svg+xml"},
+			body:         "foo",
+			expectedBody: "bar",
+		},
+		{
+			desc: "not a configured content type",
+			config: dynamic.RewriteBody{
+				Rewrites:     []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+				ContentTypes: []string{"text/html"},
+			},
+			header:       map[string]string{"Content-Type": "text/plain"},
+			body:         "foo",
+			expectedBody: "foo",
+		},
+		{
+			desc: "compressed",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+			},
+			header:       map[string]string{"Content-Type": "text/plain", "Content-Encoding": "gzip"},
+			body:         "foo",
+			expectedBody: "foo",
+		},
+		{
+			desc: "HEAD request",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+			},
+			method:          http.MethodHead,
+			header:          map[string]string{"Content-Type": "text/plain", "Content-Length": "3"},
+			expectedHeaders: map[string]string{"Content-Length": "3"},
+		},
+		{
+			desc: "larger than the max body size",
+			config: dynamic.RewriteBody{
+				Rewrites:    []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+				MaxBodySize: 5,
+			},
+			header:       map[string]string{"Content-Type": "text/plain"},
+			body:         "foo foo",
+			expectedBody: "foo foo",
+		},
+		{
+			desc: "Content-Length larger than the max body size",
+			config: dynamic.RewriteBody{
+				Rewrites:    []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+				MaxBodySize: 5,
+			},
+			header:          map[string]string{"Content-Type": "text/plain", "Content-Length": "7"},
+			body:            "foo foo",
+			expectedBody:    "foo foo",
+			expectedHeaders: map[string]string{"Content-Length": "7"},
+		},
+		{
+			desc: "status code",
+			config: dynamic.RewriteBody{
+				Rewrites: []dynamic.BodyRewrite{{Regex: "foo", Replacement: "bar"}},
+			},
+			status:       http.StatusNotFound,
+			header:       map[string]string{"Content-Type": "text/plain"},
+			body:         "foo",
+			expectedBody: "bar",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				assert.Empty(t, req.Header.Get("Accept-Encoding"))
+
+				for k, v := range test.header {
+					rw.Header().Set(k, v)
+				}
+				if test.status != 0 {
+					rw.WriteHeader(test.status)
+				}
+
+				// the body is written in several parts.
+				for _, part := range strings.SplitAfter(test.body, " ") {
+					_, _ = rw.Write([]byte(part))
+				}
+			})
+
+			handler, err := New(context.Background(), next, test.config, "test")
+			require.NoError(t, err)
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, "http://example.com/foo", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			for k, v := range test.reqHeader {
+				req.Header.Set(k, v)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			expectedStatus := test.status
+			if expectedStatus == 0 {
+				expectedStatus = http.StatusOK
+			}
+			assert.Equal(t, expectedStatus, rec.Code)
+			assert.Equal(t, test.expectedBody, rec.Body.String())
+			for k, v := range test.expectedHeaders {
+				assert.Equal(t, v, rec.Header().Get(k), k)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package idletimeout
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+)
+
+const typeName = "IdleTimeoutTCP"
+
+type idleTimeout struct {
+	name    string
+	next    tcp.Handler
+	timeout time.Duration
+}
+
+// New creates a middleware closing the connections on which no data is exchanged, in either direction, for the timeout.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPIdleTimeout, name string) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.Timeout <= 0 {
+		return nil, errors.New("timeout must be positive")
+	}
+
+	return &idleTimeout{
+		name:    name,
+		next:    next,
+		timeout: time.Duration(config.Timeout),
+	}, nil
+}
+
+// ServeTCP serves the given TCP connection.
+func (i *idleTimeout) ServeTCP(conn tcp.WriteCloser) {
+	c := newIdleConn(conn, i.timeout, func() {
+		middlewares.GetLogger(context.Background(), i.name, typeName).Debug().
+			Msgf("Closing the connection of %s, idle for %s", conn.RemoteAddr(), i.timeout)
+	})
+	defer c.stop()
+
+	i.next.ServeTCP(c)
+}
+
+// idleConn closes the connection when no data is read from it nor written to it for the timeout.
+// A timer is used rather than deadlines, as a direction of the connection can be idle while the other one is active,
+// and as the deadlines are set by the proxy to terminate the connection.
+type idleConn struct {
+	tcp.WriteCloser
+
+	timeout      time.Duration
+	lastActivity atomic.Int64 // in unix nanoseconds.
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+}
+
+func newIdleConn(conn tcp.WriteCloser, timeout time.Duration, onClose func()) *idleConn {
+	c := &idleConn{WriteCloser: conn, timeout: timeout}
+	c.lastActivity.Store(time.Now().UnixNano())
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.timer = time.AfterFunc(timeout, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.stopped {
+			return
+		}
+
+		idle := time.Since(time.Unix(0, c.lastActivity.Load()))
+		if idle < c.timeout {
+			c.timer.Reset(c.timeout - idle)
+			return
+		}
+
+		onClose()
+		_ = c.WriteCloser.Close()
+	})
+
+	return c
+}
+
+// Unwrap returns the wrapped connection.
+func (c *idleConn) Unwrap() tcp.WriteCloser {
+	return c.WriteCloser
+}
+
+func (c *idleConn) Read(p []byte) (int, error) {
+	n, err := c.WriteCloser.Read(p)
+	if n > 0 {
+		c.lastActivity.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
+
+func (c *idleConn) Write(p []byte) (int, error) {
+	n, err := c.WriteCloser.Write(p)
+	if n > 0 {
+		c.lastActivity.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
+
+// stop stops the timer, once the connection is served.
+func (c *idleConn) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stopped = true
+	c.timer.Stop()
+}
@@ -0,0 +1,71 @@
+package idletimeout
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(context.Background(), nil, dynamic.TCPIdleTimeout{}, "foo")
+	assert.EqualError(t, err, "timeout must be positive")
+
+	_, err = New(context.Background(), nil, dynamic.TCPIdleTimeout{Timeout: ptypes.Duration(time.Second)}, "foo")
+	require.NoError(t, err)
+}
+
+func TestIdleTimeout_ServeTCP(t *testing.T) {
+	served := make(chan error, 1)
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		_, err := io.Copy(io.Discard, conn)
+		served <- err
+	})
+
+	middleware, err := New(context.Background(), next, dynamic.TCPIdleTimeout{Timeout: ptypes.Duration(100 * time.Millisecond)}, "foo")
+	require.NoError(t, err)
+
+	client, server := net.Pipe()
+	t.Cleanup(func() { _ = client.Close() })
+
+	start := time.Now()
+	go middleware.ServeTCP(pipeConn{server})
+
+	// the connection is active for longer than the timeout.
+	for i := 0; i < 5; i++ {
+		_, err = client.Write([]byte("ping"))
+		require.NoError(t, err)
+
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	select {
+	case <-served:
+		t.Fatal("The active connection has been closed")
+	default:
+	}
+
+	// then it is idle.
+	select {
+	case err := <-served:
+		require.Error(t, err)
+		assert.Greater(t, time.Since(start), 300*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("The idle connection has not been closed")
+	}
+}
+
+type pipeConn struct {
+	net.Conn
+}
+
+func (c pipeConn) CloseWrite() error {
+	return c.Close()
+}
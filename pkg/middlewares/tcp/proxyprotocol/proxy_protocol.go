@@ -0,0 +1,38 @@
+package proxyprotocol
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+)
+
+const typeName = "ProxyProtocolTCP"
+
+type proxyProtocol struct {
+	next    tcp.Handler
+	version int
+}
+
+// New creates a middleware sending the PROXY protocol header to the servers of the service,
+// unless the service sends its own.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPProxyProtocol, name string) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.Version < 1 || config.Version > 2 {
+		return nil, fmt.Errorf("unknown proxyProtocol version: %d", config.Version)
+	}
+
+	return &proxyProtocol{
+		next:    next,
+		version: config.Version,
+	}, nil
+}
+
+// ServeTCP serves the given TCP connection.
+func (p *proxyProtocol) ServeTCP(conn tcp.WriteCloser) {
+	p.next.ServeTCP(tcp.WithProxyProtocol(conn, p.version))
+}
@@ -0,0 +1,76 @@
+package proxyprotocol
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		version     int
+		expectedErr string
+	}{
+		{
+			desc:    "version 1",
+			version: 1,
+		},
+		{
+			desc:    "version 2",
+			version: 2,
+		},
+		{
+			desc:        "unknown version",
+			version:     3,
+			expectedErr: "unknown proxyProtocol version: 3",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), nil, dynamic.TCPProxyProtocol{Version: test.version}, "foo")
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestProxyProtocol_ServeTCP(t *testing.T) {
+	conn := &fakeConn{}
+
+	var served tcp.WriteCloser
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		served = conn
+	})
+
+	middleware, err := New(context.Background(), next, dynamic.TCPProxyProtocol{Version: 2}, "foo")
+	require.NoError(t, err)
+
+	middleware.ServeTCP(conn)
+
+	// the connection is marked for the proxy, which sends the header.
+	unwrapper, ok := served.(interface{ Unwrap() tcp.WriteCloser })
+	require.True(t, ok)
+	assert.Same(t, conn, unwrapper.Unwrap())
+}
+
+type fakeConn struct {
+	net.Conn
+}
+
+func (c *fakeConn) CloseWrite() error {
+	panic("implement me")
+}
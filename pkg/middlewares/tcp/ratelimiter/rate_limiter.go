@@ -0,0 +1,123 @@
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mailgun/ttlmap"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+	"golang.org/x/time/rate"
+)
+
+const typeName = "RateLimiterTCP"
+
+// maxSources is the maximum number of IPs whose token bucket is kept.
+const maxSources = 65536
+
+// rateLimiter limits the rate of the new connections with a set of token buckets, one for each remote IP,
+// and closes the connections beyond the rate.
+type rateLimiter struct {
+	name  string
+	next  tcp.Handler
+	rate  rate.Limit // conns/s
+	burst int64
+	// each token bucket is "garbage collected" when it has not been used for ttl seconds.
+	ttl int
+
+	buckets *ttlmap.TtlMap // actual buckets, keyed by remote IP.
+}
+
+// New creates a connection rate limiting middleware.
+// The connections are identified and grouped by remote IP.
+func New(ctx context.Context, next tcp.Handler, config dynamic.TCPRateLimit, name string) (tcp.Handler, error) {
+	logger := middlewares.GetLogger(ctx, name, typeName)
+	logger.Debug().Msg("Creating middleware")
+
+	if config.Average <= 0 {
+		return nil, fmt.Errorf("average must be positive: %d", config.Average)
+	}
+
+	burst := config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	period := time.Duration(config.Period)
+	if period < 0 {
+		return nil, fmt.Errorf("negative value not valid for period: %v", period)
+	}
+	if period == 0 {
+		period = time.Second
+	}
+
+	rtl := float64(config.Average*int64(time.Second)) / float64(period)
+
+	// As for the HTTP rate limiter, the ttl is inversely proportional to the rate for the low rates,
+	// for a bucket not to be forgotten before it is refilled.
+	ttl := 2
+	if rtl < 1 {
+		ttl = 1 + int(1/rtl)
+	}
+
+	buckets, err := ttlmap.NewConcurrent(maxSources)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rateLimiter{
+		name:    name,
+		next:    next,
+		rate:    rate.Limit(rtl),
+		burst:   burst,
+		ttl:     ttl,
+		buckets: buckets,
+	}, nil
+}
+
+// ServeTCP serves the given TCP connection.
+func (r *rateLimiter) ServeTCP(conn tcp.WriteCloser) {
+	logger := middlewares.GetLogger(context.Background(), r.name, typeName)
+
+	ip, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		logger.Error().Err(err).Msg("Cannot parse IP from remote addr")
+		conn.Close()
+		return
+	}
+
+	allowed, err := r.allow(ip)
+	if err != nil {
+		logger.Error().Err(err).Msg("Could not check the connection rate")
+		conn.Close()
+		return
+	}
+
+	if !allowed {
+		logger.Debug().Msgf("Connection rejected: rate limit reached for %s", ip)
+		conn.Close()
+		return
+	}
+
+	r.next.ServeTCP(conn)
+}
+
+// allow takes a token from the bucket of the given IP, and reports whether there was one.
+func (r *rateLimiter) allow(ip string) (bool, error) {
+	var bucket *rate.Limiter
+	if rlSource, exists := r.buckets.Get(ip); exists {
+		bucket = rlSource.(*rate.Limiter)
+	} else {
+		bucket = rate.NewLimiter(r.rate, int(r.burst))
+	}
+
+	// The bucket is set even when it exists, to push back its expiry as long as the IP is active.
+	if err := r.buckets.Set(ip, bucket, r.ttl); err != nil {
+		return false, fmt.Errorf("setting bucket: %w", err)
+	}
+
+	return bucket.Allow(), nil
+}
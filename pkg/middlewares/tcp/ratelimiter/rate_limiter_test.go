@@ -0,0 +1,102 @@
+package ratelimiter
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      dynamic.TCPRateLimit
+		expectedErr string
+	}{
+		{
+			desc:   "valid",
+			config: dynamic.TCPRateLimit{Average: 10, Burst: 20},
+		},
+		{
+			desc:        "no average",
+			config:      dynamic.TCPRateLimit{Burst: 20},
+			expectedErr: "average must be positive: 0",
+		},
+		{
+			desc:        "negative period",
+			config:      dynamic.TCPRateLimit{Average: 10, Period: ptypes.Duration(-1)},
+			expectedErr: "negative value not valid for period: -1ns",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(context.Background(), nil, test.config, "foo")
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRateLimiter_ServeTCP(t *testing.T) {
+	var served []string
+	next := tcp.HandlerFunc(func(conn tcp.WriteCloser) {
+		served = append(served, conn.RemoteAddr().String())
+	})
+
+	// one connection per hour, after the burst.
+	middleware, err := New(context.Background(), next, dynamic.TCPRateLimit{Average: 1, Period: ptypes.Duration(3600e9), Burst: 2}, "foo")
+	require.NoError(t, err)
+
+	var closed []string
+	for _, addr := range []string{"127.0.0.1:9000", "127.0.0.1:9001", "127.0.0.1:9002", "127.0.0.2:9000"} {
+		middleware.ServeTCP(&fakeConn{addr: addr, closed: &closed})
+	}
+
+	assert.Equal(t, []string{"127.0.0.1:9000", "127.0.0.1:9001", "127.0.0.2:9000"}, served)
+	assert.Equal(t, []string{"127.0.0.1:9002"}, closed)
+}
+
+type fakeConn struct {
+	net.Conn
+
+	addr   string
+	closed *[]string
+}
+
+func (c *fakeConn) RemoteAddr() net.Addr {
+	return fakeAddr{addr: c.addr}
+}
+
+func (c *fakeConn) Close() error {
+	*c.closed = append(*c.closed, c.addr)
+	return nil
+}
+
+func (c *fakeConn) CloseWrite() error {
+	panic("implement me")
+}
+
+type fakeAddr struct {
+	addr string
+}
+
+func (a fakeAddr) Network() string {
+	return "tcp"
+}
+
+func (a fakeAddr) String() string {
+	return a.addr
+}
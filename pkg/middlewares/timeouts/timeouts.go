@@ -0,0 +1,229 @@
+package timeouts
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+)
+
+const (
+	typeName = "Timeouts"
+)
+
+type dialTimeoutKey struct{}
+
+// DialTimeout returns the dial timeout of the router handling the request of the context, false when it has none.
+func DialTimeout(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(dialTimeoutKey{}).(time.Duration)
+	return timeout, ok
+}
+
+// timeouts is a handler applying the timeouts of a router to its requests.
+type timeouts struct {
+	next                  http.Handler
+	dialTimeout           time.Duration
+	responseHeaderTimeout time.Duration
+	idleTimeout           time.Duration
+	requestTimeout        time.Duration
+}
+
+// New creates a handler applying the timeouts of a router.
+func New(ctx context.Context, next http.Handler, config dynamic.RouterTimeouts, routerName string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, routerName, typeName).Debug().Msg("Creating middleware")
+
+	if config.DialTimeout < 0 || config.ResponseHeaderTimeout < 0 || config.IdleTimeout < 0 || config.RequestTimeout < 0 {
+		return nil, errors.New("the timeouts must be positive")
+	}
+
+	return &timeouts{
+		next:                  next,
+		dialTimeout:           time.Duration(config.DialTimeout),
+		responseHeaderTimeout: time.Duration(config.ResponseHeaderTimeout),
+		idleTimeout:           time.Duration(config.IdleTimeout),
+		requestTimeout:        time.Duration(config.RequestTimeout),
+	}, nil
+}
+
+func (t *timeouts) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+
+	if t.dialTimeout > 0 {
+		ctx = context.WithValue(ctx, dialTimeoutKey{}, t.dialTimeout)
+	}
+
+	if t.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.requestTimeout)
+		defer cancel()
+	}
+
+	if t.responseHeaderTimeout > 0 || t.idleTimeout > 0 {
+		wctx := newWatchdogContext(ctx, t.responseHeaderTimeout)
+		defer wctx.stop()
+
+		ctx = wctx
+		rw = &responseWriter{rw: rw, ctx: wctx, idleTimeout: t.idleTimeout}
+	}
+
+	t.next.ServeHTTP(rw, req.WithContext(ctx))
+}
+
+// watchdogContext is a context whose deadline is pushed back by the progress of the response:
+// it expires when the response headers, then the writes of the response body, are not received in time.
+// Its error is context.DeadlineExceeded once expired, for the forwarding error to be reported as a 504 Gateway Timeout.
+type watchdogContext struct {
+	context.Context
+
+	done chan struct{}
+
+	mu     sync.Mutex
+	err    error
+	timer  *time.Timer
+	closed bool
+}
+
+// newWatchdogContext returns a context expiring after the timeout, zero meaning never until reset.
+func newWatchdogContext(parent context.Context, timeout time.Duration) *watchdogContext {
+	c := &watchdogContext{Context: parent, done: make(chan struct{})}
+	c.reset(timeout)
+
+	// the Done channel differs from the one of the parent, for the child contexts to get their error from Err.
+	go func() {
+		select {
+		case <-parent.Done():
+			c.cancel(parent.Err())
+		case <-c.done:
+		}
+	}()
+
+	return c
+}
+
+func (c *watchdogContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *watchdogContext) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *watchdogContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	c.err = err
+	c.closed = true
+	close(c.done)
+
+	if c.timer != nil {
+		c.timer.Stop()
+	}
+}
+
+// reset sets the duration after which the context expires, zero meaning never.
+func (c *watchdogContext) reset(timeout time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+
+	if timeout > 0 {
+		c.timer = time.AfterFunc(timeout, func() { c.cancel(context.DeadlineExceeded) })
+	}
+}
+
+// stop releases the resources of the context, once the request is handled.
+func (c *watchdogContext) stop() {
+	c.cancel(context.Canceled)
+}
+
+// responseWriter resets the watchdog context on each write of the response.
+type responseWriter struct {
+	rw          http.ResponseWriter
+	ctx         *watchdogContext
+	idleTimeout time.Duration
+
+	headerWritten bool
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.rw.Header()
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.headerWritten {
+		w.rw.WriteHeader(code)
+		return
+	}
+
+	// the informational responses do not end the wait for the response headers.
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		w.rw.WriteHeader(code)
+		return
+	}
+
+	w.headerWritten = true
+
+	// the upgraded connections, e.g. WebSockets, do not write the body through the response writer.
+	if code == http.StatusSwitchingProtocols {
+		w.ctx.reset(0)
+	} else {
+		w.ctx.reset(w.idleTimeout)
+	}
+
+	w.rw.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	if !w.headerWritten {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	n, err := w.rw.Write(p)
+	w.ctx.reset(w.idleTimeout)
+
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if !w.headerWritten {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := w.rw.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.rw)
+	}
+
+	w.ctx.reset(0)
+
+	return hijacker.Hijack()
+}
@@ -0,0 +1,153 @@
+package timeouts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.RouterTimeouts{IdleTimeout: ptypes.Duration(-time.Second)}, "router")
+	assert.Error(t, err)
+}
+
+func TestTimeouts(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         dynamic.RouterTimeouts
+		headerDelay    time.Duration
+		bodyDelay      time.Duration
+		expectedStatus int
+		expectedBody   string
+		expectedErr    bool
+	}{
+		{
+			desc:           "within the timeouts",
+			config:         dynamic.RouterTimeouts{ResponseHeaderTimeout: ptypes.Duration(time.Second), IdleTimeout: ptypes.Duration(time.Second)},
+			headerDelay:    10 * time.Millisecond,
+			bodyDelay:      10 * time.Millisecond,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "foobar",
+		},
+		{
+			desc:           "response header timeout",
+			config:         dynamic.RouterTimeouts{ResponseHeaderTimeout: ptypes.Duration(50 * time.Millisecond)},
+			headerDelay:    time.Second,
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+		{
+			desc:           "response header timeout not applying to the body",
+			config:         dynamic.RouterTimeouts{ResponseHeaderTimeout: ptypes.Duration(100 * time.Millisecond)},
+			bodyDelay:      200 * time.Millisecond,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "foobar",
+		},
+		{
+			desc:           "idle timeout",
+			config:         dynamic.RouterTimeouts{IdleTimeout: ptypes.Duration(50 * time.Millisecond)},
+			bodyDelay:      time.Second,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "foo",
+			expectedErr:    true,
+		},
+		{
+			desc:           "request timeout",
+			config:         dynamic.RouterTimeouts{RequestTimeout: ptypes.Duration(50 * time.Millisecond)},
+			headerDelay:    time.Second,
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				select {
+				case <-time.After(test.headerDelay):
+				case <-req.Context().Done():
+					return
+				}
+
+				rw.WriteHeader(http.StatusOK)
+				_, _ = rw.Write([]byte("foo"))
+				rw.(http.Flusher).Flush()
+
+				select {
+				case <-time.After(test.bodyDelay):
+				case <-req.Context().Done():
+					return
+				}
+
+				_, _ = rw.Write([]byte("bar"))
+			}))
+			t.Cleanup(backend.Close)
+
+			target, err := url.Parse(backend.URL)
+			require.NoError(t, err)
+
+			proxy := httputil.NewSingleHostReverseProxy(target)
+			proxy.ErrorHandler = func(rw http.ResponseWriter, req *http.Request, err error) {
+				// as the forwarding error handler, the timeouts are reported as 504 Gateway Timeout.
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					rw.WriteHeader(http.StatusGatewayTimeout)
+					return
+				}
+				rw.WriteHeader(http.StatusBadGateway)
+			}
+
+			handler, err := New(context.Background(), proxy, test.config, "router")
+			require.NoError(t, err)
+
+			server := httptest.NewServer(handler)
+			t.Cleanup(server.Close)
+
+			resp, err := http.Get(server.URL)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = resp.Body.Close() })
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			if test.expectedErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedBody, string(body))
+		})
+	}
+}
+
+func TestTimeouts_dialTimeout(t *testing.T) {
+	var timeout time.Duration
+	var ok bool
+	next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		timeout, ok = DialTimeout(req.Context())
+	})
+
+	handler, err := New(context.Background(), next, dynamic.RouterTimeouts{DialTimeout: ptypes.Duration(time.Second)}, "router")
+	require.NoError(t, err)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "http://example.com", nil))
+
+	assert.True(t, ok)
+	assert.Equal(t, time.Second, timeout)
+
+	_, ok = DialTimeout(context.Background())
+	assert.False(t, ok)
+}
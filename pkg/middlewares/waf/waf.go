@@ -0,0 +1,239 @@
+package waf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	coreruleset "github.com/corazawaf/coraza-coreruleset/v4"
+	"github.com/corazawaf/coraza/v3"
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/opentracing/opentracing-go/ext"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares"
+	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
+	"github.com/traefik/traefik/v3/pkg/tracing"
+)
+
+const (
+	typeName = "WAF"
+)
+
+// crsDirectives are the directives loading the OWASP Core Rule Set embedded in Traefik, with its recommended configuration.
+var crsDirectives = []string{
+	"Include @coraza.conf-recommended",
+	"Include @crs-setup.conf.example",
+	"Include @owasp_crs/*.conf",
+}
+
+type waf struct {
+	next   http.Handler
+	name   string
+	engine coraza.WAF
+}
+
+// New creates a WAF middleware.
+func New(ctx context.Context, next http.Handler, config dynamic.WAF, name string) (http.Handler, error) {
+	middlewares.GetLogger(ctx, name, typeName).Debug().Msg("Creating middleware")
+
+	var directives []string
+	if config.CRS {
+		directives = append(directives, crsDirectives...)
+	}
+	directives = append(directives, config.Directives...)
+
+	if len(directives) == 0 {
+		return nil, errors.New("no rule defined, set crs or directives")
+	}
+
+	// the engine mode set by the middleware takes precedence over the one of the directives, e.g. of the recommended configuration.
+	if config.DetectionOnly {
+		directives = append(directives, "SecRuleEngine DetectionOnly")
+	} else {
+		directives = append(directives, "SecRuleEngine On")
+	}
+
+	engine, err := coraza.NewWAF(coraza.NewWAFConfig().
+		WithRootFS(rulesFS{}).
+		WithDirectives(strings.Join(directives, "\n")))
+	if err != nil {
+		return nil, fmt.Errorf("loading rules: %w", err)
+	}
+
+	return &waf{
+		next:   next,
+		name:   name,
+		engine: engine,
+	}, nil
+}
+
+func (w *waf) GetTracingInformation() (string, ext.SpanKindEnum) {
+	return w.name, tracing.SpanKindNoneEnum
+}
+
+func (w *waf) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	logger := middlewares.GetLogger(req.Context(), w.name, typeName)
+
+	tx := w.engine.NewTransaction()
+	defer func() {
+		tx.ProcessLogging()
+
+		if err := tx.Close(); err != nil {
+			logger.Error().Err(err).Msg("Unable to close the WAF transaction")
+		}
+	}()
+
+	if tx.IsRuleEngineOff() {
+		w.next.ServeHTTP(rw, req)
+		return
+	}
+
+	interruption, err := processRequest(tx, req)
+
+	matched := matchedRules(tx)
+	if len(matched) > 0 {
+		if logData := accesslog.GetLogData(req); logData != nil {
+			logData.Core[accesslog.WAFMatchedRules] = matched
+		}
+	}
+
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to inspect the request")
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if interruption != nil {
+		logger.Debug().Msgf("Request blocked by the rule %d: %v", interruption.RuleID, matched)
+
+		if logData := accesslog.GetLogData(req); logData != nil {
+			logData.Core[accesslog.WAFInterruptingRule] = interruption.RuleID
+		}
+
+		status := interruption.Status
+		if status == 0 {
+			status = http.StatusForbidden
+		}
+
+		rw.WriteHeader(status)
+		return
+	}
+
+	w.next.ServeHTTP(rw, req)
+}
+
+// processRequest runs the request phases of the rules, and returns the interruption of the request, nil when it is let through.
+func processRequest(tx types.Transaction, req *http.Request) (*types.Interruption, error) {
+	host, port, _ := net.SplitHostPort(req.RemoteAddr)
+	clientPort, _ := strconv.Atoi(port)
+
+	tx.ProcessConnection(host, clientPort, "", 0)
+	tx.ProcessURI(req.URL.RequestURI(), req.Method, req.Proto)
+
+	for name, values := range req.Header {
+		for _, value := range values {
+			tx.AddRequestHeader(name, value)
+		}
+	}
+
+	// the Host and Transfer-Encoding headers are not part of the headers of the request once parsed.
+	if req.Host != "" {
+		tx.AddRequestHeader("Host", req.Host)
+		tx.SetServerName(req.Host)
+	}
+
+	if len(req.TransferEncoding) > 0 {
+		tx.AddRequestHeader("Transfer-Encoding", req.TransferEncoding[0])
+	}
+
+	if interruption := tx.ProcessRequestHeaders(); interruption != nil {
+		return interruption, nil
+	}
+
+	if tx.IsRequestBodyAccessible() && req.Body != nil && req.Body != http.NoBody {
+		interruption, _, err := tx.ReadRequestBodyFrom(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+
+		if interruption != nil {
+			return interruption, nil
+		}
+
+		inspected, err := tx.RequestBodyReader()
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+
+		// the part of the body beyond the inspection limit is forwarded after the inspected one.
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(inspected, req.Body), req.Body}
+	}
+
+	return tx.ProcessRequestBody()
+}
+
+// matchedRules returns the ID and the message of the rules matched by the request, skipping the ones without message,
+// such as the rules setting up the Core Rule Set.
+func matchedRules(tx types.Transaction) []string {
+	var matched []string
+	for _, rule := range tx.MatchedRules() {
+		if rule.Message() == "" {
+			continue
+		}
+
+		matched = append(matched, fmt.Sprintf("%d: %s", rule.Rule().ID(), rule.Message()))
+	}
+
+	return matched
+}
+
+// rulesFS is the file system the rule files are read from,
+// the files of the Core Rule Set, prefixed by @, being read from the Core Rule Set embedded in Traefik.
+type rulesFS struct{}
+
+func (rulesFS) Open(name string) (fs.File, error) {
+	if crs, ok := crsPath(name); ok {
+		return coreruleset.FS.Open(crs)
+	}
+
+	return os.Open(name)
+}
+
+func (rulesFS) ReadFile(name string) ([]byte, error) {
+	if crs, ok := crsPath(name); ok {
+		return fs.ReadFile(coreruleset.FS, crs)
+	}
+
+	return os.ReadFile(name)
+}
+
+func (rulesFS) Glob(pattern string) ([]string, error) {
+	if crs, ok := crsPath(pattern); ok {
+		return fs.Glob(coreruleset.FS, crs)
+	}
+
+	return filepath.Glob(pattern)
+}
+
+// crsPath returns the path in the embedded Core Rule Set of a path with a segment prefixed by @,
+// the included paths being relative to the directory of the file including them.
+func crsPath(name string) (string, bool) {
+	for i := 0; i < len(name); i++ {
+		if name[i] == '@' && (i == 0 || name[i-1] == '/') {
+			return name[i:], true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,177 @@
+package waf
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
+)
+
+const blockAdminRule = `SecRule REQUEST_URI "@beginsWith /admin" "id:1001,phase:1,deny,status:403,log,msg:'Admin access'"`
+
+func TestNew_invalidConfig(t *testing.T) {
+	_, err := New(context.Background(), http.NotFoundHandler(), dynamic.WAF{}, "waf")
+	assert.Error(t, err)
+
+	_, err = New(context.Background(), http.NotFoundHandler(), dynamic.WAF{Directives: []string{"SecRule"}}, "waf")
+	assert.Error(t, err)
+}
+
+func TestWAF(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		config             dynamic.WAF
+		method             string
+		target             string
+		body               string
+		expectedStatus     int
+		expectedMatched    []string
+		expectedInterrupt  interface{}
+		expectedBodyPassed bool
+	}{
+		{
+			desc:           "request let through",
+			config:         dynamic.WAF{Directives: []string{blockAdminRule}},
+			target:         "/public",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:              "request blocked",
+			config:            dynamic.WAF{Directives: []string{blockAdminRule}},
+			target:            "/admin/users",
+			expectedStatus:    http.StatusForbidden,
+			expectedMatched:   []string{"1001: Admin access"},
+			expectedInterrupt: 1001,
+		},
+		{
+			desc:            "request detected",
+			config:          dynamic.WAF{Directives: []string{blockAdminRule}, DetectionOnly: true},
+			target:          "/admin/users",
+			expectedStatus:  http.StatusOK,
+			expectedMatched: []string{"1001: Admin access"},
+		},
+		{
+			desc: "request body inspected",
+			config: dynamic.WAF{Directives: []string{
+				"SecRequestBodyAccess On",
+				`SecRule REQUEST_BODY "@contains drop table" "id:1002,phase:2,deny,status:403,log,msg:'SQL in body'"`,
+			}},
+			method:            http.MethodPost,
+			target:            "/",
+			body:              "q=drop table users",
+			expectedStatus:    http.StatusForbidden,
+			expectedMatched:   []string{"1002: SQL in body"},
+			expectedInterrupt: 1002,
+		},
+		{
+			desc: "request body forwarded",
+			config: dynamic.WAF{Directives: []string{
+				"SecRequestBodyAccess On",
+				`SecRule REQUEST_BODY "@contains drop table" "id:1002,phase:2,deny,status:403,log,msg:'SQL in body'"`,
+			}},
+			method:             http.MethodPost,
+			target:             "/",
+			body:               "q=select 1",
+			expectedStatus:     http.StatusOK,
+			expectedBodyPassed: true,
+		},
+		{
+			desc:              "core rule set",
+			config:            dynamic.WAF{CRS: true},
+			target:            "/?id=1%27%20OR%20%271%27=%271",
+			expectedStatus:    http.StatusForbidden,
+			expectedInterrupt: 949110,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			var body string
+			next := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				data, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+				body = string(data)
+			})
+
+			handler, err := New(context.Background(), next, test.config, "waf")
+			require.NoError(t, err)
+
+			method := test.method
+			if method == "" {
+				method = http.MethodGet
+			}
+
+			req := httptest.NewRequest(method, test.target, strings.NewReader(test.body))
+			req.Header.Set("User-Agent", "waf-test")
+			req.Header.Set("Accept", "*/*")
+			if test.body != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+
+			logData := &accesslog.LogData{Core: accesslog.CoreLogData{}}
+			req = req.WithContext(context.WithValue(req.Context(), accesslog.DataTableKey, logData))
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedInterrupt, logData.Core[accesslog.WAFInterruptingRule])
+
+			if test.expectedMatched != nil {
+				assert.Equal(t, test.expectedMatched, logData.Core[accesslog.WAFMatchedRules])
+			}
+
+			if test.expectedBodyPassed {
+				assert.Equal(t, test.body, body)
+			}
+		})
+	}
+}
+
+func TestWAF_ruleFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "admin.conf"), []byte(blockAdminRule), 0o600)
+	require.NoError(t, err)
+
+	handler, err := New(context.Background(), http.NotFoundHandler(), dynamic.WAF{
+		Directives: []string{"Include " + filepath.Join(dir, "*.conf")},
+	}, "waf")
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/admin", nil))
+
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func Test_crsPath(t *testing.T) {
+	testCases := []struct {
+		name     string
+		expected string
+		ok       bool
+	}{
+		{name: "@owasp_crs/*.conf", expected: "@owasp_crs/*.conf", ok: true},
+		{name: "/etc/traefik/@owasp_crs/REQUEST-911-METHOD-ENFORCEMENT.conf", expected: "@owasp_crs/REQUEST-911-METHOD-ENFORCEMENT.conf", ok: true},
+		{name: "/etc/traefik/rules.conf"},
+		{name: "/etc/traefik/admin@example.conf"},
+	}
+
+	for _, test := range testCases {
+		path, ok := crsPath(test.name)
+		assert.Equal(t, test.ok, ok, test.name)
+		assert.Equal(t, test.expected, path, test.name)
+	}
+}
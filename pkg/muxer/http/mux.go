@@ -81,6 +81,37 @@ func (m *Muxer) AddRoute(rule string, priority int, handler http.Handler) error
 	return nil
 }
 
+// NewRuleMatcher returns a function reporting whether a request matches the rule,
+// which has the syntax of the router rules.
+func NewRuleMatcher(rule string) (func(req *http.Request) bool, error) {
+	var matchers []string
+	for matcher := range httpFuncs {
+		matchers = append(matchers, matcher)
+	}
+
+	parser, err := rules.NewParser(matchers)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating parser: %w", err)
+	}
+
+	parse, err := parser.Parse(rule)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing rule %s: %w", rule, err)
+	}
+
+	buildTree, ok := parse.(rules.TreeBuilder)
+	if !ok {
+		return nil, fmt.Errorf("error while parsing rule %s", rule)
+	}
+
+	var tree matchersTree
+	if err := tree.addRule(buildTree()); err != nil {
+		return nil, fmt.Errorf("error while adding rule %s: %w", rule, err)
+	}
+
+	return tree.match, nil
+}
+
 // ParseDomains extract domains from rule.
 func ParseDomains(rule string) ([]string, error) {
 	var matchers []string
@@ -558,3 +558,46 @@ func TestGetRulePriority(t *testing.T) {
 		})
 	}
 }
+
+func TestNewRuleMatcher(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		rule        string
+		expectedErr bool
+		matching    bool
+	}{
+		{
+			desc:     "matching rule",
+			rule:     "Method(`POST`) && Header(`X-Canary`, `true`)",
+			matching: true,
+		},
+		{
+			desc: "non matching rule",
+			rule: "PathPrefix(`/api`)",
+		},
+		{
+			desc:        "invalid rule",
+			rule:        "Foo(`bar`)",
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			match, err := NewRuleMatcher(test.rule)
+			if test.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "http://localhost/foo", nil)
+			req.Header.Set("X-Canary", "true")
+
+			assert.Equal(t, test.matching, match(req))
+		})
+	}
+}
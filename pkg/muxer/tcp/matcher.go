@@ -80,6 +80,10 @@ func hostSNI(tree *matchersTree, hosts ...string) error {
 		return nil
 	}
 
+	if strings.HasPrefix(host, "*.") {
+		return wildcardHostSNI(tree, host)
+	}
+
 	if !hostOrIP.MatchString(host) {
 		return fmt.Errorf("invalid value for HostSNI matcher, %q is not a valid hostname", host)
 	}
@@ -102,6 +106,28 @@ func hostSNI(tree *matchersTree, hosts ...string) error {
 	return nil
 }
 
+// wildcardHostSNI checks if the SNI Host of the connection is a direct subdomain of the matcher wildcard host domain,
+// as a wildcard certificate would, e.g. *.example.com matches foo.example.com, but neither example.com nor foo.bar.example.com.
+func wildcardHostSNI(tree *matchersTree, host string) error {
+	// trim trailing period in case of FQDN
+	domain := strings.TrimSuffix(strings.TrimPrefix(host, "*"), ".")
+
+	// the wildcard is not allowed on a top-level domain, e.g. *.com.
+	if !hostOrIP.MatchString(domain) || strings.Count(domain, ".") < 2 {
+		return fmt.Errorf("invalid value for HostSNI matcher, %q is not a valid wildcard hostname", host)
+	}
+
+	tree.matcher = func(meta ConnData) bool {
+		serverName := strings.TrimSuffix(meta.serverName, ".")
+
+		label, found := strings.CutSuffix(serverName, domain)
+
+		return found && label != "" && !strings.Contains(label, ".")
+	}
+
+	return nil
+}
+
 // hostSNIRegexp checks if the SNI Host of the connection matches the matcher host regexp.
 func hostSNIRegexp(tree *matchersTree, templates ...string) error {
 	template := templates[0]
@@ -134,6 +134,48 @@ func Test_HostSNI(t *testing.T) {
 			serverName: "foo.example.com",
 			match:      true,
 		},
+		{
+			desc:     "Invalid HostSNI matcher (wildcard not on the first label)",
+			rule:     "HostSNI(`foo.*.example.com`)",
+			buildErr: true,
+		},
+		{
+			desc:     "Invalid HostSNI matcher (partial wildcard label)",
+			rule:     "HostSNI(`*foo.example.com`)",
+			buildErr: true,
+		},
+		{
+			desc:       "Matching wildcard host",
+			rule:       "HostSNI(`*.example.com`)",
+			serverName: "foo.example.com",
+			match:      true,
+		},
+		{
+			desc:       "Matching wildcard host with trailing dot",
+			rule:       "HostSNI(`*.example.com.`)",
+			serverName: "foo.example.com.",
+			match:      true,
+		},
+		{
+			desc:       "No matching wildcard host with the wildcard domain",
+			rule:       "HostSNI(`*.example.com`)",
+			serverName: "example.com",
+		},
+		{
+			desc:       "No matching wildcard host with a sub-subdomain",
+			rule:       "HostSNI(`*.example.com`)",
+			serverName: "foo.bar.example.com",
+		},
+		{
+			desc:       "No matching wildcard host with another domain",
+			rule:       "HostSNI(`*.example.com`)",
+			serverName: "fooexample.com",
+		},
+		{
+			desc:       "No matching wildcard host and empty server name",
+			rule:       "HostSNI(`*.example.com`)",
+			serverName: "",
+		},
 	}
 
 	for _, test := range testCases {
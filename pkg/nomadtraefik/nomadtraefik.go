@@ -0,0 +1,256 @@
+// Package nomadtraefik embeds a minimal Nomad-aware reverse proxy into other Go programs.
+//
+// The proxy runs the Nomad provider and the routing core only:
+// there is no dashboard, API, metrics, ACME or other providers.
+package nomadtraefik
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+	"github.com/traefik/traefik/v3/pkg/provider/aggregator"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
+	"github.com/traefik/traefik/v3/pkg/provider/traefik"
+	"github.com/traefik/traefik/v3/pkg/safe"
+	"github.com/traefik/traefik/v3/pkg/server"
+	"github.com/traefik/traefik/v3/pkg/server/middleware"
+	"github.com/traefik/traefik/v3/pkg/server/service"
+	"github.com/traefik/traefik/v3/pkg/tcp"
+	traefiktls "github.com/traefik/traefik/v3/pkg/tls"
+)
+
+// Config is the configuration of an embedded proxy.
+type Config struct {
+	// EntryPoints are the entry points of the proxy, by name.
+	// The unset options of the entry points take their default values.
+	EntryPoints static.EntryPoints
+	// Nomad is the configuration of the Nomad provider, its unset options take their default values.
+	Nomad *nomad.ProviderBuilder
+	// ServersTransport is the configuration of the transport between the proxy and the Nomad services.
+	ServersTransport *static.ServersTransport
+	// ProvidersThrottleDuration is the minimum duration between two configuration reloads, it defaults to 2s.
+	ProvidersThrottleDuration ptypes.Duration
+}
+
+// Proxy is an embedded reverse proxy routing to the services discovered in Nomad.
+type Proxy struct {
+	routinesPool   *safe.Pool
+	chainBuilder   *middleware.ChainBuilder
+	watcher        *server.ConfigurationWatcher
+	entryPointsTCP server.TCPEntryPoints
+	entryPointsUDP server.UDPEntryPoints
+
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopped   chan struct{}
+}
+
+// New creates a proxy from the given configuration, listening on the entry points once started.
+// The proxy stops when the context is done.
+func New(ctx context.Context, cfg Config) (*Proxy, error) {
+	if len(cfg.EntryPoints) == 0 {
+		return nil, errors.New("at least one entry point is required")
+	}
+
+	staticConfiguration := newStaticConfiguration(cfg)
+
+	providerAggregator := aggregator.NewProviderAggregator(*staticConfiguration.Providers)
+
+	// adds the internal provider for the default servers transport and the entry points redirections.
+	if err := providerAggregator.AddProvider(traefik.New(staticConfiguration)); err != nil {
+		return nil, err
+	}
+
+	routinesPool := safe.NewPool(ctx)
+	metricsRegistry := metrics.NewVoidRegistry()
+
+	entryPointsTCP, err := server.NewTCPEntryPoints(staticConfiguration.EntryPoints, nil, metricsRegistry)
+	if err != nil {
+		return nil, err
+	}
+
+	entryPointsUDP, err := server.NewUDPEntryPoints(staticConfiguration.EntryPoints)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsManager := traefiktls.NewManager()
+	roundTripperManager := service.NewRoundTripperManager(nil)
+	dialerManager := tcp.NewDialerManager(nil)
+
+	managerFactory := service.NewManagerFactory(staticConfiguration, routinesPool, metricsRegistry, roundTripperManager, nil, nil, nil, nil)
+
+	chainBuilder := middleware.NewChainBuilder(metricsRegistry, nil, nil)
+	routerFactory := server.NewRouterFactory(staticConfiguration, managerFactory, tlsManager, chainBuilder, nil, metricsRegistry, dialerManager)
+
+	watcher := server.NewConfigurationWatcher(routinesPool, providerAggregator, defaultEntryPoints(staticConfiguration.EntryPoints), "internal", staticConfiguration.Providers.Precedence)
+
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		tlsManager.UpdateConfigs(context.Background(), conf.TLS.Stores, conf.TLS.Options, conf.TLS.Certificates)
+	})
+
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		roundTripperManager.Update(conf.HTTP.ServersTransports)
+		dialerManager.Update(conf.TCP.ServersTransports)
+	})
+
+	watcher.AddListener(func(conf dynamic.Configuration) {
+		routers, udpRouters := routerFactory.CreateRouters(runtime.NewConfig(conf))
+
+		entryPointsTCP.Switch(routers)
+		entryPointsUDP.Switch(udpRouters)
+	})
+
+	p := &Proxy{
+		routinesPool:   routinesPool,
+		chainBuilder:   chainBuilder,
+		watcher:        watcher,
+		entryPointsTCP: entryPointsTCP,
+		entryPointsUDP: entryPointsUDP,
+		stopped:        make(chan struct{}),
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.Stop()
+		case <-p.stopped:
+		}
+	}()
+
+	return p, nil
+}
+
+// Start starts serving the entry points and watching the Nomad services.
+func (p *Proxy) Start() {
+	p.startOnce.Do(func() {
+		p.entryPointsTCP.Start()
+		p.entryPointsUDP.Start()
+		p.watcher.Start()
+	})
+}
+
+// Stop gracefully shuts down the entry points, and stops watching the Nomad services.
+func (p *Proxy) Stop() {
+	p.stopOnce.Do(func() {
+		p.entryPointsTCP.Stop()
+		p.entryPointsUDP.Stop()
+		p.routinesPool.Stop()
+		p.chainBuilder.Close()
+
+		close(p.stopped)
+	})
+}
+
+// Wait blocks until the proxy is stopped.
+func (p *Proxy) Wait() {
+	<-p.stopped
+}
+
+// Addr returns the address the TCP entry point listens on, useful when its configured port is 0.
+func (p *Proxy) Addr(entryPoint string) (net.Addr, error) {
+	ep, ok := p.entryPointsTCP[entryPoint]
+	if !ok {
+		return nil, fmt.Errorf("TCP entry point not found: %s", entryPoint)
+	}
+
+	return ep.Addr(), nil
+}
+
+func newStaticConfiguration(cfg Config) static.Configuration {
+	entryPoints := make(static.EntryPoints, len(cfg.EntryPoints))
+	for name, ep := range cfg.EntryPoints {
+		entryPoints[name] = withEntryPointDefaults(ep)
+	}
+
+	nomadBuilder := cfg.Nomad
+	if nomadBuilder == nil {
+		nomadBuilder = &nomad.ProviderBuilder{}
+		nomadBuilder.SetDefaults()
+	}
+
+	serversTransport := cfg.ServersTransport
+	if serversTransport == nil {
+		serversTransport = &static.ServersTransport{MaxIdleConnsPerHost: 200}
+	}
+
+	throttleDuration := cfg.ProvidersThrottleDuration
+	if throttleDuration <= 0 {
+		throttleDuration = ptypes.Duration(2 * time.Second)
+	}
+
+	return static.Configuration{
+		EntryPoints: entryPoints,
+		Providers: &static.Providers{
+			ProvidersThrottleDuration: throttleDuration,
+			Nomad:                     nomadBuilder,
+		},
+		ServersTransport: serversTransport,
+		TCPServersTransport: &static.TCPServersTransport{
+			DialTimeout:   ptypes.Duration(30 * time.Second),
+			DialKeepAlive: ptypes.Duration(15 * time.Second),
+		},
+	}
+}
+
+// withEntryPointDefaults returns a copy of the entry point, with the defaults set for the unset options.
+func withEntryPointDefaults(ep *static.EntryPoint) *static.EntryPoint {
+	defaults := &static.EntryPoint{}
+	defaults.SetDefaults()
+
+	if ep == nil {
+		return defaults
+	}
+
+	withDefaults := *ep
+	if withDefaults.Transport == nil {
+		withDefaults.Transport = defaults.Transport
+	}
+	if withDefaults.ForwardedHeaders == nil {
+		withDefaults.ForwardedHeaders = defaults.ForwardedHeaders
+	}
+	if withDefaults.UDP == nil {
+		withDefaults.UDP = defaults.UDP
+	}
+	if withDefaults.HTTP2 == nil {
+		withDefaults.HTTP2 = defaults.HTTP2
+	}
+
+	return &withDefaults
+}
+
+// defaultEntryPoints returns the TCP entry points used by the routers without entry points,
+// that is the entry points flagged as default, or all of them when none is.
+func defaultEntryPoints(entryPoints static.EntryPoints) []string {
+	var hasDefinedDefaults bool
+	for _, ep := range entryPoints {
+		if ep.AsDefault {
+			hasDefinedDefaults = true
+			break
+		}
+	}
+
+	var names []string
+	for name, ep := range entryPoints {
+		if hasDefinedDefaults && !ep.AsDefault {
+			continue
+		}
+
+		if protocol, err := ep.GetProtocol(); err == nil && protocol != "udp" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
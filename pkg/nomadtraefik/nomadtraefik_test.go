@@ -0,0 +1,138 @@
+package nomadtraefik
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
+)
+
+func TestProxy(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("whoami"))
+	}))
+	t.Cleanup(backend.Close)
+
+	host, port, err := net.SplitHostPort(strings.TrimPrefix(backend.URL, "http://"))
+	require.NoError(t, err)
+
+	nomadAPI := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/v1/services"):
+			_, _ = rw.Write([]byte(`[{"Namespace":"default","Services":[{"ServiceName":"whoami","Tags":["traefik.enable=true"]}]}]`))
+		case strings.HasSuffix(req.URL.Path, "/v1/service/whoami"):
+			_, _ = fmt.Fprintf(rw, `[{"ID":"id1","ServiceName":"whoami","Namespace":"default","JobID":"whoami","AllocID":"alloc1","Address":%q,"Port":%s,"Tags":["traefik.enable=true","traefik.http.routers.whoami.rule=Host(`+"`whoami.test`"+`)"]}]`, host, port)
+		}
+	}))
+	t.Cleanup(nomadAPI.Close)
+
+	nomadBuilder := &nomad.ProviderBuilder{}
+	nomadBuilder.SetDefaults()
+	nomadBuilder.Endpoint.Address = nomadAPI.URL
+	nomadBuilder.RefreshInterval = ptypes.Duration(100 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	proxy, err := New(ctx, Config{
+		EntryPoints:               static.EntryPoints{"web": {Address: "127.0.0.1:0"}},
+		Nomad:                     nomadBuilder,
+		ProvidersThrottleDuration: ptypes.Duration(10 * time.Millisecond),
+	})
+	require.NoError(t, err)
+
+	proxy.Start()
+
+	addr, err := proxy.Addr("web")
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+addr.String(), nil)
+	require.NoError(t, err)
+	req.Host = "whoami.test"
+
+	var body string
+	assert.Eventually(t, func() bool {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+		defer resp.Body.Close()
+
+		data, _ := io.ReadAll(resp.Body)
+		body = string(data)
+
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, "whoami", body)
+
+	cancel()
+
+	select {
+	case <-waitChan(proxy):
+	case <-time.After(5 * time.Second):
+		t.Fatal("the proxy did not stop with the context")
+	}
+
+	_, err = net.Dial("tcp", addr.String())
+	assert.Error(t, err)
+}
+
+func TestNew_noEntryPoint(t *testing.T) {
+	_, err := New(context.Background(), Config{})
+	require.Error(t, err)
+}
+
+func Test_defaultEntryPoints(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		entryPoints static.EntryPoints
+		expected    []string
+	}{
+		{
+			desc: "all the TCP entry points",
+			entryPoints: static.EntryPoints{
+				"web":       {Address: ":80"},
+				"websecure": {Address: ":443"},
+				"dns":       {Address: ":53/udp"},
+			},
+			expected: []string{"web", "websecure"},
+		},
+		{
+			desc: "entry points flagged as default",
+			entryPoints: static.EntryPoints{
+				"web":       {Address: ":80"},
+				"websecure": {Address: ":443", AsDefault: true},
+			},
+			expected: []string{"websecure"},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, defaultEntryPoints(test.entryPoints))
+		})
+	}
+}
+
+func waitChan(proxy *Proxy) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		proxy.Wait()
+		close(done)
+	}()
+	return done
+}
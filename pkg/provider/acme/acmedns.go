@@ -0,0 +1,124 @@
+package acme
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/cpu/goacmedns"
+	"github.com/go-acme/lego/v4/challenge"
+	"github.com/go-acme/lego/v4/providers/dns/acmedns"
+)
+
+// ACMEDNS holds the configuration of the delegation of the DNS challenges to an acme-dns server.
+// The domains delegate their _acme-challenge record to the acme-dns zone with a CNAME.
+type ACMEDNS struct {
+	APIBase string `description:"URL of the acme-dns server API." json:"apiBase,omitempty" toml:"apiBase,omitempty" yaml:"apiBase,omitempty" export:"true"`
+}
+
+// newACMEDNSProvider creates a DNS challenge provider updating the TXT records through the acme-dns server,
+// with the accounts registered on the first challenge of each domain, and kept in the store of the resolver.
+func newACMEDNSProvider(config *ACMEDNS, store Store, resolverName string) (challenge.Provider, error) {
+	if config.APIBase == "" {
+		return nil, errors.New("the acme-dns API base URL is required")
+	}
+
+	storage := &acmeDNSStorage{store: store, resolverName: resolverName}
+
+	return acmedns.NewDNSProviderClient(goacmedns.NewClient(config.APIBase), storage)
+}
+
+// acmeDNSStorage is a goacmedns.Storage keeping the acme-dns accounts in the store of a resolver.
+type acmeDNSStorage struct {
+	store        Store
+	resolverName string
+
+	// pending are the accounts put since the last save, indexed by domain.
+	pendingMu sync.Mutex
+	pending   map[string]goacmedns.Account
+}
+
+// Save persists the accounts put since the last save.
+func (s *acmeDNSStorage) Save() error {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	if len(s.pending) == 0 {
+		return nil
+	}
+
+	accounts, err := s.store.GetACMEDNSAccounts(s.resolverName)
+	if err != nil {
+		return err
+	}
+
+	merged := make(map[string]goacmedns.Account, len(accounts)+len(s.pending))
+	for domain, account := range accounts {
+		merged[domain] = account
+	}
+	for domain, account := range s.pending {
+		merged[domain] = account
+	}
+
+	if err := s.store.SaveACMEDNSAccounts(s.resolverName, merged); err != nil {
+		return err
+	}
+
+	s.pending = nil
+
+	return nil
+}
+
+// Put adds the account of the domain, persisted on the next save.
+func (s *acmeDNSStorage) Put(domain string, account goacmedns.Account) error {
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	if s.pending == nil {
+		s.pending = make(map[string]goacmedns.Account)
+	}
+	s.pending[domain] = account
+
+	return nil
+}
+
+// Fetch returns the account of the domain, or goacmedns.ErrDomainNotFound.
+func (s *acmeDNSStorage) Fetch(domain string) (goacmedns.Account, error) {
+	accounts, err := s.all()
+	if err != nil {
+		return goacmedns.Account{}, err
+	}
+
+	account, ok := accounts[domain]
+	if !ok {
+		return goacmedns.Account{}, goacmedns.ErrDomainNotFound
+	}
+
+	return account, nil
+}
+
+// FetchAll returns all the accounts, indexed by domain.
+// The accounts are empty when the store cannot be read.
+func (s *acmeDNSStorage) FetchAll() map[string]goacmedns.Account {
+	accounts, _ := s.all()
+	return accounts
+}
+
+func (s *acmeDNSStorage) all() (map[string]goacmedns.Account, error) {
+	accounts, err := s.store.GetACMEDNSAccounts(s.resolverName)
+	if err != nil {
+		return map[string]goacmedns.Account{}, err
+	}
+
+	s.pendingMu.Lock()
+	defer s.pendingMu.Unlock()
+
+	all := make(map[string]goacmedns.Account, len(accounts)+len(s.pending))
+	for domain, account := range accounts {
+		all[domain] = account
+	}
+	for domain, account := range s.pending {
+		all[domain] = account
+	}
+
+	return all, nil
+}
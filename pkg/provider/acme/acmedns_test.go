@@ -0,0 +1,68 @@
+package acme
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/cpu/goacmedns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestACMEDNSStorage(t *testing.T) {
+	store := NewLocalStore(filepath.Join(t.TempDir(), "acme.json"))
+
+	storage := &acmeDNSStorage{store: store, resolverName: "test"}
+
+	_, err := storage.Fetch("traefik.wtf")
+	require.ErrorIs(t, err, goacmedns.ErrDomainNotFound)
+
+	account := goacmedns.Account{
+		FullDomain: "d420c923-bbd7-4056-ab64-c3ca54c9b3cf.auth.example.org",
+		SubDomain:  "d420c923-bbd7-4056-ab64-c3ca54c9b3cf",
+		Username:   "c36f50e8-4632-44f0-83fe-e070fef28a10",
+		Password:   "htB9mR9DYgcu9bX_afHF62erXaH2TS7bg9KW3F7Z",
+		ServerURL:  "https://auth.example.org",
+	}
+
+	err = storage.Put("traefik.wtf", account)
+	require.NoError(t, err)
+
+	// the account put is fetched before being saved.
+	fetched, err := storage.Fetch("traefik.wtf")
+	require.NoError(t, err)
+	assert.Equal(t, account, fetched)
+
+	accounts, err := store.GetACMEDNSAccounts("test")
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+
+	err = storage.Save()
+	require.NoError(t, err)
+
+	accounts, err = store.GetACMEDNSAccounts("test")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]goacmedns.Account{"traefik.wtf": account}, accounts)
+
+	// the accounts are kept per resolver.
+	other := &acmeDNSStorage{store: store, resolverName: "other"}
+	assert.Empty(t, other.FetchAll())
+
+	err = other.Put("traefik.io", account)
+	require.NoError(t, err)
+	err = other.Save()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]goacmedns.Account{"traefik.wtf": account}, storage.FetchAll())
+}
+
+func Test_newACMEDNSProvider(t *testing.T) {
+	store := NewLocalStore(filepath.Join(t.TempDir(), "acme.json"))
+
+	_, err := newACMEDNSProvider(&ACMEDNS{}, store, "test")
+	require.Error(t, err)
+
+	provider, err := newACMEDNSProvider(&ACMEDNS{APIBase: "https://auth.example.org"}, store, "test")
+	require.NoError(t, err)
+	assert.NotNil(t, provider)
+}
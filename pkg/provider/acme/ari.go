@@ -0,0 +1,239 @@
+package acme
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultRenewalInfoRetryAfter is the delay before polling the renewal information of a certificate again,
+	// when the CA does not send a Retry-After header.
+	defaultRenewalInfoRetryAfter = 6 * time.Hour
+	// minRenewalInfoRetryAfter and maxRenewalInfoRetryAfter bound the Retry-After delays of the CA.
+	minRenewalInfoRetryAfter = time.Minute
+	maxRenewalInfoRetryAfter = 24 * time.Hour
+	// renewalInfoCheckInterval is the maximum interval between two checks of the certificates when the renewal information is enabled,
+	// so that a window moved forward by the CA, e.g. ahead of a revocation, is noticed in time.
+	renewalInfoCheckInterval = time.Hour
+)
+
+// RenewalInfo is the ACME Renewal Information (ARI) of a certificate: the window suggested by the CA to renew it.
+type RenewalInfo struct {
+	WindowStart    time.Time `json:"windowStart"`
+	WindowEnd      time.Time `json:"windowEnd"`
+	ExplanationURL string    `json:"explanationURL,omitempty"`
+	// NextPoll is the time after which the renewal information is polled again, as requested by the CA.
+	NextPoll time.Time `json:"nextPoll"`
+}
+
+type renewalInfoResponse struct {
+	SuggestedWindow struct {
+		Start time.Time `json:"start"`
+		End   time.Time `json:"end"`
+	} `json:"suggestedWindow"`
+	ExplanationURL string `json:"explanationURL"`
+}
+
+// renewalInfoCertID returns the identifier of the certificate in the renewal information requests:
+// the base64url encoded authority key identifier and serial number, separated by a dot.
+func renewalInfoCertID(crt *x509.Certificate) (string, error) {
+	if len(crt.AuthorityKeyId) == 0 {
+		return "", errors.New("the certificate has no authority key identifier")
+	}
+
+	// the serial is the content of its DER encoding, with a leading zero byte when its first bit is set.
+	serial := crt.SerialNumber.Bytes()
+	if len(serial) == 0 || serial[0]&0x80 != 0 {
+		serial = append([]byte{0}, serial...)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(crt.AuthorityKeyId) + "." + base64.RawURLEncoding.EncodeToString(serial), nil
+}
+
+// getRenewalInfoURL returns the renewal information endpoint of the CA, empty when the CA does not support ARI.
+// The directory of the CA is only fetched once.
+func (p *Provider) getRenewalInfoURL(ctx context.Context, client *http.Client) (string, error) {
+	p.renewalInfoMu.Lock()
+	defer p.renewalInfoMu.Unlock()
+
+	if p.renewalInfoURL != nil {
+		return *p.renewalInfoURL, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.CAServer, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fetching CA directory: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching CA directory: unexpected status code %d", resp.StatusCode)
+	}
+
+	var directory struct {
+		RenewalInfo string `json:"renewalInfo"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&directory); err != nil {
+		return "", fmt.Errorf("decoding CA directory: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(directory.RenewalInfo, "/")
+	p.renewalInfoURL = &endpoint
+
+	return endpoint, nil
+}
+
+// fetchRenewalInfo polls the renewal information of the certificate.
+func fetchRenewalInfo(ctx context.Context, client *http.Client, endpoint, certID string, now time.Time) (RenewalInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/"+certID, http.NoBody)
+	if err != nil {
+		return RenewalInfo{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return RenewalInfo{}, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return RenewalInfo{}, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var info renewalInfoResponse
+	if err = json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return RenewalInfo{}, fmt.Errorf("decoding renewal information: %w", err)
+	}
+
+	if info.SuggestedWindow.Start.IsZero() || info.SuggestedWindow.End.Before(info.SuggestedWindow.Start) {
+		return RenewalInfo{}, errors.New("invalid suggested window")
+	}
+
+	return RenewalInfo{
+		WindowStart:    info.SuggestedWindow.Start,
+		WindowEnd:      info.SuggestedWindow.End,
+		ExplanationURL: info.ExplanationURL,
+		NextPoll:       now.Add(parseRetryAfter(resp.Header.Get("Retry-After"), now)),
+	}, nil
+}
+
+// parseRetryAfter parses the Retry-After header, in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	retryAfter := defaultRenewalInfoRetryAfter
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		retryAfter = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		retryAfter = date.Sub(now)
+	}
+
+	switch {
+	case retryAfter < minRenewalInfoRetryAfter:
+		return minRenewalInfoRetryAfter
+	case retryAfter > maxRenewalInfoRetryAfter:
+		return maxRenewalInfoRetryAfter
+	default:
+		return retryAfter
+	}
+}
+
+// checkRenewalInfo returns the certificates whose renewal window, suggested by the CA, has started.
+// The renewal information is read from the store before being polled, and saved once polled,
+// so that the instances sharing the store do not poll the same certificates again.
+func (p *Provider) checkRenewalInfo(ctx context.Context, certificates []*CertAndStore, now time.Time) []*CertAndStore {
+	if !p.RenewalInfo || len(certificates) == 0 {
+		return nil
+	}
+
+	logger := log.Ctx(ctx)
+
+	if _, err := p.getClient(); err != nil {
+		logger.Error().Err(err).Msg("Unable to poll the renewal information")
+		return nil
+	}
+	client := p.httpClient
+
+	endpoint, err := p.getRenewalInfoURL(ctx, client)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Unable to get the renewal information endpoint of the CA")
+		return nil
+	}
+	if endpoint == "" {
+		logger.Debug().Msg("The CA does not provide renewal information")
+		return nil
+	}
+
+	stored, err := p.Store.GetRenewalInfo(p.ResolverName)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to read the renewal information from the store")
+	}
+
+	infos := make(map[string]RenewalInfo, len(certificates))
+	var changed bool
+	var toRenew []*CertAndStore
+
+	for _, cert := range certificates {
+		crt, err := getX509Certificate(ctx, &cert.Certificate)
+		if err != nil || crt == nil {
+			continue
+		}
+
+		certID, err := renewalInfoCertID(crt)
+		if err != nil {
+			logger.Debug().Err(err).Strs("domains", cert.Domain.ToStrArray()).Msg("Unable to poll the renewal information of the certificate")
+			continue
+		}
+
+		info, ok := stored[certID]
+		if !ok || !now.Before(info.NextPoll) {
+			polled, err := fetchRenewalInfo(ctx, client, endpoint, certID, now)
+			if err != nil {
+				logger.Warn().Err(err).Strs("domains", cert.Domain.ToStrArray()).Msg("Unable to poll the renewal information of the certificate")
+			} else {
+				info, ok = polled, true
+				changed = true
+			}
+		}
+
+		if !ok {
+			continue
+		}
+		infos[certID] = info
+
+		if !now.Before(info.WindowStart) {
+			event := logger.Info().Strs("domains", cert.Domain.ToStrArray()).Time("windowStart", info.WindowStart)
+			if info.ExplanationURL != "" {
+				event = event.Str("explanationURL", info.ExplanationURL)
+			}
+			event.Msg("The renewal window suggested by the CA has started, renewing the certificate")
+
+			toRenew = append(toRenew, cert)
+		}
+	}
+
+	// the renewal information of the certificates which are no longer in use is dropped.
+	if changed || len(infos) != len(stored) {
+		if err := p.Store.SaveRenewalInfo(p.ResolverName, infos); err != nil {
+			logger.Error().Err(err).Msg("Unable to save the renewal information to the store")
+		}
+	}
+
+	return toRenew
+}
@@ -0,0 +1,199 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/lego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+func Test_renewalInfoCertID(t *testing.T) {
+	// example of the ACME Renewal Information specification.
+	crt := &x509.Certificate{
+		AuthorityKeyId: []byte{0x69, 0x88, 0x5b, 0x6b, 0x87, 0x46, 0x40, 0x41, 0xe1, 0xb3, 0x7b, 0x84, 0x7b, 0xa0, 0xae, 0x2c, 0xde, 0x01, 0xc8, 0xd4},
+		SerialNumber:   big.NewInt(0x87654321),
+	}
+
+	certID, err := renewalInfoCertID(crt)
+	require.NoError(t, err)
+	assert.Equal(t, "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE", certID)
+
+	_, err = renewalInfoCertID(&x509.Certificate{SerialNumber: big.NewInt(1)})
+	require.Error(t, err)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc     string
+		value    string
+		expected time.Duration
+	}{
+		{
+			desc:     "missing",
+			expected: defaultRenewalInfoRetryAfter,
+		},
+		{
+			desc:     "seconds",
+			value:    "3600",
+			expected: time.Hour,
+		},
+		{
+			desc:     "HTTP date",
+			value:    now.Add(2 * time.Hour).Format(http.TimeFormat),
+			expected: 2 * time.Hour,
+		},
+		{
+			desc:     "too short",
+			value:    "1",
+			expected: minRenewalInfoRetryAfter,
+		},
+		{
+			desc:     "too long",
+			value:    "604800",
+			expected: maxRenewalInfoRetryAfter,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, parseRetryAfter(test.value, now))
+		})
+	}
+}
+
+func TestProvider_checkRenewalInfo(t *testing.T) {
+	now := time.Now()
+
+	dueCert, dueCertID := newRenewalInfoCertificate(t, "due.test", 1)
+	laterCert, laterCertID := newRenewalInfoCertificate(t, "later.test", 2)
+
+	var polls atomic.Int32
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/directory", func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(`{"renewalInfo": "` + server.URL + `/renewal-info/"}`))
+	})
+	mux.HandleFunc("/renewal-info/", func(rw http.ResponseWriter, req *http.Request) {
+		polls.Add(1)
+
+		start := now.Add(30 * 24 * time.Hour)
+		if strings.TrimPrefix(req.URL.Path, "/renewal-info/") == dueCertID {
+			start = now.Add(-time.Hour)
+		}
+
+		rw.Header().Set("Retry-After", "21600")
+		_, _ = rw.Write([]byte(`{"suggestedWindow": {"start": "` + start.Format(time.RFC3339) + `", "end": "` + start.Add(48*time.Hour).Format(time.RFC3339) + `"}, "explanationURL": "https://ca.test/incident"}`))
+	})
+	server = httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	store := NewLocalStore(filepath.Join(t.TempDir(), "acme.json"))
+	require.NoError(t, store.SaveRenewalInfo("test", map[string]RenewalInfo{"stale": {}}))
+
+	p := &Provider{
+		Configuration: &Configuration{CAServer: server.URL + "/directory", RenewalInfo: true},
+		ResolverName:  "test",
+		Store:         store,
+		client:        &lego.Client{},
+		httpClient:    server.Client(),
+	}
+
+	certificates := []*CertAndStore{dueCert, laterCert}
+
+	toRenew := p.checkRenewalInfo(context.Background(), certificates, now)
+	assert.Equal(t, []*CertAndStore{dueCert}, toRenew)
+	assert.EqualValues(t, 2, polls.Load())
+
+	stored, err := store.GetRenewalInfo("test")
+	require.NoError(t, err)
+	require.Len(t, stored, 2, "the renewal information of the certificates no longer in use is dropped")
+	assert.Equal(t, "https://ca.test/incident", stored[dueCertID].ExplanationURL)
+	assert.WithinDuration(t, now.Add(6*time.Hour), stored[laterCertID].NextPoll, time.Second)
+
+	// the renewal information is read from the store until the CA asks to poll it again.
+	toRenew = p.checkRenewalInfo(context.Background(), certificates, now.Add(time.Hour))
+	assert.Equal(t, []*CertAndStore{dueCert}, toRenew)
+	assert.EqualValues(t, 2, polls.Load())
+
+	toRenew = p.checkRenewalInfo(context.Background(), certificates, now.Add(7*time.Hour))
+	assert.Equal(t, []*CertAndStore{dueCert}, toRenew)
+	assert.EqualValues(t, 4, polls.Load())
+}
+
+func TestProvider_checkRenewalInfo_unsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(`{"newOrder": "https://ca.test/new-order"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	cert, _ := newRenewalInfoCertificate(t, "foo.test", 1)
+
+	p := &Provider{
+		Configuration: &Configuration{CAServer: server.URL, RenewalInfo: true},
+		ResolverName:  "test",
+		Store:         NewLocalStore(filepath.Join(t.TempDir(), "acme.json")),
+		client:        &lego.Client{},
+		httpClient:    server.Client(),
+	}
+
+	assert.Empty(t, p.checkRenewalInfo(context.Background(), []*CertAndStore{cert}, time.Now()))
+}
+
+func newRenewalInfoCertificate(t *testing.T, domain string, serial int64) (*CertAndStore, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:   big.NewInt(serial),
+		Subject:        pkix.Name{CommonName: domain},
+		DNSNames:       []string{domain},
+		NotBefore:      time.Now().Add(-time.Hour),
+		NotAfter:       time.Now().Add(90 * 24 * time.Hour),
+		SubjectKeyId:   []byte{1, 2, 3, 4},
+		AuthorityKeyId: []byte{1, 2, 3, 4},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	crt, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	certID, err := renewalInfoCertID(crt)
+	require.NoError(t, err)
+
+	return &CertAndStore{
+		Certificate: Certificate{
+			Domain:      types.Domain{Main: domain},
+			Certificate: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+			Key:         pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		},
+		Store: "default",
+	}, certID
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -84,7 +85,8 @@ func (c *ChallengeHTTP) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		domain, _, err := net.SplitHostPort(req.Host)
 		if err != nil {
 			logger.Debug().Err(err).Msg("Unable to split host and port. Fallback to request host.")
-			domain = req.Host
+			// the IPv6 addresses of the IP identifiers are bracketed in the host.
+			domain = strings.TrimSuffix(strings.TrimPrefix(req.Host, "["), "]")
 		}
 
 		tokenValue := c.getTokenValue(logger.WithContext(req.Context()), token, domain)
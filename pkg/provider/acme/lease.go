@@ -0,0 +1,141 @@
+package acme
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// leaseTTL is the duration of the lease of a resolver, after which another Traefik instance takes over the orders when it is not renewed.
+	leaseTTL = 30 * time.Second
+	// leaseRefreshInterval is the interval between two renewals of the lease, or two attempts to acquire it.
+	leaseRefreshInterval = leaseTTL / 3
+	// leaseWaitInterval is the interval between two checks of the orders waiting for the lease.
+	leaseWaitInterval = time.Second
+)
+
+// keepLease acquires, or renews, the lease of the resolver until the pool stops, and releases it then,
+// when the Store is shared with other Traefik instances.
+// Only the instance holding the lease orders and renews the certificates, the others serving the certificates it saves.
+func (p *Provider) keepLease(ctx context.Context, renewPeriod time.Duration) {
+	store, ok := p.Store.(leaser)
+	if !ok {
+		return
+	}
+
+	p.leaseHolder = leaseHolder()
+
+	p.refreshLease(ctx, store)
+
+	ticker := time.NewTicker(leaseRefreshInterval)
+	p.pool.GoCtx(func(ctxPool context.Context) {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if p.refreshLease(ctx, store) {
+					// the certificates the previous leader left to renew are renewed right away.
+					p.renewCertificates(ctx, renewPeriod)
+				}
+			case <-ctxPool.Done():
+				if err := store.ReleaseLease(p.ResolverName, p.leaseHolder); err != nil {
+					log.Ctx(ctx).Error().Err(err).Msg("Unable to release the lease of the resolver")
+				}
+				return
+			}
+		}
+	})
+}
+
+// refreshLease acquires, or renews, the lease of the resolver, and reports whether it was just acquired.
+// The followers reload the certificates saved by the leader, as does a new leader before taking over the orders.
+func (p *Provider) refreshLease(ctx context.Context, store leaser) bool {
+	logger := log.Ctx(ctx)
+
+	held, err := store.AcquireLease(p.ResolverName, p.leaseHolder, leaseTTL)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to acquire the lease of the resolver")
+	}
+
+	leader := p.holdsLease()
+	if !held || !leader {
+		p.reloadCertificates(ctx)
+	}
+
+	p.leaderMu.Lock()
+	p.leader = held
+	p.leaderMu.Unlock()
+
+	switch {
+	case held && !leader:
+		logger.Info().Msgf("Acquired the lease of the resolver as %s, ordering the certificates", p.leaseHolder)
+	case !held && leader:
+		logger.Warn().Msg("Lost the lease of the resolver, leaving the orders to the other Traefik instances")
+	}
+
+	return held && !leader
+}
+
+// holdsLease reports whether the instance orders and renews the certificates of the resolver,
+// which is always the case when the Store is not shared.
+func (p *Provider) holdsLease() bool {
+	if _, ok := p.Store.(leaser); !ok {
+		return true
+	}
+
+	p.leaderMu.RLock()
+	defer p.leaderMu.RUnlock()
+
+	return p.leader
+}
+
+// awaitLease waits for the instance to hold the lease of the resolver,
+// and reports whether the certificate of the domains still has to be ordered, as the leader may have obtained it in the meantime.
+func (p *Provider) awaitLease(ctx context.Context, domains []string) bool {
+	if !p.holdsLease() {
+		log.Ctx(ctx).Debug().Msgf("Waiting for the lease of the resolver, or for its leader to obtain the certificate of %v...", domains)
+	}
+
+	for !p.holdsLease() {
+		if p.certExists(append([]string(nil), domains...)) {
+			return false
+		}
+
+		time.Sleep(leaseWaitInterval)
+	}
+
+	return !p.certExists(append([]string(nil), domains...))
+}
+
+// reloadCertificates reads the certificates of the resolver from the Store, to serve the ones saved by the other Traefik instances.
+func (p *Provider) reloadCertificates(ctx context.Context) {
+	certificates, err := p.Store.GetCertificates(p.ResolverName)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to reload the ACME certificates")
+		return
+	}
+
+	p.certificatesMu.Lock()
+	defer p.certificatesMu.Unlock()
+
+	p.certificatesSyncedAt = time.Now()
+
+	if reflect.DeepEqual(certificates, p.certificates) {
+		return
+	}
+
+	p.certificates = certificates
+	p.configurationChan <- p.buildMessage()
+}
+
+// leaseHolder returns the identifier of the instance in the leases, its hostname followed by its process ID.
+func leaseHolder() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
@@ -0,0 +1,71 @@
+package acme
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-acme/lego/v4/certificate"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+func TestProvider_refreshLease(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	leader := newTestLeaseProvider(t, "leader")
+	follower := newTestLeaseProvider(t, "follower")
+
+	ctx := context.Background()
+
+	assert.True(t, leader.refreshLease(ctx, leader.Store.(leaser)))
+	assert.True(t, leader.holdsLease())
+
+	assert.False(t, follower.refreshLease(ctx, follower.Store.(leaser)))
+	assert.False(t, follower.holdsLease())
+
+	err := leader.addCertificateForDomain(types.Domain{Main: "traefik.wtf"}, &certificate.Resource{Certificate: []byte("cert"), PrivateKey: []byte("key")}, "default")
+	require.NoError(t, err)
+
+	// the follower serves the certificate obtained by the leader, instead of ordering it.
+	assert.False(t, follower.refreshLease(ctx, follower.Store.(leaser)))
+	assert.True(t, follower.certExists([]string{"traefik.wtf"}))
+	assert.False(t, follower.awaitLease(ctx, []string{"traefik.wtf"}))
+
+	// the renewals are left to the leader.
+	_ = f.countRequests("")
+	follower.renewCertificates(ctx, time.Hour)
+	assert.Equal(t, 0, f.countRequests("PUT"))
+
+	// the follower takes over the orders once the lease of the leader expires.
+	f.variables["traefik/acme/test/lease"][nomadLeaseExpiresAtItem] = time.Now().Add(-time.Second).UTC().Format(time.RFC3339Nano)
+
+	assert.True(t, follower.refreshLease(ctx, follower.Store.(leaser)))
+	assert.True(t, follower.holdsLease())
+	assert.True(t, follower.awaitLease(ctx, []string{"foo.traefik.wtf"}))
+
+	assert.False(t, leader.refreshLease(ctx, leader.Store.(leaser)))
+	assert.False(t, leader.holdsLease())
+}
+
+func TestProvider_holdsLease(t *testing.T) {
+	p := &Provider{Store: NewLocalStore("acme.json")}
+
+	// the instances not sharing their Store always order their certificates.
+	assert.True(t, p.holdsLease())
+}
+
+// newTestLeaseProvider returns a new Provider sharing the Nomad Variables of the other providers of the test.
+func newTestLeaseProvider(t *testing.T, holder string) *Provider {
+	t.Helper()
+
+	return &Provider{
+		Configuration:     &Configuration{},
+		ResolverName:      "test",
+		Store:             newTestNomadStore(t, "nomad://traefik/acme"),
+		leaseHolder:       holder,
+		configurationChan: make(chan dynamic.Message, 10),
+	}
+}
@@ -6,9 +6,11 @@ import (
 	"os"
 	"sync"
 
+	"github.com/cpu/goacmedns"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/logs"
 	"github.com/traefik/traefik/v3/pkg/safe"
+	"github.com/traefik/traefik/v3/pkg/upgrade"
 )
 
 var _ Store = (*LocalStore)(nil)
@@ -20,15 +22,37 @@ type LocalStore struct {
 
 	lock       sync.RWMutex
 	storedData map[string]*StoredData
+
+	health storeHealth
 }
 
 // NewLocalStore initializes a new LocalStore with a file name.
 func NewLocalStore(filename string) *LocalStore {
 	store := &LocalStore{filename: filename, saveDataChan: make(chan map[string]*StoredData)}
 	store.listenSaveAction()
+	upgrade.RegisterState(localStoreState(filename), store.snapshot)
 	return store
 }
 
+// localStoreState returns the name of the state of the store handed off on upgrade.
+func localStoreState(filename string) string {
+	return "acme:" + filename
+}
+
+// snapshot returns the stored data handed off to the new process on upgrade,
+// which may not be saved to the file yet.
+func (s *LocalStore) snapshot() ([]byte, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	// the new process reads the file itself.
+	if s.storedData == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(s.storedData)
+}
+
 func (s *LocalStore) save(resolverName string, storedData *StoredData) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -44,59 +68,81 @@ func (s *LocalStore) get(resolverName string) (*StoredData, error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
-	if s.storedData == nil {
-		s.storedData = map[string]*StoredData{}
+	if state, ok := upgrade.State(localStoreState(s.filename)); ok && s.storedData == nil {
+		if err := json.Unmarshal(state, &s.storedData); err != nil {
+			return nil, err
+		}
+	}
 
-		hasData, err := CheckFile(s.filename)
-		if err != nil {
+	if s.storedData == nil {
+		if err := s.health.record(s.load()); err != nil {
 			return nil, err
 		}
+	}
 
-		if hasData {
-			logger := log.With().Str(logs.ProviderName, "acme").Logger()
+	if s.storedData[resolverName] == nil {
+		s.storedData[resolverName] = &StoredData{}
+	}
+	return s.storedData[resolverName], nil
+}
 
-			f, err := os.Open(s.filename)
-			if err != nil {
-				return nil, err
-			}
-			defer f.Close()
+// load reads the stored data from the file. Is not thread safe, you should use `s.lock`.
+func (s *LocalStore) load() error {
+	s.storedData = map[string]*StoredData{}
 
-			file, err := io.ReadAll(f)
-			if err != nil {
-				return nil, err
-			}
+	hasData, err := CheckFile(s.filename)
+	if err != nil {
+		return err
+	}
 
-			if len(file) > 0 {
-				if err := json.Unmarshal(file, &s.storedData); err != nil {
-					return nil, err
-				}
-			}
+	if !hasData {
+		return nil
+	}
 
-			// Delete all certificates with no value
-			var certificates []*CertAndStore
-			for _, storedData := range s.storedData {
-				for _, certificate := range storedData.Certificates {
-					if len(certificate.Certificate.Certificate) == 0 || len(certificate.Key) == 0 {
-						logger.Debug().Msgf("Deleting empty certificate %v for %v", certificate, certificate.Domain.ToStrArray())
-						continue
-					}
-					certificates = append(certificates, certificate)
-				}
-				if len(certificates) < len(storedData.Certificates) {
-					storedData.Certificates = certificates
-
-					// we cannot pass s.storedData directly, map is reference type and as result
-					// we can face with race condition, so we need to work with objects copy
-					s.saveDataChan <- s.unSafeCopyOfStoredData()
-				}
-			}
+	logger := log.With().Str(logs.ProviderName, "acme").Logger()
+
+	f, err := os.Open(s.filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	file, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	if len(file) > 0 {
+		if err := json.Unmarshal(file, &s.storedData); err != nil {
+			return err
 		}
 	}
 
-	if s.storedData[resolverName] == nil {
-		s.storedData[resolverName] = &StoredData{}
+	// Delete all certificates with no value
+	var certificates []*CertAndStore
+	for _, storedData := range s.storedData {
+		for _, certificate := range storedData.Certificates {
+			if len(certificate.Certificate.Certificate) == 0 || len(certificate.Key) == 0 {
+				logger.Debug().Msgf("Deleting empty certificate %v for %v", certificate, certificate.Domain.ToStrArray())
+				continue
+			}
+			certificates = append(certificates, certificate)
+		}
+		if len(certificates) < len(storedData.Certificates) {
+			storedData.Certificates = certificates
+
+			// we cannot pass s.storedData directly, map is reference type and as result
+			// we can face with race condition, so we need to work with objects copy
+			s.saveDataChan <- s.unSafeCopyOfStoredData()
+		}
 	}
-	return s.storedData[resolverName], nil
+
+	return nil
+}
+
+// Status returns the state of the file of the store.
+func (s *LocalStore) Status() StoreStatus {
+	return s.health.status(StoreBackendFile, s.filename)
 }
 
 // listenSaveAction listens to a chan to store ACME data in json format into `LocalStore.filename`.
@@ -109,7 +155,7 @@ func (s *LocalStore) listenSaveAction() {
 				logger.Error().Err(err).Send()
 			}
 
-			err = os.WriteFile(s.filename, data, 0o600)
+			err = s.health.record(os.WriteFile(s.filename, data, 0o600))
 			if err != nil {
 				logger.Error().Err(err).Send()
 			}
@@ -117,11 +163,12 @@ func (s *LocalStore) listenSaveAction() {
 	})
 }
 
-// unSafeCopyOfStoredData creates maps copy of storedData. Is not thread safe, you should use `s.lock`.
+// unSafeCopyOfStoredData creates a deep copy of storedData. Is not thread safe, you should use `s.lock`.
+// The stored data of the resolvers are copied too, as they are modified by the Save methods while the copy is written to the file.
 func (s *LocalStore) unSafeCopyOfStoredData() map[string]*StoredData {
 	result := map[string]*StoredData{}
 	for k, v := range s.storedData {
-		result[k] = v
+		result[k] = v.copy()
 	}
 	return result
 }
@@ -171,3 +218,108 @@ func (s *LocalStore) SaveCertificates(resolverName string, certificates []*CertA
 
 	return nil
 }
+
+// GetPaused returns whether the resolver is paused.
+func (s *LocalStore) GetPaused(resolverName string) (bool, error) {
+	storedData, err := s.get(resolverName)
+	if err != nil {
+		return false, err
+	}
+
+	return storedData.Paused, nil
+}
+
+// SavePaused stores whether the resolver is paused.
+func (s *LocalStore) SavePaused(resolverName string, paused bool) error {
+	storedData, err := s.get(resolverName)
+	if err != nil {
+		return err
+	}
+
+	storedData.Paused = paused
+	s.save(resolverName, storedData)
+
+	return nil
+}
+
+// GetACMEDNSAccounts returns the acme-dns accounts registered by the resolver, indexed by domain.
+func (s *LocalStore) GetACMEDNSAccounts(resolverName string) (map[string]goacmedns.Account, error) {
+	storedData, err := s.get(resolverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return storedData.ACMEDNSAccounts, nil
+}
+
+// SaveACMEDNSAccounts stores the acme-dns accounts registered by the resolver.
+func (s *LocalStore) SaveACMEDNSAccounts(resolverName string, accounts map[string]goacmedns.Account) error {
+	storedData, err := s.get(resolverName)
+	if err != nil {
+		return err
+	}
+
+	storedData.ACMEDNSAccounts = accounts
+	s.save(resolverName, storedData)
+
+	return nil
+}
+
+// GetRenewalInfo returns the renewal information of the certificates of the resolver, indexed by certificate identifier.
+func (s *LocalStore) GetRenewalInfo(resolverName string) (map[string]RenewalInfo, error) {
+	storedData, err := s.get(resolverName)
+	if err != nil {
+		return nil, err
+	}
+
+	return storedData.RenewalInfo, nil
+}
+
+// SaveRenewalInfo stores the renewal information of the certificates of the resolver.
+func (s *LocalStore) SaveRenewalInfo(resolverName string, renewalInfo map[string]RenewalInfo) error {
+	storedData, err := s.get(resolverName)
+	if err != nil {
+		return err
+	}
+
+	storedData.RenewalInfo = renewalInfo
+	s.save(resolverName, storedData)
+
+	return nil
+}
+
+// copy returns a copy of the stored data, sharing neither its certificates, its maps, nor the fields modified by the Save methods.
+func (d *StoredData) copy() *StoredData {
+	if d == nil {
+		return nil
+	}
+
+	result := *d
+
+	// the certificates are updated in place by the Provider when renewed.
+	if d.Certificates != nil {
+		result.Certificates = make([]*CertAndStore, len(d.Certificates))
+		for i, certificate := range d.Certificates {
+			if certificate != nil {
+				c := *certificate
+				result.Certificates[i] = &c
+			}
+		}
+	}
+
+	if d.ACMEDNSAccounts != nil {
+		result.ACMEDNSAccounts = make(map[string]goacmedns.Account, len(d.ACMEDNSAccounts))
+		for domain, account := range d.ACMEDNSAccounts {
+			result.ACMEDNSAccounts[domain] = account
+		}
+	}
+
+	if d.RenewalInfo != nil {
+		result.RenewalInfo = make(map[string]RenewalInfo, len(d.RenewalInfo))
+		for id, info := range d.RenewalInfo {
+			result.RenewalInfo[id] = info
+		}
+	}
+
+	return &result
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/cpu/goacmedns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -85,3 +86,142 @@ func TestLocalStore_SaveAccount(t *testing.T) {
 
 	assert.Equal(t, expected, string(file))
 }
+
+func TestLocalStore_SavePaused(t *testing.T) {
+	acmeFile := filepath.Join(t.TempDir(), "acme.json")
+
+	s := NewLocalStore(acmeFile)
+
+	err := s.SavePaused("test", true)
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	file, err := os.ReadFile(acmeFile)
+	require.NoError(t, err)
+
+	expected := `{
+  "test": {
+    "Account": null,
+    "Certificates": null,
+    "Paused": true
+  }
+}`
+
+	assert.Equal(t, expected, string(file))
+
+	// A new store reading the same file must see the resolver as paused.
+	paused, err := NewLocalStore(acmeFile).GetPaused("test")
+	require.NoError(t, err)
+	assert.True(t, paused)
+
+	paused, err = NewLocalStore(acmeFile).GetPaused("other")
+	require.NoError(t, err)
+	assert.False(t, paused)
+}
+
+func TestLocalStore_SaveACMEDNSAccounts(t *testing.T) {
+	acmeFile := filepath.Join(t.TempDir(), "acme.json")
+
+	s := NewLocalStore(acmeFile)
+
+	err := s.SaveACMEDNSAccounts("test", map[string]goacmedns.Account{
+		"traefik.wtf": {FullDomain: "sub.auth.example.org", SubDomain: "sub", Username: "user", Password: "pass", ServerURL: "https://auth.example.org"},
+	})
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	file, err := os.ReadFile(acmeFile)
+	require.NoError(t, err)
+
+	expected := `{
+  "test": {
+    "Account": null,
+    "Certificates": null,
+    "ACMEDNSAccounts": {
+      "traefik.wtf": {
+        "fulldomain": "sub.auth.example.org",
+        "subdomain": "sub",
+        "username": "user",
+        "password": "pass",
+        "server_url": "https://auth.example.org"
+      }
+    }
+  }
+}`
+
+	assert.Equal(t, expected, string(file))
+
+	accounts, err := NewLocalStore(acmeFile).GetACMEDNSAccounts("test")
+	require.NoError(t, err)
+	assert.Len(t, accounts, 1)
+}
+
+func TestLocalStore_SaveRenewalInfo(t *testing.T) {
+	acmeFile := filepath.Join(t.TempDir(), "acme.json")
+
+	s := NewLocalStore(acmeFile)
+
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	err := s.SaveRenewalInfo("test", map[string]RenewalInfo{
+		"aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE": {WindowStart: start, WindowEnd: start.Add(48 * time.Hour), NextPoll: start.Add(-time.Hour)},
+	})
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	file, err := os.ReadFile(acmeFile)
+	require.NoError(t, err)
+
+	expected := `{
+  "test": {
+    "Account": null,
+    "Certificates": null,
+    "RenewalInfo": {
+      "aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE": {
+        "windowStart": "2024-03-01T00:00:00Z",
+        "windowEnd": "2024-03-03T00:00:00Z",
+        "nextPoll": "2024-02-29T23:00:00Z"
+      }
+    }
+  }
+}`
+
+	assert.Equal(t, expected, string(file))
+
+	renewalInfo, err := NewLocalStore(acmeFile).GetRenewalInfo("test")
+	require.NoError(t, err)
+	assert.Len(t, renewalInfo, 1)
+}
+
+func TestLocalStore_Status(t *testing.T) {
+	acmeFile := filepath.Join(t.TempDir(), "acme.json")
+
+	err := os.WriteFile(acmeFile, []byte("{corrupted"), 0o600)
+	require.NoError(t, err)
+
+	s := NewLocalStore(acmeFile)
+
+	status := s.Status()
+	assert.Equal(t, StoreBackendFile, status.Backend)
+	assert.Equal(t, acmeFile, status.Location)
+	assert.True(t, status.Healthy)
+
+	_, err = s.GetAccount("test")
+	require.Error(t, err)
+
+	status = s.Status()
+	assert.False(t, status.Healthy)
+	assert.NotEmpty(t, status.LastError)
+	assert.NotNil(t, status.LastErrorTime)
+
+	s = NewLocalStore(filepath.Join(t.TempDir(), "acme.json"))
+
+	_, err = s.GetAccount("test")
+	require.NoError(t, err)
+
+	status = s.Status()
+	assert.True(t, status.Healthy)
+	assert.NotNil(t, status.LastSuccess)
+}
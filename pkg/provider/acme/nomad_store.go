@@ -0,0 +1,849 @@
+package acme
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cpu/goacmedns"
+	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/logs"
+)
+
+// NomadStorageScheme is the scheme of the storage of the resolvers keeping their data in Nomad Variables.
+const NomadStorageScheme = "nomad://"
+
+// nomadStoreTimeout is the maximum duration of each request of the NomadStore to the Nomad API.
+const nomadStoreTimeout = 30 * time.Second
+
+// nomadStoreCASAttempts is the maximum number of check-and-set writes of a Variable modified concurrently by other Traefik instances.
+const nomadStoreCASAttempts = 5
+
+// errNomadConflict is returned by the check-and-set writes of a Variable modified since it was read.
+var errNomadConflict = errors.New("the Nomad Variable was modified concurrently")
+
+// The items of the Variables of a resolver.
+const (
+	nomadAccountItem         = "account"
+	nomadCertificateItem     = "certificate"
+	nomadPausedItem          = "paused"
+	nomadACMEDNSAccountsItem = "acmeDNSAccounts"
+	nomadRenewalInfoItem     = "renewalInfo"
+	nomadMigratedItem        = "migratedCertificates"
+	nomadLeaseHolderItem     = "holder"
+	nomadLeaseExpiresAtItem  = "expiresAt"
+)
+
+// The paths of the Nomad Variables are at most 128 characters long,
+// the path of the task and the name of the resolver being shortened to leave room for the Variables of the resolvers,
+// the longest being <path>/corrupt/<resolver>/certificates/<certificate>.
+const (
+	nomadTaskPathPrefix    = "nomad/jobs/"
+	nomadTaskPathMaxLength = 56
+	nomadResolverMaxLength = 16
+)
+
+// nomadPathInvalidChars are the characters not allowed in the paths of the Nomad Variables.
+var nomadPathInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9\-_~]`)
+
+var (
+	_ Store  = (*NomadStore)(nil)
+	_ leaser = (*NomadStore)(nil)
+)
+
+// NomadStore Stores implementation for Nomad Variables, shared by the Traefik instances.
+//
+// Each resolver keeps its account, each of its certificates, and the rest of its state in separate Variables,
+// under <path>/<resolver>/account, <path>/<resolver>/certificates/<certificate> and <path>/<resolver>/state,
+// so that reading the account does not transfer the certificates.
+// The instance ordering the certificates of a resolver holds the lease kept under <path>/<resolver>/lease.
+// The items which are not valid JSON are moved under <path>/corrupt, for the resolver to obtain them again.
+type NomadStore struct {
+	client *api.Client
+	path   string
+
+	// names are the original names of the shortened segments of the path, indexed by segment,
+	// saved under <path>/names along with the first write.
+	names     map[string]string
+	namesOnce sync.Once
+
+	// authoritativeRegion is the region the Variables are written to, when replicated to the other regions,
+	// and read from when the local region did not replicate the writes of the store yet.
+	authoritativeRegion string
+	writesLock          sync.Mutex
+	// writes are the last writes of the store, indexed by Variable path,
+	// so that the Variables of the local region older than these writes are not trusted.
+	writes map[string]nomadWrite
+
+	// fallback is the storage the data missing from the Variables is read from, nil without.
+	fallback *LocalStore
+
+	lock sync.Mutex
+	// digests are the digests of the certificates last read or written, indexed by Variable path,
+	// so that saving the certificates only writes the changed ones.
+	digests map[string]string
+
+	health storeHealth
+}
+
+// nomadWrite is a write of a Nomad Variable.
+type nomadWrite struct {
+	// index is the Raft index of the write in the authoritative region.
+	index   uint64
+	deleted bool
+}
+
+// nomadVariable is a Nomad Variable, the Nomad API client in use not providing the Variables endpoints yet.
+type nomadVariable struct {
+	Namespace   string            `json:",omitempty"`
+	Path        string            `json:",omitempty"`
+	ModifyIndex uint64            `json:",omitempty"`
+	Items       map[string]string `json:",omitempty"`
+}
+
+// NewNomadStore initializes a new NomadStore from a storage of the form
+// nomad://[<path>][?namespace=<namespace>&region=<region>&authoritativeRegion=<region>&fallback=<file>].
+// The Nomad API is reached as configured by the environment, as for the Nomad CLI.
+// Without a path, the Variables are kept under the path of the Nomad task running Traefik.
+func NewNomadStore(storage string) (*NomadStore, error) {
+	path, rawQuery, _ := strings.Cut(strings.TrimPrefix(storage, NomadStorageScheme), "?")
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, fmt.Errorf("parsing storage %q: %w", storage, err)
+	}
+
+	var names map[string]string
+
+	path = strings.Trim(path, "/")
+	if path == "" {
+		path, names, err = nomadTaskPath()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config := api.DefaultConfig()
+	if namespace := query.Get("namespace"); namespace != "" {
+		config.Namespace = namespace
+	}
+	if region := query.Get("region"); region != "" {
+		config.Region = region
+	}
+
+	client, err := api.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("creating Nomad client: %w", err)
+	}
+
+	store := &NomadStore{
+		client:              client,
+		path:                path,
+		names:               names,
+		authoritativeRegion: query.Get("authoritativeRegion"),
+		writes:              make(map[string]nomadWrite),
+		digests:             make(map[string]string),
+	}
+
+	if fallback := query.Get("fallback"); fallback != "" {
+		store.fallback = NewLocalStore(fallback)
+	}
+
+	return store, nil
+}
+
+// nomadTaskPath returns the path of the Variables of the Nomad task running Traefik,
+// and the original names of the segments shortened to fit the length of the Variable paths.
+func nomadTaskPath() (string, map[string]string, error) {
+	job, group, task := os.Getenv("NOMAD_JOB_NAME"), os.Getenv("NOMAD_GROUP_NAME"), os.Getenv("NOMAD_TASK_NAME")
+	if job == "" || group == "" || task == "" {
+		return "", nil, errors.New("the path of the Nomad Variables is required when Traefik does not run in a Nomad task")
+	}
+
+	segments, names := shortenPathSegments([]string{job, group, task}, nomadTaskPathMaxLength-len(nomadTaskPathPrefix))
+
+	return nomadTaskPathPrefix + strings.Join(segments, "/"), names, nil
+}
+
+// resolverPath returns the path of the Variables of the resolver.
+func (s *NomadStore) resolverPath(resolverName string) string {
+	segments, _ := shortenPathSegments([]string{resolverName}, nomadResolverMaxLength)
+	return s.path + "/" + segments[0]
+}
+
+// certificatePath returns the path of the Variable of the certificate,
+// identified by its TLS store and domains, which the Variable paths cannot hold as is.
+func (s *NomadStore) certificatePath(resolverName string, certificate *CertAndStore) string {
+	hash := sha256.Sum256([]byte(certificate.Store + "|" + strings.Join(certificate.Domain.ToStrArray(), ",")))
+	return s.resolverPath(resolverName) + "/certificates/" + hex.EncodeToString(hash[:16])
+}
+
+// GetAccount returns ACME Account.
+func (s *NomadStore) GetAccount(resolverName string) (*Account, error) {
+	return readThrough(s, resolverName, "/account", nomadAccountItem, (*LocalStore).GetAccount, s.SaveAccount)
+}
+
+// SaveAccount stores ACME Account.
+func (s *NomadStore) SaveAccount(resolverName string, account *Account) error {
+	data, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+
+	return s.write(s.resolverPath(resolverName)+"/account", map[string]string{nomadAccountItem: string(data)})
+}
+
+// GetCertificates returns ACME Certificates list.
+// With a fallback storage, its certificates missing from the Variables are written through to the Variables,
+// except the ones the Traefik instances removed since.
+func (s *NomadStore) GetCertificates(resolverName string) ([]*CertAndStore, error) {
+	certificates, err := s.readCertificates(resolverName)
+	if err != nil || s.fallback == nil {
+		return certificates, err
+	}
+
+	return s.migrateCertificates(resolverName, certificates)
+}
+
+// readCertificates returns the certificates of the Variables.
+func (s *NomadStore) readCertificates(resolverName string) ([]*CertAndStore, error) {
+	logger := log.With().Str(logs.ProviderName, "acme").Logger()
+
+	paths, err := s.list(s.resolverPath(resolverName) + "/certificates/")
+	if err != nil {
+		return nil, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var certificates []*CertAndStore
+	for _, path := range paths {
+		variable, err := s.read(path)
+		if err != nil {
+			return nil, err
+		}
+
+		// the certificate is removed since the Variables are listed.
+		if variable == nil {
+			continue
+		}
+
+		data := variable.Items[nomadCertificateItem]
+
+		var certificate CertAndStore
+		if err := json.Unmarshal([]byte(data), &certificate); err != nil {
+			if err := s.quarantine(path, nomadCertificateItem, data, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if len(certificate.Certificate.Certificate) == 0 || len(certificate.Key) == 0 {
+			logger.Debug().Msgf("Ignoring empty certificate %v for %v", certificate, certificate.Domain.ToStrArray())
+			continue
+		}
+
+		s.digests[path] = digest(data)
+		certificates = append(certificates, &certificate)
+	}
+
+	return certificates, nil
+}
+
+// SaveCertificates stores ACME Certificates list.
+// Only the changed certificates are written, and only the ones read or written by the store are removed when not in the list,
+// so that the certificates saved by the other Traefik instances in the meantime are kept.
+func (s *NomadStore) SaveCertificates(resolverName string, certificates []*CertAndStore) error {
+	items := make(map[string]string, len(certificates))
+	for _, certificate := range certificates {
+		data, err := json.Marshal(certificate)
+		if err != nil {
+			return err
+		}
+
+		items[s.certificatePath(resolverName, certificate)] = string(data)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for path, data := range items {
+		if s.digests[path] == digest(data) {
+			continue
+		}
+
+		if err := s.write(path, map[string]string{nomadCertificateItem: data}); err != nil {
+			return err
+		}
+
+		s.digests[path] = digest(data)
+	}
+
+	prefix := s.resolverPath(resolverName) + "/certificates/"
+	for path := range s.digests {
+		if _, ok := items[path]; ok || !strings.HasPrefix(path, prefix) {
+			continue
+		}
+
+		if err := s.delete(path); err != nil {
+			return err
+		}
+
+		delete(s.digests, path)
+	}
+
+	return nil
+}
+
+// GetPaused returns whether the resolver is paused.
+func (s *NomadStore) GetPaused(resolverName string) (bool, error) {
+	return readThrough(s, resolverName, "/state", nomadPausedItem, (*LocalStore).GetPaused, s.SavePaused)
+}
+
+// SavePaused stores whether the resolver is paused.
+func (s *NomadStore) SavePaused(resolverName string, paused bool) error {
+	return s.saveState(resolverName, nomadPausedItem, strconv.FormatBool(paused))
+}
+
+// GetACMEDNSAccounts returns the acme-dns accounts registered by the resolver, indexed by domain.
+func (s *NomadStore) GetACMEDNSAccounts(resolverName string) (map[string]goacmedns.Account, error) {
+	return readThrough(s, resolverName, "/state", nomadACMEDNSAccountsItem, (*LocalStore).GetACMEDNSAccounts, s.SaveACMEDNSAccounts)
+}
+
+// SaveACMEDNSAccounts stores the acme-dns accounts registered by the resolver.
+func (s *NomadStore) SaveACMEDNSAccounts(resolverName string, accounts map[string]goacmedns.Account) error {
+	data, err := json.Marshal(accounts)
+	if err != nil {
+		return err
+	}
+
+	return s.saveState(resolverName, nomadACMEDNSAccountsItem, string(data))
+}
+
+// GetRenewalInfo returns the renewal information of the certificates of the resolver, indexed by certificate identifier.
+func (s *NomadStore) GetRenewalInfo(resolverName string) (map[string]RenewalInfo, error) {
+	return readThrough(s, resolverName, "/state", nomadRenewalInfoItem, (*LocalStore).GetRenewalInfo, s.SaveRenewalInfo)
+}
+
+// SaveRenewalInfo stores the renewal information of the certificates of the resolver.
+func (s *NomadStore) SaveRenewalInfo(resolverName string, renewalInfo map[string]RenewalInfo) error {
+	data, err := json.Marshal(renewalInfo)
+	if err != nil {
+		return err
+	}
+
+	return s.saveState(resolverName, nomadRenewalInfoItem, string(data))
+}
+
+// AcquireLease acquires, or renews, the lease of the resolver for the holder until the TTL expires, and reports whether the holder holds it.
+// The lease is written with a check-and-set, so that a single instance acquires it once expired.
+func (s *NomadStore) AcquireLease(resolverName, holder string, ttl time.Duration) (bool, error) {
+	path := s.resolverPath(resolverName) + "/lease"
+
+	// the lease is read from the region the writes are sent to, for the check-and-set to apply to its last write.
+	variable, err := s.readRegion(path, s.authoritativeRegion)
+	if err != nil {
+		return false, err
+	}
+
+	var index uint64
+	if variable != nil {
+		index = variable.ModifyIndex
+
+		expiresAt, err := time.Parse(time.RFC3339Nano, variable.Items[nomadLeaseExpiresAtItem])
+		if variable.Items[nomadLeaseHolderItem] != holder && err == nil && time.Now().Before(expiresAt) {
+			return false, nil
+		}
+	}
+
+	err = s.writeCAS(path, map[string]string{
+		nomadLeaseHolderItem:    holder,
+		nomadLeaseExpiresAtItem: time.Now().Add(ttl).UTC().Format(time.RFC3339Nano),
+	}, index)
+	if errors.Is(err, errNomadConflict) {
+		// another instance acquired the lease in the meantime.
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// ReleaseLease releases the lease of the resolver when held by the holder, for another instance to acquire it without waiting for its expiry.
+func (s *NomadStore) ReleaseLease(resolverName, holder string) error {
+	path := s.resolverPath(resolverName) + "/lease"
+
+	variable, err := s.readRegion(path, s.authoritativeRegion)
+	if err != nil || variable == nil || variable.Items[nomadLeaseHolderItem] != holder {
+		return err
+	}
+
+	err = s.writeCAS(path, map[string]string{
+		nomadLeaseHolderItem:    holder,
+		nomadLeaseExpiresAtItem: time.Now().UTC().Format(time.RFC3339Nano),
+	}, variable.ModifyIndex)
+	if errors.Is(err, errNomadConflict) {
+		return nil
+	}
+
+	return err
+}
+
+// readThrough returns the item of the Variable of the resolver at the path, relative to the path of the resolver.
+// With a fallback storage, the item missing from the Variable is read from the fallback storage, and written through to the Variable.
+func readThrough[T any](s *NomadStore, resolverName, path, item string, fromFallback func(*LocalStore, string) (T, error), save func(string, T) error) (T, error) {
+	var value T
+
+	found, err := s.getItem(s.resolverPath(resolverName)+path, item, &value)
+	if err != nil || found || s.fallback == nil {
+		return value, err
+	}
+
+	value, err = fromFallback(s.fallback, resolverName)
+	if err != nil {
+		return value, fmt.Errorf("reading the fallback storage %s: %w", s.fallback.filename, err)
+	}
+
+	if reflect.ValueOf(&value).Elem().IsZero() {
+		return value, nil
+	}
+
+	log.Info().Str(logs.ProviderName, resolverName+".acme").
+		Msgf("Writing the %s of %s through to the Nomad Variables", item, s.fallback.filename)
+
+	if err := save(resolverName, value); err != nil {
+		return value, err
+	}
+
+	return value, nil
+}
+
+// getItem decodes the JSON item of the Variable into value, and reports whether it was found.
+// The value is left unchanged when the Variable or the item does not exist.
+func (s *NomadStore) getItem(path, item string, value interface{}) (bool, error) {
+	variable, err := s.read(path)
+	if err != nil {
+		return false, err
+	}
+
+	if variable == nil {
+		return false, nil
+	}
+
+	data, ok := variable.Items[item]
+	if !ok {
+		return false, nil
+	}
+
+	if err := json.Unmarshal([]byte(data), value); err != nil {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+
+		return false, s.quarantine(path, item, data, err)
+	}
+
+	return true, nil
+}
+
+// migrateCertificates writes the certificates of the fallback storage missing from the Variables through to the Variables,
+// and returns them along with the certificates of the Variables.
+// The certificates of the fallback storage are recorded in the state of the resolver once in the Variables,
+// so that they are not written again once removed.
+func (s *NomadStore) migrateCertificates(resolverName string, certificates []*CertAndStore) ([]*CertAndStore, error) {
+	local, err := s.fallback.GetCertificates(resolverName)
+	if err != nil {
+		return nil, fmt.Errorf("reading the fallback storage %s: %w", s.fallback.filename, err)
+	}
+
+	if len(local) == 0 {
+		return certificates, nil
+	}
+
+	var migrated []string
+	if _, err := s.getItem(s.resolverPath(resolverName)+"/state", nomadMigratedItem, &migrated); err != nil {
+		return nil, err
+	}
+
+	done := make(map[string]struct{}, len(migrated))
+	for _, path := range migrated {
+		done[path] = struct{}{}
+	}
+
+	stored := make(map[string]struct{}, len(certificates))
+	for _, certificate := range certificates {
+		stored[s.certificatePath(resolverName, certificate)] = struct{}{}
+	}
+
+	var written []*CertAndStore
+	recorded := len(migrated)
+	for _, certificate := range local {
+		path := s.certificatePath(resolverName, certificate)
+		if _, ok := done[path]; ok {
+			continue
+		}
+
+		done[path] = struct{}{}
+		migrated = append(migrated, path)
+
+		if _, ok := stored[path]; !ok {
+			written = append(written, certificate)
+		}
+	}
+
+	if len(migrated) == recorded {
+		return certificates, nil
+	}
+
+	if len(written) > 0 {
+		log.Info().Str(logs.ProviderName, resolverName+".acme").
+			Msgf("Writing %d certificate(s) of %s through to the Nomad Variables", len(written), s.fallback.filename)
+
+		certificates = append(certificates, written...)
+		if err := s.SaveCertificates(resolverName, certificates); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(migrated)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.saveState(resolverName, nomadMigratedItem, string(data)); err != nil {
+		return nil, err
+	}
+
+	return certificates, nil
+}
+
+// quarantine moves the item of the Variable, which is not valid JSON, to the same path under <path>/corrupt,
+// so that the resolver carries on as if the item did not exist, without losing its data.
+// It must be called with the lock held.
+func (s *NomadStore) quarantine(path, item, data string, cause error) error {
+	corruptPath := s.path + "/corrupt/" + strings.TrimPrefix(path, s.path+"/")
+
+	log.Error().Str(logs.ProviderName, "acme").Err(cause).
+		Msgf("The item %s of the Nomad Variable %s is corrupted, moving it to %s: the resolver obtains its data again", item, path, corruptPath)
+
+	corrupt, err := s.read(corruptPath)
+	if err != nil {
+		return err
+	}
+
+	items := map[string]string{item: data}
+	if corrupt != nil {
+		for k, v := range corrupt.Items {
+			if k != item {
+				items[k] = v
+			}
+		}
+	}
+
+	if err := s.write(corruptPath, items); err != nil {
+		return err
+	}
+
+	variable, err := s.read(path)
+	if err != nil {
+		return err
+	}
+
+	if variable == nil {
+		return nil
+	}
+
+	// the item may have been saved again in the meantime.
+	if variable.Items[item] != data {
+		return nil
+	}
+
+	delete(variable.Items, item)
+	delete(s.digests, path)
+
+	if len(variable.Items) == 0 {
+		return s.delete(path)
+	}
+
+	return s.write(path, variable.Items)
+}
+
+// saveState sets an item of the state Variable of the resolver, keeping its other items.
+// As the other Traefik instances set the other items of the same Variable, it is written with a check-and-set,
+// and the item is set again on the Variable they wrote in the meantime.
+func (s *NomadStore) saveState(resolverName, item, data string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	path := s.resolverPath(resolverName) + "/state"
+
+	variable, err := s.read(path)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		var index uint64
+		items := make(map[string]string)
+		if variable != nil {
+			index = variable.ModifyIndex
+			for k, v := range variable.Items {
+				items[k] = v
+			}
+		}
+		items[item] = data
+
+		err = s.writeCAS(path, items, index)
+		if !errors.Is(err, errNomadConflict) || attempt == nomadStoreCASAttempts {
+			return err
+		}
+
+		log.Debug().Str(logs.ProviderName, "acme").Msgf("The Nomad Variable %s was modified concurrently, setting the item %s again", path, item)
+
+		// the conflicting write is read from the region the writes are sent to, as the local region may not have replicated it yet.
+		variable, err = s.readRegion(path, s.authoritativeRegion)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// read returns the Variable at the path, nil when it does not exist.
+// The Variable is read from the authoritative region when the local region did not replicate the last write of the store yet.
+func (s *NomadStore) read(path string) (*nomadVariable, error) {
+	variable, err := s.readRegion(path, "")
+	if err != nil || s.authoritativeRegion == "" || !s.stale(path, variable) {
+		return variable, err
+	}
+
+	log.Debug().Str(logs.ProviderName, "acme").
+		Msgf("The Nomad Variable %s is not replicated yet, reading it from the %s region", path, s.authoritativeRegion)
+
+	return s.readRegion(path, s.authoritativeRegion)
+}
+
+// readRegion returns the Variable at the path in the region, the local one when empty, nil when it does not exist.
+func (s *NomadStore) readRegion(path, region string) (*nomadVariable, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), nomadStoreTimeout)
+	defer cancel()
+
+	var variable nomadVariable
+	if _, err := s.client.Raw().Query("/v1/var/"+path, &variable, (&api.QueryOptions{Region: region}).WithContext(ctx)); err != nil {
+		if isNomadNotFound(err) {
+			return nil, s.health.record(nil)
+		}
+
+		return nil, s.health.record(fmt.Errorf("reading Nomad Variable %s: %w", path, err))
+	}
+
+	return &variable, s.health.record(nil)
+}
+
+// Status returns the state of the Nomad Variables of the store.
+func (s *NomadStore) Status() StoreStatus {
+	return s.health.status(StoreBackendNomad, NomadStorageScheme+s.path)
+}
+
+// stale reports whether the Variable read from the local region is older than the last write of the store.
+func (s *NomadStore) stale(path string, variable *nomadVariable) bool {
+	s.writesLock.Lock()
+	defer s.writesLock.Unlock()
+
+	write, ok := s.writes[path]
+	switch {
+	case !ok:
+		return false
+	case variable == nil:
+		return !write.deleted
+	default:
+		return variable.ModifyIndex < write.index
+	}
+}
+
+// setWrite records the last write of the store to the Variable, when the Variables are replicated.
+func (s *NomadStore) setWrite(path string, write nomadWrite) {
+	if s.authoritativeRegion == "" {
+		return
+	}
+
+	s.writesLock.Lock()
+	defer s.writesLock.Unlock()
+
+	s.writes[path] = write
+}
+
+// writeOptions returns the options of the writes, sent to the authoritative region when the Variables are replicated.
+func (s *NomadStore) writeOptions(ctx context.Context) *api.WriteOptions {
+	return (&api.WriteOptions{Region: s.authoritativeRegion}).WithContext(ctx)
+}
+
+func (s *NomadStore) write(path string, items map[string]string) error {
+	return s.writeEndpoint(path, "/v1/var/"+path, items)
+}
+
+// writeCAS writes the Variable only when its index is still the one it was read with, 0 when it did not exist.
+// It returns errNomadConflict when the Variable was modified in the meantime.
+func (s *NomadStore) writeCAS(path string, items map[string]string, index uint64) error {
+	return s.writeEndpoint(path, "/v1/var/"+path+"?cas="+strconv.FormatUint(index, 10), items)
+}
+
+func (s *NomadStore) writeEndpoint(path, endpoint string, items map[string]string) error {
+	s.namesOnce.Do(s.writeNames)
+
+	ctx, cancel := context.WithTimeout(context.Background(), nomadStoreTimeout)
+	defer cancel()
+
+	var written nomadVariable
+	variable := nomadVariable{Path: path, Items: items}
+	if _, err := s.client.Raw().Write(endpoint, variable, &written, s.writeOptions(ctx)); err != nil {
+		if isNomadConflict(err) {
+			_ = s.health.record(nil)
+			return fmt.Errorf("writing Nomad Variable %s: %w", path, errNomadConflict)
+		}
+
+		return s.health.record(fmt.Errorf("writing Nomad Variable %s: %w", path, err))
+	}
+
+	s.setWrite(path, nomadWrite{index: written.ModifyIndex})
+
+	return s.health.record(nil)
+}
+
+// writeNames saves the original names of the shortened segments of the path, for the operators to find the Variables of a task.
+func (s *NomadStore) writeNames() {
+	if len(s.names) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), nomadStoreTimeout)
+	defer cancel()
+
+	path := s.path + "/names"
+	variable := nomadVariable{Path: path, Items: s.names}
+	if _, err := s.client.Raw().Write("/v1/var/"+path, variable, nil, s.writeOptions(ctx)); err != nil {
+		log.Error().Str(logs.ProviderName, "acme").Err(err).Msgf("Unable to save the names of the shortened segments of the Nomad Variables path %s", s.path)
+	}
+}
+
+func (s *NomadStore) delete(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), nomadStoreTimeout)
+	defer cancel()
+
+	meta, err := s.client.Raw().Delete("/v1/var/"+path, nil, s.writeOptions(ctx))
+	if err != nil {
+		if isNomadNotFound(err) {
+			return s.health.record(nil)
+		}
+
+		return s.health.record(fmt.Errorf("deleting Nomad Variable %s: %w", path, err))
+	}
+
+	s.setWrite(path, nomadWrite{index: meta.LastIndex, deleted: true})
+
+	return s.health.record(nil)
+}
+
+// list returns the sorted paths of the Variables under the prefix,
+// including the ones written by the store the local region did not replicate yet.
+func (s *NomadStore) list(prefix string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), nomadStoreTimeout)
+	defer cancel()
+
+	var variables []nomadVariable
+	if _, err := s.client.Raw().Query("/v1/vars?prefix="+url.QueryEscape(prefix), &variables, (&api.QueryOptions{}).WithContext(ctx)); err != nil {
+		return nil, s.health.record(fmt.Errorf("listing Nomad Variables %s: %w", prefix, err))
+	}
+
+	_ = s.health.record(nil)
+
+	listed := make(map[string]struct{}, len(variables))
+	paths := make([]string, 0, len(variables))
+	for _, variable := range variables {
+		listed[variable.Path] = struct{}{}
+		paths = append(paths, variable.Path)
+	}
+
+	s.writesLock.Lock()
+	for path, write := range s.writes {
+		if _, ok := listed[path]; !ok && !write.deleted && strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	s.writesLock.Unlock()
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+// shortenPathSegments returns the segments of a Variable path fitting, once joined by slashes, in maxLength characters.
+// The segments holding characters not allowed in the paths, or too long, are replaced by their allowed characters,
+// truncated, and suffixed by a hash of their name, so that they stay distinct.
+// It also returns the original names of the replaced segments, indexed by segment.
+func shortenPathSegments(names []string, maxLength int) ([]string, map[string]string) {
+	// the shortest names leave the part of the length they do not use to the longest ones.
+	order := make([]int, len(names))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return len(names[order[i]]) < len(names[order[j]]) })
+
+	segments := make([]string, len(names))
+	var replaced map[string]string
+
+	remaining := maxLength - (len(names) - 1)
+	for k, i := range order {
+		budget := remaining / (len(names) - k)
+
+		segment := nomadPathInvalidChars.ReplaceAllString(names[i], "_")
+		if segment != names[i] || len(segment) > budget {
+			hash := digest(names[i])[:8]
+			if keep := budget - len(hash) - 1; len(segment) > keep {
+				if keep < 0 {
+					keep = 0
+				}
+				segment = segment[:keep]
+			}
+
+			segment = strings.TrimPrefix(segment+"-"+hash, "-")
+
+			if replaced == nil {
+				replaced = make(map[string]string)
+			}
+			replaced[segment] = names[i]
+		}
+
+		segments[i] = segment
+		remaining -= len(segment)
+	}
+
+	return segments, replaced
+}
+
+// isNomadNotFound reports whether the Nomad API responded with a Not Found status,
+// which the Nomad API client only reports in the error message.
+func isNomadNotFound(err error) bool {
+	return strings.Contains(err.Error(), "Unexpected response code: 404")
+}
+
+// isNomadConflict reports whether the Nomad API responded with a Conflict status to a check-and-set write.
+func isNomadConflict(err error) bool {
+	return strings.Contains(err.Error(), "Unexpected response code: 409")
+}
+
+func digest(data string) string {
+	hash := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(hash[:])
+}
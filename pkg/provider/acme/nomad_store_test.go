@@ -0,0 +1,698 @@
+package acme
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cpu/goacmedns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+// fakeNomadVariables is an in-memory Nomad Variables API.
+type fakeNomadVariables struct {
+	mu        sync.Mutex
+	variables map[string]map[string]string
+	indexes   map[string]uint64
+	index     uint64
+	// replicas are the Variables of the other regions, served to the requests for these regions.
+	replicas map[string]*fakeNomadVariables
+	// requests are the requests received, as "<method> <path>".
+	requests []string
+	// unavailable makes the API respond with a 500 Internal Server Error.
+	unavailable bool
+	// beforeWrite is called before the next write of a Variable, to simulate the concurrent write of another instance.
+	beforeWrite func(path string)
+}
+
+func newFakeNomadVariables(t *testing.T) *fakeNomadVariables {
+	t.Helper()
+
+	f := &fakeNomadVariables{
+		variables: make(map[string]map[string]string),
+		indexes:   make(map[string]uint64),
+		replicas:  make(map[string]*fakeNomadVariables),
+	}
+
+	ts := httptest.NewServer(f)
+	t.Cleanup(ts.Close)
+
+	t.Setenv("NOMAD_ADDR", ts.URL)
+
+	return f
+}
+
+// replicate copies the Variables to the region.
+func (f *fakeNomadVariables) replicate(region string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	replica := &fakeNomadVariables{
+		variables: make(map[string]map[string]string),
+		indexes:   make(map[string]uint64),
+		index:     f.index,
+	}
+	for path, items := range f.variables {
+		replica.variables[path] = items
+		replica.indexes[path] = f.indexes[path]
+	}
+
+	f.replicas[region] = replica
+}
+
+func (f *fakeNomadVariables) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, req.Method+" "+req.URL.Path)
+
+	if f.unavailable {
+		http.Error(rw, "no cluster leader", http.StatusInternalServerError)
+		return
+	}
+
+	if replica, ok := f.replicas[req.URL.Query().Get("region")]; ok {
+		replica.serve(rw, req)
+		return
+	}
+
+	f.serve(rw, req)
+}
+
+func (f *fakeNomadVariables) serve(rw http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/v1/vars" {
+		prefix := req.URL.Query().Get("prefix")
+
+		variables := []nomadVariable{}
+		for path := range f.variables {
+			if strings.HasPrefix(path, prefix) {
+				variables = append(variables, nomadVariable{Path: path})
+			}
+		}
+		sort.Slice(variables, func(i, j int) bool { return variables[i].Path < variables[j].Path })
+
+		_ = json.NewEncoder(rw).Encode(variables)
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v1/var/")
+
+	switch req.Method {
+	case http.MethodGet:
+		items, ok := f.variables[path]
+		if !ok {
+			http.Error(rw, "variable not found", http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(rw).Encode(nomadVariable{Path: path, ModifyIndex: f.indexes[path], Items: items})
+
+	case http.MethodPut:
+		var variable nomadVariable
+		if err := json.NewDecoder(req.Body).Decode(&variable); err != nil {
+			http.Error(rw, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if f.beforeWrite != nil {
+			beforeWrite := f.beforeWrite
+			f.beforeWrite = nil
+			beforeWrite(path)
+		}
+
+		if cas := req.URL.Query().Get("cas"); cas != "" {
+			index, err := strconv.ParseUint(cas, 10, 64)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if index != f.indexes[path] {
+				rw.WriteHeader(http.StatusConflict)
+				_ = json.NewEncoder(rw).Encode(nomadVariable{Path: path, ModifyIndex: f.indexes[path], Items: f.variables[path]})
+				return
+			}
+		}
+
+		f.index++
+		f.variables[path] = variable.Items
+		f.indexes[path] = f.index
+
+		variable.ModifyIndex = f.index
+		_ = json.NewEncoder(rw).Encode(variable)
+
+	case http.MethodDelete:
+		f.index++
+		delete(f.variables, path)
+		delete(f.indexes, path)
+
+		rw.Header().Set("X-Nomad-Index", strconv.FormatUint(f.index, 10))
+	}
+}
+
+// countRequests returns the number of requests received matching the prefix, and resets the received requests.
+func (f *fakeNomadVariables) countRequests(prefix string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var count int
+	for _, request := range f.requests {
+		if strings.HasPrefix(request, prefix) {
+			count++
+		}
+	}
+	f.requests = nil
+
+	return count
+}
+
+func TestNewNomadStore(t *testing.T) {
+	newFakeNomadVariables(t)
+
+	s, err := NewNomadStore("nomad:///traefik/acme/?namespace=ingress")
+	require.NoError(t, err)
+	assert.Equal(t, "traefik/acme", s.path)
+
+	_, err = NewNomadStore("nomad://")
+	require.Error(t, err)
+
+	t.Setenv("NOMAD_JOB_NAME", "ingress")
+	t.Setenv("NOMAD_GROUP_NAME", "proxy")
+	t.Setenv("NOMAD_TASK_NAME", "traefik")
+
+	s, err = NewNomadStore("nomad://")
+	require.NoError(t, err)
+	assert.Equal(t, "nomad/jobs/ingress/proxy/traefik", s.path)
+}
+
+func TestNewNomadStore_longNames(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	job := strings.Repeat("a", 60)
+	t.Setenv("NOMAD_JOB_NAME", job)
+	t.Setenv("NOMAD_GROUP_NAME", "proxy")
+	t.Setenv("NOMAD_TASK_NAME", "traefik")
+
+	s, err := NewNomadStore("nomad://")
+	require.NoError(t, err)
+	assert.Equal(t, "nomad/jobs/aaaaaaaaaaaaaaaaaaaaaa-11ee3912/proxy/traefik", s.path)
+
+	err = s.SavePaused("a-very-long-resolver-name", true)
+	require.NoError(t, err)
+
+	// the original names of the shortened segments are saved along with the first write.
+	assert.Equal(t, map[string]string{"aaaaaaaaaaaaaaaaaaaaaa-11ee3912": job}, f.variables["nomad/jobs/aaaaaaaaaaaaaaaaaaaaaa-11ee3912/proxy/traefik/names"])
+	assert.Contains(t, f.variables, "nomad/jobs/aaaaaaaaaaaaaaaaaaaaaa-11ee3912/proxy/traefik/a-very--2ed22a4e/state")
+}
+
+func Test_shortenPathSegments(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		names            []string
+		maxLength        int
+		expected         []string
+		expectedReplaced map[string]string
+	}{
+		{
+			desc:      "short names",
+			names:     []string{"ingress", "proxy", "traefik"},
+			maxLength: 45,
+			expected:  []string{"ingress", "proxy", "traefik"},
+		},
+		{
+			desc:             "invalid characters",
+			names:            []string{"my.job"},
+			maxLength:        16,
+			expected:         []string{"my_job-bc373230"},
+			expectedReplaced: map[string]string{"my_job-bc373230": "my.job"},
+		},
+		{
+			desc:             "long name",
+			names:            []string{strings.Repeat("a", 60), "proxy", "traefik"},
+			maxLength:        45,
+			expected:         []string{"aaaaaaaaaaaaaaaaaaaaaa-11ee3912", "proxy", "traefik"},
+			expectedReplaced: map[string]string{"aaaaaaaaaaaaaaaaaaaaaa-11ee3912": strings.Repeat("a", 60)},
+		},
+		{
+			desc:      "long names",
+			names:     []string{strings.Repeat("a", 60), strings.Repeat("b", 30), "traefik"},
+			maxLength: 45,
+			expected:  []string{"aaaaaaaaa-11ee3912", "bbbbbbbbb-7a3f7ddc", "traefik"},
+			expectedReplaced: map[string]string{
+				"aaaaaaaaa-11ee3912": strings.Repeat("a", 60),
+				"bbbbbbbbb-7a3f7ddc": strings.Repeat("b", 30),
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			segments, replaced := shortenPathSegments(test.names, test.maxLength)
+			assert.Equal(t, test.expected, segments)
+			assert.Equal(t, test.expectedReplaced, replaced)
+			assert.LessOrEqual(t, len(strings.Join(segments, "/")), test.maxLength)
+		})
+	}
+}
+
+func TestNomadStore_account(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	s, err := NewNomadStore("nomad://traefik/acme")
+	require.NoError(t, err)
+
+	account, err := s.GetAccount("test")
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	err = s.SaveAccount("test", &Account{Email: "some@email.com"})
+	require.NoError(t, err)
+
+	err = s.SaveCertificates("test", []*CertAndStore{
+		{Certificate: Certificate{Domain: types.Domain{Main: "traefik.wtf"}, Certificate: []byte("cert"), Key: []byte("key")}, Store: "default"},
+	})
+	require.NoError(t, err)
+
+	f.countRequests("")
+
+	account, err = newTestNomadStore(t, "nomad://traefik/acme").GetAccount("test")
+	require.NoError(t, err)
+	assert.Equal(t, &Account{Email: "some@email.com"}, account)
+
+	// reading the account does not transfer the certificates.
+	assert.Equal(t, 1, f.countRequests("GET /v1/var/traefik/acme/test/account"))
+}
+
+func TestNomadStore_Status(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	s := newTestNomadStore(t, "nomad://traefik/acme")
+
+	_, err := s.GetAccount("test")
+	require.NoError(t, err)
+
+	status := s.Status()
+	assert.Equal(t, StoreBackendNomad, status.Backend)
+	assert.Equal(t, "nomad://traefik/acme", status.Location)
+	assert.True(t, status.Healthy)
+	assert.NotNil(t, status.LastSuccess)
+
+	f.mu.Lock()
+	f.unavailable = true
+	f.mu.Unlock()
+
+	err = s.SaveAccount("test", &Account{Email: "some@email.com"})
+	require.Error(t, err)
+
+	status = s.Status()
+	assert.False(t, status.Healthy)
+	assert.Contains(t, status.LastError, "writing Nomad Variable traefik/acme/test/account")
+	assert.NotNil(t, status.LastErrorTime)
+}
+
+func TestNomadStore_certificates(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	s, err := NewNomadStore("nomad://traefik/acme")
+	require.NoError(t, err)
+
+	foo := &CertAndStore{Certificate: Certificate{Domain: types.Domain{Main: "foo.traefik.wtf"}, Certificate: []byte("foo"), Key: []byte("key")}, Store: "default"}
+	bar := &CertAndStore{Certificate: Certificate{Domain: types.Domain{Main: "bar.traefik.wtf", SANs: []string{"www.bar.traefik.wtf"}}, Certificate: []byte("bar"), Key: []byte("key")}, Store: "default"}
+
+	err = s.SaveCertificates("test", []*CertAndStore{foo, bar})
+	require.NoError(t, err)
+	assert.Equal(t, 2, f.countRequests("PUT"))
+
+	certificates, err := newTestNomadStore(t, "nomad://traefik/acme").GetCertificates("test")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*CertAndStore{foo, bar}, certificates)
+
+	// only the renewed certificate is written, and the removed one deleted.
+	renewed := &CertAndStore{Certificate: Certificate{Domain: foo.Domain, Certificate: []byte("renewed"), Key: []byte("key")}, Store: "default"}
+	err = s.SaveCertificates("test", []*CertAndStore{renewed})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, f.countRequests("DELETE"))
+
+	err = s.SaveCertificates("test", []*CertAndStore{renewed})
+	require.NoError(t, err)
+	assert.Equal(t, 0, f.countRequests("PUT"))
+
+	certificates, err = newTestNomadStore(t, "nomad://traefik/acme").GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{renewed}, certificates)
+
+	// the certificates saved by another instance are not removed.
+	other := newTestNomadStore(t, "nomad://traefik/acme")
+	err = other.SaveCertificates("test", []*CertAndStore{renewed, bar})
+	require.NoError(t, err)
+
+	err = s.SaveCertificates("test", []*CertAndStore{})
+	require.NoError(t, err)
+
+	certificates, err = newTestNomadStore(t, "nomad://traefik/acme").GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{bar}, certificates)
+}
+
+func TestNomadStore_state(t *testing.T) {
+	newFakeNomadVariables(t)
+
+	s, err := NewNomadStore("nomad://traefik/acme")
+	require.NoError(t, err)
+
+	err = s.SavePaused("test", true)
+	require.NoError(t, err)
+
+	err = s.SaveACMEDNSAccounts("test", map[string]goacmedns.Account{
+		"traefik.wtf": {FullDomain: "sub.auth.example.org", SubDomain: "sub", Username: "user", Password: "pass", ServerURL: "https://auth.example.org"},
+	})
+	require.NoError(t, err)
+
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	err = s.SaveRenewalInfo("test", map[string]RenewalInfo{
+		"aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE": {WindowStart: start, WindowEnd: start.Add(48 * time.Hour), NextPoll: start.Add(-time.Hour)},
+	})
+	require.NoError(t, err)
+
+	// the items of the state are saved together.
+	s = newTestNomadStore(t, "nomad://traefik/acme")
+
+	paused, err := s.GetPaused("test")
+	require.NoError(t, err)
+	assert.True(t, paused)
+
+	accounts, err := s.GetACMEDNSAccounts("test")
+	require.NoError(t, err)
+	assert.Len(t, accounts, 1)
+
+	renewalInfo, err := s.GetRenewalInfo("test")
+	require.NoError(t, err)
+	assert.Equal(t, start, renewalInfo["aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE"].WindowStart)
+
+	paused, err = s.GetPaused("other")
+	require.NoError(t, err)
+	assert.False(t, paused)
+}
+
+func TestNomadStore_lease(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	s := newTestNomadStore(t, "nomad://traefik/acme")
+	other := newTestNomadStore(t, "nomad://traefik/acme")
+
+	held, err := s.AcquireLease("test", "foo", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+	assert.Equal(t, "foo", f.variables["traefik/acme/test/lease"][nomadLeaseHolderItem])
+
+	held, err = other.AcquireLease("test", "bar", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	// the holder renews its lease.
+	held, err = s.AcquireLease("test", "foo", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	// the lease is acquired by another instance once expired.
+	f.variables["traefik/acme/test/lease"][nomadLeaseExpiresAtItem] = time.Now().Add(-time.Second).UTC().Format(time.RFC3339Nano)
+
+	held, err = other.AcquireLease("test", "bar", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+
+	held, err = s.AcquireLease("test", "foo", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	// the lease is only released by its holder.
+	err = s.ReleaseLease("test", "foo")
+	require.NoError(t, err)
+
+	held, err = s.AcquireLease("test", "foo", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, held)
+
+	err = other.ReleaseLease("test", "bar")
+	require.NoError(t, err)
+
+	held, err = s.AcquireLease("test", "foo", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, held)
+}
+
+func TestNomadStore_leaseConflict(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	s := newTestNomadStore(t, "nomad://traefik/acme")
+
+	// another instance acquired the lease since it was read at the index 41.
+	f.variables["traefik/acme/test/lease"] = map[string]string{
+		nomadLeaseHolderItem:    "bar",
+		nomadLeaseExpiresAtItem: time.Now().Add(-time.Second).UTC().Format(time.RFC3339Nano),
+	}
+	f.indexes["traefik/acme/test/lease"] = 42
+
+	err := s.writeCAS("traefik/acme/test/lease", map[string]string{nomadLeaseHolderItem: "foo"}, 41)
+	require.ErrorIs(t, err, errNomadConflict)
+	assert.Equal(t, "bar", f.variables["traefik/acme/test/lease"][nomadLeaseHolderItem])
+	assert.True(t, s.Status().Healthy)
+}
+
+func TestNomadStore_stateConcurrentWrite(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	s, err := NewNomadStore("nomad://traefik/acme")
+	require.NoError(t, err)
+
+	err = s.SavePaused("test", true)
+	require.NoError(t, err)
+
+	// another instance saves its renewal info between the read and the write of the state.
+	other := newTestNomadStore(t, "nomad://traefik/acme")
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	f.beforeWrite = func(path string) {
+		f.index++
+		f.variables[path] = map[string]string{
+			nomadPausedItem:      f.variables[path][nomadPausedItem],
+			nomadRenewalInfoItem: `{"aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE":{"windowStart":"2024-03-01T00:00:00Z"}}`,
+		}
+		f.indexes[path] = f.index
+	}
+
+	_ = f.countRequests("")
+
+	err = s.SaveACMEDNSAccounts("test", map[string]goacmedns.Account{
+		"traefik.wtf": {FullDomain: "sub.auth.example.org", SubDomain: "sub", Username: "user", Password: "pass", ServerURL: "https://auth.example.org"},
+	})
+	require.NoError(t, err)
+
+	// the conflicting write is retried on the Variable written by the other instance.
+	assert.Equal(t, 2, f.countRequests("PUT /v1/var/traefik/acme/test/state"))
+
+	paused, err := other.GetPaused("test")
+	require.NoError(t, err)
+	assert.True(t, paused)
+
+	accounts, err := other.GetACMEDNSAccounts("test")
+	require.NoError(t, err)
+	assert.Len(t, accounts, 1)
+
+	renewalInfo, err := other.GetRenewalInfo("test")
+	require.NoError(t, err)
+	assert.Equal(t, start, renewalInfo["aYhba4dGQEHhs3uEe6CuLN4ByNQ.AIdlQyE"].WindowStart)
+}
+
+func TestNomadStore_corrupted(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	s, err := NewNomadStore("nomad://traefik/acme")
+	require.NoError(t, err)
+
+	foo := &CertAndStore{Certificate: Certificate{Domain: types.Domain{Main: "foo.traefik.wtf"}, Certificate: []byte("foo"), Key: []byte("key")}, Store: "default"}
+	err = s.SaveCertificates("test", []*CertAndStore{foo})
+	require.NoError(t, err)
+
+	err = s.SavePaused("test", true)
+	require.NoError(t, err)
+
+	f.variables["traefik/acme/test/certificates/broken"] = map[string]string{nomadCertificateItem: `{"domain":`}
+	f.variables["traefik/acme/test/account"] = map[string]string{nomadAccountItem: `not json`}
+	f.variables["traefik/acme/test/state"][nomadRenewalInfoItem] = `[`
+
+	s = newTestNomadStore(t, "nomad://traefik/acme")
+
+	// the corrupted items are moved aside, and the resolver carries on without them.
+	certificates, err := s.GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{foo}, certificates)
+
+	account, err := s.GetAccount("test")
+	require.NoError(t, err)
+	assert.Nil(t, account)
+
+	renewalInfo, err := s.GetRenewalInfo("test")
+	require.NoError(t, err)
+	assert.Nil(t, renewalInfo)
+
+	paused, err := s.GetPaused("test")
+	require.NoError(t, err)
+	assert.True(t, paused)
+
+	assert.Equal(t, map[string]string{nomadCertificateItem: `{"domain":`}, f.variables["traefik/acme/corrupt/test/certificates/broken"])
+	assert.Equal(t, map[string]string{nomadAccountItem: `not json`}, f.variables["traefik/acme/corrupt/test/account"])
+	assert.Equal(t, map[string]string{nomadRenewalInfoItem: `[`}, f.variables["traefik/acme/corrupt/test/state"])
+	assert.NotContains(t, f.variables, "traefik/acme/test/certificates/broken")
+	assert.NotContains(t, f.variables, "traefik/acme/test/account")
+	assert.Equal(t, map[string]string{nomadPausedItem: "true"}, f.variables["traefik/acme/test/state"])
+}
+
+func TestNomadStore_replicated(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	foo := &CertAndStore{Certificate: Certificate{Domain: types.Domain{Main: "foo.traefik.wtf"}, Certificate: []byte("foo"), Key: []byte("key")}, Store: "default"}
+	bar := &CertAndStore{Certificate: Certificate{Domain: types.Domain{Main: "bar.traefik.wtf"}, Certificate: []byte("bar"), Key: []byte("key")}, Store: "default"}
+
+	s := newTestNomadStore(t, "nomad://traefik/acme?region=eu&authoritativeRegion=us")
+
+	err := s.SaveCertificates("test", []*CertAndStore{foo})
+	require.NoError(t, err)
+
+	err = s.SavePaused("test", true)
+	require.NoError(t, err)
+
+	f.replicate("eu")
+
+	// the writes made since the replication are read from the authoritative region.
+	renewed := &CertAndStore{Certificate: Certificate{Domain: foo.Domain, Certificate: []byte("renewed"), Key: []byte("key")}, Store: "default"}
+	err = s.SaveCertificates("test", []*CertAndStore{renewed, bar})
+	require.NoError(t, err)
+
+	err = s.SavePaused("test", false)
+	require.NoError(t, err)
+
+	err = s.SaveAccount("test", &Account{Email: "some@email.com"})
+	require.NoError(t, err)
+
+	certificates, err := s.GetCertificates("test")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*CertAndStore{renewed, bar}, certificates)
+
+	paused, err := s.GetPaused("test")
+	require.NoError(t, err)
+	assert.False(t, paused)
+
+	account, err := s.GetAccount("test")
+	require.NoError(t, err)
+	assert.Equal(t, &Account{Email: "some@email.com"}, account)
+
+	err = s.SaveCertificates("test", []*CertAndStore{bar})
+	require.NoError(t, err)
+
+	certificates, err = s.GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{bar}, certificates)
+
+	// once replicated, the Variables are read from the local region.
+	f.replicate("eu")
+	f.countRequests("")
+
+	certificates, err = s.GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{bar}, certificates)
+	assert.Equal(t, 2, f.countRequests(""))
+
+	// without the authoritative region, the Variables of the local region are trusted.
+	certificates, err = newTestNomadStore(t, "nomad://traefik/acme?region=eu").GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{bar}, certificates)
+}
+
+func TestNomadStore_fallback(t *testing.T) {
+	f := newFakeNomadVariables(t)
+
+	acmeFile := filepath.Join(t.TempDir(), "acme.json")
+	err := os.WriteFile(acmeFile, []byte(`{
+  "test": {
+    "Account": {
+      "Email": "some@email.com"
+    },
+    "Certificates": [
+      {
+        "domain": {"main": "foo.traefik.wtf"},
+        "certificate": "Zm9v",
+        "key": "a2V5",
+        "Store": "default"
+      }
+    ],
+    "Paused": true
+  }
+}`), 0o600)
+	require.NoError(t, err)
+
+	foo := &CertAndStore{Certificate: Certificate{Domain: types.Domain{Main: "foo.traefik.wtf"}, Certificate: []byte("foo"), Key: []byte("key")}, Store: "default"}
+	bar := &CertAndStore{Certificate: Certificate{Domain: types.Domain{Main: "bar.traefik.wtf"}, Certificate: []byte("bar"), Key: []byte("key")}, Store: "default"}
+
+	err = newTestNomadStore(t, "nomad://traefik/acme").SaveCertificates("test", []*CertAndStore{bar})
+	require.NoError(t, err)
+
+	s := newTestNomadStore(t, "nomad://traefik/acme?fallback="+acmeFile)
+
+	// the data missing from the Variables is read from the file, and written through to the Variables.
+	account, err := s.GetAccount("test")
+	require.NoError(t, err)
+	assert.Equal(t, &Account{Email: "some@email.com"}, account)
+
+	certificates, err := s.GetCertificates("test")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*CertAndStore{foo, bar}, certificates)
+
+	paused, err := s.GetPaused("test")
+	require.NoError(t, err)
+	assert.True(t, paused)
+
+	s = newTestNomadStore(t, "nomad://traefik/acme")
+
+	account, err = s.GetAccount("test")
+	require.NoError(t, err)
+	assert.Equal(t, &Account{Email: "some@email.com"}, account)
+
+	certificates, err = s.GetCertificates("test")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*CertAndStore{foo, bar}, certificates)
+
+	// the certificates removed once written through are not written again.
+	err = s.SaveCertificates("test", []*CertAndStore{bar})
+	require.NoError(t, err)
+
+	f.countRequests("")
+
+	certificates, err = newTestNomadStore(t, "nomad://traefik/acme?fallback="+acmeFile).GetCertificates("test")
+	require.NoError(t, err)
+	assert.Equal(t, []*CertAndStore{bar}, certificates)
+	assert.Equal(t, 0, f.countRequests("PUT"))
+}
+
+// newTestNomadStore returns a new NomadStore, sharing the Variables of the other stores of the test.
+func newTestNomadStore(t *testing.T, storage string) *NomadStore {
+	t.Helper()
+
+	s, err := NewNomadStore(storage)
+	require.NoError(t, err)
+
+	return s
+}
@@ -6,6 +6,8 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
 	"reflect"
 	"sort"
@@ -43,10 +45,13 @@ type Configuration struct {
 	KeyType              string `description:"KeyType used for generating certificate private key. Allow value 'EC256', 'EC384', 'RSA2048', 'RSA4096', 'RSA8192'." json:"keyType,omitempty" toml:"keyType,omitempty" yaml:"keyType,omitempty" export:"true"`
 	EAB                  *EAB   `description:"External Account Binding to use." json:"eab,omitempty" toml:"eab,omitempty" yaml:"eab,omitempty"`
 	CertificatesDuration int    `description:"Certificates' duration in hours." json:"certificatesDuration,omitempty" toml:"certificatesDuration,omitempty" yaml:"certificatesDuration,omitempty" export:"true"`
+	RenewalInfo          bool   `description:"Poll the ACME Renewal Information (ARI) of the CA, to renew the certificates in the window it suggests, e.g. ahead of a revocation." json:"renewalInfo,omitempty" toml:"renewalInfo,omitempty" yaml:"renewalInfo,omitempty" export:"true"`
 
 	DNSChallenge  *DNSChallenge  `description:"Activate DNS-01 Challenge." json:"dnsChallenge,omitempty" toml:"dnsChallenge,omitempty" yaml:"dnsChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	HTTPChallenge *HTTPChallenge `description:"Activate HTTP-01 Challenge." json:"httpChallenge,omitempty" toml:"httpChallenge,omitempty" yaml:"httpChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
 	TLSChallenge  *TLSChallenge  `description:"Activate TLS-ALPN-01 Challenge." json:"tlsChallenge,omitempty" toml:"tlsChallenge,omitempty" yaml:"tlsChallenge,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+
+	WarmUp *WarmUp `description:"Obtain the certificates of a list of domains at startup." json:"warmUp,omitempty" toml:"warmUp,omitempty" yaml:"warmUp,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values.
@@ -82,6 +87,11 @@ type DNSChallenge struct {
 	DelayBeforeCheck        ptypes.Duration `description:"Assume DNS propagates after a delay in seconds rather than finding and querying nameservers." json:"delayBeforeCheck,omitempty" toml:"delayBeforeCheck,omitempty" yaml:"delayBeforeCheck,omitempty" export:"true"`
 	Resolvers               []string        `description:"Use following DNS servers to resolve the FQDN authority." json:"resolvers,omitempty" toml:"resolvers,omitempty" yaml:"resolvers,omitempty"`
 	DisablePropagationCheck bool            `description:"Disable the DNS propagation checks before notifying ACME that the DNS challenge is ready. [not recommended]" json:"disablePropagationCheck,omitempty" toml:"disablePropagationCheck,omitempty" yaml:"disablePropagationCheck,omitempty" export:"true"`
+	ACMEDNS                 *ACMEDNS        `description:"Delegate the DNS challenges to an acme-dns server, instead of using a DNS provider." json:"acmeDNS,omitempty" toml:"acmeDNS,omitempty" yaml:"acmeDNS,omitempty" export:"true"`
+}
+
+func (d *DNSChallenge) enabled() bool {
+	return d != nil && (len(d.Provider) > 0 || d.ACMEDNS != nil)
 }
 
 // HTTPChallenge contains HTTP challenge configuration.
@@ -103,6 +113,8 @@ type Provider struct {
 
 	certificates   []*CertAndStore
 	certificatesMu sync.RWMutex
+	// certificatesSyncedAt is the last time the certificates were read from, or saved to, the Store.
+	certificatesSyncedAt time.Time
 
 	account                *Account
 	client                 *lego.Client
@@ -113,6 +125,18 @@ type Provider struct {
 	pool                   *safe.Pool
 	resolvingDomains       map[string]struct{}
 	resolvingDomainsMutex  sync.RWMutex
+
+	paused   bool
+	pausedMu sync.RWMutex
+
+	// leaseHolder identifies the instance in the lease of the resolver, when its Store is shared with other instances.
+	leaseHolder string
+	leader      bool
+	leaderMu    sync.RWMutex
+
+	httpClient     *http.Client // HTTP client of the ACME client, used to poll the renewal information
+	renewalInfoURL *string      // renewal information endpoint of the CA, empty when not supported, nil until fetched
+	renewalInfoMu  sync.Mutex
 }
 
 // SetTLSManager sets the tls manager to use.
@@ -156,12 +180,28 @@ func (p *Provider) Init() error {
 
 	p.certificatesMu.Lock()
 	p.certificates, err = p.Store.GetCertificates(p.ResolverName)
+	if err == nil {
+		p.certificatesSyncedAt = time.Now()
+	}
 	p.certificatesMu.Unlock()
 
 	if err != nil {
 		return fmt.Errorf("unable to get ACME certificates : %w", err)
 	}
 
+	paused, err := p.Store.GetPaused(p.ResolverName)
+	if err != nil {
+		return fmt.Errorf("unable to get ACME resolver state: %w", err)
+	}
+
+	p.pausedMu.Lock()
+	p.paused = paused
+	p.pausedMu.Unlock()
+
+	if paused {
+		logger.Warn().Msg("The resolver is paused, no certificate will be obtained or renewed until it is resumed.")
+	}
+
 	// Init the currently resolved domain map
 	p.resolvingDomains = make(map[string]struct{})
 
@@ -186,6 +226,49 @@ func isAccountMatchingCaServer(ctx context.Context, accountURI, serverURI string
 	return cau.Hostname() == aru.Hostname()
 }
 
+// Paused returns whether the resolver is paused.
+func (p *Provider) Paused() bool {
+	p.pausedMu.RLock()
+	defer p.pausedMu.RUnlock()
+
+	return p.paused
+}
+
+// Pause stops the resolver from obtaining new certificates and renewing the existing ones.
+// The state is persisted in the Store, so that the resolver stays paused across restarts.
+func (p *Provider) Pause() error {
+	return p.setPaused(true)
+}
+
+// Resume resumes the resolver after a Pause.
+func (p *Provider) Resume() error {
+	return p.setPaused(false)
+}
+
+func (p *Provider) setPaused(paused bool) error {
+	p.pausedMu.Lock()
+	defer p.pausedMu.Unlock()
+
+	if p.paused == paused {
+		return nil
+	}
+
+	if err := p.Store.SavePaused(p.ResolverName, paused); err != nil {
+		return fmt.Errorf("unable to save ACME resolver state: %w", err)
+	}
+
+	p.paused = paused
+
+	logger := log.With().Str(logs.ProviderName, p.ResolverName+".acme").Logger()
+	if paused {
+		logger.Info().Msg("Resolver paused")
+	} else {
+		logger.Info().Msg("Resolver resumed")
+	}
+
+	return nil
+}
+
 // ThrottleDuration returns the throttle duration.
 func (p *Provider) ThrottleDuration() time.Duration {
 	return 0
@@ -211,11 +294,18 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 	p.configurationChan <- msg
 
 	renewPeriod, renewInterval := getCertificateRenewDurations(p.CertificatesDuration)
+	if p.RenewalInfo && renewInterval > renewalInfoCheckInterval {
+		renewInterval = renewalInfoCheckInterval
+	}
 	logger.Debug().Msgf("Attempt to renew certificates %q before expiry and check every %q",
 		renewPeriod, renewInterval)
 
+	p.keepLease(ctx, renewPeriod)
+
 	p.renewCertificates(ctx, renewPeriod)
 
+	p.warmUp(ctx)
+
 	ticker := time.NewTicker(renewInterval)
 	pool.GoCtx(func(ctxPool context.Context) {
 		for {
@@ -266,6 +356,7 @@ func (p *Provider) getClient() (*lego.Client, error) {
 	if err != nil {
 		return nil, err
 	}
+	p.httpClient = config.HTTPClient
 
 	// New users will need to register; be sure to save it
 	if account.GetRegistration() == nil {
@@ -284,17 +375,29 @@ func (p *Provider) getClient() (*lego.Client, error) {
 		return nil, err
 	}
 
-	if (p.DNSChallenge == nil || len(p.DNSChallenge.Provider) == 0) &&
+	if !p.DNSChallenge.enabled() &&
 		(p.HTTPChallenge == nil || len(p.HTTPChallenge.EntryPoint) == 0) &&
 		p.TLSChallenge == nil {
 		return nil, errors.New("ACME challenge not specified, please select TLS or HTTP or DNS Challenge")
 	}
 
-	if p.DNSChallenge != nil && len(p.DNSChallenge.Provider) > 0 {
-		logger.Debug().Msgf("Using DNS Challenge provider: %s", p.DNSChallenge.Provider)
-
+	if p.DNSChallenge.enabled() {
 		var provider challenge.Provider
-		provider, err = dns.NewDNSChallengeProviderByName(p.DNSChallenge.Provider)
+
+		switch {
+		case len(p.DNSChallenge.Provider) > 0 && p.DNSChallenge.ACMEDNS != nil:
+			return nil, errors.New("the DNS challenge provider and acme-dns cannot be used together")
+
+		case p.DNSChallenge.ACMEDNS != nil:
+			logger.Debug().Msgf("Using acme-dns server: %s", p.DNSChallenge.ACMEDNS.APIBase)
+
+			provider, err = newACMEDNSProvider(p.DNSChallenge.ACMEDNS, p.Store, p.ResolverName)
+
+		default:
+			logger.Debug().Msgf("Using DNS Challenge provider: %s", p.DNSChallenge.Provider)
+
+			provider, err = dns.NewDNSChallengeProviderByName(p.DNSChallenge.Provider)
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -551,6 +654,10 @@ func (p *Provider) watchNewDomains(ctx context.Context) {
 }
 
 func (p *Provider) resolveDefaultCertificate(ctx context.Context, domains []string) (*certificate.Resource, error) {
+	if p.Paused() {
+		return nil, errors.New("resolver is paused")
+	}
+
 	logger := log.Ctx(ctx)
 
 	p.resolvingDomainsMutex.Lock()
@@ -573,6 +680,10 @@ func (p *Provider) resolveDefaultCertificate(ctx context.Context, domains []stri
 
 	defer p.removeResolvingDomains(append(domains, domainKey))
 
+	if !p.awaitLease(ctx, domains) {
+		return nil, nil
+	}
+
 	logger.Debug().Msgf("Loading ACME certificates %+v...", domains)
 
 	client, err := p.getClient()
@@ -604,6 +715,10 @@ func (p *Provider) resolveDefaultCertificate(ctx context.Context, domains []stri
 }
 
 func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain, tlsStore string) (types.Domain, *certificate.Resource, error) {
+	if p.Paused() {
+		return types.Domain{}, nil, errors.New("resolver is paused")
+	}
+
 	domains, err := p.sanitizeDomains(ctx, domain)
 	if err != nil {
 		return types.Domain{}, nil, err
@@ -617,6 +732,10 @@ func (p *Provider) resolveCertificate(ctx context.Context, domain types.Domain,
 
 	defer p.removeResolvingDomains(uncheckedDomains)
 
+	if !p.awaitLease(ctx, domains) {
+		return types.Domain{}, nil, nil
+	}
+
 	logger := log.Ctx(ctx)
 	logger.Debug().Msgf("Loading ACME certificates %+v...", uncheckedDomains)
 
@@ -687,7 +806,13 @@ func (p *Provider) addCertificateForDomain(domain types.Domain, crt *certificate
 
 	p.configurationChan <- p.buildMessage()
 
-	return p.Store.SaveCertificates(p.ResolverName, p.certificates)
+	if err := p.Store.SaveCertificates(p.ResolverName, p.certificates); err != nil {
+		return err
+	}
+
+	p.certificatesSyncedAt = time.Now()
+
+	return nil
 }
 
 // getCertificateRenewDurations returns renew durations calculated from the given certificatesDuration in hours.
@@ -795,21 +920,37 @@ func (p *Provider) buildMessage() dynamic.Message {
 func (p *Provider) renewCertificates(ctx context.Context, renewPeriod time.Duration) {
 	logger := log.Ctx(ctx)
 
+	if p.Paused() {
+		logger.Info().Msg("Resolver is paused, skipping certificate renew")
+		return
+	}
+
+	if !p.holdsLease() {
+		logger.Debug().Msg("Another Traefik instance holds the lease of the resolver, skipping certificate renew")
+		return
+	}
+
 	logger.Info().Msg("Testing certificate renew...")
 
 	p.certificatesMu.RLock()
 
 	var certificates []*CertAndStore
+	var valid []*CertAndStore
 	for _, cert := range p.certificates {
 		crt, err := getX509Certificate(ctx, &cert.Certificate)
 		// If there's an error, we assume the cert is broken, and needs update
 		if err != nil || crt == nil || crt.NotAfter.Before(time.Now().Add(renewPeriod)) {
 			certificates = append(certificates, cert)
+			continue
 		}
+		valid = append(valid, cert)
 	}
 
 	p.certificatesMu.RUnlock()
 
+	// the certificates flagged for an early renewal by the CA are renewed ahead of schedule.
+	certificates = append(certificates, p.checkRenewalInfo(ctx, valid, time.Now())...)
+
 	for _, cert := range certificates {
 		client, err := p.getClient()
 		if err != nil {
@@ -930,6 +1071,17 @@ func (p *Provider) sanitizeDomains(ctx context.Context, domain types.Domain) ([]
 
 	var cleanDomains []string
 	for _, dom := range domains {
+		// IP identifiers (RFC 8738) are validated with the HTTP-01 or TLS-ALPN-01 challenges only,
+		// and are ordered in their canonical form, as the CA issues them.
+		if ip := net.ParseIP(dom); ip != nil {
+			if p.DNSChallenge.enabled() {
+				return nil, fmt.Errorf("unable to generate a certificate in ACME provider for IP address %q : the DNS-01 challenge cannot validate IP addresses", dom)
+			}
+
+			cleanDomains = append(cleanDomains, ip.String())
+			continue
+		}
+
 		if strings.HasPrefix(dom, "*") {
 			if p.DNSChallenge == nil {
 				return nil, fmt.Errorf("unable to generate a wildcard certificate in ACME provider for domain %q : ACME needs a DNSChallenge", strings.Join(domains, ","))
@@ -3,11 +3,13 @@ package acme
 import (
 	"context"
 	"crypto/tls"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/go-acme/lego/v4/certcrypto"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v3/pkg/safe"
 	"github.com/traefik/traefik/v3/pkg/types"
 )
@@ -245,6 +247,20 @@ func TestProvider_sanitizeDomains(t *testing.T) {
 			expectedErr:     "",
 			expectedDomains: []string{"*.traefik.wtf", "*.acme.wtf"},
 		},
+		{
+			desc:            "IP addresses",
+			domains:         types.Domain{Main: "10.0.0.1", SANs: []string{"2001:DB8:0:0::1", "traefik.wtf"}},
+			dnsChallenge:    nil,
+			expectedErr:     "",
+			expectedDomains: []string{"10.0.0.1", "2001:db8::1", "traefik.wtf"},
+		},
+		{
+			desc:            "IP address with DNSChallenge",
+			domains:         types.Domain{Main: "traefik.wtf", SANs: []string{"10.0.0.1"}},
+			dnsChallenge:    &DNSChallenge{Provider: "manual"},
+			expectedErr:     "unable to generate a certificate in ACME provider for IP address \"10.0.0.1\" : the DNS-01 challenge cannot validate IP addresses",
+			expectedDomains: nil,
+		},
 	}
 
 	for _, test := range testCases {
@@ -259,7 +275,7 @@ func TestProvider_sanitizeDomains(t *testing.T) {
 			if len(test.expectedErr) > 0 {
 				assert.EqualError(t, err, test.expectedErr, "Unexpected error.")
 			} else {
-				assert.Equal(t, len(test.expectedDomains), len(domains), "Unexpected domains.")
+				assert.Equal(t, test.expectedDomains, domains, "Unexpected domains.")
 			}
 		})
 	}
@@ -594,6 +610,43 @@ func TestInitAccount(t *testing.T) {
 	}
 }
 
+func TestProvider_PauseResume(t *testing.T) {
+	store := NewLocalStore(filepath.Join(t.TempDir(), "acme.json"))
+
+	acmeProvider := &Provider{
+		Configuration: &Configuration{Storage: "acme.json", CertificatesDuration: 24},
+		ResolverName:  "test",
+		Store:         store,
+	}
+	require.NoError(t, acmeProvider.Init())
+	assert.False(t, acmeProvider.Paused())
+
+	require.NoError(t, acmeProvider.Pause())
+	assert.True(t, acmeProvider.Paused())
+
+	_, _, err := acmeProvider.resolveCertificate(context.Background(), types.Domain{Main: "example.com"}, "default")
+	assert.Error(t, err)
+
+	_, err = acmeProvider.resolveDefaultCertificate(context.Background(), []string{"example.com"})
+	assert.Error(t, err)
+
+	// The paused state is restored from the store on init.
+	restarted := &Provider{
+		Configuration: acmeProvider.Configuration,
+		ResolverName:  "test",
+		Store:         store,
+	}
+	require.NoError(t, restarted.Init())
+	assert.True(t, restarted.Paused())
+
+	require.NoError(t, restarted.Resume())
+	assert.False(t, restarted.Paused())
+
+	paused, err := store.GetPaused("test")
+	require.NoError(t, err)
+	assert.False(t, paused)
+}
+
 func Test_getCertificateRenewDurations(t *testing.T) {
 	testCases := []struct {
 		desc                  string
@@ -0,0 +1,78 @@
+package acme
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/logs"
+)
+
+// ErrCertificateNotFound is returned when the resolver has no certificate for a domain.
+var ErrCertificateNotFound = errors.New("certificate not found")
+
+// RemoveCertificate removes the certificate of the given main domain from the resolver and its Store,
+// after revoking it with the CA when revoke is true, with the reason code when not nil (as defined by RFC 5280).
+// The certificate is obtained again if routers still need it, once the configuration without it is applied.
+func (p *Provider) RemoveCertificate(domain string, revoke bool, reason *uint) error {
+	msg, err := p.removeCertificate(domain, revoke, reason)
+	if err != nil {
+		return err
+	}
+
+	// the lock is released before sending the configuration,
+	// not to block the access to the certificates until the configuration watcher receives it.
+	p.configurationChan <- msg
+
+	return nil
+}
+
+func (p *Provider) removeCertificate(domain string, revoke bool, reason *uint) (dynamic.Message, error) {
+	p.certificatesMu.Lock()
+	defer p.certificatesMu.Unlock()
+
+	index := -1
+	for i, cert := range p.certificates {
+		if strings.EqualFold(cert.Domain.Main, domain) {
+			index = i
+			break
+		}
+	}
+
+	if index < 0 {
+		return dynamic.Message{}, fmt.Errorf("%w: %s", ErrCertificateNotFound, domain)
+	}
+
+	logger := log.With().Str(logs.ProviderName, p.ResolverName+".acme").Strs("domains", p.certificates[index].Domain.ToStrArray()).Logger()
+
+	if revoke {
+		client, err := p.getClient()
+		if err != nil {
+			return dynamic.Message{}, fmt.Errorf("unable to get ACME client: %w", err)
+		}
+
+		if err := client.Certificate.RevokeWithReason(p.certificates[index].Certificate.Certificate, reason); err != nil {
+			return dynamic.Message{}, fmt.Errorf("unable to revoke certificate: %w", err)
+		}
+
+		logger.Info().Msg("Certificate revoked")
+	}
+
+	certificates := make([]*CertAndStore, 0, len(p.certificates)-1)
+	certificates = append(certificates, p.certificates[:index]...)
+	certificates = append(certificates, p.certificates[index+1:]...)
+
+	if err := p.Store.SaveCertificates(p.ResolverName, certificates); err != nil {
+		return dynamic.Message{}, fmt.Errorf("unable to save ACME certificates: %w", err)
+	}
+
+	p.certificates = certificates
+	p.certificatesSyncedAt = time.Now()
+
+	logger.Info().Msg("Certificate removed")
+
+	return p.buildMessage(), nil
+}
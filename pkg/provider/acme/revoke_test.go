@@ -0,0 +1,48 @@
+package acme
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+func TestProvider_RemoveCertificate(t *testing.T) {
+	store := NewLocalStore(filepath.Join(t.TempDir(), "acme.json"))
+
+	certificates := []*CertAndStore{
+		{Certificate: Certificate{Domain: types.Domain{Main: "a.example.com"}, Certificate: []byte("a"), Key: []byte("a")}, Store: "default"},
+		{Certificate: Certificate{Domain: types.Domain{Main: "b.example.com", SANs: []string{"c.example.com"}}, Certificate: []byte("b"), Key: []byte("b")}, Store: "default"},
+	}
+	require.NoError(t, store.SaveCertificates("test", certificates))
+
+	configurationChan := make(chan dynamic.Message, 1)
+
+	acmeProvider := &Provider{
+		Configuration:     &Configuration{Storage: "acme.json", CertificatesDuration: 24},
+		ResolverName:      "test",
+		Store:             store,
+		configurationChan: configurationChan,
+	}
+	require.NoError(t, acmeProvider.Init())
+
+	err := acmeProvider.RemoveCertificate("c.example.com", false, nil)
+	assert.ErrorIs(t, err, ErrCertificateNotFound, "the certificates are removed by main domain")
+
+	require.NoError(t, acmeProvider.RemoveCertificate("B.example.com", false, nil))
+
+	msg := <-configurationChan
+	require.Len(t, msg.Configuration.TLS.Certificates, 1)
+	assert.Equal(t, "a", msg.Configuration.TLS.Certificates[0].CertFile.String())
+
+	stored, err := store.GetCertificates("test")
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, "a.example.com", stored[0].Domain.Main)
+
+	err = acmeProvider.RemoveCertificate("b.example.com", false, nil)
+	assert.ErrorIs(t, err, ErrCertificateNotFound)
+}
@@ -1,9 +1,27 @@
 package acme
 
+import (
+	"sync"
+	"time"
+
+	"github.com/cpu/goacmedns"
+)
+
+// The backends of the stores.
+const (
+	StoreBackendFile  = "file"
+	StoreBackendNomad = "nomad"
+)
+
 // StoredData represents the data managed by Store.
 type StoredData struct {
 	Account      *Account
 	Certificates []*CertAndStore
+	Paused       bool `json:",omitempty"`
+	// ACMEDNSAccounts are the acme-dns accounts registered for the DNS challenges, indexed by domain.
+	ACMEDNSAccounts map[string]goacmedns.Account `json:",omitempty"`
+	// RenewalInfo is the ACME Renewal Information of the certificates, indexed by certificate identifier.
+	RenewalInfo map[string]RenewalInfo `json:",omitempty"`
 }
 
 // Store is a generic interface that represents a storage.
@@ -12,4 +30,89 @@ type Store interface {
 	SaveAccount(string, *Account) error
 	GetCertificates(string) ([]*CertAndStore, error)
 	SaveCertificates(string, []*CertAndStore) error
+	GetPaused(string) (bool, error)
+	SavePaused(string, bool) error
+	GetACMEDNSAccounts(string) (map[string]goacmedns.Account, error)
+	SaveACMEDNSAccounts(string, map[string]goacmedns.Account) error
+	GetRenewalInfo(string) (map[string]RenewalInfo, error)
+	SaveRenewalInfo(string, map[string]RenewalInfo) error
+}
+
+// StoreStatus is the state of the store of a resolver, as reported by the API.
+type StoreStatus struct {
+	// Backend is the backend of the store, file or nomad, empty when unknown.
+	Backend string `json:"backend,omitempty"`
+	// Location is the file path, or the nomad:// path of the Nomad Variables, of the store.
+	Location string `json:"location,omitempty"`
+	// Healthy reports whether the last operation of the store on its backend succeeded.
+	Healthy       bool       `json:"healthy"`
+	LastSuccess   *time.Time `json:"lastSuccess,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+	// Certificates is the number of certificates of the resolver in the store.
+	Certificates int `json:"certificates"`
+	// SyncedAt is the last time the certificates of the resolver were read from, or saved to, the store, nil when never.
+	SyncedAt *time.Time `json:"syncedAt,omitempty"`
+}
+
+// statusReporter is a store reporting the state of its backend.
+type statusReporter interface {
+	Status() StoreStatus
+}
+
+// leaser is a store shared by several Traefik instances, leasing the orders of the certificates of a resolver to one of them at a time.
+type leaser interface {
+	// AcquireLease acquires, or renews, the lease of the resolver for the holder until the TTL expires, and reports whether the holder holds it.
+	AcquireLease(resolverName, holder string, ttl time.Duration) (bool, error)
+	// ReleaseLease releases the lease of the resolver when held by the holder.
+	ReleaseLease(resolverName, holder string) error
+}
+
+// storeHealth records the outcome of the operations of a store on its backend.
+type storeHealth struct {
+	mu            sync.RWMutex
+	failed        bool
+	lastSuccess   time.Time
+	lastError     string
+	lastErrorTime time.Time
+}
+
+// record records the outcome of an operation, and returns its error.
+func (h *storeHealth) record(err error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err != nil {
+		h.failed = true
+		h.lastError = err.Error()
+		h.lastErrorTime = time.Now()
+		return err
+	}
+
+	h.failed = false
+	h.lastSuccess = time.Now()
+
+	return nil
+}
+
+func (h *storeHealth) status(backend, location string) StoreStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return StoreStatus{
+		Backend:       backend,
+		Location:      location,
+		Healthy:       !h.failed,
+		LastSuccess:   timeOrNil(h.lastSuccess),
+		LastError:     h.lastError,
+		LastErrorTime: timeOrNil(h.lastErrorTime),
+	}
+}
+
+// timeOrNil returns a pointer to the time, nil when it is zero.
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
 }
@@ -0,0 +1,56 @@
+package acme
+
+import (
+	"time"
+
+	"github.com/go-acme/lego/v4/certcrypto"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+// CertificateSummary describes a certificate of the resolver, without its key, as reported by the diagnostics.
+type CertificateSummary struct {
+	Domain   types.Domain `json:"domain"`
+	Store    string       `json:"store,omitempty"`
+	NotAfter time.Time    `json:"notAfter,omitempty"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// CertificatesSummary returns the summary of the certificates of the resolver.
+func (p *Provider) CertificatesSummary() []CertificateSummary {
+	p.certificatesMu.RLock()
+	defer p.certificatesMu.RUnlock()
+
+	summaries := make([]CertificateSummary, 0, len(p.certificates))
+	for _, cert := range p.certificates {
+		summary := CertificateSummary{Domain: cert.Domain, Store: cert.Store}
+
+		crt, err := certcrypto.ParsePEMCertificate(cert.Certificate.Certificate)
+		if err != nil {
+			summary.Error = err.Error()
+		} else {
+			summary.NotAfter = crt.NotAfter
+		}
+
+		summaries = append(summaries, summary)
+	}
+
+	return summaries
+}
+
+// StoreStatus returns the state of the Store of the resolver.
+func (p *Provider) StoreStatus() StoreStatus {
+	var status StoreStatus
+	if reporter, ok := p.Store.(statusReporter); ok {
+		status = reporter.Status()
+	} else {
+		status.Healthy = true
+	}
+
+	p.certificatesMu.RLock()
+	defer p.certificatesMu.RUnlock()
+
+	status.Certificates = len(p.certificates)
+	status.SyncedAt = timeOrNil(p.certificatesSyncedAt)
+
+	return status
+}
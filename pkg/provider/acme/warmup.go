@@ -0,0 +1,179 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog/log"
+	traefiktls "github.com/traefik/traefik/v3/pkg/tls"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+// WarmUp holds the lists of domains whose certificates are obtained at startup,
+// rather than when the first router referencing them is created.
+type WarmUp struct {
+	File  string       `description:"Path to a file listing the domains, one certificate per line, with the main domain followed by the SANs, separated by commas." json:"file,omitempty" toml:"file,omitempty" yaml:"file,omitempty" export:"true"`
+	Nomad *WarmUpNomad `description:"Nomad Variable listing the domains, one certificate per item, with the main domain followed by the SANs, separated by commas." json:"nomad,omitempty" toml:"nomad,omitempty" yaml:"nomad,omitempty" export:"true"`
+}
+
+// WarmUpNomad holds the configuration of the Nomad Variable listing the warm-up domains.
+type WarmUpNomad struct {
+	Address   string `description:"The address of the Nomad server, including scheme and port." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
+	Token     string `description:"Token is used to provide a per-request ACL token." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" loggable:"false"`
+	Namespace string `description:"Namespace of the Nomad Variable." json:"namespace,omitempty" toml:"namespace,omitempty" yaml:"namespace,omitempty" export:"true"`
+	Region    string `description:"Nomad region to use. If not provided, the local agent region is used." json:"region,omitempty" toml:"region,omitempty" yaml:"region,omitempty" export:"true"`
+	Path      string `description:"Path of the Nomad Variable." json:"path,omitempty" toml:"path,omitempty" yaml:"path,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values.
+func (w *WarmUpNomad) SetDefaults() {
+	w.Namespace = api.DefaultNamespace
+}
+
+// warmUp obtains, one after the other, the certificates of the warm-up domains which are not stored yet.
+func (p *Provider) warmUp(ctx context.Context) {
+	if p.WarmUp == nil {
+		return
+	}
+
+	logger := log.Ctx(ctx)
+
+	domains, err := p.WarmUp.domains(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Unable to read the warm-up domains")
+		return
+	}
+
+	logger.Info().Msgf("Warming up %d certificate(s)", len(domains))
+
+	p.pool.GoCtx(func(ctxPool context.Context) {
+		for _, domain := range domains {
+			if ctxPool.Err() != nil {
+				return
+			}
+
+			dom, cert, err := p.resolveCertificate(ctx, domain, traefiktls.DefaultTLSStoreName)
+			if err != nil {
+				logger.Error().Err(err).Strs("domains", domain.ToStrArray()).Msg("Unable to obtain ACME certificate for domains")
+				continue
+			}
+
+			err = p.addCertificateForDomain(dom, cert, traefiktls.DefaultTLSStoreName)
+			if err != nil {
+				logger.Error().Err(err).Strs("domains", dom.ToStrArray()).Msg("Error adding certificate for domains")
+			}
+		}
+	})
+}
+
+// domains returns the domains listed by the file and the Nomad Variable.
+func (w *WarmUp) domains(ctx context.Context) ([]types.Domain, error) {
+	var domains []types.Domain
+
+	if w.File != "" {
+		data, err := os.ReadFile(w.File)
+		if err != nil {
+			return nil, fmt.Errorf("reading warm-up file: %w", err)
+		}
+
+		fileDomains, err := parseWarmUpDomains(strings.Split(string(data), "\n"))
+		if err != nil {
+			return nil, fmt.Errorf("parsing warm-up file %s: %w", w.File, err)
+		}
+
+		domains = append(domains, fileDomains...)
+	}
+
+	if w.Nomad != nil {
+		items, err := w.Nomad.items(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// items are sorted by name, so that the certificates are obtained in a predictable order.
+		names := make([]string, 0, len(items))
+		for name := range items {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		lines := make([]string, 0, len(names))
+		for _, name := range names {
+			lines = append(lines, items[name])
+		}
+
+		nomadDomains, err := parseWarmUpDomains(lines)
+		if err != nil {
+			return nil, fmt.Errorf("parsing Nomad Variable %s: %w", w.Nomad.Path, err)
+		}
+
+		domains = append(domains, nomadDomains...)
+	}
+
+	return domains, nil
+}
+
+// parseWarmUpDomains parses the lines listing the domains of a certificate,
+// with the main domain followed by the SANs, separated by commas.
+// Empty lines, and lines starting with # are ignored.
+func parseWarmUpDomains(lines []string) ([]types.Domain, error) {
+	var domains []types.Domain
+
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var names []string
+		for _, name := range strings.Split(line, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return nil, fmt.Errorf("empty domain in %q", line)
+			}
+
+			names = append(names, name)
+		}
+
+		domain := types.Domain{Main: names[0]}
+		if len(names) > 1 {
+			domain.SANs = names[1:]
+		}
+
+		domains = append(domains, domain)
+	}
+
+	return domains, nil
+}
+
+func (w *WarmUpNomad) items(ctx context.Context) (map[string]string, error) {
+	path := strings.Trim(w.Path, "/")
+	if path == "" {
+		return nil, errors.New("the path of the Nomad Variable is required")
+	}
+
+	client, err := api.NewClient(&api.Config{
+		Address:   w.Address,
+		Namespace: w.Namespace,
+		Region:    w.Region,
+		SecretID:  w.Token,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating Nomad client: %w", err)
+	}
+
+	// The Nomad API client in use does not provide the Variables endpoints yet.
+	var variable struct {
+		Items map[string]string
+	}
+	if _, err := client.Raw().Query("/v1/var/"+path, &variable, (&api.QueryOptions{}).WithContext(ctx)); err != nil {
+		return nil, fmt.Errorf("reading Nomad Variable %s: %w", path, err)
+	}
+
+	return variable.Items, nil
+}
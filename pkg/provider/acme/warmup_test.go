@@ -0,0 +1,116 @@
+package acme
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+func Test_parseWarmUpDomains(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		lines       []string
+		expected    []types.Domain
+		expectedErr string
+	}{
+		{
+			desc:  "no lines",
+			lines: nil,
+		},
+		{
+			desc: "main domains and SANs",
+			lines: []string{
+				"traefik.wtf",
+				" traefik.io , www.traefik.io,api.traefik.io ",
+			},
+			expected: []types.Domain{
+				{Main: "traefik.wtf"},
+				{Main: "traefik.io", SANs: []string{"www.traefik.io", "api.traefik.io"}},
+			},
+		},
+		{
+			desc: "empty lines and comments",
+			lines: []string{
+				"# migrated from the previous cluster",
+				"",
+				"  ",
+				"traefik.wtf",
+			},
+			expected: []types.Domain{
+				{Main: "traefik.wtf"},
+			},
+		},
+		{
+			desc:        "empty domain",
+			lines:       []string{"traefik.io,,www.traefik.io"},
+			expectedErr: `empty domain in "traefik.io,,www.traefik.io"`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			domains, err := parseWarmUpDomains(test.lines)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, domains)
+		})
+	}
+}
+
+func TestWarmUp_domains(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/v1/var/traefik/warmup" || req.URL.Query().Get("namespace") != "ingress" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = fmt.Fprint(rw, `{"Namespace": "ingress", "Path": "traefik/warmup", "Items": {"b": "api.traefik.io", "a": "traefik.io,www.traefik.io"}}`)
+	}))
+	t.Cleanup(ts.Close)
+
+	file := filepath.Join(t.TempDir(), "domains.txt")
+	err := os.WriteFile(file, []byte("# domains\ntraefik.wtf\n"), 0o600)
+	require.NoError(t, err)
+
+	warmUp := &WarmUp{
+		File: file,
+		Nomad: &WarmUpNomad{
+			Address:   ts.URL,
+			Namespace: "ingress",
+			Path:      "/traefik/warmup",
+		},
+	}
+
+	domains, err := warmUp.domains(context.Background())
+	require.NoError(t, err)
+
+	expected := []types.Domain{
+		{Main: "traefik.wtf"},
+		{Main: "traefik.io", SANs: []string{"www.traefik.io"}},
+		{Main: "api.traefik.io"},
+	}
+	assert.Equal(t, expected, domains)
+
+	warmUp.Nomad.Path = "traefik/missing"
+	_, err = warmUp.domains(context.Background())
+	require.Error(t, err)
+
+	warmUp.File = filepath.Join(t.TempDir(), "missing.txt")
+	_, err = warmUp.domains(context.Background())
+	require.Error(t, err)
+}
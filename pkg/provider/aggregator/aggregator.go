@@ -9,6 +9,7 @@ import (
 	"github.com/traefik/traefik/v3/pkg/config/static"
 	"github.com/traefik/traefik/v3/pkg/provider"
 	"github.com/traefik/traefik/v3/pkg/provider/file"
+	"github.com/traefik/traefik/v3/pkg/provider/nomad"
 	"github.com/traefik/traefik/v3/pkg/provider/traefik"
 	"github.com/traefik/traefik/v3/pkg/redactor"
 	"github.com/traefik/traefik/v3/pkg/safe"
@@ -163,6 +164,18 @@ func (p *ProviderAggregator) AddProvider(provider provider.Provider) error {
 	return nil
 }
 
+// NomadProviders returns the Nomad providers of the aggregate.
+func (p ProviderAggregator) NomadProviders() []*nomad.Provider {
+	var providers []*nomad.Provider
+	for _, prd := range p.providers {
+		if nomadProvider, ok := prd.(*nomad.Provider); ok {
+			providers = append(providers, nomadProvider)
+		}
+	}
+
+	return providers
+}
+
 // Init the provider.
 func (p ProviderAggregator) Init() error {
 	return nil
@@ -68,6 +68,12 @@ func Merge(ctx context.Context, configurations map[string]*dynamic.Configuration
 	transportsTCPToDelete := map[string]struct{}{}
 	transportsTCP := map[string][]string{}
 
+	entryPointsTCPToDelete := map[string]struct{}{}
+	entryPointsTCP := map[string][]string{}
+
+	entryPointsUDPToDelete := map[string]struct{}{}
+	entryPointsUDP := map[string][]string{}
+
 	var sortedKeys []string
 	for key := range configurations {
 		sortedKeys = append(sortedKeys, key)
@@ -118,6 +124,13 @@ func Merge(ctx context.Context, configurations map[string]*dynamic.Configuration
 			}
 		}
 
+		for entryPointName, entryPoint := range conf.TCP.EntryPoints {
+			entryPointsTCP[entryPointName] = append(entryPointsTCP[entryPointName], root)
+			if !AddEntryPointTCP(configuration.TCP, entryPointName, entryPoint) {
+				entryPointsTCPToDelete[entryPointName] = struct{}{}
+			}
+		}
+
 		for serviceName, service := range conf.UDP.Services {
 			servicesUDP[serviceName] = append(servicesUDP[serviceName], root)
 			if !AddServiceUDP(configuration.UDP, serviceName, service) {
@@ -132,6 +145,13 @@ func Merge(ctx context.Context, configurations map[string]*dynamic.Configuration
 			}
 		}
 
+		for entryPointName, entryPoint := range conf.UDP.EntryPoints {
+			entryPointsUDP[entryPointName] = append(entryPointsUDP[entryPointName], root)
+			if !AddEntryPointUDP(configuration.UDP, entryPointName, entryPoint) {
+				entryPointsUDPToDelete[entryPointName] = struct{}{}
+			}
+		}
+
 		for middlewareName, middleware := range conf.HTTP.Middlewares {
 			middlewares[middlewareName] = append(middlewares[middlewareName], root)
 			if !AddMiddleware(configuration.HTTP, middlewareName, middleware) {
@@ -189,6 +209,13 @@ func Merge(ctx context.Context, configurations map[string]*dynamic.Configuration
 		delete(configuration.TCP.ServersTransports, transportName)
 	}
 
+	for entryPointName := range entryPointsTCPToDelete {
+		logger.Error().Str(logs.EntryPointName, entryPointName).
+			Interface("configuration", entryPointsTCP[entryPointName]).
+			Msg("EntryPoint TCP defined multiple times with different configurations")
+		delete(configuration.TCP.EntryPoints, entryPointName)
+	}
+
 	for serviceName := range servicesUDPToDelete {
 		logger.Error().Str(logs.ServiceName, serviceName).
 			Interface("configuration", servicesUDP[serviceName]).
@@ -203,6 +230,13 @@ func Merge(ctx context.Context, configurations map[string]*dynamic.Configuration
 		delete(configuration.UDP.Routers, routerName)
 	}
 
+	for entryPointName := range entryPointsUDPToDelete {
+		logger.Error().Str(logs.EntryPointName, entryPointName).
+			Interface("configuration", entryPointsUDP[entryPointName]).
+			Msg("EntryPoint UDP defined multiple times with different configurations")
+		delete(configuration.UDP.EntryPoints, entryPointName)
+	}
+
 	for middlewareName := range middlewaresToDelete {
 		logger.Error().Str(logs.MiddlewareName, middlewareName).
 			Interface("configuration", middlewares[middlewareName]).
@@ -275,6 +309,20 @@ func AddTransportTCP(configuration *dynamic.TCPConfiguration, transportName stri
 	return reflect.DeepEqual(configuration.ServersTransports[transportName], transport)
 }
 
+// AddEntryPointTCP adds an entrypoint to a configuration.
+func AddEntryPointTCP(configuration *dynamic.TCPConfiguration, entryPointName string, entryPoint *dynamic.TCPEntryPoint) bool {
+	if configuration.EntryPoints == nil {
+		configuration.EntryPoints = make(map[string]*dynamic.TCPEntryPoint)
+	}
+
+	if _, ok := configuration.EntryPoints[entryPointName]; !ok {
+		configuration.EntryPoints[entryPointName] = entryPoint
+		return true
+	}
+
+	return reflect.DeepEqual(configuration.EntryPoints[entryPointName], entryPoint)
+}
+
 // AddServiceUDP adds a service to a configuration.
 func AddServiceUDP(configuration *dynamic.UDPConfiguration, serviceName string, service *dynamic.UDPService) bool {
 	if _, ok := configuration.Services[serviceName]; !ok {
@@ -310,6 +358,20 @@ func AddRouterUDP(configuration *dynamic.UDPConfiguration, routerName string, ro
 	return reflect.DeepEqual(configuration.Routers[routerName], router)
 }
 
+// AddEntryPointUDP adds an entrypoint to a configuration.
+func AddEntryPointUDP(configuration *dynamic.UDPConfiguration, entryPointName string, entryPoint *dynamic.UDPEntryPoint) bool {
+	if configuration.EntryPoints == nil {
+		configuration.EntryPoints = make(map[string]*dynamic.UDPEntryPoint)
+	}
+
+	if _, ok := configuration.EntryPoints[entryPointName]; !ok {
+		configuration.EntryPoints[entryPointName] = entryPoint
+		return true
+	}
+
+	return reflect.DeepEqual(configuration.EntryPoints[entryPointName], entryPoint)
+}
+
 // AddService adds a service to a configuration.
 func AddService(configuration *dynamic.HTTPConfiguration, serviceName string, service *dynamic.Service) bool {
 	if _, ok := configuration.Services[serviceName]; !ok {
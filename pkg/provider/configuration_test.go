@@ -0,0 +1,64 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func BenchmarkMerge(b *testing.B) {
+	for _, size := range []int{10, 100, 1000, 5000} {
+		configurations := syntheticConfigurations(size)
+
+		b.Run(fmt.Sprintf("configurations=%d", size), func(b *testing.B) {
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				Merge(ctx, configurations)
+			}
+		})
+	}
+}
+
+// syntheticConfigurations returns size configurations, each defining its own router and service,
+// and sharing an identical middleware definition with the other configurations of the same group.
+func syntheticConfigurations(size int) map[string]*dynamic.Configuration {
+	configurations := make(map[string]*dynamic.Configuration, size)
+	for i := 0; i < size; i++ {
+		name := fmt.Sprintf("svc-%d", i)
+		middleware := fmt.Sprintf("strip-%d", i/3)
+
+		configurations[name] = &dynamic.Configuration{
+			HTTP: &dynamic.HTTPConfiguration{
+				Routers: map[string]*dynamic.Router{
+					name: {
+						Rule:        fmt.Sprintf("Host(`%s.example.com`)", name),
+						Service:     name,
+						Middlewares: []string{middleware},
+					},
+				},
+				Middlewares: map[string]*dynamic.Middleware{
+					middleware: {
+						StripPrefix: &dynamic.StripPrefix{Prefixes: []string{"/" + middleware}},
+					},
+				},
+				Services: map[string]*dynamic.Service{
+					name: {
+						LoadBalancer: &dynamic.ServersLoadBalancer{
+							Servers: []dynamic.Server{{URL: fmt.Sprintf("http://10.0.0.%d:80", i%250+1)}},
+						},
+					},
+				},
+			},
+			TCP: &dynamic.TCPConfiguration{},
+			UDP: &dynamic.UDPConfiguration{},
+		}
+	}
+
+	return configurations
+}
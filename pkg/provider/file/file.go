@@ -451,6 +451,18 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 			}
 		}
 
+		for name, conf := range c.TCP.EntryPoints {
+			if configuration.TCP.EntryPoints == nil {
+				configuration.TCP.EntryPoints = make(map[string]*dynamic.TCPEntryPoint)
+			}
+
+			if _, exists := configuration.TCP.EntryPoints[name]; exists {
+				logger.Warn().Str(logs.EntryPointName, name).Msg("TCP entrypoint already configured, skipping")
+			} else {
+				configuration.TCP.EntryPoints[name] = conf
+			}
+		}
+
 		for name, conf := range c.UDP.Routers {
 			if _, exists := configuration.UDP.Routers[name]; exists {
 				logger.Warn().Str(logs.RouterName, name).Msg("UDP router already configured, skipping")
@@ -467,6 +479,18 @@ func (p *Provider) loadFileConfigFromDirectory(ctx context.Context, directory st
 			}
 		}
 
+		for name, conf := range c.UDP.EntryPoints {
+			if configuration.UDP.EntryPoints == nil {
+				configuration.UDP.EntryPoints = make(map[string]*dynamic.UDPEntryPoint)
+			}
+
+			if _, exists := configuration.UDP.EntryPoints[name]; exists {
+				logger.Warn().Str(logs.EntryPointName, name).Msg("UDP entrypoint already configured, skipping")
+			} else {
+				configuration.UDP.EntryPoints[name] = conf
+			}
+		}
+
 		for _, conf := range c.TLS.Certificates {
 			if _, exists := configTLSMaps[conf]; exists {
 				logger.Warn().Msgf("TLS configuration %v already configured, skipping", conf)
@@ -0,0 +1,201 @@
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/traefik/traefik/v3/pkg/config/runtime"
+)
+
+// benchmarkSizes are the numbers of simulated Nomad services used by the benchmarks.
+var benchmarkSizes = []int{10, 100, 1000, 5000}
+
+// syntheticCluster simulates a Nomad cluster running services with instances allocations each.
+type syntheticCluster struct {
+	services  int
+	instances int
+}
+
+func (c syntheticCluster) serviceName(s int) string {
+	return fmt.Sprintf("svc-%d", s)
+}
+
+func (c syntheticCluster) tags(s int) []string {
+	name := c.serviceName(s)
+
+	tags := []string{
+		"traefik.enable=true",
+		fmt.Sprintf("traefik.http.routers.%s.rule=Host(`%s.example.com`)", name, name),
+		fmt.Sprintf("traefik.http.routers.%s.entrypoints=websecure", name),
+	}
+
+	// a third of the services use a middleware, shared by all the instances of the service.
+	if s%3 == 0 {
+		tags = append(tags,
+			fmt.Sprintf("traefik.http.routers.%s.middlewares=%s-strip", name, name),
+			fmt.Sprintf("traefik.http.middlewares.%s-strip.stripprefix.prefixes=/%s", name, name),
+		)
+	}
+
+	return tags
+}
+
+func (c syntheticCluster) registrations(s int) []*api.ServiceRegistration {
+	registrations := make([]*api.ServiceRegistration, 0, c.instances)
+	for i := 0; i < c.instances; i++ {
+		allocID := fmt.Sprintf("%08d-0000-0000-0000-%012d", s, i)
+		registrations = append(registrations, &api.ServiceRegistration{
+			ID:          fmt.Sprintf("_nomad-task-%s-group-%s-%s-http", allocID, c.serviceName(s), c.serviceName(s)),
+			ServiceName: c.serviceName(s),
+			Namespace:   "default",
+			NodeID:      fmt.Sprintf("node-%d", i),
+			Datacenter:  "dc1",
+			JobID:       c.serviceName(s),
+			AllocID:     allocID,
+			Tags:        c.tags(s),
+			Address:     fmt.Sprintf("10.0.%d.%d", (s/250)%250, s%250+1),
+			Port:        20000 + i,
+		})
+	}
+	return registrations
+}
+
+// items returns the items the provider would discover from the cluster.
+func (c syntheticCluster) items() []item {
+	items := make([]item, 0, c.services*c.instances)
+	for s := 0; s < c.services; s++ {
+		for _, r := range c.registrations(s) {
+			items = append(items, item{
+				ID:         r.ID,
+				Name:       r.ServiceName,
+				Namespace:  r.Namespace,
+				Node:       r.NodeID,
+				Datacenter: r.Datacenter,
+				AllocID:    r.AllocID,
+				Address:    r.Address,
+				Port:       r.Port,
+				Tags:       r.Tags,
+				ExtraConf:  configuration{Enable: true},
+			})
+		}
+	}
+	return items
+}
+
+// server returns a fake Nomad API serving the cluster services.
+func (c syntheticCluster) server(b *testing.B) *httptest.Server {
+	b.Helper()
+
+	stub := api.ServiceRegistrationListStub{Namespace: "default"}
+	services := make(map[string][]byte, c.services)
+	for s := 0; s < c.services; s++ {
+		stub.Services = append(stub.Services, &api.ServiceRegistrationStub{
+			ServiceName: c.serviceName(s),
+			Tags:        c.tags(s),
+		})
+
+		data, err := json.Marshal(c.registrations(s))
+		if err != nil {
+			b.Fatal(err)
+		}
+		services[c.serviceName(s)] = data
+	}
+
+	list, err := json.Marshal([]*api.ServiceRegistrationListStub{&stub})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/v1/services") {
+			_, _ = w.Write(list)
+			return
+		}
+
+		data, ok := services[strings.TrimPrefix(r.URL.Path, "/v1/service/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	b.Cleanup(ts.Close)
+
+	return ts
+}
+
+func newBenchmarkProvider(b *testing.B, address string) *Provider {
+	b.Helper()
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.DefaultRule = "Host(`{{ normalize .Name }}.traefik.test`)"
+	if err := p.Init(); err != nil {
+		b.Fatal(err)
+	}
+
+	if address != "" {
+		p.Endpoint.Address = address
+
+		var err error
+		p.client, err = createClient(p.namespace, p.Endpoint, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	return p
+}
+
+// BenchmarkProvider_buildConfig measures the time to build and merge the dynamic configuration from discovered items.
+func BenchmarkProvider_buildConfig(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		cluster := syntheticCluster{services: size, instances: 3}
+
+		b.Run(fmt.Sprintf("services=%d", size), func(b *testing.B) {
+			p := newBenchmarkProvider(b, "")
+			items := cluster.items()
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				p.buildConfig(ctx, items)
+			}
+		})
+	}
+}
+
+// BenchmarkProvider_pipeline measures the end-to-end time from the Nomad API listing to the runtime router table.
+func BenchmarkProvider_pipeline(b *testing.B) {
+	for _, size := range benchmarkSizes {
+		cluster := syntheticCluster{services: size, instances: 3}
+
+		b.Run(fmt.Sprintf("services=%d", size), func(b *testing.B) {
+			p := newBenchmarkProvider(b, cluster.server(b).URL)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				items, err := p.getNomadServiceData(ctx)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				rtConf := runtime.NewConfig(*p.buildConfig(ctx, items))
+				if len(rtConf.Routers) != size {
+					b.Fatalf("got %d routers, want %d", len(rtConf.Routers), size)
+				}
+			}
+		})
+	}
+}
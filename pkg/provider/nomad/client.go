@@ -0,0 +1,129 @@
+package nomad
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+)
+
+// maxDrainedBodySize is the size of the remainder of a response body read before closing it,
+// for the connection to be reused: the Nomad API client closes the bodies after decoding them,
+// which leaves the trailing newline of the JSON documents unread.
+const maxDrainedBodySize = 4 << 10
+
+// EndpointTransport holds the configuration of the HTTP transport of the Nomad API client.
+type EndpointTransport struct {
+	MaxIdleConnsPerHost int             `description:"Maximum number of idle connections kept open to the Nomad server." json:"maxIdleConnsPerHost,omitempty" toml:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty" export:"true"`
+	IdleConnTimeout     ptypes.Duration `description:"Duration an idle connection to the Nomad server is kept open." json:"idleConnTimeout,omitempty" toml:"idleConnTimeout,omitempty" yaml:"idleConnTimeout,omitempty" export:"true"`
+	DialTimeout         ptypes.Duration `description:"Timeout of the connection to the Nomad server." json:"dialTimeout,omitempty" toml:"dialTimeout,omitempty" yaml:"dialTimeout,omitempty" export:"true"`
+	KeepAlive           ptypes.Duration `description:"Interval between the TCP keep-alive probes of the connections to the Nomad server." json:"keepAlive,omitempty" toml:"keepAlive,omitempty" yaml:"keepAlive,omitempty" export:"true"`
+	TLSHandshakeTimeout ptypes.Duration `description:"Timeout of the TLS handshake with the Nomad server." json:"tlsHandshakeTimeout,omitempty" toml:"tlsHandshakeTimeout,omitempty" yaml:"tlsHandshakeTimeout,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values of the HTTP transport.
+func (t *EndpointTransport) SetDefaults() {
+	t.MaxIdleConnsPerHost = 10
+	t.IdleConnTimeout = ptypes.Duration(90 * time.Second)
+	t.DialTimeout = ptypes.Duration(30 * time.Second)
+	t.KeepAlive = ptypes.Duration(30 * time.Second)
+	t.TLSHandshakeTimeout = ptypes.Duration(10 * time.Second)
+}
+
+// newHTTPClient returns the HTTP client shared by the Nomad API clients of the provider, one per region,
+// so that they reuse the connections to the Nomad server between the refreshes.
+func (p *Provider) newHTTPClient() (*http.Client, error) {
+	config := p.Endpoint.Transport
+	if config == nil {
+		config = &EndpointTransport{}
+		config.SetDefaults()
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   time.Duration(config.DialTimeout),
+			KeepAlive: time.Duration(config.KeepAlive),
+		}).DialContext,
+		MaxIdleConns:        config.MaxIdleConnsPerHost,
+		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
+		IdleConnTimeout:     time.Duration(config.IdleConnTimeout),
+		TLSHandshakeTimeout: time.Duration(config.TLSHandshakeTimeout),
+		TLSClientConfig:     &tls.Config{MinVersion: tls.VersionTLS12},
+		// as the default Nomad API client, HTTP/2 is not used, the alloc exec and websocket endpoints not supporting it.
+		ForceAttemptHTTP2: false,
+	}
+
+	client := &http.Client{Transport: transport}
+
+	// the TLS configuration of the Nomad API client is ignored when its HTTP client is set.
+	if p.Endpoint.TLS != nil {
+		err := api.ConfigureTLS(client, &api.TLSConfig{
+			CACert:        p.Endpoint.TLS.CA,
+			ClientCert:    p.Endpoint.TLS.Cert,
+			ClientKey:     p.Endpoint.TLS.Key,
+			TLSServerName: p.Endpoint.TLS.ServerName,
+			Insecure:      p.Endpoint.TLS.InsecureSkipVerify,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	client.Transport = &instrumentedTransport{
+		next:            transport,
+		provider:        p.name,
+		metricsRegistry: p.metricsRegistry,
+	}
+
+	return client, nil
+}
+
+// instrumentedTransport reports the metrics of the requests to the Nomad API,
+// and drains the response bodies for the connections to be reused.
+type instrumentedTransport struct {
+	next            http.RoundTripper
+	provider        string
+	metricsRegistry metrics.Registry // nil when not set
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+
+	resp, err := t.next.RoundTrip(req)
+
+	if t.metricsRegistry != nil {
+		code := "error"
+		if err == nil {
+			code = strconv.Itoa(resp.StatusCode)
+		}
+
+		labels := []string{"provider", t.provider, "method", req.Method, "code", code}
+		t.metricsRegistry.ProviderAPIReqsCounter().With(labels...).Add(1)
+		t.metricsRegistry.ProviderAPIReqDurationHistogram().With(labels...).ObserveFromStart(start)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = &drainedBody{ReadCloser: resp.Body}
+
+	return resp, nil
+}
+
+// drainedBody reads the remainder of a response body, up to maxDrainedBodySize, before closing it.
+type drainedBody struct {
+	io.ReadCloser
+}
+
+func (b *drainedBody) Close() error {
+	_, _ = io.CopyN(io.Discard, b.ReadCloser, maxDrainedBodySize)
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,74 @@
+package nomad
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+	"github.com/traefik/traefik/v3/pkg/testhelpers"
+)
+
+type collectingAPIMetrics struct {
+	metrics.Registry
+	reqs *testhelpers.CollectingCounter
+}
+
+func (m *collectingAPIMetrics) ProviderAPIReqsCounter() gokitmetrics.Counter {
+	return m.reqs
+}
+
+func TestProvider_newHTTPClient(t *testing.T) {
+	var conns atomic.Int32
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/v1/services"):
+			// the JSON encoder of the Nomad API ends the documents with a newline.
+			_, _ = w.Write([]byte(servicesRedis + "\n"))
+		case strings.HasSuffix(r.URL.Path, "/v1/service/redis"):
+			_, _ = w.Write([]byte(redis + "\n"))
+		}
+	}))
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	ts.Start()
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	err := p.Init()
+	require.NoError(t, err)
+
+	registry := &collectingAPIMetrics{
+		Registry: metrics.NewVoidRegistry(),
+		reqs:     &testhelpers.CollectingCounter{},
+	}
+	p.SetMetricsRegistry(registry)
+
+	p.httpClient, err = p.newHTTPClient()
+	require.NoError(t, err)
+
+	p.client, err = createClient(p.namespace, p.Endpoint, p.httpClient)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = p.getNomadServiceData(context.Background())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), conns.Load(), "the connection is reused between the refreshes")
+	assert.Equal(t, 6.0, registry.reqs.CounterValue)
+	assert.Equal(t, []string{"provider", "nomad", "method", http.MethodGet, "code", "200"}, registry.reqs.LastLabelValues)
+}
@@ -1,6 +1,7 @@
 package nomad
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -21,6 +22,27 @@ import (
 func (p *Provider) buildConfig(ctx context.Context, items []item) *dynamic.Configuration {
 	configurations := make(map[string]*dynamic.Configuration)
 
+	// failover tiers of the HTTP services, indexed by service name.
+	tiers := make(map[string]map[int]struct{})
+	// localities of the HTTP services whose remote servers are weighted against the local ones, indexed by service name.
+	localities := make(map[string]map[bool]struct{})
+	// mirrors of the HTTP services, indexed by service name.
+	mirrors := make(map[string]dynamic.MirrorService)
+	// job versions of the HTTP services whose job versions are weighted, with their weights, indexed by service name.
+	versions := make(map[string]map[uint64]int)
+	versionWeights := getVersionWeights(items)
+
+	var configErrors []ConfigurationError
+	defer func() { p.setConfigurationErrors(configErrors) }()
+
+	// service instances kept on this refresh, with the configuration built from their tags.
+	var discovered []DiscoveredService
+	defer func() { p.setDiscoveredServices(discovered, configErrors) }()
+
+	// instances backing the HTTP servers, indexed by server URL.
+	instances := make(map[string]Instance)
+	defer func() { p.setInstances(instances) }()
+
 	for _, i := range items {
 		svcName := provider.Normalize(i.Node + "-" + i.Name + "-" + i.ID)
 		logger := log.Ctx(ctx).With().Str(logs.ServiceName, svcName).Logger()
@@ -30,11 +52,41 @@ func (p *Provider) buildConfig(ctx context.Context, items []item) *dynamic.Confi
 			continue
 		}
 
-		labels := tagsToLabels(i.Tags, p.Prefix)
+		discovered = append(discovered, p.newDiscoveredService(i))
+		discoveredSvc := &discovered[len(discovered)-1]
+
+		if err := p.verifyTags(i); err != nil {
+			logger.Error().Err(err).Msg("Skipping service with unverified tags")
+			configErrors = append(configErrors, newConfigurationError(i, err))
+			continue
+		}
+
+		labels, transportLabels := splitServersTransportLabels(tagsToLabels(i.Tags, p.Prefix))
 
 		config, err := label.DecodeConfiguration(labels)
 		if err != nil {
-			logger.Error().Err(err).Msg("Failed to decode configuration")
+			configErr := newConfigurationError(i, err)
+			configErr.Tags = validateLabels(labels, p.Prefix)
+			for _, tagErr := range configErr.Tags {
+				logger.Error().Str("tag", tagErr.Tag).Str("value", tagErr.Value).Str("suggestion", tagErr.Suggestion).Msgf("Invalid tag: %s", tagErr.Message)
+			}
+
+			if !p.DefaultRoutingOnError {
+				logger.Error().Err(err).Msg("Failed to decode configuration")
+				configErrors = append(configErrors, configErr)
+				continue
+			}
+
+			logger.Error().Err(err).Msg("Failed to decode configuration, routing with the default rule")
+			configErr.Fallback = true
+			configErrors = append(configErrors, configErr)
+
+			config, _ = label.DecodeConfiguration(nil)
+		}
+
+		// only the HTTP services are drained.
+		if i.Draining && (len(config.TCP.Routers) > 0 || len(config.TCP.Services) > 0 || len(config.UDP.Routers) > 0 || len(config.UDP.Services) > 0) {
+			logger.Debug().Msg("Skip draining TCP or UDP service")
 			continue
 		}
 
@@ -44,6 +96,7 @@ func (p *Provider) buildConfig(ctx context.Context, items []item) *dynamic.Confi
 			tcpOrUDP = true
 			if err := p.buildTCPConfig(i, config.TCP); err != nil {
 				logger.Error().Err(err).Msg("Failed to build TCP service configuration")
+				configErrors = append(configErrors, newConfigurationError(i, err))
 				continue
 			}
 			provider.BuildTCPRouterConfiguration(ctxSvc, config.TCP)
@@ -53,6 +106,7 @@ func (p *Provider) buildConfig(ctx context.Context, items []item) *dynamic.Confi
 			tcpOrUDP = true
 			if err := p.buildUDPConfig(i, config.UDP); err != nil {
 				logger.Error().Err(err).Msg("Failed to build UDP service configuration")
+				configErrors = append(configErrors, newConfigurationError(i, err))
 				continue
 			}
 			provider.BuildUDPRouterConfiguration(ctxSvc, config.UDP)
@@ -62,6 +116,8 @@ func (p *Provider) buildConfig(ctx context.Context, items []item) *dynamic.Confi
 		if tcpOrUDP && len(config.HTTP.Routers) == 0 &&
 			len(config.HTTP.Middlewares) == 0 &&
 			len(config.HTTP.Services) == 0 {
+			p.applyNamespacePolicy(ctxSvc, i, config)
+			p.addResources(discoveredSvc, config)
 			configurations[svcName] = config
 			continue
 		}
@@ -69,22 +125,147 @@ func (p *Provider) buildConfig(ctx context.Context, items []item) *dynamic.Confi
 		// configure http service
 		if err := p.buildServiceConfig(i, config.HTTP); err != nil {
 			logger.Error().Err(err).Msg("Failed to build HTTP service configuration")
+			configErrors = append(configErrors, newConfigurationError(i, err))
+			continue
+		}
+
+		if err := addServersTransport(i, config.HTTP, transportLabels); err != nil {
+			logger.Error().Err(err).Msg("Failed to build the servers transport")
+			configErrors = append(configErrors, newConfigurationError(i, err))
+			continue
+		}
+
+		if i.ExtraConf.Mirror != nil {
+			addMirror(i, config.HTTP, mirrors)
+			addInstances(i, config.HTTP, instances)
+			p.addResources(discoveredSvc, config)
+			configurations[svcName] = config
 			continue
 		}
 
 		model := struct {
-			Name   string
-			Labels map[string]string
+			Name       string
+			Labels     map[string]string
+			Namespace  string
+			Job        string
+			Datacenter string
+			NodeName   string
+			AllocID    string
+			Tags       map[string]string
 		}{
-			Name:   i.Name,
-			Labels: labels,
+			Name:       i.Name,
+			Labels:     labels,
+			Namespace:  i.Namespace,
+			Job:        i.Job,
+			Datacenter: i.Datacenter,
+			NodeName:   i.NodeName,
+			AllocID:    i.AllocID,
+			Tags:       tagsToMap(i.Tags),
 		}
 
+		defaultRouters := defaultRuleRouters(config.HTTP, getName(i))
+
 		provider.BuildRouterConfiguration(ctx, config.HTTP, getName(i), p.defaultRuleTpl, model)
+		addRouterDefaults(i, config.HTTP)
+		// the policy applies before the provider attaches its own middlewares.
+		p.applyNamespacePolicy(ctxSvc, i, config)
+		if err := p.addStripPrefix(i, config.HTTP, defaultRouters, model); err != nil {
+			logger.Error().Err(err).Msg("Failed to build the default path prefix")
+			configErrors = append(configErrors, newConfigurationError(i, err))
+			continue
+		}
+		p.addSecureHeaders(i, config.HTTP)
+		p.addJobHeaders(i, config.HTTP)
+		p.addGuardrails(i, config.HTTP)
+		p.addResourcePressure(i, config.HTTP)
+		addFailoverTier(p.failoverTier(i), config.HTTP, tiers)
+		p.addLocality(i, config.HTTP, localities)
+		addJobVersion(i, config.HTTP, versionWeights, versions)
+		addInstances(i, config.HTTP, instances)
+		p.addResources(discoveredSvc, config)
 		configurations[svcName] = config
 	}
 
-	return provider.Merge(ctx, configurations)
+	merged := provider.Merge(ctx, configurations)
+	// the services of the job versions are built first, as they are part of the localities or failover tiers.
+	buildJobVersionServices(ctx, merged.HTTP, versions)
+	p.buildLocalityServices(ctx, merged.HTTP, localities)
+	buildFailoverServices(ctx, merged.HTTP, tiers)
+	buildMirroringServices(ctx, merged.HTTP, mirrors)
+
+	return merged
+}
+
+// addFailoverTier renames the HTTP services of an item which is part of a failover after their tier,
+// the routers keep referencing the service name, which becomes the failover service.
+func addFailoverTier(tier int, configuration *dynamic.HTTPConfiguration, tiers map[string]map[int]struct{}) {
+	if tier == 0 {
+		return
+	}
+
+	services := make(map[string]*dynamic.Service, len(configuration.Services))
+	for name, service := range configuration.Services {
+		services[failoverTierName(name, tier)] = service
+
+		if tiers[name] == nil {
+			tiers[name] = make(map[int]struct{})
+		}
+		tiers[name][tier] = struct{}{}
+	}
+	configuration.Services = services
+}
+
+// buildFailoverServices builds, for each service which is part of a failover, a chain of failover services:
+// the requests go to the lowest tier, and fail over to the next tier when all its servers are down.
+func buildFailoverServices(ctx context.Context, configuration *dynamic.HTTPConfiguration, tiers map[string]map[int]struct{}) {
+	for name, serviceTiers := range tiers {
+		logger := log.Ctx(ctx).With().Str(logs.ServiceName, name).Logger()
+
+		if _, exists := configuration.Services[name]; exists {
+			logger.Error().Msg("Service defined both with and without a failover tier, skipping the failover")
+			continue
+		}
+
+		var sorted []int
+		for tier := range serviceTiers {
+			// the service of a tier may have been dropped by the merge, because of conflicting definitions.
+			if _, exists := configuration.Services[failoverTierName(name, tier)]; exists {
+				sorted = append(sorted, tier)
+			}
+		}
+		sort.Ints(sorted)
+
+		switch len(sorted) {
+		case 0:
+			continue
+		case 1:
+			// nothing to fail over to.
+			tierName := failoverTierName(name, sorted[0])
+			configuration.Services[name] = configuration.Services[tierName]
+			delete(configuration.Services, tierName)
+			continue
+		}
+
+		fallback := failoverTierName(name, sorted[len(sorted)-1])
+		for k := len(sorted) - 2; k >= 0; k-- {
+			failoverName := name
+			if k > 0 {
+				failoverName = fmt.Sprintf("%s-failover-tier%d", name, sorted[k])
+			}
+
+			configuration.Services[failoverName] = &dynamic.Service{
+				Failover: &dynamic.Failover{
+					Service:  failoverTierName(name, sorted[k]),
+					Fallback: fallback,
+				},
+			}
+			fallback = failoverName
+		}
+	}
+}
+
+func failoverTierName(name string, tier int) string {
+	return fmt.Sprintf("%s-tier%d", name, tier)
 }
 
 func (p *Provider) buildTCPConfig(i item, configuration *dynamic.TCPConfiguration) error {
@@ -144,6 +325,166 @@ func (p *Provider) buildServiceConfig(i item, configuration *dynamic.HTTPConfigu
 	return nil
 }
 
+// addSecureHeaders attaches the secure headers middleware to the routers bound to a public entrypoint.
+// Routers without entrypoints are bound to all the default entrypoints, and are therefore considered public.
+func (p *Provider) addSecureHeaders(i item, configuration *dynamic.HTTPConfiguration) {
+	if p.SecureHeaders == nil || i.ExtraConf.SkipSecureHeaders {
+		return
+	}
+
+	var attached bool
+	for _, router := range configuration.Routers {
+		if !isPublicRouter(router, p.SecureHeaders.EntryPoints) {
+			continue
+		}
+
+		router.Middlewares = append([]string{secureHeadersMiddlewareName}, router.Middlewares...)
+		attached = true
+	}
+
+	if !attached {
+		return
+	}
+
+	if configuration.Middlewares == nil {
+		configuration.Middlewares = make(map[string]*dynamic.Middleware)
+	}
+
+	configuration.Middlewares[secureHeadersMiddlewareName] = &dynamic.Middleware{
+		Headers: &dynamic.Headers{
+			STSSeconds:           p.SecureHeaders.STSSeconds,
+			STSIncludeSubdomains: p.SecureHeaders.STSIncludeSubdomains,
+			FrameDeny:            p.SecureHeaders.FrameDeny,
+			ContentTypeNosniff:   p.SecureHeaders.ContentTypeNosniff,
+		},
+	}
+}
+
+// addJobHeaders attaches to the routers the headers middleware adding the job and the namespace of the service to the forwarded requests.
+// The middleware is named after the namespace and the job, so that the routers of all the instances of the job are identical.
+// The allocation is specific to each server, it is therefore not propagated, as a router does not know the server it forwards to.
+func (p *Provider) addJobHeaders(i item, configuration *dynamic.HTTPConfiguration) {
+	if !p.JobHeaders || len(configuration.Routers) == 0 {
+		return
+	}
+
+	name := provider.Normalize(jobHeadersMiddlewarePrefix + i.Namespace + "-" + i.Job)
+
+	for _, router := range configuration.Routers {
+		router.Middlewares = append(router.Middlewares, name)
+	}
+
+	if configuration.Middlewares == nil {
+		configuration.Middlewares = make(map[string]*dynamic.Middleware)
+	}
+
+	configuration.Middlewares[name] = &dynamic.Middleware{
+		Headers: &dynamic.Headers{
+			CustomRequestHeaders: map[string]string{
+				"X-Nomad-Job":       i.Job,
+				"X-Nomad-Namespace": i.Namespace,
+			},
+		},
+	}
+}
+
+// addRouterDefaults sets the default middlewares and entrypoints of the service
+// on its HTTP routers which do not declare their own.
+func addRouterDefaults(i item, configuration *dynamic.HTTPConfiguration) {
+	for _, router := range configuration.Routers {
+		if len(router.Middlewares) == 0 && len(i.ExtraConf.RouterMiddlewares) > 0 {
+			router.Middlewares = append([]string(nil), i.ExtraConf.RouterMiddlewares...)
+		}
+
+		if len(router.EntryPoints) == 0 && len(i.ExtraConf.RouterEntryPoints) > 0 {
+			router.EntryPoints = append([]string(nil), i.ExtraConf.RouterEntryPoints...)
+		}
+	}
+}
+
+// splitList splits a comma separated list of values, ignoring the empty ones.
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// defaultRuleRouters returns the names of the routers which get their rule from the default rule template.
+func defaultRuleRouters(configuration *dynamic.HTTPConfiguration, defaultRouterName string) []string {
+	if len(configuration.Routers) == 0 {
+		return []string{defaultRouterName}
+	}
+
+	var names []string
+	for name, router := range configuration.Routers {
+		if router.Rule == "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// addStripPrefix attaches a middleware stripping the default path prefix to the routers using the default path rule.
+func (p *Provider) addStripPrefix(i item, configuration *dynamic.HTTPConfiguration, routerNames []string, model interface{}) error {
+	if p.defaultPathTpl == nil {
+		return nil
+	}
+
+	writer := &bytes.Buffer{}
+	if err := p.defaultPathTpl.Execute(writer, model); err != nil {
+		return err
+	}
+	prefix := writer.String()
+
+	middlewareName := getName(i) + "-stripprefix"
+
+	var attached bool
+	for _, name := range routerNames {
+		router, ok := configuration.Routers[name]
+		if !ok {
+			continue
+		}
+
+		router.Middlewares = append(router.Middlewares, middlewareName)
+		attached = true
+	}
+
+	if !attached {
+		return nil
+	}
+
+	if configuration.Middlewares == nil {
+		configuration.Middlewares = make(map[string]*dynamic.Middleware)
+	}
+
+	configuration.Middlewares[middlewareName] = &dynamic.Middleware{
+		StripPrefix: &dynamic.StripPrefix{Prefixes: []string{prefix}},
+	}
+
+	return nil
+}
+
+func isPublicRouter(router *dynamic.Router, publicEntryPoints []string) bool {
+	if len(router.EntryPoints) == 0 {
+		return true
+	}
+
+	for _, entryPoint := range router.EntryPoints {
+		for _, public := range publicEntryPoints {
+			if entryPoint == public {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // TODO: check whether it is mandatory to filter again.
 func (p *Provider) keepItem(ctx context.Context, i item) bool {
 	logger := log.Ctx(ctx)
@@ -181,16 +522,11 @@ func (p *Provider) addServerTCP(i item, lb *dynamic.TCPServersLoadBalancer) erro
 		return errors.New("address is missing")
 	}
 
-	port := lb.Servers[0].Port
-	lb.Servers[0].Port = ""
-
-	if port == "" && i.Port > 0 {
-		port = strconv.Itoa(i.Port)
-	}
-
-	if port == "" {
-		return errors.New("port is missing")
+	port, err := resolvePort(i, lb.Servers[0].Port)
+	if err != nil {
+		return err
 	}
+	lb.Servers[0].Port = ""
 
 	lb.Servers[0].Address = net.JoinHostPort(i.Address, port)
 
@@ -210,16 +546,11 @@ func (p *Provider) addServerUDP(i item, lb *dynamic.UDPServersLoadBalancer) erro
 		return errors.New("address is missing")
 	}
 
-	port := lb.Servers[0].Port
-	lb.Servers[0].Port = ""
-
-	if port == "" && i.Port > 0 {
-		port = strconv.Itoa(i.Port)
-	}
-
-	if port == "" {
-		return errors.New("port is missing")
+	port, err := resolvePort(i, lb.Servers[0].Port)
+	if err != nil {
+		return err
 	}
+	lb.Servers[0].Port = ""
 
 	lb.Servers[0].Address = net.JoinHostPort(i.Address, port)
 
@@ -242,22 +573,44 @@ func (p *Provider) addServer(i item, lb *dynamic.ServersLoadBalancer) error {
 		return errors.New("address is missing")
 	}
 
-	port := lb.Servers[0].Port
+	port, err := resolvePort(i, lb.Servers[0].Port)
+	if err != nil {
+		return err
+	}
 	lb.Servers[0].Port = ""
 
-	if port == "" && i.Port > 0 {
-		port = strconv.Itoa(i.Port)
+	scheme := lb.Servers[0].Scheme
+	lb.Servers[0].Scheme = ""
+	lb.Servers[0].URL = fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(i.Address, port))
+
+	if i.Draining {
+		lb.Servers[0].Weight = new(int)
 	}
 
+	return nil
+}
+
+// resolvePort returns the port number of the server for item i.
+// The given port may either be a port number, or a Nomad port label which is
+// resolved against the allocation ports. When empty, the service port is used.
+func resolvePort(i item, port string) (string, error) {
 	if port == "" {
-		return errors.New("port is missing")
+		if i.Port > 0 {
+			return strconv.Itoa(i.Port), nil
+		}
+		return "", errors.New("port is missing")
 	}
 
-	scheme := lb.Servers[0].Scheme
-	lb.Servers[0].Scheme = ""
-	lb.Servers[0].URL = fmt.Sprintf("%s://%s", scheme, net.JoinHostPort(i.Address, port))
+	if _, err := strconv.Atoi(port); err == nil {
+		return port, nil
+	}
 
-	return nil
+	value, ok := i.Ports[port]
+	if !ok {
+		return "", fmt.Errorf("port label %q not found in allocation %s", port, i.AllocID)
+	}
+
+	return strconv.Itoa(value), nil
 }
 
 func getName(i item) string {
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	traefiktls "github.com/traefik/traefik/v3/pkg/tls"
 )
 
 func Test_defaultRule(t *testing.T) {
@@ -123,6 +124,117 @@ func Test_defaultRule(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "default rule with nomad metadata",
+			items: []item{
+				{
+					ID:         "id",
+					Node:       "Node1",
+					NodeName:   "worker-1",
+					Name:       "Test",
+					Namespace:  "ns1",
+					Job:        "web",
+					Datacenter: "dc1",
+					AllocID:    "71a63a80-a98a-93ee-4fd7-73b808577c20",
+					Address:    "127.0.0.1",
+					Port:       9999,
+					ExtraConf:  configuration{Enable: true},
+				},
+			},
+			rule: "Host(`{{ .Job }}.{{ .Namespace }}.example.com`) || Host(`{{ .NodeName }}.{{ .Datacenter }}.example.com`) || Header(`X-Alloc`, `{{ .AllocID }}`)",
+			expected: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Routers:           map[string]*dynamic.TCPRouter{},
+					Middlewares:       map[string]*dynamic.TCPMiddleware{},
+					Services:          map[string]*dynamic.TCPService{},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"Test": {
+							Service: "Test",
+							Rule:    "Host(`web.ns1.example.com`) || Host(`worker-1.dc1.example.com`) || Header(`X-Alloc`, `71a63a80-a98a-93ee-4fd7-73b808577c20`)",
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services: map[string]*dynamic.Service{
+						"Test": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{
+									{
+										URL: "http://127.0.0.1:9999",
+									},
+								},
+								PassHostHeader: Bool(true),
+								ResponseForwarding: &dynamic.ResponseForwarding{
+									FlushInterval: ptypes.Duration(100 * time.Millisecond),
+								},
+							},
+						},
+					},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
+		{
+			desc: "default rule with tags",
+			items: []item{
+				{
+					ID:   "id",
+					Node: "Node1",
+					Name: "Test",
+					Tags: []string{
+						"subdomain=api",
+						"public",
+					},
+					Address:   "127.0.0.1",
+					Port:      9999,
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+			rule: `Host("{{ index .Tags "subdomain" }}.example.com")`,
+			expected: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Routers:           map[string]*dynamic.TCPRouter{},
+					Middlewares:       map[string]*dynamic.TCPMiddleware{},
+					Services:          map[string]*dynamic.TCPService{},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"Test": {
+							Service: "Test",
+							Rule:    `Host("api.example.com")`,
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services: map[string]*dynamic.Service{
+						"Test": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{
+									{
+										URL: "http://127.0.0.1:9999",
+									},
+								},
+								PassHostHeader: Bool(true),
+								ResponseForwarding: &dynamic.ResponseForwarding{
+									FlushInterval: ptypes.Duration(100 * time.Millisecond),
+								},
+							},
+						},
+					},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
 		{
 			desc: "invalid rule",
 			items: []item{
@@ -661,6 +773,69 @@ func Test_buildConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "one service with gRPC health check labels",
+			items: []item{
+				{
+					ID:   "id1",
+					Name: "Test",
+					Tags: []string{
+						"traefik.http.services.Service1.loadbalancer.server.scheme = h2c",
+						"traefik.http.services.Service1.loadbalancer.healthcheck.mode = grpc",
+						"traefik.http.services.Service1.loadbalancer.healthcheck.port = 9000",
+						"traefik.http.routers.Router1.rule = Host(`foo.com`)",
+						"traefik.http.routers.Router1.service = Service1",
+					},
+					Address:   "127.0.0.1",
+					Port:      9999,
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+			expected: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Routers:           map[string]*dynamic.TCPRouter{},
+					Middlewares:       map[string]*dynamic.TCPMiddleware{},
+					Services:          map[string]*dynamic.TCPService{},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"Router1": {
+							Service: "Service1",
+							Rule:    "Host(`foo.com`)",
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services: map[string]*dynamic.Service{
+						"Service1": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{
+									{
+										URL: "h2c://127.0.0.1:9999",
+									},
+								},
+								HealthCheck: &dynamic.ServerHealthCheck{
+									Mode:            "grpc",
+									Port:            9000,
+									Interval:        dynamic.DefaultHealthCheckInterval,
+									Timeout:         dynamic.DefaultHealthCheckTimeout,
+									FollowRedirects: Bool(true),
+								},
+								PassHostHeader: Bool(true),
+								ResponseForwarding: &dynamic.ResponseForwarding{
+									FlushInterval: ptypes.Duration(100 * time.Millisecond),
+								},
+							},
+						},
+					},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
 		{
 			desc: "one service with rule label",
 			items: []item{
@@ -1057,6 +1232,76 @@ func Test_buildConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "one service with Priority in label",
+			items: []item{
+				{
+					ID:   "id1",
+					Name: "Test",
+					Tags: []string{
+						"traefik.http.middlewares.Middleware1.priority.maxinflight = 42",
+						"traefik.http.middlewares.Middleware1.priority.classes[0].name = checkout",
+						"traefik.http.middlewares.Middleware1.priority.classes[0].priority = 10",
+						"traefik.http.middlewares.Middleware1.priority.classes[0].headers.X-Tier = gold",
+						"traefik.http.middlewares.Middleware1.priority.classes[0].pathprefixes = /checkout,/cart",
+					},
+					Address:   "127.0.0.1",
+					Port:      9999,
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+			expected: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Routers:           map[string]*dynamic.TCPRouter{},
+					Middlewares:       map[string]*dynamic.TCPMiddleware{},
+					Services:          map[string]*dynamic.TCPService{},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"Test": {
+							Service: "Test",
+							Rule:    "Host(`Test.traefik.test`)",
+						},
+					},
+					Services: map[string]*dynamic.Service{
+						"Test": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{
+									{
+										URL: "http://127.0.0.1:9999",
+									},
+								},
+								PassHostHeader: Bool(true),
+								ResponseForwarding: &dynamic.ResponseForwarding{
+									FlushInterval: ptypes.Duration(100 * time.Millisecond),
+								},
+							},
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{
+						"Middleware1": {
+							Priority: &dynamic.Priority{
+								Classes: []dynamic.PriorityClass{
+									{
+										Name:         "checkout",
+										Priority:     10,
+										Headers:      map[string]string{"X-Tier": "gold"},
+										PathPrefixes: []string{"/checkout", "/cart"},
+									},
+								},
+								MaxInFlight: 42,
+							},
+						},
+					},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
 		{
 			desc: "two services with same middleware",
 			items: []item{
@@ -1485,14 +1730,18 @@ func Test_buildConfig(t *testing.T) {
 			},
 		},
 		{
-			desc: "one service without port",
+			desc: "one service with port labels on two services",
 			items: []item{
 				{
-					ID:        "id1",
-					Node:      "Node1",
-					Name:      "Test",
-					Tags:      []string{},
-					Address:   "127.0.0.2",
+					ID:   "id1",
+					Name: "Test",
+					Tags: []string{
+						"traefik.http.services.Service1.LoadBalancer.server.port = http",
+						"traefik.http.services.Service2.LoadBalancer.server.port = admin",
+					},
+					Address:   "127.0.0.1",
+					Port:      9999,
+					Ports:     map[string]int{"http": 20001, "admin": 20002},
 					ExtraConf: configuration{Enable: true},
 				},
 			},
@@ -1508,23 +1757,52 @@ func Test_buildConfig(t *testing.T) {
 					Services: map[string]*dynamic.UDPService{},
 				},
 				HTTP: &dynamic.HTTPConfiguration{
-					Routers:           map[string]*dynamic.Router{},
-					Middlewares:       map[string]*dynamic.Middleware{},
-					Services:          map[string]*dynamic.Service{},
+					Routers:     map[string]*dynamic.Router{},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services: map[string]*dynamic.Service{
+						"Service1": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{
+									{
+										URL: "http://127.0.0.1:20001",
+									},
+								},
+								PassHostHeader: Bool(true),
+								ResponseForwarding: &dynamic.ResponseForwarding{
+									FlushInterval: ptypes.Duration(100 * time.Millisecond),
+								},
+							},
+						},
+						"Service2": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{
+									{
+										URL: "http://127.0.0.1:20002",
+									},
+								},
+								PassHostHeader: Bool(true),
+								ResponseForwarding: &dynamic.ResponseForwarding{
+									FlushInterval: ptypes.Duration(100 * time.Millisecond),
+								},
+							},
+						},
+					},
 					ServersTransports: map[string]*dynamic.ServersTransport{},
 				},
 			},
 		},
 		{
-			desc: "one service without port with middleware",
+			desc: "one service with unknown port label",
 			items: []item{
 				{
 					ID:   "id1",
 					Name: "Test",
 					Tags: []string{
-						"traefik.http.middlewares.Middleware1.inflightreq.amount = 42",
+						"traefik.http.services.Service1.LoadBalancer.server.port = unknown",
 					},
-					Address:   "127.0.0.2",
+					Address:   "127.0.0.1",
+					Port:      9999,
+					Ports:     map[string]int{"http": 20001},
 					ExtraConf: configuration{Enable: true},
 				},
 			},
@@ -1548,11 +1826,74 @@ func Test_buildConfig(t *testing.T) {
 			},
 		},
 		{
-			desc: "one service with traefik.enable false",
+			desc: "one service without port",
 			items: []item{
 				{
-					ID:   "id1",
-					Name: "Test",
+					ID:        "id1",
+					Node:      "Node1",
+					Name:      "Test",
+					Tags:      []string{},
+					Address:   "127.0.0.2",
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+			expected: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Routers:           map[string]*dynamic.TCPRouter{},
+					Middlewares:       map[string]*dynamic.TCPMiddleware{},
+					Services:          map[string]*dynamic.TCPService{},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares:       map[string]*dynamic.Middleware{},
+					Services:          map[string]*dynamic.Service{},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
+		{
+			desc: "one service without port with middleware",
+			items: []item{
+				{
+					ID:   "id1",
+					Name: "Test",
+					Tags: []string{
+						"traefik.http.middlewares.Middleware1.inflightreq.amount = 42",
+					},
+					Address:   "127.0.0.2",
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+			expected: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Routers:           map[string]*dynamic.TCPRouter{},
+					Middlewares:       map[string]*dynamic.TCPMiddleware{},
+					Services:          map[string]*dynamic.TCPService{},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares:       map[string]*dynamic.Middleware{},
+					Services:          map[string]*dynamic.Service{},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
+		{
+			desc: "one service with traefik.enable false",
+			items: []item{
+				{
+					ID:   "id1",
+					Name: "Test",
 					Tags: []string{
 						"traefik.enable=false",
 					},
@@ -1981,6 +2322,60 @@ func Test_buildConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "tcp with tags and port label",
+			items: []item{
+				{
+					ID:   "id1",
+					Name: "Test",
+					Tags: []string{
+						"traefik.tcp.routers.foo.rule = HostSNI(`foo.bar`)",
+						"traefik.tcp.routers.foo.tls.options = foo",
+						"traefik.tcp.services.foo.loadbalancer.server.port = db",
+					},
+					Address:   "127.0.0.1",
+					Port:      9999,
+					Ports:     map[string]int{"db": 25432},
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+			expected: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Routers: map[string]*dynamic.TCPRouter{
+						"foo": {
+							Service: "foo",
+							Rule:    "HostSNI(`foo.bar`)",
+							TLS: &dynamic.RouterTCPTLSConfig{
+								Options: "foo",
+							},
+						},
+					},
+					Middlewares: map[string]*dynamic.TCPMiddleware{},
+					Services: map[string]*dynamic.TCPService{
+						"foo": {
+							LoadBalancer: &dynamic.TCPServersLoadBalancer{
+								Servers: []dynamic.TCPServer{
+									{
+										Address: "127.0.0.1:25432",
+									},
+								},
+							},
+						},
+					},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares:       map[string]*dynamic.Middleware{},
+					Services:          map[string]*dynamic.Service{},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
 		{
 			desc: "udp with label and port",
 			items: []item{
@@ -2030,6 +2425,61 @@ func Test_buildConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "udp with label for the sessions and the source hash",
+			items: []item{
+				{
+					ID:   "id1",
+					Name: "Test",
+					Tags: []string{
+						"traefik.udp.routers.foo.entrypoints = mydns",
+						"traefik.udp.routers.foo.sessiontimeout = 5s",
+						"traefik.udp.routers.foo.maxsessions = 1000",
+						"traefik.udp.services.foo.loadbalancer.server.port = 80",
+						"traefik.udp.services.foo.loadbalancer.sourcehash = true",
+					},
+					Address:   "127.0.0.1",
+					Port:      9999,
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+			expected: &dynamic.Configuration{
+				UDP: &dynamic.UDPConfiguration{
+					Routers: map[string]*dynamic.UDPRouter{
+						"foo": {
+							Service:        "foo",
+							EntryPoints:    []string{"mydns"},
+							SessionTimeout: ptypes.Duration(5 * time.Second),
+							MaxSessions:    1000,
+						},
+					},
+					Services: map[string]*dynamic.UDPService{
+						"foo": {
+							LoadBalancer: &dynamic.UDPServersLoadBalancer{
+								Servers: []dynamic.UDPServer{
+									{
+										Address: "127.0.0.1:80",
+									},
+								},
+								SourceHash: true,
+							},
+						},
+					},
+				},
+				TCP: &dynamic.TCPConfiguration{
+					Routers:           map[string]*dynamic.TCPRouter{},
+					Middlewares:       map[string]*dynamic.TCPMiddleware{},
+					Services:          map[string]*dynamic.TCPService{},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares:       map[string]*dynamic.Middleware{},
+					Services:          map[string]*dynamic.Service{},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
 		{
 			desc: "tcp with label and port and http service",
 			items: []item{
@@ -2577,6 +3027,99 @@ func Test_buildConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "two instances, one draining",
+			items: []item{
+				{
+					ID:        "id1",
+					Node:      "Node1",
+					Name:      "Test",
+					Address:   "127.0.0.1",
+					Port:      80,
+					ExtraConf: configuration{Enable: true},
+				},
+				{
+					ID:        "id2",
+					Node:      "Node2",
+					Name:      "Test",
+					Address:   "127.0.0.2",
+					Port:      80,
+					Draining:  true,
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+			expected: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Routers:           map[string]*dynamic.TCPRouter{},
+					Middlewares:       map[string]*dynamic.TCPMiddleware{},
+					Services:          map[string]*dynamic.TCPService{},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"Test": {
+							Service: "Test",
+							Rule:    "Host(`Test.traefik.test`)",
+						},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services: map[string]*dynamic.Service{
+						"Test": {
+							LoadBalancer: &dynamic.ServersLoadBalancer{
+								Servers: []dynamic.Server{
+									{URL: "http://127.0.0.1:80"},
+									{URL: "http://127.0.0.2:80", Weight: Int(0)},
+								},
+								PassHostHeader: Bool(true),
+								ResponseForwarding: &dynamic.ResponseForwarding{
+									FlushInterval: ptypes.Duration(100 * time.Millisecond),
+								},
+							},
+						},
+					},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
+		{
+			desc: "draining TCP service",
+			items: []item{
+				{
+					ID:      "id1",
+					Node:    "Node1",
+					Name:    "Test",
+					Address: "127.0.0.1",
+					Port:    80,
+					Tags: []string{
+						"traefik.tcp.routers.test.rule=HostSNI(`foobar`)",
+					},
+					Draining:  true,
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+			expected: &dynamic.Configuration{
+				TCP: &dynamic.TCPConfiguration{
+					Routers:           map[string]*dynamic.TCPRouter{},
+					Middlewares:       map[string]*dynamic.TCPMiddleware{},
+					Services:          map[string]*dynamic.TCPService{},
+					ServersTransports: map[string]*dynamic.TCPServersTransport{},
+				},
+				UDP: &dynamic.UDPConfiguration{
+					Routers:  map[string]*dynamic.UDPRouter{},
+					Services: map[string]*dynamic.UDPService{},
+				},
+				HTTP: &dynamic.HTTPConfiguration{
+					Routers:           map[string]*dynamic.Router{},
+					Middlewares:       map[string]*dynamic.Middleware{},
+					Services:          map[string]*dynamic.Service{},
+					ServersTransports: map[string]*dynamic.ServersTransport{},
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -2595,17 +3138,1203 @@ func Test_buildConfig(t *testing.T) {
 	}
 }
 
-func Test_keepItem(t *testing.T) {
+func Test_buildConfig_secureHeaders(t *testing.T) {
 	testCases := []struct {
-		name        string
-		i           item
-		constraints string
-		exp         bool
+		desc                string
+		secureHeaders       *SecureHeaders
+		tags                []string
+		expectedMiddlewares map[string][]string
 	}{
 		{
-			name: "enable true",
-			i:    item{ExtraConf: configuration{Enable: true}},
-			exp:  true,
+			desc: "secure headers disabled",
+			tags: []string{
+				"traefik.http.routers.public.entrypoints=websecure",
+				"traefik.http.routers.public.service=Test",
+			},
+			expectedMiddlewares: map[string][]string{
+				"public": nil,
+			},
+		},
+		{
+			desc:          "router bound to a public entrypoint",
+			secureHeaders: &SecureHeaders{EntryPoints: []string{"websecure"}},
+			tags: []string{
+				"traefik.http.routers.public.entrypoints=websecure",
+				"traefik.http.routers.public.service=Test",
+				"traefik.http.routers.internal.entrypoints=internal",
+				"traefik.http.routers.internal.service=Test",
+			},
+			expectedMiddlewares: map[string][]string{
+				"public":   {"secure-headers"},
+				"internal": nil,
+			},
+		},
+		{
+			desc:          "router without entrypoints",
+			secureHeaders: &SecureHeaders{EntryPoints: []string{"websecure"}},
+			expectedMiddlewares: map[string][]string{
+				"Test": {"secure-headers"},
+			},
+		},
+		{
+			desc:          "secure headers prepended to router middlewares",
+			secureHeaders: &SecureHeaders{EntryPoints: []string{"websecure"}},
+			tags: []string{
+				"traefik.http.routers.public.entrypoints=websecure",
+				"traefik.http.routers.public.service=Test",
+				"traefik.http.routers.public.middlewares=auth",
+				"traefik.http.middlewares.auth.basicauth.users=test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/",
+			},
+			expectedMiddlewares: map[string][]string{
+				"public": {"secure-headers", "auth"},
+			},
+		},
+		{
+			desc:          "secure headers disabled by tag",
+			secureHeaders: &SecureHeaders{EntryPoints: []string{"websecure"}},
+			tags: []string{
+				"traefik.nomad.secureheaders=false",
+				"traefik.http.routers.public.entrypoints=websecure",
+				"traefik.http.routers.public.service=Test",
+			},
+			expectedMiddlewares: map[string][]string{
+				"public": nil,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			p.DefaultRule = "Host(`{{ normalize .Name }}.traefik.test`)"
+			if test.secureHeaders != nil {
+				test.secureHeaders.SetDefaults()
+			}
+			p.SecureHeaders = test.secureHeaders
+			err := p.Init()
+			require.NoError(t, err)
+
+			items := []item{
+				{
+					ID:        "id1",
+					Name:      "Test",
+					Tags:      test.tags,
+					Address:   "127.0.0.1",
+					Port:      9999,
+					ExtraConf: p.getExtraConf(test.tags),
+				},
+			}
+
+			c := p.buildConfig(context.TODO(), items)
+
+			require.Len(t, c.HTTP.Routers, len(test.expectedMiddlewares))
+			var attached bool
+			for name, middlewares := range test.expectedMiddlewares {
+				require.Contains(t, c.HTTP.Routers, name)
+				assert.Equal(t, middlewares, c.HTTP.Routers[name].Middlewares)
+
+				for _, middleware := range middlewares {
+					attached = attached || middleware == "secure-headers"
+				}
+			}
+
+			if !attached {
+				assert.NotContains(t, c.HTTP.Middlewares, "secure-headers")
+				return
+			}
+
+			expected := &dynamic.Middleware{
+				Headers: &dynamic.Headers{
+					STSSeconds:           31536000,
+					STSIncludeSubdomains: true,
+					FrameDeny:            true,
+					ContentTypeNosniff:   true,
+				},
+			}
+			assert.Equal(t, expected, c.HTTP.Middlewares["secure-headers"])
+		})
+	}
+}
+
+func Test_buildConfig_jobHeaders(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		jobHeaders          bool
+		tags                []string
+		expectedMiddlewares map[string][]string
+	}{
+		{
+			desc: "job headers disabled",
+			expectedMiddlewares: map[string][]string{
+				"Test": nil,
+			},
+		},
+		{
+			desc:       "job headers attached to the default router",
+			jobHeaders: true,
+			expectedMiddlewares: map[string][]string{
+				"Test": {"nomad-job-headers-ns1-job1"},
+			},
+		},
+		{
+			desc:       "job headers appended to router middlewares",
+			jobHeaders: true,
+			tags: []string{
+				"traefik.http.routers.public.service=Test",
+				"traefik.http.routers.public.middlewares=auth",
+				"traefik.http.middlewares.auth.basicauth.users=test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/",
+			},
+			expectedMiddlewares: map[string][]string{
+				"public": {"auth", "nomad-job-headers-ns1-job1"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			p.DefaultRule = "Host(`{{ normalize .Name }}.traefik.test`)"
+			p.JobHeaders = test.jobHeaders
+			err := p.Init()
+			require.NoError(t, err)
+
+			items := []item{
+				{
+					ID:        "id1",
+					Name:      "Test",
+					Namespace: "ns1",
+					Job:       "job1",
+					AllocID:   "alloc1",
+					Tags:      test.tags,
+					Address:   "127.0.0.1",
+					Port:      9999,
+					ExtraConf: p.getExtraConf(test.tags),
+				},
+				{
+					ID:        "id2",
+					Name:      "Test",
+					Namespace: "ns1",
+					Job:       "job1",
+					AllocID:   "alloc2",
+					Tags:      test.tags,
+					Address:   "127.0.0.2",
+					Port:      9999,
+					ExtraConf: p.getExtraConf(test.tags),
+				},
+			}
+
+			c := p.buildConfig(context.TODO(), items)
+
+			require.Len(t, c.HTTP.Routers, len(test.expectedMiddlewares))
+			for name, middlewares := range test.expectedMiddlewares {
+				require.Contains(t, c.HTTP.Routers, name)
+				assert.Equal(t, middlewares, c.HTTP.Routers[name].Middlewares)
+			}
+
+			// the instances of the job share the router and the middleware, the servers of both are kept.
+			require.Contains(t, c.HTTP.Services, "Test")
+			assert.Len(t, c.HTTP.Services["Test"].LoadBalancer.Servers, 2)
+
+			if !test.jobHeaders {
+				assert.NotContains(t, c.HTTP.Middlewares, "nomad-job-headers-ns1-job1")
+				return
+			}
+
+			expected := &dynamic.Middleware{
+				Headers: &dynamic.Headers{
+					CustomRequestHeaders: map[string]string{
+						"X-Nomad-Job":       "job1",
+						"X-Nomad-Namespace": "ns1",
+					},
+				},
+			}
+			assert.Equal(t, expected, c.HTTP.Middlewares["nomad-job-headers-ns1-job1"])
+		})
+	}
+}
+
+func Test_buildConfig_defaultPathRule(t *testing.T) {
+	testCases := []struct {
+		desc                string
+		pathRule            string
+		tags                []string
+		expectedRules       map[string]string
+		expectedMiddlewares map[string][]string
+		expectedPrefix      string
+	}{
+		{
+			desc:     "default router",
+			pathRule: "/{{ normalize .Name }}",
+			expectedRules: map[string]string{
+				"Test": "PathPrefix(`/Test`)",
+			},
+			expectedMiddlewares: map[string][]string{
+				"Test": {"Test-stripprefix"},
+			},
+			expectedPrefix: "/Test",
+		},
+		{
+			desc:     "prefix derived from the job and namespace",
+			pathRule: "/{{ .Namespace }}/{{ .Job }}",
+			expectedRules: map[string]string{
+				"Test": "PathPrefix(`/default/api`)",
+			},
+			expectedMiddlewares: map[string][]string{
+				"Test": {"Test-stripprefix"},
+			},
+			expectedPrefix: "/default/api",
+		},
+		{
+			desc:     "stripprefix appended to router middlewares",
+			pathRule: "/{{ normalize .Name }}",
+			tags: []string{
+				"traefik.http.routers.api.middlewares=auth",
+				"traefik.http.middlewares.auth.basicauth.users=test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/",
+			},
+			expectedRules: map[string]string{
+				"api": "PathPrefix(`/Test`)",
+			},
+			expectedMiddlewares: map[string][]string{
+				"api": {"auth", "Test-stripprefix"},
+			},
+			expectedPrefix: "/Test",
+		},
+		{
+			desc:     "router with its own rule",
+			pathRule: "/{{ normalize .Name }}",
+			tags: []string{
+				"traefik.http.routers.api.rule=Host(`api.example.com`)",
+			},
+			expectedRules: map[string]string{
+				"api": "Host(`api.example.com`)",
+			},
+			expectedMiddlewares: map[string][]string{
+				"api": nil,
+			},
+		},
+		{
+			desc: "default path rule disabled",
+			expectedRules: map[string]string{
+				"Test": "Host(`Test.traefik.test`)",
+			},
+			expectedMiddlewares: map[string][]string{
+				"Test": nil,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			p.DefaultRule = "Host(`{{ normalize .Name }}.traefik.test`)"
+			p.DefaultPathRule = test.pathRule
+			err := p.Init()
+			require.NoError(t, err)
+
+			items := []item{
+				{
+					ID:        "id1",
+					Name:      "Test",
+					Namespace: "default",
+					Job:       "api",
+					Tags:      test.tags,
+					Address:   "127.0.0.1",
+					Port:      9999,
+					ExtraConf: p.getExtraConf(test.tags),
+				},
+			}
+
+			c := p.buildConfig(context.TODO(), items)
+
+			require.Len(t, c.HTTP.Routers, len(test.expectedRules))
+			for name, rule := range test.expectedRules {
+				require.Contains(t, c.HTTP.Routers, name)
+				assert.Equal(t, rule, c.HTTP.Routers[name].Rule)
+				assert.Equal(t, test.expectedMiddlewares[name], c.HTTP.Routers[name].Middlewares)
+			}
+
+			if test.expectedPrefix == "" {
+				assert.NotContains(t, c.HTTP.Middlewares, "Test-stripprefix")
+				return
+			}
+
+			expected := &dynamic.Middleware{
+				StripPrefix: &dynamic.StripPrefix{Prefixes: []string{test.expectedPrefix}},
+			}
+			assert.Equal(t, expected, c.HTTP.Middlewares["Test-stripprefix"])
+		})
+	}
+}
+
+func Test_buildConfig_failoverTiers(t *testing.T) {
+	healthCheck := "traefik.http.services.Test.loadbalancer.healthcheck.path=/health"
+
+	newItem := func(id, address string, tags ...string) item {
+		p := Provider{Configuration: Configuration{Prefix: "traefik", ExposedByDefault: true}}
+
+		return item{
+			ID:        id,
+			Node:      "Node1",
+			Name:      "Test",
+			Address:   address,
+			Port:      80,
+			Tags:      tags,
+			ExtraConf: p.getExtraConf(tags),
+		}
+	}
+
+	testCases := []struct {
+		desc             string
+		items            []item
+		expectedServers  map[string][]string
+		expectedFailover map[string]*dynamic.Failover
+	}{
+		{
+			desc: "two tiers",
+			items: []item{
+				newItem("id1", "127.0.0.1", "traefik.nomad.failoverTier=1", healthCheck),
+				newItem("id2", "127.0.0.2", "traefik.nomad.failoverTier=1", healthCheck),
+				newItem("id3", "127.0.0.3", "traefik.nomad.failoverTier=2", healthCheck),
+			},
+			expectedServers: map[string][]string{
+				"Test-tier1": {"http://127.0.0.1:80", "http://127.0.0.2:80"},
+				"Test-tier2": {"http://127.0.0.3:80"},
+			},
+			expectedFailover: map[string]*dynamic.Failover{
+				"Test": {Service: "Test-tier1", Fallback: "Test-tier2"},
+			},
+		},
+		{
+			desc: "three tiers",
+			items: []item{
+				newItem("id1", "127.0.0.1", "traefik.nomad.failoverTier=1", healthCheck),
+				newItem("id2", "127.0.0.2", "traefik.nomad.failoverTier=2", healthCheck),
+				newItem("id3", "127.0.0.3", "traefik.nomad.failoverTier=5", healthCheck),
+			},
+			expectedServers: map[string][]string{
+				"Test-tier1": {"http://127.0.0.1:80"},
+				"Test-tier2": {"http://127.0.0.2:80"},
+				"Test-tier5": {"http://127.0.0.3:80"},
+			},
+			expectedFailover: map[string]*dynamic.Failover{
+				"Test":                {Service: "Test-tier1", Fallback: "Test-failover-tier2"},
+				"Test-failover-tier2": {Service: "Test-tier2", Fallback: "Test-tier5"},
+			},
+		},
+		{
+			desc: "single tier",
+			items: []item{
+				newItem("id1", "127.0.0.1", "traefik.nomad.failoverTier=2"),
+			},
+			expectedServers: map[string][]string{
+				"Test": {"http://127.0.0.1:80"},
+			},
+		},
+		{
+			desc: "tiered and untiered instances",
+			items: []item{
+				newItem("id1", "127.0.0.1", "traefik.nomad.failoverTier=1"),
+				newItem("id2", "127.0.0.2"),
+			},
+			expectedServers: map[string][]string{
+				"Test":       {"http://127.0.0.2:80"},
+				"Test-tier1": {"http://127.0.0.1:80"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			err := p.Init()
+			require.NoError(t, err)
+
+			c := p.buildConfig(context.Background(), test.items)
+
+			require.Contains(t, c.HTTP.Routers, "Test")
+			assert.Equal(t, "Test", c.HTTP.Routers["Test"].Service)
+
+			servers := make(map[string][]string)
+			failovers := make(map[string]*dynamic.Failover)
+			for name, service := range c.HTTP.Services {
+				if service.Failover != nil {
+					failovers[name] = service.Failover
+					continue
+				}
+
+				for _, server := range service.LoadBalancer.Servers {
+					servers[name] = append(servers[name], server.URL)
+				}
+			}
+
+			assert.Equal(t, test.expectedServers, servers)
+			if test.expectedFailover == nil {
+				test.expectedFailover = map[string]*dynamic.Failover{}
+			}
+			assert.Equal(t, test.expectedFailover, failovers)
+		})
+	}
+}
+
+func Test_buildConfig_mirror(t *testing.T) {
+	newItem := func(id, address string, tags ...string) item {
+		p := Provider{Configuration: Configuration{Prefix: "traefik", ExposedByDefault: true}}
+
+		return item{
+			ID:        id,
+			Node:      "Node1",
+			Name:      "Test",
+			Address:   address,
+			Port:      80,
+			Tags:      tags,
+			ExtraConf: p.getExtraConf(tags),
+		}
+	}
+
+	canaryTags := []string{"traefik.nomad.canary=true", "traefik.nomad.mirror.percent=10", "traefik.nomad.mirror.rule=Header(`X-Canary`, `true`)"}
+
+	testCases := []struct {
+		desc              string
+		items             []item
+		expectedServers   map[string][]string
+		expectedMirroring map[string]*dynamic.Mirroring
+	}{
+		{
+			desc: "canary mirror",
+			items: []item{
+				newItem("id1", "127.0.0.1"),
+				newItem("id2", "127.0.0.2", canaryTags...),
+				newItem("id3", "127.0.0.3", canaryTags...),
+			},
+			expectedServers: map[string][]string{
+				"Test-primary": {"http://127.0.0.1:80"},
+				"Test-mirror":  {"http://127.0.0.2:80", "http://127.0.0.3:80"},
+			},
+			expectedMirroring: map[string]*dynamic.Mirroring{
+				"Test": {
+					Service: "Test-primary",
+					Mirrors: []dynamic.MirrorService{{Name: "Test-mirror", Percent: 10, Rule: "Header(`X-Canary`, `true`)"}},
+				},
+			},
+		},
+		{
+			desc: "mirror of a failover",
+			items: []item{
+				newItem("id1", "127.0.0.1", "traefik.nomad.failoverTier=1"),
+				newItem("id2", "127.0.0.2", "traefik.nomad.failoverTier=2"),
+				newItem("id3", "127.0.0.3", "traefik.nomad.mirror.percent=50"),
+			},
+			expectedServers: map[string][]string{
+				"Test-tier1":  {"http://127.0.0.1:80"},
+				"Test-tier2":  {"http://127.0.0.2:80"},
+				"Test-mirror": {"http://127.0.0.3:80"},
+			},
+			expectedMirroring: map[string]*dynamic.Mirroring{
+				"Test": {
+					Service: "Test-primary",
+					Mirrors: []dynamic.MirrorService{{Name: "Test-mirror", Percent: 50}},
+				},
+			},
+		},
+		{
+			desc: "no service to mirror",
+			items: []item{
+				newItem("id1", "127.0.0.1", canaryTags...),
+			},
+			expectedServers: map[string][]string{
+				"Test-mirror": {"http://127.0.0.1:80"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			err := p.Init()
+			require.NoError(t, err)
+
+			c := p.buildConfig(context.Background(), test.items)
+
+			// the mirrors are not routed.
+			for _, router := range c.HTTP.Routers {
+				assert.Equal(t, "Test", router.Service)
+			}
+
+			servers := make(map[string][]string)
+			mirrorings := make(map[string]*dynamic.Mirroring)
+			for name, service := range c.HTTP.Services {
+				if service.Mirroring != nil {
+					mirrorings[name] = service.Mirroring
+					continue
+				}
+
+				if service.LoadBalancer == nil {
+					continue
+				}
+
+				for _, server := range service.LoadBalancer.Servers {
+					servers[name] = append(servers[name], server.URL)
+				}
+			}
+
+			assert.Equal(t, test.expectedServers, servers)
+			if test.expectedMirroring == nil {
+				test.expectedMirroring = map[string]*dynamic.Mirroring{}
+			}
+			assert.Equal(t, test.expectedMirroring, mirrorings)
+		})
+	}
+}
+
+func Test_buildConfig_locality(t *testing.T) {
+	newItem := func(id, address, datacenter string, tags ...string) item {
+		p := Provider{Configuration: Configuration{Prefix: "traefik", ExposedByDefault: true}}
+
+		return item{
+			ID:         id,
+			Node:       "Node1",
+			Name:       "Test",
+			Datacenter: datacenter,
+			Address:    address,
+			Port:       80,
+			Tags:       tags,
+			ExtraConf:  p.getExtraConf(tags),
+		}
+	}
+
+	testCases := []struct {
+		desc             string
+		remoteWeight     int
+		items            []item
+		expectedServers  map[string][]string
+		expectedFailover map[string]*dynamic.Failover
+		expectedWeighted map[string][]dynamic.WRRService
+	}{
+		{
+			desc: "remote servers as fallback",
+			items: []item{
+				newItem("id1", "127.0.0.1", "dc1"),
+				newItem("id2", "127.0.0.2", "dc2"),
+				newItem("id3", "127.0.0.3", "dc3"),
+			},
+			expectedServers: map[string][]string{
+				"Test-tier1": {"http://127.0.0.1:80"},
+				"Test-tier2": {"http://127.0.0.2:80", "http://127.0.0.3:80"},
+			},
+			expectedFailover: map[string]*dynamic.Failover{
+				"Test": {Service: "Test-tier1", Fallback: "Test-tier2"},
+			},
+		},
+		{
+			desc:         "weighted remote servers",
+			remoteWeight: 10,
+			items: []item{
+				newItem("id1", "127.0.0.1", "dc1"),
+				newItem("id2", "127.0.0.2", "dc2"),
+			},
+			expectedServers: map[string][]string{
+				"Test-local":  {"http://127.0.0.1:80"},
+				"Test-remote": {"http://127.0.0.2:80"},
+			},
+			expectedWeighted: map[string][]dynamic.WRRService{
+				"Test": {
+					{Name: "Test-local", Weight: Int(100)},
+					{Name: "Test-remote", Weight: Int(10)},
+				},
+			},
+		},
+		{
+			desc:         "only remote servers",
+			remoteWeight: 10,
+			items: []item{
+				newItem("id1", "127.0.0.1", "dc2"),
+			},
+			expectedServers: map[string][]string{
+				"Test": {"http://127.0.0.1:80"},
+			},
+		},
+		{
+			desc: "only local servers",
+			items: []item{
+				newItem("id1", "127.0.0.1", "dc1"),
+			},
+			expectedServers: map[string][]string{
+				"Test": {"http://127.0.0.1:80"},
+			},
+		},
+		{
+			desc: "explicit failover tiers",
+			items: []item{
+				newItem("id1", "127.0.0.1", "dc2", "traefik.nomad.failoverTier=1"),
+				newItem("id2", "127.0.0.2", "dc1", "traefik.nomad.failoverTier=3"),
+			},
+			expectedServers: map[string][]string{
+				"Test-tier1": {"http://127.0.0.1:80"},
+				"Test-tier3": {"http://127.0.0.2:80"},
+			},
+			expectedFailover: map[string]*dynamic.Failover{
+				"Test": {Service: "Test-tier1", Fallback: "Test-tier3"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			p.Locality = &Locality{Datacenter: "dc1", RemoteWeight: test.remoteWeight}
+			err := p.Init()
+			require.NoError(t, err)
+
+			c := p.buildConfig(context.Background(), test.items)
+
+			require.Contains(t, c.HTTP.Routers, "Test")
+			assert.Equal(t, "Test", c.HTTP.Routers["Test"].Service)
+
+			servers := make(map[string][]string)
+			failovers := make(map[string]*dynamic.Failover)
+			weighted := make(map[string][]dynamic.WRRService)
+			for name, service := range c.HTTP.Services {
+				switch {
+				case service.Failover != nil:
+					failovers[name] = service.Failover
+				case service.Weighted != nil:
+					weighted[name] = service.Weighted.Services
+				default:
+					for _, server := range service.LoadBalancer.Servers {
+						servers[name] = append(servers[name], server.URL)
+					}
+				}
+			}
+
+			assert.Equal(t, test.expectedServers, servers)
+			if test.expectedFailover == nil {
+				test.expectedFailover = map[string]*dynamic.Failover{}
+			}
+			assert.Equal(t, test.expectedFailover, failovers)
+			if test.expectedWeighted == nil {
+				test.expectedWeighted = map[string][]dynamic.WRRService{}
+			}
+			assert.Equal(t, test.expectedWeighted, weighted)
+		})
+	}
+}
+
+func Test_buildConfig_jobVersions(t *testing.T) {
+	newItem := func(id, address string, version uint64, tags ...string) item {
+		p := Provider{Configuration: Configuration{Prefix: "traefik", ExposedByDefault: true}}
+
+		return item{
+			ID:         id,
+			Node:       "Node1",
+			Name:       "Test",
+			Datacenter: "dc1",
+			Address:    address,
+			Port:       80,
+			JobVersion: &version,
+			Tags:       tags,
+			ExtraConf:  p.getExtraConf(tags),
+		}
+	}
+
+	testCases := []struct {
+		desc             string
+		items            []item
+		expectedServers  map[string][]string
+		expectedWeighted map[string][]dynamic.WRRService
+	}{
+		{
+			desc: "weighted job versions",
+			items: []item{
+				newItem("id1", "127.0.0.1", 3, "traefik.nomad.versionweights=3:90,4:10"),
+				newItem("id2", "127.0.0.2", 3, "traefik.nomad.versionweights=3:90,4:10"),
+				newItem("id3", "127.0.0.3", 4, "traefik.nomad.versionweights=3:90,4:10"),
+			},
+			expectedServers: map[string][]string{
+				"Test-v3": {"http://127.0.0.1:80", "http://127.0.0.2:80"},
+				"Test-v4": {"http://127.0.0.3:80"},
+			},
+			expectedWeighted: map[string][]dynamic.WRRService{
+				"Test": {
+					{Name: "Test-v3", Weight: Int(90)},
+					{Name: "Test-v4", Weight: Int(10)},
+				},
+			},
+		},
+		{
+			desc: "weights of the newest job version",
+			items: []item{
+				newItem("id1", "127.0.0.1", 3),
+				newItem("id2", "127.0.0.2", 4, "traefik.nomad.versionweights=3:50,4:50"),
+				newItem("id3", "127.0.0.3", 5, "traefik.nomad.versionweights=3:75,5:25"),
+			},
+			expectedServers: map[string][]string{
+				"Test-v3": {"http://127.0.0.1:80"},
+				"Test-v4": {"http://127.0.0.2:80"},
+				"Test-v5": {"http://127.0.0.3:80"},
+			},
+			expectedWeighted: map[string][]dynamic.WRRService{
+				"Test": {
+					{Name: "Test-v3", Weight: Int(75)},
+					{Name: "Test-v4", Weight: Int(0)},
+					{Name: "Test-v5", Weight: Int(25)},
+				},
+			},
+		},
+		{
+			desc: "single job version",
+			items: []item{
+				newItem("id1", "127.0.0.1", 4, "traefik.nomad.versionweights=3:90,4:10"),
+			},
+			expectedServers: map[string][]string{
+				"Test": {"http://127.0.0.1:80"},
+			},
+		},
+		{
+			desc: "invalid weights are ignored",
+			items: []item{
+				newItem("id1", "127.0.0.1", 3, "traefik.nomad.versionweights=3:foo,4:10,bar"),
+				newItem("id2", "127.0.0.2", 4, "traefik.nomad.versionweights=3:foo,4:10,bar"),
+			},
+			expectedServers: map[string][]string{
+				"Test-v3": {"http://127.0.0.1:80"},
+				"Test-v4": {"http://127.0.0.2:80"},
+			},
+			expectedWeighted: map[string][]dynamic.WRRService{
+				"Test": {
+					{Name: "Test-v3", Weight: Int(0)},
+					{Name: "Test-v4", Weight: Int(10)},
+				},
+			},
+		},
+		{
+			desc: "job versions not weighted",
+			items: []item{
+				newItem("id1", "127.0.0.1", 3),
+				newItem("id2", "127.0.0.2", 4),
+			},
+			expectedServers: map[string][]string{
+				"Test": {"http://127.0.0.1:80", "http://127.0.0.2:80"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			err := p.Init()
+			require.NoError(t, err)
+
+			c := p.buildConfig(context.Background(), test.items)
+
+			require.Contains(t, c.HTTP.Routers, "Test")
+			assert.Equal(t, "Test", c.HTTP.Routers["Test"].Service)
+
+			servers := make(map[string][]string)
+			weighted := make(map[string][]dynamic.WRRService)
+			for name, service := range c.HTTP.Services {
+				if service.Weighted != nil {
+					weighted[name] = service.Weighted.Services
+					continue
+				}
+
+				for _, server := range service.LoadBalancer.Servers {
+					servers[name] = append(servers[name], server.URL)
+				}
+			}
+
+			assert.Equal(t, test.expectedServers, servers)
+			if test.expectedWeighted == nil {
+				test.expectedWeighted = map[string][]dynamic.WRRService{}
+			}
+			assert.Equal(t, test.expectedWeighted, weighted)
+		})
+	}
+}
+
+func Test_buildConfig_instances(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	err := p.Init()
+	require.NoError(t, err)
+
+	items := []item{
+		{
+			ID:         "id1",
+			Name:       "Test",
+			Namespace:  "default",
+			Job:        "job",
+			Node:       "Node1",
+			Datacenter: "dc1",
+			AllocID:    "alloc1",
+			Address:    "127.0.0.1",
+			Port:       80,
+			ExtraConf:  configuration{Enable: true},
+		},
+	}
+
+	p.buildConfig(context.Background(), items)
+
+	instance, ok := p.Instance("http://127.0.0.1:80")
+	require.True(t, ok)
+	assert.Equal(t, Instance{
+		ServiceName: "Test",
+		ServiceID:   "id1",
+		Namespace:   "default",
+		Job:         "job",
+		AllocID:     "alloc1",
+		Node:        "Node1",
+		Datacenter:  "dc1",
+	}, instance)
+
+	p.buildConfig(context.Background(), nil)
+
+	_, ok = p.Instance("http://127.0.0.1:80")
+	assert.False(t, ok)
+}
+
+func TestLocalityInit(t *testing.T) {
+	t.Setenv("NOMAD_DC", "dc2")
+
+	l := &Locality{}
+	require.NoError(t, l.init())
+	assert.Equal(t, "dc2", l.Datacenter)
+
+	l = &Locality{Datacenter: "dc1"}
+	require.NoError(t, l.init())
+	assert.Equal(t, "dc1", l.Datacenter)
+
+	t.Setenv("NOMAD_DC", "")
+
+	l = &Locality{}
+	require.Error(t, l.init())
+}
+
+func Test_buildConfig_namespacePolicies(t *testing.T) {
+	newItem := func(namespace string, tags ...string) item {
+		p := Provider{Configuration: Configuration{Prefix: "traefik", ExposedByDefault: true}}
+
+		return item{
+			ID:        "id",
+			Node:      "Node1",
+			Namespace: namespace,
+			Name:      "Test",
+			Address:   "127.0.0.1",
+			Port:      80,
+			Tags:      tags,
+			ExtraConf: p.getExtraConf(tags),
+		}
+	}
+
+	policies := map[string]*NamespacePolicy{
+		"dev": {
+			EntryPoints: []string{"internal"},
+			Middlewares: []string{"auth@file"},
+		},
+		"staging": {
+			EntryPoints: []string{"internal", "web"},
+		},
+	}
+
+	testCases := []struct {
+		desc            string
+		item            item
+		expectedRouters map[string][]string
+	}{
+		{
+			desc:            "namespace without policy",
+			item:            newItem("prod", "traefik.http.routers.Test.entrypoints=websecure"),
+			expectedRouters: map[string][]string{"Test": {"websecure"}},
+		},
+		{
+			desc:            "router without entrypoints gets the allowed ones",
+			item:            newItem("staging"),
+			expectedRouters: map[string][]string{"Test": {"internal", "web"}},
+		},
+		{
+			desc:            "disallowed entrypoints are removed",
+			item:            newItem("staging", "traefik.http.routers.Test.entrypoints=websecure,web"),
+			expectedRouters: map[string][]string{"Test": {"web"}},
+		},
+		{
+			desc:            "router bound only to disallowed entrypoints",
+			item:            newItem("dev", "traefik.http.routers.Test.entrypoints=websecure"),
+			expectedRouters: map[string][]string{},
+		},
+		{
+			desc:            "allowed middleware",
+			item:            newItem("dev", "traefik.http.routers.Test.middlewares=auth@file"),
+			expectedRouters: map[string][]string{"Test": {"internal"}},
+		},
+		{
+			desc: "disallowed middleware",
+			item: newItem("dev",
+				"traefik.http.routers.Test.middlewares=auth@file,Redirect",
+				"traefik.http.middlewares.Redirect.redirectscheme.scheme=https",
+			),
+			expectedRouters: map[string][]string{},
+		},
+		{
+			desc: "TCP router",
+			item: newItem("dev",
+				"traefik.tcp.routers.Test.rule=HostSNI(`*`)",
+				"traefik.tcp.routers.Test.entrypoints=websecure,internal",
+			),
+			expectedRouters: map[string][]string{"tcp/Test": {"internal"}},
+		},
+		{
+			desc: "UDP router",
+			item: newItem("dev",
+				"traefik.udp.routers.Test.entrypoints=dns",
+			),
+			expectedRouters: map[string][]string{},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := new(Provider)
+			p.SetDefaults()
+			p.NamespacePolicies = policies
+			err := p.Init()
+			require.NoError(t, err)
+
+			c := p.buildConfig(context.Background(), []item{test.item})
+
+			routers := make(map[string][]string)
+			for name, router := range c.HTTP.Routers {
+				routers[name] = router.EntryPoints
+			}
+			for name, router := range c.TCP.Routers {
+				routers["tcp/"+name] = router.EntryPoints
+			}
+			for name, router := range c.UDP.Routers {
+				routers["udp/"+name] = router.EntryPoints
+			}
+
+			assert.Equal(t, test.expectedRouters, routers)
+		})
+	}
+}
+
+func Test_buildConfig_routerDefaults(t *testing.T) {
+	newItem := func(tags ...string) item {
+		p := Provider{Configuration: Configuration{Prefix: "traefik", ExposedByDefault: true}}
+
+		return item{
+			ID:        "id",
+			Node:      "Node1",
+			Name:      "Test",
+			Address:   "127.0.0.1",
+			Port:      80,
+			Tags:      tags,
+			ExtraConf: p.getExtraConf(tags),
+		}
+	}
+
+	type router struct {
+		EntryPoints []string
+		Middlewares []string
+	}
+
+	testCases := []struct {
+		desc            string
+		item            item
+		expectedRouters map[string]router
+	}{
+		{
+			desc:            "no defaults",
+			item:            newItem(),
+			expectedRouters: map[string]router{"Test": {}},
+		},
+		{
+			desc: "default router",
+			item: newItem(
+				"traefik.nomad.router.middlewares=auth@file, compress@file",
+				"traefik.nomad.router.entrypoints=websecure",
+			),
+			expectedRouters: map[string]router{
+				"Test": {EntryPoints: []string{"websecure"}, Middlewares: []string{"auth@file", "compress@file"}},
+			},
+		},
+		{
+			desc: "routers declaring their own middlewares and entrypoints",
+			item: newItem(
+				"traefik.nomad.router.middlewares=auth@file",
+				"traefik.nomad.router.entrypoints=websecure",
+				"traefik.http.routers.api.rule=Path(`/api`)",
+				"traefik.http.routers.admin.rule=Path(`/admin`)",
+				"traefik.http.routers.admin.middlewares=admin-auth@file",
+				"traefik.http.routers.admin.entrypoints=internal",
+			),
+			expectedRouters: map[string]router{
+				"api":   {EntryPoints: []string{"websecure"}, Middlewares: []string{"auth@file"}},
+				"admin": {EntryPoints: []string{"internal"}, Middlewares: []string{"admin-auth@file"}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := new(Provider)
+			p.SetDefaults()
+			err := p.Init()
+			require.NoError(t, err)
+
+			c := p.buildConfig(context.Background(), []item{test.item})
+
+			routers := make(map[string]router)
+			for name, r := range c.HTTP.Routers {
+				routers[name] = router{EntryPoints: r.EntryPoints, Middlewares: r.Middlewares}
+			}
+
+			assert.Equal(t, test.expectedRouters, routers)
+		})
+	}
+}
+
+func Test_buildConfig_configurationErrors(t *testing.T) {
+	newItem := func(tags ...string) item {
+		p := Provider{Configuration: Configuration{Prefix: "traefik", ExposedByDefault: true}}
+
+		return item{
+			ID:        "id1",
+			Node:      "Node1",
+			Namespace: "ns",
+			Name:      "Test",
+			AllocID:   "alloc1",
+			Address:   "127.0.0.1",
+			Port:      80,
+			Tags:      tags,
+			ExtraConf: p.getExtraConf(tags),
+		}
+	}
+
+	testCases := []struct {
+		desc                  string
+		defaultRoutingOnError bool
+		items                 []item
+		expectedRouters       map[string]string
+		expectedTags          []TagError
+		expectedFallback      bool
+	}{
+		{
+			desc:            "valid tags",
+			items:           []item{newItem("traefik.http.routers.Test.priority=10")},
+			expectedRouters: map[string]string{"Test": "Host(`Test`)"},
+		},
+		{
+			desc: "invalid tag drops the service",
+			items: []item{newItem(
+				"traefik.http.routers.Test.priority=high",
+				"traefik.http.routers.Test.rule=Host(`example.com`)",
+			)},
+			expectedTags: []TagError{{
+				Tag:   "traefik.http.routers.Test.priority",
+				Value: "high",
+			}},
+		},
+		{
+			desc:                  "invalid tag falls back to the default routing",
+			defaultRoutingOnError: true,
+			items: []item{newItem(
+				"traefik.http.routers.Test.priority=high",
+				"traefik.http.routers.Test.rule=Host(`example.com`)",
+			)},
+			expectedRouters: map[string]string{"Test": "Host(`Test`)"},
+			expectedTags: []TagError{{
+				Tag:   "traefik.http.routers.Test.priority",
+				Value: "high",
+			}},
+			expectedFallback: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := new(Provider)
+			p.SetDefaults()
+			p.DefaultRoutingOnError = test.defaultRoutingOnError
+			err := p.Init()
+			require.NoError(t, err)
+
+			c := p.buildConfig(context.Background(), test.items)
+
+			routers := make(map[string]string)
+			for name, router := range c.HTTP.Routers {
+				routers[name] = router.Rule
+			}
+			if test.expectedRouters == nil {
+				test.expectedRouters = map[string]string{}
+			}
+			assert.Equal(t, test.expectedRouters, routers)
+
+			configErrors := p.ConfigurationErrors()
+			if test.expectedTags == nil {
+				assert.Empty(t, configErrors)
+				return
+			}
+
+			require.Len(t, configErrors, 1)
+			assert.Equal(t, "Test", configErrors[0].ServiceName)
+			assert.Equal(t, "id1", configErrors[0].ServiceID)
+			assert.Equal(t, "ns", configErrors[0].Namespace)
+			assert.Equal(t, "alloc1", configErrors[0].AllocID)
+			assert.NotEmpty(t, configErrors[0].Message)
+			assert.Equal(t, test.expectedFallback, configErrors[0].Fallback)
+
+			require.Len(t, configErrors[0].Tags, len(test.expectedTags))
+			for j, tagErr := range configErrors[0].Tags {
+				assert.Equal(t, test.expectedTags[j].Tag, tagErr.Tag)
+				assert.Equal(t, test.expectedTags[j].Value, tagErr.Value)
+				assert.NotEmpty(t, tagErr.Message)
+			}
+		})
+	}
+}
+
+func Test_validateLabels(t *testing.T) {
+	labels := map[string]string{
+		"traefik.http.routers.Test.priority":                     "high",
+		"traefik.http.routers.Test.rule":                         "Host(`example.com`)",
+		"traefik.http.services.Test.loadbalancer.passhostheader": "maybe",
+	}
+
+	tagErrors := validateLabels(labels, "custom")
+
+	require.Len(t, tagErrors, 2)
+	assert.Equal(t, "custom.http.routers.Test.priority", tagErrors[0].Tag)
+	assert.Equal(t, "high", tagErrors[0].Value)
+	assert.Equal(t, "custom.http.services.Test.loadbalancer.passhostheader", tagErrors[1].Tag)
+	assert.Equal(t, "maybe", tagErrors[1].Value)
+}
+
+func Test_keepItem(t *testing.T) {
+	testCases := []struct {
+		name        string
+		i           item
+		constraints string
+		exp         bool
+	}{
+		{
+			name: "enable true",
+			i:    item{ExtraConf: configuration{Enable: true}},
+			exp:  true,
 		},
 		{
 			name: "enable false",
@@ -2691,3 +4420,202 @@ func extractNamespacesFromProvider(providers []*Provider) []string {
 
 func Int(v int) *int    { return &v }
 func Bool(v bool) *bool { return &v }
+
+func Test_buildConfig_serversTransport(t *testing.T) {
+	newItem := func(id string, tags ...string) item {
+		p := Provider{Configuration: Configuration{Prefix: "traefik", ExposedByDefault: true}}
+
+		return item{
+			ID:        id,
+			Node:      "Node1",
+			Name:      "Test",
+			Address:   "127.0.0.1",
+			Port:      443,
+			Tags:      tags,
+			ExtraConf: p.getExtraConf(tags),
+		}
+	}
+
+	testCases := []struct {
+		desc               string
+		items              []item
+		expectedTransports map[string]*dynamic.ServersTransport
+		expectedServices   map[string]string
+		expectedErrors     int
+	}{
+		{
+			desc:               "no servers transport",
+			items:              []item{newItem("id1")},
+			expectedTransports: map[string]*dynamic.ServersTransport{},
+			expectedServices:   map[string]string{"Test": ""},
+		},
+		{
+			desc: "servers transport shared by the instances",
+			items: []item{
+				newItem("id1",
+					"traefik.http.services.Test.loadbalancer.server.scheme=https",
+					"traefik.http.serversTransport.insecureSkipVerify=true",
+					"traefik.http.serversTransport.serverName=test.internal",
+					"traefik.http.serversTransport.rootCAs=/secrets/ca.pem,/secrets/ca2.pem",
+				),
+				newItem("id2",
+					"traefik.http.services.Test.loadbalancer.server.scheme=https",
+					"traefik.http.serverstransport.insecureskipverify=true",
+					"traefik.http.serverstransport.servername=test.internal",
+					"traefik.http.serverstransport.rootcas=/secrets/ca.pem,/secrets/ca2.pem",
+				),
+			},
+			expectedTransports: map[string]*dynamic.ServersTransport{
+				"Test": {
+					ServerName:         "test.internal",
+					InsecureSkipVerify: true,
+					RootCAs:            []traefiktls.FileOrContent{"/secrets/ca.pem", "/secrets/ca2.pem"},
+				},
+			},
+			expectedServices: map[string]string{"Test": "Test"},
+		},
+		{
+			desc: "servers transport connection pool",
+			items: []item{
+				newItem("id1",
+					"traefik.http.services.Test.loadbalancer.server.port=80",
+					"traefik.http.serverstransport.maxconnsperhost=100",
+					"traefik.http.serverstransport.maxidleconnsperhost=100",
+					"traefik.http.serverstransport.maxidleconns=500",
+					"traefik.http.serverstransport.strictmaxconcurrentstreams=true",
+				),
+			},
+			expectedTransports: map[string]*dynamic.ServersTransport{
+				"Test": {
+					MaxConnsPerHost:            100,
+					MaxIdleConnsPerHost:        100,
+					MaxIdleConns:               500,
+					StrictMaxConcurrentStreams: true,
+				},
+			},
+			expectedServices: map[string]string{"Test": "Test"},
+		},
+		{
+			desc: "referenced servers transport is kept",
+			items: []item{
+				newItem("id1",
+					"traefik.http.services.Test.loadbalancer.serversTransport=foobar@file",
+					"traefik.http.services.Other.loadbalancer.server.port=80",
+					"traefik.http.serversTransport.insecureSkipVerify=true",
+				),
+			},
+			expectedTransports: map[string]*dynamic.ServersTransport{
+				"Test": {InsecureSkipVerify: true},
+			},
+			expectedServices: map[string]string{"Test": "foobar@file", "Other": "Test"},
+		},
+		{
+			desc: "invalid servers transport",
+			items: []item{
+				newItem("id1", "traefik.http.serversTransport.insecureSkipVerify=foobar"),
+			},
+			expectedTransports: map[string]*dynamic.ServersTransport{},
+			expectedServices:   map[string]string{},
+			expectedErrors:     1,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := new(Provider)
+			p.SetDefaults()
+			err := p.Init()
+			require.NoError(t, err)
+
+			c := p.buildConfig(context.Background(), test.items)
+
+			assert.Equal(t, test.expectedTransports, c.HTTP.ServersTransports)
+
+			services := make(map[string]string)
+			for name, service := range c.HTTP.Services {
+				services[name] = service.LoadBalancer.ServersTransport
+			}
+			assert.Equal(t, test.expectedServices, services)
+
+			assert.Len(t, p.ConfigurationErrors(), test.expectedErrors)
+		})
+	}
+}
+
+func Test_buildConfig_discoveredServices(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	err := p.Init()
+	require.NoError(t, err)
+
+	items := []item{
+		{
+			ID:         "id1",
+			Name:       "whoami",
+			Namespace:  "default",
+			Job:        "web",
+			Node:       "Node1",
+			Datacenter: "dc1",
+			AllocID:    "alloc1",
+			Address:    "127.0.0.1",
+			Port:       80,
+			Tags:       []string{"traefik.enable=true", "traefik.http.routers.whoami.rule=Host(`whoami.example.com`)", "version=1.0"},
+			ExtraConf:  configuration{Enable: true},
+		},
+		{
+			ID:        "id2",
+			Name:      "redis",
+			Namespace: "default",
+			Job:       "cache",
+			Address:   "127.0.0.1",
+			Port:      6379,
+			Tags:      []string{"traefik.tcp.routers.redis.rule=HostSNI(`*`)"},
+			ExtraConf: configuration{Enable: true},
+		},
+		{
+			ID:        "id3",
+			Name:      "broken",
+			Namespace: "default",
+			Job:       "broken",
+			Address:   "127.0.0.1",
+			Port:      80,
+			Tags:      []string{"traefik.http.routers.broken.priority=high"},
+			ExtraConf: configuration{Enable: true},
+		},
+		{
+			ID:        "id4",
+			Name:      "disabled",
+			Address:   "127.0.0.1",
+			Port:      80,
+			ExtraConf: configuration{Enable: false},
+		},
+	}
+
+	p.buildConfig(context.Background(), items)
+
+	discovered := p.DiscoveredServices()
+	require.Len(t, discovered, 3)
+
+	assert.Equal(t, "broken", discovered[0].ServiceName)
+	assert.Equal(t, []string{"traefik.http.routers.broken.priority=high"}, discovered[0].Tags)
+	assert.Empty(t, discovered[0].Routers)
+	require.NotNil(t, discovered[0].Error)
+	assert.Equal(t, "id3", discovered[0].Error.ServiceID)
+
+	assert.Equal(t, DiscoveredService{
+		Instance: Instance{ServiceName: "redis", ServiceID: "id2", Namespace: "default", Job: "cache"},
+		Tags:     []string{"traefik.tcp.routers.redis.rule=HostSNI(`*`)"},
+		Routers:  []Resource{{Protocol: "tcp", Name: "redis@nomad"}},
+		Services: []Resource{{Protocol: "tcp", Name: "redis@nomad"}},
+	}, discovered[1])
+
+	assert.Equal(t, DiscoveredService{
+		Instance: Instance{ServiceName: "whoami", ServiceID: "id1", Namespace: "default", Job: "web", AllocID: "alloc1", Node: "Node1", Datacenter: "dc1"},
+		Tags:     []string{"traefik.enable=true", "traefik.http.routers.whoami.rule=Host(`whoami.example.com`)"},
+		Routers:  []Resource{{Protocol: "http", Name: "whoami@nomad"}},
+		Services: []Resource{{Protocol: "http", Name: "whoami@nomad"}},
+	}, discovered[2])
+}
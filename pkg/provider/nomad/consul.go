@@ -0,0 +1,303 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog/log"
+)
+
+// serviceProviderNomad is the provider of the services registered in Nomad, the API client only having the Consul one.
+const serviceProviderNomad = "nomad"
+
+// getConsulServices returns the services registered in Consul by the running allocations, as service stubs,
+// along with the instances of each stub.
+// As the Consul registrations are not part of the Nomad API, the instances are built from the job specification
+// and the allocated ports of the allocations, which are memoized in allocs.
+// The tags of a stub are the ones of all its instances, as for the services registered in Nomad.
+func (p *Provider) getConsulServices(ctx context.Context, client *api.Client, allocs map[string]*api.Allocation) ([]*api.ServiceRegistrationListStub, map[*api.ServiceRegistrationStub][]*api.ServiceRegistration, error) {
+	opts := &api.QueryOptions{AllowStale: p.Stale}
+	opts = opts.WithContext(ctx)
+
+	allocStubs, _, err := client.Allocations().List(opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing allocations: %w", err)
+	}
+
+	// whether the task groups of the job versions register services in Consul,
+	// so that the allocations of the other task groups are fetched at most once per job version.
+	consulGroups := make(map[string]bool)
+
+	// the instances indexed by namespace and service name.
+	services := make(map[string]map[string][]*api.ServiceRegistration)
+	for _, stub := range allocStubs {
+		if stub.ClientStatus != api.AllocClientStatusRunning {
+			continue
+		}
+
+		// the allocations failing their deployment health checks are not routed to, as Consul would not.
+		if stub.DeploymentStatus != nil && stub.DeploymentStatus.Healthy != nil && !*stub.DeploymentStatus.Healthy {
+			continue
+		}
+
+		groupKey := fmt.Sprintf("%s/%s@%d/%s", stub.Namespace, stub.JobID, stub.JobVersion, stub.TaskGroup)
+		if hasConsul, ok := consulGroups[groupKey]; ok && !hasConsul {
+			continue
+		}
+
+		alloc, err := p.getAllocation(ctx, client, allocs, stub.ID)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("allocID", stub.ID).Msg("Skipping the Consul services of the allocation")
+			continue
+		}
+
+		instances := p.consulInstances(alloc)
+		consulGroups[groupKey] = len(instances) > 0
+
+		for _, instance := range instances {
+			// as the Nomad services fetched by fetchService, the instances not enabled are filtered out,
+			// unless the enable tag can be set in the meta blocks.
+			if !p.UseMeta && !p.getExtraConf(instance.Tags).Enable {
+				continue
+			}
+
+			if services[instance.Namespace] == nil {
+				services[instance.Namespace] = make(map[string][]*api.ServiceRegistration)
+			}
+			services[instance.Namespace][instance.ServiceName] = append(services[instance.Namespace][instance.ServiceName], instance)
+		}
+	}
+
+	namespaces := make([]string, 0, len(services))
+	for namespace := range services {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var listStubs []*api.ServiceRegistrationListStub
+	instancesByStub := make(map[*api.ServiceRegistrationStub][]*api.ServiceRegistration)
+	for _, namespace := range namespaces {
+		names := make([]string, 0, len(services[namespace]))
+		for name := range services[namespace] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		listStub := &api.ServiceRegistrationListStub{Namespace: namespace}
+		for _, name := range names {
+			serviceStub := &api.ServiceRegistrationStub{ServiceName: name, Tags: unionTags(services[namespace][name])}
+			listStub.Services = append(listStub.Services, serviceStub)
+			instancesByStub[serviceStub] = services[namespace][name]
+		}
+
+		listStubs = append(listStubs, listStub)
+	}
+
+	return listStubs, instancesByStub, nil
+}
+
+// unionTags returns the tags of all the instances, in order of appearance.
+func unionTags(instances []*api.ServiceRegistration) []string {
+	seen := make(map[string]struct{})
+
+	var tags []string
+	for _, instance := range instances {
+		for _, tag := range instance.Tags {
+			if _, ok := seen[tag]; ok {
+				continue
+			}
+			seen[tag] = struct{}{}
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// consulInstances returns the instances of the services of the allocation registered in Consul,
+// the ones whose port cannot be resolved being skipped.
+func (p *Provider) consulInstances(alloc *api.Allocation) []*api.ServiceRegistration {
+	if alloc.Job == nil {
+		return nil
+	}
+
+	group := findTaskGroup(alloc.Job, alloc.TaskGroup)
+	if group == nil {
+		return nil
+	}
+
+	jobName := ""
+	if alloc.Job.Name != nil {
+		jobName = *alloc.Job.Name
+	}
+
+	// the datacenter of the allocation is only known when the job runs in a single one.
+	var datacenter string
+	if len(alloc.Job.Datacenters) == 1 {
+		datacenter = alloc.Job.Datacenters[0]
+	}
+
+	canary := alloc.DeploymentStatus != nil && alloc.DeploymentStatus.Canary
+
+	var instances []*api.ServiceRegistration
+	add := func(service *api.Service, taskName string) {
+		if service.Provider == serviceProviderNomad {
+			return
+		}
+
+		address, port, ok := consulServiceAddress(alloc, service)
+		if !ok {
+			log.Debug().Str("allocID", alloc.ID).Str("serviceName", service.Name).
+				Msgf("Skipping the Consul service whose port %q is not allocated", service.PortLabel)
+			return
+		}
+
+		name := interpolateServiceName(service.Name, jobName, *group.Name, taskName)
+
+		tags := service.Tags
+		if canary && len(service.CanaryTags) > 0 {
+			tags = service.CanaryTags
+		}
+
+		owner := "group-" + *group.Name
+		if taskName != "" {
+			owner = taskName
+		}
+
+		instances = append(instances, &api.ServiceRegistration{
+			// the ID follows the format of the Consul registrations of Nomad.
+			ID:          fmt.Sprintf("_nomad-task-%s-%s-%s-%s", alloc.ID, owner, name, service.PortLabel),
+			ServiceName: name,
+			Namespace:   alloc.Namespace,
+			NodeID:      alloc.NodeID,
+			Datacenter:  datacenter,
+			JobID:       alloc.JobID,
+			AllocID:     alloc.ID,
+			Tags:        tags,
+			Address:     address,
+			Port:        port,
+		})
+	}
+
+	for _, service := range group.Services {
+		add(service, "")
+	}
+
+	for _, task := range group.Tasks {
+		// the services of a task are only registered while the task runs.
+		if state, ok := alloc.TaskStates[task.Name]; ok && state.State != "running" {
+			continue
+		}
+
+		for _, service := range task.Services {
+			add(service, task.Name)
+		}
+	}
+
+	return instances
+}
+
+// consulServiceAddress returns the host address and port of the service, from the ports allocated to the allocation.
+// The port label of the service may also be a port number, on the address of the allocation.
+func consulServiceAddress(alloc *api.Allocation, service *api.Service) (string, int, bool) {
+	if alloc.AllocatedResources == nil || service.PortLabel == "" {
+		return "", 0, false
+	}
+
+	address, port := allocationPort(alloc, service.PortLabel)
+	if port == 0 {
+		number, err := strconv.Atoi(service.PortLabel)
+		if err != nil || number <= 0 {
+			return "", 0, false
+		}
+		port = number
+	}
+
+	if service.Address != "" {
+		address = service.Address
+	}
+
+	if address == "" {
+		return "", 0, false
+	}
+
+	return address, port, true
+}
+
+// allocationPort returns the host address and port allocated for the label, a zero port when the label is not allocated.
+// The address is the one of the first network when the port has none.
+func allocationPort(alloc *api.Allocation, label string) (string, int) {
+	var defaultAddress string
+
+	networks := alloc.AllocatedResources.Shared.Networks
+	for _, task := range alloc.AllocatedResources.Tasks {
+		if task != nil {
+			networks = append(networks, task.Networks...)
+		}
+	}
+
+	for _, network := range networks {
+		if network == nil {
+			continue
+		}
+
+		if defaultAddress == "" {
+			defaultAddress = network.IP
+		}
+
+		for _, port := range append(network.ReservedPorts, network.DynamicPorts...) {
+			if port.Label == label {
+				return network.IP, port.Value
+			}
+		}
+	}
+
+	for _, port := range alloc.AllocatedResources.Shared.Ports {
+		if port.Label == label {
+			address := port.HostIP
+			if address == "" {
+				address = defaultAddress
+			}
+			return address, port.Value
+		}
+	}
+
+	return defaultAddress, 0
+}
+
+// findTaskGroup returns the task group of the job with the given name, nil if there is none.
+func findTaskGroup(job *api.Job, name string) *api.TaskGroup {
+	for _, group := range job.TaskGroups {
+		if group.Name != nil && *group.Name == name {
+			return group
+		}
+	}
+
+	return nil
+}
+
+// interpolateServiceName interpolates the name of a service of the job specification
+// with the job, group and task names, which are the only variables a service name can reference.
+// An empty name is replaced with the default service name.
+func interpolateServiceName(name, jobName, groupName, taskName string) string {
+	if name == "" {
+		name = jobName + "-" + groupName
+		if taskName != "" {
+			name += "-" + taskName
+		}
+		return name
+	}
+
+	return strings.NewReplacer(
+		"${NOMAD_JOB_NAME}", jobName,
+		"${JOB}", jobName,
+		"${NOMAD_GROUP_NAME}", groupName,
+		"${TASKGROUP}", groupName,
+		"${NOMAD_TASK_NAME}", taskName,
+		"${TASK}", taskName,
+	).Replace(name)
+}
@@ -0,0 +1,97 @@
+package nomad
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// DiscoveredService is a Nomad service instance discovered on the last refresh,
+// with the Traefik tags it is configured with, and the configuration built from them, as reported by the API.
+type DiscoveredService struct {
+	Instance
+
+	Tags     []string            `json:"tags,omitempty"`
+	Routers  []Resource          `json:"routers,omitempty"`
+	Services []Resource          `json:"services,omitempty"`
+	Error    *ConfigurationError `json:"error,omitempty"`
+}
+
+// Resource is a router or a service of the dynamic configuration, qualified with the name of the provider.
+type Resource struct {
+	Protocol string `json:"protocol"`
+	Name     string `json:"name"`
+}
+
+// DiscoveredServices returns the service instances discovered on the last refresh.
+func (p *Provider) DiscoveredServices() []DiscoveredService {
+	p.discoveredMu.RLock()
+	defer p.discoveredMu.RUnlock()
+
+	return append([]DiscoveredService(nil), p.discovered...)
+}
+
+// setDiscoveredServices stores the discovered service instances, along with the configuration errors they have.
+func (p *Provider) setDiscoveredServices(discovered []DiscoveredService, configErrors []ConfigurationError) {
+	errorsByID := make(map[string]ConfigurationError, len(configErrors))
+	for _, configErr := range configErrors {
+		errorsByID[configErr.ServiceID] = configErr
+	}
+
+	for k := range discovered {
+		if configErr, ok := errorsByID[discovered[k].ServiceID]; ok {
+			discovered[k].Error = &configErr
+		}
+	}
+
+	sort.Slice(discovered, func(i, j int) bool {
+		if discovered[i].ServiceName != discovered[j].ServiceName {
+			return discovered[i].ServiceName < discovered[j].ServiceName
+		}
+		return discovered[i].ServiceID < discovered[j].ServiceID
+	})
+
+	p.discoveredMu.Lock()
+	p.discovered = discovered
+	p.discoveredMu.Unlock()
+}
+
+// newDiscoveredService returns the discovered service instance of the item, with its Traefik tags.
+func (p *Provider) newDiscoveredService(i item) DiscoveredService {
+	var tags []string
+	for _, tag := range i.Tags {
+		if strings.HasPrefix(tag, p.Prefix+".") {
+			tags = append(tags, tag)
+		}
+	}
+
+	return DiscoveredService{Instance: newInstance(i), Tags: tags}
+}
+
+// addResources records the routers and services built from the tags of the service instance.
+func (p *Provider) addResources(discovered *DiscoveredService, configuration *dynamic.Configuration) {
+	add := func(resources []Resource, protocol string, names []string) []Resource {
+		sort.Strings(names)
+		for _, name := range names {
+			resources = append(resources, Resource{Protocol: protocol, Name: name + "@" + p.name})
+		}
+		return resources
+	}
+
+	discovered.Routers = add(discovered.Routers, "http", keys(configuration.HTTP.Routers))
+	discovered.Routers = add(discovered.Routers, "tcp", keys(configuration.TCP.Routers))
+	discovered.Routers = add(discovered.Routers, "udp", keys(configuration.UDP.Routers))
+
+	discovered.Services = add(discovered.Services, "http", keys(configuration.HTTP.Services))
+	discovered.Services = add(discovered.Services, "tcp", keys(configuration.TCP.Services))
+	discovered.Services = add(discovered.Services, "udp", keys(configuration.UDP.Services))
+}
+
+func keys[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,178 @@
+package nomad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// DNSFallback holds the configuration of the degraded discovery mode,
+// used to keep critical services routed while the Nomad API is unavailable.
+type DNSFallback struct {
+	Services []string `description:"Names of the services to resolve through DNS when the Nomad API is unavailable." json:"services,omitempty" toml:"services,omitempty" yaml:"services,omitempty" export:"true"`
+	Domain   string   `description:"Domain appended to the service names to build the DNS SRV record names." json:"domain,omitempty" toml:"domain,omitempty" yaml:"domain,omitempty" export:"true"`
+	Resolver string   `description:"Address of the DNS server to query. If not provided, the system resolver is used." json:"resolver,omitempty" toml:"resolver,omitempty" yaml:"resolver,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values of the DNS fallback.
+func (d *DNSFallback) SetDefaults() {
+	d.Domain = "service.consul"
+}
+
+// dnsResolver is the subset of net.Resolver used by the DNS fallback.
+type dnsResolver interface {
+	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+func newDNSResolver(address string) dnsResolver {
+	if address == "" {
+		return net.DefaultResolver
+	}
+
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			dialer := net.Dialer{Timeout: 5 * time.Second}
+			return dialer.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// loadDNSConfiguration sends the last known configuration,
+// in which the instances of the fallback services are replaced by the ones resolved from their DNS SRV records.
+func (p *Provider) loadDNSConfiguration(ctx context.Context, configurationC chan<- dynamic.Message) {
+	if p.DNSFallback == nil || len(p.DNSFallback.Services) == 0 {
+		return
+	}
+
+	logger := log.Ctx(ctx)
+
+	items, err := p.getDNSServiceData(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to resolve Nomad services through DNS")
+		return
+	}
+
+	logger.Warn().Msgf("Nomad API unavailable, routing %d instance(s) of the fallback services discovered through DNS", len(items))
+
+	configurationC <- dynamic.Message{
+		ProviderName:  p.name,
+		Configuration: p.buildConfig(ctx, p.replaceResolved(items)),
+	}
+}
+
+// replaceResolved returns the last known items, where the items of the services resolved through DNS are replaced by the resolved ones.
+// The services which did not resolve keep their last known items.
+func (p *Provider) replaceResolved(resolved []item) []item {
+	names := make(map[string]struct{})
+	for _, i := range resolved {
+		names[i.Name] = struct{}{}
+	}
+
+	var items []item
+	for _, i := range p.knownItems {
+		if _, ok := names[i.Name]; !ok {
+			items = append(items, i)
+		}
+	}
+
+	return append(items, resolved...)
+}
+
+// getDNSServiceData resolves the fallback services through DNS.
+// The resolved instances reuse the tags of the last instances discovered through the Nomad API, if any.
+func (p *Provider) getDNSServiceData(ctx context.Context) ([]item, error) {
+	if p.dnsResolver == nil {
+		p.dnsResolver = newDNSResolver(p.DNSFallback.Resolver)
+	}
+
+	var items []item
+	var errs []error
+
+	for _, name := range p.DNSFallback.Services {
+		instances, err := p.resolveDNSService(ctx, name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		items = append(items, instances...)
+	}
+
+	if len(items) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	for _, err := range errs {
+		log.Ctx(ctx).Warn().Err(err).Send()
+	}
+
+	return items, nil
+}
+
+func (p *Provider) resolveDNSService(ctx context.Context, name string) ([]item, error) {
+	record := name
+	if p.DNSFallback.Domain != "" {
+		record = name + "." + strings.TrimPrefix(p.DNSFallback.Domain, ".")
+	}
+
+	_, srvs, err := p.dnsResolver.LookupSRV(ctx, "", "", record)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lookup SRV record %s: %w", record, err)
+	}
+
+	tags := p.lastTags[name]
+
+	var items []item
+	for _, srv := range srvs {
+		target := strings.TrimSuffix(srv.Target, ".")
+
+		address := target
+		if net.ParseIP(target) == nil {
+			addresses, err := p.dnsResolver.LookupHost(ctx, target)
+			if err != nil || len(addresses) == 0 {
+				log.Ctx(ctx).Warn().Err(err).Str("target", target).Msgf("Failed to resolve target of SRV record %s", record)
+				continue
+			}
+			address = addresses[0]
+		}
+
+		items = append(items, item{
+			ID:        fmt.Sprintf("%s-%s-%d", name, address, srv.Port),
+			Name:      name,
+			Node:      target,
+			Address:   address,
+			Port:      int(srv.Port),
+			Tags:      tags,
+			ExtraConf: p.getExtraConf(tags),
+		})
+	}
+
+	return items, nil
+}
+
+// recordTags remembers the tags of the fallback services discovered through the Nomad API,
+// to be reused by the instances resolved through DNS.
+func (p *Provider) recordTags(items []item) {
+	if p.DNSFallback == nil {
+		return
+	}
+
+	lastTags := make(map[string][]string)
+	for _, i := range items {
+		lastTags[i.Name] = i.Tags
+	}
+
+	for _, name := range p.DNSFallback.Services {
+		if tags, ok := lastTags[name]; ok {
+			p.lastTags[name] = tags
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package nomad
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+type fakeDNSResolver struct {
+	srvs  map[string][]*net.SRV
+	hosts map[string][]string
+}
+
+func (r fakeDNSResolver) LookupSRV(_ context.Context, _, _, name string) (string, []*net.SRV, error) {
+	srvs, ok := r.srvs[name]
+	if !ok {
+		return "", nil, errors.New("no such host")
+	}
+	return name, srvs, nil
+}
+
+func (r fakeDNSResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	addresses, ok := r.hosts[host]
+	if !ok {
+		return nil, errors.New("no such host")
+	}
+	return addresses, nil
+}
+
+func Test_getDNSServiceData(t *testing.T) {
+	resolver := fakeDNSResolver{
+		srvs: map[string][]*net.SRV{
+			"api.service.consul.": {
+				{Target: "node1.node.dc1.consul.", Port: 20001},
+				{Target: "10.0.0.2", Port: 20002},
+				{Target: "unknown.node.dc1.consul.", Port: 20003},
+			},
+		},
+		hosts: map[string][]string{
+			"node1.node.dc1.consul": {"10.0.0.1"},
+		},
+	}
+
+	testCases := []struct {
+		desc     string
+		services []string
+		lastTags map[string][]string
+		expected []item
+		wantErr  bool
+	}{
+		{
+			desc:     "service without known tags",
+			services: []string{"api"},
+			expected: []item{
+				{
+					ID:        "api-10.0.0.1-20001",
+					Name:      "api",
+					Node:      "node1.node.dc1.consul",
+					Address:   "10.0.0.1",
+					Port:      20001,
+					ExtraConf: configuration{Enable: true},
+				},
+				{
+					ID:        "api-10.0.0.2-20002",
+					Name:      "api",
+					Node:      "10.0.0.2",
+					Address:   "10.0.0.2",
+					Port:      20002,
+					ExtraConf: configuration{Enable: true},
+				},
+			},
+		},
+		{
+			desc:     "service with known tags",
+			services: []string{"api", "unknown"},
+			lastTags: map[string][]string{"api": {"traefik.nomad.canary=true"}},
+			expected: []item{
+				{
+					ID:        "api-10.0.0.1-20001",
+					Name:      "api",
+					Node:      "node1.node.dc1.consul",
+					Address:   "10.0.0.1",
+					Port:      20001,
+					Tags:      []string{"traefik.nomad.canary=true"},
+					ExtraConf: configuration{Enable: true, Canary: true},
+				},
+				{
+					ID:        "api-10.0.0.2-20002",
+					Name:      "api",
+					Node:      "10.0.0.2",
+					Address:   "10.0.0.2",
+					Port:      20002,
+					Tags:      []string{"traefik.nomad.canary=true"},
+					ExtraConf: configuration{Enable: true, Canary: true},
+				},
+			},
+		},
+		{
+			desc:     "no service resolved",
+			services: []string{"unknown"},
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			p.DNSFallback = &DNSFallback{Services: test.services, Domain: "service.consul."}
+			err := p.Init()
+			require.NoError(t, err)
+
+			p.dnsResolver = resolver
+			if test.lastTags != nil {
+				p.lastTags = test.lastTags
+			}
+
+			items, err := p.getDNSServiceData(context.Background())
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, items)
+		})
+	}
+}
+
+func Test_recordTags(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	p.DNSFallback = &DNSFallback{Services: []string{"api", "web"}}
+	require.NoError(t, p.Init())
+
+	p.recordTags([]item{
+		{Name: "api", Tags: []string{"traefik.http.routers.api.rule=Host(`api.example.com`)"}},
+		{Name: "other", Tags: []string{"traefik.enable=true"}},
+	})
+	p.recordTags([]item{
+		{Name: "web", Tags: []string{"traefik.enable=true"}},
+	})
+
+	expected := map[string][]string{
+		"api": {"traefik.http.routers.api.rule=Host(`api.example.com`)"},
+		"web": {"traefik.enable=true"},
+	}
+	assert.Equal(t, expected, p.lastTags)
+}
+
+func Test_loadDNSConfiguration(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	p.DefaultRule = "Host(`{{ normalize .Name }}.traefik.test`)"
+	p.DNSFallback = &DNSFallback{Services: []string{"api"}, Domain: "service.consul"}
+	require.NoError(t, p.Init())
+
+	p.dnsResolver = fakeDNSResolver{
+		srvs: map[string][]*net.SRV{
+			"api.service.consul": {{Target: "10.0.0.1", Port: 8080}},
+		},
+	}
+
+	configurationC := make(chan dynamic.Message, 1)
+	p.loadDNSConfiguration(context.Background(), configurationC)
+
+	require.Len(t, configurationC, 1)
+	msg := <-configurationC
+
+	require.Contains(t, msg.Configuration.HTTP.Services, "api")
+	assert.Equal(t, []dynamic.Server{{URL: "http://10.0.0.1:8080"}}, msg.Configuration.HTTP.Services["api"].LoadBalancer.Servers)
+	assert.Equal(t, "Host(`api.traefik.test`)", msg.Configuration.HTTP.Routers["api"].Rule)
+}
+
+func Test_loadDNSConfiguration_keepsKnownItems(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	p.DefaultRule = "Host(`{{ normalize .Name }}.traefik.test`)"
+	p.DNSFallback = &DNSFallback{Services: []string{"api"}, Domain: "service.consul"}
+	require.NoError(t, p.Init())
+
+	p.dnsResolver = fakeDNSResolver{
+		srvs: map[string][]*net.SRV{
+			"api.service.consul": {{Target: "10.0.0.3", Port: 8080}},
+		},
+	}
+
+	configurationC := make(chan dynamic.Message, 2)
+
+	// the configuration loaded from the Nomad API before it became unavailable.
+	p.applyConfiguration(context.Background(), configurationC, []item{
+		{ID: "api-1", Name: "api", Address: "10.0.0.1", Port: 8080, ExtraConf: configuration{Enable: true}},
+		{ID: "web-1", Name: "web", Address: "10.0.0.2", Port: 8080, ExtraConf: configuration{Enable: true}},
+	})
+	<-configurationC
+
+	p.loadDNSConfiguration(context.Background(), configurationC)
+
+	require.Len(t, configurationC, 1)
+	msg := <-configurationC
+
+	require.Contains(t, msg.Configuration.HTTP.Services, "api")
+	assert.Equal(t, []dynamic.Server{{URL: "http://10.0.0.3:8080"}}, msg.Configuration.HTTP.Services["api"].LoadBalancer.Servers)
+	assert.Equal(t, "Host(`api.traefik.test`)", msg.Configuration.HTTP.Routers["api"].Rule)
+
+	require.Contains(t, msg.Configuration.HTTP.Services, "web")
+	assert.Equal(t, []dynamic.Server{{URL: "http://10.0.0.2:8080"}}, msg.Configuration.HTTP.Services["web"].LoadBalancer.Servers)
+	assert.Equal(t, "Host(`web.traefik.test`)", msg.Configuration.HTTP.Routers["web"].Rule)
+}
@@ -0,0 +1,114 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog/log"
+)
+
+// drainingItem is an item of a stopping allocation, kept in the configuration until the end of its drain window.
+type drainingItem struct {
+	item
+
+	deadline time.Time
+}
+
+// getStoppingAllocations returns the IDs of the allocations which are desired to stop, but are still running.
+func (p *Provider) getStoppingAllocations(ctx context.Context, client *api.Client) (map[string]struct{}, error) {
+	opts := &api.QueryOptions{AllowStale: p.Stale}
+	opts = opts.WithContext(ctx)
+
+	stubs, _, err := client.Allocations().List(opts)
+	if err != nil {
+		return nil, fmt.Errorf("listing allocations: %w", err)
+	}
+
+	stopping := make(map[string]struct{})
+	for _, stub := range stubs {
+		if stub.ClientStatus != api.AllocClientStatusRunning {
+			continue
+		}
+
+		if stub.DesiredStatus == api.AllocDesiredStatusStop || stub.DesiredStatus == api.AllocDesiredStatusEvict {
+			stopping[stub.ID] = struct{}{}
+		}
+	}
+
+	return stopping, nil
+}
+
+// drain returns the items along with the items which are draining.
+// An item is draining during the drain timeout, starting when its allocation is stopping,
+// or when its service registration disappears, whichever is first.
+// Draining items are kept with a zero weight, so that they only serve the requests bound to them by a sticky cookie.
+func (p *Provider) drain(ctx context.Context, items []item, now time.Time) []item {
+	if p.DrainTimeout <= 0 {
+		return items
+	}
+
+	logger := log.Ctx(ctx)
+
+	current := make(map[string]item, len(items))
+	stopping := make(map[string]struct{})
+
+	var result []item
+	for _, i := range items {
+		if !i.Draining {
+			current[i.ID] = i
+			delete(p.draining, i.ID)
+			result = append(result, i)
+			continue
+		}
+
+		stopping[i.ID] = struct{}{}
+		p.startDraining(ctx, i, now)
+	}
+
+	for id, i := range p.lastItems {
+		if _, ok := current[id]; ok {
+			continue
+		}
+
+		i.Draining = true
+		p.startDraining(ctx, i, now)
+	}
+	p.lastItems = current
+
+	var draining []item
+	for id, d := range p.draining {
+		if !now.Before(d.deadline) {
+			// the item of an allocation which is still stopping is kept,
+			// so that the drain window does not start again on the next refresh.
+			if _, ok := stopping[id]; !ok {
+				delete(p.draining, id)
+			}
+
+			logger.Debug().Str("serviceName", d.Name).Str("allocID", d.AllocID).Msg("Drain window elapsed")
+			continue
+		}
+
+		draining = append(draining, d.item)
+	}
+
+	// draining items are appended in a stable order, to build a configuration which does not depend on the map iteration order.
+	sort.Slice(draining, func(i, j int) bool {
+		return draining[i].ID < draining[j].ID
+	})
+
+	return append(result, draining...)
+}
+
+func (p *Provider) startDraining(ctx context.Context, i item, now time.Time) {
+	if _, ok := p.draining[i.ID]; ok {
+		return
+	}
+
+	log.Ctx(ctx).Debug().Str("serviceName", i.Name).Str("allocID", i.AllocID).
+		Msgf("Draining service instance for %s", time.Duration(p.DrainTimeout))
+
+	p.draining[i.ID] = drainingItem{item: i, deadline: now.Add(time.Duration(p.DrainTimeout))}
+}
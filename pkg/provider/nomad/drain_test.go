@@ -0,0 +1,99 @@
+package nomad
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func Test_drain(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	p.DrainTimeout = ptypes.Duration(time.Minute)
+	p.lastItems = make(map[string]item)
+	p.draining = make(map[string]drainingItem)
+
+	now := time.Now()
+	web1 := item{ID: "web1", Name: "web", AllocID: "alloc1"}
+	web2 := item{ID: "web2", Name: "web", AllocID: "alloc2"}
+	web3 := item{ID: "web3", Name: "web", AllocID: "alloc3"}
+
+	drained := func(i item) item {
+		i.Draining = true
+		return i
+	}
+
+	refreshes := []struct {
+		desc     string
+		at       time.Duration
+		items    []item
+		expected []item
+	}{
+		{
+			desc:     "initial refresh",
+			items:    []item{web1, web2, web3},
+			expected: []item{web1, web2, web3},
+		},
+		{
+			desc:     "allocation stopping",
+			at:       10 * time.Second,
+			items:    []item{web1, drained(web2), web3},
+			expected: []item{web1, web3, drained(web2)},
+		},
+		{
+			desc:     "service deregistered",
+			at:       20 * time.Second,
+			items:    []item{web1},
+			expected: []item{web1, drained(web2), drained(web3)},
+		},
+		{
+			desc:     "drain window of the stopping allocation elapsed",
+			at:       70 * time.Second,
+			items:    []item{web1},
+			expected: []item{web1, drained(web3)},
+		},
+		{
+			desc:     "drain window of the deregistered service elapsed",
+			at:       80 * time.Second,
+			items:    []item{web1},
+			expected: []item{web1},
+		},
+	}
+
+	for _, refresh := range refreshes {
+		items := p.drain(context.Background(), refresh.items, now.Add(refresh.at))
+		assert.Equal(t, refresh.expected, items, refresh.desc)
+	}
+}
+
+func Test_drain_stillStopping(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	p.DrainTimeout = ptypes.Duration(time.Minute)
+	p.lastItems = make(map[string]item)
+	p.draining = make(map[string]drainingItem)
+
+	now := time.Now()
+	web := item{ID: "web1", Name: "web", AllocID: "alloc1", Draining: true}
+
+	assert.Equal(t, []item{web}, p.drain(context.Background(), []item{web}, now))
+
+	// the allocation is still stopping after the drain window,
+	// it must not be drained again.
+	assert.Empty(t, p.drain(context.Background(), []item{web}, now.Add(time.Minute)))
+	assert.Empty(t, p.drain(context.Background(), []item{web}, now.Add(2*time.Minute)))
+
+	// nor once deregistered.
+	assert.Empty(t, p.drain(context.Background(), nil, now.Add(3*time.Minute)))
+}
+
+func Test_drain_disabled(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+
+	items := []item{{ID: "web1", Draining: true}}
+	assert.Equal(t, items, p.drain(context.Background(), items, time.Now()))
+}
@@ -0,0 +1,79 @@
+package nomad
+
+import (
+	"context"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog/log"
+)
+
+// holdEmpty returns the items of the last refresh which discovered services,
+// while consecutive refreshes discover none, until the empty refresh threshold is reached,
+// so that an API hiccup listing no service does not take all the routes down.
+// The empty refresh is accepted right away when the Nomad API reports a deregistration,
+// as the index of the listing of each region advanced since the last discovered services.
+func (p *Provider) holdEmpty(ctx context.Context, items []item) []item {
+	if len(items) > 0 {
+		p.emptyRefreshes = 0
+		p.heldItems = items
+		p.heldIndexes = p.copyIndexes()
+		return items
+	}
+
+	if len(p.heldItems) == 0 || p.AllowEmptyRefresh {
+		return items
+	}
+
+	logger := log.Ctx(ctx)
+
+	if p.deregistered() {
+		logger.Debug().Msg("The Nomad services were deregistered, removing their routes")
+		p.releaseHeld()
+		return items
+	}
+
+	p.emptyRefreshes++
+	if p.emptyRefreshes >= p.EmptyRefreshThreshold {
+		logger.Warn().Msgf("No Nomad service discovered on %d consecutive refreshes, removing the routes of the %d last known instance(s)",
+			p.emptyRefreshes, len(p.heldItems))
+		p.releaseHeld()
+		return items
+	}
+
+	logger.Warn().Msgf("No Nomad service discovered (%d/%d), keeping the %d last known instance(s)",
+		p.emptyRefreshes, p.EmptyRefreshThreshold, len(p.heldItems))
+
+	return p.heldItems
+}
+
+// deregistered reports whether the index of the services of every region advanced since the last discovered services,
+// which is not the case of a listing returned by a Nomad server which lost or has not caught up with the state of the cluster.
+func (p *Provider) deregistered() bool {
+	current := p.copyIndexes()
+
+	for client, index := range p.heldIndexes {
+		if index == 0 || current[client] <= index {
+			return false
+		}
+	}
+
+	return len(p.heldIndexes) > 0
+}
+
+func (p *Provider) releaseHeld() {
+	p.emptyRefreshes = 0
+	p.heldItems = nil
+	p.heldIndexes = nil
+}
+
+func (p *Provider) copyIndexes() map[*api.Client]uint64 {
+	p.indexesMu.Lock()
+	defer p.indexesMu.Unlock()
+
+	indexes := make(map[*api.Client]uint64, len(p.indexes))
+	for client, index := range p.indexes {
+		indexes[client] = index
+	}
+
+	return indexes
+}
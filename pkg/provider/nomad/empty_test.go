@@ -0,0 +1,107 @@
+package nomad
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_holdEmpty(t *testing.T) {
+	web := item{ID: "web1", Name: "web"}
+
+	testCases := []struct {
+		desc       string
+		threshold  int
+		allowEmpty bool
+		// indexes of the listings of the refreshes, the first one discovering the web service.
+		indexes  []uint64
+		expected [][]item
+	}{
+		{
+			desc:      "hiccup shorter than the threshold",
+			threshold: 3,
+			indexes:   []uint64{10, 10, 10},
+			expected:  [][]item{{web}, {web}, {web}},
+		},
+		{
+			desc:      "threshold reached",
+			threshold: 3,
+			indexes:   []uint64{10, 10, 10, 10},
+			expected:  [][]item{{web}, {web}, {web}, nil},
+		},
+		{
+			desc:      "listing of a server behind",
+			threshold: 3,
+			indexes:   []uint64{10, 5},
+			expected:  [][]item{{web}, {web}},
+		},
+		{
+			desc:      "deregistration",
+			threshold: 3,
+			indexes:   []uint64{10, 11},
+			expected:  [][]item{{web}, nil},
+		},
+		{
+			desc:       "empty refreshes allowed",
+			threshold:  3,
+			allowEmpty: true,
+			indexes:    []uint64{10, 10},
+			expected:   [][]item{{web}, nil},
+		},
+		{
+			desc:     "default threshold",
+			indexes:  []uint64{10, 10},
+			expected: [][]item{{web}, nil},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			p := new(Provider)
+			p.SetDefaults()
+			if test.threshold > 0 {
+				p.EmptyRefreshThreshold = test.threshold
+			}
+			p.AllowEmptyRefresh = test.allowEmpty
+
+			client := &api.Client{}
+			p.indexes = map[*api.Client]uint64{}
+
+			for i, index := range test.indexes {
+				p.setLastIndex(client, index)
+
+				var items []item
+				if i == 0 {
+					items = []item{web}
+				}
+
+				assert.Equal(t, test.expected[i], p.holdEmpty(context.Background(), items), "refresh %d", i)
+			}
+		})
+	}
+}
+
+func Test_holdEmpty_rediscovered(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	p.EmptyRefreshThreshold = 3
+	p.indexes = map[*api.Client]uint64{}
+
+	web := item{ID: "web1", Name: "web"}
+	api1 := item{ID: "api1", Name: "api"}
+
+	assert.Equal(t, []item{web}, p.holdEmpty(context.Background(), []item{web}))
+	assert.Equal(t, []item{web}, p.holdEmpty(context.Background(), nil))
+	assert.Equal(t, []item{web}, p.holdEmpty(context.Background(), nil))
+
+	// a refresh discovering services resets the count of the empty refreshes.
+	assert.Equal(t, []item{api1}, p.holdEmpty(context.Background(), []item{api1}))
+	assert.Equal(t, []item{api1}, p.holdEmpty(context.Background(), nil))
+	assert.Equal(t, []item{api1}, p.holdEmpty(context.Background(), nil))
+	assert.Empty(t, p.holdEmpty(context.Background(), nil))
+}
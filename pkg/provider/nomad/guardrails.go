@@ -0,0 +1,77 @@
+package nomad
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/provider"
+)
+
+// guardrailsMiddlewarePrefix is the prefix of the name of the request limits middleware attached to the routers.
+const guardrailsMiddlewarePrefix = "nomad-guardrails-"
+
+// Guardrails holds the platform-wide limits of the requests forwarded to the services.
+// A service can tighten the limits with the <prefix>.nomad.maxbodybytes and <prefix>.nomad.requesttimeout tags,
+// but not loosen them.
+type Guardrails struct {
+	MaxBodyBytes   int64           `description:"Maximum size of the request bodies, in bytes. Not limited when zero." json:"maxBodyBytes,omitempty" toml:"maxBodyBytes,omitempty" yaml:"maxBodyBytes,omitempty" export:"true"`
+	RequestTimeout ptypes.Duration `description:"Maximum duration of the requests, including the transfer of the response body. Not limited when zero." json:"requestTimeout,omitempty" toml:"requestTimeout,omitempty" yaml:"requestTimeout,omitempty" export:"true"`
+}
+
+func (g *Guardrails) init() error {
+	if g.MaxBodyBytes < 0 {
+		return errors.New("the maximum body size must be positive")
+	}
+
+	if g.RequestTimeout < 0 {
+		return errors.New("the request timeout must be positive")
+	}
+
+	return nil
+}
+
+// addGuardrails attaches to the routers the request limits middleware enforcing the limits of the service,
+// that is the platform-wide limits, tightened by the ones of the service.
+// The middleware is named after the limits, so that the services with the same limits share it.
+func (p *Provider) addGuardrails(i item, configuration *dynamic.HTTPConfiguration) {
+	if p.Guardrails == nil || len(configuration.Routers) == 0 {
+		return
+	}
+
+	maxBodyBytes := tighten(p.Guardrails.MaxBodyBytes, i.ExtraConf.MaxBodyBytes)
+	requestTimeout := tighten(int64(p.Guardrails.RequestTimeout), int64(i.ExtraConf.RequestTimeout))
+
+	if maxBodyBytes == 0 && requestTimeout == 0 {
+		return
+	}
+
+	name := provider.Normalize(guardrailsMiddlewarePrefix + strconv.FormatInt(maxBodyBytes, 10) + "-" + time.Duration(requestTimeout).String())
+
+	// the limits apply before the middlewares of the service, e.g. before an authentication reading the body.
+	for _, router := range configuration.Routers {
+		router.Middlewares = append([]string{name}, router.Middlewares...)
+	}
+
+	if configuration.Middlewares == nil {
+		configuration.Middlewares = make(map[string]*dynamic.Middleware)
+	}
+
+	configuration.Middlewares[name] = &dynamic.Middleware{
+		RequestLimits: &dynamic.RequestLimits{
+			MaxBodyBytes: maxBodyBytes,
+			Timeout:      ptypes.Duration(requestTimeout),
+		},
+	}
+}
+
+// tighten returns the value when it is lower than the limit, the limit otherwise, zero meaning no limit.
+func tighten(limit, value int64) int64 {
+	if value > 0 && (limit == 0 || value < limit) {
+		return value
+	}
+
+	return limit
+}
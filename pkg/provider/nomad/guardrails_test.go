@@ -0,0 +1,122 @@
+package nomad
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func Test_buildConfig_guardrails(t *testing.T) {
+	platform := &Guardrails{MaxBodyBytes: 1048576, RequestTimeout: ptypes.Duration(30 * time.Second)}
+
+	testCases := []struct {
+		desc                string
+		guardrails          *Guardrails
+		tags                []string
+		expectedMiddlewares []string
+		expectedLimits      *dynamic.RequestLimits
+	}{
+		{
+			desc: "no guardrails",
+			tags: []string{"traefik.nomad.maxbodybytes=1024"},
+		},
+		{
+			desc:                "platform limits",
+			guardrails:          platform,
+			expectedMiddlewares: []string{"nomad-guardrails-1048576-30s"},
+			expectedLimits:      &dynamic.RequestLimits{MaxBodyBytes: 1048576, Timeout: ptypes.Duration(30 * time.Second)},
+		},
+		{
+			desc:                "limits tightened by the service",
+			guardrails:          platform,
+			tags:                []string{"traefik.nomad.maxbodybytes=1024", "traefik.nomad.requesttimeout=5s"},
+			expectedMiddlewares: []string{"nomad-guardrails-1024-5s"},
+			expectedLimits:      &dynamic.RequestLimits{MaxBodyBytes: 1024, Timeout: ptypes.Duration(5 * time.Second)},
+		},
+		{
+			desc:                "limits not loosened by the service",
+			guardrails:          platform,
+			tags:                []string{"traefik.nomad.maxbodybytes=10485760", "traefik.nomad.requesttimeout=1m"},
+			expectedMiddlewares: []string{"nomad-guardrails-1048576-30s"},
+			expectedLimits:      &dynamic.RequestLimits{MaxBodyBytes: 1048576, Timeout: ptypes.Duration(30 * time.Second)},
+		},
+		{
+			desc:                "invalid service limits ignored",
+			guardrails:          platform,
+			tags:                []string{"traefik.nomad.maxbodybytes=foo", "traefik.nomad.requesttimeout=-1s"},
+			expectedMiddlewares: []string{"nomad-guardrails-1048576-30s"},
+			expectedLimits:      &dynamic.RequestLimits{MaxBodyBytes: 1048576, Timeout: ptypes.Duration(30 * time.Second)},
+		},
+		{
+			desc:                "service limit without platform limit",
+			guardrails:          &Guardrails{MaxBodyBytes: 1048576},
+			tags:                []string{"traefik.nomad.requesttimeout=5s"},
+			expectedMiddlewares: []string{"nomad-guardrails-1048576-5s"},
+			expectedLimits:      &dynamic.RequestLimits{MaxBodyBytes: 1048576, Timeout: ptypes.Duration(5 * time.Second)},
+		},
+		{
+			desc:       "no limits",
+			guardrails: &Guardrails{},
+		},
+		{
+			desc:       "limits before the router middlewares",
+			guardrails: platform,
+			tags: []string{
+				"traefik.http.routers.Test.middlewares=auth",
+				"traefik.http.middlewares.auth.basicauth.users=test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/",
+			},
+			expectedMiddlewares: []string{"nomad-guardrails-1048576-30s", "auth"},
+			expectedLimits:      &dynamic.RequestLimits{MaxBodyBytes: 1048576, Timeout: ptypes.Duration(30 * time.Second)},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			p.DefaultRule = "Host(`{{ normalize .Name }}.traefik.test`)"
+			p.Guardrails = test.guardrails
+			err := p.Init()
+			require.NoError(t, err)
+
+			items := []item{
+				{
+					ID:        "id1",
+					Name:      "Test",
+					Namespace: "ns1",
+					Job:       "job1",
+					Tags:      test.tags,
+					Address:   "127.0.0.1",
+					Port:      9999,
+					ExtraConf: p.getExtraConf(test.tags),
+				},
+			}
+
+			c := p.buildConfig(context.TODO(), items)
+
+			require.Contains(t, c.HTTP.Routers, "Test")
+			assert.Equal(t, test.expectedMiddlewares, c.HTTP.Routers["Test"].Middlewares)
+
+			if test.expectedLimits == nil {
+				for name := range c.HTTP.Middlewares {
+					assert.NotContains(t, name, "nomad-guardrails")
+				}
+				return
+			}
+
+			require.Contains(t, c.HTTP.Middlewares, test.expectedMiddlewares[0])
+			assert.Equal(t, test.expectedLimits, c.HTTP.Middlewares[test.expectedMiddlewares[0]].RequestLimits)
+		})
+	}
+}
+
+func TestGuardrails_init(t *testing.T) {
+	assert.NoError(t, (&Guardrails{}).init())
+	assert.Error(t, (&Guardrails{MaxBodyBytes: -1}).init())
+	assert.Error(t, (&Guardrails{RequestTimeout: ptypes.Duration(-time.Second)}).init())
+}
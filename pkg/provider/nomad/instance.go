@@ -0,0 +1,54 @@
+package nomad
+
+import "github.com/traefik/traefik/v3/pkg/config/dynamic"
+
+// Instance is the Nomad service instance backing a server, as reported by the API.
+type Instance struct {
+	ServiceName string `json:"serviceName"`
+	ServiceID   string `json:"serviceID"`
+	Namespace   string `json:"namespace,omitempty"`
+	Job         string `json:"job,omitempty"`
+	AllocID     string `json:"allocID,omitempty"`
+	Node        string `json:"node,omitempty"`
+	Datacenter  string `json:"datacenter,omitempty"`
+}
+
+// Instance returns the service instance backing the HTTP server with the given URL, discovered on the last refresh.
+func (p *Provider) Instance(serverURL string) (Instance, bool) {
+	p.instancesMu.RLock()
+	defer p.instancesMu.RUnlock()
+
+	instance, ok := p.instances[serverURL]
+	return instance, ok
+}
+
+func (p *Provider) setInstances(instances map[string]Instance) {
+	p.instancesMu.Lock()
+	p.instances = instances
+	p.instancesMu.Unlock()
+}
+
+// addInstances records the item as the instance backing the servers of its HTTP services.
+func addInstances(i item, configuration *dynamic.HTTPConfiguration, instances map[string]Instance) {
+	for _, service := range configuration.Services {
+		if service.LoadBalancer == nil {
+			continue
+		}
+
+		for _, server := range service.LoadBalancer.Servers {
+			instances[server.URL] = newInstance(i)
+		}
+	}
+}
+
+func newInstance(i item) Instance {
+	return Instance{
+		ServiceName: i.Name,
+		ServiceID:   i.ID,
+		Namespace:   i.Namespace,
+		Job:         i.Job,
+		AllocID:     i.AllocID,
+		Node:        i.Node,
+		Datacenter:  i.Datacenter,
+	}
+}
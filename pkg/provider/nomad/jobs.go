@@ -0,0 +1,171 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog/log"
+)
+
+// jobTypes are the types of Nomad jobs, the sysbatch type is not part of the API client constants.
+var jobTypes = []string{api.JobTypeService, api.JobTypeSystem, api.JobTypeBatch, "sysbatch"}
+
+// validateJobFilters checks the job selector and the job types of the configuration.
+func (c *Configuration) validateJobFilters() error {
+	if _, err := path.Match(c.JobSelector, ""); err != nil {
+		return fmt.Errorf("invalid job selector %q: %w", c.JobSelector, err)
+	}
+
+	for _, jobType := range c.JobTypes {
+		if !contains(jobTypes, jobType) {
+			return fmt.Errorf("invalid job type %q, expected one of %q", jobType, jobTypes)
+		}
+	}
+
+	return nil
+}
+
+// jobMetaConstraint is a constraint on a meta key of the jobs, e.g. traefik-tenant == team-a.
+type jobMetaConstraint struct {
+	key    string
+	value  string
+	negate bool
+}
+
+// parseJobMetaConstraint parses a "key == value" or "key != value" constraint, nil when the constraint is empty.
+func parseJobMetaConstraint(constraint string) (*jobMetaConstraint, error) {
+	if strings.TrimSpace(constraint) == "" {
+		return nil, nil
+	}
+
+	operator := "=="
+	key, value, found := strings.Cut(constraint, operator)
+	if !found {
+		operator = "!="
+		key, value, found = strings.Cut(constraint, operator)
+	}
+	if !found {
+		return nil, fmt.Errorf("invalid job meta constraint %q, expected \"key == value\" or \"key != value\"", constraint)
+	}
+
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, fmt.Errorf("invalid job meta constraint %q, the meta key is empty", constraint)
+	}
+
+	return &jobMetaConstraint{
+		key:    key,
+		value:  strings.Trim(strings.TrimSpace(value), `"`),
+		negate: operator == "!=",
+	}, nil
+}
+
+// match reports whether the meta of a job matches the constraint.
+func (c *jobMetaConstraint) match(meta map[string]string) bool {
+	return (meta[c.key] == c.value) != c.negate
+}
+
+// filter returns the filter expression of the constraint, evaluated by the Nomad API on the job list stubs.
+func (c *jobMetaConstraint) filter() string {
+	operator := "=="
+	if c.negate {
+		operator = "!="
+	}
+
+	return fmt.Sprintf("Meta[%q] %s %q", c.key, operator, c.value)
+}
+
+// jobInfo holds the fields of a job used to filter its services.
+type jobInfo struct {
+	jobType string
+	meta    map[string]string
+}
+
+// jobsCache holds the jobs fetched during a refresh, indexed by job ID.
+type jobsCache struct {
+	// matching are the IDs of the jobs matching the job meta constraint, as listed by the Nomad API,
+	// nil when the API cannot filter the jobs by meta.
+	matching map[string]struct{}
+	jobs     map[string]jobInfo
+}
+
+// newJobsCache creates the jobs cache of a refresh.
+// When a job meta constraint is configured, the matching jobs are listed with a filtered query,
+// so that the jobs of the other tenants are not fetched.
+// The Nomad versions whose job list stubs do not have the meta reject the filter,
+// the jobs are then fetched and matched one by one.
+func (p *Provider) newJobsCache(ctx context.Context, client *api.Client) *jobsCache {
+	cache := &jobsCache{jobs: make(map[string]jobInfo)}
+
+	if p.jobMeta == nil {
+		return cache
+	}
+
+	opts := &api.QueryOptions{
+		AllowStale: p.Stale,
+		Filter:     p.jobMeta.filter(),
+		Params:     map[string]string{"meta": "true"},
+	}
+	opts = opts.WithContext(ctx)
+
+	stubs, _, err := client.Jobs().List(opts)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Unable to filter the Nomad jobs by meta, the jobs are matched one by one")
+		return cache
+	}
+
+	cache.matching = make(map[string]struct{}, len(stubs))
+	for _, stub := range stubs {
+		cache.matching[stub.ID] = struct{}{}
+	}
+
+	return cache
+}
+
+// keepJob reports whether the services of the job are discovered, according to the job selector, job types, and job meta constraint.
+// The jobs are only fetched when they need to be matched by type or meta, once per refresh.
+func (p *Provider) keepJob(ctx context.Context, client *api.Client, cache *jobsCache, jobID string) (bool, error) {
+	if p.JobSelector != "" {
+		// the pattern is validated on init.
+		if matched, _ := path.Match(p.JobSelector, jobID); !matched {
+			return false, nil
+		}
+	}
+
+	metaMatched := cache.matching != nil
+	if metaMatched {
+		if _, ok := cache.matching[jobID]; !ok {
+			return false, nil
+		}
+	}
+
+	if len(p.JobTypes) == 0 && (p.jobMeta == nil || metaMatched) {
+		return true, nil
+	}
+
+	job, ok := cache.jobs[jobID]
+	if !ok {
+		opts := &api.QueryOptions{AllowStale: p.Stale}
+		opts = opts.WithContext(ctx)
+
+		j, _, err := client.Jobs().Info(jobID, opts)
+		if err != nil {
+			return false, fmt.Errorf("failed to fetch job %s: %w", jobID, err)
+		}
+
+		if j.Type != nil {
+			job.jobType = *j.Type
+		}
+		job.meta = j.Meta
+		cache.jobs[jobID] = job
+	}
+
+	if p.jobMeta != nil && !metaMatched && !p.jobMeta.match(job.meta) {
+		return false, nil
+	}
+
+	return len(p.JobTypes) == 0 || contains(p.JobTypes, job.jobType), nil
+}
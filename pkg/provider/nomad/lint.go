@@ -0,0 +1,389 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// TagLint is a tag of a Nomad service whose key is unknown, as reported by the API.
+// A tag with an unknown key is either ignored, or makes the configuration of the service fail.
+type TagLint struct {
+	ServiceName string `json:"serviceName"`
+	Namespace   string `json:"namespace,omitempty"`
+	Tag         string `json:"tag"`
+	Message     string `json:"message"`
+	// Suggestion is the closest known key, empty when none is close enough.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// extraKeys are the keys of the labels read by the provider which are not part of the dynamic configuration, see getExtraConf.
+// They are matched case-sensitively.
+var extraKeys = []string{
+	"enable",
+	"nomad.canary",
+	"nomad.secureheaders",
+	"nomad.failoverTier",
+	"nomad.maxbodybytes",
+	"nomad.requesttimeout",
+	"nomad.mirror.percent",
+	"nomad.mirror.rule",
+	"nomad.versionweights",
+	"nomad.router.middlewares",
+	"nomad.router.entrypoints",
+}
+
+// keyNode is a node of the tree of the label keys known by the provider.
+type keyNode struct {
+	name string
+	// caseSensitive reports whether the name only matches with the same case.
+	caseSensitive bool
+
+	children map[string]*keyNode // indexed by lowercase name
+	// names is the node below the names chosen in the configuration, e.g. the router names, nil when there are none.
+	names *keyNode
+	// anything reports whether any key is known below the node, e.g. for the plugin configurations.
+	anything bool
+}
+
+var (
+	knownKeysOnce sync.Once
+	knownKeys     *keyNode
+)
+
+// getKnownKeys returns the tree of the known keys, below the "traefik" root of the labels.
+func getKnownKeys() *keyNode {
+	knownKeysOnce.Do(func() {
+		// only the HTTP, TCP, and UDP configurations are decoded from the labels.
+		knownKeys = &keyNode{children: map[string]*keyNode{}}
+		seen := map[reflect.Type]*keyNode{}
+		knownKeys.add("http", newKeyNode(reflect.TypeOf(dynamic.HTTPConfiguration{}), seen))
+		knownKeys.add("tcp", newKeyNode(reflect.TypeOf(dynamic.TCPConfiguration{}), seen))
+		knownKeys.add("udp", newKeyNode(reflect.TypeOf(dynamic.UDPConfiguration{}), seen))
+
+		// the servers transport labels are split from the others, see serversTransportLabelPrefix.
+		knownKeys.children["http"].add("serversTransport", newKeyNode(reflect.TypeOf(dynamic.ServersTransport{}), seen))
+
+		for _, key := range extraKeys {
+			node := knownKeys
+			for _, name := range strings.Split(key, ".") {
+				child, ok := node.children[strings.ToLower(name)]
+				if !ok {
+					child = node.add(name, &keyNode{children: map[string]*keyNode{}})
+					child.caseSensitive = true
+				}
+				node = child
+			}
+		}
+	})
+
+	return knownKeys
+}
+
+// newKeyNode returns the tree of the label keys decoded into the given type.
+func newKeyNode(typ reflect.Type, seen map[reflect.Type]*keyNode) *keyNode {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if node, ok := seen[typ]; ok {
+		return node
+	}
+
+	node := &keyNode{children: map[string]*keyNode{}}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		seen[typ] = node
+		addFields(node, typ, seen)
+
+	case reflect.Map:
+		node.names = newKeyNode(typ.Elem(), seen)
+
+	case reflect.Slice:
+		elem := typ.Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		// the elements of the slices of structs are indexed, e.g. "certificates[0].certFile".
+		if elem.Kind() == reflect.Struct {
+			return newKeyNode(elem, seen)
+		}
+
+	case reflect.Interface:
+		node.anything = true
+	}
+
+	return node
+}
+
+func addFields(node *keyNode, typ reflect.Type, seen map[reflect.Type]*keyNode) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() || field.Tag.Get("label") == "-" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addFields(node, field.Type, seen)
+			continue
+		}
+
+		// the names are matched case-insensitively, and are suggested as written in the documentation.
+		name := field.Name
+		if jsonName, _, _ := strings.Cut(field.Tag.Get("json"), ","); strings.EqualFold(jsonName, name) {
+			name = jsonName
+		}
+		if sliceName := field.Tag.Get("label-slice-as-struct"); sliceName != "" {
+			name = sliceName
+		}
+
+		node.add(name, newKeyNode(field.Type, seen))
+	}
+}
+
+// add adds a child to the node, and returns it.
+// The node of a type being shared by all the fields of this type, the child gets its own copy holding its name.
+func (n *keyNode) add(name string, child *keyNode) *keyNode {
+	named := *child
+	named.name = name
+	n.children[strings.ToLower(name)] = &named
+
+	return &named
+}
+
+// lintKey reports whether the key of a label, e.g. "traefik.http.routers.foo.rule", is known,
+// and returns the closest known key otherwise, empty when none is close enough.
+func lintKey(key string) (bool, string) {
+	segments := strings.Split(strings.TrimPrefix(key, "traefik."), ".")
+
+	known := true
+	node := getKnownKeys()
+	for i, segment := range segments {
+		if node.anything {
+			break
+		}
+
+		// the index of the slice elements is not part of the name.
+		name, index := segment, ""
+		if open := strings.IndexByte(segment, '['); open > 0 && strings.HasSuffix(segment, "]") {
+			name, index = segment[:open], segment[open:]
+		}
+
+		if child, ok := node.children[strings.ToLower(name)]; ok {
+			if child.caseSensitive && child.name != name {
+				known = false
+				segments[i] = child.name + index
+			}
+			node = child
+			continue
+		}
+
+		if node.names != nil {
+			node = node.names
+			continue
+		}
+
+		known = false
+
+		child := node.closest(name)
+		if child == nil {
+			return false, ""
+		}
+		segments[i] = child.name + index
+		node = child
+	}
+
+	if known {
+		return true, ""
+	}
+
+	return false, "traefik." + strings.Join(segments, ".")
+}
+
+// closest returns the child whose name is the closest to the given one, nil when none is close enough.
+func (n *keyNode) closest(name string) *keyNode {
+	names := make([]string, 0, len(n.children))
+	for lower := range n.children {
+		names = append(names, lower)
+	}
+	sort.Strings(names)
+
+	var best *keyNode
+	bestDistance := -1
+	for _, lower := range names {
+		distance := editDistance(strings.ToLower(name), lower)
+		if closeEnough(distance, len(lower)) && (bestDistance < 0 || distance < bestDistance) {
+			best, bestDistance = n.children[lower], distance
+		}
+	}
+
+	return best
+}
+
+// closeEnough reports whether a name at the given edit distance of a known name, of the given length, is a typo of it.
+func closeEnough(distance, length int) bool {
+	return distance <= 2 && distance*3 <= length
+}
+
+// editDistance returns the number of insertions, deletions, substitutions, and transpositions of adjacent characters
+// turning a into b (optimal string alignment distance).
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// rows of the distances between the prefixes of a and b, the previous ones being kept for the transpositions.
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = minInt(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// lintTags returns the tags of a service whose key is unknown, either starting with the prefix,
+// or whose prefix is a typo of it, e.g. "treafik.enable".
+func lintTags(namespace, serviceName string, tags []string, prefix string) []TagLint {
+	var lints []TagLint
+	seen := make(map[string]struct{})
+
+	for _, tag := range tags {
+		key, _, ok := strings.Cut(tag, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		lint := TagLint{ServiceName: serviceName, Namespace: namespace, Tag: key}
+
+		if rest, ok := strings.CutPrefix(key, prefix+"."); ok {
+			known, suggestion := lintKey("traefik." + rest)
+			if known {
+				continue
+			}
+
+			if suggestion != "" {
+				lint.Suggestion = withPrefix(suggestion, prefix)
+			}
+		} else {
+			first, rest, ok := strings.Cut(key, ".")
+			if !ok || !closeEnough(editDistance(strings.ToLower(first), strings.ToLower(prefix)), len(prefix)) {
+				continue
+			}
+
+			// the tag is only reported when the rest of its key is known, not to suggest the prefix for unrelated tags.
+			known, suggestion := lintKey("traefik." + rest)
+			switch {
+			case known:
+				lint.Suggestion = prefix + "." + rest
+			case suggestion != "":
+				lint.Suggestion = withPrefix(suggestion, prefix)
+			default:
+				continue
+			}
+		}
+
+		lint.Message = "unknown tag"
+		if lint.Suggestion != "" {
+			lint.Message = fmt.Sprintf("unknown tag, did you mean %s?", lint.Suggestion)
+		}
+
+		lints = append(lints, lint)
+	}
+
+	return lints
+}
+
+// withPrefix returns the key of a label, e.g. "traefik.enable", with the configured prefix, as written in the Nomad job.
+func withPrefix(key, prefix string) string {
+	return prefix + "." + strings.TrimPrefix(key, "traefik.")
+}
+
+// TagLints returns the tags with an unknown key of the services listed on the last refresh.
+func (p *Provider) TagLints() []TagLint {
+	p.lintsMu.RLock()
+	defer p.lintsMu.RUnlock()
+
+	var lints []TagLint
+	for _, clientLints := range p.lints {
+		lints = append(lints, clientLints...)
+	}
+
+	sort.Slice(lints, func(i, j int) bool {
+		if lints[i].Namespace != lints[j].Namespace {
+			return lints[i].Namespace < lints[j].Namespace
+		}
+		if lints[i].ServiceName != lints[j].ServiceName {
+			return lints[i].ServiceName < lints[j].ServiceName
+		}
+		return lints[i].Tag < lints[j].Tag
+	})
+
+	return lints
+}
+
+// setTagLints sets the tags with an unknown key of the services listed with the client,
+// and logs the ones which were not reported by the previous refresh, not to log them on each refresh.
+func (p *Provider) setTagLints(ctx context.Context, client *api.Client, lints []TagLint) {
+	p.lintsMu.Lock()
+	defer p.lintsMu.Unlock()
+
+	previous := make(map[TagLint]struct{}, len(p.lints[client]))
+	for _, lint := range p.lints[client] {
+		previous[lint] = struct{}{}
+	}
+
+	for _, lint := range lints {
+		if _, ok := previous[lint]; ok {
+			continue
+		}
+
+		logger := log.Ctx(ctx).Warn().Str("serviceName", lint.ServiceName).Str("namespace", lint.Namespace).Str("tag", lint.Tag)
+		if lint.Suggestion == "" {
+			logger.Msg("Unknown tag")
+			continue
+		}
+		logger.Msgf("Unknown tag, did you mean %s?", lint.Suggestion)
+	}
+
+	if p.lints == nil {
+		p.lints = make(map[*api.Client][]TagLint)
+	}
+	p.lints[client] = lints
+}
@@ -0,0 +1,222 @@
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lintKey(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		key                string
+		expectedKnown      bool
+		expectedSuggestion string
+	}{
+		{
+			desc:          "router rule",
+			key:           "traefik.http.routers.foo.rule",
+			expectedKnown: true,
+		},
+		{
+			desc:          "case-insensitive key",
+			key:           "traefik.http.routers.foo.TLS.CertResolver",
+			expectedKnown: true,
+		},
+		{
+			desc:          "server port",
+			key:           "traefik.http.services.foo.loadbalancer.server.port",
+			expectedKnown: true,
+		},
+		{
+			desc:          "headers names",
+			key:           "traefik.http.middlewares.foo.headers.customrequestheaders.X-Foo",
+			expectedKnown: true,
+		},
+		{
+			desc:          "plugin configuration",
+			key:           "traefik.http.middlewares.foo.plugin.bar.baz.qux",
+			expectedKnown: true,
+		},
+		{
+			desc:          "servers transport",
+			key:           "traefik.http.serverstransport.insecureSkipVerify",
+			expectedKnown: true,
+		},
+		{
+			desc:          "extra configuration",
+			key:           "traefik.nomad.failoverTier",
+			expectedKnown: true,
+		},
+		{
+			desc:               "typo in a field",
+			key:                "traefik.http.routers.foo.rle",
+			expectedSuggestion: "traefik.http.routers.foo.rule",
+		},
+		{
+			desc:               "typo in a nested field",
+			key:                "traefik.http.routers.foo.tls.certresolvr",
+			expectedSuggestion: "traefik.http.routers.foo.tls.certResolver",
+		},
+		{
+			desc:               "typo in a namespace",
+			key:                "traefik.htp.routers.foo.rule",
+			expectedSuggestion: "traefik.http.routers.foo.rule",
+		},
+		{
+			desc:               "typos in several segments",
+			key:                "traefik.http.routres.foo.rulle",
+			expectedSuggestion: "traefik.http.routers.foo.rule",
+		},
+		{
+			desc:               "transposition",
+			key:                "traefik.enabel",
+			expectedSuggestion: "traefik.enable",
+		},
+		{
+			desc:               "extra configuration with the wrong case",
+			key:                "traefik.nomad.failovertier",
+			expectedSuggestion: "traefik.nomad.failoverTier",
+		},
+		{
+			desc: "unknown field",
+			key:  "traefik.http.routers.foo.something",
+		},
+		{
+			desc: "below a value",
+			key:  "traefik.http.routers.foo.rule.foo",
+		},
+		{
+			desc: "TLS configuration",
+			key:  "traefik.tls.options.foo.minVersion",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			known, suggestion := lintKey(test.key)
+			assert.Equal(t, test.expectedKnown, known)
+			assert.Equal(t, test.expectedSuggestion, suggestion)
+		})
+	}
+}
+
+func Test_editDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("traefik", "traefik"))
+	assert.Equal(t, 1, editDistance("treafik", "traefik"))
+	assert.Equal(t, 1, editDistance("rle", "rule"))
+	assert.Equal(t, 2, editDistance("traefk.", "traefik"))
+	assert.Equal(t, 3, editDistance("", "foo"))
+}
+
+func Test_lintTags(t *testing.T) {
+	tags := []string{
+		"traefik.enable=true",
+		"traefik.http.routers.foo.rle=Host(`example.com`)",
+		"traefik.http.routers.foo.rle=Host(`example.org`)",
+		"traefik.http.routers.foo.something=foo",
+		"treafik.enable=true",
+		"Traefik.http.routers.foo.rule=Host(`example.com`)",
+		"trafik.http.routers.foo.entrypont=web",
+		"trafic.foo=bar",
+		"team.enable=true",
+		"traefik.enable",
+		"version=1.2",
+	}
+
+	lints := lintTags("ns", "foo", tags, "traefik")
+
+	expected := []TagLint{
+		{
+			ServiceName: "foo",
+			Namespace:   "ns",
+			Tag:         "traefik.http.routers.foo.rle",
+			Message:     "unknown tag, did you mean traefik.http.routers.foo.rule?",
+			Suggestion:  "traefik.http.routers.foo.rule",
+		},
+		{
+			ServiceName: "foo",
+			Namespace:   "ns",
+			Tag:         "traefik.http.routers.foo.something",
+			Message:     "unknown tag",
+		},
+		{
+			ServiceName: "foo",
+			Namespace:   "ns",
+			Tag:         "treafik.enable",
+			Message:     "unknown tag, did you mean traefik.enable?",
+			Suggestion:  "traefik.enable",
+		},
+		{
+			ServiceName: "foo",
+			Namespace:   "ns",
+			Tag:         "Traefik.http.routers.foo.rule",
+			Message:     "unknown tag, did you mean traefik.http.routers.foo.rule?",
+			Suggestion:  "traefik.http.routers.foo.rule",
+		},
+		{
+			ServiceName: "foo",
+			Namespace:   "ns",
+			Tag:         "trafik.http.routers.foo.entrypont",
+			Message:     "unknown tag, did you mean traefik.http.routers.foo.entryPoints?",
+			Suggestion:  "traefik.http.routers.foo.entryPoints",
+		},
+	}
+
+	assert.Equal(t, expected, lints)
+}
+
+func Test_getNomadServiceData_tagLints(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/v1/services"):
+			_, _ = w.Write([]byte(`[{"Namespace":"default","Services":[
+				{"ServiceName":"hello","Tags":["traefik.enable=true","traefik.http.routers.hello.rle=Host(` + "`hello.example.com`" + `)"]},
+				{"ServiceName":"typo","Tags":["treafik.enable=true"]}
+			]}]`))
+		case strings.HasSuffix(r.URL.Path, "/v1/service/hello"):
+			_, _ = w.Write([]byte(`[]`))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.ExposedByDefault = false
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	_, err = p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+
+	expected := []TagLint{
+		{
+			ServiceName: "hello",
+			Namespace:   "default",
+			Tag:         "traefik.http.routers.hello.rle",
+			Message:     "unknown tag, did you mean traefik.http.routers.hello.rule?",
+			Suggestion:  "traefik.http.routers.hello.rule",
+		},
+		{
+			ServiceName: "typo",
+			Namespace:   "default",
+			Tag:         "treafik.enable",
+			Message:     "unknown tag, did you mean traefik.enable?",
+			Suggestion:  "traefik.enable",
+		},
+	}
+	assert.Equal(t, expected, p.TagLints())
+}
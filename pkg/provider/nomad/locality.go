@@ -0,0 +1,122 @@
+package nomad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/logs"
+)
+
+// localWeight is the weight of the servers of the local datacenter, the remote weight is relative to it.
+const localWeight = 100
+
+// Locality holds the configuration of the preference for the servers of the datacenter of the Traefik instance.
+type Locality struct {
+	Datacenter   string `description:"Datacenter of the Traefik instance. Defaults to the NOMAD_DC environment variable, set for the Nomad tasks." json:"datacenter,omitempty" toml:"datacenter,omitempty" yaml:"datacenter,omitempty" export:"true"`
+	RemoteWeight int    `description:"Weight of the servers of the other datacenters, relative to a weight of 100 for the local ones. When zero, they only receive the requests once all the local servers are down." json:"remoteWeight,omitempty" toml:"remoteWeight,omitempty" yaml:"remoteWeight,omitempty" export:"true"`
+}
+
+func (l *Locality) init() error {
+	if l.Datacenter == "" {
+		l.Datacenter = os.Getenv("NOMAD_DC")
+	}
+
+	if l.Datacenter == "" {
+		return errors.New("the datacenter is required, and the NOMAD_DC environment variable is not set")
+	}
+
+	if l.RemoteWeight < 0 {
+		return fmt.Errorf("invalid remote weight %d", l.RemoteWeight)
+	}
+
+	return nil
+}
+
+// failoverTier returns the failover tier of the item: its own tier when it declares one,
+// otherwise, when the remote servers are a fallback of the local ones, the tier of its datacenter.
+func (p *Provider) failoverTier(i item) int {
+	if i.ExtraConf.FailoverTier > 0 || p.Locality == nil || p.Locality.RemoteWeight > 0 {
+		return i.ExtraConf.FailoverTier
+	}
+
+	if i.Datacenter == p.Locality.Datacenter {
+		return 1
+	}
+
+	return 2
+}
+
+// addLocality renames the HTTP services of an item after its locality,
+// when the remote servers are weighted against the local ones.
+// The routers keep referencing the service name, which becomes the weighted service.
+func (p *Provider) addLocality(i item, configuration *dynamic.HTTPConfiguration, localities map[string]map[bool]struct{}) {
+	if i.ExtraConf.FailoverTier > 0 || p.Locality == nil || p.Locality.RemoteWeight == 0 {
+		return
+	}
+
+	local := i.Datacenter == p.Locality.Datacenter
+
+	services := make(map[string]*dynamic.Service, len(configuration.Services))
+	for name, service := range configuration.Services {
+		services[localityName(name, local)] = service
+
+		if localities[name] == nil {
+			localities[name] = make(map[bool]struct{})
+		}
+		localities[name][local] = struct{}{}
+	}
+	configuration.Services = services
+}
+
+// buildLocalityServices builds, for each service having both local and remote servers,
+// a weighted service balancing the requests between them.
+func (p *Provider) buildLocalityServices(ctx context.Context, configuration *dynamic.HTTPConfiguration, localities map[string]map[bool]struct{}) {
+	for name := range localities {
+		logger := log.Ctx(ctx).With().Str(logs.ServiceName, name).Logger()
+
+		if _, exists := configuration.Services[name]; exists {
+			logger.Error().Msg("Service defined both with and without a locality, skipping the locality")
+			continue
+		}
+
+		localName, remoteName := localityName(name, true), localityName(name, false)
+		_, hasLocal := configuration.Services[localName]
+		_, hasRemote := configuration.Services[remoteName]
+
+		switch {
+		case hasLocal && hasRemote:
+			configuration.Services[name] = &dynamic.Service{
+				Weighted: &dynamic.WeightedRoundRobin{
+					Services: []dynamic.WRRService{
+						{Name: localName, Weight: intPtr(localWeight)},
+						{Name: remoteName, Weight: intPtr(p.Locality.RemoteWeight)},
+					},
+				},
+			}
+
+		case hasLocal:
+			configuration.Services[name] = configuration.Services[localName]
+			delete(configuration.Services, localName)
+
+		case hasRemote:
+			configuration.Services[name] = configuration.Services[remoteName]
+			delete(configuration.Services, remoteName)
+		}
+	}
+}
+
+func localityName(name string, local bool) string {
+	if local {
+		return name + "-local"
+	}
+
+	return name + "-remote"
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,86 @@
+package nomad
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+)
+
+// metaTags returns the Traefik tags defined in the meta blocks of the job, of the task group,
+// of the task, and of the service of an allocation, as key=value tags.
+// The meta of the service overrides the one of its task, which overrides the one of the task group,
+// which overrides the one of the job.
+// When the allocation is a canary, the canary meta of the service is used instead of its meta, if any.
+func metaTags(alloc *api.Allocation, serviceName, prefix string) []string {
+	if alloc == nil || alloc.Job == nil {
+		return nil
+	}
+
+	meta := make(map[string]string)
+	merge := func(m map[string]string) {
+		for k, v := range m {
+			if strings.HasPrefix(k, prefix+".") {
+				meta[k] = v
+			}
+		}
+	}
+
+	merge(alloc.Job.Meta)
+
+	group := findTaskGroup(alloc.Job, alloc.TaskGroup)
+	if group != nil {
+		merge(group.Meta)
+
+		if service, task := findService(alloc.Job, group, serviceName); service != nil {
+			if task != nil {
+				merge(task.Meta)
+			}
+
+			if alloc.DeploymentStatus != nil && alloc.DeploymentStatus.Canary && len(service.CanaryMeta) > 0 {
+				merge(service.CanaryMeta)
+			} else {
+				merge(service.Meta)
+			}
+		}
+	}
+
+	tags := make([]string, 0, len(meta))
+	for k, v := range meta {
+		tags = append(tags, k+"="+v)
+	}
+
+	// tags are sorted, so that the items do not depend on the map iteration order.
+	sort.Strings(tags)
+
+	return tags
+}
+
+// findService returns the service of the task group, or of one of its tasks, registered with the given name,
+// along with the task registering it, nil for the services of the task group.
+// The service names of the job specification are interpolated with the job, group and task names,
+// as the registrations hold the interpolated names.
+func findService(job *api.Job, group *api.TaskGroup, serviceName string) (*api.Service, *api.Task) {
+	jobName := ""
+	if job.Name != nil {
+		jobName = *job.Name
+	}
+
+	groupName := *group.Name
+
+	for _, service := range group.Services {
+		if interpolateServiceName(service.Name, jobName, groupName, "") == serviceName {
+			return service, nil
+		}
+	}
+
+	for _, task := range group.Tasks {
+		for _, service := range task.Services {
+			if interpolateServiceName(service.Name, jobName, groupName, task.Name) == serviceName {
+				return service, task
+			}
+		}
+	}
+
+	return nil, nil
+}
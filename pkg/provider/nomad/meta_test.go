@@ -0,0 +1,155 @@
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_metaTags(t *testing.T) {
+	job := func(canaryMeta map[string]string) *api.Job {
+		return &api.Job{
+			Name: ptr("echo"),
+			Meta: map[string]string{
+				"traefik.http.routers.echo.rule":        "Host(`job.example.com`)",
+				"traefik.http.routers.echo.entrypoints": "web",
+				"owner":                                 "team-a",
+			},
+			TaskGroups: []*api.TaskGroup{
+				{
+					Name: ptr("api"),
+					Meta: map[string]string{
+						"traefik.http.routers.echo.rule": "Host(`group.example.com`)",
+						"traefik.enable":                 "true",
+					},
+					Services: []*api.Service{
+						{
+							Name: "${NOMAD_JOB_NAME}-${NOMAD_GROUP_NAME}",
+							Meta: map[string]string{
+								"traefik.http.routers.echo.entrypoints": "websecure",
+							},
+							CanaryMeta: canaryMeta,
+						},
+					},
+					Tasks: []*api.Task{
+						{
+							Name: "server",
+							Meta: map[string]string{
+								"traefik.nomad.router.middlewares": "auth@file",
+							},
+							Services: []*api.Service{
+								{
+									Meta: map[string]string{
+										"traefik.http.routers.echo.rule": "Host(`task.example.com`)",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc        string
+		alloc       *api.Allocation
+		serviceName string
+		expected    []string
+	}{
+		{
+			desc:        "no job",
+			alloc:       &api.Allocation{TaskGroup: "api"},
+			serviceName: "echo-api",
+		},
+		{
+			desc:        "group service overrides group and job",
+			alloc:       &api.Allocation{TaskGroup: "api", Job: job(nil)},
+			serviceName: "echo-api",
+			expected: []string{
+				"traefik.enable=true",
+				"traefik.http.routers.echo.entrypoints=websecure",
+				"traefik.http.routers.echo.rule=Host(`group.example.com`)",
+			},
+		},
+		{
+			desc:        "task service with default name and task meta",
+			alloc:       &api.Allocation{TaskGroup: "api", Job: job(nil)},
+			serviceName: "echo-api-server",
+			expected: []string{
+				"traefik.enable=true",
+				"traefik.http.routers.echo.entrypoints=web",
+				"traefik.http.routers.echo.rule=Host(`task.example.com`)",
+				"traefik.nomad.router.middlewares=auth@file",
+			},
+		},
+		{
+			desc:        "unknown service",
+			alloc:       &api.Allocation{TaskGroup: "api", Job: job(nil)},
+			serviceName: "other",
+			expected: []string{
+				"traefik.enable=true",
+				"traefik.http.routers.echo.entrypoints=web",
+				"traefik.http.routers.echo.rule=Host(`group.example.com`)",
+			},
+		},
+		{
+			desc: "canary meta",
+			alloc: &api.Allocation{
+				TaskGroup:        "api",
+				Job:              job(map[string]string{"traefik.http.routers.echo.entrypoints": "canary"}),
+				DeploymentStatus: &api.AllocDeploymentStatus{Canary: true},
+			},
+			serviceName: "echo-api",
+			expected: []string{
+				"traefik.enable=true",
+				"traefik.http.routers.echo.entrypoints=canary",
+				"traefik.http.routers.echo.rule=Host(`group.example.com`)",
+			},
+		},
+		{
+			desc: "canary without canary meta",
+			alloc: &api.Allocation{
+				TaskGroup:        "api",
+				Job:              job(nil),
+				DeploymentStatus: &api.AllocDeploymentStatus{Canary: true},
+			},
+			serviceName: "echo-api",
+			expected: []string{
+				"traefik.enable=true",
+				"traefik.http.routers.echo.entrypoints=websecure",
+				"traefik.http.routers.echo.rule=Host(`group.example.com`)",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, metaTags(test.alloc, test.serviceName, "traefik"))
+		})
+	}
+}
+
+func Test_metaTags_prefix(t *testing.T) {
+	alloc := &api.Allocation{
+		Job: &api.Job{
+			Meta: map[string]string{
+				"traefik.enable":      "true",
+				"ingress.enable":      "true",
+				"ingressfoo.enable":   "true",
+				"ingress.http.foo.x":  "y",
+				"other.ingress.thing": "z",
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"ingress.enable=true", "ingress.http.foo.x=y"}, metaTags(alloc, "echo", "ingress"))
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,72 @@
+package nomad
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/logs"
+	"github.com/traefik/traefik/v3/pkg/provider"
+)
+
+// mirror is the mirroring of the requests of a service to an item, usually a canary.
+type mirror struct {
+	Percent int    // <prefix>.nomad.mirror.percent is the corresponding label, defaults to 100.
+	Rule    string // <prefix>.nomad.mirror.rule is the corresponding label, empty when all the requests are mirrored.
+}
+
+// addMirror renames the HTTP services of an item mirroring the requests of the service of the same name,
+// and drops its routers: the item only receives the mirrored requests.
+func addMirror(i item, configuration *dynamic.HTTPConfiguration, mirrors map[string]dynamic.MirrorService) {
+	services := make(map[string]*dynamic.Service, len(configuration.Services))
+	for name, service := range configuration.Services {
+		// the default service of a canary has a name of its own, while it mirrors the service of the item name.
+		if name == getName(i) {
+			name = provider.Normalize(i.Name)
+		}
+
+		services[mirrorName(name)] = service
+
+		mirrors[name] = dynamic.MirrorService{
+			Name:    mirrorName(name),
+			Percent: i.ExtraConf.Mirror.Percent,
+			Rule:    i.ExtraConf.Mirror.Rule,
+		}
+	}
+
+	configuration.Services = services
+	configuration.Routers = nil
+}
+
+// buildMirroringServices builds, for each mirrored service, a mirroring service sending the requests to it,
+// and a copy of them to its mirror.
+// The routers keep referencing the service name, which becomes the mirroring service.
+func buildMirroringServices(ctx context.Context, configuration *dynamic.HTTPConfiguration, mirrors map[string]dynamic.MirrorService) {
+	for name, mirrorService := range mirrors {
+		logger := log.Ctx(ctx).With().Str(logs.ServiceName, name).Logger()
+
+		// the mirror may have been dropped by the merge, because of conflicting definitions.
+		if _, exists := configuration.Services[mirrorService.Name]; !exists {
+			continue
+		}
+
+		service, exists := configuration.Services[name]
+		if !exists {
+			logger.Debug().Msg("No service to mirror the requests of")
+			continue
+		}
+
+		primaryName := name + "-primary"
+		configuration.Services[primaryName] = service
+		configuration.Services[name] = &dynamic.Service{
+			Mirroring: &dynamic.Mirroring{
+				Service: primaryName,
+				Mirrors: []dynamic.MirrorService{mirrorService},
+			},
+		}
+	}
+}
+
+func mirrorName(name string) string {
+	return name + "-mirror"
+}
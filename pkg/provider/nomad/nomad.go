@@ -4,21 +4,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
-	"github.com/traefik/traefik/v3/pkg/job"
 	"github.com/traefik/traefik/v3/pkg/logs"
+	"github.com/traefik/traefik/v3/pkg/metrics"
 	"github.com/traefik/traefik/v3/pkg/provider"
 	"github.com/traefik/traefik/v3/pkg/provider/constraints"
 	"github.com/traefik/traefik/v3/pkg/safe"
-	"github.com/traefik/traefik/v3/pkg/types"
 )
 
 const (
@@ -31,19 +36,32 @@ const (
 	// defaultPrefix is the default prefix used in tag values indicating the service
 	// should be consumed and exposed via traefik.
 	defaultPrefix = "traefik"
+
+	// secureHeadersMiddlewareName is the name of the headers middleware attached to public routers.
+	secureHeadersMiddlewareName = "secure-headers"
+
+	// jobHeadersMiddlewarePrefix is the prefix of the name of the headers middleware propagating the job of the service.
+	jobHeadersMiddlewarePrefix = "nomad-job-headers-"
 )
 
 var _ provider.Provider = (*Provider)(nil)
 
 type item struct {
-	ID         string   // service ID
-	Name       string   // service name
-	Namespace  string   // service namespace
-	Node       string   // node ID
-	Datacenter string   // region
-	Address    string   // service address
-	Port       int      // service port
-	Tags       []string // service tags
+	ID          string         // service ID
+	Name        string         // service name
+	Namespace   string         // service namespace
+	Job         string         // job ID
+	Node        string         // node ID
+	NodeName    string         // node name, only set when referenced by the default rule
+	Datacenter  string         // region
+	AllocID     string         // allocation ID
+	JobVersion  *uint64        // allocation job version, only set when the job versions of the service are weighted
+	Utilization float64        // highest utilization of the allocation CPU and memory limits, in percent, only set with the resource pressure
+	Address     string         // service address
+	Port        int            // service port
+	Ports       map[string]int // allocation ports, indexed by label
+	Tags        []string       // service tags
+	Draining    bool           // whether the allocation is stopping, or the service deregistered
 
 	ExtraConf configuration // global options
 }
@@ -53,6 +71,25 @@ type item struct {
 type configuration struct {
 	Enable bool // <prefix>.enable is the corresponding label.
 	Canary bool // <prefix>.nomad.canary is the corresponding label.
+
+	SkipSecureHeaders bool // <prefix>.nomad.secureheaders=false is the corresponding label.
+
+	FailoverTier int // <prefix>.nomad.failoverTier is the corresponding label, zero when the service is not part of a failover.
+
+	// RouterMiddlewares and RouterEntryPoints are the defaults of the HTTP routers of the service,
+	// usually declared once in the meta block of a task registering several services.
+	RouterMiddlewares []string // <prefix>.nomad.router.middlewares is the corresponding label.
+	RouterEntryPoints []string // <prefix>.nomad.router.entrypoints is the corresponding label.
+
+	// MaxBodyBytes and RequestTimeout tighten the guardrails of the service, zero when not set.
+	MaxBodyBytes   int64           // <prefix>.nomad.maxbodybytes is the corresponding label.
+	RequestTimeout ptypes.Duration // <prefix>.nomad.requesttimeout is the corresponding label.
+
+	// Mirror is the mirroring of the requests of the service of the same name to the service, nil when it is routed.
+	Mirror *mirror
+
+	// VersionWeights are the weights of the job versions of the service, indexed by job version, nil when not weighted.
+	VersionWeights map[uint64]int // <prefix>.nomad.versionweights is the corresponding label.
 }
 
 // ProviderBuilder is responsible for constructing namespaced instances of the Nomad provider.
@@ -85,13 +122,35 @@ func (p *ProviderBuilder) BuildProviders() []*Provider {
 
 // Configuration represents the Nomad provider configuration.
 type Configuration struct {
-	DefaultRule      string          `description:"Default rule." json:"defaultRule,omitempty" toml:"defaultRule,omitempty" yaml:"defaultRule,omitempty"`
-	Constraints      string          `description:"Constraints is an expression that Traefik matches against the Nomad service's tags to determine whether to create route(s) for that service." json:"constraints,omitempty" toml:"constraints,omitempty" yaml:"constraints,omitempty" export:"true"`
-	Endpoint         *EndpointConfig `description:"Nomad endpoint settings" json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty" export:"true"`
-	Prefix           string          `description:"Prefix for nomad service tags." json:"prefix,omitempty" toml:"prefix,omitempty" yaml:"prefix,omitempty" export:"true"`
-	Stale            bool            `description:"Use stale consistency for catalog reads." json:"stale,omitempty" toml:"stale,omitempty" yaml:"stale,omitempty" export:"true"`
-	ExposedByDefault bool            `description:"Expose Nomad services by default." json:"exposedByDefault,omitempty" toml:"exposedByDefault,omitempty" yaml:"exposedByDefault,omitempty" export:"true"`
-	RefreshInterval  ptypes.Duration `description:"Interval for polling Nomad API." json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+	DefaultRule           string                      `description:"Default rule." json:"defaultRule,omitempty" toml:"defaultRule,omitempty" yaml:"defaultRule,omitempty"`
+	DefaultPathRule       string                      `description:"Default path prefix, routed with a PathPrefix rule and stripped before forwarding. Takes precedence over the default rule." json:"defaultPathRule,omitempty" toml:"defaultPathRule,omitempty" yaml:"defaultPathRule,omitempty"`
+	Constraints           string                      `description:"Constraints is an expression that Traefik matches against the Nomad service's tags to determine whether to create route(s) for that service." json:"constraints,omitempty" toml:"constraints,omitempty" yaml:"constraints,omitempty" export:"true"`
+	Endpoint              *EndpointConfig             `description:"Nomad endpoint settings" json:"endpoint,omitempty" toml:"endpoint,omitempty" yaml:"endpoint,omitempty" export:"true"`
+	Prefix                string                      `description:"Prefix for nomad service tags." json:"prefix,omitempty" toml:"prefix,omitempty" yaml:"prefix,omitempty" export:"true"`
+	Stale                 bool                        `description:"Use stale consistency for catalog reads." json:"stale,omitempty" toml:"stale,omitempty" yaml:"stale,omitempty" export:"true"`
+	Regions               []string                    `description:"Nomad regions to discover services in concurrently. If not provided, the endpoint region is used." json:"regions,omitempty" toml:"regions,omitempty" yaml:"regions,omitempty" export:"true"`
+	ExposedByDefault      bool                        `description:"Expose Nomad services by default." json:"exposedByDefault,omitempty" toml:"exposedByDefault,omitempty" yaml:"exposedByDefault,omitempty" export:"true"`
+	RefreshInterval       ptypes.Duration             `description:"Interval for polling Nomad API." json:"refreshInterval,omitempty" toml:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty" export:"true"`
+	Watch                 bool                        `description:"Watch the Nomad services with blocking queries, instead of polling at a fixed interval. The refresh interval is then the maximum wait of the queries." json:"watch,omitempty" toml:"watch,omitempty" yaml:"watch,omitempty" export:"true"`
+	ReconcileInterval     ptypes.Duration             `description:"Interval at which the services of the last loaded configuration are compared with a full listing of the Nomad API, their configuration being loaded again when they drifted. Disabled when zero." json:"reconcileInterval,omitempty" toml:"reconcileInterval,omitempty" yaml:"reconcileInterval,omitempty" export:"true"`
+	ThrottleDuration      ptypes.Duration             `description:"Minimum duration between two refreshes, the changes happening in between are coalesced into a single configuration." json:"throttleDuration,omitempty" toml:"throttleDuration,omitempty" yaml:"throttleDuration,omitempty" export:"true"`
+	SecureHeaders         *SecureHeaders              `description:"Attach a hardened headers middleware to routers bound to public entrypoints." json:"secureHeaders,omitempty" toml:"secureHeaders,omitempty" yaml:"secureHeaders,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	JobHeaders            bool                        `description:"Attach a headers middleware adding the X-Nomad-Job and X-Nomad-Namespace headers to the requests forwarded to the services." json:"jobHeaders,omitempty" toml:"jobHeaders,omitempty" yaml:"jobHeaders,omitempty" export:"true"`
+	DNSFallback           *DNSFallback                `description:"Resolve critical services through DNS SRV records when the Nomad API is unavailable." json:"dnsFallback,omitempty" toml:"dnsFallback,omitempty" yaml:"dnsFallback,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	ConsulServices        bool                        `description:"Also discover the services of the Nomad jobs registered in Consul, from the allocations of the jobs." json:"consulServices,omitempty" toml:"consulServices,omitempty" yaml:"consulServices,omitempty" export:"true"`
+	DrainTimeout          ptypes.Duration             `description:"Duration during which the servers of stopping allocations only receive the requests bound to them by a sticky cookie, before being removed. Disabled when zero." json:"drainTimeout,omitempty" toml:"drainTimeout,omitempty" yaml:"drainTimeout,omitempty" export:"true"`
+	DefaultRoutingOnError bool                        `description:"Route the services whose tags are invalid with the default rule, instead of dropping them." json:"defaultRoutingOnError,omitempty" toml:"defaultRoutingOnError,omitempty" yaml:"defaultRoutingOnError,omitempty" export:"true"`
+	UseMeta               bool                        `description:"Read the Traefik configuration from the meta blocks of the jobs, task groups, tasks and services, in addition to the service tags." json:"useMeta,omitempty" toml:"useMeta,omitempty" yaml:"useMeta,omitempty" export:"true"`
+	NamespacePolicies     map[string]*NamespacePolicy `description:"Entrypoints and middlewares the routers of a Nomad namespace are allowed to use, indexed by namespace. The namespaces without a policy are not restricted." json:"namespacePolicies,omitempty" toml:"namespacePolicies,omitempty" yaml:"namespacePolicies,omitempty" export:"true"`
+	Locality              *Locality                   `description:"Prefer the servers of the HTTP services located in the datacenter of the Traefik instance." json:"locality,omitempty" toml:"locality,omitempty" yaml:"locality,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	JobSelector           string                      `description:"Glob matched against the job IDs, only the services of the matching jobs are discovered." json:"jobSelector,omitempty" toml:"jobSelector,omitempty" yaml:"jobSelector,omitempty" export:"true"`
+	JobTypes              []string                    `description:"Types of the jobs whose services are discovered (service, system, batch, sysbatch). All the types are discovered when empty." json:"jobTypes,omitempty" toml:"jobTypes,omitempty" yaml:"jobTypes,omitempty" export:"true"`
+	TagsSignature         *TagsSignature              `description:"Only route the services whose Traefik tags are signed with the key, in the sig tag." json:"tagsSignature,omitempty" toml:"tagsSignature,omitempty" yaml:"tagsSignature,omitempty" export:"true"`
+	JobMetaConstraint     string                      `description:"Constraint on the meta of the jobs, as key == value or key != value, only the services of the matching jobs are discovered." json:"jobMetaConstraint,omitempty" toml:"jobMetaConstraint,omitempty" yaml:"jobMetaConstraint,omitempty" export:"true"`
+	Guardrails            *Guardrails                 `description:"Limits of the requests forwarded to the services, which the services can tighten but not loosen." json:"guardrails,omitempty" toml:"guardrails,omitempty" yaml:"guardrails,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	ResourcePressure      *ResourcePressure           `description:"Reduce the weight of the HTTP servers whose allocations are near their CPU or memory limits, after the stats of the Nomad clients." json:"resourcePressure,omitempty" toml:"resourcePressure,omitempty" yaml:"resourcePressure,omitempty" label:"allowEmpty" file:"allowEmpty" export:"true"`
+	EmptyRefreshThreshold int                         `description:"Number of consecutive refreshes discovering no service before the routes of the previously discovered services are removed, unless the Nomad API reports their deregistration." json:"emptyRefreshThreshold,omitempty" toml:"emptyRefreshThreshold,omitempty" yaml:"emptyRefreshThreshold,omitempty" export:"true"`
+	AllowEmptyRefresh     bool                        `description:"Remove the routes of the previously discovered services on the first refresh discovering no service." json:"allowEmptyRefresh,omitempty" toml:"allowEmptyRefresh,omitempty" yaml:"allowEmptyRefresh,omitempty" export:"true"`
 }
 
 // SetDefaults sets the default values for the Nomad Traefik Provider Configuration.
@@ -103,11 +162,15 @@ func (c *Configuration) SetDefaults() {
 		Token:   defConfig.SecretID,
 	}
 
-	if defConfig.TLSConfig != nil && (defConfig.TLSConfig.Insecure || defConfig.TLSConfig.CACert != "" || defConfig.TLSConfig.ClientCert != "" || defConfig.TLSConfig.ClientKey != "") {
-		c.Endpoint.TLS = &types.ClientTLS{
+	c.Endpoint.Transport = &EndpointTransport{}
+	c.Endpoint.Transport.SetDefaults()
+
+	if defConfig.TLSConfig != nil && (defConfig.TLSConfig.Insecure || defConfig.TLSConfig.CACert != "" || defConfig.TLSConfig.ClientCert != "" || defConfig.TLSConfig.ClientKey != "" || defConfig.TLSConfig.TLSServerName != "") {
+		c.Endpoint.TLS = &EndpointTLS{
 			CA:                 defConfig.TLSConfig.CACert,
 			Cert:               defConfig.TLSConfig.ClientCert,
 			Key:                defConfig.TLSConfig.ClientKey,
+			ServerName:         defConfig.TLSConfig.TLSServerName,
 			InsecureSkipVerify: defConfig.TLSConfig.Insecure,
 		}
 	}
@@ -118,15 +181,62 @@ func (c *Configuration) SetDefaults() {
 	c.DefaultRule = defaultTemplateRule
 }
 
+// SecureHeaders holds the configuration of the headers middleware
+// automatically attached to the routers bound to public entrypoints.
+type SecureHeaders struct {
+	EntryPoints          []string `description:"Public entrypoints, routers bound to these get the secure headers middleware." json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
+	STSSeconds           int64    `description:"Max-age of the Strict-Transport-Security header." json:"stsSeconds,omitempty" toml:"stsSeconds,omitempty" yaml:"stsSeconds,omitempty" export:"true"`
+	STSIncludeSubdomains bool     `description:"Append the includeSubDomains directive to the Strict-Transport-Security header." json:"stsIncludeSubdomains,omitempty" toml:"stsIncludeSubdomains,omitempty" yaml:"stsIncludeSubdomains,omitempty" export:"true"`
+	FrameDeny            bool     `description:"Add the X-Frame-Options header with the DENY value." json:"frameDeny,omitempty" toml:"frameDeny,omitempty" yaml:"frameDeny,omitempty" export:"true"`
+	ContentTypeNosniff   bool     `description:"Add the X-Content-Type-Options header with the nosniff value." json:"contentTypeNosniff,omitempty" toml:"contentTypeNosniff,omitempty" yaml:"contentTypeNosniff,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values of the secure headers.
+func (s *SecureHeaders) SetDefaults() {
+	s.STSSeconds = 31536000
+	s.STSIncludeSubdomains = true
+	s.FrameDeny = true
+	s.ContentTypeNosniff = true
+}
+
 type EndpointConfig struct {
 	// Address is the Nomad endpoint address, if empty it defaults to NOMAD_ADDR or "http://127.0.0.1:4646".
 	Address string `description:"The address of the Nomad server, including scheme and port." json:"address,omitempty" toml:"address,omitempty" yaml:"address,omitempty"`
 	// Region is the Nomad region, if empty it defaults to NOMAD_REGION.
 	Region string `description:"Nomad region to use. If not provided, the local agent region is used." json:"region,omitempty" toml:"region,omitempty" yaml:"region,omitempty"`
 	// Token is the ACL token to connect with Nomad, if empty it defaults to NOMAD_TOKEN.
-	Token            string           `description:"Token is used to provide a per-request ACL token." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" loggable:"false"`
-	TLS              *types.ClientTLS `description:"Configure TLS." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
-	EndpointWaitTime ptypes.Duration  `description:"WaitTime limits how long a Watch will block. If not provided, the agent default values will be used" json:"endpointWaitTime,omitempty" toml:"endpointWaitTime,omitempty" yaml:"endpointWaitTime,omitempty" export:"true"`
+	Token string `description:"Token is used to provide a per-request ACL token." json:"token,omitempty" toml:"token,omitempty" yaml:"token,omitempty" loggable:"false"`
+	// TokenFile is the path to a file containing the ACL token, it takes precedence over Token.
+	// The file is read again before each refresh, so that the token can be rotated without restarting.
+	TokenFile        string             `description:"Path to a file containing the ACL token, read again before each refresh to support token rotation." json:"tokenFile,omitempty" toml:"tokenFile,omitempty" yaml:"tokenFile,omitempty"`
+	WorkloadIdentity bool               `description:"Use the workload identity token of the Nomad task running Traefik as the ACL token." json:"workloadIdentity,omitempty" toml:"workloadIdentity,omitempty" yaml:"workloadIdentity,omitempty" export:"true"`
+	TLS              *EndpointTLS       `description:"Configure TLS." json:"tls,omitempty" toml:"tls,omitempty" yaml:"tls,omitempty" export:"true"`
+	Transport        *EndpointTransport `description:"Configure the HTTP transport." json:"transport,omitempty" toml:"transport,omitempty" yaml:"transport,omitempty" export:"true"`
+	EndpointWaitTime ptypes.Duration    `description:"WaitTime limits how long a Watch will block. If not provided, the agent default values will be used" json:"endpointWaitTime,omitempty" toml:"endpointWaitTime,omitempty" yaml:"endpointWaitTime,omitempty" export:"true"`
+}
+
+// token returns the ACL token to connect with Nomad.
+func (e *EndpointConfig) token() (string, error) {
+	path := e.tokenPath()
+	if path == "" {
+		return e.Token, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading token file: %w", err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// EndpointTLS holds the TLS configuration of the Nomad API client.
+type EndpointTLS struct {
+	CA                 string `description:"TLS CA" json:"ca,omitempty" toml:"ca,omitempty" yaml:"ca,omitempty"`
+	Cert               string `description:"TLS cert" json:"cert,omitempty" toml:"cert,omitempty" yaml:"cert,omitempty"`
+	Key                string `description:"TLS key" json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty" loggable:"false"`
+	ServerName         string `description:"TLS server name, used to verify the Nomad server certificate (e.g. server.global.nomad)" json:"serverName,omitempty" toml:"serverName,omitempty" yaml:"serverName,omitempty"`
+	InsecureSkipVerify bool   `description:"TLS insecure skip verify" json:"insecureSkipVerify,omitempty" toml:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" export:"true"`
 }
 
 // Provider holds configuration along with the namespace it will discover services in.
@@ -135,8 +245,54 @@ type Provider struct {
 
 	name           string
 	namespace      string
-	client         *api.Client        // client for Nomad API
-	defaultRuleTpl *template.Template // default routing rule
+	client         *api.Client            // client for Nomad API
+	regionClients  map[string]*api.Client // clients for Nomad API of the configured regions, indexed by region
+	defaultRuleTpl *template.Template     // default routing rule
+	defaultPathTpl *template.Template     // default path prefix, nil when the default path rule is not used
+	needNodeName   bool                   // whether the default rule references the node name
+	jobMeta        *jobMetaConstraint     // constraint on the meta of the jobs, nil when not configured
+
+	token string // ACL token in use, tracked to detect the token file rotations
+
+	dnsResolver dnsResolver         // resolver used by the DNS fallback
+	lastTags    map[string][]string // last tags of the DNS fallback services, indexed by service name
+	knownItems  []item              // items of the last loaded configuration, kept by the DNS fallback
+
+	lastItems   map[string]item         // items of the last refresh, indexed by service ID
+	lastDigest  string                  // digest of the items of the last loaded configuration, compared by the reconciliations
+	regionItems map[string][]item       // items of the last successful refresh of each region, indexed by region
+	draining    map[string]drainingItem // items within their drain window, indexed by service ID
+
+	emptyRefreshes int                    // number of consecutive refreshes which discovered no service
+	heldItems      []item                 // items of the last refresh which discovered services, kept while the refreshes discover none
+	heldIndexes    map[*api.Client]uint64 // indexes of the services of the last refresh which discovered services, indexed by client
+
+	configErrorsMu sync.RWMutex
+	configErrors   []ConfigurationError // configuration errors of the last refresh, exposed by the API
+
+	instancesMu sync.RWMutex
+	instances   map[string]Instance // instances backing the HTTP servers of the last refresh, indexed by server URL, exposed by the API
+
+	discoveredMu sync.RWMutex
+	discovered   []DiscoveredService // service instances of the last refresh, exposed by the API
+
+	statusMu sync.RWMutex
+	status   Status // state of the synchronization with the Nomad API, exposed by the diagnostics
+
+	metricsRegistry metrics.Registry // registry of the discovery and configuration metrics, nil when not set
+	httpClient      *http.Client     // HTTP client shared by the Nomad API clients, nil until the provider is started
+
+	indexesMu sync.Mutex
+	indexes   map[*api.Client]uint64 // index of the services of the last refresh, indexed by client, used by the watch mode
+
+	lintsMu sync.RWMutex
+	lints   map[*api.Client][]TagLint // tags with an unknown key of the last refresh, indexed by client, exposed by the API
+}
+
+// SetMetricsRegistry sets the registry of the metrics reporting the health of the discovery,
+// and of the configuration of the discovered services, it must be called before the provider is started.
+func (p *Provider) SetMetricsRegistry(registry metrics.Registry) {
+	p.metricsRegistry = registry
 }
 
 // SetDefaults sets the default values for the Nomad Traefik Provider.
@@ -150,12 +306,71 @@ func (p *Provider) Init() error {
 		return errors.New("wildcard namespace not supported")
 	}
 
-	defaultRuleTpl, err := provider.MakeDefaultRuleTemplate(p.DefaultRule, nil)
+	defaultRule := p.DefaultRule
+	if p.DefaultPathRule != "" {
+		defaultPathTpl, err := provider.MakeDefaultRuleTemplate(p.DefaultPathRule, nil)
+		if err != nil {
+			return fmt.Errorf("error while parsing default path rule: %w", err)
+		}
+		p.defaultPathTpl = defaultPathTpl
+
+		defaultRule = "PathPrefix(`" + p.DefaultPathRule + "`)"
+	}
+
+	defaultRuleTpl, err := provider.MakeDefaultRuleTemplate(defaultRule, nil)
 	if err != nil {
 		return fmt.Errorf("error while parsing default rule: %w", err)
 	}
 	p.defaultRuleTpl = defaultRuleTpl
 
+	// the node name is not part of the service registrations,
+	// it is only looked up in the allocations when the default rule needs it.
+	p.needNodeName = strings.Contains(defaultRule, ".NodeName")
+
+	if err := p.validateJobFilters(); err != nil {
+		return err
+	}
+
+	jobMeta, err := parseJobMetaConstraint(p.JobMetaConstraint)
+	if err != nil {
+		return err
+	}
+	p.jobMeta = jobMeta
+
+	if p.Locality != nil {
+		if err := p.Locality.init(); err != nil {
+			return fmt.Errorf("invalid locality: %w", err)
+		}
+	}
+
+	if p.Guardrails != nil {
+		if err := p.Guardrails.init(); err != nil {
+			return fmt.Errorf("invalid guardrails: %w", err)
+		}
+	}
+
+	if p.ResourcePressure != nil {
+		if err := p.ResourcePressure.init(); err != nil {
+			return fmt.Errorf("invalid resource pressure: %w", err)
+		}
+	}
+
+	if p.Endpoint != nil && p.Endpoint.WorkloadIdentity && p.Endpoint.TokenFile == "" && os.Getenv("NOMAD_SECRETS_DIR") == "" {
+		return errors.New("workload identity requires Traefik to run as a Nomad task: NOMAD_SECRETS_DIR is not set")
+	}
+
+	if p.TagsSignature != nil {
+		if err := p.TagsSignature.init(); err != nil {
+			return fmt.Errorf("invalid tags signature: %w", err)
+		}
+	}
+
+	p.lastTags = make(map[string][]string)
+	p.lastItems = make(map[string]item)
+	p.regionItems = make(map[string][]item)
+	p.draining = make(map[string]drainingItem)
+	p.indexes = make(map[*api.Client]uint64)
+
 	// In case they didn't initialize Provider with BuildProviders
 	if p.name == "" {
 		p.name = providerName
@@ -168,15 +383,48 @@ func (p *Provider) Init() error {
 // using the given configuration channel.
 func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.Pool) error {
 	var err error
-	p.client, err = createClient(p.namespace, p.Endpoint)
+	p.httpClient, err = p.newHTTPClient()
+	if err != nil {
+		return fmt.Errorf("failed to create nomad HTTP client: %w", err)
+	}
+
+	p.client, err = createClient(p.namespace, p.Endpoint, p.httpClient)
 	if err != nil {
 		return fmt.Errorf("failed to create nomad API client: %w", err)
 	}
 
+	p.token, err = p.Endpoint.token()
+	if err != nil {
+		return fmt.Errorf("failed to read nomad ACL token: %w", err)
+	}
+
+	if len(p.Regions) > 0 {
+		p.regionClients = make(map[string]*api.Client, len(p.Regions))
+		for _, region := range p.Regions {
+			endpoint := *p.Endpoint
+			endpoint.Region = region
+
+			p.regionClients[region], err = createClient(p.namespace, &endpoint, p.httpClient)
+			if err != nil {
+				return fmt.Errorf("failed to create nomad API client for region %s: %w", region, err)
+			}
+		}
+	}
+
+	tokenChanged, err := p.watchTokenFile(pool)
+	if err != nil {
+		return fmt.Errorf("failed to watch nomad ACL token file: %w", err)
+	}
+
 	pool.GoCtx(func(routineCtx context.Context) {
 		logger := log.Ctx(routineCtx).With().Str(logs.ProviderName, p.name).Logger()
 		ctxLog := logger.WithContext(routineCtx)
 
+		// The reconnection delay grows until the Nomad API is reachable again, and is only reset by a successful load:
+		// while the API is unreachable, the last known configuration is kept, as no new configuration is sent.
+		reconnect := backoff.NewExponentialBackOff()
+		reconnect.MaxElapsedTime = 0
+
 		operation := func() error {
 			ctx, cancel := context.WithCancel(ctxLog)
 			defer cancel()
@@ -185,33 +433,70 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 			if err := p.loadConfiguration(ctx, configurationChan); err != nil {
 				return fmt.Errorf("failed to load initial nomad services: %w", err)
 			}
+			lastLoad := time.Now()
+			reconnect.Reset()
 
-			// issue periodic refreshes in the background
-			// (Nomad does not support Watch style observations)
+			// issue periodic refreshes in the background,
+			// or refreshes on changes when watching the services with blocking queries.
 			ticker := time.NewTicker(time.Duration(p.RefreshInterval))
 			defer ticker.Stop()
 
 			// enter loop where we wait for and respond to notifications
 			for {
-				select {
-				case <-ctx.Done():
+				// a change of the token file wakes the loop up, to rotate the token right away.
+				waitCtx, cancelWait := waitTokenChange(ctx, tokenChanged)
+				var reconcile bool
+				if p.Watch {
+					reconcile = p.watchServicesUntil(waitCtx, p.nextReconcile(lastLoad))
+				} else {
+					reconcileC, stopReconcile := reconcileTimer(p.nextReconcile(lastLoad))
+					select {
+					case <-waitCtx.Done():
+					case <-ticker.C:
+					case <-reconcileC:
+						reconcile = true
+					}
+					stopReconcile()
+				}
+				cancelWait()
+
+				// the changes happening within the throttle window, e.g. during a deployment, are loaded at once.
+				waitContext(ctx, time.Until(lastLoad.Add(time.Duration(p.ThrottleDuration))))
+
+				if ctx.Err() != nil {
 					return nil
-				case <-ticker.C:
 				}
+
+				if reconcile {
+					if err := p.reconcile(ctx, configurationChan); err != nil {
+						return fmt.Errorf("failed to reconcile nomad services: %w", err)
+					}
+					lastLoad = time.Now()
+					continue
+				}
+
 				// load services due to refresh
 				if err := p.loadConfiguration(ctx, configurationChan); err != nil {
 					return fmt.Errorf("failed to refresh nomad services: %w", err)
 				}
+				lastLoad = time.Now()
 			}
 		}
 
 		failure := func(err error, d time.Duration) {
-			logger.Error().Err(err).Msgf("Provider connection error, retrying in %s", d)
+			logger.Error().Err(err).Msgf("Provider connection error, keeping the last known configuration, retrying in %s", d)
+
+			if p.metricsRegistry != nil {
+				p.metricsRegistry.ProviderAPIRetriesCounter().With("provider", p.name).Add(1)
+			}
+
+			// keep the critical services routed while the Nomad API is unavailable
+			p.loadDNSConfiguration(ctxLog, configurationChan)
 		}
 
 		if retryErr := backoff.RetryNotify(
 			safe.OperationWithRecover(operation),
-			backoff.WithContext(job.NewBackOff(backoff.NewExponentialBackOff()), ctxLog),
+			backoff.WithContext(reconnect, ctxLog),
 			failure,
 		); retryErr != nil {
 			logger.Error().Err(retryErr).Msg("Cannot connect to Nomad server")
@@ -222,75 +507,325 @@ func (p *Provider) Provide(configurationChan chan<- dynamic.Message, pool *safe.
 }
 
 func (p *Provider) loadConfiguration(ctx context.Context, configurationC chan<- dynamic.Message) error {
+	p.rotateToken(ctx)
+
 	items, err := p.getNomadServiceData(ctx)
 	if err != nil {
+		p.recordSync(err)
 		return err
 	}
+
+	p.applyConfiguration(ctx, configurationC, p.holdEmpty(ctx, items))
+	return nil
+}
+
+// applyConfiguration sends the configuration built from the items, along with the draining ones.
+func (p *Provider) applyConfiguration(ctx context.Context, configurationC chan<- dynamic.Message, items []item) {
+	p.lastDigest = itemsDigest(items)
+	p.knownItems = items
+	p.recordTags(items)
+
+	items = p.drain(ctx, items, time.Now())
+
 	configurationC <- dynamic.Message{
 		ProviderName:  p.name,
 		Configuration: p.buildConfig(ctx, items),
 	}
 
-	return nil
+	p.recordSync(nil)
+}
+
+// rotateToken reads the token file again, and updates the clients when the token has changed.
+// The previous token is kept when the file cannot be read, or is empty as while it is being written.
+func (p *Provider) rotateToken(ctx context.Context) {
+	if p.Endpoint.tokenPath() == "" {
+		return
+	}
+
+	token, err := p.Endpoint.token()
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Unable to rotate the Nomad ACL token")
+		return
+	}
+
+	if token == "" || token == p.token {
+		return
+	}
+
+	p.client.SetSecretID(token)
+	for _, client := range p.regionClients {
+		client.SetSecretID(token)
+	}
+	p.token = token
+
+	log.Ctx(ctx).Info().Msg("Nomad ACL token rotated")
 }
 
 func (p *Provider) getNomadServiceData(ctx context.Context) ([]item, error) {
+	if len(p.regionClients) == 0 {
+		return p.getRegionServiceData(ctx, p.client)
+	}
+
+	type result struct {
+		region string
+		items  []item
+		err    error
+	}
+
+	results := make(chan result, len(p.regionClients))
+	for region, client := range p.regionClients {
+		region, client := region, client
+		go func() {
+			logger := log.Ctx(ctx).With().Str("region", region).Logger()
+			items, err := p.getRegionServiceData(logger.WithContext(ctx), client)
+			results <- result{region: region, items: items, err: err}
+		}()
+	}
+
+	var items []item
+	var errs []error
+	for range p.regionClients {
+		res := <-results
+		if res.err != nil {
+			log.Ctx(ctx).Error().Err(res.err).Str("region", res.region).
+				Msgf("Failed to discover Nomad services, keeping the %d last known instance(s) of the region", len(p.regionItems[res.region]))
+			errs = append(errs, fmt.Errorf("region %s: %w", res.region, res.err))
+			items = append(items, p.regionItems[res.region]...)
+			continue
+		}
+		p.regionItems[res.region] = res.items
+		items = append(items, res.items...)
+	}
+
+	// Only fail when no region could be reached,
+	// so that an unavailable region does not take the routes of the others down,
+	// its last known instances being routed until it is reachable again.
+	if len(errs) == len(p.regionClients) {
+		return nil, errors.Join(errs...)
+	}
+
+	// Sort the items to build a configuration which does not depend on the order of the responses.
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].ID < items[j].ID
+	})
+
+	return items, nil
+}
+
+// getRegionServiceData returns the items of the services registered in the region of the given client.
+func (p *Provider) getRegionServiceData(ctx context.Context, client *api.Client) ([]item, error) {
 	// first, get list of service stubs
 	opts := &api.QueryOptions{AllowStale: p.Stale}
 	opts = opts.WithContext(ctx)
 
-	stubs, _, err := p.client.Services().List(opts)
+	stubs, meta, err := client.Services().List(opts)
 	if err != nil {
+		p.setLastIndex(client, 0)
 		return nil, err
 	}
+	p.setLastIndex(client, meta.LastIndex)
 
 	var items []item
 
+	// allocations are only fetched when a port label or the node name is referenced,
+	// and are shared by all the services registered by the same allocation.
+	allocs := make(map[string]*api.Allocation)
+
+	// checks are only fetched for UDP services, which have no other failure signal.
+	checks := make(map[string][]checkResult)
+
+	// utilization of the allocations, only fetched with the resource pressure, indexed by allocation ID.
+	utilizations := make(map[string]float64)
+
+	// jobs of the services, only fetched when the jobs are filtered by type or meta.
+	jobs := p.newJobsCache(ctx, client)
+
+	var stopping map[string]struct{}
+	if p.DrainTimeout > 0 {
+		stopping, err = p.getStoppingAllocations(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// the services registered in Consul are not part of the Nomad API, their instances are built from the allocations.
+	var consulInstances map[*api.ServiceRegistrationStub][]*api.ServiceRegistration
+	if p.ConsulServices {
+		consulStubs, instances, err := p.getConsulServices(ctx, client, allocs)
+		if err != nil {
+			return nil, err
+		}
+
+		stubs = append(stubs, consulStubs...)
+		consulInstances = instances
+	}
+
+	// the tags of all the listed services are linted, as a typo in the prefix, e.g. in the enable tag, filters the service out.
+	var lints []TagLint
+
 	for _, stub := range stubs {
 		for _, service := range stub.Services {
-			logger := log.Ctx(ctx).With().Str("serviceName", service.ServiceName).Logger()
+			lints = append(lints, lintTags(stub.Namespace, service.ServiceName, service.Tags, p.Prefix)...)
 
-			extraConf := p.getExtraConf(service.Tags)
-			if !extraConf.Enable {
-				logger.Debug().Msg("Filter Nomad service that is not enabled")
-				continue
-			}
+			logger := log.Ctx(ctx).With().Str("serviceName", service.ServiceName).Logger()
 
-			matches, err := constraints.MatchTags(service.Tags, p.Constraints)
-			if err != nil {
-				logger.Error().Err(err).Msg("Error matching constraint expressions")
+			// the tags of the meta blocks are only known once the allocations are fetched,
+			// the services are then filtered when building the configuration.
+			if !p.UseMeta && !p.keepService(logger, service.Tags) {
 				continue
 			}
 
-			if !matches {
-				logger.Debug().Msgf("Filter Nomad service not matching constraints: %q", p.Constraints)
-				continue
+			instances, ok := consulInstances[service]
+			if !ok {
+				instances, err = p.fetchService(ctx, client, service.ServiceName)
+				if err != nil {
+					return nil, err
+				}
 			}
 
-			instances, err := p.fetchService(ctx, service.ServiceName)
-			if err != nil {
-				return nil, err
-			}
+			// the job versions are only fetched when the instances of a job version weight them,
+			// for all the instances of the service.
+			versionWeighted := p.UseMeta || hasVersionWeights(instances, p.Prefix)
 
 			for _, i := range instances {
+				keep, err := p.keepJob(ctx, client, jobs, i.JobID)
+				if err != nil {
+					return nil, err
+				}
+				if !keep {
+					logger.Debug().Str("job", i.JobID).Msg("Filter Nomad service of a job not matching the job selector, types or meta constraint")
+					continue
+				}
+
+				var alloc *api.Allocation
+
+				// fetchAllocation fetches the allocation of the instance once,
+				// and reports whether it succeeded, the instance being skipped otherwise,
+				// e.g. when the allocation is garbage collected before its services are deregistered.
+				fetchAllocation := func() bool {
+					if alloc != nil {
+						return true
+					}
+
+					alloc, err = p.getAllocation(ctx, client, allocs, i.AllocID)
+					if err != nil {
+						logger.Warn().Err(err).Str("allocID", i.AllocID).Msg("Skipping the service instance")
+						return false
+					}
+
+					return true
+				}
+
+				tags := i.Tags
+				if p.UseMeta {
+					if !fetchAllocation() {
+						continue
+					}
+
+					// the tags of the service override the ones of the meta blocks.
+					tags = append(metaTags(alloc, i.ServiceName, p.Prefix), i.Tags...)
+				}
+
+				labels := tagsToLabels(tags, p.Prefix)
+
+				if hasUDPLabels(labels) {
+					allocChecks, err := p.getAllocationChecks(ctx, client, checks, i.AllocID)
+					if err != nil {
+						// the checks endpoint is not available before Nomad 1.4, the instance is kept.
+						logger.Debug().Err(err).Msg("Unable to fetch the checks of the UDP service instance")
+					} else if check := failingCheck(allocChecks, i.ServiceName); check != "" {
+						logger.Debug().Str("allocID", i.AllocID).Str("check", check).Msg("Filter UDP service instance with a failing check")
+						continue
+					}
+				}
+
+				var jobVersion *uint64
+				if versionWeighted {
+					if !fetchAllocation() {
+						continue
+					}
+
+					if alloc.Job != nil {
+						jobVersion = alloc.Job.Version
+					}
+				}
+
+				var utilization float64
+				if p.ResourcePressure != nil {
+					if !fetchAllocation() {
+						continue
+					}
+
+					utilization, err = p.getAllocationUtilization(ctx, client, utilizations, alloc)
+					if err != nil {
+						// the servers of the instance keep their full weight, e.g. when its Nomad client is unreachable.
+						logger.Debug().Err(err).Msg("Unable to fetch the utilization of the service instance")
+					}
+				}
+
+				var ports map[string]int
+				var nodeName string
+				if portLabel := hasPortLabel(labels); portLabel || p.needNodeName {
+					if !fetchAllocation() {
+						continue
+					}
+
+					if portLabel {
+						ports = allocationPorts(alloc)
+					}
+					nodeName = alloc.NodeName
+				}
+
+				_, draining := stopping[i.AllocID]
+
 				items = append(items, item{
-					ID:         i.ID,
-					Name:       i.ServiceName,
-					Namespace:  i.Namespace,
-					Node:       i.NodeID,
-					Datacenter: i.Datacenter,
-					Address:    i.Address,
-					Port:       i.Port,
-					Tags:       i.Tags,
-					ExtraConf:  p.getExtraConf(i.Tags),
+					ID:          i.ID,
+					Name:        i.ServiceName,
+					Namespace:   i.Namespace,
+					Job:         i.JobID,
+					Node:        i.NodeID,
+					NodeName:    nodeName,
+					Datacenter:  i.Datacenter,
+					AllocID:     i.AllocID,
+					JobVersion:  jobVersion,
+					Utilization: utilization,
+					Address:     i.Address,
+					Port:        i.Port,
+					Ports:       ports,
+					Tags:        tags,
+					Draining:    draining,
+					ExtraConf:   p.getExtraConf(tags),
 				})
 			}
 		}
 	}
 
+	p.setTagLints(ctx, client, lints)
+
 	return items, nil
 }
 
+// keepService reports whether the service is enabled and matches the constraints, according to its tags.
+func (p *Provider) keepService(logger zerolog.Logger, tags []string) bool {
+	if !p.getExtraConf(tags).Enable {
+		logger.Debug().Msg("Filter Nomad service that is not enabled")
+		return false
+	}
+
+	matches, err := constraints.MatchTags(tags, p.Constraints)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error matching constraint expressions")
+		return false
+	}
+
+	if !matches {
+		logger.Debug().Msgf("Filter Nomad service not matching constraints: %q", p.Constraints)
+		return false
+	}
+
+	return true
+}
+
 // getExtraConf returns a configuration with settings which are not part of the dynamic configuration (e.g. "<prefix>.enable").
 func (p *Provider) getExtraConf(tags []string) configuration {
 	labels := tagsToLabels(tags, p.Prefix)
@@ -305,14 +840,70 @@ func (p *Provider) getExtraConf(tags []string) configuration {
 		canary = strings.EqualFold(v, "true")
 	}
 
-	return configuration{Enable: enabled, Canary: canary}
+	var skipSecureHeaders bool
+	if v, exists := labels["traefik.nomad.secureheaders"]; exists {
+		skipSecureHeaders = strings.EqualFold(v, "false")
+	}
+
+	var failoverTier int
+	if v, exists := labels["traefik.nomad.failoverTier"]; exists {
+		// invalid tiers are ignored, the service is then not part of a failover.
+		if tier, err := strconv.Atoi(v); err == nil && tier > 0 {
+			failoverTier = tier
+		}
+	}
+
+	var maxBodyBytes int64
+	if v, exists := labels["traefik.nomad.maxbodybytes"]; exists {
+		// invalid limits are ignored, the service then gets the platform-wide limit.
+		if size, err := strconv.ParseInt(v, 10, 64); err == nil && size > 0 {
+			maxBodyBytes = size
+		}
+	}
+
+	var requestTimeout ptypes.Duration
+	if v, exists := labels["traefik.nomad.requesttimeout"]; exists {
+		var timeout ptypes.Duration
+		if err := timeout.Set(v); err == nil && timeout > 0 {
+			requestTimeout = timeout
+		}
+	}
+
+	var mirrorConf *mirror
+	if v, exists := labels["traefik.nomad.mirror.percent"]; exists || labels["traefik.nomad.mirror.rule"] != "" {
+		mirrorConf = &mirror{Percent: 100, Rule: labels["traefik.nomad.mirror.rule"]}
+
+		// invalid percentages are ignored, all the matching requests are then mirrored.
+		if percent, err := strconv.Atoi(v); err == nil && percent >= 0 && percent <= 100 {
+			mirrorConf.Percent = percent
+		}
+	}
+
+	var versionWeights map[uint64]int
+	if v, exists := labels["traefik.nomad.versionweights"]; exists {
+		versionWeights = parseVersionWeights(v)
+	}
+
+	return configuration{
+		Enable:            enabled,
+		Canary:            canary,
+		SkipSecureHeaders: skipSecureHeaders,
+		FailoverTier:      failoverTier,
+		RouterMiddlewares: splitList(labels["traefik.nomad.router.middlewares"]),
+		RouterEntryPoints: splitList(labels["traefik.nomad.router.entrypoints"]),
+		MaxBodyBytes:      maxBodyBytes,
+		RequestTimeout:    requestTimeout,
+		Mirror:            mirrorConf,
+		VersionWeights:    versionWeights,
+	}
 }
 
 // fetchService queries Nomad API for services matching name,
 // that also have the  <prefix>.enable=true set in its tags.
-func (p *Provider) fetchService(ctx context.Context, name string) ([]*api.ServiceRegistration, error) {
+func (p *Provider) fetchService(ctx context.Context, client *api.Client, name string) ([]*api.ServiceRegistration, error) {
 	var tagFilter string
-	if !p.ExposedByDefault {
+	// the enable tag can also be set in the meta blocks, which are not part of the service registrations.
+	if !p.ExposedByDefault && !p.UseMeta {
 		tagFilter = fmt.Sprintf(`Tags contains %q`, fmt.Sprintf("%s.enable=true", p.Prefix))
 	}
 
@@ -322,28 +913,136 @@ func (p *Provider) fetchService(ctx context.Context, name string) ([]*api.Servic
 	opts := &api.QueryOptions{AllowStale: p.Stale, Filter: tagFilter}
 	opts = opts.WithContext(ctx)
 
-	services, _, err := p.client.Services().Get(name, opts)
+	services, _, err := client.Services().Get(name, opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch services: %w", err)
 	}
 	return services, nil
 }
 
-func createClient(namespace string, endpoint *EndpointConfig) (*api.Client, error) {
+// getAllocation returns the allocation matching allocID.
+// The result is memoized in cache, so that each allocation is fetched at most once.
+func (p *Provider) getAllocation(ctx context.Context, client *api.Client, cache map[string]*api.Allocation, allocID string) (*api.Allocation, error) {
+	if alloc, ok := cache[allocID]; ok {
+		return alloc, nil
+	}
+
+	opts := &api.QueryOptions{AllowStale: p.Stale}
+	opts = opts.WithContext(ctx)
+
+	alloc, _, err := client.Allocations().Info(allocID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch allocation %s: %w", allocID, err)
+	}
+
+	cache[allocID] = alloc
+
+	return alloc, nil
+}
+
+// checkResult is the latest result of a Nomad service check.
+type checkResult struct {
+	Service string
+	Check   string
+	Status  string
+}
+
+// getAllocationChecks returns the latest results of the checks of the allocation services.
+// The Nomad API client in use does not provide the checks endpoint yet.
+func (p *Provider) getAllocationChecks(ctx context.Context, client *api.Client, cache map[string][]checkResult, allocID string) ([]checkResult, error) {
+	if checks, ok := cache[allocID]; ok {
+		return checks, nil
+	}
+
+	opts := &api.QueryOptions{AllowStale: p.Stale}
+	opts = opts.WithContext(ctx)
+
+	var results map[string]checkResult
+	if _, err := client.Raw().Query("/v1/client/allocation/"+allocID+"/checks", &results, opts); err != nil {
+		return nil, fmt.Errorf("failed to fetch checks of allocation %s: %w", allocID, err)
+	}
+
+	checks := make([]checkResult, 0, len(results))
+	for _, result := range results {
+		checks = append(checks, result)
+	}
+
+	cache[allocID] = checks
+
+	return checks, nil
+}
+
+// failingCheck returns the name of a failing check of the service, if any.
+// Pending checks are not considered as failing.
+func failingCheck(checks []checkResult, serviceName string) string {
+	for _, check := range checks {
+		if check.Service == serviceName && check.Status == "failure" {
+			return check.Check
+		}
+	}
+
+	return ""
+}
+
+// allocationPorts returns the host ports allocated to alloc, indexed by label.
+func allocationPorts(alloc *api.Allocation) map[string]int {
+	ports := make(map[string]int)
+	if alloc == nil || alloc.AllocatedResources == nil {
+		return ports
+	}
+
+	addNetworks := func(networks []*api.NetworkResource) {
+		for _, network := range networks {
+			if network == nil {
+				continue
+			}
+			for _, port := range network.ReservedPorts {
+				ports[port.Label] = port.Value
+			}
+			for _, port := range network.DynamicPorts {
+				ports[port.Label] = port.Value
+			}
+		}
+	}
+
+	for _, task := range alloc.AllocatedResources.Tasks {
+		if task != nil {
+			addNetworks(task.Networks)
+		}
+	}
+
+	addNetworks(alloc.AllocatedResources.Shared.Networks)
+
+	for _, port := range alloc.AllocatedResources.Shared.Ports {
+		ports[port.Label] = port.Value
+	}
+
+	return ports
+}
+
+// createClient returns a Nomad API client using the given HTTP client, or the default one of the Nomad API when nil.
+func createClient(namespace string, endpoint *EndpointConfig, httpClient *http.Client) (*api.Client, error) {
+	token, err := endpoint.token()
+	if err != nil {
+		return nil, err
+	}
+
 	config := api.Config{
-		Address:   endpoint.Address,
-		Namespace: namespace,
-		Region:    endpoint.Region,
-		SecretID:  endpoint.Token,
-		WaitTime:  time.Duration(endpoint.EndpointWaitTime),
+		Address:    endpoint.Address,
+		Namespace:  namespace,
+		Region:     endpoint.Region,
+		SecretID:   token,
+		WaitTime:   time.Duration(endpoint.EndpointWaitTime),
+		HttpClient: httpClient,
 	}
 
 	if endpoint.TLS != nil {
 		config.TLSConfig = &api.TLSConfig{
-			CACert:     endpoint.TLS.CA,
-			ClientCert: endpoint.TLS.Cert,
-			ClientKey:  endpoint.TLS.Key,
-			Insecure:   endpoint.TLS.InsecureSkipVerify,
+			CACert:        endpoint.TLS.CA,
+			ClientCert:    endpoint.TLS.Cert,
+			ClientKey:     endpoint.TLS.Key,
+			TLSServerName: endpoint.TLS.ServerName,
+			Insecure:      endpoint.TLS.InsecureSkipVerify,
 		}
 	}
 
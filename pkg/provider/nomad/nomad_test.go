@@ -4,12 +4,17 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/nomad/api"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/traefik/traefik/v3/pkg/types"
+	ptypes "github.com/traefik/paerser/types"
 )
 
 func Test_globalConfig(t *testing.T) {
@@ -62,6 +67,48 @@ func Test_globalConfig(t *testing.T) {
 			ExposedByDefault: true,
 			exp:              configuration{Enable: false},
 		},
+		{
+			Name:             "expose_by_default_tags_disable_secure_headers",
+			Prefix:           "traefik",
+			Tags:             []string{"traefik.nomad.secureheaders=false"},
+			ExposedByDefault: true,
+			exp:              configuration{Enable: true, SkipSecureHeaders: true},
+		},
+		{
+			Name:             "expose_by_default_tags_failover_tier",
+			Prefix:           "traefik",
+			Tags:             []string{"traefik.nomad.failoverTier=2"},
+			ExposedByDefault: true,
+			exp:              configuration{Enable: true, FailoverTier: 2},
+		},
+		{
+			Name:             "expose_by_default_tags_invalid_failover_tier",
+			Prefix:           "traefik",
+			Tags:             []string{"traefik.nomad.failoverTier=primary"},
+			ExposedByDefault: true,
+			exp:              configuration{Enable: true},
+		},
+		{
+			Name:             "expose_by_default_tags_mirror",
+			Prefix:           "traefik",
+			Tags:             []string{"traefik.nomad.mirror.percent=10", "traefik.nomad.mirror.rule=Method(`GET`)"},
+			ExposedByDefault: true,
+			exp:              configuration{Enable: true, Mirror: &mirror{Percent: 10, Rule: "Method(`GET`)"}},
+		},
+		{
+			Name:             "expose_by_default_tags_mirror_rule_only",
+			Prefix:           "traefik",
+			Tags:             []string{"traefik.nomad.mirror.rule=Method(`GET`)"},
+			ExposedByDefault: true,
+			exp:              configuration{Enable: true, Mirror: &mirror{Percent: 100, Rule: "Method(`GET`)"}},
+		},
+		{
+			Name:             "expose_by_default_tags_invalid_mirror_percent",
+			Prefix:           "traefik",
+			Tags:             []string{"traefik.nomad.mirror.percent=150"},
+			ExposedByDefault: true,
+			exp:              configuration{Enable: true, Mirror: &mirror{Percent: 100}},
+		},
 	}
 
 	for _, test := range cases {
@@ -89,29 +136,45 @@ func TestProvider_SetDefaults_Endpoint(t *testing.T) {
 			envs: map[string]string{},
 			expected: &EndpointConfig{
 				Address: "http://127.0.0.1:4646",
+				Transport: &EndpointTransport{
+					MaxIdleConnsPerHost: 10,
+					IdleConnTimeout:     ptypes.Duration(90 * time.Second),
+					DialTimeout:         ptypes.Duration(30 * time.Second),
+					KeepAlive:           ptypes.Duration(30 * time.Second),
+					TLSHandshakeTimeout: ptypes.Duration(10 * time.Second),
+				},
 			},
 		},
 		{
 			desc: "with env vars",
 			envs: map[string]string{
-				"NOMAD_ADDR":        "https://nomad.example.com",
-				"NOMAD_REGION":      "us-west",
-				"NOMAD_TOKEN":       "almighty_token",
-				"NOMAD_CACERT":      "/etc/ssl/private/nomad-agent-ca.pem",
-				"NOMAD_CLIENT_CERT": "/etc/ssl/private/global-client-nomad.pem",
-				"NOMAD_CLIENT_KEY":  "/etc/ssl/private/global-client-nomad-key.pem",
-				"NOMAD_SKIP_VERIFY": "true",
+				"NOMAD_ADDR":            "https://nomad.example.com",
+				"NOMAD_REGION":          "us-west",
+				"NOMAD_TOKEN":           "almighty_token",
+				"NOMAD_CACERT":          "/etc/ssl/private/nomad-agent-ca.pem",
+				"NOMAD_CLIENT_CERT":     "/etc/ssl/private/global-client-nomad.pem",
+				"NOMAD_CLIENT_KEY":      "/etc/ssl/private/global-client-nomad-key.pem",
+				"NOMAD_SKIP_VERIFY":     "true",
+				"NOMAD_TLS_SERVER_NAME": "server.global.nomad",
 			},
 			expected: &EndpointConfig{
 				Address: "https://nomad.example.com",
 				Region:  "us-west",
 				Token:   "almighty_token",
-				TLS: &types.ClientTLS{
+				TLS: &EndpointTLS{
 					CA:                 "/etc/ssl/private/nomad-agent-ca.pem",
 					Cert:               "/etc/ssl/private/global-client-nomad.pem",
 					Key:                "/etc/ssl/private/global-client-nomad-key.pem",
+					ServerName:         "server.global.nomad",
 					InsecureSkipVerify: true,
 				},
+				Transport: &EndpointTransport{
+					MaxIdleConnsPerHost: 10,
+					IdleConnTimeout:     ptypes.Duration(90 * time.Second),
+					DialTimeout:         ptypes.Duration(30 * time.Second),
+					KeepAlive:           ptypes.Duration(30 * time.Second),
+					TLSHandshakeTimeout: ptypes.Duration(10 * time.Second),
+				},
 				EndpointWaitTime: 0,
 			},
 		},
@@ -132,6 +195,52 @@ func TestProvider_SetDefaults_Endpoint(t *testing.T) {
 	}
 }
 
+func Test_rotateToken(t *testing.T) {
+	var token string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token = r.Header.Get("X-Nomad-Token")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(ts.Close)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first-token\n"), 0o600))
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.Endpoint.Token = "ignored-token"
+	p.Endpoint.TokenFile = tokenFile
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+	p.token, err = p.Endpoint.token()
+	require.NoError(t, err)
+
+	_, err = p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "first-token", token)
+
+	// the token file is rotated.
+	require.NoError(t, os.WriteFile(tokenFile, []byte("second-token"), 0o600))
+	p.rotateToken(context.TODO())
+
+	_, err = p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "second-token", token)
+
+	// the previous token is kept when the token file cannot be read.
+	require.NoError(t, os.Remove(tokenFile))
+	p.rotateToken(context.TODO())
+
+	_, err = p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "second-token", token)
+}
+
 func Test_getNomadServiceData(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch {
@@ -152,7 +261,7 @@ func Test_getNomadServiceData(t *testing.T) {
 	require.NoError(t, err)
 
 	// fudge client, avoid starting up via Provide
-	p.client, err = createClient(p.namespace, p.Endpoint)
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
 	require.NoError(t, err)
 
 	// make the query for services
@@ -161,70 +270,1411 @@ func Test_getNomadServiceData(t *testing.T) {
 	require.Len(t, items, 2)
 }
 
-const services = `
-[
-  {
-    "Namespace": "default",
-    "Services": [
-      {
-        "ServiceName": "redis",
-        "Tags": [
-          "traefik.enable=true"
-        ]
-      },
-      {
-        "ServiceName": "hello-nomad",
-        "Tags": [
-          "traefik.enable=true",
-          "traefik.http.routers.hellon.entrypoints=web",
-          "traefik.http.routers.hellon.service=hello-nomad"
-        ]
-      }
-    ]
-  }
-]
-`
+func Test_getNomadServiceData_exposedByDefault(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		exposedByDefault bool
+		services         string
+		expected         []string
+		expectedFilter   string
+	}{
+		{
+			desc:             "exposed by default",
+			exposedByDefault: true,
+			services:         `[{"Namespace":"default","Services":[{"ServiceName":"redis","Tags":[]},{"ServiceName":"hello-nomad","Tags":["traefik.enable=false"]}]}]`,
+			expected:         []string{"redis"},
+		},
+		{
+			desc:             "not exposed by default",
+			exposedByDefault: false,
+			services:         `[{"Namespace":"default","Services":[{"ServiceName":"redis","Tags":[]},{"ServiceName":"hello-nomad","Tags":["traefik.enable=true"]}]}]`,
+			expected:         []string{"hello-nomad"},
+			expectedFilter:   `Tags contains "traefik.enable=true"`,
+		},
+	}
 
-const redis = `
-[
-  {
-    "Address": "127.0.0.1",
-    "AllocID": "07501480-8175-8071-7da6-133bd1ff890f",
-    "CreateIndex": 46,
-    "Datacenter": "dc1",
-    "ID": "_nomad-task-07501480-8175-8071-7da6-133bd1ff890f-group-redis-redis-redis",
-    "JobID": "echo",
-    "ModifyIndex": 46,
-    "Namespace": "default",
-    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
-    "Port": 30826,
-    "ServiceName": "redis",
-    "Tags": [
-      "traefik.enable=true"
-    ]
-  }
-]
-`
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var filters []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/v1/services"):
+					_, _ = w.Write([]byte(test.services))
+				case strings.HasSuffix(r.URL.Path, "/v1/service/redis"):
+					filters = append(filters, r.URL.Query().Get("filter"))
+					_, _ = w.Write([]byte(redis))
+				case strings.HasSuffix(r.URL.Path, "/v1/service/hello-nomad"):
+					filters = append(filters, r.URL.Query().Get("filter"))
+					_, _ = w.Write([]byte(hello))
+				}
+			}))
+			t.Cleanup(ts.Close)
 
-const hello = `
-[
-  {
-    "Address": "127.0.0.1",
-    "AllocID": "71a63a80-a98a-93ee-4fd7-73b808577c20",
-    "CreateIndex": 18,
-    "Datacenter": "dc1",
-    "ID": "_nomad-task-71a63a80-a98a-93ee-4fd7-73b808577c20-group-hello-nomad-hello-nomad-http",
-    "JobID": "echo",
-    "ModifyIndex": 18,
-    "Namespace": "default",
-    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
-    "Port": 20627,
-    "ServiceName": "hello-nomad",
-    "Tags": [
-      "traefik.enable=true",
-      "traefik.http.routers.hellon.entrypoints=web",
-      "traefik.http.routers.hellon.service=hello-nomad"
-    ]
-  }
-]
+			p := new(Provider)
+			p.SetDefaults()
+			p.Endpoint.Address = ts.URL
+			p.ExposedByDefault = test.exposedByDefault
+			err := p.Init()
+			require.NoError(t, err)
+
+			p.client, err = createClient(p.namespace, p.Endpoint, nil)
+			require.NoError(t, err)
+
+			items, err := p.getNomadServiceData(context.TODO())
+			require.NoError(t, err)
+
+			var names []string
+			for _, i := range items {
+				names = append(names, i.Name)
+			}
+			assert.Equal(t, test.expected, names)
+
+			// disabled services are filtered out before their instances are fetched,
+			// and only enabled instances are requested when not exposed by default.
+			assert.Equal(t, []string{test.expectedFilter}, filters)
+		})
+	}
+}
+
+func Test_getNomadServiceData_constraints(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		constraints string
+		expected    []string
+	}{
+		{
+			desc:     "no constraints",
+			expected: []string{"redis", "hello-nomad"},
+		},
+		{
+			desc:        "select services with entrypoint tag",
+			constraints: "Tag(`traefik.http.routers.hellon.entrypoints=web`)",
+			expected:    []string{"hello-nomad"},
+		},
+		{
+			desc:        "select disjoint subset",
+			constraints: "!Tag(`traefik.http.routers.hellon.entrypoints=web`)",
+			expected:    []string{"redis"},
+		},
+		{
+			desc:        "select nothing",
+			constraints: "Tag(`traefik.tags=private`)",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			fetched := make(map[string]bool)
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.RequestURI, "/v1/services"):
+					_, _ = w.Write([]byte(services))
+				case strings.HasSuffix(r.RequestURI, "/v1/service/redis"):
+					fetched["redis"] = true
+					_, _ = w.Write([]byte(redis))
+				case strings.HasSuffix(r.RequestURI, "/v1/service/hello-nomad"):
+					fetched["hello-nomad"] = true
+					_, _ = w.Write([]byte(hello))
+				}
+			}))
+			t.Cleanup(ts.Close)
+
+			p := new(Provider)
+			p.SetDefaults()
+			p.Endpoint.Address = ts.URL
+			p.Constraints = test.constraints
+			err := p.Init()
+			require.NoError(t, err)
+
+			p.client, err = createClient(p.namespace, p.Endpoint, nil)
+			require.NoError(t, err)
+
+			items, err := p.getNomadServiceData(context.TODO())
+			require.NoError(t, err)
+
+			var names []string
+			for _, i := range items {
+				names = append(names, i.Name)
+			}
+			assert.Equal(t, test.expected, names)
+
+			// services filtered out by constraints must not be queried at all.
+			assert.Len(t, fetched, len(test.expected))
+		})
+	}
+}
+
+func Test_getNomadServiceData_jobs(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		jobSelector       string
+		jobTypes          []string
+		jobMetaConstraint string
+		filterJobs        bool // whether the API filters the jobs by meta
+		expected          []string
+		fetchedJobs       int
+	}{
+		{
+			desc:     "no filter",
+			expected: []string{"redis", "node-exporter"},
+		},
+		{
+			desc:        "select jobs by name",
+			jobSelector: "ech*",
+			expected:    []string{"redis"},
+		},
+		{
+			desc:        "select no job",
+			jobSelector: "web-*",
+		},
+		{
+			desc:        "select jobs by type",
+			jobTypes:    []string{"service"},
+			expected:    []string{"redis"},
+			fetchedJobs: 2,
+		},
+		{
+			desc:        "select jobs by name and type",
+			jobSelector: "node-*",
+			jobTypes:    []string{"system", "service"},
+			expected:    []string{"node-exporter"},
+			fetchedJobs: 1,
+		},
+		{
+			desc:              "select jobs by meta with a filtered query",
+			jobMetaConstraint: "traefik-tenant == team-a",
+			filterJobs:        true,
+			expected:          []string{"redis"},
+		},
+		{
+			desc:              "select jobs by meta without filtered query",
+			jobMetaConstraint: "traefik-tenant == team-a",
+			expected:          []string{"redis"},
+			fetchedJobs:       2,
+		},
+		{
+			desc:              "exclude jobs by meta",
+			jobMetaConstraint: "traefik-tenant != team-a",
+			expected:          []string{"node-exporter"},
+			fetchedJobs:       2,
+		},
+		{
+			desc:              "select jobs by meta and type with a filtered query",
+			jobMetaConstraint: "traefik-tenant == team-a",
+			jobTypes:          []string{"system"},
+			filterJobs:        true,
+			fetchedJobs:       1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var fetchedJobs int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case strings.HasSuffix(r.URL.Path, "/v1/services"):
+					_, _ = w.Write([]byte(servicesJobs))
+				case strings.HasSuffix(r.URL.Path, "/v1/service/redis"):
+					_, _ = w.Write([]byte(redis))
+				case strings.HasSuffix(r.URL.Path, "/v1/service/node-exporter"):
+					_, _ = w.Write([]byte(nodeExporter))
+				case strings.HasSuffix(r.URL.Path, "/v1/jobs"):
+					if !test.filterJobs {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					assert.Equal(t, `Meta["traefik-tenant"] == "team-a"`, r.URL.Query().Get("filter"))
+					assert.Equal(t, "true", r.URL.Query().Get("meta"))
+					_, _ = w.Write([]byte(`[{"ID": "echo", "Type": "service"}]`))
+				case strings.HasSuffix(r.URL.Path, "/v1/job/echo"):
+					fetchedJobs++
+					_, _ = w.Write([]byte(`{"ID": "echo", "Type": "service", "Meta": {"traefik-tenant": "team-a"}}`))
+				case strings.HasSuffix(r.URL.Path, "/v1/job/node-exporter"):
+					fetchedJobs++
+					_, _ = w.Write([]byte(`{"ID": "node-exporter", "Type": "system", "Meta": {"traefik-tenant": "team-b"}}`))
+				}
+			}))
+			t.Cleanup(ts.Close)
+
+			p := new(Provider)
+			p.SetDefaults()
+			p.Endpoint.Address = ts.URL
+			p.JobSelector = test.jobSelector
+			p.JobTypes = test.jobTypes
+			p.JobMetaConstraint = test.jobMetaConstraint
+			err := p.Init()
+			require.NoError(t, err)
+
+			p.client, err = createClient(p.namespace, p.Endpoint, nil)
+			require.NoError(t, err)
+
+			items, err := p.getNomadServiceData(context.TODO())
+			require.NoError(t, err)
+
+			var names []string
+			for _, i := range items {
+				names = append(names, i.Name)
+			}
+			assert.Equal(t, test.expected, names)
+
+			// the jobs are fetched once per refresh, and only to filter them by type, or by meta when the API cannot filter them.
+			assert.Equal(t, test.fetchedJobs, fetchedJobs)
+		})
+	}
+}
+
+func TestProvider_Init_jobFilters(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	p.JobSelector = "web-["
+	require.Error(t, p.Init())
+
+	p = new(Provider)
+	p.SetDefaults()
+	p.JobTypes = []string{"service", "cron"}
+	require.Error(t, p.Init())
+
+	p = new(Provider)
+	p.SetDefaults()
+	p.JobMetaConstraint = "traefik-tenant"
+	require.Error(t, p.Init())
+
+	p = new(Provider)
+	p.SetDefaults()
+	p.JobSelector = "web-*"
+	p.JobTypes = []string{"service", "sysbatch"}
+	p.JobMetaConstraint = "traefik-tenant == team-a"
+	require.NoError(t, p.Init())
+}
+
+func Test_parseJobMetaConstraint(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		constraint  string
+		expected    *jobMetaConstraint
+		expectedErr string
+	}{
+		{
+			desc: "empty",
+		},
+		{
+			desc:       "equal",
+			constraint: "traefik-tenant == team-a",
+			expected:   &jobMetaConstraint{key: "traefik-tenant", value: "team-a"},
+		},
+		{
+			desc:       "not equal",
+			constraint: "traefik-tenant!=team-a",
+			expected:   &jobMetaConstraint{key: "traefik-tenant", value: "team-a", negate: true},
+		},
+		{
+			desc:       "quoted value",
+			constraint: `traefik-tenant == "team a"`,
+			expected:   &jobMetaConstraint{key: "traefik-tenant", value: "team a"},
+		},
+		{
+			desc:        "missing operator",
+			constraint:  "traefik-tenant",
+			expectedErr: `invalid job meta constraint "traefik-tenant", expected "key == value" or "key != value"`,
+		},
+		{
+			desc:        "missing key",
+			constraint:  " == team-a",
+			expectedErr: `invalid job meta constraint " == team-a", the meta key is empty`,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			constraint, err := parseJobMetaConstraint(test.constraint)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, constraint)
+		})
+	}
+}
+
+func Test_getNomadServiceData_regions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		region := r.URL.Query().Get("region")
+		switch {
+		case region == "broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.HasSuffix(r.URL.Path, "/v1/services") && region == "us":
+			_, _ = w.Write([]byte(`[{"Namespace":"default","Services":[{"ServiceName":"redis","Tags":["traefik.enable=true"]}]}]`))
+		case strings.HasSuffix(r.URL.Path, "/v1/services") && region == "eu":
+			_, _ = w.Write([]byte(`[{"Namespace":"default","Services":[{"ServiceName":"hello-nomad","Tags":["traefik.enable=true"]}]}]`))
+		case strings.HasSuffix(r.URL.Path, "/v1/service/redis"):
+			_, _ = w.Write([]byte(redis))
+		case strings.HasSuffix(r.URL.Path, "/v1/service/hello-nomad"):
+			_, _ = w.Write([]byte(hello))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	testCases := []struct {
+		desc     string
+		regions  []string
+		expected []string
+		wantErr  bool
+	}{
+		{
+			desc:     "two regions",
+			regions:  []string{"us", "eu"},
+			expected: []string{"redis", "hello-nomad"},
+		},
+		{
+			desc:     "one region unreachable",
+			regions:  []string{"us", "broken"},
+			expected: []string{"redis"},
+		},
+		{
+			desc:    "all regions unreachable",
+			regions: []string{"broken"},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			p.Endpoint.Address = ts.URL
+			p.Regions = test.regions
+			err := p.Init()
+			require.NoError(t, err)
+
+			p.regionClients = make(map[string]*api.Client)
+			for _, region := range test.regions {
+				p.regionClients[region], err = createClient(p.namespace, &EndpointConfig{Address: ts.URL, Region: region}, nil)
+				require.NoError(t, err)
+			}
+
+			items, err := p.getNomadServiceData(context.TODO())
+			if test.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			var names []string
+			for _, i := range items {
+				names = append(names, i.Name)
+			}
+			assert.ElementsMatch(t, test.expected, names)
+		})
+	}
+}
+
+func Test_getNomadServiceData_regionLastKnown(t *testing.T) {
+	var euDown atomic.Bool
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		region := r.URL.Query().Get("region")
+		switch {
+		case region == "eu" && euDown.Load():
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.HasSuffix(r.URL.Path, "/v1/services") && region == "us":
+			_, _ = w.Write([]byte(`[{"Namespace":"default","Services":[{"ServiceName":"redis","Tags":["traefik.enable=true"]}]}]`))
+		case strings.HasSuffix(r.URL.Path, "/v1/services") && region == "eu":
+			_, _ = w.Write([]byte(`[{"Namespace":"default","Services":[{"ServiceName":"hello-nomad","Tags":["traefik.enable=true"]}]}]`))
+		case strings.HasSuffix(r.URL.Path, "/v1/service/redis"):
+			_, _ = w.Write([]byte(redis))
+		case strings.HasSuffix(r.URL.Path, "/v1/service/hello-nomad"):
+			_, _ = w.Write([]byte(hello))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.Regions = []string{"us", "eu"}
+	err := p.Init()
+	require.NoError(t, err)
+
+	p.regionClients = make(map[string]*api.Client)
+	for _, region := range p.Regions {
+		p.regionClients[region], err = createClient(p.namespace, &EndpointConfig{Address: ts.URL, Region: region}, nil)
+		require.NoError(t, err)
+	}
+
+	names := func(items []item) []string {
+		var names []string
+		for _, i := range items {
+			names = append(names, i.Name)
+		}
+		return names
+	}
+
+	items, err := p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"redis", "hello-nomad"}, names(items))
+
+	// the instances of the unreachable region are the ones of its last successful refresh.
+	euDown.Store(true)
+
+	items, err = p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"redis", "hello-nomad"}, names(items))
+}
+
+func Test_getNomadServiceData_portLabels(t *testing.T) {
+	var allocRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.RequestURI, "/v1/services"):
+			_, _ = w.Write([]byte(servicesPortLabels))
+		case strings.HasSuffix(r.RequestURI, "/v1/service/multi"):
+			_, _ = w.Write([]byte(multi))
+		case strings.HasSuffix(r.RequestURI, "/v1/allocation/e5b6c1ae-0fa3-43b2-85c4-84c8d94cbbc3"):
+			allocRequests++
+			_, _ = w.Write([]byte(multiAlloc))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	items, err := p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, 1, allocRequests)
+	for _, i := range items {
+		assert.Equal(t, map[string]int{"http": 25123, "admin": 25124, "metrics": 9102}, i.Ports)
+	}
+}
+
+func Test_getNomadServiceData_nodeName(t *testing.T) {
+	var allocRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.RequestURI, "/v1/services"):
+			_, _ = w.Write([]byte(servicesRedis))
+		case strings.HasSuffix(r.RequestURI, "/v1/service/redis"):
+			_, _ = w.Write([]byte(redis))
+		case strings.HasSuffix(r.RequestURI, "/v1/allocation/07501480-8175-8071-7da6-133bd1ff890f"):
+			allocRequests++
+			_, _ = w.Write([]byte(redisAlloc))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	testCases := []struct {
+		desc                  string
+		rule                  string
+		expectedNodeName      string
+		expectedAllocRequests int
+	}{
+		{
+			desc:                  "node name not referenced",
+			rule:                  "Host(`{{ .Job }}.example.com`)",
+			expectedNodeName:      "",
+			expectedAllocRequests: 0,
+		},
+		{
+			desc:                  "node name referenced",
+			rule:                  "Host(`{{ .NodeName }}.example.com`)",
+			expectedNodeName:      "worker-1",
+			expectedAllocRequests: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			allocRequests = 0
+
+			p := new(Provider)
+			p.SetDefaults()
+			p.Endpoint.Address = ts.URL
+			p.DefaultRule = test.rule
+			err := p.Init()
+			require.NoError(t, err)
+
+			// fudge client, avoid starting up via Provide
+			p.client, err = createClient(p.namespace, p.Endpoint, nil)
+			require.NoError(t, err)
+
+			items, err := p.getNomadServiceData(context.TODO())
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+
+			assert.Equal(t, test.expectedAllocRequests, allocRequests)
+			assert.Equal(t, "echo", items[0].Job)
+			assert.Equal(t, test.expectedNodeName, items[0].NodeName)
+		})
+	}
+}
+
+func Test_getNomadServiceData_jobVersions(t *testing.T) {
+	var allocRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.RequestURI, "/v1/services"):
+			_, _ = w.Write([]byte(servicesVersions))
+		case strings.HasSuffix(r.RequestURI, "/v1/service/versions"):
+			_, _ = w.Write([]byte(versions))
+		case strings.HasSuffix(r.RequestURI, "/v1/allocation/6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01"):
+			allocRequests++
+			_, _ = w.Write([]byte(versionsAllocV3))
+		case strings.HasSuffix(r.RequestURI, "/v1/allocation/6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02"):
+			allocRequests++
+			_, _ = w.Write([]byte(versionsAllocV4))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	items, err := p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	// the job version of the instance without the weights is fetched too.
+	assert.Equal(t, 2, allocRequests)
+
+	jobVersions := make(map[string]uint64)
+	for _, i := range items {
+		require.NotNil(t, i.JobVersion)
+		jobVersions[i.AllocID] = *i.JobVersion
+	}
+	assert.Equal(t, map[string]uint64{
+		"6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01": 3,
+		"6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02": 4,
+	}, jobVersions)
+}
+
+func Test_getNomadServiceData_allocationNotFound(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.RequestURI, "/v1/services"):
+			_, _ = w.Write([]byte(servicesVersions))
+		case strings.HasSuffix(r.RequestURI, "/v1/service/versions"):
+			_, _ = w.Write([]byte(versions))
+		case strings.HasSuffix(r.RequestURI, "/v1/allocation/6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01"):
+			_, _ = w.Write([]byte(versionsAllocV3))
+		case strings.HasSuffix(r.RequestURI, "/v1/allocation/6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02"):
+			// the allocation is garbage collected, its service registration not being removed yet.
+			http.Error(w, "alloc not found", http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	// only the instance whose allocation is not found is skipped.
+	items, err := p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01", items[0].AllocID)
+}
+
+func Test_getNomadServiceData_meta(t *testing.T) {
+	var allocRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/v1/services"):
+			_, _ = w.Write([]byte(servicesMeta))
+		case strings.HasSuffix(r.URL.Path, "/v1/service/redis"):
+			// the enable tag is set in the meta blocks, the instances must not be filtered by tags.
+			if r.URL.Query().Get("filter") != "" {
+				_, _ = w.Write([]byte("[]"))
+				return
+			}
+			_, _ = w.Write([]byte(redisMeta))
+		case strings.HasSuffix(r.URL.Path, "/v1/allocation/07501480-8175-8071-7da6-133bd1ff890f"):
+			allocRequests++
+			_, _ = w.Write([]byte(redisMetaAlloc))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.ExposedByDefault = false
+	p.UseMeta = true
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	items, err := p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	assert.Equal(t, 1, allocRequests)
+	assert.Equal(t, []string{
+		"traefik.enable=true",
+		"traefik.http.routers.redis.entrypoints=web",
+		"traefik.http.routers.redis.rule=Host(`meta.example.com`)",
+		"traefik.http.routers.redis.rule=Host(`redis.example.com`)",
+	}, items[0].Tags)
+	assert.True(t, items[0].ExtraConf.Enable)
+
+	config := p.buildConfig(context.TODO(), items)
+	require.Contains(t, config.HTTP.Routers, "redis")
+	assert.Equal(t, "Host(`redis.example.com`)", config.HTTP.Routers["redis"].Rule)
+	assert.Equal(t, []string{"web"}, config.HTTP.Routers["redis"].EntryPoints)
+}
+
+func Test_getNomadServiceData_stoppingAllocations(t *testing.T) {
+	var allocationsRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/services":
+			_, _ = w.Write([]byte(servicesRedis))
+		case "/v1/service/redis":
+			_, _ = w.Write([]byte(redis))
+		case "/v1/allocations":
+			allocationsRequests++
+			_, _ = w.Write([]byte(redisStoppingAllocs))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	testCases := []struct {
+		desc                        string
+		drainTimeout                ptypes.Duration
+		expectedDraining            bool
+		expectedAllocationsRequests int
+	}{
+		{
+			desc:                        "drain disabled",
+			expectedDraining:            false,
+			expectedAllocationsRequests: 0,
+		},
+		{
+			desc:                        "drain enabled",
+			drainTimeout:                ptypes.Duration(time.Minute),
+			expectedDraining:            true,
+			expectedAllocationsRequests: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			allocationsRequests = 0
+
+			p := new(Provider)
+			p.SetDefaults()
+			p.Endpoint.Address = ts.URL
+			p.DrainTimeout = test.drainTimeout
+			err := p.Init()
+			require.NoError(t, err)
+
+			// fudge client, avoid starting up via Provide
+			p.client, err = createClient(p.namespace, p.Endpoint, nil)
+			require.NoError(t, err)
+
+			items, err := p.getNomadServiceData(context.TODO())
+			require.NoError(t, err)
+			require.Len(t, items, 1)
+
+			assert.Equal(t, test.expectedAllocationsRequests, allocationsRequests)
+			assert.Equal(t, test.expectedDraining, items[0].Draining)
+		})
+	}
+}
+
+func Test_getNomadServiceData_udpChecks(t *testing.T) {
+	var checksRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/services":
+			_, _ = w.Write([]byte(servicesDNS))
+		case "/v1/service/dns":
+			_, _ = w.Write([]byte(dns))
+		case "/v1/client/allocation/1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a01/checks":
+			checksRequests++
+			_, _ = w.Write([]byte(dnsHealthyChecks))
+		case "/v1/client/allocation/1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a02/checks":
+			checksRequests++
+			_, _ = w.Write([]byte(dnsFailingChecks))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	items, err := p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+
+	var allocIDs []string
+	for _, i := range items {
+		allocIDs = append(allocIDs, i.AllocID)
+	}
+
+	// the instance with a failing check is dropped,
+	// the instance whose checks cannot be fetched is kept.
+	assert.Equal(t, []string{
+		"1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a01",
+		"1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a03",
+	}, allocIDs)
+	assert.Equal(t, 2, checksRequests)
+}
+
+func Test_getNomadServiceData_consulServices(t *testing.T) {
+	allocRequests := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/services":
+			_, _ = w.Write([]byte("[]"))
+		case r.URL.Path == "/v1/allocations":
+			_, _ = w.Write([]byte(consulAllocs))
+		case strings.HasPrefix(r.URL.Path, "/v1/allocation/"):
+			id := strings.TrimPrefix(r.URL.Path, "/v1/allocation/")
+			allocRequests[id]++
+			if id == "9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e01" {
+				_, _ = w.Write([]byte(consulWebAlloc))
+				return
+			}
+			_, _ = w.Write([]byte(consulBatchAlloc))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.ConsulServices = true
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	items, err := p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	assert.Equal(t, "web", items[0].Name)
+	assert.Equal(t, "shop", items[0].Job)
+	assert.Equal(t, "9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e01", items[0].AllocID)
+	assert.Equal(t, "10.0.0.7", items[0].Address)
+	assert.Equal(t, 27017, items[0].Port)
+	assert.Equal(t, []string{"traefik.enable=true"}, items[0].Tags)
+
+	// the allocations not running are not fetched,
+	// and a single allocation of the task group without Consul service is.
+	assert.Equal(t, map[string]int{
+		"9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e01": 1,
+		"9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e02": 1,
+	}, allocRequests)
+}
+
+const services = `
+[
+  {
+    "Namespace": "default",
+    "Services": [
+      {
+        "ServiceName": "redis",
+        "Tags": [
+          "traefik.enable=true"
+        ]
+      },
+      {
+        "ServiceName": "hello-nomad",
+        "Tags": [
+          "traefik.enable=true",
+          "traefik.http.routers.hellon.entrypoints=web",
+          "traefik.http.routers.hellon.service=hello-nomad"
+        ]
+      }
+    ]
+  }
+]
+`
+
+const redis = `
+[
+  {
+    "Address": "127.0.0.1",
+    "AllocID": "07501480-8175-8071-7da6-133bd1ff890f",
+    "CreateIndex": 46,
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-07501480-8175-8071-7da6-133bd1ff890f-group-redis-redis-redis",
+    "JobID": "echo",
+    "ModifyIndex": 46,
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 30826,
+    "ServiceName": "redis",
+    "Tags": [
+      "traefik.enable=true"
+    ]
+  }
+]
+`
+
+const servicesRedis = `
+[
+  {
+    "Namespace": "default",
+    "Services": [
+      {
+        "ServiceName": "redis",
+        "Tags": [
+          "traefik.enable=true"
+        ]
+      }
+    ]
+  }
+]
+`
+
+const redisAlloc = `
+{
+  "ID": "07501480-8175-8071-7da6-133bd1ff890f",
+  "Namespace": "default",
+  "JobID": "echo",
+  "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+  "NodeName": "worker-1"
+}
+`
+
+const redisStoppingAllocs = `
+[
+  {
+    "ID": "07501480-8175-8071-7da6-133bd1ff890f",
+    "DesiredStatus": "stop",
+    "ClientStatus": "running"
+  },
+  {
+    "ID": "b9f269c3-6b4b-4ec6-8a0e-e4c3c1a8d1f2",
+    "DesiredStatus": "stop",
+    "ClientStatus": "complete"
+  }
+]
+`
+
+const servicesDNS = `
+[
+  {
+    "Namespace": "default",
+    "Services": [
+      {
+        "ServiceName": "dns",
+        "Tags": [
+          "traefik.enable=true",
+          "traefik.udp.routers.dns.entrypoints=dns"
+        ]
+      }
+    ]
+  }
+]
+`
+
+const dns = `
+[
+  {
+    "Address": "127.0.0.1",
+    "AllocID": "1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a01",
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a01-group-dns-dns-dns",
+    "JobID": "dns",
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 5301,
+    "ServiceName": "dns",
+    "Tags": [
+      "traefik.enable=true",
+      "traefik.udp.routers.dns.entrypoints=dns"
+    ]
+  },
+  {
+    "Address": "127.0.0.2",
+    "AllocID": "1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a02",
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a02-group-dns-dns-dns",
+    "JobID": "dns",
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 5302,
+    "ServiceName": "dns",
+    "Tags": [
+      "traefik.enable=true",
+      "traefik.udp.routers.dns.entrypoints=dns"
+    ]
+  },
+  {
+    "Address": "127.0.0.3",
+    "AllocID": "1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a03",
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-1d9d1a3e-7c9c-4c1b-9f5e-0d7a9a0e1a03-group-dns-dns-dns",
+    "JobID": "dns",
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 5303,
+    "ServiceName": "dns",
+    "Tags": [
+      "traefik.enable=true",
+      "traefik.udp.routers.dns.entrypoints=dns"
+    ]
+  }
+]
+`
+
+const dnsHealthyChecks = `
+{
+  "c0a0d8d0d0c7d6f0e3c7a1b2c3d4e5f6": {
+    "Check": "dig",
+    "Group": "dns",
+    "ID": "c0a0d8d0d0c7d6f0e3c7a1b2c3d4e5f6",
+    "Mode": "healthiness",
+    "Output": "ok",
+    "Service": "dns",
+    "Status": "success",
+    "Timestamp": 1689000000
+  }
+}
+`
+
+const dnsFailingChecks = `
+{
+  "a1b2c3d4e5f6c0a0d8d0d0c7d6f0e3c7": {
+    "Check": "dig",
+    "Group": "dns",
+    "ID": "a1b2c3d4e5f6c0a0d8d0d0c7d6f0e3c7",
+    "Mode": "healthiness",
+    "Output": "connection timed out",
+    "Service": "dns",
+    "Status": "failure",
+    "Timestamp": 1689000000
+  }
+}
+`
+
+const servicesJobs = `
+[
+  {
+    "Namespace": "default",
+    "Services": [
+      {
+        "ServiceName": "redis",
+        "Tags": [
+          "traefik.enable=true"
+        ]
+      },
+      {
+        "ServiceName": "node-exporter",
+        "Tags": [
+          "traefik.enable=true"
+        ]
+      }
+    ]
+  }
+]
+`
+
+const nodeExporter = `
+[
+  {
+    "Address": "127.0.0.1",
+    "AllocID": "3f1c2a4b-5d6e-4f70-8a9b-0c1d2e3f4a5b",
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-3f1c2a4b-5d6e-4f70-8a9b-0c1d2e3f4a5b-group-node-exporter-node-exporter-http",
+    "JobID": "node-exporter",
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 9100,
+    "ServiceName": "node-exporter",
+    "Tags": [
+      "traefik.enable=true"
+    ]
+  }
+]
+`
+
+const hello = `
+[
+  {
+    "Address": "127.0.0.1",
+    "AllocID": "71a63a80-a98a-93ee-4fd7-73b808577c20",
+    "CreateIndex": 18,
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-71a63a80-a98a-93ee-4fd7-73b808577c20-group-hello-nomad-hello-nomad-http",
+    "JobID": "echo",
+    "ModifyIndex": 18,
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 20627,
+    "ServiceName": "hello-nomad",
+    "Tags": [
+      "traefik.enable=true",
+      "traefik.http.routers.hellon.entrypoints=web",
+      "traefik.http.routers.hellon.service=hello-nomad"
+    ]
+  }
+]
+`
+
+const servicesPortLabels = `
+[
+  {
+    "Namespace": "default",
+    "Services": [
+      {
+        "ServiceName": "multi",
+        "Tags": [
+          "traefik.http.services.multi-http.loadbalancer.server.port=http",
+          "traefik.http.services.multi-admin.loadbalancer.server.port=admin"
+        ]
+      }
+    ]
+  }
+]
+`
+
+const multi = `
+[
+  {
+    "Address": "127.0.0.1",
+    "AllocID": "e5b6c1ae-0fa3-43b2-85c4-84c8d94cbbc3",
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-e5b6c1ae-0fa3-43b2-85c4-84c8d94cbbc3-group-multi-multi-http",
+    "JobID": "multi",
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 25123,
+    "ServiceName": "multi",
+    "Tags": [
+      "traefik.http.services.multi-http.loadbalancer.server.port=http",
+      "traefik.http.services.multi-admin.loadbalancer.server.port=admin"
+    ]
+  },
+  {
+    "Address": "127.0.0.1",
+    "AllocID": "e5b6c1ae-0fa3-43b2-85c4-84c8d94cbbc3",
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-e5b6c1ae-0fa3-43b2-85c4-84c8d94cbbc3-group-multi-multi-admin",
+    "JobID": "multi",
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 25124,
+    "ServiceName": "multi",
+    "Tags": [
+      "traefik.http.services.multi-http.loadbalancer.server.port=http",
+      "traefik.http.services.multi-admin.loadbalancer.server.port=admin"
+    ]
+  }
+]
+`
+
+const multiAlloc = `
+{
+  "ID": "e5b6c1ae-0fa3-43b2-85c4-84c8d94cbbc3",
+  "Namespace": "default",
+  "JobID": "multi",
+  "AllocatedResources": {
+    "Tasks": {
+      "server": {
+        "Networks": [
+          {
+            "ReservedPorts": [{"Label": "metrics", "Value": 9102}]
+          }
+        ]
+      }
+    },
+    "Shared": {
+      "Ports": [
+        {"Label": "http", "Value": 25123, "To": 8080, "HostIP": "127.0.0.1"},
+        {"Label": "admin", "Value": 25124, "To": 9090, "HostIP": "127.0.0.1"}
+      ]
+    }
+  }
+}
+`
+
+const servicesMeta = `
+[
+  {
+    "Namespace": "default",
+    "Services": [
+      {
+        "ServiceName": "redis",
+        "Tags": [
+          "traefik.http.routers.redis.rule=Host(` + "`redis.example.com`" + `)"
+        ]
+      }
+    ]
+  }
+]
+`
+
+const redisMeta = `
+[
+  {
+    "Address": "127.0.0.1",
+    "AllocID": "07501480-8175-8071-7da6-133bd1ff890f",
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-07501480-8175-8071-7da6-133bd1ff890f-group-redis-redis-redis",
+    "JobID": "echo",
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 30826,
+    "ServiceName": "redis",
+    "Tags": [
+      "traefik.http.routers.redis.rule=Host(` + "`redis.example.com`" + `)"
+    ]
+  }
+]
+`
+
+const redisMetaAlloc = `
+{
+  "ID": "07501480-8175-8071-7da6-133bd1ff890f",
+  "Namespace": "default",
+  "JobID": "echo",
+  "TaskGroup": "group",
+  "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+  "NodeName": "worker-1",
+  "Job": {
+    "ID": "echo",
+    "Name": "echo",
+    "Meta": {
+      "traefik.enable": "true",
+      "owner": "team-a"
+    },
+    "TaskGroups": [
+      {
+        "Name": "group",
+        "Meta": {
+          "traefik.http.routers.redis.entrypoints": "web"
+        },
+        "Services": [
+          {
+            "Name": "redis",
+            "Meta": {
+              "traefik.http.routers.redis.rule": "Host(` + "`meta.example.com`" + `)"
+            }
+          }
+        ]
+      }
+    ]
+  }
+}
+`
+
+const servicesVersions = `
+[
+  {
+    "Namespace": "default",
+    "Services": [
+      {
+        "ServiceName": "versions",
+        "Tags": [
+          "traefik.enable=true",
+          "traefik.nomad.versionweights=3:90,4:10"
+        ]
+      }
+    ]
+  }
+]
+`
+
+const versions = `
+[
+  {
+    "Address": "127.0.0.1",
+    "AllocID": "6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01",
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01-group-versions-versions-http",
+    "JobID": "versions",
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 25123,
+    "ServiceName": "versions",
+    "Tags": [
+      "traefik.enable=true"
+    ]
+  },
+  {
+    "Address": "127.0.0.1",
+    "AllocID": "6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02",
+    "Datacenter": "dc1",
+    "ID": "_nomad-task-6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02-group-versions-versions-http",
+    "JobID": "versions",
+    "Namespace": "default",
+    "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+    "Port": 25124,
+    "ServiceName": "versions",
+    "Tags": [
+      "traefik.enable=true",
+      "traefik.nomad.versionweights=3:90,4:10"
+    ]
+  }
+]
+`
+
+const versionsAllocV3 = `
+{
+  "ID": "6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01",
+  "Namespace": "default",
+  "JobID": "versions",
+  "Job": {
+    "ID": "versions",
+    "Version": 3
+  }
+}
+`
+
+const versionsAllocV4 = `
+{
+  "ID": "6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02",
+  "Namespace": "default",
+  "JobID": "versions",
+  "Job": {
+    "ID": "versions",
+    "Version": 4
+  }
+}
+`
+
+const consulAllocs = `
+[
+  {
+    "ID": "9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e01",
+    "Namespace": "default",
+    "JobID": "shop",
+    "JobVersion": 2,
+    "TaskGroup": "web",
+    "ClientStatus": "running"
+  },
+  {
+    "ID": "9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e02",
+    "Namespace": "default",
+    "JobID": "shop",
+    "JobVersion": 2,
+    "TaskGroup": "batch",
+    "ClientStatus": "running"
+  },
+  {
+    "ID": "9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e03",
+    "Namespace": "default",
+    "JobID": "shop",
+    "JobVersion": 2,
+    "TaskGroup": "batch",
+    "ClientStatus": "running"
+  },
+  {
+    "ID": "9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e04",
+    "Namespace": "default",
+    "JobID": "shop",
+    "JobVersion": 1,
+    "TaskGroup": "web",
+    "ClientStatus": "complete"
+  }
+]
+`
+
+const consulWebAlloc = `
+{
+  "ID": "9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e01",
+  "Namespace": "default",
+  "JobID": "shop",
+  "TaskGroup": "web",
+  "NodeID": "6d7f412e-e7ff-2e66-d47b-867b0e9d8726",
+  "AllocatedResources": {
+    "Shared": {
+      "Ports": [
+        {
+          "Label": "db",
+          "Value": 27017,
+          "HostIP": "10.0.0.7"
+        }
+      ]
+    }
+  },
+  "Job": {
+    "ID": "shop",
+    "Name": "shop",
+    "Datacenters": ["dc1"],
+    "TaskGroups": [
+      {
+        "Name": "web",
+        "Services": [
+          {
+            "Name": "${TASKGROUP}",
+            "PortLabel": "db",
+            "Provider": "consul",
+            "Tags": ["traefik.enable=true"]
+          },
+          {
+            "Name": "internal",
+            "PortLabel": "db",
+            "Provider": "nomad",
+            "Tags": ["traefik.enable=true"]
+          }
+        ]
+      }
+    ]
+  }
+}
+`
+
+const consulBatchAlloc = `
+{
+  "ID": "9c0e7a51-1f5b-4c2b-8a7d-2f4b6c1d3e02",
+  "Namespace": "default",
+  "JobID": "shop",
+  "TaskGroup": "batch",
+  "Job": {
+    "ID": "shop",
+    "Name": "shop",
+    "TaskGroups": [
+      {
+        "Name": "batch"
+      }
+    ]
+  }
+}
 `
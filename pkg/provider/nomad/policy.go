@@ -0,0 +1,99 @@
+package nomad
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/logs"
+)
+
+// NamespacePolicy holds the entrypoints and middlewares the routers of a Nomad namespace are allowed to use.
+type NamespacePolicy struct {
+	EntryPoints []string `description:"Entrypoints the routers are allowed to be bound to. All the entrypoints are allowed when empty." json:"entryPoints,omitempty" toml:"entryPoints,omitempty" yaml:"entryPoints,omitempty" export:"true"`
+	Middlewares []string `description:"Middlewares the routers are allowed to reference. All the middlewares are allowed when empty." json:"middlewares,omitempty" toml:"middlewares,omitempty" yaml:"middlewares,omitempty" export:"true"`
+}
+
+// applyNamespacePolicy restricts the routers of an item to the entrypoints and middlewares allowed for its namespace.
+// The disallowed entrypoints are removed from the routers, and the routers without entrypoints get the allowed ones.
+// The routers left without any entrypoint, or referencing a disallowed middleware, are removed,
+// rather than being exposed without a middleware they rely on.
+func (p *Provider) applyNamespacePolicy(ctx context.Context, i item, configuration *dynamic.Configuration) {
+	policy, ok := p.NamespacePolicies[i.Namespace]
+	if !ok || policy == nil {
+		return
+	}
+
+	logger := log.Ctx(ctx).With().Str("namespace", i.Namespace).Logger()
+
+	for name, router := range configuration.HTTP.Routers {
+		entryPoints, allowed := policy.check(router.EntryPoints, router.Middlewares)
+		if !allowed {
+			logger.Error().Str(logs.RouterName, name).Msg("Router not allowed by the namespace policy")
+			delete(configuration.HTTP.Routers, name)
+			continue
+		}
+
+		router.EntryPoints = entryPoints
+	}
+
+	for name, router := range configuration.TCP.Routers {
+		entryPoints, allowed := policy.check(router.EntryPoints, router.Middlewares)
+		if !allowed {
+			logger.Error().Str(logs.RouterName, name).Msg("TCP router not allowed by the namespace policy")
+			delete(configuration.TCP.Routers, name)
+			continue
+		}
+
+		router.EntryPoints = entryPoints
+	}
+
+	for name, router := range configuration.UDP.Routers {
+		entryPoints, allowed := policy.check(router.EntryPoints, nil)
+		if !allowed {
+			logger.Error().Str(logs.RouterName, name).Msg("UDP router not allowed by the namespace policy")
+			delete(configuration.UDP.Routers, name)
+			continue
+		}
+
+		router.EntryPoints = entryPoints
+	}
+}
+
+// check returns the allowed entrypoints of a router, and whether the router is allowed at all.
+func (n *NamespacePolicy) check(entryPoints, middlewares []string) ([]string, bool) {
+	if len(n.Middlewares) > 0 {
+		for _, middleware := range middlewares {
+			if !contains(n.Middlewares, middleware) {
+				return nil, false
+			}
+		}
+	}
+
+	if len(n.EntryPoints) == 0 {
+		return entryPoints, true
+	}
+
+	if len(entryPoints) == 0 {
+		return append([]string(nil), n.EntryPoints...), true
+	}
+
+	var allowed []string
+	for _, entryPoint := range entryPoints {
+		if contains(n.EntryPoints, entryPoint) {
+			allowed = append(allowed, entryPoint)
+		}
+	}
+
+	return allowed, len(allowed) > 0
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,143 @@
+package nomad
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// pressureMaxWeight is the weight of the servers whose allocations are below the pressure threshold.
+const pressureMaxWeight = 100
+
+// ResourcePressure holds the configuration of the weighting of the HTTP servers
+// after the CPU and memory utilization of their allocations.
+type ResourcePressure struct {
+	Threshold int `description:"Utilization of the CPU or memory limit of an allocation, in percent, above which the weight of its servers is reduced." json:"threshold,omitempty" toml:"threshold,omitempty" yaml:"threshold,omitempty" export:"true"`
+	MinWeight int `description:"Weight of the servers of the allocations using all their CPU or memory limit, relative to a weight of 100 below the threshold." json:"minWeight,omitempty" toml:"minWeight,omitempty" yaml:"minWeight,omitempty" export:"true"`
+}
+
+// SetDefaults sets the default values of the resource pressure.
+func (r *ResourcePressure) SetDefaults() {
+	r.Threshold = 80
+	r.MinWeight = 10
+}
+
+func (r *ResourcePressure) init() error {
+	if r.Threshold < 0 || r.Threshold >= 100 {
+		return fmt.Errorf("invalid threshold %d, must be between 0 and 99", r.Threshold)
+	}
+
+	if r.MinWeight < 0 || r.MinWeight > pressureMaxWeight {
+		return fmt.Errorf("invalid minimum weight %d, must be between 0 and %d", r.MinWeight, pressureMaxWeight)
+	}
+
+	return nil
+}
+
+// weight returns the weight of the servers of an allocation with the given utilization, in percent:
+// the weight decreases linearly from 100 at the threshold, to the minimum weight when all the limit is used.
+func (r *ResourcePressure) weight(utilization float64) int {
+	if utilization <= float64(r.Threshold) {
+		return pressureMaxWeight
+	}
+
+	if utilization >= 100 {
+		return r.MinWeight
+	}
+
+	ratio := (utilization - float64(r.Threshold)) / float64(100-r.Threshold)
+	return pressureMaxWeight - int(math.Round(ratio*float64(pressureMaxWeight-r.MinWeight)))
+}
+
+// getAllocationUtilization returns the highest utilization of the CPU and memory limits of the allocation, in percent.
+// The stats are served by the Nomad client running the allocation, through the servers.
+func (p *Provider) getAllocationUtilization(ctx context.Context, client *api.Client, cache map[string]float64, alloc *api.Allocation) (float64, error) {
+	if utilization, ok := cache[alloc.ID]; ok {
+		return utilization, nil
+	}
+
+	opts := &api.QueryOptions{AllowStale: p.Stale}
+	opts = opts.WithContext(ctx)
+
+	stats, err := client.Allocations().Stats(alloc, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch stats of allocation %s: %w", alloc.ID, err)
+	}
+
+	utilization, err := allocationUtilization(alloc, stats)
+	if err != nil {
+		return 0, fmt.Errorf("allocation %s: %w", alloc.ID, err)
+	}
+
+	cache[alloc.ID] = utilization
+
+	return utilization, nil
+}
+
+// allocationUtilization returns the highest utilization of the CPU and memory limits of the allocation, in percent.
+// The memory limit is the maximum memory of the tasks when oversubscribed, and the memory usage is the resident set size,
+// or the total usage when the resident set size is not measured, as with the cgroups v2.
+func allocationUtilization(alloc *api.Allocation, stats *api.AllocResourceUsage) (float64, error) {
+	if alloc.AllocatedResources == nil || stats == nil || stats.ResourceUsage == nil {
+		return 0, errors.New("resources or stats missing")
+	}
+
+	var cpuMHz, memoryMB int64
+	for _, task := range alloc.AllocatedResources.Tasks {
+		if task == nil {
+			continue
+		}
+
+		cpuMHz += task.Cpu.CpuShares
+
+		if task.Memory.MemoryMaxMB > task.Memory.MemoryMB {
+			memoryMB += task.Memory.MemoryMaxMB
+		} else {
+			memoryMB += task.Memory.MemoryMB
+		}
+	}
+
+	var utilization float64
+
+	if cpu := stats.ResourceUsage.CpuStats; cpu != nil && cpuMHz > 0 {
+		utilization = math.Max(utilization, 100*cpu.TotalTicks/float64(cpuMHz))
+	}
+
+	if memory := stats.ResourceUsage.MemoryStats; memory != nil && memoryMB > 0 {
+		used := memory.RSS
+		if used == 0 {
+			used = memory.Usage
+		}
+		utilization = math.Max(utilization, 100*float64(used)/float64(memoryMB<<20))
+	}
+
+	return utilization, nil
+}
+
+// addResourcePressure weights the HTTP servers of an item after the utilization of its allocation.
+// All the servers are weighted, so that the weights of the merged servers of a service are relative to one another,
+// and the servers of the draining items keep their zero weight.
+func (p *Provider) addResourcePressure(i item, configuration *dynamic.HTTPConfiguration) {
+	if p.ResourcePressure == nil {
+		return
+	}
+
+	weight := p.ResourcePressure.weight(i.Utilization)
+
+	for _, service := range configuration.Services {
+		if service.LoadBalancer == nil {
+			continue
+		}
+
+		for j := range service.LoadBalancer.Servers {
+			server := &service.LoadBalancer.Servers[j]
+			if server.Weight == nil {
+				server.Weight = intPtr(weight)
+			}
+		}
+	}
+}
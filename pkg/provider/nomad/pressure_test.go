@@ -0,0 +1,268 @@
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+func TestResourcePressure_weight(t *testing.T) {
+	pressure := &ResourcePressure{}
+	pressure.SetDefaults()
+
+	testCases := []struct {
+		utilization float64
+		expected    int
+	}{
+		{utilization: 0, expected: 100},
+		{utilization: 80, expected: 100},
+		{utilization: 90, expected: 55},
+		{utilization: 99, expected: 14},
+		{utilization: 100, expected: 10},
+		{utilization: 150, expected: 10},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, pressure.weight(test.utilization), "utilization %v", test.utilization)
+	}
+}
+
+func TestResourcePressure_init(t *testing.T) {
+	assert.NoError(t, (&ResourcePressure{Threshold: 80, MinWeight: 10}).init())
+	assert.NoError(t, (&ResourcePressure{}).init())
+	assert.Error(t, (&ResourcePressure{Threshold: 100}).init())
+	assert.Error(t, (&ResourcePressure{Threshold: -1}).init())
+	assert.Error(t, (&ResourcePressure{MinWeight: 101}).init())
+}
+
+func Test_allocationUtilization(t *testing.T) {
+	alloc := &api.Allocation{
+		AllocatedResources: &api.AllocatedResources{
+			Tasks: map[string]*api.AllocatedTaskResources{
+				"web":     {Cpu: api.AllocatedCpuResources{CpuShares: 500}, Memory: api.AllocatedMemoryResources{MemoryMB: 256}},
+				"sidecar": {Cpu: api.AllocatedCpuResources{CpuShares: 500}, Memory: api.AllocatedMemoryResources{MemoryMB: 128, MemoryMaxMB: 256}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc        string
+		alloc       *api.Allocation
+		stats       *api.AllocResourceUsage
+		expected    float64
+		expectedErr bool
+	}{
+		{
+			desc:  "CPU bound",
+			alloc: alloc,
+			stats: &api.AllocResourceUsage{ResourceUsage: &api.ResourceUsage{
+				CpuStats:    &api.CpuStats{TotalTicks: 900},
+				MemoryStats: &api.MemoryStats{RSS: 128 << 20},
+			}},
+			expected: 90,
+		},
+		{
+			desc:  "memory bound, with the maximum memory of the oversubscribed task",
+			alloc: alloc,
+			stats: &api.AllocResourceUsage{ResourceUsage: &api.ResourceUsage{
+				CpuStats:    &api.CpuStats{TotalTicks: 100},
+				MemoryStats: &api.MemoryStats{RSS: 384 << 20},
+			}},
+			expected: 75,
+		},
+		{
+			desc:  "memory usage when the resident set size is not measured",
+			alloc: alloc,
+			stats: &api.AllocResourceUsage{ResourceUsage: &api.ResourceUsage{
+				MemoryStats: &api.MemoryStats{Usage: 256 << 20},
+			}},
+			expected: 50,
+		},
+		{
+			desc:        "resources missing",
+			alloc:       &api.Allocation{},
+			stats:       &api.AllocResourceUsage{ResourceUsage: &api.ResourceUsage{}},
+			expectedErr: true,
+		},
+		{
+			desc:        "stats missing",
+			alloc:       alloc,
+			stats:       &api.AllocResourceUsage{},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			utilization, err := allocationUtilization(test.alloc, test.stats)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.InDelta(t, test.expected, utilization, 0.001)
+		})
+	}
+}
+
+func Test_buildConfig_resourcePressure(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	p.DefaultRule = "Host(`{{ normalize .Name }}.traefik.test`)"
+	p.ResourcePressure = &ResourcePressure{Threshold: 80, MinWeight: 10}
+	err := p.Init()
+	require.NoError(t, err)
+
+	newItem := func(id string, port int, utilization float64, draining bool) item {
+		return item{
+			ID:          id,
+			Name:        "Test",
+			Namespace:   "ns1",
+			Address:     "127.0.0.1",
+			Port:        port,
+			Utilization: utilization,
+			Draining:    draining,
+			ExtraConf:   p.getExtraConf(nil),
+		}
+	}
+
+	items := []item{
+		newItem("id1", 9991, 20, false),
+		newItem("id2", 9992, 90, false),
+		newItem("id3", 9993, 95, true),
+	}
+
+	c := p.buildConfig(context.TODO(), items)
+
+	require.Contains(t, c.HTTP.Services, "Test")
+	require.NotNil(t, c.HTTP.Services["Test"].LoadBalancer)
+
+	weights := make(map[string]int)
+	for _, server := range c.HTTP.Services["Test"].LoadBalancer.Servers {
+		require.NotNil(t, server.Weight)
+		weights[server.URL] = *server.Weight
+	}
+
+	// the draining server keeps its zero weight.
+	assert.Equal(t, map[string]int{
+		"http://127.0.0.1:9991": 100,
+		"http://127.0.0.1:9992": 55,
+		"http://127.0.0.1:9993": 0,
+	}, weights)
+}
+
+func Test_buildConfig_noResourcePressure(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	err := p.Init()
+	require.NoError(t, err)
+
+	items := []item{{ID: "id1", Name: "Test", Address: "127.0.0.1", Port: 9999, Utilization: 95, ExtraConf: p.getExtraConf(nil)}}
+
+	c := p.buildConfig(context.TODO(), items)
+
+	require.Contains(t, c.HTTP.Services, "Test")
+	assert.Equal(t, []dynamic.Server{{URL: "http://127.0.0.1:9999"}}, c.HTTP.Services["Test"].LoadBalancer.Servers)
+}
+
+func Test_getNomadServiceData_resourcePressure(t *testing.T) {
+	var statsRequests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/services":
+			_, _ = w.Write([]byte(servicesVersions))
+		case "/v1/service/versions":
+			_, _ = w.Write([]byte(versions))
+		case "/v1/allocation/6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01":
+			_, _ = w.Write([]byte(pressureAlloc1))
+		case "/v1/allocation/6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02":
+			_, _ = w.Write([]byte(pressureAlloc2))
+		case "/v1/client/allocation/6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01/stats":
+			statsRequests++
+			_, _ = w.Write([]byte(pressureStats1))
+		case "/v1/client/allocation/6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02/stats":
+			statsRequests++
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.ResourcePressure = &ResourcePressure{}
+	p.ResourcePressure.SetDefaults()
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	items, err := p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	assert.Equal(t, 2, statsRequests)
+
+	utilizations := make(map[string]float64)
+	for _, i := range items {
+		utilizations[i.AllocID] = i.Utilization
+	}
+
+	// the instance whose stats cannot be fetched is kept, with no utilization.
+	assert.Equal(t, map[string]float64{
+		"6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01": 90,
+		"6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02": 0,
+	}, utilizations)
+}
+
+const pressureAlloc1 = `
+{
+  "ID": "6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a01",
+  "Namespace": "default",
+  "JobID": "versions",
+  "AllocatedResources": {
+    "Tasks": {
+      "web": {
+        "Cpu": {"CpuShares": 500},
+        "Memory": {"MemoryMB": 256}
+      }
+    }
+  }
+}
+`
+
+const pressureAlloc2 = `
+{
+  "ID": "6e1a7f7e-3c1b-4b0e-9d3a-0c6d0c1f0a02",
+  "Namespace": "default",
+  "JobID": "versions",
+  "AllocatedResources": {
+    "Tasks": {
+      "web": {
+        "Cpu": {"CpuShares": 500},
+        "Memory": {"MemoryMB": 256}
+      }
+    }
+  }
+}
+`
+
+const pressureStats1 = `
+{
+  "ResourceUsage": {
+    "CpuStats": {"TotalTicks": 450},
+    "MemoryStats": {"RSS": 67108864}
+  }
+}
+`
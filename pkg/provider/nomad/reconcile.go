@@ -0,0 +1,110 @@
+package nomad
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+)
+
+// nextReconcile returns the time of the next reconciliation of the services,
+// the zero time when the reconciliations are disabled.
+// A reconciliation is only due when no configuration was loaded within the reconcile interval.
+func (p *Provider) nextReconcile(lastLoad time.Time) time.Time {
+	if p.ReconcileInterval <= 0 {
+		return time.Time{}
+	}
+
+	return lastLoad.Add(time.Duration(p.ReconcileInterval))
+}
+
+// reconcileTimer returns a channel receiving at the deadline, and a function stopping the timer.
+// The channel is nil, and never receives, when the deadline is zero.
+func reconcileTimer(deadline time.Time) (<-chan time.Time, func()) {
+	if deadline.IsZero() {
+		return nil, func() {}
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	return timer.C, func() { timer.Stop() }
+}
+
+// watchServicesUntil watches the services like watchServices, until the deadline when it is not zero,
+// and reports whether the deadline elapsed before a blocking query returned.
+// The deadline does not depend on the Nomad servers, so that a blocking query which hangs is abandoned.
+func (p *Provider) watchServicesUntil(ctx context.Context, deadline time.Time) bool {
+	if deadline.IsZero() {
+		p.watchServices(ctx)
+		return false
+	}
+
+	ctxWatch, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	returned := p.watchServices(ctxWatch)
+
+	return !returned && ctx.Err() == nil
+}
+
+// reconcile compares the services listed by the Nomad API with the ones of the last loaded configuration,
+// and loads the configuration again when they drifted.
+func (p *Provider) reconcile(ctx context.Context, configurationC chan<- dynamic.Message) error {
+	p.rotateToken(ctx)
+
+	items, err := p.getNomadServiceData(ctx)
+	if err != nil {
+		p.recordSync(err)
+		return err
+	}
+
+	items = p.holdEmpty(ctx, items)
+
+	if itemsDigest(items) != p.lastDigest {
+		log.Ctx(ctx).Warn().Msg("The Nomad services drifted from the loaded ones, loading their configuration")
+
+		if p.metricsRegistry != nil {
+			p.metricsRegistry.ProviderDriftCounter().With("provider", p.name).Add(1)
+		}
+
+		p.applyConfiguration(ctx, configurationC, items)
+		return nil
+	}
+
+	// the items within their drain window are removed from the configuration once it elapses.
+	if len(p.draining) > 0 {
+		p.applyConfiguration(ctx, configurationC, items)
+		return nil
+	}
+
+	log.Ctx(ctx).Debug().Msg("The Nomad services did not drift from the loaded ones")
+	p.recordSync(nil)
+	return nil
+}
+
+// itemsDigest returns a digest of the registrations of the items, independent of their order.
+// The utilization of the allocations is left out, as it changes without the services changing.
+func itemsDigest(items []item) string {
+	lines := make([]string, 0, len(items))
+	for _, i := range items {
+		var jobVersion string
+		if i.JobVersion != nil {
+			jobVersion = fmt.Sprint(*i.JobVersion)
+		}
+
+		lines = append(lines, fmt.Sprintf("%q %q %q %q %q %q %q %s %q %d %v %q %t",
+			i.ID, i.Name, i.Namespace, i.Job, i.Node, i.Datacenter, i.AllocID, jobVersion, i.Address, i.Port, i.Ports, i.Tags, i.Draining))
+	}
+	sort.Strings(lines)
+
+	hash := sha256.New()
+	for _, line := range lines {
+		_, _ = fmt.Fprintln(hash, line)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
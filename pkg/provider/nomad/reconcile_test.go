@@ -0,0 +1,152 @@
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+	"github.com/traefik/traefik/v3/pkg/testhelpers"
+)
+
+type collectingDriftMetrics struct {
+	metrics.Registry
+	drift *testhelpers.CollectingCounter
+}
+
+func (m *collectingDriftMetrics) ProviderDriftCounter() gokitmetrics.Counter {
+	return m.drift
+}
+
+func TestProvider_reconcile(t *testing.T) {
+	var port atomic.Value
+	port.Store("30826")
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/v1/services"):
+			_, _ = w.Write([]byte(servicesRedis))
+		case strings.HasSuffix(r.URL.Path, "/v1/service/redis"):
+			_, _ = w.Write([]byte(strings.Replace(redis, "30826", port.Load().(string), 1)))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.ReconcileInterval = ptypes.Duration(time.Minute)
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	registry := &collectingDriftMetrics{
+		Registry: metrics.NewVoidRegistry(),
+		drift:    &testhelpers.CollectingCounter{},
+	}
+	p.SetMetricsRegistry(registry)
+
+	configurationC := make(chan dynamic.Message, 3)
+
+	err = p.loadConfiguration(context.Background(), configurationC)
+	require.NoError(t, err)
+	require.Len(t, configurationC, 1)
+	<-configurationC
+
+	err = p.reconcile(context.Background(), configurationC)
+	require.NoError(t, err)
+	assert.Empty(t, configurationC, "the configuration is not loaded again when the services did not drift")
+	assert.Zero(t, registry.drift.CounterValue)
+
+	port.Store("30827")
+
+	err = p.reconcile(context.Background(), configurationC)
+	require.NoError(t, err)
+	require.Len(t, configurationC, 1)
+	assert.Equal(t, 1.0, registry.drift.CounterValue)
+	assert.Equal(t, []string{"provider", p.name}, registry.drift.LastLabelValues)
+
+	message := <-configurationC
+	service := message.Configuration.HTTP.Services["redis"]
+	require.NotNil(t, service)
+	require.NotNil(t, service.LoadBalancer)
+	require.Len(t, service.LoadBalancer.Servers, 1)
+	assert.Equal(t, "http://127.0.0.1:30827", service.LoadBalancer.Servers[0].URL)
+
+	err = p.reconcile(context.Background(), configurationC)
+	require.NoError(t, err)
+	assert.Empty(t, configurationC, "the drifted services are the loaded ones once reconciled")
+	assert.Equal(t, 1.0, registry.drift.CounterValue)
+}
+
+func TestProvider_watchServicesUntil(t *testing.T) {
+	// a blocking query which never returns.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.Watch = true
+	p.RefreshInterval = ptypes.Duration(time.Hour)
+	err := p.Init()
+	require.NoError(t, err)
+
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+	p.setLastIndex(p.client, 42)
+
+	start := time.Now()
+	reconcile := p.watchServicesUntil(context.Background(), start.Add(50*time.Millisecond))
+
+	assert.True(t, reconcile)
+	assert.Less(t, time.Since(start), time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	reconcile = p.watchServicesUntil(ctx, time.Now().Add(time.Hour))
+	assert.False(t, reconcile, "a canceled watch is not reconciled")
+}
+
+func TestProvider_nextReconcile(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+
+	lastLoad := time.Now()
+	assert.True(t, p.nextReconcile(lastLoad).IsZero(), "the reconciliations are disabled by default")
+
+	p.ReconcileInterval = ptypes.Duration(time.Minute)
+	assert.Equal(t, lastLoad.Add(time.Minute), p.nextReconcile(lastLoad))
+}
+
+func Test_itemsDigest(t *testing.T) {
+	a := item{ID: "a", Name: "redis", Address: "127.0.0.1", Port: 80, Tags: []string{"traefik.enable=true"}}
+	b := item{ID: "b", Name: "redis", Address: "127.0.0.2", Port: 80, Tags: []string{"traefik.enable=true"}}
+
+	assert.Equal(t, itemsDigest([]item{a, b}), itemsDigest([]item{b, a}))
+
+	utilized := a
+	utilized.Utilization = 90
+	assert.Equal(t, itemsDigest([]item{a, b}), itemsDigest([]item{utilized, b}))
+
+	retagged := a
+	retagged.Tags = []string{"traefik.enable=false"}
+	assert.NotEqual(t, itemsDigest([]item{a, b}), itemsDigest([]item{retagged, b}))
+
+	assert.NotEqual(t, itemsDigest([]item{a, b}), itemsDigest([]item{a}))
+}
@@ -0,0 +1,89 @@
+package nomad
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// signatureTag is the name of the tag holding the signature of the Traefik tags of a service, without the prefix.
+const signatureTag = "sig"
+
+// TagsSignature holds the configuration of the verification of the signature of the Traefik tags of the services.
+type TagsSignature struct {
+	Key     string `description:"Key of the HMAC-SHA256 signature of the Traefik tags." json:"key,omitempty" toml:"key,omitempty" yaml:"key,omitempty" loggable:"false"`
+	KeyFile string `description:"Path to a file containing the key of the HMAC-SHA256 signature of the Traefik tags, it takes precedence over the key." json:"keyFile,omitempty" toml:"keyFile,omitempty" yaml:"keyFile,omitempty"`
+
+	key []byte
+}
+
+func (s *TagsSignature) init() error {
+	key := s.Key
+	if s.KeyFile != "" {
+		data, err := os.ReadFile(s.KeyFile)
+		if err != nil {
+			return fmt.Errorf("reading key file: %w", err)
+		}
+		key = strings.TrimSpace(string(data))
+	}
+
+	if key == "" {
+		return errors.New("the key is required")
+	}
+
+	s.key = []byte(key)
+	return nil
+}
+
+// sign returns the hex encoded HMAC-SHA256 of the Traefik tags, sorted and separated by new lines,
+// the signature tag being excluded.
+func (s *TagsSignature) sign(tags []string, prefix string) string {
+	var signed []string
+	for _, tag := range tags {
+		if strings.HasPrefix(tag, prefix+".") && !isSignatureTag(tag, prefix) {
+			signed = append(signed, tag)
+		}
+	}
+	sort.Strings(signed)
+
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strings.Join(signed, "\n")))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyTags checks that the signature tag of the item matches its Traefik tags.
+func (p *Provider) verifyTags(i item) error {
+	if p.TagsSignature == nil {
+		return nil
+	}
+
+	var signature string
+	for _, tag := range i.Tags {
+		if isSignatureTag(tag, p.Prefix) {
+			_, value, _ := strings.Cut(tag, "=")
+			signature = strings.TrimSpace(value)
+		}
+	}
+
+	if signature == "" {
+		return errors.New("the tags are not signed")
+	}
+
+	expected := p.TagsSignature.sign(i.Tags, p.Prefix)
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(expected)) {
+		return errors.New("invalid tags signature")
+	}
+
+	return nil
+}
+
+func isSignatureTag(tag, prefix string) bool {
+	key, _, found := strings.Cut(tag, "=")
+	return found && strings.TrimSpace(key) == prefix+"."+signatureTag
+}
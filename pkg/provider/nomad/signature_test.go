@@ -0,0 +1,140 @@
+package nomad
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTagsSignature_init(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("file-key\n"), 0o600))
+
+	testCases := []struct {
+		desc        string
+		signature   TagsSignature
+		expectedKey string
+		expectedErr string
+	}{
+		{
+			desc:        "key",
+			signature:   TagsSignature{Key: "secret"},
+			expectedKey: "secret",
+		},
+		{
+			desc:        "key file takes precedence over the key",
+			signature:   TagsSignature{Key: "secret", KeyFile: keyFile},
+			expectedKey: "file-key",
+		},
+		{
+			desc:        "missing key",
+			signature:   TagsSignature{},
+			expectedErr: "the key is required",
+		},
+		{
+			desc:        "missing key file",
+			signature:   TagsSignature{KeyFile: filepath.Join(t.TempDir(), "missing")},
+			expectedErr: "reading key file",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := test.signature.init()
+			if test.expectedErr != "" {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedKey, string(test.signature.key))
+		})
+	}
+}
+
+func TestTagsSignature_sign(t *testing.T) {
+	s := &TagsSignature{key: []byte("secret")}
+
+	tags := []string{
+		"traefik.http.routers.a.rule=Host(`a.test`)",
+		"traefik.enable=true",
+		"other=tag",
+	}
+
+	// printf 'traefik.enable=true\ntraefik.http.routers.a.rule=Host(`a.test`)' | openssl dgst -sha256 -hmac secret
+	expected := "e3b6d3557a1ad414b66a4b63722f669ae0445604e6ae5162d52881183e74cf2f"
+
+	assert.Equal(t, expected, s.sign(tags, "traefik"))
+
+	// the order of the tags, the other tags, and the signature tag itself are not signed.
+	reordered := []string{"traefik.enable=true", "traefik.sig=foo", "traefik.http.routers.a.rule=Host(`a.test`)"}
+	assert.Equal(t, expected, s.sign(reordered, "traefik"))
+}
+
+func Test_buildConfig_tagsSignature(t *testing.T) {
+	signer := &TagsSignature{key: []byte("secret")}
+	tags := []string{"traefik.http.routers.a.rule=Host(`a.test`)"}
+
+	testCases := []struct {
+		desc          string
+		tags          []string
+		expectedError string
+	}{
+		{
+			desc: "valid signature",
+			tags: append([]string{"traefik.sig=" + signer.sign(tags, "traefik")}, tags...),
+		},
+		{
+			desc:          "missing signature",
+			tags:          tags,
+			expectedError: "the tags are not signed",
+		},
+		{
+			desc:          "invalid signature",
+			tags:          append([]string{"traefik.sig=" + signer.sign(tags, "traefik")}, "traefik.http.routers.a.rule=Host(`b.test`)"),
+			expectedError: "invalid tags signature",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			p := new(Provider)
+			p.SetDefaults()
+			p.TagsSignature = &TagsSignature{Key: "secret"}
+			err := p.Init()
+			require.NoError(t, err)
+
+			items := []item{
+				{
+					ID:        "id1",
+					Name:      "Test",
+					Tags:      test.tags,
+					Address:   "127.0.0.1",
+					Port:      9999,
+					ExtraConf: p.getExtraConf(test.tags),
+				},
+			}
+
+			c := p.buildConfig(context.TODO(), items)
+
+			if test.expectedError == "" {
+				assert.Contains(t, c.HTTP.Routers, "a")
+				assert.Empty(t, p.ConfigurationErrors())
+				return
+			}
+
+			assert.Empty(t, c.HTTP.Routers)
+
+			configErrors := p.ConfigurationErrors()
+			require.Len(t, configErrors, 1)
+			assert.Equal(t, test.expectedError, configErrors[0].Message)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package nomad
+
+import "time"
+
+// Status is the state of the synchronization of the provider with the Nomad API, as reported by the diagnostics.
+// The discovery, that is reaching the Nomad API, and the configuration of the discovered services are reported apart,
+// so that invalid tags cannot be mistaken for an unavailable Nomad API.
+type Status struct {
+	Name          string    `json:"name"`
+	LastSync      time.Time `json:"lastSync,omitempty"`
+	LastError     string    `json:"lastError,omitempty"`
+	LastErrorTime time.Time `json:"lastErrorTime,omitempty"`
+	// Degraded reports whether the Nomad API cannot be reached, the last known configuration being kept.
+	Degraded      bool      `json:"degraded"`
+	DegradedSince time.Time `json:"degradedSince,omitempty"`
+	Services      int       `json:"services"`
+	// ConfigurationErrors is the number of service instances whose configuration cannot be applied on the last refresh.
+	ConfigurationErrors int `json:"configurationErrors"`
+	// ConfigurationDegraded reports whether some service instances have a configuration which cannot be applied.
+	ConfigurationDegraded      bool      `json:"configurationDegraded"`
+	ConfigurationDegradedSince time.Time `json:"configurationDegradedSince,omitempty"`
+}
+
+// Status returns the state of the synchronization of the provider with the Nomad API.
+func (p *Provider) Status() Status {
+	p.statusMu.RLock()
+	status := p.status
+	p.statusMu.RUnlock()
+
+	status.Name = p.name
+	status.Services = len(p.DiscoveredServices())
+	status.ConfigurationErrors = len(p.ConfigurationErrors())
+
+	return status
+}
+
+// recordSync records the outcome of a refresh of the services.
+// The provider is degraded, serving its last known configuration, from the first failed refresh until the next successful one.
+func (p *Provider) recordSync(err error) {
+	p.statusMu.Lock()
+	defer p.statusMu.Unlock()
+
+	now := time.Now()
+
+	if p.metricsRegistry != nil {
+		discoveryUp := 1.0
+		if err != nil {
+			discoveryUp = 0
+		}
+		p.metricsRegistry.ProviderDiscoveryUpGauge().With("provider", p.name).Set(discoveryUp)
+	}
+
+	if err != nil {
+		p.status.LastError = err.Error()
+		p.status.LastErrorTime = now
+
+		if !p.status.Degraded {
+			p.status.Degraded = true
+			p.status.DegradedSince = now
+		}
+		return
+	}
+
+	p.status.LastSync = now
+	p.status.Degraded = false
+	p.status.DegradedSince = time.Time{}
+}
+
+// recordConfiguration records the number of configuration errors of the last built configuration.
+// The provider configuration is degraded from the first configuration with errors until the next one without.
+func (p *Provider) recordConfiguration(configErrors int) {
+	p.statusMu.Lock()
+	defer p.statusMu.Unlock()
+
+	if p.metricsRegistry != nil {
+		p.metricsRegistry.ProviderConfigErrorsGauge().With("provider", p.name).Set(float64(configErrors))
+	}
+
+	if configErrors == 0 {
+		p.status.ConfigurationDegraded = false
+		p.status.ConfigurationDegradedSince = time.Time{}
+		return
+	}
+
+	if !p.status.ConfigurationDegraded {
+		p.status.ConfigurationDegraded = true
+		p.status.ConfigurationDegradedSince = time.Now()
+	}
+}
@@ -0,0 +1,102 @@
+package nomad
+
+import (
+	"errors"
+	"testing"
+
+	gokitmetrics "github.com/go-kit/kit/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+	"github.com/traefik/traefik/v3/pkg/testhelpers"
+)
+
+func TestProvider_Status(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	err := p.Init()
+	require.NoError(t, err)
+
+	p.recordSync(errors.New("connection refused"))
+
+	status := p.Status()
+	assert.Equal(t, "connection refused", status.LastError)
+	assert.False(t, status.LastErrorTime.IsZero())
+	assert.True(t, status.LastSync.IsZero())
+	assert.True(t, status.Degraded)
+	assert.Equal(t, status.LastErrorTime, status.DegradedSince)
+
+	p.recordSync(errors.New("connection reset"))
+
+	degradedSince := status.DegradedSince
+	status = p.Status()
+	assert.Equal(t, "connection reset", status.LastError)
+	assert.Equal(t, degradedSince, status.DegradedSince, "the provider is degraded since the first failed refresh")
+
+	p.recordSync(nil)
+
+	status = p.Status()
+	assert.False(t, status.LastSync.IsZero())
+	assert.False(t, status.Degraded)
+	assert.True(t, status.DegradedSince.IsZero())
+	assert.Equal(t, "connection reset", status.LastError, "the last error is kept for the diagnostics")
+}
+
+type collectingProviderMetrics struct {
+	metrics.Registry
+	discoveryUp  *testhelpers.CollectingGauge
+	configErrors *testhelpers.CollectingGauge
+}
+
+func (m *collectingProviderMetrics) ProviderDiscoveryUpGauge() gokitmetrics.Gauge {
+	return m.discoveryUp
+}
+
+func (m *collectingProviderMetrics) ProviderConfigErrorsGauge() gokitmetrics.Gauge {
+	return m.configErrors
+}
+
+func TestProvider_Status_configuration(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	err := p.Init()
+	require.NoError(t, err)
+
+	registry := &collectingProviderMetrics{
+		Registry:     metrics.NewVoidRegistry(),
+		discoveryUp:  &testhelpers.CollectingGauge{},
+		configErrors: &testhelpers.CollectingGauge{},
+	}
+	p.SetMetricsRegistry(registry)
+
+	p.recordSync(nil)
+	p.setConfigurationErrors([]ConfigurationError{{ServiceName: "a"}, {ServiceName: "b"}})
+
+	status := p.Status()
+	assert.False(t, status.Degraded, "invalid tags do not degrade the discovery")
+	assert.True(t, status.ConfigurationDegraded)
+	assert.False(t, status.ConfigurationDegradedSince.IsZero())
+	assert.Equal(t, 2, status.ConfigurationErrors)
+
+	assert.Equal(t, 1.0, registry.discoveryUp.GaugeValue)
+	assert.Equal(t, 2.0, registry.configErrors.GaugeValue)
+	assert.Equal(t, []string{"provider", p.name}, registry.configErrors.LastLabelValues)
+
+	p.recordSync(errors.New("connection refused"))
+
+	status = p.Status()
+	assert.True(t, status.Degraded)
+	assert.True(t, status.ConfigurationDegraded, "the last configuration is kept while the Nomad API is unavailable")
+	assert.Equal(t, 0.0, registry.discoveryUp.GaugeValue)
+	assert.Equal(t, 2.0, registry.configErrors.GaugeValue)
+
+	p.recordSync(nil)
+	p.setConfigurationErrors(nil)
+
+	status = p.Status()
+	assert.False(t, status.Degraded)
+	assert.False(t, status.ConfigurationDegraded)
+	assert.True(t, status.ConfigurationDegradedSince.IsZero())
+	assert.Equal(t, 1.0, registry.discoveryUp.GaugeValue)
+	assert.Equal(t, 0.0, registry.configErrors.GaugeValue)
+}
@@ -1,6 +1,7 @@
 package nomad
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -17,3 +18,38 @@ func tagsToLabels(tags []string, prefix string) map[string]string {
 	}
 	return labels
 }
+
+// hasPortLabel reports whether any of the server port labels references
+// a Nomad port label rather than a port number.
+func hasPortLabel(labels map[string]string) bool {
+	for key, value := range labels {
+		if !strings.HasSuffix(strings.ToLower(key), ".loadbalancer.server.port") || value == "" {
+			continue
+		}
+		if _, err := strconv.Atoi(value); err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasUDPLabels reports whether the labels define a UDP router or service.
+func hasUDPLabels(labels map[string]string) bool {
+	for key := range labels {
+		if strings.HasPrefix(strings.ToLower(key), "traefik.udp.") {
+			return true
+		}
+	}
+	return false
+}
+
+// tagsToMap parses all the tags as key=value pairs, for use in the default rule template.
+// Tags without a value are mapped to an empty string.
+func tagsToMap(tags []string) map[string]string {
+	m := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		key, value, _ := strings.Cut(tag, "=")
+		m[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return m
+}
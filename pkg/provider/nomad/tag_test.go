@@ -107,3 +107,150 @@ func Test_tagsToLabels(t *testing.T) {
 		})
 	}
 }
+
+func Test_hasPortLabel(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		labels   map[string]string
+		expected bool
+	}{
+		{
+			desc:     "no labels",
+			labels:   map[string]string{},
+			expected: false,
+		},
+		{
+			desc: "numeric port",
+			labels: map[string]string{
+				"traefik.http.services.foo.loadbalancer.server.port": "8080",
+			},
+			expected: false,
+		},
+		{
+			desc: "empty port",
+			labels: map[string]string{
+				"traefik.http.services.foo.loadbalancer.server.port": "",
+			},
+			expected: false,
+		},
+		{
+			desc: "port label",
+			labels: map[string]string{
+				"traefik.http.services.foo.loadbalancer.server.port": "http",
+			},
+			expected: true,
+		},
+		{
+			desc: "tcp port label with mixed case key",
+			labels: map[string]string{
+				"traefik.tcp.services.foo.LoadBalancer.Server.Port": "db",
+			},
+			expected: true,
+		},
+		{
+			desc: "healthcheck port is not a server port",
+			labels: map[string]string{
+				"traefik.http.services.foo.loadbalancer.healthcheck.port": "http",
+			},
+			expected: false,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, hasPortLabel(test.labels))
+		})
+	}
+}
+
+func Test_hasUDPLabels(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		labels   map[string]string
+		expected bool
+	}{
+		{
+			desc:     "no labels",
+			labels:   map[string]string{},
+			expected: false,
+		},
+		{
+			desc: "http labels",
+			labels: map[string]string{
+				"traefik.http.routers.foo.rule": "Host(`foo`)",
+			},
+			expected: false,
+		},
+		{
+			desc: "udp router",
+			labels: map[string]string{
+				"traefik.udp.routers.foo.entrypoints": "dns",
+			},
+			expected: true,
+		},
+		{
+			desc: "udp service with mixed case key",
+			labels: map[string]string{
+				"traefik.UDP.services.foo.loadbalancer.server.port": "53",
+			},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, hasUDPLabels(test.labels))
+		})
+	}
+}
+
+func Test_tagsToMap(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		tags     []string
+		expected map[string]string
+	}{
+		{
+			desc:     "no tags",
+			tags:     []string{},
+			expected: map[string]string{},
+		},
+		{
+			desc: "key value tags",
+			tags: []string{
+				"traefik.enable=true",
+				"subdomain = api",
+				"rule=Host(`a=b`)",
+			},
+			expected: map[string]string{
+				"traefik.enable": "true",
+				"subdomain":      "api",
+				"rule":           "Host(`a=b`)",
+			},
+		},
+		{
+			desc: "tag without value",
+			tags: []string{
+				"public",
+			},
+			expected: map[string]string{
+				"public": "",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, test.expected, tagsToMap(test.tags))
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/safe"
+	"gopkg.in/fsnotify.v1"
+)
+
+// workloadIdentityTokenFile is the file, in the secrets directory of a Nomad task,
+// the workload identity token is written to when the identity block of the task sets file = true.
+const workloadIdentityTokenFile = "nomad_token"
+
+// tokenPath returns the path of the file containing the ACL token, empty when the token is not read from a file.
+func (e *EndpointConfig) tokenPath() string {
+	if e.TokenFile != "" || !e.WorkloadIdentity {
+		return e.TokenFile
+	}
+
+	return filepath.Join(os.Getenv("NOMAD_SECRETS_DIR"), workloadIdentityTokenFile)
+}
+
+// watchTokenFile notifies when the content of the token file changes, so that the refresh loop rotates the token
+// without waiting for the next refresh, as a short-lived token may expire in the meantime.
+// The directory of the file is watched, as the tools rotating the token usually replace the file.
+func (p *Provider) watchTokenFile(pool *safe.Pool) (<-chan struct{}, error) {
+	path := p.Endpoint.tokenPath()
+	if path == "" {
+		return nil, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("creating token file watcher: %w", err)
+	}
+
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		_ = watcher.Close()
+		return nil, fmt.Errorf("adding token file watcher: %w", err)
+	}
+
+	changed := make(chan struct{}, 1)
+	last := p.token
+
+	pool.GoCtx(func(ctx context.Context) {
+		defer watcher.Close()
+
+		logger := log.Ctx(ctx).With().Str("tokenFile", path).Logger()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-watcher.Events:
+				token, err := p.Endpoint.token()
+				if err != nil || token == "" || token == last {
+					// the file is being written or replaced, or an unrelated file of the directory changed.
+					continue
+				}
+				last = token
+
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			case err := <-watcher.Errors:
+				logger.Error().Err(err).Msg("Token file watcher error")
+			}
+		}
+	})
+
+	return changed, nil
+}
+
+// waitTokenChange returns a context which is canceled when the token changes, along with its cancel function.
+func waitTokenChange(ctx context.Context, changed <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	if changed == nil {
+		return ctx, cancel
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-changed:
+			log.Ctx(ctx).Debug().Msg("Nomad ACL token file changed")
+			cancel()
+		}
+	}()
+
+	return ctx, cancel
+}
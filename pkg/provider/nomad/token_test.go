@@ -0,0 +1,117 @@
+package nomad
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/safe"
+)
+
+func TestEndpointConfig_tokenPath(t *testing.T) {
+	t.Setenv("NOMAD_SECRETS_DIR", "/alloc/task/secrets")
+
+	testCases := []struct {
+		desc     string
+		endpoint EndpointConfig
+		expected string
+	}{
+		{
+			desc:     "no token file",
+			endpoint: EndpointConfig{Token: "token"},
+		},
+		{
+			desc:     "token file",
+			endpoint: EndpointConfig{TokenFile: "/secrets/nomad-token"},
+			expected: "/secrets/nomad-token",
+		},
+		{
+			desc:     "workload identity",
+			endpoint: EndpointConfig{WorkloadIdentity: true},
+			expected: "/alloc/task/secrets/nomad_token",
+		},
+		{
+			desc:     "token file takes precedence over the workload identity",
+			endpoint: EndpointConfig{TokenFile: "/secrets/nomad-token", WorkloadIdentity: true},
+			expected: "/secrets/nomad-token",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.endpoint.tokenPath())
+		})
+	}
+}
+
+func TestProvider_Init_workloadIdentity(t *testing.T) {
+	t.Setenv("NOMAD_SECRETS_DIR", "")
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.WorkloadIdentity = true
+
+	err := p.Init()
+	require.ErrorContains(t, err, "NOMAD_SECRETS_DIR is not set")
+}
+
+func TestProvider_watchTokenFile(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("first-token"), 0o600))
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.TokenFile = tokenFile
+	err := p.Init()
+	require.NoError(t, err)
+
+	p.token = "first-token"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := safe.NewPool(ctx)
+	t.Cleanup(func() {
+		cancel()
+		pool.Stop()
+	})
+
+	changed, err := p.watchTokenFile(pool)
+	require.NoError(t, err)
+	require.NotNil(t, changed)
+
+	// the token file is replaced, as done by the tools rotating the token.
+	tmp := filepath.Join(filepath.Dir(tokenFile), ".token.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("second-token"), 0o600))
+	require.NoError(t, os.Rename(tmp, tokenFile))
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the token change was not notified")
+	}
+
+	waitCtx, cancelWait := waitTokenChange(context.Background(), changed)
+	t.Cleanup(cancelWait)
+
+	require.NoError(t, os.WriteFile(tokenFile, []byte("third-token"), 0o600))
+
+	select {
+	case <-waitCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the wait was not interrupted by the token change")
+	}
+}
+
+func TestProvider_watchTokenFile_noTokenFile(t *testing.T) {
+	p := new(Provider)
+	p.SetDefaults()
+	err := p.Init()
+	require.NoError(t, err)
+
+	changed, err := p.watchTokenFile(safe.NewPool(context.Background()))
+	require.NoError(t, err)
+	assert.Nil(t, changed)
+}
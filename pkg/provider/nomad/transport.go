@@ -0,0 +1,63 @@
+package nomad
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/label"
+)
+
+// serversTransportLabelPrefix is the prefix of the labels configuring the transport to the servers of the HTTP services,
+// which are not part of the dynamic configuration labels.
+const serversTransportLabelPrefix = "traefik.http.serverstransport."
+
+// splitServersTransportLabels separates the labels configuring the servers transport of the service from the other labels.
+// The servers transport labels are returned without their prefix, e.g. "traefik.insecureSkipVerify".
+func splitServersTransportLabels(labels map[string]string) (map[string]string, map[string]string) {
+	var transportLabels map[string]string
+	others := make(map[string]string, len(labels))
+
+	for key, value := range labels {
+		if !strings.HasPrefix(strings.ToLower(key), serversTransportLabelPrefix) {
+			others[key] = value
+			continue
+		}
+
+		if transportLabels == nil {
+			transportLabels = make(map[string]string)
+		}
+		transportLabels["traefik."+key[len(serversTransportLabelPrefix):]] = value
+	}
+
+	return others, transportLabels
+}
+
+// addServersTransport declares the servers transport configured by the labels,
+// and makes the HTTP services of the item which do not reference a servers transport use it.
+// The transport is named after the service, so that all the instances of the service share it.
+func addServersTransport(i item, configuration *dynamic.HTTPConfiguration, transportLabels map[string]string) error {
+	if len(transportLabels) == 0 {
+		return nil
+	}
+
+	transport := &dynamic.ServersTransport{}
+	if err := label.Decode(transportLabels, transport); err != nil {
+		return fmt.Errorf("decoding servers transport: %w", err)
+	}
+
+	name := getName(i)
+
+	if configuration.ServersTransports == nil {
+		configuration.ServersTransports = make(map[string]*dynamic.ServersTransport)
+	}
+	configuration.ServersTransports[name] = transport
+
+	for _, service := range configuration.Services {
+		if service.LoadBalancer != nil && service.LoadBalancer.ServersTransport == "" {
+			service.LoadBalancer.ServersTransport = name
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package nomad
+
+import (
+	"sort"
+
+	"github.com/traefik/traefik/v3/pkg/config/label"
+)
+
+// ConfigurationError is a configuration error of a Nomad service instance, as reported by the API.
+type ConfigurationError struct {
+	ServiceName string     `json:"serviceName"`
+	ServiceID   string     `json:"serviceID"`
+	Namespace   string     `json:"namespace,omitempty"`
+	AllocID     string     `json:"allocID,omitempty"`
+	Message     string     `json:"message"`
+	Tags        []TagError `json:"tags,omitempty"`
+	// Fallback reports whether the service instance is routed with the default rule, rather than being dropped.
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// TagError is an error in the value of a Traefik tag.
+type TagError struct {
+	Tag     string `json:"tag"`
+	Value   string `json:"value"`
+	Message string `json:"message"`
+	// Suggestion is the closest known key, when the key of the tag is unknown.
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// ConfigurationErrors returns the configuration errors of the service instances discovered on the last refresh.
+func (p *Provider) ConfigurationErrors() []ConfigurationError {
+	p.configErrorsMu.RLock()
+	defer p.configErrorsMu.RUnlock()
+
+	return append([]ConfigurationError(nil), p.configErrors...)
+}
+
+func (p *Provider) setConfigurationErrors(configErrors []ConfigurationError) {
+	sort.Slice(configErrors, func(i, j int) bool {
+		if configErrors[i].ServiceName != configErrors[j].ServiceName {
+			return configErrors[i].ServiceName < configErrors[j].ServiceName
+		}
+		return configErrors[i].ServiceID < configErrors[j].ServiceID
+	})
+
+	p.configErrorsMu.Lock()
+	p.configErrors = configErrors
+	p.configErrorsMu.Unlock()
+
+	p.recordConfiguration(len(configErrors))
+}
+
+func newConfigurationError(i item, err error) ConfigurationError {
+	return ConfigurationError{
+		ServiceName: i.Name,
+		ServiceID:   i.ID,
+		Namespace:   i.Namespace,
+		AllocID:     i.AllocID,
+		Message:     err.Error(),
+	}
+}
+
+// validateLabels decodes the labels one by one, to report which tags make the configuration decoding fail.
+// The tags are reported with the configured prefix, as they are written in the Nomad job.
+func validateLabels(labels map[string]string, prefix string) []TagError {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var tagErrors []TagError
+	for _, key := range keys {
+		if _, err := label.DecodeConfiguration(map[string]string{key: labels[key]}); err != nil {
+			tagErr := TagError{
+				Tag:     withPrefix(key, prefix),
+				Value:   labels[key],
+				Message: err.Error(),
+			}
+			if known, suggestion := lintKey(key); !known && suggestion != "" {
+				tagErr.Suggestion = withPrefix(suggestion, prefix)
+			}
+			tagErrors = append(tagErrors, tagErr)
+		}
+	}
+
+	return tagErrors
+}
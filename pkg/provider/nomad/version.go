@@ -0,0 +1,158 @@
+package nomad
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/logs"
+)
+
+// parseVersionWeights parses the weights of the job versions, as <version>:<weight> pairs separated by commas.
+// Invalid pairs are ignored.
+func parseVersionWeights(value string) map[uint64]int {
+	weights := make(map[uint64]int)
+	for _, pair := range splitList(value) {
+		version, weight, found := strings.Cut(pair, ":")
+		if !found {
+			continue
+		}
+
+		v, err := strconv.ParseUint(strings.TrimSpace(version), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		w, err := strconv.Atoi(strings.TrimSpace(weight))
+		if err != nil || w < 0 {
+			continue
+		}
+
+		weights[v] = w
+	}
+
+	return weights
+}
+
+// hasVersionWeights reports whether an instance of the service weights its job versions.
+func hasVersionWeights(instances []*api.ServiceRegistration, prefix string) bool {
+	for _, i := range instances {
+		if _, exists := tagsToLabels(i.Tags, prefix)["traefik.nomad.versionweights"]; exists {
+			return true
+		}
+	}
+	return false
+}
+
+// versionWeightsKey returns the key of the weights of the job versions of the item service.
+func versionWeightsKey(i item) string {
+	return i.Namespace + "/" + i.Name
+}
+
+// getVersionWeights returns the weights of the job versions of the services, indexed by versionWeightsKey.
+// When the instances of several job versions declare weights, the declaration of the newest version applies.
+func getVersionWeights(items []item) map[string]map[uint64]int {
+	// job versions of the declarations, indexed by versionWeightsKey.
+	declaredBy := make(map[string]uint64)
+
+	weights := make(map[string]map[uint64]int)
+	for _, i := range items {
+		if i.ExtraConf.VersionWeights == nil || i.JobVersion == nil {
+			continue
+		}
+
+		key := versionWeightsKey(i)
+		if version, exists := declaredBy[key]; exists && version >= *i.JobVersion {
+			continue
+		}
+
+		declaredBy[key] = *i.JobVersion
+		weights[key] = i.ExtraConf.VersionWeights
+	}
+
+	return weights
+}
+
+// addJobVersion renames the HTTP services of an item after its job version,
+// when the job versions of its service are weighted.
+// The routers keep referencing the service name, which becomes the weighted service.
+func addJobVersion(i item, configuration *dynamic.HTTPConfiguration, weights map[string]map[uint64]int, versions map[string]map[uint64]int) {
+	serviceWeights, ok := weights[versionWeightsKey(i)]
+	if !ok || i.JobVersion == nil {
+		return
+	}
+
+	// the versions without a weight do not receive any request.
+	weight := serviceWeights[*i.JobVersion]
+
+	services := make(map[string]*dynamic.Service, len(configuration.Services))
+	for name, service := range configuration.Services {
+		services[jobVersionName(name, *i.JobVersion)] = service
+
+		if versions[name] == nil {
+			versions[name] = make(map[uint64]int)
+		}
+		versions[name][*i.JobVersion] = weight
+	}
+	configuration.Services = services
+}
+
+// buildJobVersionServices builds, for each service having the servers of several job versions,
+// a weighted service balancing the requests between them.
+func buildJobVersionServices(ctx context.Context, configuration *dynamic.HTTPConfiguration, versions map[string]map[uint64]int) {
+	for name, serviceVersions := range versions {
+		logger := log.Ctx(ctx).With().Str(logs.ServiceName, name).Logger()
+
+		if _, exists := configuration.Services[name]; exists {
+			logger.Error().Msg("Service defined both with and without a job version, skipping the job version weights")
+			continue
+		}
+
+		var sorted []uint64
+		for version := range serviceVersions {
+			// the service of a version may have been dropped by the merge, because of conflicting definitions.
+			if _, exists := configuration.Services[jobVersionName(name, version)]; exists {
+				sorted = append(sorted, version)
+			}
+		}
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+		switch len(sorted) {
+		case 0:
+			continue
+		case 1:
+			// a single version receives all the requests, whatever its weight.
+			versionName := jobVersionName(name, sorted[0])
+			configuration.Services[name] = configuration.Services[versionName]
+			delete(configuration.Services, versionName)
+			continue
+		}
+
+		weighted := &dynamic.WeightedRoundRobin{}
+		var total int
+		for _, version := range sorted {
+			weight := serviceVersions[version]
+			total += weight
+
+			weighted.Services = append(weighted.Services, dynamic.WRRService{
+				Name:   jobVersionName(name, version),
+				Weight: intPtr(weight),
+			})
+		}
+
+		if total == 0 {
+			logger.Warn().Msg("No job version with a weight, the service does not receive any request")
+		}
+
+		configuration.Services[name] = &dynamic.Service{Weighted: weighted}
+	}
+}
+
+func jobVersionName(name string, version uint64) string {
+	return fmt.Sprintf("%s-v%d", name, version)
+}
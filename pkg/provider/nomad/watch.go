@@ -0,0 +1,109 @@
+package nomad
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/rs/zerolog/log"
+)
+
+// watchServices blocks until the services registered in one of the regions change,
+// or until the refresh interval elapses, so that the changes which are not part of the services
+// (e.g. checks, allocations stopping) are still picked up.
+// It reports whether a blocking query returned, rather than the context being done.
+func (p *Provider) watchServices(ctx context.Context) bool {
+	clients := p.clients()
+
+	ctxWatch, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{}, len(clients))
+	for _, client := range clients {
+		client := client
+		go func() {
+			p.waitServices(ctxWatch, client)
+			done <- struct{}{}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-done:
+	}
+	cancel()
+
+	// the refreshes of the Traefik instances watching the same cluster are spread within the throttle window,
+	// as they are all woken up by the same change.
+	if throttle := int64(p.ThrottleDuration); throttle > 0 {
+		waitContext(ctx, time.Duration(rand.Int63n(throttle)))
+	}
+
+	return true
+}
+
+// waitServices runs a blocking query on the services registered in the region of the client,
+// which returns when they change, or when the refresh interval elapses.
+func (p *Provider) waitServices(ctx context.Context, client *api.Client) {
+	wait := time.Duration(p.RefreshInterval)
+
+	index := p.lastIndex(client)
+	if index == 0 {
+		// the services of the region could not be listed on the last refresh,
+		// a blocking query would return immediately.
+		waitContext(ctx, wait)
+		return
+	}
+
+	opts := &api.QueryOptions{AllowStale: p.Stale, WaitIndex: index, WaitTime: wait}
+	opts = opts.WithContext(ctx)
+
+	if _, _, err := client.Services().List(opts); err != nil && ctx.Err() == nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("Unable to watch the Nomad services")
+		waitContext(ctx, wait)
+	}
+}
+
+// clients returns the clients of all the regions the services are discovered in.
+func (p *Provider) clients() []*api.Client {
+	if len(p.regionClients) == 0 {
+		return []*api.Client{p.client}
+	}
+
+	clients := make([]*api.Client, 0, len(p.regionClients))
+	for _, client := range p.regionClients {
+		clients = append(clients, client)
+	}
+
+	return clients
+}
+
+func (p *Provider) lastIndex(client *api.Client) uint64 {
+	p.indexesMu.Lock()
+	defer p.indexesMu.Unlock()
+
+	return p.indexes[client]
+}
+
+func (p *Provider) setLastIndex(client *api.Client, index uint64) {
+	p.indexesMu.Lock()
+	defer p.indexesMu.Unlock()
+
+	p.indexes[client] = index
+}
+
+func waitContext(ctx context.Context, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,76 @@
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+)
+
+func Test_watchServices(t *testing.T) {
+	var watchQueries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/v1/services"):
+			if r.URL.Query().Get("index") != "" {
+				watchQueries = append(watchQueries, r.URL.Query().Get("index")+"/"+r.URL.Query().Get("wait"))
+			}
+			w.Header().Set("X-Nomad-Index", "42")
+			_, _ = w.Write([]byte(servicesRedis))
+		case strings.HasSuffix(r.URL.Path, "/v1/service/redis"):
+			_, _ = w.Write([]byte(redis))
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.Watch = true
+	p.RefreshInterval = ptypes.Duration(time.Minute)
+	err := p.Init()
+	require.NoError(t, err)
+
+	// fudge client, avoid starting up via Provide
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	items, err := p.getNomadServiceData(context.TODO())
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, uint64(42), p.lastIndex(p.client))
+
+	p.watchServices(context.TODO())
+
+	assert.Equal(t, []string{"42/60000ms"}, watchQueries)
+}
+
+func Test_waitServices_noIndex(t *testing.T) {
+	var requests int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	t.Cleanup(ts.Close)
+
+	p := new(Provider)
+	p.SetDefaults()
+	p.Endpoint.Address = ts.URL
+	p.RefreshInterval = ptypes.Duration(50 * time.Millisecond)
+	err := p.Init()
+	require.NoError(t, err)
+
+	p.client, err = createClient(p.namespace, p.Endpoint, nil)
+	require.NoError(t, err)
+
+	start := time.Now()
+	p.waitServices(context.TODO(), p.client)
+
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	assert.Zero(t, requests)
+}
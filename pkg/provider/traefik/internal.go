@@ -79,6 +79,7 @@ func (i *Provider) createConfiguration(ctx context.Context) *dynamic.Configurati
 	i.prometheusConfiguration(cfg)
 	i.entryPointModels(cfg)
 	i.redirection(ctx, cfg)
+	i.defaultServices(cfg)
 	i.serverTransport(cfg)
 	i.serverTransportTCP(cfg)
 
@@ -160,6 +161,23 @@ func (i *Provider) redirection(ctx context.Context, cfg *dynamic.Configuration)
 	}
 }
 
+// defaultServices creates, for each entry point with a default service,
+// a catch-all router with the lowest priority, so that it only handles the requests matching no other router.
+func (i *Provider) defaultServices(cfg *dynamic.Configuration) {
+	for name, ep := range i.staticCfg.EntryPoints {
+		if ep.HTTP.DefaultService == "" {
+			continue
+		}
+
+		cfg.HTTP.Routers[provider.Normalize(name+"-default-service")] = &dynamic.Router{
+			Rule:        "PathPrefix(`/`)",
+			EntryPoints: []string{name},
+			Service:     ep.HTTP.DefaultService,
+			Priority:    1,
+		}
+	}
+}
+
 func (i *Provider) getRedirectPort(name string, def *static.Redirections) (string, error) {
 	exp := regexp.MustCompile(`^:(\d+)$`)
 
@@ -63,6 +63,12 @@ func init() {
 						},
 					},
 				},
+				Timeouts: &dynamic.RouterTimeouts{
+					DialTimeout:           ptypes.Duration(111 * time.Second),
+					ResponseHeaderTimeout: ptypes.Duration(111 * time.Second),
+					IdleTimeout:           ptypes.Duration(111 * time.Second),
+					RequestTimeout:        ptypes.Duration(111 * time.Second),
+				},
 			},
 		},
 		Services: map[string]*dynamic.Service{
@@ -143,6 +149,8 @@ func init() {
 					},
 				},
 				MaxIdleConnsPerHost: 42,
+				MaxIdleConns:        42,
+				MaxConnsPerHost:     42,
 				ForwardingTimeouts: &dynamic.ForwardingTimeouts{
 					DialTimeout:           42,
 					ResponseHeaderTimeout: 42,
@@ -150,6 +158,7 @@ func init() {
 					ReadIdleTimeout:       42,
 					PingTimeout:           42,
 				},
+				StrictMaxConcurrentStreams: true,
 			},
 		},
 		Models: map[string]*dynamic.Model{
@@ -238,8 +247,24 @@ func init() {
 							Depth:       42,
 							ExcludedIPs: []string{"127.0.0.1"},
 						},
-						RequestHeaderName: "foo",
-						RequestHost:       true,
+						RequestHeaderName:         "foo",
+						RequestHost:               true,
+						RequestQueryParameterName: "foo",
+						RequestJWTClaim:           "foo",
+						RequestClientCertCN:       true,
+					},
+					Redis: &dynamic.Redis{
+						Endpoints: []string{"127.0.0.1:6379"},
+						TLS: &types.ClientTLS{
+							CA:                 "ca.pem",
+							Cert:               "cert.pem",
+							Key:                "cert.pem",
+							InsecureSkipVerify: true,
+						},
+						Username: "foo",
+						Password: "foo",
+						DB:       42,
+						Timeout:  42,
 					},
 				},
 				RedirectRegex: &dynamic.RedirectRegex{
@@ -278,6 +303,47 @@ func init() {
 					AuthResponseHeaders:      []string{"foo"},
 					AuthResponseHeadersRegex: "foo",
 					AuthRequestHeaders:       []string{"foo"},
+					ForwardBody:              true,
+					MaxBodySize:              42,
+					Timeout:                  42,
+					FailureMode:              "open",
+					Cache: &dynamic.ForwardAuthCache{
+						TTL:        42,
+						Key:        []string{"header:Authorization"},
+						MaxEntries: 42,
+					},
+				},
+				APIKeyAuth: &dynamic.APIKeyAuth{
+					HeaderName:   "foo",
+					RemoveHeader: true,
+					HeaderField:  "foo",
+					Nomad: &dynamic.APIKeyAuthNomad{
+						Address:         "127.0.0.1",
+						Token:           "foo",
+						Namespace:       "foo",
+						Region:          "foo",
+						Path:            "foo",
+						RefreshInterval: 42,
+					},
+				},
+				OIDC: &dynamic.OIDC{
+					Issuer:            "https://issuer.example.com",
+					ClientID:          "foo",
+					ClientSecret:      "foo",
+					Scopes:            []string{"foo"},
+					RedirectURL:       "https://example.com/oauth2/callback",
+					CallbackPath:      "foo",
+					LogoutPath:        "foo",
+					SessionSecret:     "foo",
+					SessionCookieName: "foo",
+					SessionMaxAge:     42,
+					ClaimsHeaders:     map[string]string{"foo": "bar"},
+					TLS: &types.ClientTLS{
+						CA:                 "ca.pem",
+						Cert:               "cert.pem",
+						Key:                "cert.pem",
+						InsecureSkipVerify: true,
+					},
 				},
 				InFlightReq: &dynamic.InFlightReq{
 					Amount: 42,
@@ -296,15 +362,29 @@ func init() {
 					MaxResponseBodyBytes: 42,
 					MemResponseBodyBytes: 42,
 					RetryExpression:      "foo",
+					RequestOnly:          true,
+					SpoolDirectory:       "foo",
 				},
 				CircuitBreaker: &dynamic.CircuitBreaker{
 					Expression: "foo",
 				},
 				Compress: &dynamic.Compress{
 					ExcludedContentTypes: []string{"foo"},
+					IncludedContentTypes: []string{"foo"},
+					Encodings:            []string{"foo"},
+					GzipLevel:            42,
+					BrotliLevel:          42,
+					ZstdLevel:            42,
 				},
 				PassTLSClientCert: &dynamic.PassTLSClientCert{
-					PEM: true,
+					PEM:        true,
+					SpiffeID:   true,
+					SANHeaders: true,
+					Encoding:   "foobar",
+					Allowed: &dynamic.TLSClientCertificateAllowed{
+						CommonNames: []string{"foobar"},
+						SANs:        []string{"foobar"},
+					},
 					Info: &dynamic.TLSClientCertificateInfo{
 						NotAfter:  true,
 						NotBefore: true,
@@ -334,8 +414,108 @@ func init() {
 				Retry: &dynamic.Retry{
 					Attempts:        42,
 					InitialInterval: 42,
+					MaxInterval:     42,
+					Status:          []string{"foo"},
+					IdempotentOnly:  true,
+					Budget: &dynamic.RetryBudget{
+						Percent:    42,
+						MinRetries: 42,
+						Window:     42,
+					},
 				},
 				ContentType: &dynamic.ContentType{},
+				RequestLimits: &dynamic.RequestLimits{
+					MaxBodyBytes:         42,
+					Timeout:              42,
+					WebSocketMaxLifetime: 42,
+				},
+				GeoIP: &dynamic.GeoIP{
+					DatabaseFile:          "foo",
+					ASNDatabaseFile:       "foo",
+					AllowedCountries:      []string{"foo"},
+					BlockedCountries:      []string{"foo"},
+					AllowUnknown:          true,
+					CountryHeader:         "foo",
+					ASNHeader:             "foo",
+					ASNOrganizationHeader: "foo",
+					IPStrategy: &dynamic.IPStrategy{
+						Depth:       42,
+						ExcludedIPs: []string{"127.0.0.1"},
+					},
+				},
+				Cache: &dynamic.Cache{
+					MaxSize:              42,
+					MaxEntrySize:         42,
+					DefaultTTL:           42,
+					StaleWhileRevalidate: 42,
+					Redis: &dynamic.Redis{
+						Endpoints: []string{"127.0.0.1:6379"},
+						TLS: &types.ClientTLS{
+							CA:                 "ca.pem",
+							Cert:               "cert.pem",
+							Key:                "cert.pem",
+							InsecureSkipVerify: true,
+						},
+						Username: "foo",
+						Password: "foo",
+						DB:       42,
+						Timeout:  42,
+					},
+				},
+				RewriteBody: &dynamic.RewriteBody{
+					Rewrites: []dynamic.BodyRewrite{
+						{
+							Regex:       "http://foo",
+							Replacement: "https://{{ .Host }}",
+						},
+					},
+					ContentTypes: []string{"text/html"},
+					MaxBodySize:  42,
+				},
+				Priority: &dynamic.Priority{
+					Classes: []dynamic.PriorityClass{
+						{
+							Name:         "checkout",
+							Priority:     42,
+							Headers:      map[string]string{"X-Tier": "gold"},
+							PathPrefixes: []string{"/checkout"},
+						},
+					},
+					MaxInFlight: 42,
+					MaxLatency:  42,
+				},
+				RequestID: &dynamic.RequestID{
+					HeaderName:         "X-Request-Id",
+					ResponseHeaderName: "X-Request-Id",
+					Format:             "ulid",
+					Overwrite:          true,
+				},
+				Maintenance: &dynamic.Maintenance{
+					Enabled:     true,
+					StatusCode:  503,
+					ContentType: "text/html",
+					Body:        "maintenance",
+					File:        "/etc/traefik/maintenance.html",
+					RetryAfter:  42,
+				},
+				WAF: &dynamic.WAF{
+					CRS:           true,
+					Directives:    []string{"SecRuleRemoveById 920350"},
+					DetectionOnly: true,
+				},
+				BotDetection: &dynamic.BotDetection{
+					Action:            "tarpit",
+					TarpitDelay:       42,
+					BlockedUserAgents: []string{"sqlmap"},
+					AllowedUserAgents: []string{"probe"},
+					RequiredHeaders:   []string{"Accept-Language"},
+					Challenge: &dynamic.BotChallenge{
+						Secret:     "foo",
+						Difficulty: 16,
+						TTL:        42,
+						CookieName: "traefik_bot",
+					},
+				},
 				Plugin: map[string]dynamic.PluginConf{
 					"foo": {
 						"answer": struct{ Answer int }{
@@ -412,8 +592,10 @@ func init() {
 	config.UDP = &dynamic.UDPConfiguration{
 		Routers: map[string]*dynamic.UDPRouter{
 			"foo": {
-				EntryPoints: []string{"foo"},
-				Service:     "foo",
+				EntryPoints:    []string{"foo"},
+				Service:        "foo",
+				SessionTimeout: ptypes.Duration(111 * time.Second),
+				MaxSessions:    42,
 			},
 		},
 		Services: map[string]*dynamic.UDPService{
@@ -424,6 +606,7 @@ func init() {
 							Address: "127.0.0.1:8080",
 						},
 					},
+					SourceHash: true,
 				},
 			},
 			"bar": {
@@ -529,10 +712,21 @@ func TestDo_staticConfiguration(t *testing.T) {
 					WriteTimeout: ptypes.Duration(111 * time.Second),
 					IdleTimeout:  ptypes.Duration(111 * time.Second),
 				},
+				InFlightRequests: &static.InFlightRequests{
+					Amount:       42,
+					QueueSize:    42,
+					QueueTimeout: ptypes.Duration(111 * time.Second),
+				},
+				WebSockets: &static.WebSockets{
+					MaxLifetime:  ptypes.Duration(111 * time.Second),
+					CloseTimeout: ptypes.Duration(111 * time.Second),
+				},
 			},
 			ProxyProtocol: &static.ProxyProtocol{
 				Insecure:   true,
 				TrustedIPs: []string{"127.0.0.1/32", "192.168.0.1"},
+				Required:   true,
+				TLVHeaders: true,
 			},
 			ForwardedHeaders: &static.ForwardedHeaders{
 				Insecure:   true,
@@ -758,9 +952,11 @@ func TestDo_staticConfiguration(t *testing.T) {
 	}
 
 	config.API = &static.API{
-		Insecure:  true,
-		Dashboard: true,
-		Debug:     true,
+		Insecure:            true,
+		Dashboard:           true,
+		Debug:               true,
+		ManageCertResolvers: true,
+		ManageMaintenance:   true,
 	}
 
 	config.Metrics = &types.Metrics{
@@ -887,6 +1083,10 @@ func TestDo_staticConfiguration(t *testing.T) {
 		ResolvDepth:     42,
 	}
 
+	config.Upgrade = &static.Upgrade{
+		ReadyTimeout: ptypes.Duration(111 * time.Second),
+	}
+
 	config.CertificatesResolvers = map[string]static.CertificateResolver{
 		"CertificateResolver0": {
 			ACME: &acme.Configuration{
@@ -1,6 +1,8 @@
 package server
 
 import (
+	"reflect"
+
 	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
@@ -38,6 +40,12 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 
 	var defaultTLSOptionProviders []string
 	var defaultTLSStoreProviders []string
+
+	// the entrypoints are not qualified with the provider name, as the routers reference them by name.
+	entryPointsTCPProviders := make(map[string][]string)
+	entryPointsTCPToDelete := make(map[string]struct{})
+	entryPointsUDPProviders := make(map[string][]string)
+	entryPointsUDPToDelete := make(map[string]struct{})
 	for pvd, configuration := range configurations {
 		if configuration.HTTP != nil {
 			for routerName, router := range configuration.HTTP.Routers {
@@ -84,6 +92,18 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 			for serversTransportName, serversTransport := range configuration.TCP.ServersTransports {
 				conf.TCP.ServersTransports[provider.MakeQualifiedName(pvd, serversTransportName)] = serversTransport
 			}
+			for entryPointName, entryPoint := range configuration.TCP.EntryPoints {
+				entryPointsTCPProviders[entryPointName] = append(entryPointsTCPProviders[entryPointName], pvd)
+
+				if conf.TCP.EntryPoints == nil {
+					conf.TCP.EntryPoints = make(map[string]*dynamic.TCPEntryPoint)
+				}
+				if existing, ok := conf.TCP.EntryPoints[entryPointName]; ok && !reflect.DeepEqual(existing, entryPoint) {
+					entryPointsTCPToDelete[entryPointName] = struct{}{}
+					continue
+				}
+				conf.TCP.EntryPoints[entryPointName] = entryPoint
+			}
 		}
 
 		if configuration.UDP != nil {
@@ -93,6 +113,18 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 			for serviceName, service := range configuration.UDP.Services {
 				conf.UDP.Services[provider.MakeQualifiedName(pvd, serviceName)] = service
 			}
+			for entryPointName, entryPoint := range configuration.UDP.EntryPoints {
+				entryPointsUDPProviders[entryPointName] = append(entryPointsUDPProviders[entryPointName], pvd)
+
+				if conf.UDP.EntryPoints == nil {
+					conf.UDP.EntryPoints = make(map[string]*dynamic.UDPEntryPoint)
+				}
+				if existing, ok := conf.UDP.EntryPoints[entryPointName]; ok && !reflect.DeepEqual(existing, entryPoint) {
+					entryPointsUDPToDelete[entryPointName] = struct{}{}
+					continue
+				}
+				conf.UDP.EntryPoints[entryPointName] = entryPoint
+			}
 		}
 
 		if configuration.TLS != nil {
@@ -125,6 +157,18 @@ func mergeConfiguration(configurations dynamic.Configurations, defaultEntryPoint
 		}
 	}
 
+	for entryPointName := range entryPointsTCPToDelete {
+		log.Error().Str(logs.EntryPointName, entryPointName).
+			Msgf("EntryPoint TCP defined multiple times with different configurations in %v", entryPointsTCPProviders[entryPointName])
+		delete(conf.TCP.EntryPoints, entryPointName)
+	}
+
+	for entryPointName := range entryPointsUDPToDelete {
+		log.Error().Str(logs.EntryPointName, entryPointName).
+			Msgf("EntryPoint UDP defined multiple times with different configurations in %v", entryPointsUDPProviders[entryPointName])
+		delete(conf.UDP.EntryPoints, entryPointName)
+	}
+
 	if len(defaultTLSStoreProviders) > 1 {
 		log.Error().Msgf("Default TLS Stores defined multiple times in %v", defaultTLSOptionProviders)
 		delete(conf.TLS.Stores, tls.DefaultTLSStoreName)
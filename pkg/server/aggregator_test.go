@@ -480,6 +480,62 @@ func Test_mergeConfiguration_defaultTCPEntryPoint(t *testing.T) {
 	assert.Equal(t, expected, actual.TCP)
 }
 
+func Test_mergeConfiguration_tcpEntryPoints(t *testing.T) {
+	given := dynamic.Configurations{
+		"provider-1": &dynamic.Configuration{
+			TCP: &dynamic.TCPConfiguration{
+				EntryPoints: map[string]*dynamic.TCPEntryPoint{
+					"redis":    {Port: 10001},
+					"postgres": {Port: 10002},
+				},
+			},
+		},
+		"provider-2": &dynamic.Configuration{
+			TCP: &dynamic.TCPConfiguration{
+				EntryPoints: map[string]*dynamic.TCPEntryPoint{
+					"redis":    {Port: 10001},
+					"postgres": {Port: 10003},
+				},
+			},
+		},
+	}
+
+	actual := mergeConfiguration(given, nil)
+
+	expected := map[string]*dynamic.TCPEntryPoint{
+		"redis": {Port: 10001},
+	}
+	assert.Equal(t, expected, actual.TCP.EntryPoints)
+}
+
+func Test_mergeConfiguration_udpEntryPoints(t *testing.T) {
+	given := dynamic.Configurations{
+		"provider-1": &dynamic.Configuration{
+			UDP: &dynamic.UDPConfiguration{
+				EntryPoints: map[string]*dynamic.UDPEntryPoint{
+					"dns":    {Port: 10053},
+					"syslog": {Port: 10514},
+				},
+			},
+		},
+		"provider-2": &dynamic.Configuration{
+			UDP: &dynamic.UDPConfiguration{
+				EntryPoints: map[string]*dynamic.UDPEntryPoint{
+					"dns":    {Port: 10053},
+					"syslog": {Port: 10515},
+				},
+			},
+		},
+	}
+
+	actual := mergeConfiguration(given, nil)
+
+	expected := map[string]*dynamic.UDPEntryPoint{
+		"dns": {Port: 10053},
+	}
+	assert.Equal(t, expected, actual.UDP.EntryPoints)
+}
+
 func Test_applyModel(t *testing.T) {
 	testCases := []struct {
 		desc     string
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"reflect"
+	"sort"
 
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -25,7 +26,9 @@ type ConfigurationWatcher struct {
 	newConfigs chan dynamic.Configurations
 
 	requiredProvider       string
+	providersPrecedence    []string
 	configurationListeners []func(dynamic.Configuration)
+	providersListeners     []func([]string)
 
 	routinesPool *safe.Pool
 }
@@ -36,6 +39,7 @@ func NewConfigurationWatcher(
 	pvd provider.Provider,
 	defaultEntryPoints []string,
 	requiredProvider string,
+	providersPrecedence []string,
 ) *ConfigurationWatcher {
 	return &ConfigurationWatcher{
 		providerAggregator:  pvd,
@@ -44,6 +48,7 @@ func NewConfigurationWatcher(
 		routinesPool:        routinesPool,
 		defaultEntryPoints:  defaultEntryPoints,
 		requiredProvider:    requiredProvider,
+		providersPrecedence: providersPrecedence,
 	}
 }
 
@@ -68,6 +73,12 @@ func (c *ConfigurationWatcher) AddListener(listener func(dynamic.Configuration))
 	c.configurationListeners = append(c.configurationListeners, listener)
 }
 
+// AddProvidersListener adds a new listener function called with the names of the providers,
+// sorted, once their configuration is applied.
+func (c *ConfigurationWatcher) AddProvidersListener(listener func(providers []string)) {
+	c.providersListeners = append(c.providersListeners, listener)
+}
+
 func (c *ConfigurationWatcher) startProviderAggregator() {
 	log.Info().Msgf("Starting provider aggregator %T", c.providerAggregator)
 
@@ -164,13 +175,28 @@ func (c *ConfigurationWatcher) applyConfigurations(ctx context.Context) {
 				continue
 			}
 
-			conf := mergeConfiguration(newConfigs.DeepCopy(), c.defaultEntryPoints)
+			configurations := newConfigs.DeepCopy()
+			applyProvidersPrecedence(configurations, c.providersPrecedence)
+
+			conf := mergeConfiguration(configurations, c.defaultEntryPoints)
 			conf = applyModel(conf)
 
 			for _, listener := range c.configurationListeners {
 				listener(conf)
 			}
 
+			if len(c.providersListeners) > 0 {
+				providers := make([]string, 0, len(newConfigs))
+				for name := range newConfigs {
+					providers = append(providers, name)
+				}
+				sort.Strings(providers)
+
+				for _, listener := range c.providersListeners {
+					listener(providers)
+				}
+			}
+
 			lastConfigurations = newConfigs
 		}
 	}
@@ -74,7 +74,7 @@ func TestNewConfigurationWatcher(t *testing.T) {
 		}},
 	}
 
-	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "", nil)
 
 	run := make(chan struct{})
 
@@ -143,7 +143,7 @@ func TestWaitForRequiredProvider(t *testing.T) {
 		Configuration: config,
 	})
 
-	watcher := NewConfigurationWatcher(routinesPool, pvdAggregator, []string{}, "required")
+	watcher := NewConfigurationWatcher(routinesPool, pvdAggregator, []string{}, "required", nil)
 
 	publishedConfigCount := 0
 	watcher.AddListener(func(_ dynamic.Configuration) {
@@ -179,7 +179,7 @@ func TestIgnoreTransientConfiguration(t *testing.T) {
 		),
 	}
 
-	watcher := NewConfigurationWatcher(routinesPool, &mockProvider{}, []string{"defaultEP"}, "")
+	watcher := NewConfigurationWatcher(routinesPool, &mockProvider{}, []string{"defaultEP"}, "", nil)
 
 	publishedConfigCount := 0
 	var lastConfig dynamic.Configuration
@@ -269,7 +269,7 @@ func TestListenProvidersThrottleProviderConfigReload(t *testing.T) {
 	err := providerAggregator.AddProvider(pvd)
 	assert.Nil(t, err)
 
-	watcher := NewConfigurationWatcher(routinesPool, providerAggregator, []string{}, "")
+	watcher := NewConfigurationWatcher(routinesPool, providerAggregator, []string{}, "", nil)
 
 	publishedConfigCount := 0
 	watcher.AddListener(func(_ dynamic.Configuration) {
@@ -297,7 +297,7 @@ func TestListenProvidersSkipsEmptyConfigs(t *testing.T) {
 		messages: []dynamic.Message{{ProviderName: "mock"}},
 	}
 
-	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "", nil)
 	watcher.AddListener(func(_ dynamic.Configuration) {
 		t.Error("An empty configuration was published but it should not")
 	})
@@ -328,7 +328,7 @@ func TestListenProvidersSkipsSameConfigurationForProvider(t *testing.T) {
 		messages: []dynamic.Message{message, message},
 	}
 
-	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "", nil)
 
 	var configurationReloads int
 	watcher.AddListener(func(_ dynamic.Configuration) {
@@ -372,7 +372,7 @@ func TestListenProvidersDoesNotSkipFlappingConfiguration(t *testing.T) {
 		},
 	}
 
-	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{"defaultEP"}, "")
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{"defaultEP"}, "", nil)
 
 	var lastConfig dynamic.Configuration
 	watcher.AddListener(func(conf dynamic.Configuration) {
@@ -452,7 +452,7 @@ func TestListenProvidersIgnoreSameConfig(t *testing.T) {
 	err := providerAggregator.AddProvider(pvd)
 	assert.Nil(t, err)
 
-	watcher := NewConfigurationWatcher(routinesPool, providerAggregator, []string{"defaultEP"}, "")
+	watcher := NewConfigurationWatcher(routinesPool, providerAggregator, []string{"defaultEP"}, "", nil)
 
 	var configurationReloads int
 	var lastConfig dynamic.Configuration
@@ -509,7 +509,7 @@ func TestListenProvidersIgnoreSameConfig(t *testing.T) {
 func TestApplyConfigUnderStress(t *testing.T) {
 	routinesPool := safe.NewPool(context.Background())
 
-	watcher := NewConfigurationWatcher(routinesPool, &mockProvider{}, []string{"defaultEP"}, "")
+	watcher := NewConfigurationWatcher(routinesPool, &mockProvider{}, []string{"defaultEP"}, "", nil)
 
 	routinesPool.GoCtx(func(ctx context.Context) {
 		i := 0
@@ -595,7 +595,7 @@ func TestListenProvidersIgnoreIntermediateConfigs(t *testing.T) {
 	err := providerAggregator.AddProvider(pvd)
 	assert.Nil(t, err)
 
-	watcher := NewConfigurationWatcher(routinesPool, providerAggregator, []string{"defaultEP"}, "")
+	watcher := NewConfigurationWatcher(routinesPool, providerAggregator, []string{"defaultEP"}, "", nil)
 
 	var configurationReloads int
 	var lastConfig dynamic.Configuration
@@ -658,7 +658,7 @@ func TestListenProvidersPublishesConfigForEachProvider(t *testing.T) {
 		},
 	}
 
-	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{"defaultEP"}, "")
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{"defaultEP"}, "", nil)
 
 	var publishedProviderConfig dynamic.Configuration
 
@@ -707,6 +707,49 @@ func TestListenProvidersPublishesConfigForEachProvider(t *testing.T) {
 	assert.Equal(t, expected, publishedProviderConfig)
 }
 
+func TestListenProvidersPublishesAppliedProviders(t *testing.T) {
+	routinesPool := safe.NewPool(context.Background())
+
+	configuration := &dynamic.Configuration{
+		HTTP: th.BuildConfiguration(
+			th.WithRouters(th.WithRouter("foo")),
+			th.WithLoadBalancerServices(th.WithService("bar")),
+		),
+	}
+
+	pvd := &mockProvider{
+		messages: []dynamic.Message{
+			{ProviderName: "mock2", Configuration: configuration},
+			{ProviderName: "mock", Configuration: configuration},
+		},
+	}
+
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{"defaultEP"}, "", nil)
+
+	var mu sync.Mutex
+	var publishedProviders []string
+
+	watcher.AddProvidersListener(func(providers []string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		publishedProviders = providers
+	})
+
+	watcher.Start()
+
+	t.Cleanup(watcher.Stop)
+	t.Cleanup(routinesPool.Stop)
+
+	// give some time so that the configuration can be processed
+	time.Sleep(100 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"mock", "mock2"}, publishedProviders)
+}
+
 func TestPublishConfigUpdatedByProvider(t *testing.T) {
 	routinesPool := safe.NewPool(context.Background())
 
@@ -732,7 +775,7 @@ func TestPublishConfigUpdatedByProvider(t *testing.T) {
 		},
 	}
 
-	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "", nil)
 
 	publishedConfigCount := 0
 	watcher.AddListener(func(configuration dynamic.Configuration) {
@@ -782,7 +825,7 @@ func TestPublishConfigUpdatedByConfigWatcherListener(t *testing.T) {
 		},
 	}
 
-	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "")
+	watcher := NewConfigurationWatcher(routinesPool, pvd, []string{}, "", nil)
 
 	publishedConfigCount := 0
 	watcher.AddListener(func(configuration dynamic.Configuration) {
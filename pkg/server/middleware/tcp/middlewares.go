@@ -6,8 +6,11 @@ import (
 	"strings"
 
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
+	"github.com/traefik/traefik/v3/pkg/middlewares/tcp/idletimeout"
 	"github.com/traefik/traefik/v3/pkg/middlewares/tcp/inflightconn"
 	"github.com/traefik/traefik/v3/pkg/middlewares/tcp/ipallowlist"
+	"github.com/traefik/traefik/v3/pkg/middlewares/tcp/proxyprotocol"
+	"github.com/traefik/traefik/v3/pkg/middlewares/tcp/ratelimiter"
 	"github.com/traefik/traefik/v3/pkg/server/provider"
 	"github.com/traefik/traefik/v3/pkg/tcp"
 )
@@ -87,6 +90,13 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 
 	var middleware tcp.Constructor
 
+	// IdleTimeout
+	if config.IdleTimeout != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return idletimeout.New(ctx, next, *config.IdleTimeout, middlewareName)
+		}
+	}
+
 	// InFlightConn
 	if config.InFlightConn != nil {
 		middleware = func(next tcp.Handler) (tcp.Handler, error) {
@@ -101,6 +111,20 @@ func (b *Builder) buildConstructor(ctx context.Context, middlewareName string) (
 		}
 	}
 
+	// ProxyProtocol
+	if config.ProxyProtocol != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return proxyprotocol.New(ctx, next, *config.ProxyProtocol, middlewareName)
+		}
+	}
+
+	// RateLimit
+	if config.RateLimit != nil {
+		middleware = func(next tcp.Handler) (tcp.Handler, error) {
+			return ratelimiter.New(ctx, next, *config.RateLimit, middlewareName)
+		}
+	}
+
 	if middleware == nil {
 		return nil, fmt.Errorf("invalid middleware %q configuration: invalid middleware type or middleware does not exist", middlewareName)
 	}
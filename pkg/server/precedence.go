@@ -0,0 +1,170 @@
+package server
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/logs"
+	"github.com/traefik/traefik/v3/pkg/server/provider"
+	"github.com/traefik/traefik/v3/pkg/tls"
+)
+
+// removedElements holds the names of the elements removed from the configuration of each provider,
+// along with the provider whose definition is kept instead.
+type removedElements map[string]map[string]string
+
+// applyProvidersPrecedence resolves the name conflicts between the providers listed in precedence,
+// by decreasing precedence: an element defined by several of them is only kept in the configuration
+// of the provider having the highest precedence, and removed from the others.
+// The routers of the other providers referencing the removed element by its unqualified name
+// are pointed at the kept element.
+// The providers which are not listed are left untouched.
+func applyProvidersPrecedence(configurations dynamic.Configurations, precedence []string) {
+	ranks := make(map[string]int, len(precedence))
+	for i, pvd := range precedence {
+		if _, exists := ranks[pvd]; !exists {
+			ranks[pvd] = i
+		}
+	}
+
+	var providers []string
+	for pvd, configuration := range configurations {
+		if _, ok := ranks[pvd]; ok && configuration != nil {
+			providers = append(providers, pvd)
+		}
+	}
+
+	if len(providers) < 2 {
+		return
+	}
+
+	sort.Slice(providers, func(i, j int) bool { return ranks[providers[i]] < ranks[providers[j]] })
+
+	httpRouters := make(map[string]map[string]*dynamic.Router)
+	httpMiddlewares := make(map[string]map[string]*dynamic.Middleware)
+	httpServices := make(map[string]map[string]*dynamic.Service)
+	httpServersTransports := make(map[string]map[string]*dynamic.ServersTransport)
+	tcpRouters := make(map[string]map[string]*dynamic.TCPRouter)
+	tcpMiddlewares := make(map[string]map[string]*dynamic.TCPMiddleware)
+	tcpServices := make(map[string]map[string]*dynamic.TCPService)
+	tcpServersTransports := make(map[string]map[string]*dynamic.TCPServersTransport)
+	tcpEntryPoints := make(map[string]map[string]*dynamic.TCPEntryPoint)
+	udpRouters := make(map[string]map[string]*dynamic.UDPRouter)
+	udpServices := make(map[string]map[string]*dynamic.UDPService)
+	udpEntryPoints := make(map[string]map[string]*dynamic.UDPEntryPoint)
+	tlsStores := make(map[string]map[string]tls.Store)
+	tlsOptions := make(map[string]map[string]tls.Options)
+
+	for _, pvd := range providers {
+		configuration := configurations[pvd]
+		if configuration.HTTP != nil {
+			httpRouters[pvd] = configuration.HTTP.Routers
+			httpMiddlewares[pvd] = configuration.HTTP.Middlewares
+			httpServices[pvd] = configuration.HTTP.Services
+			httpServersTransports[pvd] = configuration.HTTP.ServersTransports
+		}
+		if configuration.TCP != nil {
+			tcpRouters[pvd] = configuration.TCP.Routers
+			tcpMiddlewares[pvd] = configuration.TCP.Middlewares
+			tcpServices[pvd] = configuration.TCP.Services
+			tcpServersTransports[pvd] = configuration.TCP.ServersTransports
+			tcpEntryPoints[pvd] = configuration.TCP.EntryPoints
+		}
+		if configuration.UDP != nil {
+			udpRouters[pvd] = configuration.UDP.Routers
+			udpServices[pvd] = configuration.UDP.Services
+			udpEntryPoints[pvd] = configuration.UDP.EntryPoints
+		}
+		if configuration.TLS != nil {
+			tlsStores[pvd] = configuration.TLS.Stores
+			tlsOptions[pvd] = configuration.TLS.Options
+		}
+	}
+
+	removeConflicts("router", providers, httpRouters)
+	removedMiddlewares := removeConflicts("middleware", providers, httpMiddlewares)
+	removedServices := removeConflicts("service", providers, httpServices)
+	removeConflicts("serversTransport", providers, httpServersTransports)
+	removeConflicts("TCP router", providers, tcpRouters)
+	removedTCPMiddlewares := removeConflicts("TCP middleware", providers, tcpMiddlewares)
+	removedTCPServices := removeConflicts("TCP service", providers, tcpServices)
+	removeConflicts("TCP serversTransport", providers, tcpServersTransports)
+	removeConflicts("TCP entryPoint", providers, tcpEntryPoints)
+	removeConflicts("UDP router", providers, udpRouters)
+	removedUDPServices := removeConflicts("UDP service", providers, udpServices)
+	removeConflicts("UDP entryPoint", providers, udpEntryPoints)
+	removeConflicts("TLS store", providers, tlsStores)
+	removedTLSOptions := removeConflicts("TLS options", providers, tlsOptions)
+
+	for _, pvd := range providers {
+		for _, router := range httpRouters[pvd] {
+			router.Service = removedServices.qualify(pvd, router.Service)
+			for i, name := range router.Middlewares {
+				router.Middlewares[i] = removedMiddlewares.qualify(pvd, name)
+			}
+			if router.TLS != nil {
+				router.TLS.Options = removedTLSOptions.qualify(pvd, router.TLS.Options)
+			}
+		}
+
+		for _, router := range tcpRouters[pvd] {
+			router.Service = removedTCPServices.qualify(pvd, router.Service)
+			for i, name := range router.Middlewares {
+				router.Middlewares[i] = removedTCPMiddlewares.qualify(pvd, name)
+			}
+			if router.TLS != nil {
+				router.TLS.Options = removedTLSOptions.qualify(pvd, router.TLS.Options)
+			}
+		}
+
+		for _, router := range udpRouters[pvd] {
+			router.Service = removedUDPServices.qualify(pvd, router.Service)
+		}
+	}
+}
+
+// removeConflicts removes the elements defined by several providers from all of them but the first,
+// the providers being sorted by decreasing precedence, and the elements being indexed by provider.
+func removeConflicts[V any](kind string, providers []string, elements map[string]map[string]V) removedElements {
+	removed := make(removedElements)
+
+	// providers keeping the elements, indexed by element name.
+	owners := make(map[string]string)
+	for _, pvd := range providers {
+		for name := range elements[pvd] {
+			owner, exists := owners[name]
+			if !exists {
+				owners[name] = pvd
+				continue
+			}
+
+			log.Warn().Str(logs.ProviderName, pvd).
+				Msgf("The %s %q is also defined by the provider %s, which has precedence: ignoring its definition", kind, name, owner)
+
+			delete(elements[pvd], name)
+
+			if removed[pvd] == nil {
+				removed[pvd] = make(map[string]string)
+			}
+			removed[pvd][name] = owner
+		}
+	}
+
+	return removed
+}
+
+// qualify returns the name qualified with the provider of the kept element,
+// when the given unqualified name references an element removed from the configuration of the provider.
+// The default TLS options and store are never qualified, and keep being referenced by their name.
+func (r removedElements) qualify(pvd, name string) string {
+	if strings.Contains(name, "@") || name == tls.DefaultTLSConfigName {
+		return name
+	}
+
+	if owner, ok := r[pvd][name]; ok {
+		return provider.MakeQualifiedName(owner, name)
+	}
+	return name
+}
@@ -0,0 +1,167 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/tls"
+)
+
+func Test_applyProvidersPrecedence(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		precedence []string
+		given      dynamic.Configurations
+		expected   dynamic.Configurations
+	}{
+		{
+			desc: "no precedence",
+			given: dynamic.Configurations{
+				"file":  httpConfiguration("whoami", "whoami", "auth"),
+				"nomad": httpConfiguration("whoami", "whoami", "auth"),
+			},
+			expected: dynamic.Configurations{
+				"file":  httpConfiguration("whoami", "whoami", "auth"),
+				"nomad": httpConfiguration("whoami", "whoami", "auth"),
+			},
+		},
+		{
+			desc:       "file has precedence",
+			precedence: []string{"file", "nomad"},
+			given: dynamic.Configurations{
+				"file":  httpConfiguration("whoami", "whoami", "auth"),
+				"nomad": httpConfiguration("whoami", "whoami", "auth"),
+			},
+			expected: dynamic.Configurations{
+				"file": httpConfiguration("whoami", "whoami", "auth"),
+				"nomad": {HTTP: &dynamic.HTTPConfiguration{
+					Routers:     map[string]*dynamic.Router{},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services:    map[string]*dynamic.Service{},
+				}},
+			},
+		},
+		{
+			desc:       "nomad routers pointed at the file elements",
+			precedence: []string{"file", "nomad"},
+			given: dynamic.Configurations{
+				"file":  httpConfiguration("", "whoami", "auth"),
+				"nomad": httpConfiguration("whoami", "whoami", "auth"),
+			},
+			expected: dynamic.Configurations{
+				"file": httpConfiguration("", "whoami", "auth"),
+				"nomad": {HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"whoami": {Service: "whoami@file", Middlewares: []string{"auth@file", "other@consul"}},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services:    map[string]*dynamic.Service{},
+				}},
+			},
+		},
+		{
+			desc:       "nomad has precedence",
+			precedence: []string{"nomad", "file"},
+			given: dynamic.Configurations{
+				"file":  httpConfiguration("whoami", "whoami", ""),
+				"nomad": httpConfiguration("", "whoami", ""),
+			},
+			expected: dynamic.Configurations{
+				"file": {HTTP: &dynamic.HTTPConfiguration{
+					Routers: map[string]*dynamic.Router{
+						"whoami": {Service: "whoami@nomad", Middlewares: []string{"other@consul"}},
+					},
+					Middlewares: map[string]*dynamic.Middleware{},
+					Services:    map[string]*dynamic.Service{},
+				}},
+				"nomad": httpConfiguration("", "whoami", ""),
+			},
+		},
+		{
+			desc:       "unlisted provider left untouched",
+			precedence: []string{"file", "nomad"},
+			given: dynamic.Configurations{
+				"file":   httpConfiguration("whoami", "whoami", ""),
+				"consul": httpConfiguration("whoami", "whoami", ""),
+			},
+			expected: dynamic.Configurations{
+				"file":   httpConfiguration("whoami", "whoami", ""),
+				"consul": httpConfiguration("whoami", "whoami", ""),
+			},
+		},
+		{
+			desc:       "default TLS options",
+			precedence: []string{"file", "nomad"},
+			given: dynamic.Configurations{
+				"file": {TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{"default": {MinVersion: "VersionTLS12"}, "strict": {MinVersion: "VersionTLS13"}},
+				}},
+				"nomad": {
+					HTTP: &dynamic.HTTPConfiguration{
+						Routers: map[string]*dynamic.Router{
+							"default": {Service: "whoami", TLS: &dynamic.RouterTLSConfig{Options: "default"}},
+							"strict":  {Service: "whoami", TLS: &dynamic.RouterTLSConfig{Options: "strict"}},
+						},
+					},
+					TLS: &dynamic.TLSConfiguration{
+						Options: map[string]tls.Options{"default": {MinVersion: "VersionTLS10"}, "strict": {MinVersion: "VersionTLS13"}},
+					},
+				},
+			},
+			expected: dynamic.Configurations{
+				"file": {TLS: &dynamic.TLSConfiguration{
+					Options: map[string]tls.Options{"default": {MinVersion: "VersionTLS12"}, "strict": {MinVersion: "VersionTLS13"}},
+				}},
+				"nomad": {
+					HTTP: &dynamic.HTTPConfiguration{
+						Routers: map[string]*dynamic.Router{
+							"default": {Service: "whoami", TLS: &dynamic.RouterTLSConfig{Options: "default"}},
+							"strict":  {Service: "whoami", TLS: &dynamic.RouterTLSConfig{Options: "strict@file"}},
+						},
+					},
+					TLS: &dynamic.TLSConfiguration{
+						Options: map[string]tls.Options{},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			applyProvidersPrecedence(test.given, test.precedence)
+			assert.Equal(t, test.expected, test.given)
+		})
+	}
+}
+
+// httpConfiguration returns an HTTP configuration defining the given router, service, and middleware,
+// the router referencing the service and the middleware.
+func httpConfiguration(router, service, middleware string) *dynamic.Configuration {
+	configuration := &dynamic.Configuration{HTTP: &dynamic.HTTPConfiguration{
+		Routers:     map[string]*dynamic.Router{},
+		Middlewares: map[string]*dynamic.Middleware{},
+		Services:    map[string]*dynamic.Service{},
+	}}
+
+	if router != "" {
+		configuration.HTTP.Routers[router] = &dynamic.Router{Service: service, Middlewares: []string{"other@consul"}}
+		if middleware != "" {
+			configuration.HTTP.Routers[router].Middlewares = []string{middleware, "other@consul"}
+		}
+	}
+
+	if service != "" {
+		configuration.HTTP.Services[service] = &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{}}
+	}
+
+	if middleware != "" {
+		configuration.HTTP.Middlewares[middleware] = &dynamic.Middleware{AddPrefix: &dynamic.AddPrefix{Prefix: "/" + middleware}}
+	}
+
+	return configuration
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
 	metricsMiddle "github.com/traefik/traefik/v3/pkg/middlewares/metrics"
 	"github.com/traefik/traefik/v3/pkg/middlewares/recovery"
+	"github.com/traefik/traefik/v3/pkg/middlewares/timeouts"
 	"github.com/traefik/traefik/v3/pkg/middlewares/tracing"
 	httpmuxer "github.com/traefik/traefik/v3/pkg/muxer/http"
 	"github.com/traefik/traefik/v3/pkg/server/middleware"
@@ -195,7 +196,7 @@ func (m *Manager) buildHTTPHandler(ctx context.Context, router *runtime.RouterIn
 		return nil, err
 	}
 
-	mHandler := m.middlewaresBuilder.BuildChain(ctx, router.Middlewares)
+	mHandler := m.buildMiddlewaresChain(ctx, router.Middlewares, routerName)
 
 	tHandler := func(next http.Handler) (http.Handler, error) {
 		return tracing.NewForwarder(ctx, routerName, router.Service, next), nil
@@ -207,9 +208,32 @@ func (m *Manager) buildHTTPHandler(ctx context.Context, router *runtime.RouterIn
 		chain = chain.Append(metricsMiddle.WrapRouterHandler(ctx, m.metricsRegistry, routerName, provider.GetQualifiedName(ctx, router.Service)))
 	}
 
+	// the timeouts apply to the middlewares too, e.g. to a ForwardAuth request.
+	if router.Timeouts != nil {
+		chain = chain.Append(func(next http.Handler) (http.Handler, error) {
+			return timeouts.New(ctx, next, *router.Timeouts, routerName)
+		})
+	}
+
 	return chain.Extend(*mHandler).Append(tHandler).Then(sHandler)
 }
 
+// buildMiddlewaresChain creates the chain of the middlewares of the router,
+// each of them being wrapped to record its own request duration when the middleware metrics are enabled.
+func (m *Manager) buildMiddlewaresChain(ctx context.Context, middlewares []string, routerName string) *alice.Chain {
+	if m.metricsRegistry == nil || !m.metricsRegistry.IsMiddlewareEnabled() {
+		return m.middlewaresBuilder.BuildChain(ctx, middlewares)
+	}
+
+	chain := alice.New()
+	for _, name := range middlewares {
+		mChain := m.middlewaresBuilder.BuildChain(ctx, []string{name})
+		chain = chain.Append(metricsMiddle.WrapMiddlewareHandler(ctx, m.metricsRegistry, routerName, name, mChain))
+	}
+
+	return &chain
+}
+
 // BuildDefaultHTTPRouter creates a default HTTP router.
 func BuildDefaultHTTPRouter() http.Handler {
 	return http.NotFoundHandler()
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sort"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
@@ -88,6 +89,20 @@ func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[strin
 			continue
 		}
 
+		if routerConfig.SessionTimeout < 0 {
+			err := errors.New("the sessionTimeout of the udp router must be positive")
+			routerConfig.AddError(err, true)
+			logger.Error().Err(err).Send()
+			continue
+		}
+
+		if routerConfig.MaxSessions < 0 {
+			err := errors.New("the maxSessions of the udp router must be positive")
+			routerConfig.AddError(err, true)
+			logger.Error().Err(err).Send()
+			continue
+		}
+
 		handler, err := m.serviceManager.BuildUDP(ctxRouter, routerConfig.Service)
 		if err != nil {
 			routerConfig.AddError(err, true)
@@ -95,6 +110,10 @@ func (m *Manager) buildEntryPointHandlers(ctx context.Context, configs map[strin
 			continue
 		}
 
+		if routerConfig.SessionTimeout > 0 || routerConfig.MaxSessions > 0 {
+			handler = udp.NewSessionHandler(handler, time.Duration(routerConfig.SessionTimeout), routerConfig.MaxSessions)
+		}
+
 		handlers = append(handlers, handler)
 	}
 
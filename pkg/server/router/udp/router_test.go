@@ -3,8 +3,10 @@ package udp
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/server/service/udp"
@@ -103,6 +105,48 @@ func TestRuntimeConfiguration(t *testing.T) {
 			},
 			expectedError: 2,
 		},
+		{
+			desc: "Router with negative session settings",
+			serviceConfig: map[string]*runtime.UDPServiceInfo{
+				"foo-service": {
+					UDPService: &dynamic.UDPService{
+						LoadBalancer: &dynamic.UDPServersLoadBalancer{
+							Servers: []dynamic.UDPServer{
+								{
+									Port:    "8085",
+									Address: "127.0.0.1:8085",
+								},
+							},
+						},
+					},
+				},
+			},
+			routerConfig: map[string]*runtime.UDPRouterInfo{
+				"foo": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints:    []string{"web"},
+						Service:        "foo-service",
+						SessionTimeout: ptypes.Duration(-time.Second),
+					},
+				},
+				"bar": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints: []string{"web"},
+						Service:     "foo-service",
+						MaxSessions: -1,
+					},
+				},
+				"baz": {
+					UDPRouter: &dynamic.UDPRouter{
+						EntryPoints:    []string{"web"},
+						Service:        "foo-service",
+						SessionTimeout: ptypes.Duration(time.Second),
+						MaxSessions:    10,
+					},
+				},
+			},
+			expectedError: 2,
+		},
 	}
 
 	for _, test := range testCases {
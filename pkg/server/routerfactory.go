@@ -25,6 +25,10 @@ type RouterFactory struct {
 	entryPointsTCP []string
 	entryPointsUDP []string
 
+	// dynamicEntryPointsTCP and dynamicEntryPointsUDP are the names of the running entrypoints declared by the providers.
+	dynamicEntryPointsTCP []string
+	dynamicEntryPointsUDP []string
+
 	managerFactory  *service.ManagerFactory
 	metricsRegistry metrics.Registry
 
@@ -69,6 +73,18 @@ func NewRouterFactory(staticConfiguration static.Configuration, managerFactory *
 	}
 }
 
+// SetDynamicEntryPoints sets the names of the running TCP entrypoints declared by the providers,
+// whose routers are created along with the ones of the static entrypoints.
+func (f *RouterFactory) SetDynamicEntryPoints(names []string) {
+	f.dynamicEntryPointsTCP = names
+}
+
+// SetDynamicUDPEntryPoints sets the names of the running UDP entrypoints declared by the providers,
+// whose routers are created along with the ones of the static entrypoints.
+func (f *RouterFactory) SetDynamicUDPEntryPoints(names []string) {
+	f.dynamicEntryPointsUDP = names
+}
+
 // CreateRouters creates new TCPRouters and UDPRouters.
 func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string]*tcprouter.Router, map[string]udp.Handler) {
 	if f.cancelPrevState != nil {
@@ -81,12 +97,14 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	// HTTP
 	serviceManager := f.managerFactory.Build(rtConf)
 
-	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder)
+	middlewaresBuilder := middleware.NewBuilder(rtConf.Middlewares, serviceManager, f.pluginBuilder, f.metricsRegistry)
 
 	routerManager := router.NewManager(rtConf, serviceManager, middlewaresBuilder, f.chainBuilder, f.metricsRegistry, f.tlsManager)
 
-	handlersNonTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, false)
-	handlersTLS := routerManager.BuildHandlers(ctx, f.entryPointsTCP, true)
+	entryPointsTCP := append(append([]string(nil), f.entryPointsTCP...), f.dynamicEntryPointsTCP...)
+
+	handlersNonTLS := routerManager.BuildHandlers(ctx, entryPointsTCP, false)
+	handlersTLS := routerManager.BuildHandlers(ctx, entryPointsTCP, true)
 
 	serviceManager.LaunchHealthCheck(ctx)
 
@@ -96,12 +114,13 @@ func (f *RouterFactory) CreateRouters(rtConf *runtime.Configuration) (map[string
 	middlewaresTCPBuilder := tcpmiddleware.NewBuilder(rtConf.TCPMiddlewares)
 
 	rtTCPManager := tcprouter.NewManager(rtConf, svcTCPManager, middlewaresTCPBuilder, handlersNonTLS, handlersTLS, f.tlsManager)
-	routersTCP := rtTCPManager.BuildHandlers(ctx, f.entryPointsTCP)
+	routersTCP := rtTCPManager.BuildHandlers(ctx, entryPointsTCP)
 
 	// UDP
 	svcUDPManager := udpsvc.NewManager(rtConf)
 	rtUDPManager := udprouter.NewManager(rtConf, svcUDPManager)
-	routersUDP := rtUDPManager.BuildHandlers(ctx, f.entryPointsUDP)
+	entryPointsUDP := append(append([]string(nil), f.entryPointsUDP...), f.dynamicEntryPointsUDP...)
+	routersUDP := rtUDPManager.BuildHandlers(ctx, entryPointsUDP)
 
 	rtConf.PopulateUsedBy()
 
@@ -5,13 +5,17 @@ import (
 	"errors"
 	"os"
 	"os/signal"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/diagnostics"
 	"github.com/traefik/traefik/v3/pkg/metrics"
 	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
 	"github.com/traefik/traefik/v3/pkg/safe"
 	"github.com/traefik/traefik/v3/pkg/server/middleware"
+	"github.com/traefik/traefik/v3/pkg/upgrade"
 )
 
 // Server is the reverse-proxy/load-balancer engine.
@@ -21,8 +25,20 @@ type Server struct {
 	udpEntryPoints UDPEntryPoints
 	chainBuilder   *middleware.ChainBuilder
 
+	dynamicTCPEntryPoints *DynamicTCPEntryPoints // nil when the providers cannot declare entrypoints
+	dynamicUDPEntryPoints *DynamicUDPEntryPoints // nil when the providers cannot declare entrypoints
+
 	accessLoggerMiddleware *accesslog.Handler
 
+	diagnostics *diagnostics.Collector // dumps the diagnostic bundle on SIGUSR2, when not nil
+
+	upgradeReadyTimeout time.Duration // upgrades the binary on SIGHUP, when not zero
+
+	// providers are the providers whose configuration is applied, handed off on upgrade.
+	providersMu      sync.Mutex
+	providers        []string
+	providersUpdated chan struct{}
+
 	signals  chan os.Signal
 	stopChan chan bool
 
@@ -30,20 +46,32 @@ type Server struct {
 }
 
 // NewServer returns an initialized Server.
-func NewServer(routinesPool *safe.Pool, entryPoints TCPEntryPoints, entryPointsUDP UDPEntryPoints, watcher *ConfigurationWatcher,
-	chainBuilder *middleware.ChainBuilder, accessLoggerMiddleware *accesslog.Handler,
+func NewServer(routinesPool *safe.Pool, entryPoints TCPEntryPoints, entryPointsUDP UDPEntryPoints,
+	dynamicEntryPointsTCP *DynamicTCPEntryPoints, dynamicEntryPointsUDP *DynamicUDPEntryPoints,
+	watcher *ConfigurationWatcher, chainBuilder *middleware.ChainBuilder, accessLoggerMiddleware *accesslog.Handler, diagnosticsCollector *diagnostics.Collector,
+	upgradeConfig *static.Upgrade,
 ) *Server {
 	srv := &Server{
 		watcher:                watcher,
 		tcpEntryPoints:         entryPoints,
 		chainBuilder:           chainBuilder,
 		accessLoggerMiddleware: accessLoggerMiddleware,
+		diagnostics:            diagnosticsCollector,
+		providersUpdated:       make(chan struct{}),
 		signals:                make(chan os.Signal, 1),
 		stopChan:               make(chan bool, 1),
 		routinesPool:           routinesPool,
 		udpEntryPoints:         entryPointsUDP,
+		dynamicTCPEntryPoints:  dynamicEntryPointsTCP,
+		dynamicUDPEntryPoints:  dynamicEntryPointsUDP,
+	}
+
+	if upgradeConfig != nil {
+		srv.upgradeReadyTimeout = time.Duration(upgradeConfig.ReadyTimeout)
 	}
 
+	watcher.AddProvidersListener(srv.setProviders)
+
 	srv.configureSignals()
 
 	return srv
@@ -59,11 +87,90 @@ func (s *Server) Start(ctx context.Context) {
 		s.Stop()
 	}()
 
+	if upgrade.Inherited() {
+		// the previous process serves the requests until the configuration of its providers is applied.
+		s.watcher.Start()
+		safe.Go(func() { s.takeOver(ctx) })
+	} else {
+		s.startEntryPoints()
+		s.watcher.Start()
+	}
+
+	s.routinesPool.GoCtx(s.listenSignals)
+}
+
+func (s *Server) startEntryPoints() {
 	s.tcpEntryPoints.Start()
 	s.udpEntryPoints.Start()
-	s.watcher.Start()
+}
 
-	s.routinesPool.GoCtx(s.listenSignals)
+// takeOver starts the entry points, on the listeners inherited from the previous process,
+// once the configuration of the providers of the previous process is applied, and reports the readiness to it.
+func (s *Server) takeOver(ctx context.Context) {
+	providers := upgrade.Providers()
+	log.Info().Strs("providers", providers).Msg("Waiting for the configuration of the providers before taking over")
+
+	if !s.waitProviders(ctx, providers) {
+		return
+	}
+
+	s.startEntryPoints()
+
+	if err := upgrade.Ready(); err != nil {
+		log.Error().Err(err).Msg("Error taking over")
+		return
+	}
+
+	log.Info().Msg("Took over the listeners of the previous process")
+}
+
+func (s *Server) setProviders(providers []string) {
+	s.providersMu.Lock()
+	defer s.providersMu.Unlock()
+
+	s.providers = providers
+
+	close(s.providersUpdated)
+	s.providersUpdated = make(chan struct{})
+}
+
+func (s *Server) appliedProviders() []string {
+	s.providersMu.Lock()
+	defer s.providersMu.Unlock()
+
+	return s.providers
+}
+
+// waitProviders waits for the configuration of the given providers to be applied,
+// and reports whether it is before the context is done.
+func (s *Server) waitProviders(ctx context.Context, providers []string) bool {
+	for {
+		s.providersMu.Lock()
+		applied := make(map[string]struct{}, len(s.providers))
+		for _, name := range s.providers {
+			applied[name] = struct{}{}
+		}
+		updated := s.providersUpdated
+		s.providersMu.Unlock()
+
+		missing := false
+		for _, name := range providers {
+			if _, ok := applied[name]; !ok {
+				missing = true
+				break
+			}
+		}
+
+		if !missing {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-updated:
+		}
+	}
 }
 
 // Wait blocks until the server shutdown.
@@ -77,6 +184,12 @@ func (s *Server) Stop() {
 
 	s.tcpEntryPoints.Stop()
 	s.udpEntryPoints.Stop()
+	if s.dynamicTCPEntryPoints != nil {
+		s.dynamicTCPEntryPoints.Stop()
+	}
+	if s.dynamicUDPEntryPoints != nil {
+		s.dynamicUDPEntryPoints.Stop()
+	}
 
 	s.stopChan <- true
 }
@@ -0,0 +1,199 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/logs"
+)
+
+// dynamicEntryPoint is an entrypoint declared by the providers, started and stopped by the DynamicEntryPoints whatever its protocol.
+type dynamicEntryPoint interface {
+	// Start serves the entrypoint until it is shut down.
+	Start(ctx context.Context)
+	// release releases the port of the entrypoint, when possible before it is shut down, so that it can be reused right away.
+	release() error
+	// Shutdown stops the entrypoint, giving its open connections the grace timeout to complete.
+	Shutdown(ctx context.Context)
+}
+
+// startDynamicEntryPoint creates the entrypoint of a protocol listening on the port, and starts it.
+type startDynamicEntryPoint func(ctx context.Context, name, host string, port int) (dynamicEntryPoint, error)
+
+// DynamicEntryPoints holds the entrypoints declared by the providers,
+// listening on the ports of the range allowed by the static configuration.
+// It is shared by the TCP and UDP entrypoints, so that a name is used by a single entrypoint of either protocol.
+type DynamicEntryPoints struct {
+	host    string
+	minPort int
+	maxPort int
+
+	staticEntryPoints static.EntryPoints
+
+	entryPointsMu sync.Mutex
+	entryPoints   map[string]*runningDynamicEntryPoint
+}
+
+type runningDynamicEntryPoint struct {
+	protocol   string
+	port       int
+	entryPoint dynamicEntryPoint
+}
+
+// NewDynamicEntryPoints creates a new DynamicEntryPoints.
+func NewDynamicEntryPoints(config *static.DynamicEntryPoints, staticEntryPoints static.EntryPoints) (*DynamicEntryPoints, error) {
+	minPort, maxPort, err := config.Ports()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DynamicEntryPoints{
+		host:              config.Host,
+		minPort:           minPort,
+		maxPort:           maxPort,
+		staticEntryPoints: staticEntryPoints,
+		entryPoints:       make(map[string]*runningDynamicEntryPoint),
+	}, nil
+}
+
+// update starts the entrypoints of the protocol added to the configuration, given by their ports, and stops the ones removed from it.
+// The entrypoints whose port has changed are restarted.
+// It returns the names of the running entrypoints of the protocol.
+func (d *DynamicEntryPoints) update(protocol string, ports map[string]int, start startDynamicEntryPoint) []string {
+	d.entryPointsMu.Lock()
+	defer d.entryPointsMu.Unlock()
+
+	for name, ep := range d.entryPoints {
+		if ep.protocol != protocol {
+			continue
+		}
+
+		if port, ok := ports[name]; ok && port == ep.port {
+			continue
+		}
+
+		d.stop(name, ep.entryPoint)
+		delete(d.entryPoints, name)
+	}
+
+	// the entrypoints are started in a predictable order, so that the conflicts are resolved the same way on each update.
+	names := make([]string, 0, len(ports))
+	for name := range ports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if ep, ok := d.entryPoints[name]; ok && ep.protocol == protocol {
+			continue
+		}
+
+		logger := log.With().Str(logs.EntryPointName, name).Logger()
+
+		if err := d.check(protocol, name, ports[name]); err != nil {
+			logger.Error().Err(err).Msg("Invalid dynamic entrypoint")
+			continue
+		}
+
+		entryPoint, err := start(logger.WithContext(context.Background()), name, d.host, ports[name])
+		if err != nil {
+			logger.Error().Err(err).Msg("Unable to start the dynamic entrypoint")
+			continue
+		}
+
+		d.entryPoints[name] = &runningDynamicEntryPoint{protocol: protocol, port: ports[name], entryPoint: entryPoint}
+	}
+
+	var running []string
+	for name, ep := range d.entryPoints {
+		if ep.protocol == protocol {
+			running = append(running, name)
+		}
+	}
+	sort.Strings(running)
+
+	return running
+}
+
+// each calls fn with the running entrypoints of the protocol.
+func (d *DynamicEntryPoints) each(protocol string, fn func(name string, entryPoint dynamicEntryPoint)) {
+	d.entryPointsMu.Lock()
+	defer d.entryPointsMu.Unlock()
+
+	for name, ep := range d.entryPoints {
+		if ep.protocol == protocol {
+			fn(name, ep.entryPoint)
+		}
+	}
+}
+
+// shutdown stops the running entrypoints of the protocol, and waits for them to be closed.
+func (d *DynamicEntryPoints) shutdown(protocol string) {
+	d.entryPointsMu.Lock()
+	defer d.entryPointsMu.Unlock()
+
+	var wg sync.WaitGroup
+	for name, ep := range d.entryPoints {
+		if ep.protocol != protocol {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func(name string, entryPoint dynamicEntryPoint) {
+			defer wg.Done()
+
+			logger := log.With().Str(logs.EntryPointName, name).Logger()
+			entryPoint.Shutdown(logger.WithContext(context.Background()))
+
+			logger.Debug().Msg("Dynamic entrypoint closed")
+		}(name, ep.entryPoint)
+
+		delete(d.entryPoints, name)
+	}
+
+	wg.Wait()
+}
+
+func (d *DynamicEntryPoints) check(protocol, name string, port int) error {
+	if _, ok := d.staticEntryPoints[name]; ok {
+		return errors.New("an entrypoint with the same name is defined by the static configuration")
+	}
+
+	if ep, ok := d.entryPoints[name]; ok {
+		return fmt.Errorf("an entrypoint with the same name is declared with the %s protocol", ep.protocol)
+	}
+
+	if port < d.minPort || port > d.maxPort {
+		return fmt.Errorf("port %d is out of the allowed range %d-%d", port, d.minPort, d.maxPort)
+	}
+
+	// the entrypoints of different protocols can listen on the same port.
+	for other, ep := range d.entryPoints {
+		if ep.protocol == protocol && ep.port == port {
+			return fmt.Errorf("port %d is already used by the entrypoint %s", port, other)
+		}
+	}
+
+	return nil
+}
+
+// stop releases the port of the entrypoint right away when possible,
+// and gives its open connections the grace timeout to complete.
+func (d *DynamicEntryPoints) stop(name string, entryPoint dynamicEntryPoint) {
+	logger := log.With().Str(logs.EntryPointName, name).Logger()
+
+	if err := entryPoint.release(); err != nil {
+		logger.Debug().Err(err).Msg("Unable to release the port of the dynamic entrypoint")
+	}
+
+	go func() {
+		entryPoint.Shutdown(logger.WithContext(context.Background()))
+		logger.Info().Msg("Dynamic entrypoint closed")
+	}()
+}
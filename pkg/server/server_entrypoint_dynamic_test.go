@@ -0,0 +1,61 @@
+package server
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+)
+
+func TestDynamicEntryPoints_protocols(t *testing.T) {
+	port1, port2 := freePort(t), freePort(t)
+	minPort, maxPort := port1, port2
+	if minPort > maxPort {
+		minPort, maxPort = maxPort, minPort
+	}
+
+	config := &static.DynamicEntryPoints{
+		PortRange: fmt.Sprintf("%d-%d", minPort, maxPort),
+		Host:      "127.0.0.1",
+	}
+
+	dynamicEntryPoints, err := NewDynamicEntryPoints(config, static.EntryPoints{})
+	require.NoError(t, err)
+
+	tcpEntryPoints := NewDynamicTCPEntryPoints(dynamicEntryPoints, nil, metrics.NewVoidRegistry())
+	t.Cleanup(tcpEntryPoints.Stop)
+
+	udpEntryPoints := NewDynamicUDPEntryPoints(dynamicEntryPoints)
+	t.Cleanup(udpEntryPoints.Stop)
+
+	running := tcpEntryPoints.Update(map[string]*dynamic.TCPEntryPoint{
+		"dns": {Port: port1},
+	})
+	assert.Equal(t, []string{"dns"}, running)
+
+	// the name of a TCP entrypoint cannot be used by a UDP entrypoint, unlike its port.
+	running = udpEntryPoints.Update(map[string]*dynamic.UDPEntryPoint{
+		"dns":    {Port: port2},
+		"dnsUDP": {Port: port1},
+	})
+	assert.Equal(t, []string{"dnsUDP"}, running)
+
+	// the name is available once the TCP entrypoint is removed.
+	running = tcpEntryPoints.Update(nil)
+	assert.Empty(t, running)
+
+	running = udpEntryPoints.Update(map[string]*dynamic.UDPEntryPoint{
+		"dns":    {Port: port2},
+		"dnsUDP": {Port: port1},
+	})
+	assert.Equal(t, []string{"dns", "dnsUDP"}, running)
+
+	running = tcpEntryPoints.Update(map[string]*dynamic.TCPEntryPoint{
+		"dns": {Port: port1},
+	})
+	assert.Empty(t, running)
+}
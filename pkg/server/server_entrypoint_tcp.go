@@ -32,6 +32,7 @@ import (
 	tcprouter "github.com/traefik/traefik/v3/pkg/server/router/tcp"
 	"github.com/traefik/traefik/v3/pkg/tcp"
 	"github.com/traefik/traefik/v3/pkg/types"
+	"github.com/traefik/traefik/v3/pkg/upgrade"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
 )
@@ -126,7 +127,10 @@ func (eps TCPEntryPoints) Stop() {
 // Switch the TCP routers.
 func (eps TCPEntryPoints) Switch(routersTCP map[string]*tcprouter.Router) {
 	for entryPointName, rt := range routersTCP {
-		eps[entryPointName].SwitchRouter(rt)
+		// the routers of the dynamic entrypoints are switched by the DynamicTCPEntryPoints.
+		if ep, ok := eps[entryPointName]; ok {
+			ep.SwitchRouter(rt)
+		}
 	}
 }
 
@@ -136,6 +140,7 @@ type TCPEntryPoint struct {
 	switcher               *tcp.HandlerSwitcher
 	transportConfiguration *static.EntryPointsTransport
 	tracker                *connectionTracker
+	webSockets             *webSocketTracker
 	httpServer             *httpServer
 	httpsServer            *httpServer
 
@@ -155,14 +160,24 @@ func NewTCPEntryPoint(ctx context.Context, configuration *static.EntryPoint, hos
 
 	reqDecorator := requestdecorator.New(hostResolverConfig)
 
-	httpServer, err := createHTTPServer(ctx, listener, configuration, true, reqDecorator)
+	limiter, err := newInFlightLimiter(configuration.Transport.InFlightRequests)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing server: %w", err)
+	}
+
+	webSockets, err := newWebSocketTracker(configuration.Transport.WebSockets)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing server: %w", err)
+	}
+
+	httpServer, err := createHTTPServer(ctx, listener, configuration, true, reqDecorator, limiter, webSockets)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing http server: %w", err)
 	}
 
 	rt.SetHTTPForwarder(httpServer.Forwarder)
 
-	httpsServer, err := createHTTPServer(ctx, listener, configuration, false, reqDecorator)
+	httpsServer, err := createHTTPServer(ctx, listener, configuration, false, reqDecorator, limiter, webSockets)
 	if err != nil {
 		return nil, fmt.Errorf("error preparing https server: %w", err)
 	}
@@ -182,6 +197,7 @@ func NewTCPEntryPoint(ctx context.Context, configuration *static.EntryPoint, hos
 		switcher:               tcpSwitcher,
 		transportConfiguration: configuration.Transport,
 		tracker:                tracker,
+		webSockets:             webSockets,
 		httpServer:             httpServer,
 		httpsServer:            httpsServer,
 		http3Server:            h3Server,
@@ -256,6 +272,10 @@ func (e *TCPEntryPoint) Shutdown(ctx context.Context) {
 		time.Sleep(reqAcceptGraceTimeOut)
 	}
 
+	// the WebSocket connections are closed by their clients during the grace timeout,
+	// rather than being cut off when it is over.
+	e.webSockets.Shutdown(ctx)
+
 	graceTimeOut := time.Duration(e.transportConfiguration.LifeCycle.GraceTimeOut)
 	ctx, cancel := context.WithTimeout(ctx, graceTimeOut)
 	logger.Debug().Msgf("Waiting %s seconds before killing connections", graceTimeOut)
@@ -317,6 +337,11 @@ func (e *TCPEntryPoint) Shutdown(ctx context.Context) {
 	cancel()
 }
 
+// Addr returns the address the entry point listens on.
+func (e *TCPEntryPoint) Addr() net.Addr {
+	return e.listener.Addr()
+}
+
 // SwitchRouter switches the TCP router handler.
 func (e *TCPEntryPoint) SwitchRouter(rt *tcprouter.Router) {
 	rt.SetHTTPForwarder(e.httpServer.Forwarder)
@@ -399,9 +424,15 @@ func (ln tcpKeepAliveListener) Accept() (net.Conn, error) {
 }
 
 func buildProxyProtocolListener(ctx context.Context, entryPoint *static.EntryPoint, listener net.Listener) (net.Listener, error) {
-	proxyListener := &proxyproto.Listener{Listener: listener}
-
 	if entryPoint.ProxyProtocol.Insecure {
+		proxyListener := &proxyproto.Listener{Listener: listener}
+
+		if entryPoint.ProxyProtocol.Required {
+			proxyListener.Policy = func(upstream net.Addr) (proxyproto.Policy, error) {
+				return proxyproto.REQUIRE, nil
+			}
+		}
+
 		log.Ctx(ctx).Info().Msg("Enabling ProxyProtocol without trusted IPs: Insecure")
 		return proxyListener, nil
 	}
@@ -411,6 +442,22 @@ func buildProxyProtocolListener(ctx context.Context, entryPoint *static.EntryPoi
 		return nil, err
 	}
 
+	if entryPoint.ProxyProtocol.Required {
+		// the connections of the trusted IPs without PROXY protocol header are closed on their first read.
+		listener = &trustedIPsListener{Listener: listener, ctx: ctx, checker: checker}
+		proxyListener := &proxyproto.Listener{
+			Listener: listener,
+			Policy: func(upstream net.Addr) (proxyproto.Policy, error) {
+				return proxyproto.REQUIRE, nil
+			},
+		}
+
+		log.Ctx(ctx).Info().Msgf("Requiring ProxyProtocol for trusted IPs %v", entryPoint.ProxyProtocol.TrustedIPs)
+
+		return proxyListener, nil
+	}
+
+	proxyListener := &proxyproto.Listener{Listener: listener}
 	proxyListener.Policy = func(upstream net.Addr) (proxyproto.Policy, error) {
 		ipAddr, ok := upstream.(*net.TCPAddr)
 		if !ok {
@@ -430,7 +477,7 @@ func buildProxyProtocolListener(ctx context.Context, entryPoint *static.EntryPoi
 }
 
 func buildListener(ctx context.Context, entryPoint *static.EntryPoint) (net.Listener, error) {
-	listener, err := net.Listen("tcp", entryPoint.GetAddress())
+	listener, err := upgrade.Listen("tcp", entryPoint.GetAddress())
 	if err != nil {
 		return nil, fmt.Errorf("error opening listener: %w", err)
 	}
@@ -532,7 +579,7 @@ type httpServer struct {
 	Switcher  *middlewares.HTTPHandlerSwitcher
 }
 
-func createHTTPServer(ctx context.Context, ln net.Listener, configuration *static.EntryPoint, withH2c bool, reqDecorator *requestdecorator.RequestDecorator) (*httpServer, error) {
+func createHTTPServer(ctx context.Context, ln net.Listener, configuration *static.EntryPoint, withH2c bool, reqDecorator *requestdecorator.RequestDecorator, limiter *inFlightLimiter, webSockets *webSocketTracker) (*httpServer, error) {
 	if configuration.HTTP2.MaxConcurrentStreams < 0 {
 		return nil, errors.New("max concurrent streams value must be greater than or equal to zero")
 	}
@@ -544,6 +591,9 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 		return nil, err
 	}
 
+	next = limiter.wrap(next)
+	next = webSockets.wrap(next)
+
 	var handler http.Handler
 	handler, err = forwardedheaders.NewXForwarded(
 		configuration.ForwardedHeaders.Insecure,
@@ -553,6 +603,11 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 		return nil, err
 	}
 
+	tlvHeaders := configuration.ProxyProtocol != nil && configuration.ProxyProtocol.TLVHeaders
+	if tlvHeaders {
+		handler = proxyProtocolTLVHeaders(handler)
+	}
+
 	handler = http.AllowQuerySemicolons(handler)
 
 	handler = contenttype.DisableAutoDetection(handler)
@@ -571,6 +626,10 @@ func createHTTPServer(ctx context.Context, ln net.Listener, configuration *stati
 		IdleTimeout:  time.Duration(configuration.Transport.RespondingTimeouts.IdleTimeout),
 	}
 
+	if tlvHeaders {
+		serverHTTP.ConnContext = withProxyProtocolConn
+	}
+
 	// ConfigureServer configures HTTP/2 with the MaxConcurrentStreams option for the given server.
 	// Also keeping behavior the same as
 	// https://cs.opensource.google/go/go/+/refs/tags/go1.17.7:src/net/http/server.go;l=3262
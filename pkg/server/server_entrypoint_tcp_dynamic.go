@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/logs"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+	tcprouter "github.com/traefik/traefik/v3/pkg/server/router/tcp"
+	"github.com/traefik/traefik/v3/pkg/types"
+)
+
+// DynamicTCPEntryPoints holds the TCP entrypoints declared by the providers, registered in the DynamicEntryPoints.
+type DynamicTCPEntryPoints struct {
+	entryPoints *DynamicEntryPoints
+
+	hostResolverConfig *types.HostResolverConfig
+	metricsRegistry    metrics.Registry
+}
+
+// NewDynamicTCPEntryPoints creates a new DynamicTCPEntryPoints.
+func NewDynamicTCPEntryPoints(entryPoints *DynamicEntryPoints, hostResolverConfig *types.HostResolverConfig, metricsRegistry metrics.Registry) *DynamicTCPEntryPoints {
+	return &DynamicTCPEntryPoints{
+		entryPoints:        entryPoints,
+		hostResolverConfig: hostResolverConfig,
+		metricsRegistry:    metricsRegistry,
+	}
+}
+
+// Update starts the entrypoints added to the configuration, and stops the ones removed from it.
+// The entrypoints whose port has changed are restarted.
+// It returns the names of the running entrypoints.
+func (d *DynamicTCPEntryPoints) Update(configurations map[string]*dynamic.TCPEntryPoint) []string {
+	ports := make(map[string]int, len(configurations))
+	for name, config := range configurations {
+		if config == nil {
+			log.Error().Str(logs.EntryPointName, name).Msg("Invalid dynamic entrypoint: empty configuration")
+			continue
+		}
+
+		ports[name] = config.Port
+	}
+
+	return d.entryPoints.update("tcp", ports, d.start)
+}
+
+// Switch the TCP routers of the running entrypoints.
+func (d *DynamicTCPEntryPoints) Switch(routersTCP map[string]*tcprouter.Router) {
+	d.entryPoints.each("tcp", func(name string, entryPoint dynamicEntryPoint) {
+		if rt, ok := routersTCP[name]; ok {
+			entryPoint.(*TCPEntryPoint).SwitchRouter(rt)
+		}
+	})
+}
+
+// Stop the running entrypoints.
+func (d *DynamicTCPEntryPoints) Stop() {
+	d.entryPoints.shutdown("tcp")
+}
+
+func (d *DynamicTCPEntryPoints) start(ctx context.Context, name, host string, port int) (dynamicEntryPoint, error) {
+	config := &static.EntryPoint{}
+	config.SetDefaults()
+	config.Address = net.JoinHostPort(host, strconv.Itoa(port))
+
+	openConnectionsGauge := d.metricsRegistry.
+		OpenConnectionsGauge().
+		With("entrypoint", name, "protocol", "TCP")
+
+	entryPoint, err := NewTCPEntryPoint(ctx, config, d.hostResolverConfig, openConnectionsGauge)
+	if err != nil {
+		return nil, err
+	}
+
+	go entryPoint.Start(ctx)
+
+	log.Ctx(ctx).Info().Msgf("Dynamic entrypoint listening on %s", config.Address)
+
+	return entryPoint, nil
+}
+
+// release closes the listener of the entrypoint, so that its port can be reused while its open connections complete.
+func (e *TCPEntryPoint) release() error {
+	return e.listener.Close()
+}
@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/metrics"
+	tcprouter "github.com/traefik/traefik/v3/pkg/server/router/tcp"
+)
+
+func TestDynamicTCPEntryPoints(t *testing.T) {
+	port1, port2 := freePort(t), freePort(t)
+	minPort, maxPort := port1, port2
+	if minPort > maxPort {
+		minPort, maxPort = maxPort, minPort
+	}
+
+	config := &static.DynamicEntryPoints{
+		PortRange: fmt.Sprintf("%d-%d", minPort, maxPort),
+		Host:      "127.0.0.1",
+	}
+	staticEntryPoints := static.EntryPoints{"web": &static.EntryPoint{Address: ":80"}}
+
+	dynamicEntryPoints, err := NewDynamicEntryPoints(config, staticEntryPoints)
+	require.NoError(t, err)
+
+	eps := NewDynamicTCPEntryPoints(dynamicEntryPoints, nil, metrics.NewVoidRegistry())
+	t.Cleanup(eps.Stop)
+
+	running := eps.Update(map[string]*dynamic.TCPEntryPoint{
+		"redis":      {Port: port1},
+		"duplicate":  {Port: port1},
+		"web":        {Port: port2},
+		"outOfRange": {Port: maxPort + 1},
+	})
+	// the entrypoints are started in the order of their names, the duplicate one gets the port first.
+	assert.Equal(t, []string{"duplicate"}, running)
+
+	running = eps.Update(map[string]*dynamic.TCPEntryPoint{
+		"redis":    {Port: port1},
+		"postgres": {Port: port2},
+	})
+	assert.Equal(t, []string{"postgres", "redis"}, running)
+
+	router := &tcprouter.Router{}
+	router.SetHTTPHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusTeapot)
+	}))
+	eps.Switch(map[string]*tcprouter.Router{"redis": router})
+
+	resp, err := http.Get("http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port1)))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+
+	running = eps.Update(map[string]*dynamic.TCPEntryPoint{
+		"postgres": {Port: port2},
+	})
+	assert.Equal(t, []string{"postgres"}, running)
+
+	// the listener of a removed entrypoint is closed right away.
+	_, err = net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port1)), time.Second)
+	require.Error(t, err)
+}
+
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ln.Close() }()
+
+	return ln.Addr().(*net.TCPAddr).Port
+}
@@ -8,11 +8,14 @@ import (
 	"net"
 	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/quic-go/quic-go/http3"
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/static"
 	tcprouter "github.com/traefik/traefik/v3/pkg/server/router/tcp"
+	"github.com/traefik/traefik/v3/pkg/upgrade"
 )
 
 type http3server struct {
@@ -20,6 +23,10 @@ type http3server struct {
 
 	http3conn net.PacketConn
 
+	// activeRequests is the number of HTTP/3 requests being served, awaited by the graceful shutdown.
+	activeRequests atomic.Int64
+	shuttingDown   atomic.Bool
+
 	lock   sync.RWMutex
 	getter func(info *tls.ClientHelloInfo) (*tls.Config, error)
 }
@@ -33,7 +40,7 @@ func newHTTP3Server(ctx context.Context, configuration *static.EntryPoint, https
 		return nil, errors.New("advertised port must be greater than or equal to zero")
 	}
 
-	conn, err := net.ListenPacket("udp", configuration.GetAddress())
+	conn, err := upgrade.ListenPacket("udp", configuration.GetAddress())
 	if err != nil {
 		return nil, fmt.Errorf("starting listener: %w", err)
 	}
@@ -48,13 +55,19 @@ func newHTTP3Server(ctx context.Context, configuration *static.EntryPoint, https
 	h3.Server = &http3.Server{
 		Addr:      configuration.GetAddress(),
 		Port:      configuration.HTTP3.AdvertisedPort,
-		Handler:   httpsServer.Server.(*http.Server).Handler,
+		Handler:   h3.trackRequests(httpsServer.Server.(*http.Server).Handler),
 		TLSConfig: &tls.Config{GetConfigForClient: h3.getGetConfigForClient},
 	}
 
 	previousHandler := httpsServer.Server.(*http.Server).Handler
 
 	httpsServer.Server.(*http.Server).Handler = http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		// HTTP/3 is not advertised anymore once the shutdown has started, so that the clients stay on TCP.
+		if h3.shuttingDown.Load() {
+			previousHandler.ServeHTTP(rw, req)
+			return
+		}
+
 		if err := h3.Server.SetQuicHeaders(rw.Header()); err != nil {
 			log.Ctx(ctx).Error().Err(err).Msg("Failed to set HTTP3 headers")
 		}
@@ -83,7 +96,31 @@ func (e *http3server) getGetConfigForClient(info *tls.ClientHelloInfo) (*tls.Con
 	return e.getter(info)
 }
 
-func (e *http3server) Shutdown(_ context.Context) error {
-	// TODO: use e.Server.CloseGracefully() when available.
+func (e *http3server) trackRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		e.activeRequests.Add(1)
+		defer e.activeRequests.Add(-1)
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// Shutdown stops advertising HTTP/3 and waits for the active requests to complete before closing the server,
+// as http3.Server.CloseGracefully is not implemented.
+// If the context expires first, the context's error is returned and the server is left open.
+func (e *http3server) Shutdown(ctx context.Context) error {
+	e.shuttingDown.Store(true)
+
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for e.activeRequests.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
 	return e.Server.Close()
 }
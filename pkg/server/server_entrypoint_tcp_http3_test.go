@@ -5,9 +5,11 @@ import (
 	"context"
 	"crypto/tls"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/quic-go/quic-go/http3"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v3/pkg/config/static"
@@ -129,3 +131,33 @@ func TestHTTP3AdvertisedPort(t *testing.T) {
 	assert.NotContains(t, r.Header.Get("Alt-Svc"), ":8090")
 	assert.Contains(t, r.Header.Get("Alt-Svc"), ":8080")
 }
+
+func TestHTTP3Shutdown(t *testing.T) {
+	h3 := &http3server{Server: &http3.Server{}}
+
+	block := make(chan struct{})
+	handler := h3.trackRequests(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		<-block
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "https://127.0.0.1", nil))
+	}()
+
+	require.Eventually(t, func() bool { return h3.activeRequests.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	err := h3.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, h3.shuttingDown.Load())
+
+	close(block)
+	<-done
+
+	err = h3.Shutdown(context.Background())
+	assert.NoError(t, err)
+}
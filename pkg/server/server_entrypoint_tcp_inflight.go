@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+)
+
+// inFlightLimiter limits the number of requests handled at the same time by an entry point,
+// shared by its HTTP, HTTPS and HTTP/3 servers.
+type inFlightLimiter struct {
+	// slots holds a token for each request being handled.
+	slots chan struct{}
+	// queue holds a token for each request waiting for a slot.
+	queue        chan struct{}
+	queueTimeout time.Duration
+}
+
+func newInFlightLimiter(config *static.InFlightRequests) (*inFlightLimiter, error) {
+	if config == nil {
+		return nil, nil
+	}
+
+	if config.Amount <= 0 {
+		return nil, errors.New("in-flight requests amount must be greater than zero")
+	}
+
+	if config.QueueSize < 0 {
+		return nil, errors.New("in-flight requests queue size must be greater than or equal to zero")
+	}
+
+	return &inFlightLimiter{
+		slots:        make(chan struct{}, config.Amount),
+		queue:        make(chan struct{}, config.QueueSize),
+		queueTimeout: time.Duration(config.QueueTimeout),
+	}, nil
+}
+
+// wrap returns a handler limiting the requests handled at the same time by the next handler,
+// the requests refused because the queue is full or the queue timeout is reached get a 503 response.
+// A nil limiter returns the next handler.
+func (l *inFlightLimiter) wrap(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if err := l.acquire(req.Context()); err != nil {
+			log.Ctx(req.Context()).Debug().Err(err).Msg("Refusing the request")
+
+			if req.Context().Err() == nil {
+				http.Error(rw, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+			}
+			return
+		}
+		defer l.release()
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+func (l *inFlightLimiter) acquire(ctx context.Context) error {
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	default:
+	}
+
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		return errors.New("too many in-flight requests")
+	}
+	defer func() { <-l.queue }()
+
+	var timeout <-chan time.Time
+	if l.queueTimeout > 0 {
+		timer := time.NewTimer(l.queueTimeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-timeout:
+		return errors.New("in-flight requests queue timeout reached")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *inFlightLimiter) release() {
+	<-l.slots
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+)
+
+func TestNewInFlightLimiter(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      *static.InFlightRequests
+		expectedErr bool
+	}{
+		{
+			desc: "no limit",
+		},
+		{
+			desc:   "amount",
+			config: &static.InFlightRequests{Amount: 1},
+		},
+		{
+			desc:        "zero amount",
+			config:      &static.InFlightRequests{},
+			expectedErr: true,
+		},
+		{
+			desc:        "negative queue size",
+			config:      &static.InFlightRequests{Amount: 1, QueueSize: -1},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := newInFlightLimiter(test.config)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestInFlightLimiter(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		config         *static.InFlightRequests
+		expectedStatus int
+	}{
+		{
+			desc:           "no queue",
+			config:         &static.InFlightRequests{Amount: 1},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			desc:           "queued until the slot is released",
+			config:         &static.InFlightRequests{Amount: 1, QueueSize: 1, QueueTimeout: ptypes.Duration(5 * time.Second)},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			desc:           "queue timeout",
+			config:         &static.InFlightRequests{Amount: 1, QueueSize: 1, QueueTimeout: ptypes.Duration(10 * time.Millisecond)},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			limiter, err := newInFlightLimiter(test.config)
+			require.NoError(t, err)
+
+			started := make(chan struct{}, 1)
+			unblock := make(chan struct{})
+
+			handler := limiter.wrap(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				if req.URL.Path == "/blocking" {
+					started <- struct{}{}
+					<-unblock
+				}
+				rw.WriteHeader(http.StatusOK)
+			}))
+
+			blockingDone := make(chan struct{})
+			go func() {
+				defer close(blockingDone)
+				handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/blocking", nil))
+			}()
+			<-started
+
+			time.AfterFunc(100*time.Millisecond, func() { close(unblock) })
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+
+			<-blockingDone
+
+			// the slot is released once the request is handled.
+			recorder = httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, http.StatusOK, recorder.Code)
+		})
+	}
+}
+
+func TestInFlightLimiter_queueFull(t *testing.T) {
+	limiter, err := newInFlightLimiter(&static.InFlightRequests{Amount: 1, QueueSize: 1, QueueTimeout: ptypes.Duration(5 * time.Second)})
+	require.NoError(t, err)
+
+	// takes the slot and the place in the queue.
+	limiter.slots <- struct{}{}
+	limiter.queue <- struct{}{}
+
+	handler := limiter.wrap(http.HandlerFunc(func(rw http.ResponseWriter, _ *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/pires/go-proxyproto/tlvparse"
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/ip"
+	tcprouter "github.com/traefik/traefik/v3/pkg/server/router/tcp"
+)
+
+// proxyProtocolHeaderPrefix is the prefix of the request headers set from the TLVs of the PROXY protocol v2 header.
+const proxyProtocolHeaderPrefix = "X-Proxy-Protocol-"
+
+// trustedIPsListener closes the connections which are not opened by a trusted IP.
+type trustedIPsListener struct {
+	net.Listener
+
+	ctx     context.Context
+	checker *ip.Checker
+}
+
+func (l *trustedIPsListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		addr, ok := conn.RemoteAddr().(*net.TCPAddr)
+		if ok && l.checker.ContainsIP(addr.IP) {
+			return conn, nil
+		}
+
+		log.Ctx(l.ctx).Debug().Msgf("Closing the connection from %s, which is not in the trusted IPs list", conn.RemoteAddr())
+		_ = conn.Close()
+	}
+}
+
+type proxyProtocolConnKey struct{}
+
+// withProxyProtocolConn is an http.Server ConnContext, adding the PROXY protocol connection, if any, to the context.
+func withProxyProtocolConn(ctx context.Context, conn net.Conn) context.Context {
+	if ppConn := proxyProtocolConn(conn); ppConn != nil {
+		return context.WithValue(ctx, proxyProtocolConnKey{}, ppConn)
+	}
+	return ctx
+}
+
+// proxyProtocolConn returns the PROXY protocol connection wrapped by the given connection, if any.
+func proxyProtocolConn(conn net.Conn) *proxyproto.Conn {
+	for {
+		switch c := conn.(type) {
+		case *proxyproto.Conn:
+			return c
+		case *tls.Conn:
+			conn = c.NetConn()
+		case *tcprouter.Conn:
+			conn = c.WriteCloser
+		case *trackedConnection:
+			conn = c.WriteCloser
+		case *writeCloserWrapper:
+			conn = c.Conn
+		default:
+			return nil
+		}
+	}
+}
+
+// proxyProtocolTLVHeaders sets the TLVs of the PROXY protocol v2 header of the connection as request headers,
+// removing the ones sent by the client.
+func proxyProtocolTLVHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		for name := range req.Header {
+			if strings.HasPrefix(name, proxyProtocolHeaderPrefix) {
+				req.Header.Del(name)
+			}
+		}
+
+		ppConn, ok := req.Context().Value(proxyProtocolConnKey{}).(*proxyproto.Conn)
+		if !ok {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		header := ppConn.ProxyHeader()
+		if header == nil {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		tlvs, err := header.TLVs()
+		if err != nil {
+			log.Ctx(req.Context()).Debug().Err(err).Msg("Invalid PROXY protocol TLVs")
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		for name, value := range tlvHeaders(tlvs) {
+			req.Header.Set(name, value)
+		}
+
+		next.ServeHTTP(rw, req)
+	})
+}
+
+// tlvHeaders returns the request headers of the TLVs.
+// The values of the known TLVs are decoded, and the others are hex-encoded in a header named after their type.
+func tlvHeaders(tlvs []proxyproto.TLV) map[string]string {
+	headers := make(map[string]string)
+
+	for _, tlv := range tlvs {
+		switch tlv.Type {
+		case proxyproto.PP2_TYPE_ALPN:
+			headers[proxyProtocolHeaderPrefix+"Alpn"] = string(tlv.Value)
+			continue
+
+		case proxyproto.PP2_TYPE_AUTHORITY:
+			headers[proxyProtocolHeaderPrefix+"Authority"] = string(tlv.Value)
+			continue
+
+		case proxyproto.PP2_TYPE_UNIQUE_ID:
+			headers[proxyProtocolHeaderPrefix+"Unique-Id"] = hex.EncodeToString(tlv.Value)
+			continue
+
+		case proxyproto.PP2_TYPE_SSL:
+			if ssl, err := tlvparse.SSL(tlv); err == nil {
+				if version, ok := ssl.SSLVersion(); ok {
+					headers[proxyProtocolHeaderPrefix+"Ssl-Version"] = version
+				}
+				if cn, ok := ssl.ClientCN(); ok {
+					headers[proxyProtocolHeaderPrefix+"Ssl-Client-Cn"] = cn
+				}
+				continue
+			}
+
+		case tlvparse.PP2_TYPE_AWS:
+			if id, err := tlvparse.AWSVPCEndpointID(tlv); err == nil {
+				headers[proxyProtocolHeaderPrefix+"Aws-Vpce-Id"] = id
+				continue
+			}
+
+		case tlvparse.PP2_TYPE_AZURE:
+			if id, ok := tlvparse.FindAzurePrivateEndpointLinkID([]proxyproto.TLV{tlv}); ok {
+				headers[proxyProtocolHeaderPrefix+"Azure-Link-Id"] = strconv.FormatUint(uint64(id), 10)
+				continue
+			}
+
+		case tlvparse.PP2_TYPE_GCP:
+			if id, ok := tlvparse.ExtractPSCConnectionID([]proxyproto.TLV{tlv}); ok {
+				headers[proxyProtocolHeaderPrefix+"Gcp-Psc-Id"] = strconv.FormatUint(id, 10)
+				continue
+			}
+		}
+
+		headers[fmt.Sprintf("%sTlv-%02X", proxyProtocolHeaderPrefix, byte(tlv.Type))] = hex.EncodeToString(tlv.Value)
+	}
+
+	return headers
+}
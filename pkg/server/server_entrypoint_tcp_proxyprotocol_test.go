@@ -0,0 +1,164 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pires/go-proxyproto"
+	"github.com/pires/go-proxyproto/tlvparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	tcprouter "github.com/traefik/traefik/v3/pkg/server/router/tcp"
+)
+
+func TestProxyProtocol(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		proxyProtocol   *static.ProxyProtocol
+		withHeader      bool
+		expectedClosed  bool
+		expectedHeaders map[string]string
+	}{
+		{
+			desc:          "TLV headers",
+			proxyProtocol: &static.ProxyProtocol{Insecure: true, TLVHeaders: true},
+			withHeader:    true,
+			expectedHeaders: map[string]string{
+				"X-Proxy-Protocol-Aws-Vpce-Id": "vpce-08d2bf15fac5001c9",
+				"X-Proxy-Protocol-Authority":   "example.com",
+				"X-Proxy-Protocol-Tlv-E1":      "666f6f",
+				"X-Proxy-Protocol-Spoofed":     "",
+			},
+		},
+		{
+			desc:          "TLV headers disabled",
+			proxyProtocol: &static.ProxyProtocol{Insecure: true},
+			withHeader:    true,
+			expectedHeaders: map[string]string{
+				"X-Proxy-Protocol-Aws-Vpce-Id": "",
+				"X-Proxy-Protocol-Spoofed":     "true",
+			},
+		},
+		{
+			desc:          "TLV headers without PROXY protocol header",
+			proxyProtocol: &static.ProxyProtocol{Insecure: true, TLVHeaders: true},
+			expectedHeaders: map[string]string{
+				"X-Proxy-Protocol-Aws-Vpce-Id": "",
+				"X-Proxy-Protocol-Spoofed":     "",
+			},
+		},
+		{
+			desc:           "required without PROXY protocol header",
+			proxyProtocol:  &static.ProxyProtocol{Insecure: true, Required: true},
+			expectedClosed: true,
+		},
+		{
+			desc:          "required with PROXY protocol header from a trusted IP",
+			proxyProtocol: &static.ProxyProtocol{TrustedIPs: []string{"127.0.0.1"}, Required: true},
+			withHeader:    true,
+		},
+		{
+			desc:           "required with PROXY protocol header from an untrusted IP",
+			proxyProtocol:  &static.ProxyProtocol{TrustedIPs: []string{"10.0.0.1"}, Required: true},
+			withHeader:     true,
+			expectedClosed: true,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			epConfig := &static.EntryPointsTransport{}
+			epConfig.SetDefaults()
+
+			entryPoint, err := NewTCPEntryPoint(context.Background(), &static.EntryPoint{
+				Address:          "127.0.0.1:0",
+				Transport:        epConfig,
+				ForwardedHeaders: &static.ForwardedHeaders{},
+				HTTP2:            &static.HTTP2Config{},
+				ProxyProtocol:    test.proxyProtocol,
+			}, nil, nil)
+			require.NoError(t, err)
+
+			t.Cleanup(func() { entryPoint.Shutdown(context.Background()) })
+
+			router := &tcprouter.Router{}
+			router.SetHTTPHandler(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				for name := range test.expectedHeaders {
+					rw.Header().Set(name, req.Header.Get(name))
+				}
+				rw.WriteHeader(http.StatusOK)
+			}))
+
+			conn, err := startEntrypoint(entryPoint, router)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+
+			require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+			if test.withHeader {
+				header := proxyproto.HeaderProxyFromAddrs(2,
+					&net.TCPAddr{IP: net.ParseIP("192.168.1.1"), Port: 12345},
+					&net.TCPAddr{IP: net.ParseIP("192.168.1.2"), Port: 80},
+				)
+				err = header.SetTLVs([]proxyproto.TLV{
+					{Type: tlvparse.PP2_TYPE_AWS, Value: append([]byte{tlvparse.PP2_SUBTYPE_AWS_VPCE_ID}, "vpce-08d2bf15fac5001c9"...)},
+					{Type: proxyproto.PP2_TYPE_AUTHORITY, Value: []byte("example.com")},
+					{Type: 0xE1, Value: []byte("foo")},
+				})
+				require.NoError(t, err)
+
+				_, err = header.WriteTo(conn)
+				require.NoError(t, err)
+			}
+
+			req, err := http.NewRequest(http.MethodGet, "http://127.0.0.1/", nil)
+			require.NoError(t, err)
+			req.Header.Set("X-Proxy-Protocol-Spoofed", "true")
+
+			err = req.Write(conn)
+			if test.expectedClosed && err != nil {
+				return
+			}
+			require.NoError(t, err)
+
+			resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+			if test.expectedClosed {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			for name, value := range test.expectedHeaders {
+				assert.Equal(t, value, resp.Header.Get(name), name)
+			}
+		})
+	}
+}
+
+func TestTLVHeaders(t *testing.T) {
+	tlvs := []proxyproto.TLV{
+		{Type: proxyproto.PP2_TYPE_ALPN, Value: []byte("h2")},
+		{Type: proxyproto.PP2_TYPE_UNIQUE_ID, Value: []byte{0xca, 0xfe}},
+		{Type: tlvparse.PP2_TYPE_AZURE, Value: []byte{0x01, 0x2a, 0x00, 0x00, 0x00}},
+		// malformed AWS TLV, without the VPC endpoint ID subtype.
+		{Type: tlvparse.PP2_TYPE_AWS, Value: []byte{0x02, 0x01}},
+	}
+
+	expected := map[string]string{
+		"X-Proxy-Protocol-Alpn":          "h2",
+		"X-Proxy-Protocol-Unique-Id":     "cafe",
+		"X-Proxy-Protocol-Azure-Link-Id": "42",
+		"X-Proxy-Protocol-Tlv-EA":        "0201",
+	}
+
+	assert.Equal(t, expected, tlvHeaders(tlvs))
+}
@@ -0,0 +1,370 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"golang.org/x/net/http/httpguts"
+)
+
+// webSocketCloseGoingAway is the close frame sent to the WebSocket clients, with the 1001 (going away) status code.
+var webSocketCloseGoingAway = []byte{0x88, 0x02, 0x03, 0xe9}
+
+// httpHeadEnd is the end of the head of the switching protocols response, written before the WebSocket frames.
+var httpHeadEnd = []byte("\r\n\r\n")
+
+// defaultWebSocketCloseTimeout is the close timeout of the WebSocket connections
+// whose lifetime is only limited by a router.
+const defaultWebSocketCloseTimeout = 10 * time.Second
+
+// webSocketTracker tracks the WebSocket connections of an entry point, shared by its HTTP and HTTPS servers,
+// to close them with a close frame once their lifetime is over, or when the entry point shuts down,
+// instead of cutting them off.
+type webSocketTracker struct {
+	maxLifetime  time.Duration
+	closeTimeout time.Duration
+	// drain reports whether the connections are closed with a close frame when the entry point shuts down.
+	drain bool
+
+	mu       sync.Mutex
+	conns    map[*webSocketConn]struct{}
+	draining bool
+}
+
+func newWebSocketTracker(config *static.WebSockets) (*webSocketTracker, error) {
+	tracker := &webSocketTracker{
+		closeTimeout: defaultWebSocketCloseTimeout,
+		conns:        make(map[*webSocketConn]struct{}),
+	}
+
+	if config == nil {
+		return tracker, nil
+	}
+
+	if config.MaxLifetime < 0 {
+		return nil, errors.New("WebSocket max lifetime must be greater than or equal to zero")
+	}
+
+	if config.CloseTimeout <= 0 {
+		return nil, errors.New("WebSocket close timeout must be greater than zero")
+	}
+
+	tracker.maxLifetime = time.Duration(config.MaxLifetime)
+	tracker.closeTimeout = time.Duration(config.CloseTimeout)
+	tracker.drain = true
+
+	return tracker, nil
+}
+
+// wrap returns a handler tracking the connections hijacked by the WebSocket upgrades handled by the next handler.
+func (t *webSocketTracker) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !isWebSocketUpgrade(req) {
+			next.ServeHTTP(rw, req)
+			return
+		}
+
+		next.ServeHTTP(&webSocketResponseWriter{ResponseWriter: rw, tracker: t}, req)
+	})
+}
+
+// Shutdown sends a close frame to the tracked connections, and to the ones upgraded afterwards,
+// the connections not closed by their clients within the close timeout being closed.
+// It does nothing when the draining is not enabled, the connections being closed with the entry point.
+func (t *webSocketTracker) Shutdown(ctx context.Context) {
+	if !t.drain {
+		return
+	}
+
+	t.mu.Lock()
+	t.draining = true
+	conns := make([]*webSocketConn, 0, len(t.conns))
+	for conn := range t.conns {
+		conns = append(conns, conn)
+	}
+	t.mu.Unlock()
+
+	if len(conns) > 0 {
+		log.Ctx(ctx).Debug().Msgf("Closing %d WebSocket connection(s)", len(conns))
+	}
+
+	// a connection whose client is slow to read delays its close frame, but not the others.
+	for _, conn := range conns {
+		go conn.goAway()
+	}
+}
+
+func (t *webSocketTracker) add(conn *webSocketConn) {
+	t.mu.Lock()
+	t.conns[conn] = struct{}{}
+	draining := t.draining
+	t.mu.Unlock()
+
+	if draining {
+		conn.goAway()
+		return
+	}
+
+	conn.SetMaxLifetime(t.maxLifetime)
+}
+
+func (t *webSocketTracker) remove(conn *webSocketConn) {
+	t.mu.Lock()
+	delete(t.conns, conn)
+	t.mu.Unlock()
+}
+
+// webSocketResponseWriter wraps the connection hijacked by a WebSocket upgrade, to track it.
+type webSocketResponseWriter struct {
+	http.ResponseWriter
+
+	tracker *webSocketTracker
+}
+
+func (w *webSocketResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hijacks the connection, the returned buffered writer writing to the tracked connection,
+// so that the close frames are not written before the switching protocols response.
+func (w *webSocketResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", w.ResponseWriter)
+	}
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wsConn := &webSocketConn{Conn: conn, tracker: w.tracker, closeTimeout: w.tracker.closeTimeout}
+	w.tracker.add(wsConn)
+
+	return wsConn, bufio.NewReadWriter(rw.Reader, bufio.NewWriter(wsConn)), nil
+}
+
+// webSocketConn is a connection hijacked by a WebSocket upgrade,
+// which follows the frames written to the client to send a close frame between two of them.
+type webSocketConn struct {
+	net.Conn
+
+	tracker      *webSocketTracker
+	closeTimeout time.Duration
+
+	mu       sync.Mutex
+	frames   frameTracker
+	lifetime *time.Timer
+	// closing reports whether a close frame is to be sent at the end of the current frame.
+	closing bool
+	// closeSent reports whether the close frame is sent, the frames written afterwards being dropped.
+	closeSent bool
+	shutdown  *time.Timer
+}
+
+// SetMaxLifetime limits the lifetime of the connection, from now on, replacing any previous limit.
+// The lifetime is not limited when zero.
+func (c *webSocketConn) SetMaxLifetime(lifetime time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.lifetime != nil {
+		c.lifetime.Stop()
+		c.lifetime = nil
+	}
+
+	if lifetime > 0 && !c.closing {
+		c.lifetime = time.AfterFunc(lifetime, c.goAway)
+	}
+}
+
+// goAway sends a close frame at the end of the current frame,
+// and closes the connection unless the client closes it within the close timeout.
+func (c *webSocketConn) goAway() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closing {
+		return
+	}
+	c.closing = true
+
+	if c.frames.boundary() {
+		c.writeClose()
+	}
+
+	c.shutdown = time.AfterFunc(c.closeTimeout, func() { _ = c.Close() })
+}
+
+func (c *webSocketConn) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closeSent {
+		return len(p), nil
+	}
+
+	if !c.closing {
+		c.frames.advance(p)
+		return c.Conn.Write(p)
+	}
+
+	// the current frame is completed, before the close frame.
+	n := c.frames.remaining(p)
+	if n > 0 {
+		c.frames.advance(p[:n])
+		if _, err := c.Conn.Write(p[:n]); err != nil {
+			return 0, err
+		}
+	}
+
+	if c.frames.boundary() {
+		c.writeClose()
+	}
+
+	return len(p), nil
+}
+
+// writeClose writes the close frame, it must be called with the lock held.
+func (c *webSocketConn) writeClose() {
+	c.closeSent = true
+	if _, err := c.Conn.Write(webSocketCloseGoingAway); err != nil {
+		_ = c.Conn.Close()
+	}
+}
+
+// Close closes the connection, before taking the lock, to unblock a write to a client slow to read.
+func (c *webSocketConn) Close() error {
+	err := c.Conn.Close()
+
+	c.mu.Lock()
+	if c.lifetime != nil {
+		c.lifetime.Stop()
+	}
+	if c.shutdown != nil {
+		c.shutdown.Stop()
+	}
+	c.mu.Unlock()
+
+	c.tracker.remove(c)
+
+	return err
+}
+
+// frameTracker follows the head of the switching protocols response, then the WebSocket frames written to a client.
+type frameTracker struct {
+	// headDone reports whether the head of the response is written.
+	headDone bool
+	// headMatched is the number of bytes of the end of the head matched so far.
+	headMatched int
+	// header holds the bytes of the header of the current frame, when not complete.
+	header []byte
+	// payload is the number of bytes of the payload of the current frame yet to be written.
+	payload uint64
+}
+
+// boundary reports whether the response head and the last frame are complete.
+func (f *frameTracker) boundary() bool {
+	return f.headDone && len(f.header) == 0 && f.payload == 0
+}
+
+// remaining returns the number of the first bytes of p completing the response head or the current frame.
+func (f *frameTracker) remaining(p []byte) int {
+	tracker := *f
+	tracker.header = append([]byte(nil), f.header...)
+
+	for i := range p {
+		if tracker.boundary() {
+			return i
+		}
+		tracker.advance(p[i : i+1])
+	}
+
+	return len(p)
+}
+
+func (f *frameTracker) advance(p []byte) {
+	for len(p) > 0 {
+		if !f.headDone {
+			if p[0] == httpHeadEnd[f.headMatched] {
+				f.headMatched++
+			} else if p[0] == httpHeadEnd[0] {
+				f.headMatched = 1
+			} else {
+				f.headMatched = 0
+			}
+			f.headDone = f.headMatched == len(httpHeadEnd)
+			p = p[1:]
+			continue
+		}
+
+		if f.payload > 0 {
+			n := uint64(len(p))
+			if n > f.payload {
+				n = f.payload
+			}
+			f.payload -= n
+			p = p[n:]
+			continue
+		}
+
+		f.header = append(f.header, p[0])
+		p = p[1:]
+
+		if size, ok := frameHeaderSize(f.header); ok && len(f.header) == size {
+			f.payload = framePayloadLength(f.header)
+			f.header = f.header[:0]
+		}
+	}
+}
+
+// frameHeaderSize returns the size of the frame header starting with the given bytes,
+// once its first two bytes are known.
+func frameHeaderSize(header []byte) (int, bool) {
+	if len(header) < 2 {
+		return 0, false
+	}
+
+	size := 2
+	switch header[1] & 0x7f {
+	case 126:
+		size += 2
+	case 127:
+		size += 8
+	}
+
+	if header[1]&0x80 != 0 {
+		size += 4
+	}
+
+	return size, true
+}
+
+// framePayloadLength returns the payload length of the frame of the given complete header.
+func framePayloadLength(header []byte) uint64 {
+	switch length := header[1] & 0x7f; length {
+	case 126:
+		return uint64(binary.BigEndian.Uint16(header[2:4]))
+	case 127:
+		return binary.BigEndian.Uint64(header[2:10])
+	default:
+		return uint64(length)
+	}
+}
+
+func isWebSocketUpgrade(req *http.Request) bool {
+	return httpguts.HeaderValuesContainsToken(req.Header["Connection"], "Upgrade") &&
+		strings.EqualFold(req.Header.Get("Upgrade"), "websocket")
+}
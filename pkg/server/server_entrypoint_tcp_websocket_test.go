@@ -0,0 +1,198 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+)
+
+const switchingProtocols = "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n"
+
+func TestNewWebSocketTracker(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		config      *static.WebSockets
+		expectedErr bool
+	}{
+		{
+			desc: "no config",
+		},
+		{
+			desc:   "valid config",
+			config: &static.WebSockets{MaxLifetime: ptypes.Duration(time.Hour), CloseTimeout: ptypes.Duration(time.Second)},
+		},
+		{
+			desc:        "negative max lifetime",
+			config:      &static.WebSockets{MaxLifetime: ptypes.Duration(-time.Hour), CloseTimeout: ptypes.Duration(time.Second)},
+			expectedErr: true,
+		},
+		{
+			desc:        "no close timeout",
+			config:      &static.WebSockets{MaxLifetime: ptypes.Duration(time.Hour)},
+			expectedErr: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			tracker, err := newWebSocketTracker(test.config)
+			if test.expectedErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.config != nil, tracker.drain)
+		})
+	}
+}
+
+func TestFrameTracker(t *testing.T) {
+	var frames frameTracker
+
+	frames.advance([]byte(switchingProtocols[:20]))
+	assert.False(t, frames.boundary())
+
+	frames.advance([]byte(switchingProtocols[20:]))
+	assert.True(t, frames.boundary())
+
+	// a text frame with a 5 bytes payload.
+	frames.advance([]byte{0x81, 0x05, 'h', 'e'})
+	assert.False(t, frames.boundary())
+	assert.Equal(t, 3, frames.remaining([]byte{'l', 'l', 'o', 0x81}))
+
+	frames.advance([]byte{'l', 'l', 'o'})
+	assert.True(t, frames.boundary())
+
+	// a binary frame with a 16 bits payload length, split within its header.
+	frames.advance([]byte{0x82, 0x7e})
+	assert.False(t, frames.boundary())
+	frames.advance([]byte{0x01, 0x00})
+	assert.Equal(t, uint64(256), frames.payload)
+	frames.advance(make([]byte, 256))
+	assert.True(t, frames.boundary())
+
+	// a masked frame with a 64 bits payload length.
+	frames.advance([]byte{0x82, 0xff, 0, 0, 0, 0, 0, 0, 0, 0x02, 1, 2, 3, 4, 'h', 'i'})
+	assert.True(t, frames.boundary())
+}
+
+func TestWebSocketConn_goAway(t *testing.T) {
+	tracker, err := newWebSocketTracker(&static.WebSockets{CloseTimeout: ptypes.Duration(time.Hour)})
+	require.NoError(t, err)
+
+	written := &bytes.Buffer{}
+	conn := &webSocketConn{Conn: &bufferConn{buffer: written}, tracker: tracker, closeTimeout: time.Hour}
+	tracker.add(conn)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	_, err = conn.Write([]byte(switchingProtocols))
+	require.NoError(t, err)
+	_, err = conn.Write([]byte{0x81, 0x05, 'h', 'e'})
+	require.NoError(t, err)
+
+	// the close frame waits for the end of the current frame.
+	tracker.Shutdown(context.Background())
+	require.Eventually(t, func() bool { conn.mu.Lock(); defer conn.mu.Unlock(); return conn.closing }, time.Second, 10*time.Millisecond)
+
+	n, err := conn.Write([]byte{'l', 'l', 'o', 0x81, 0x01, '!'})
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+
+	// the frames written after the close frame are dropped.
+	_, err = conn.Write([]byte{0x81, 0x01, '?'})
+	require.NoError(t, err)
+
+	expected := append([]byte(switchingProtocols), 0x81, 0x05, 'h', 'e', 'l', 'l', 'o')
+	expected = append(expected, webSocketCloseGoingAway...)
+	assert.Equal(t, expected, written.Bytes())
+}
+
+func TestWebSocketTracker(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		config   *static.WebSockets
+		shutdown bool
+	}{
+		{
+			desc:   "max lifetime",
+			config: &static.WebSockets{MaxLifetime: ptypes.Duration(200 * time.Millisecond), CloseTimeout: ptypes.Duration(time.Second)},
+		},
+		{
+			desc:     "shutdown",
+			config:   &static.WebSockets{CloseTimeout: ptypes.Duration(time.Second)},
+			shutdown: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			tracker, err := newWebSocketTracker(test.config)
+			require.NoError(t, err)
+
+			upgrader := websocket.Upgrader{}
+			server := httptest.NewServer(tracker.wrap(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				conn, err := upgrader.Upgrade(rw, req, nil)
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+
+				// the server keeps writing, the close frame being sent between two messages.
+				for {
+					if err := conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat("x", 1000))); err != nil {
+						return
+					}
+					time.Sleep(10 * time.Millisecond)
+				}
+			})))
+			t.Cleanup(server.Close)
+
+			conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+
+			_, _, err = conn.ReadMessage()
+			require.NoError(t, err)
+
+			if test.shutdown {
+				tracker.Shutdown(context.Background())
+			}
+
+			for {
+				_, message, err := conn.ReadMessage()
+				if err != nil {
+					assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), err)
+					break
+				}
+				assert.Len(t, message, 1000)
+			}
+		})
+	}
+}
+
+// bufferConn is a connection writing to a buffer.
+type bufferConn struct {
+	net.Conn
+
+	buffer *bytes.Buffer
+}
+
+func (c *bufferConn) Write(p []byte) (int, error) {
+	return c.buffer.Write(p)
+}
+
+func (c *bufferConn) Close() error {
+	return nil
+}
@@ -69,12 +69,10 @@ func (eps UDPEntryPoints) Stop() {
 // Switch swaps out all the given handlers in their associated entrypoints.
 func (eps UDPEntryPoints) Switch(handlers map[string]udp.Handler) {
 	for epName, handler := range handlers {
+		// the handlers of the dynamic entrypoints are switched by the DynamicUDPEntryPoints.
 		if ep, ok := eps[epName]; ok {
 			ep.Switch(handler)
-			continue
 		}
-
-		log.Error().Str(logs.EntryPointName, epName).Msg("EntryPoint does not exist")
 	}
 }
 
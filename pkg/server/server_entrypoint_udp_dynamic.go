@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/logs"
+	"github.com/traefik/traefik/v3/pkg/udp"
+)
+
+// DynamicUDPEntryPoints holds the UDP entrypoints declared by the providers, registered in the DynamicEntryPoints.
+type DynamicUDPEntryPoints struct {
+	entryPoints *DynamicEntryPoints
+}
+
+// NewDynamicUDPEntryPoints creates a new DynamicUDPEntryPoints.
+func NewDynamicUDPEntryPoints(entryPoints *DynamicEntryPoints) *DynamicUDPEntryPoints {
+	return &DynamicUDPEntryPoints{entryPoints: entryPoints}
+}
+
+// Update starts the entrypoints added to the configuration, and stops the ones removed from it.
+// The entrypoints whose port has changed are restarted.
+// It returns the names of the running entrypoints.
+func (d *DynamicUDPEntryPoints) Update(configurations map[string]*dynamic.UDPEntryPoint) []string {
+	ports := make(map[string]int, len(configurations))
+	for name, config := range configurations {
+		if config == nil {
+			log.Error().Str(logs.EntryPointName, name).Msg("Invalid dynamic entrypoint: empty configuration")
+			continue
+		}
+
+		ports[name] = config.Port
+	}
+
+	return d.entryPoints.update("udp", ports, d.start)
+}
+
+// Switch the UDP handlers of the running entrypoints.
+func (d *DynamicUDPEntryPoints) Switch(handlers map[string]udp.Handler) {
+	d.entryPoints.each("udp", func(name string, entryPoint dynamicEntryPoint) {
+		if handler, ok := handlers[name]; ok {
+			entryPoint.(*UDPEntryPoint).Switch(handler)
+		}
+	})
+}
+
+// Stop the running entrypoints.
+func (d *DynamicUDPEntryPoints) Stop() {
+	d.entryPoints.shutdown("udp")
+}
+
+func (d *DynamicUDPEntryPoints) start(ctx context.Context, _, host string, port int) (dynamicEntryPoint, error) {
+	config := &static.EntryPoint{}
+	config.SetDefaults()
+	config.Address = net.JoinHostPort(host, strconv.Itoa(port)) + "/udp"
+
+	entryPoint, err := NewUDPEntryPoint(config)
+	if err != nil {
+		return nil, err
+	}
+
+	go entryPoint.Start(ctx)
+
+	log.Ctx(ctx).Info().Msgf("Dynamic entrypoint listening on %s", config.Address)
+
+	return entryPoint, nil
+}
+
+// release does not release the port of the entrypoint:
+// as its sessions share the socket of the listener, the port is only released once they are closed on shutdown.
+func (e *UDPEntryPoint) release() error {
+	return nil
+}
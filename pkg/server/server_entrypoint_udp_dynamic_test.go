@@ -0,0 +1,82 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/config/static"
+	"github.com/traefik/traefik/v3/pkg/udp"
+)
+
+func TestDynamicUDPEntryPoints(t *testing.T) {
+	port1, port2 := freeUDPPort(t), freeUDPPort(t)
+	minPort, maxPort := port1, port2
+	if minPort > maxPort {
+		minPort, maxPort = maxPort, minPort
+	}
+
+	config := &static.DynamicEntryPoints{
+		PortRange: fmt.Sprintf("%d-%d", minPort, maxPort),
+		Host:      "127.0.0.1",
+	}
+	staticEntryPoints := static.EntryPoints{"dns": &static.EntryPoint{Address: ":53/udp"}}
+
+	dynamicEntryPoints, err := NewDynamicEntryPoints(config, staticEntryPoints)
+	require.NoError(t, err)
+
+	eps := NewDynamicUDPEntryPoints(dynamicEntryPoints)
+	t.Cleanup(eps.Stop)
+
+	running := eps.Update(map[string]*dynamic.UDPEntryPoint{
+		"syslog":     {Port: port1},
+		"syslog2":    {Port: port1},
+		"dns":        {Port: port2},
+		"outOfRange": {Port: maxPort + 1},
+	})
+	// the entrypoints are started in the order of their names, the first one gets the port.
+	assert.Equal(t, []string{"syslog"}, running)
+
+	running = eps.Update(map[string]*dynamic.UDPEntryPoint{
+		"syslog": {Port: port1},
+		"statsd": {Port: port2},
+	})
+	assert.Equal(t, []string{"statsd", "syslog"}, running)
+
+	eps.Switch(map[string]udp.Handler{"syslog": udp.HandlerFunc(func(conn *udp.Conn) {
+		b := make([]byte, 1024)
+		n, err := conn.Read(b)
+		if err != nil {
+			return
+		}
+
+		_, _ = conn.Write(b[:n])
+		_ = conn.Close()
+	})})
+
+	conn, err := net.Dial("udp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port1)))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	requireEcho(t, "TEST", conn, time.Second)
+
+	running = eps.Update(map[string]*dynamic.UDPEntryPoint{
+		"statsd": {Port: port2},
+	})
+	assert.Equal(t, []string{"statsd"}, running)
+}
+
+func freeUDPPort(t *testing.T) int {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
@@ -5,14 +5,22 @@ package server
 
 import (
 	"context"
+	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/safe"
+	"github.com/traefik/traefik/v3/pkg/upgrade"
 )
 
 func (s *Server) configureSignals() {
-	signal.Notify(s.signals, syscall.SIGUSR1)
+	signal.Notify(s.signals, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	// SIGHUP keeps its default behavior, terminating Traefik, unless the upgrades are enabled.
+	if s.upgradeReadyTimeout > 0 {
+		signal.Notify(s.signals, syscall.SIGHUP)
+	}
 }
 
 func (s *Server) listenSignals(ctx context.Context) {
@@ -21,7 +29,8 @@ func (s *Server) listenSignals(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case sig := <-s.signals:
-			if sig == syscall.SIGUSR1 {
+			switch sig {
+			case syscall.SIGUSR1:
 				log.Info().Msgf("Closing and re-opening log files for rotation: %+v", sig)
 
 				if s.accessLoggerMiddleware != nil {
@@ -29,7 +38,38 @@ func (s *Server) listenSignals(ctx context.Context) {
 						log.Error().Err(err).Msg("Error rotating access log")
 					}
 				}
+			case syscall.SIGUSR2:
+				if s.diagnostics == nil {
+					continue
+				}
+
+				path, err := s.diagnostics.WriteFile(os.TempDir())
+				if err != nil {
+					log.Error().Err(err).Msg("Error writing the diagnostic bundle")
+					continue
+				}
+
+				log.Info().Str("path", path).Msgf("Diagnostic bundle written: %+v", sig)
+			case syscall.SIGHUP:
+				safe.Go(s.upgrade)
 			}
 		}
 	}
 }
+
+// upgrade hands the listeners off to a new process, started from the current executable,
+// and stops the server gracefully, as on SIGTERM, once the new process is ready.
+func (s *Server) upgrade() {
+	log.Info().Msg("Upgrading: starting a new process")
+
+	if err := upgrade.Upgrade(s.appliedProviders(), s.upgradeReadyTimeout); err != nil {
+		log.Error().Err(err).Msg("Upgrade failed, the server keeps running")
+		return
+	}
+
+	log.Info().Msg("Upgrade done: the new process is ready")
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		log.Error().Err(err).Msg("Error stopping the server after the upgrade")
+	}
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// dnsScheme is the scheme of the servers' URL of the DNS servers,
+// to which the DNS over HTTPS queries are forwarded as plain DNS.
+const dnsScheme = "dns"
+
+const dnsMessageContentType = "application/dns-message"
+
+// dnsTimeout is the maximum duration of a DNS exchange with a server, if the request has no earlier deadline.
+const dnsTimeout = 5 * time.Second
+
+// maxDNSMessageSize is the maximum size of a DNS message over TCP.
+const maxDNSMessageSize = math.MaxUint16
+
+// dnsProxy terminates the DNS over HTTPS (RFC 8484) queries,
+// and forwards them as plain DNS over UDP to the server,
+// and over TCP when the response over UDP is truncated.
+type dnsProxy struct {
+	address string
+}
+
+func buildDNSProxy(target *url.URL) (http.Handler, error) {
+	if _, _, err := net.SplitHostPort(target.Host); err != nil {
+		return nil, fmt.Errorf("invalid DNS server address %q: %w", target.Host, err)
+	}
+
+	return &dnsProxy{address: target.Host}, nil
+}
+
+func (p *dnsProxy) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	query, statusCode := readDNSQuery(req)
+	if statusCode != http.StatusOK {
+		http.Error(rw, http.StatusText(statusCode), statusCode)
+		return
+	}
+
+	var header dnsmessage.Parser
+	if _, err := header.Start(query); err != nil {
+		http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), dnsTimeout)
+	defer cancel()
+
+	resp, err := p.exchange(ctx, "udp", query)
+	if err == nil && truncated(resp) {
+		resp, err = p.exchange(ctx, "tcp", query)
+	}
+	if err != nil {
+		errorHandler(rw, req, err)
+		return
+	}
+
+	rw.Header().Set("Content-Type", dnsMessageContentType)
+	rw.Header().Set("Content-Length", strconv.Itoa(len(resp)))
+	if ttl, ok := minTTL(resp); ok {
+		rw.Header().Set("Cache-Control", "max-age="+strconv.FormatUint(uint64(ttl), 10))
+	}
+
+	rw.WriteHeader(http.StatusOK)
+	_, _ = rw.Write(resp)
+}
+
+// readDNSQuery returns the DNS query of a GET or POST DNS over HTTPS request,
+// or the status code of the response to an invalid request.
+func readDNSQuery(req *http.Request) ([]byte, int) {
+	switch req.Method {
+	case http.MethodGet:
+		query, err := base64.RawURLEncoding.DecodeString(req.URL.Query().Get("dns"))
+		if err != nil || len(query) == 0 {
+			return nil, http.StatusBadRequest
+		}
+		return query, http.StatusOK
+
+	case http.MethodPost:
+		mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil || mediaType != dnsMessageContentType {
+			return nil, http.StatusUnsupportedMediaType
+		}
+
+		query, err := io.ReadAll(io.LimitReader(req.Body, maxDNSMessageSize+1))
+		if err != nil || len(query) == 0 {
+			return nil, http.StatusBadRequest
+		}
+		if len(query) > maxDNSMessageSize {
+			return nil, http.StatusRequestEntityTooLarge
+		}
+		return query, http.StatusOK
+
+	default:
+		return nil, http.StatusMethodNotAllowed
+	}
+}
+
+// exchange sends the query to the server, and returns its response.
+func (p *dnsProxy) exchange(ctx context.Context, network string, query []byte) ([]byte, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, network, p.address)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if network == "udp" {
+		if _, err := conn.Write(query); err != nil {
+			return nil, err
+		}
+
+		resp := make([]byte, maxDNSMessageSize)
+		n, err := conn.Read(resp)
+		if err != nil {
+			return nil, err
+		}
+		return resp[:n], nil
+	}
+
+	// Over TCP, the messages are prefixed by their length.
+	msg := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(msg, uint16(len(query)))
+	copy(msg[2:], query)
+
+	if _, err := conn.Write(msg); err != nil {
+		return nil, err
+	}
+
+	var length [2]byte
+	if _, err := io.ReadFull(conn, length[:]); err != nil {
+		return nil, err
+	}
+
+	resp := make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func truncated(resp []byte) bool {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(resp)
+	return err == nil && header.Truncated
+}
+
+// minTTL returns the minimum TTL of the records of the response, as its freshness lifetime.
+func minTTL(resp []byte) (uint32, bool) {
+	var parser dnsmessage.Parser
+	if _, err := parser.Start(resp); err != nil {
+		return 0, false
+	}
+
+	if err := parser.SkipAllQuestions(); err != nil {
+		return 0, false
+	}
+
+	var (
+		ttl   uint32
+		found bool
+	)
+	for {
+		header, err := parser.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return 0, false
+		}
+
+		if !found || header.TTL < ttl {
+			ttl, found = header.TTL, true
+		}
+
+		if err := parser.SkipAnswer(); err != nil {
+			return 0, false
+		}
+	}
+
+	return ttl, found
+}
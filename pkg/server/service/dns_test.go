@@ -0,0 +1,253 @@
+package service
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSProxy(t *testing.T) {
+	address := startDNSServer(t)
+
+	testCases := []struct {
+		desc           string
+		method         string
+		name           string
+		contentType    string
+		body           []byte
+		expectedStatus int
+		expectedIP     [4]byte
+		expectedCache  string
+	}{
+		{
+			desc:           "GET query",
+			method:         http.MethodGet,
+			name:           "foo.example.com.",
+			expectedStatus: http.StatusOK,
+			expectedIP:     [4]byte{10, 0, 0, 1},
+			expectedCache:  "max-age=60",
+		},
+		{
+			desc:           "POST query",
+			method:         http.MethodPost,
+			name:           "foo.example.com.",
+			contentType:    dnsMessageContentType,
+			expectedStatus: http.StatusOK,
+			expectedIP:     [4]byte{10, 0, 0, 1},
+			expectedCache:  "max-age=60",
+		},
+		{
+			desc:           "truncated response over UDP retried over TCP",
+			method:         http.MethodPost,
+			name:           "big.example.com.",
+			contentType:    dnsMessageContentType,
+			expectedStatus: http.StatusOK,
+			expectedIP:     [4]byte{10, 0, 0, 2},
+			expectedCache:  "max-age=30",
+		},
+		{
+			desc:           "POST query with the wrong content type",
+			method:         http.MethodPost,
+			name:           "foo.example.com.",
+			contentType:    "text/plain",
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+		{
+			desc:           "POST query with an invalid message",
+			method:         http.MethodPost,
+			contentType:    dnsMessageContentType,
+			body:           []byte("foo"),
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			desc:           "unsupported method",
+			method:         http.MethodPut,
+			name:           "foo.example.com.",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			handler, err := buildDNSProxy(&url.URL{Scheme: dnsScheme, Host: address})
+			require.NoError(t, err)
+
+			body := test.body
+			if body == nil {
+				body = buildDNSQuery(t, test.name)
+			}
+
+			var req *http.Request
+			if test.method == http.MethodGet {
+				req = httptest.NewRequest(test.method, "/dns-query?dns="+base64.RawURLEncoding.EncodeToString(body), nil)
+			} else {
+				req = httptest.NewRequest(test.method, "/dns-query", bytes.NewReader(body))
+				req.Header.Set("Content-Type", test.contentType)
+			}
+
+			rw := httptest.NewRecorder()
+			handler.ServeHTTP(rw, req)
+
+			require.Equal(t, test.expectedStatus, rw.Code)
+			if test.expectedStatus != http.StatusOK {
+				return
+			}
+
+			assert.Equal(t, dnsMessageContentType, rw.Header().Get("Content-Type"))
+			assert.Equal(t, test.expectedCache, rw.Header().Get("Cache-Control"))
+
+			var msg dnsmessage.Message
+			require.NoError(t, msg.Unpack(rw.Body.Bytes()))
+			require.Len(t, msg.Answers, 1)
+
+			resource, ok := msg.Answers[0].Body.(*dnsmessage.AResource)
+			require.True(t, ok)
+			assert.Equal(t, test.expectedIP, resource.A)
+		})
+	}
+}
+
+func TestDNSProxy_serverDown(t *testing.T) {
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.LocalAddr().String()
+	require.NoError(t, listener.Close())
+
+	handler, err := buildDNSProxy(&url.URL{Scheme: dnsScheme, Host: address})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/dns-query", bytes.NewReader(buildDNSQuery(t, "foo.example.com.")))
+	req.Header.Set("Content-Type", dnsMessageContentType)
+
+	rw := httptest.NewRecorder()
+	handler.ServeHTTP(rw, req)
+
+	assert.Equal(t, http.StatusBadGateway, rw.Code)
+}
+
+func TestBuildDNSProxy_missingPort(t *testing.T) {
+	_, err := buildDNSProxy(&url.URL{Scheme: dnsScheme, Host: "127.0.0.1"})
+	assert.Error(t, err)
+}
+
+func buildDNSQuery(t *testing.T, name string) []byte {
+	t.Helper()
+
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+
+	query, err := msg.Pack()
+	require.NoError(t, err)
+
+	return query
+}
+
+// startDNSServer starts a DNS server on the same port over UDP and TCP,
+// answering foo.example.com. over both, and big.example.com. only over TCP, the response over UDP being truncated.
+func startDNSServer(t *testing.T) string {
+	t.Helper()
+
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = packetConn.Close() })
+
+	listener, err := net.Listen("tcp", packetConn.LocalAddr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		buf := make([]byte, maxDNSMessageSize)
+		for {
+			n, addr, err := packetConn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			_, _ = packetConn.WriteTo(buildDNSResponse(t, buf[:n], true), addr)
+		}
+	}()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer func() { _ = conn.Close() }()
+
+				var length [2]byte
+				if _, err := io.ReadFull(conn, length[:]); err != nil {
+					return
+				}
+
+				query := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err := io.ReadFull(conn, query); err != nil {
+					return
+				}
+
+				resp := buildDNSResponse(t, query, false)
+				binary.BigEndian.PutUint16(length[:], uint16(len(resp)))
+				_, _ = conn.Write(append(length[:], resp...))
+			}()
+		}
+	}()
+
+	return packetConn.LocalAddr().String()
+}
+
+func buildDNSResponse(t *testing.T, query []byte, overUDP bool) []byte {
+	t.Helper()
+
+	var msg dnsmessage.Message
+	require.NoError(t, msg.Unpack(query))
+
+	question := msg.Questions[0]
+
+	msg.Header.Response = true
+
+	header := dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET}
+
+	switch question.Name.String() {
+	case "foo.example.com.":
+		header.TTL = 60
+		msg.Answers = []dnsmessage.Resource{{Header: header, Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 1}}}}
+
+	case "big.example.com.":
+		if overUDP {
+			msg.Header.Truncated = true
+			break
+		}
+
+		header.TTL = 30
+		msg.Answers = []dnsmessage.Resource{{Header: header, Body: &dnsmessage.AResource{A: [4]byte{10, 0, 0, 2}}}}
+
+	default:
+		msg.Header.RCode = dnsmessage.RCodeNameError
+	}
+
+	resp, err := msg.Pack()
+	require.NoError(t, err)
+
+	return resp
+}
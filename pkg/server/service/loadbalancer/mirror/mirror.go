@@ -10,11 +10,13 @@ import (
 	"net"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/healthcheck"
 	"github.com/traefik/traefik/v3/pkg/middlewares/accesslog"
+	httpmuxer "github.com/traefik/traefik/v3/pkg/muxer/http"
 	"github.com/traefik/traefik/v3/pkg/safe"
 )
 
@@ -28,8 +30,16 @@ type Mirroring struct {
 	maxBodySize      int64
 	wantsHealthCheck bool
 
-	lock  sync.RWMutex
-	total uint64
+	// recordComparison records the comparisons of the responses of the mirrors with the ones of the service, nil when they are not compared.
+	recordComparison func(mirror string, comparison Comparison)
+}
+
+// Comparison is the comparison of the response of a mirror to a request with the response of the mirrored service.
+type Comparison struct {
+	Status        int
+	MirrorStatus  int
+	Latency       time.Duration
+	MirrorLatency time.Duration
 }
 
 // New returns a new instance of *Mirroring.
@@ -43,28 +53,30 @@ func New(handler http.Handler, pool *safe.Pool, maxBodySize int64, hc *dynamic.H
 	}
 }
 
-func (m *Mirroring) inc() uint64 {
-	m.lock.Lock()
-	defer m.lock.Unlock()
-	m.total++
-	return m.total
-}
-
 type mirrorHandler struct {
 	http.Handler
+	name    string
 	percent int
+	// match reports whether a request is mirrored by the handler, nil when all the requests are.
+	match func(req *http.Request) bool
 
-	lock  sync.RWMutex
+	lock sync.RWMutex
+	// total is the number of requests matched by the handler, count the number of the mirrored ones.
+	total uint64
 	count uint64
 }
 
-func (m *Mirroring) getActiveMirrors() []http.Handler {
-	total := m.inc()
-
-	var mirrors []http.Handler
+func (m *Mirroring) getActiveMirrors(req *http.Request) []*mirrorHandler {
+	var mirrors []*mirrorHandler
 	for _, handler := range m.mirrorHandlers {
+		// the percentage applies to the requests matching the rule of the mirror.
+		if handler.match != nil && !handler.match(req) {
+			continue
+		}
+
 		handler.lock.Lock()
-		if handler.count*100 < total*uint64(handler.percent) {
+		handler.total++
+		if handler.count*100 < handler.total*uint64(handler.percent) {
 			handler.count++
 			handler.lock.Unlock()
 			mirrors = append(mirrors, handler)
@@ -76,7 +88,7 @@ func (m *Mirroring) getActiveMirrors() []http.Handler {
 }
 
 func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
-	mirrors := m.getActiveMirrors()
+	mirrors := m.getActiveMirrors(req)
 	if len(mirrors) == 0 {
 		m.handler.ServeHTTP(rw, req)
 		return
@@ -97,7 +109,16 @@ func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// the response of the service is only recorded to be compared with the ones of the mirrors.
+	var recorder *statusRecorder
+	if m.recordComparison != nil {
+		recorder = &statusRecorder{ResponseWriter: rw}
+		rw = recorder
+	}
+
+	start := time.Now()
 	m.handler.ServeHTTP(rw, rr.clone(req.Context()))
+	latency := time.Since(start)
 
 	select {
 	case <-req.Context().Done():
@@ -124,17 +145,56 @@ func (m *Mirroring) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
 			// which would trigger a cancellation of the ongoing mirrored requests.
 			// Therefore, we give a new, non-cancellable context  to each of the mirrored calls,
 			// so they can terminate by themselves.
-			handler.ServeHTTP(m.rw, r.WithContext(contextStopPropagation{ctx}))
+			if m.recordComparison == nil {
+				handler.ServeHTTP(m.rw, r.WithContext(contextStopPropagation{ctx}))
+				continue
+			}
+
+			mirrorRecorder := &statusRecorder{ResponseWriter: m.rw}
+			mirrorStart := time.Now()
+			handler.ServeHTTP(mirrorRecorder, r.WithContext(contextStopPropagation{ctx}))
+
+			m.recordComparison(handler.name, Comparison{
+				Status:        recorder.getStatus(),
+				MirrorStatus:  mirrorRecorder.getStatus(),
+				Latency:       latency,
+				MirrorLatency: time.Since(mirrorStart),
+			})
 		}
 	})
 }
 
+// SetComparisonRecorder sets the function recording the comparisons of the responses of the mirrors
+// with the ones of the service, called once the response of a mirror is received.
+// Not thread safe.
+func (m *Mirroring) SetComparisonRecorder(fn func(mirror string, comparison Comparison)) {
+	m.recordComparison = fn
+}
+
 // AddMirror adds an httpHandler to mirror to.
 func (m *Mirroring) AddMirror(handler http.Handler, percent int) error {
+	return m.AddMirrorWithRule("", handler, percent, "")
+}
+
+// AddMirrorWithRule adds an httpHandler, named after the mirror service, to mirror the requests matching the rule to,
+// the rule having the syntax of the router rules.
+// The percentage applies to the matching requests, all the requests matching an empty rule.
+func (m *Mirroring) AddMirrorWithRule(name string, handler http.Handler, percent int, rule string) error {
 	if percent < 0 || percent > 100 {
 		return errors.New("percent must be between 0 and 100")
 	}
-	m.mirrorHandlers = append(m.mirrorHandlers, &mirrorHandler{Handler: handler, percent: percent})
+
+	mh := &mirrorHandler{Handler: handler, name: name, percent: percent}
+
+	if rule != "" {
+		match, err := httpmuxer.NewRuleMatcher(rule)
+		if err != nil {
+			return fmt.Errorf("invalid mirror rule: %w", err)
+		}
+		mh.match = match
+	}
+
+	m.mirrorHandlers = append(m.mirrorHandlers, mh)
 	return nil
 }
 
@@ -163,6 +223,52 @@ func (m *Mirroring) RegisterStatusUpdater(fn func(up bool)) error {
 	return nil
 }
 
+// statusRecorder records the status code of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	// the informational responses are followed by the final response.
+	if r.status == 0 && (code >= 200 || code == http.StatusSwitchingProtocols) {
+		r.status = code
+	}
+
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(data []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := r.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("%T is not a http.Hijacker", r.ResponseWriter)
+	}
+
+	return hijacker.Hijack()
+}
+
+// getStatus returns the status code of the response, http.StatusOK when nothing was written, as sent by the server.
+func (r *statusRecorder) getStatus() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
 type blackHoleResponseWriter struct{}
 
 func (b blackHoleResponseWriter) Flush() {}
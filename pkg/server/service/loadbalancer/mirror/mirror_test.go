@@ -6,10 +6,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v3/pkg/safe"
 )
 
@@ -88,6 +91,88 @@ func TestInvalidPercent(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestMirroringWithRule(t *testing.T) {
+	var countMirror1, countMirror2 int32
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	})
+	pool := safe.NewPool(context.Background())
+	mirror := New(handler, pool, defaultMaxBodySize, nil)
+	err := mirror.AddMirrorWithRule("", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&countMirror1, 1)
+	}), 100, "Header(`X-Mirror`, `true`) && Method(`GET`)")
+	assert.NoError(t, err)
+
+	err = mirror.AddMirrorWithRule("", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&countMirror2, 1)
+	}), 50, "PathPrefix(`/api`)")
+	assert.NoError(t, err)
+
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest(http.MethodGet, "/api", nil)
+		if i%2 == 0 {
+			req.Header.Set("X-Mirror", "true")
+		}
+		mirror.ServeHTTP(httptest.NewRecorder(), req)
+
+		mirror.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	pool.Stop()
+
+	// the percentage applies to the requests matching the rule.
+	assert.Equal(t, 5, int(atomic.LoadInt32(&countMirror1)))
+	assert.Equal(t, 5, int(atomic.LoadInt32(&countMirror2)))
+}
+
+func TestMirroringComparison(t *testing.T) {
+	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusEarlyHints)
+		_, _ = rw.Write([]byte("foo"))
+	})
+	pool := safe.NewPool(context.Background())
+	mirror := New(handler, pool, defaultMaxBodySize, nil)
+
+	var lock sync.Mutex
+	comparisons := map[string][]Comparison{}
+	mirror.SetComparisonRecorder(func(name string, comparison Comparison) {
+		lock.Lock()
+		defer lock.Unlock()
+		comparisons[name] = append(comparisons[name], comparison)
+	})
+
+	err := mirror.AddMirrorWithRule("same", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), 100, "")
+	assert.NoError(t, err)
+
+	err = mirror.AddMirrorWithRule("failing", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		rw.WriteHeader(http.StatusBadGateway)
+	}), 100, "")
+	assert.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	mirror.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	pool.Stop()
+
+	assert.Equal(t, "foo", rec.Body.String())
+
+	require.Len(t, comparisons["same"], 1)
+	assert.Equal(t, http.StatusOK, comparisons["same"][0].Status)
+	assert.Equal(t, http.StatusOK, comparisons["same"][0].MirrorStatus)
+
+	require.Len(t, comparisons["failing"], 1)
+	assert.Equal(t, http.StatusOK, comparisons["failing"][0].Status)
+	assert.Equal(t, http.StatusBadGateway, comparisons["failing"][0].MirrorStatus)
+	assert.GreaterOrEqual(t, comparisons["failing"][0].MirrorLatency, 10*time.Millisecond)
+}
+
+func TestInvalidRule(t *testing.T) {
+	mirror := New(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}), safe.NewPool(context.Background()), defaultMaxBodySize, nil)
+	err := mirror.AddMirrorWithRule("", nil, 10, "Foo(`bar`)")
+	assert.Error(t, err)
+}
+
 func TestHijack(t *testing.T) {
 	handler := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 		rw.WriteHeader(http.StatusOK)
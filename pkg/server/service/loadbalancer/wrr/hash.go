@@ -0,0 +1,168 @@
+package wrr
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/ip"
+)
+
+// defaultVirtualNodes is the default number of points of a server on the hash ring, as in ketama.
+const defaultVirtualNodes = 160
+
+// stickyHash binds the requests to the servers by the consistent hashing of a request attribute,
+// the servers being placed on a ketama ring.
+type stickyHash struct {
+	header       string
+	cookie       string
+	ipStrategy   ip.Strategy
+	virtualNodes int
+}
+
+// key returns the hashed attribute of the request, or an empty string when the request does not have it.
+func (s *stickyHash) key(req *http.Request) string {
+	switch {
+	case s.header != "":
+		return req.Header.Get(s.header)
+	case s.cookie != "":
+		cookie, err := req.Cookie(s.cookie)
+		if err != nil {
+			return ""
+		}
+		return cookie.Value
+	default:
+		return s.ipStrategy.GetIP(req)
+	}
+}
+
+// ringPoint is a point of a server on the hash ring.
+type ringPoint struct {
+	hash    uint32
+	handler *namedHandler
+}
+
+// SetStickyHash binds the requests to the servers by the consistent hashing of a header, a cookie, or the client IP.
+// The requests without the hashed attribute are load-balanced by the strategy.
+// Not thread safe.
+func (b *Balancer) SetStickyHash(config *dynamic.StickyHash) error {
+	if config == nil {
+		b.stickyHash = nil
+		return nil
+	}
+
+	if b.stickyCookie != nil {
+		return errors.New("sticky cookie and hash cannot be both set")
+	}
+
+	if config.Header != "" && config.Cookie != "" {
+		return errors.New("sticky hash header and cookie cannot be both set")
+	}
+
+	if config.VirtualNodes < 0 {
+		return fmt.Errorf("invalid sticky hash virtualNodes %d, must be positive", config.VirtualNodes)
+	}
+
+	s := &stickyHash{
+		header:       config.Header,
+		cookie:       config.Cookie,
+		virtualNodes: config.VirtualNodes,
+	}
+
+	if s.virtualNodes == 0 {
+		s.virtualNodes = defaultVirtualNodes
+	}
+
+	if s.header == "" && s.cookie == "" {
+		ipStrategy := config.IPStrategy
+		if ipStrategy == nil {
+			ipStrategy = &dynamic.IPStrategy{}
+		}
+
+		var err error
+		s.ipStrategy, err = ipStrategy.Get()
+		if err != nil {
+			return fmt.Errorf("sticky hash ipStrategy: %w", err)
+		}
+	}
+
+	b.stickyHash = s
+
+	return nil
+}
+
+// hashServer returns the first healthy server, not tried yet, clockwise from the hash of the request attribute on the ring,
+// or nil when the request does not have the attribute, or no such server is left.
+func (b *Balancer) hashServer(req *http.Request, tried map[string]struct{}) *namedHandler {
+	key := b.stickyHash.key(req)
+	if key == "" {
+		return nil
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.ring == nil {
+		b.ring = buildRing(b.handlers, b.stickyHash.virtualNodes)
+	}
+
+	if len(b.ring) == 0 {
+		return nil
+	}
+
+	hash := ringHash(key)
+	start := sort.Search(len(b.ring), func(i int) bool { return b.ring[i].hash >= hash })
+
+	for i := 0; i < len(b.ring); i++ {
+		handler := b.ring[(start+i)%len(b.ring)].handler
+		if _, ok := b.status[handler.name]; !ok {
+			continue
+		}
+		if _, ok := tried[handler.name]; ok {
+			continue
+		}
+
+		log.Debug().Msgf("Service selected by hash: %s", handler.name)
+		return handler
+	}
+
+	return nil
+}
+
+// buildRing places the handlers on the hash ring, with a number of points proportional to their weight.
+// The points only depend on the handler names, so that the ring only changes by the points of the added or removed handlers.
+func buildRing(handlers []*namedHandler, virtualNodes int) []ringPoint {
+	var ring []ringPoint
+	for _, handler := range handlers {
+		points := int(float64(virtualNodes) * handler.weight)
+
+		// each digest gives 4 points, as in ketama.
+		for i := 0; i < (points+3)/4; i++ {
+			digest := md5.Sum([]byte(handler.name + "-" + strconv.Itoa(i)))
+			for j := 0; j < 4; j++ {
+				ring = append(ring, ringPoint{hash: binary.LittleEndian.Uint32(digest[j*4:]), handler: handler})
+			}
+		}
+	}
+
+	sort.Slice(ring, func(i, j int) bool {
+		if ring[i].hash == ring[j].hash {
+			// the ties are broken by name, for the ring not to depend on the order of the handlers.
+			return ring[i].handler.name < ring[j].handler.name
+		}
+		return ring[i].hash < ring[j].hash
+	})
+
+	return ring
+}
+
+func ringHash(key string) uint32 {
+	digest := md5.Sum([]byte(key))
+	return binary.LittleEndian.Uint32(digest[:4])
+}
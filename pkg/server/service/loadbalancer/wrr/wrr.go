@@ -4,8 +4,13 @@ import (
 	"container/heap"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"math/rand"
 	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
@@ -16,6 +21,21 @@ type namedHandler struct {
 	name     string
 	weight   float64
 	deadline float64
+	// inflight is the number of requests being served by the handler.
+	inflight atomic.Int64
+}
+
+// ServeHTTP serves the request, counting it as in-flight while it is served.
+func (h *namedHandler) ServeHTTP(rw http.ResponseWriter, req *http.Request) {
+	h.inflight.Add(1)
+	defer h.inflight.Add(-1)
+
+	h.Handler.ServeHTTP(rw, req)
+}
+
+// load returns the number of in-flight requests of the handler relative to its weight.
+func (h *namedHandler) load() float64 {
+	return float64(h.inflight.Load()) / h.weight
 }
 
 type stickyCookie struct {
@@ -29,8 +49,11 @@ type stickyCookie struct {
 // Each pick from the schedule has the earliest deadline entry selected.
 // Entries have deadlines set at currentDeadline + 1 / weight,
 // providing weighted round-robin behavior with floating point weights and an O(log n) pick time.
+// The least connections, power of two choices and random strategies can be selected instead, with SetStrategy.
+// The requests can be bound to the servers by a sticky cookie, or by consistent hashing with SetStickyHash.
 type Balancer struct {
 	stickyCookie     *stickyCookie
+	stickyHash       *stickyHash
 	wantsHealthCheck bool
 
 	mutex       sync.RWMutex
@@ -39,18 +62,39 @@ type Balancer struct {
 	// status is a record of which child services of the Balancer are healthy, keyed
 	// by name of child service. A service is initially added to the map when it is
 	// created via Add, and it is later removed or added to the map as needed,
-	// through the SetStatus method.
+	// through the SetStatus and SetEjected methods.
 	status map[string]struct{}
+	// down is the set of child services reported down by the active health check, keyed by name.
+	down map[string]struct{}
+	// ejected is the set of child services ejected by the passive health check, keyed by name.
+	// A child service is up only when it is neither down nor ejected.
+	ejected map[string]struct{}
 	// updaters is the list of hooks that are run (to update the Balancer
 	// parent(s)), whenever the Balancer status changes.
 	updaters []func(bool)
+	// draining is the set of handlers which do not receive new requests,
+	// but still serve the requests bound to them by a sticky cookie, keyed by name.
+	draining map[string]http.Handler
+	// retryConnectionRefused is whether the idempotent requests refused by a server are retried on another one.
+	retryConnectionRefused bool
+	// strategy is the load-balancing strategy picking the next server, see dynamic.BalancerStrategyWRR and the like.
+	strategy string
+	// rand is the source of the random strategies, guarded by the mutex.
+	rand *rand.Rand
+	// ring is the hash ring of the sticky hash, built on the first hashed request, and reset when a handler is added.
+	ring []ringPoint
 }
 
 // New creates a new load balancer.
 func New(sticky *dynamic.Sticky, wantHealthCheck bool) *Balancer {
 	balancer := &Balancer{
 		status:           make(map[string]struct{}),
+		down:             make(map[string]struct{}),
+		ejected:          make(map[string]struct{}),
+		draining:         make(map[string]http.Handler),
 		wantsHealthCheck: wantHealthCheck,
+		strategy:         dynamic.BalancerStrategyWRR,
+		rand:             rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 	if sticky != nil && sticky.Cookie != nil {
 		balancer.stickyCookie = &stickyCookie{
@@ -99,8 +143,40 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 	b.mutex.Lock()
 	defer b.mutex.Unlock()
 
+	if up {
+		delete(b.down, childName)
+	} else {
+		b.down[childName] = struct{}{}
+	}
+
+	b.updateStatus(ctx, childName)
+}
+
+// SetEjected ejects, or restores, the given child of the balancer, on behalf of the passive health check,
+// and reports whether the child is up afterward, that is when the active health check does not report it down.
+func (b *Balancer) SetEjected(ctx context.Context, childName string, ejected bool) bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if ejected {
+		b.ejected[childName] = struct{}{}
+	} else {
+		delete(b.ejected, childName)
+	}
+
+	return b.updateStatus(ctx, childName)
+}
+
+// updateStatus updates the status of the given child from the verdicts of the active and passive health checks,
+// propagates the status change of the balancer, and reports whether the child is up.
+// The mutex must be held.
+func (b *Balancer) updateStatus(ctx context.Context, childName string) bool {
 	upBefore := len(b.status) > 0
 
+	_, down := b.down[childName]
+	_, ejected := b.ejected[childName]
+	up := !down && !ejected
+
 	status := "DOWN"
 	if up {
 		status = "UP"
@@ -124,7 +200,7 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 	if upBefore == upAfter {
 		// We're still with the same status, no need to propagate
 		log.Ctx(ctx).Debug().Msgf("Still %s, no need to propagate", status)
-		return
+		return up
 	}
 
 	// Status Change
@@ -132,6 +208,8 @@ func (b *Balancer) SetStatus(ctx context.Context, childName string, up bool) {
 	for _, fn := range b.updaters {
 		fn(upAfter)
 	}
+
+	return up
 }
 
 // RegisterStatusUpdater adds fn to the list of hooks that are run when the
@@ -155,6 +233,15 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 		return nil, errNoAvailableServer
 	}
 
+	switch b.strategy {
+	case dynamic.BalancerStrategyLeastConn:
+		return b.nextLeastConnServer()
+	case dynamic.BalancerStrategyP2C:
+		return b.nextP2CServer()
+	case dynamic.BalancerStrategyRandom:
+		return b.nextRandomServer()
+	}
+
 	var handler *namedHandler
 	for {
 		// Pick handler with closest deadline.
@@ -174,7 +261,104 @@ func (b *Balancer) nextServer() (*namedHandler, error) {
 	return handler, nil
 }
 
+// healthyHandlers returns the handlers which are up.
+// The mutex must be held.
+func (b *Balancer) healthyHandlers() []*namedHandler {
+	healthy := make([]*namedHandler, 0, len(b.status))
+	for _, handler := range b.handlers {
+		if _, ok := b.status[handler.name]; ok {
+			healthy = append(healthy, handler)
+		}
+	}
+
+	return healthy
+}
+
+// nextLeastConnServer picks the healthy handler with the fewest in-flight requests relative to its weight,
+// the ties being broken randomly.
+// The mutex must be held.
+func (b *Balancer) nextLeastConnServer() (*namedHandler, error) {
+	var handler *namedHandler
+	var ties int
+	for _, candidate := range b.healthyHandlers() {
+		switch {
+		case handler == nil || candidate.load() < handler.load():
+			handler, ties = candidate, 1
+		case candidate.load() == handler.load():
+			// reservoir sampling among the handlers with the same load.
+			ties++
+			if b.rand.Intn(ties) == 0 {
+				handler = candidate
+			}
+		}
+	}
+
+	if handler == nil {
+		return nil, errNoAvailableServer
+	}
+
+	log.Debug().Msgf("Service selected by least connections: %s", handler.name)
+	return handler, nil
+}
+
+// nextP2CServer picks two random healthy handlers, and returns the one with the fewest in-flight requests relative to its weight.
+// The mutex must be held.
+func (b *Balancer) nextP2CServer() (*namedHandler, error) {
+	healthy := b.healthyHandlers()
+	if len(healthy) == 0 {
+		return nil, errNoAvailableServer
+	}
+
+	handler := healthy[b.rand.Intn(len(healthy))]
+	if len(healthy) > 1 {
+		i := b.rand.Intn(len(healthy) - 1)
+		if healthy[i] == handler {
+			// the second choice is distinct from the first one.
+			i = len(healthy) - 1
+		}
+
+		if other := healthy[i]; other.load() < handler.load() {
+			handler = other
+		}
+	}
+
+	log.Debug().Msgf("Service selected by P2C: %s", handler.name)
+	return handler, nil
+}
+
+// nextRandomServer picks a random healthy handler, proportionally to its weight.
+// The mutex must be held.
+func (b *Balancer) nextRandomServer() (*namedHandler, error) {
+	healthy := b.healthyHandlers()
+	if len(healthy) == 0 {
+		return nil, errNoAvailableServer
+	}
+
+	var total float64
+	for _, handler := range healthy {
+		total += handler.weight
+	}
+
+	pick := b.rand.Float64() * total
+	handler := healthy[len(healthy)-1]
+	for _, candidate := range healthy {
+		pick -= candidate.weight
+		if pick < 0 {
+			handler = candidate
+			break
+		}
+	}
+
+	log.Debug().Msgf("Service selected randomly: %s", handler.name)
+	return handler, nil
+}
+
 func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if b.retryConnectionRefused && isIdempotent(req.Method) {
+		b.serveWithRetries(w, req)
+		return
+	}
+
 	if b.stickyCookie != nil {
 		cookie, err := req.Cookie(b.stickyCookie.name)
 
@@ -183,6 +367,14 @@ func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 
 		if err == nil && cookie != nil {
+			b.mutex.RLock()
+			handler, draining := b.draining[cookie.Value]
+			b.mutex.RUnlock()
+			if draining {
+				handler.ServeHTTP(w, req)
+				return
+			}
+
 			for _, handler := range b.handlers {
 				if handler.name != cookie.Value {
 					continue
@@ -203,6 +395,13 @@ func (b *Balancer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		}
 	}
 
+	if b.stickyHash != nil {
+		if server := b.hashServer(req, nil); server != nil {
+			server.ServeHTTP(w, req)
+			return
+		}
+	}
+
 	server, err := b.nextServer()
 	if err != nil {
 		if errors.Is(err, errNoAvailableServer) {
@@ -239,5 +438,188 @@ func (b *Balancer) Add(name string, handler http.Handler, weight *int) {
 	h.deadline = b.curDeadline + 1/h.weight
 	heap.Push(b, h)
 	b.status[name] = struct{}{}
+	b.ring = nil
 	b.mutex.Unlock()
 }
+
+// AddDraining adds a handler which does not receive new requests,
+// but still serves the requests bound to it by a sticky cookie.
+func (b *Balancer) AddDraining(name string, handler http.Handler) {
+	b.mutex.Lock()
+	b.draining[name] = handler
+	b.mutex.Unlock()
+}
+
+// SetStrategy sets the load-balancing strategy picking the next server, the weighted round robin being the default.
+// Not thread safe.
+func (b *Balancer) SetStrategy(strategy string) error {
+	switch strategy {
+	case "":
+		b.strategy = dynamic.BalancerStrategyWRR
+	case dynamic.BalancerStrategyWRR, dynamic.BalancerStrategyLeastConn, dynamic.BalancerStrategyP2C, dynamic.BalancerStrategyRandom:
+		b.strategy = strategy
+	default:
+		return fmt.Errorf("unknown load-balancing strategy: %s", strategy)
+	}
+
+	return nil
+}
+
+// EnableConnectionRefusedRetry makes the balancer retry the idempotent requests refused by a server on another one.
+// Not thread safe.
+func (b *Balancer) EnableConnectionRefusedRetry() {
+	b.retryConnectionRefused = true
+}
+
+type connectionRefusedKey struct{}
+
+// ConnectionRefused records that a server refused the connection of the request,
+// and reports whether the balancer retries the request on another server.
+// When it does, the response must not be written.
+func ConnectionRefused(req *http.Request) bool {
+	refused, ok := req.Context().Value(connectionRefusedKey{}).(*bool)
+	if !ok {
+		return false
+	}
+
+	*refused = true
+	return true
+}
+
+// serveWithRetries serves the request with the server bound by the sticky cookie or hash, or with the next server,
+// and retries it on another healthy server as long as the servers refuse the connection.
+// The last server is tried without the retry marker, so that its error is written as usual.
+func (b *Balancer) serveWithRetries(w http.ResponseWriter, req *http.Request) {
+	if req.Body != nil && req.Body != http.NoBody {
+		// the transport closes the body on errors.
+		closableBody := req.Body
+		defer closableBody.Close()
+		req.Body = io.NopCloser(closableBody)
+	}
+
+	tried := make(map[string]struct{})
+
+	if b.stickyCookie != nil {
+		cookie, err := req.Cookie(b.stickyCookie.name)
+
+		if err != nil && !errors.Is(err, http.ErrNoCookie) {
+			log.Warn().Err(err).Msg("Error while reading cookie")
+		}
+
+		if err == nil && cookie != nil {
+			b.mutex.RLock()
+			handler, draining := b.draining[cookie.Value]
+			_, up := b.status[cookie.Value]
+			b.mutex.RUnlock()
+
+			if draining {
+				handler.ServeHTTP(w, req)
+				return
+			}
+
+			for _, handler := range b.handlers {
+				if handler.name != cookie.Value || !up {
+					continue
+				}
+
+				if !b.serveAttempt(w, req, handler, tried) {
+					return
+				}
+				break
+			}
+		}
+	}
+
+	if b.stickyHash != nil {
+		// the request is retried on the next servers of the ring, for the retried requests to stick too.
+		for server := b.hashServer(req, tried); server != nil; server = b.hashServer(req, tried) {
+			if !b.serveAttempt(w, req, server, tried) {
+				return
+			}
+		}
+	}
+
+	for {
+		server, err := b.nextServer()
+		if err != nil {
+			if errors.Is(err, errNoAvailableServer) {
+				http.Error(w, errNoAvailableServer.Error(), http.StatusServiceUnavailable)
+			} else {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		// a server already tried is served again only when no other healthy server is left, e.g. after a status change.
+		if _, ok := tried[server.name]; ok && b.hasUntried(tried) {
+			continue
+		}
+
+		var cookie string
+		if b.stickyCookie != nil {
+			cookie = (&http.Cookie{Name: b.stickyCookie.name, Value: server.name, Path: "/", HttpOnly: b.stickyCookie.httpOnly, Secure: b.stickyCookie.secure}).String()
+			w.Header().Add("Set-Cookie", cookie)
+		}
+
+		if !b.serveAttempt(w, req, server, tried) {
+			return
+		}
+
+		if cookie != "" {
+			removeHeaderValue(w.Header(), "Set-Cookie", cookie)
+		}
+	}
+}
+
+// serveAttempt serves the request with the given server, and reports whether the server refused the connection.
+func (b *Balancer) serveAttempt(w http.ResponseWriter, req *http.Request, server *namedHandler, tried map[string]struct{}) bool {
+	tried[server.name] = struct{}{}
+
+	if !b.hasUntried(tried) {
+		server.ServeHTTP(w, req)
+		return false
+	}
+
+	var refused bool
+	server.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), connectionRefusedKey{}, &refused)))
+
+	if refused {
+		log.Ctx(req.Context()).Debug().Msgf("Connection refused by %s, retrying on another server", server.name)
+	}
+
+	return refused
+}
+
+// hasUntried reports whether some healthy servers have not been tried yet.
+func (b *Balancer) hasUntried(tried map[string]struct{}) bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for name := range b.status {
+		if _, ok := tried[name]; !ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeHeaderValue(header http.Header, key, value string) {
+	values := header.Values(key)
+	for i, v := range values {
+		if v == value {
+			header[http.CanonicalHeaderKey(key)] = append(values[:i:i], values[i+1:]...)
+			return
+		}
+	}
+}
+
+// isIdempotent reports whether the method is idempotent (RFC 9110, section 9.2.2).
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 )
 
@@ -133,6 +137,52 @@ func TestBalancerDownThenUp(t *testing.T) {
 	assert.Equal(t, 1, recorder.save["second"])
 }
 
+func TestBalancerEjected(t *testing.T) {
+	balancer := New(nil, false)
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	ctx := context.WithValue(context.Background(), serviceName, "parent")
+
+	assert.False(t, balancer.SetEjected(ctx, "second", true))
+
+	// the active health check does not restore an ejected server.
+	balancer.SetStatus(ctx, "second", true)
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 3; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["first"])
+
+	// the end of the ejection does not restore a server reported down by the active health check.
+	balancer.SetStatus(ctx, "second", false)
+	assert.False(t, balancer.SetEjected(ctx, "second", false))
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 3; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 3, recorder.save["first"])
+
+	balancer.SetStatus(ctx, "second", true)
+
+	recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 2; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	assert.Equal(t, 1, recorder.save["first"])
+	assert.Equal(t, 1, recorder.save["second"])
+}
+
 func TestBalancerPropagate(t *testing.T) {
 	balancer1 := New(nil, true)
 
@@ -249,6 +299,153 @@ func TestSticky(t *testing.T) {
 	assert.Equal(t, 3, recorder.save["second"])
 }
 
+func TestStickyDraining(t *testing.T) {
+	balancer := New(&dynamic.Sticky{
+		Cookie: &dynamic.Cookie{Name: "test"},
+	}, false)
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.AddDraining("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+
+	// requests bound to the draining server are still served by it.
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "test", Value: "second"})
+	for i := 0; i < 3; i++ {
+		balancer.ServeHTTP(recorder, req)
+	}
+
+	// new requests are not.
+	for i := 0; i < 3; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, 3, recorder.save["first"])
+	assert.Equal(t, 3, recorder.save["second"])
+}
+
+func TestBalancerOnlyDraining(t *testing.T) {
+	balancer := New(nil, false)
+
+	balancer.AddDraining("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Result().StatusCode)
+}
+
+func TestBalancerConnectionRefusedRetry(t *testing.T) {
+	refusing := http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if ConnectionRefused(req) {
+			return
+		}
+		rw.Header().Set("server", "refused")
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	serving := func(name string) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("server", name)
+			rw.WriteHeader(http.StatusOK)
+		})
+	}
+
+	testCases := []struct {
+		desc           string
+		method         string
+		servers        []string
+		expectedStatus int
+		expectedServer string
+	}{
+		{
+			desc:           "idempotent request retried on another server",
+			method:         http.MethodGet,
+			servers:        []string{"refusing", "second"},
+			expectedStatus: http.StatusOK,
+			expectedServer: "second",
+		},
+		{
+			desc:           "non idempotent request not retried",
+			method:         http.MethodPost,
+			servers:        []string{"refusing", "second"},
+			expectedStatus: http.StatusBadGateway,
+			expectedServer: "refused",
+		},
+		{
+			desc:           "all servers refusing",
+			method:         http.MethodPut,
+			servers:        []string{"refusing", "refusing2"},
+			expectedStatus: http.StatusBadGateway,
+			expectedServer: "refused",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			balancer := New(nil, false)
+			balancer.EnableConnectionRefusedRetry()
+
+			for _, name := range test.servers {
+				if strings.HasPrefix(name, "refusing") {
+					balancer.Add(name, refusing, Int(1))
+					continue
+				}
+				balancer.Add(name, serving(name), Int(1))
+			}
+
+			recorder := httptest.NewRecorder()
+			balancer.ServeHTTP(recorder, httptest.NewRequest(test.method, "/", nil))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+			assert.Equal(t, test.expectedServer, recorder.Header().Get("server"))
+		})
+	}
+}
+
+func TestStickyConnectionRefusedRetry(t *testing.T) {
+	balancer := New(&dynamic.Sticky{
+		Cookie: &dynamic.Cookie{Name: "test"},
+	}, false)
+	balancer.EnableConnectionRefusedRetry()
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if ConnectionRefused(req) {
+			return
+		}
+		rw.WriteHeader(http.StatusBadGateway)
+	}), Int(1))
+
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(&http.Cookie{Name: "test", Value: "first"})
+
+	recorder := httptest.NewRecorder()
+	balancer.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "second", recorder.Header().Get("server"))
+
+	cookies := recorder.Result().Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "second", cookies[0].Value)
+}
+
 // TestBalancerBias makes sure that the WRR algorithm spreads elements evenly right from the start,
 // and that it does not "over-favor" the high-weighted ones with a biased start-up regime.
 func TestBalancerBias(t *testing.T) {
@@ -275,6 +472,321 @@ func TestBalancerBias(t *testing.T) {
 	assert.Equal(t, wantSequence, recorder.sequence)
 }
 
+func TestBalancer_SetStrategy(t *testing.T) {
+	balancer := New(nil, false)
+
+	require.NoError(t, balancer.SetStrategy(""))
+	assert.Equal(t, dynamic.BalancerStrategyWRR, balancer.strategy)
+
+	require.NoError(t, balancer.SetStrategy(dynamic.BalancerStrategyP2C))
+	assert.Equal(t, dynamic.BalancerStrategyP2C, balancer.strategy)
+
+	assert.EqualError(t, balancer.SetStrategy("foo"), "unknown load-balancing strategy: foo")
+}
+
+func TestBalancerStrategyOneServerDown(t *testing.T) {
+	strategies := []string{dynamic.BalancerStrategyLeastConn, dynamic.BalancerStrategyP2C, dynamic.BalancerStrategyRandom}
+
+	for _, strategy := range strategies {
+		strategy := strategy
+		t.Run(strategy, func(t *testing.T) {
+			t.Parallel()
+
+			balancer := New(nil, false)
+			require.NoError(t, balancer.SetStrategy(strategy))
+
+			balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("server", "first")
+				rw.WriteHeader(http.StatusOK)
+			}), Int(1))
+
+			balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("server", "second")
+				rw.WriteHeader(http.StatusOK)
+			}), Int(1))
+
+			balancer.SetStatus(context.WithValue(context.Background(), serviceName, "parent"), "second", false)
+
+			recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+			for i := 0; i < 10; i++ {
+				balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+			}
+
+			assert.Equal(t, 10, recorder.save["first"])
+
+			balancer.SetStatus(context.WithValue(context.Background(), serviceName, "parent"), "first", false)
+
+			recorder = &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+			balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			assert.Equal(t, []int{http.StatusServiceUnavailable}, recorder.status)
+		})
+	}
+}
+
+func TestBalancerLeastConn(t *testing.T) {
+	balancer := New(nil, false)
+	require.NoError(t, balancer.SetStrategy(dynamic.BalancerStrategyLeastConn))
+
+	started := make(chan string)
+	release := make(chan struct{})
+
+	for name, weight := range map[string]int{"first": 3, "second": 1} {
+		name := name
+		balancer.Add(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			started <- name
+			<-release
+		}), Int(weight))
+	}
+
+	var wg sync.WaitGroup
+	counts := map[string]int{}
+
+	// each request is in-flight when the next one is balanced.
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+
+		counts[<-started]++
+	}
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"first": 6, "second": 2}, counts)
+}
+
+func TestBalancerP2C(t *testing.T) {
+	balancer := New(nil, false)
+	require.NoError(t, balancer.SetStrategy(dynamic.BalancerStrategyP2C))
+
+	started := make(chan string)
+	release := make(chan struct{})
+
+	for _, name := range []string{"first", "second"} {
+		name := name
+		balancer.Add(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			started <- name
+			<-release
+		}), Int(1))
+	}
+
+	var wg sync.WaitGroup
+	counts := map[string]int{}
+
+	// with two servers, both are always picked, and the least loaded one serves the request.
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			balancer.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+
+		counts[<-started]++
+	}
+
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, map[string]int{"first": 3, "second": 3}, counts)
+}
+
+func TestBalancerRandom(t *testing.T) {
+	balancer := New(nil, false)
+	require.NoError(t, balancer.SetStrategy(dynamic.BalancerStrategyRandom))
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(3))
+
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 4000; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.InDelta(t, 3000, recorder.save["first"], 200)
+	assert.InDelta(t, 1000, recorder.save["second"], 200)
+}
+
+func TestBalancer_SetStickyHash(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		sticky      *dynamic.Sticky
+		hash        *dynamic.StickyHash
+		expectedErr string
+	}{
+		{
+			desc: "client IP",
+			hash: &dynamic.StickyHash{},
+		},
+		{
+			desc: "header",
+			hash: &dynamic.StickyHash{Header: "X-Session"},
+		},
+		{
+			desc:        "header and cookie",
+			hash:        &dynamic.StickyHash{Header: "X-Session", Cookie: "session"},
+			expectedErr: "sticky hash header and cookie cannot be both set",
+		},
+		{
+			desc:        "sticky cookie",
+			sticky:      &dynamic.Sticky{Cookie: &dynamic.Cookie{Name: "sticky"}},
+			hash:        &dynamic.StickyHash{Header: "X-Session"},
+			expectedErr: "sticky cookie and hash cannot be both set",
+		},
+		{
+			desc:        "negative virtual nodes",
+			hash:        &dynamic.StickyHash{VirtualNodes: -1},
+			expectedErr: "invalid sticky hash virtualNodes -1, must be positive",
+		},
+		{
+			desc:        "invalid IP strategy",
+			hash:        &dynamic.StickyHash{IPStrategy: &dynamic.IPStrategy{ExcludedIPs: []string{"foo"}}},
+			expectedErr: "sticky hash ipStrategy: parsing CIDR trusted IPs <nil>: invalid CIDR address: foo",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			err := New(test.sticky, false).SetStickyHash(test.hash)
+			if test.expectedErr != "" {
+				assert.EqualError(t, err, test.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBalancerStickyHash(t *testing.T) {
+	balancer := New(nil, false)
+	require.NoError(t, balancer.SetStickyHash(&dynamic.StickyHash{Header: "X-Session"}))
+
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		balancer.Add(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("server", name)
+			rw.WriteHeader(http.StatusOK)
+		}), Int(1))
+	}
+
+	serve := func(session string) string {
+		recorder := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Session", session)
+		balancer.ServeHTTP(recorder, req)
+		return recorder.Header().Get("server")
+	}
+
+	bound := map[string]string{}
+	counts := map[string]int{}
+	for i := 0; i < 300; i++ {
+		session := strconv.Itoa(i)
+		bound[session] = serve(session)
+		counts[bound[session]]++
+
+		assert.Equal(t, bound[session], serve(session))
+	}
+
+	for _, name := range []string{"first", "second", "third"} {
+		assert.InDelta(t, 100, counts[name], 40, name)
+	}
+
+	// only the sessions of the down server move.
+	balancer.SetStatus(context.Background(), "second", false)
+
+	for session, server := range bound {
+		if server == "second" {
+			assert.NotEqual(t, "second", serve(session))
+		} else {
+			assert.Equal(t, server, serve(session))
+		}
+	}
+
+	balancer.SetStatus(context.Background(), "second", true)
+
+	for session, server := range bound {
+		assert.Equal(t, server, serve(session))
+	}
+}
+
+func TestBalancerStickyHashServerAdded(t *testing.T) {
+	bind := func(names ...string) map[string]string {
+		balancer := New(nil, false)
+		require.NoError(t, balancer.SetStickyHash(&dynamic.StickyHash{Cookie: "session"}))
+
+		for _, name := range names {
+			name := name
+			balancer.Add(name, http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("server", name)
+				rw.WriteHeader(http.StatusOK)
+			}), Int(1))
+		}
+
+		bound := map[string]string{}
+		for i := 0; i < 400; i++ {
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.AddCookie(&http.Cookie{Name: "session", Value: strconv.Itoa(i)})
+			balancer.ServeHTTP(recorder, req)
+			bound[strconv.Itoa(i)] = recorder.Header().Get("server")
+		}
+		return bound
+	}
+
+	before := bind("first", "second", "third")
+	after := bind("fourth", "third", "second", "first")
+
+	var moved int
+	for session, server := range after {
+		if server == before[session] {
+			continue
+		}
+
+		// the sessions only move to the added server.
+		assert.Equal(t, "fourth", server)
+		moved++
+	}
+
+	assert.InDelta(t, 100, moved, 40)
+}
+
+func TestBalancerStickyHashMissingKey(t *testing.T) {
+	balancer := New(nil, false)
+	require.NoError(t, balancer.SetStickyHash(&dynamic.StickyHash{Header: "X-Session"}))
+
+	balancer.Add("first", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "first")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	balancer.Add("second", http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("server", "second")
+		rw.WriteHeader(http.StatusOK)
+	}), Int(1))
+
+	// the requests without the header are load-balanced.
+	recorder := &responseRecorder{ResponseRecorder: httptest.NewRecorder(), save: map[string]int{}}
+	for i := 0; i < 4; i++ {
+		balancer.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	assert.Equal(t, map[string]int{"first": 2, "second": 2}, recorder.save)
+}
+
 func Int(v int) *int { return &v }
 
 type responseRecorder struct {
@@ -9,9 +9,11 @@ import (
 	"net/http/httputil"
 	"net/url"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/traefik/traefik/v3/pkg/server/service/loadbalancer/wrr"
 	"golang.org/x/net/http/httpguts"
 )
 
@@ -93,9 +95,20 @@ func isWebSocketUpgrade(req *http.Request) bool {
 }
 
 func errorHandler(w http.ResponseWriter, req *http.Request, err error) {
+	// the load-balancer retries the request on another server, nothing must be written.
+	if errors.Is(err, syscall.ECONNREFUSED) && wrr.ConnectionRefused(req) {
+		log.Ctx(req.Context()).Debug().Err(err).Msg("Connection refused")
+		return
+	}
+
 	statusCode := http.StatusInternalServerError
 
+	var maxBytesErr *http.MaxBytesError
+
 	switch {
+	case errors.As(err, &maxBytesErr):
+		// the request body exceeds the limit set by a middleware.
+		statusCode = http.StatusRequestEntityTooLarge
 	case errors.Is(err, io.EOF):
 		statusCode = http.StatusBadGateway
 	case errors.Is(err, context.Canceled):
@@ -1,12 +1,15 @@
 package service
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/traefik/traefik/v3/pkg/testhelpers"
 )
 
@@ -35,3 +38,49 @@ func BenchmarkProxy(b *testing.B) {
 		handler.ServeHTTP(w, req)
 	}
 }
+
+func TestProxy_errorStatusCodes(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = io.Copy(io.Discard, req.Body)
+		time.Sleep(50 * time.Millisecond)
+	}))
+	t.Cleanup(backend.Close)
+
+	target := testhelpers.MustParseURL(backend.URL)
+	handler := buildSingleHostProxy(target, false, 0, http.DefaultTransport, newBufferPool())
+
+	testCases := []struct {
+		desc           string
+		req            func(rw http.ResponseWriter) *http.Request
+		expectedStatus int
+	}{
+		{
+			desc: "request body too large",
+			req: func(rw http.ResponseWriter) *http.Request {
+				req := httptest.NewRequest(http.MethodPost, backend.URL, strings.NewReader("0123456789"))
+				req.ContentLength = -1
+				req.Body = http.MaxBytesReader(rw, req.Body, 5)
+				return req
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			desc: "deadline exceeded",
+			req: func(rw http.ResponseWriter) *http.Request {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				t.Cleanup(cancel)
+				return httptest.NewRequest(http.MethodGet, backend.URL, nil).WithContext(ctx)
+			},
+			expectedStatus: http.StatusGatewayTimeout,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, test.req(recorder))
+
+			assert.Equal(t, test.expectedStatus, recorder.Code)
+		})
+	}
+}
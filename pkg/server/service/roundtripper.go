@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
@@ -17,6 +18,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
 	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
+	"github.com/traefik/traefik/v3/pkg/middlewares/timeouts"
 	traefiktls "github.com/traefik/traefik/v3/pkg/tls"
 	"golang.org/x/net/http2"
 )
@@ -113,8 +115,8 @@ func (r *RoundTripperManager) Get(name string) (http.RoundTripper, error) {
 
 // createRoundTripper creates an http.RoundTripper configured with the Transport configuration settings.
 // For the settings that can't be configured in Traefik it uses the default http.Transport settings.
-// An exception to this is the MaxIdleConns setting as we only provide the option MaxIdleConnsPerHost in Traefik at this point in time.
-// Setting this value to the default of 100 could lead to confusing behavior and backwards compatibility issues.
+// An exception to this is the MaxIdleConns setting, which is not limited by default, unlike the default of 100 of http.Transport,
+// as it could lead to confusing behavior with MaxIdleConnsPerHost and backwards compatibility issues.
 func (r *RoundTripperManager) createRoundTripper(cfg *dynamic.ServersTransport) (http.RoundTripper, error) {
 	if cfg == nil {
 		return nil, errors.New("no transport configuration given")
@@ -131,8 +133,10 @@ func (r *RoundTripperManager) createRoundTripper(cfg *dynamic.ServersTransport)
 
 	transport := &http.Transport{
 		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           dialer.DialContext,
+		DialContext:           dialContext(dialer),
 		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
 		IdleConnTimeout:       90 * time.Second,
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
@@ -156,9 +160,11 @@ func (r *RoundTripperManager) createRoundTripper(cfg *dynamic.ServersTransport)
 		}
 
 		transport.TLSClientConfig = tlsconfig.MTLSClientConfig(r.spiffeX509Source, r.spiffeX509Source, spiffeAuthorizer)
+		// the server name is only sent as SNI, the server SVID being verified by the authorizer.
+		transport.TLSClientConfig.ServerName = cfg.ServerName
 	}
 
-	if cfg.InsecureSkipVerify || len(cfg.RootCAs) > 0 || len(cfg.ServerName) > 0 || len(cfg.Certificates) > 0 || cfg.PeerCertURI != "" {
+	if cfg.InsecureSkipVerify || len(cfg.RootCAs) > 0 || (len(cfg.ServerName) > 0 && cfg.Spiffe == nil) || len(cfg.Certificates) > 0 || cfg.PeerCertURI != "" {
 		if transport.TLSClientConfig != nil {
 			return nil, errors.New("TLS and SPIFFE configuration cannot be defined at the same time")
 		}
@@ -182,7 +188,21 @@ func (r *RoundTripperManager) createRoundTripper(cfg *dynamic.ServersTransport)
 		return transport, nil
 	}
 
-	return newSmartRoundTripper(transport, cfg.ForwardingTimeouts)
+	return newSmartRoundTripper(transport, cfg.ForwardingTimeouts, cfg.StrictMaxConcurrentStreams)
+}
+
+// dialContext returns a dial function applying the dial timeout of the router handling the request, if any.
+// The dialer timeout also applies, the shortest one taking effect.
+func dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if timeout, ok := timeouts.DialTimeout(ctx); ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		return dialer.DialContext(ctx, network, addr)
+	}
 }
 
 func createRootCACertPool(rootCAs []traefiktls.FileOrContent) *x509.CertPool {
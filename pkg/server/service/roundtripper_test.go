@@ -11,6 +11,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -240,6 +241,7 @@ func TestMTLS(t *testing.T) {
 
 func TestSpiffeMTLS(t *testing.T) {
 	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set("X-Server-Name", req.TLS.ServerName)
 		rw.WriteHeader(http.StatusOK)
 	}))
 
@@ -285,8 +287,10 @@ func TestSpiffeMTLS(t *testing.T) {
 	testCases := []struct {
 		desc           string
 		config         dynamic.Spiffe
+		serverName     string
 		clientSource   SpiffeX509Source
 		wantStatusCode int
+		wantServerName string
 		wantError      bool
 	}{
 		{
@@ -336,6 +340,16 @@ func TestSpiffeMTLS(t *testing.T) {
 			clientSource: &clientSource,
 			wantError:    true,
 		},
+		{
+			desc: "sends the server name",
+			config: dynamic.Spiffe{
+				TrustDomain: "spiffe://traefik.test",
+			},
+			serverName:     "backend.traefik.test",
+			clientSource:   &clientSource,
+			wantStatusCode: http.StatusOK,
+			wantServerName: "backend.traefik.test",
+		},
 		{
 			desc:         "raises an error when spiffe is enabled on the transport but no workloadapi address is given",
 			config:       dynamic.Spiffe{},
@@ -350,7 +364,8 @@ func TestSpiffeMTLS(t *testing.T) {
 
 			dynamicConf := map[string]*dynamic.ServersTransport{
 				"test": {
-					Spiffe: &test.config,
+					ServerName: test.serverName,
+					Spiffe:     &test.config,
 				},
 			}
 
@@ -369,6 +384,7 @@ func TestSpiffeMTLS(t *testing.T) {
 
 			require.NoError(t, err)
 			assert.Equal(t, test.wantStatusCode, resp.StatusCode)
+			assert.Equal(t, test.wantServerName, resp.Header.Get("X-Server-Name"))
 		})
 	}
 }
@@ -439,6 +455,62 @@ func TestDisableHTTP2(t *testing.T) {
 	}
 }
 
+func TestMaxConnsPerHost(t *testing.T) {
+	var conns, maxConns atomic.Int32
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		rw.WriteHeader(http.StatusOK)
+	}))
+	srv.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		switch state {
+		case http.StateNew:
+			n := conns.Add(1)
+			for {
+				m := maxConns.Load()
+				if n <= m || maxConns.CompareAndSwap(m, n) {
+					break
+				}
+			}
+		case http.StateClosed, http.StateHijacked:
+			conns.Add(-1)
+		}
+	}
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	rtManager := NewRoundTripperManager(nil)
+	rtManager.Update(map[string]*dynamic.ServersTransport{
+		"test": {
+			MaxConnsPerHost: 2,
+		},
+	})
+
+	tr, err := rtManager.Get("test")
+	require.NoError(t, err)
+
+	client := http.Client{Transport: tr}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			resp, err := client.Get(srv.URL)
+			if !assert.NoError(t, err) {
+				return
+			}
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxConns.Load(), int32(2))
+}
+
 // fakeSpiffePKI simulates a SPIFFE aware PKI and allows generating multiple valid SVIDs.
 type fakeSpiffePKI struct {
 	caPrivateKey *rsa.PrivateKey
@@ -116,7 +116,7 @@ func (m *Manager) BuildHTTP(rootCtx context.Context, serviceName string) (http.H
 		}
 	case conf.Mirroring != nil:
 		var err error
-		lb, err = m.getMirrorServiceHandler(ctx, conf.Mirroring)
+		lb, err = m.getMirrorServiceHandler(ctx, serviceName, conf.Mirroring)
 		if err != nil {
 			conf.AddError(err, true)
 			return nil, err
@@ -186,7 +186,7 @@ func (m *Manager) getFailoverServiceHandler(ctx context.Context, serviceName str
 	return f, nil
 }
 
-func (m *Manager) getMirrorServiceHandler(ctx context.Context, config *dynamic.Mirroring) (http.Handler, error) {
+func (m *Manager) getMirrorServiceHandler(ctx context.Context, serviceName string, config *dynamic.Mirroring) (http.Handler, error) {
 	serviceHandler, err := m.BuildHTTP(ctx, config.Service)
 	if err != nil {
 		return nil, err
@@ -197,13 +197,20 @@ func (m *Manager) getMirrorServiceHandler(ctx context.Context, config *dynamic.M
 		maxBodySize = *config.MaxBodySize
 	}
 	handler := mirror.New(serviceHandler, m.routinePool, maxBodySize, config.HealthCheck)
+
+	if info, ok := m.configs[serviceName]; ok {
+		handler.SetComparisonRecorder(func(mirrorName string, comparison mirror.Comparison) {
+			info.RecordMirrorComparison(mirrorName, comparison.Status, comparison.MirrorStatus, comparison.MirrorLatency-comparison.Latency)
+		})
+	}
+
 	for _, mirrorConfig := range config.Mirrors {
 		mirrorHandler, err := m.BuildHTTP(ctx, mirrorConfig.Name)
 		if err != nil {
 			return nil, err
 		}
 
-		err = handler.AddMirror(mirrorHandler, mirrorConfig.Percent)
+		err = handler.AddMirrorWithRule(mirrorConfig.Name, mirrorHandler, mirrorConfig.Percent, mirrorConfig.Rule)
 		if err != nil {
 			return nil, err
 		}
@@ -218,6 +225,12 @@ func (m *Manager) getWRRServiceHandler(ctx context.Context, serviceName string,
 	}
 
 	balancer := wrr.New(config.Sticky, config.HealthCheck != nil)
+	if config.Sticky != nil {
+		if err := balancer.SetStickyHash(config.Sticky.Hash); err != nil {
+			return nil, err
+		}
+	}
+
 	for _, service := range shuffle(config.Services, m.rand) {
 		serviceHandler, err := m.BuildHTTP(ctx, service.Name)
 		if err != nil {
@@ -281,6 +294,23 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 	}
 
 	lb := wrr.New(service.Sticky, service.HealthCheck != nil)
+	if err := lb.SetStrategy(service.Strategy); err != nil {
+		return nil, err
+	}
+	if service.Sticky != nil {
+		if err := lb.SetStickyHash(service.Sticky.Hash); err != nil {
+			return nil, err
+		}
+	}
+	if service.RetryOnConnectionRefused {
+		lb.EnableConnectionRefusedRetry()
+	}
+
+	var passiveHealthChecker *healthcheck.PassiveServiceHealthChecker
+	if service.PassiveHealthCheck != nil {
+		passiveHealthChecker = healthcheck.NewPassiveServiceHealthChecker(ctx, m.metricsRegistry, service.PassiveHealthCheck, lb, info)
+	}
+
 	healthCheckTargets := make(map[string]*url.URL)
 
 	for _, server := range shuffle(service.Servers, m.rand) {
@@ -297,7 +327,15 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 		logger.Debug().Str(logs.ServerName, proxyName).Stringer("target", target).
 			Msg("Creating server")
 
-		proxy := buildSingleHostProxy(target, passHostHeader, time.Duration(flushInterval), roundTripper, m.bufferPool)
+		var proxy http.Handler
+		if target.Scheme == dnsScheme {
+			proxy, err = buildDNSProxy(target)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			proxy = buildSingleHostProxy(target, passHostHeader, time.Duration(flushInterval), roundTripper, m.bufferPool)
+		}
 
 		proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceURL, target.String(), nil)
 		proxy = accesslog.NewFieldHandler(proxy, accesslog.ServiceAddr, target.Host, nil)
@@ -307,11 +345,21 @@ func (m *Manager) getLoadBalancerServiceHandler(ctx context.Context, serviceName
 			proxy = metricsMiddle.NewServiceMiddleware(ctx, proxy, m.metricsRegistry, serviceName)
 		}
 
-		lb.Add(proxyName, proxy, nil)
-
 		// servers are considered UP by default.
 		info.UpdateServerStatus(target.String(), runtime.StatusUp)
 
+		// a server with a zero weight is draining, it only serves the requests bound to it by a sticky cookie.
+		if server.Weight != nil && *server.Weight == 0 {
+			lb.AddDraining(proxyName, proxy)
+			continue
+		}
+
+		if passiveHealthChecker != nil {
+			proxy = passiveHealthChecker.WrapHandler(ctx, proxyName, target, proxy)
+		}
+
+		lb.Add(proxyName, proxy, server.Weight)
+
 		healthCheckTargets[proxyName] = target
 	}
 
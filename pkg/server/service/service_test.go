@@ -9,9 +9,11 @@ import (
 	"net/textproto"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	ptypes "github.com/traefik/paerser/types"
 	"github.com/traefik/traefik/v3/pkg/config/dynamic"
 	"github.com/traefik/traefik/v3/pkg/config/runtime"
 	"github.com/traefik/traefik/v3/pkg/server/provider"
@@ -359,7 +361,193 @@ func TestGetLoadBalancerServiceHandler(t *testing.T) {
 	}
 }
 
+func TestGetLoadBalancerServiceHandler_stickyAcrossRebuilds(t *testing.T) {
+	sm := NewManager(nil, nil, nil, &RoundTripperManager{
+		roundTrippers: map[string]http.RoundTripper{
+			"default@internal": http.DefaultTransport,
+		},
+	})
+
+	var servers []dynamic.Server
+	for _, name := range []string{"first", "second", "third"} {
+		name := name
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-From", name)
+		}))
+		t.Cleanup(server.Close)
+
+		servers = append(servers, dynamic.Server{URL: server.URL})
+	}
+
+	build := func(servers []dynamic.Server) http.Handler {
+		t.Helper()
+
+		serviceInfo := &runtime.ServiceInfo{Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+			Sticky:  &dynamic.Sticky{Cookie: &dynamic.Cookie{}},
+			Servers: servers,
+		}}}
+
+		handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", serviceInfo)
+		require.NoError(t, err)
+
+		return handler
+	}
+
+	recorder := httptest.NewRecorder()
+	build(servers).ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+	xFrom := recorder.Header().Get("X-From")
+	cookie := recorder.Header().Get("Set-Cookie")
+	require.NotEmpty(t, cookie)
+
+	// The server names are derived from the server URLs,
+	// so the cookie still designates the same server once the configuration has been rebuilt with servers in another order.
+	reversed := []dynamic.Server{servers[2], servers[1], servers[0]}
+	for i := 0; i < 5; i++ {
+		req := testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil)
+		req.Header.Set("Cookie", cookie)
+
+		recorder = httptest.NewRecorder()
+		build(reversed).ServeHTTP(recorder, req)
+
+		assert.Equal(t, xFrom, recorder.Header().Get("X-From"))
+	}
+}
+
+func TestGetLoadBalancerServiceHandler_retryOnConnectionRefused(t *testing.T) {
+	sm := NewManager(nil, nil, nil, &RoundTripperManager{
+		roundTrippers: map[string]http.RoundTripper{
+			"default@internal": http.DefaultTransport,
+		},
+	})
+
+	alive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		w.Header().Set("X-From", "alive")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(alive.Close)
+
+	// a closed server refuses the connections.
+	stopped := httptest.NewServer(http.NotFoundHandler())
+	stopped.Close()
+
+	serviceInfo := &runtime.ServiceInfo{Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Servers:                  []dynamic.Server{{URL: alive.URL}, {URL: stopped.URL}},
+		RetryOnConnectionRefused: true,
+	}}}
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", serviceInfo)
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodPut, "http://callme", strings.NewReader("payload")))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "alive", recorder.Header().Get("X-From"))
+		assert.Equal(t, "payload", recorder.Body.String())
+	}
+
+	// the other requests are not retried.
+	statusCodes := make(map[int]int)
+	for i := 0; i < 4; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodPost, "http://callme", nil))
+
+		statusCodes[recorder.Code]++
+	}
+
+	assert.Equal(t, map[int]int{http.StatusOK: 2, http.StatusBadGateway: 2}, statusCodes)
+}
+
+func TestGetLoadBalancerServiceHandler_passiveHealthCheck(t *testing.T) {
+	sm := NewManager(nil, nil, nil, &RoundTripperManager{
+		roundTrippers: map[string]http.RoundTripper{
+			"default@internal": http.DefaultTransport,
+		},
+	})
+
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "healthy")
+	}))
+	t.Cleanup(healthy.Close)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(failing.Close)
+
+	serviceInfo := &runtime.ServiceInfo{Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Servers: []dynamic.Server{{URL: healthy.URL}, {URL: failing.URL}},
+		PassiveHealthCheck: &dynamic.PassiveServerHealthCheck{
+			MaxFailedAttempts:  2,
+			FailureWindow:      ptypes.Duration(time.Minute),
+			Cooldown:           ptypes.Duration(time.Minute),
+			MaxEjectionPercent: 50,
+		},
+	}}}
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", serviceInfo)
+	require.NoError(t, err)
+
+	// the failing server is ejected after its second failure.
+	statusCodes := make(map[int]int)
+	for i := 0; i < 4; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+		statusCodes[recorder.Code]++
+	}
+
+	assert.Equal(t, map[int]int{http.StatusOK: 2, http.StatusInternalServerError: 2}, statusCodes)
+
+	for i := 0; i < 4; i++ {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "healthy", recorder.Header().Get("X-From"))
+	}
+
+	assert.Equal(t, map[string]string{healthy.URL: runtime.StatusUp, failing.URL: runtime.StatusDown}, serviceInfo.GetAllStatus())
+}
+
 // This test is an adapted version of net/http/httputil.Test1xxResponses test.
+func TestGetLoadBalancerServiceHandler_strategy(t *testing.T) {
+	sm := NewManager(nil, nil, nil, &RoundTripperManager{
+		roundTrippers: map[string]http.RoundTripper{
+			"default@internal": http.DefaultTransport,
+		},
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-From", "server")
+	}))
+	t.Cleanup(server.Close)
+
+	serviceInfo := &runtime.ServiceInfo{Service: &dynamic.Service{LoadBalancer: &dynamic.ServersLoadBalancer{
+		Strategy: dynamic.BalancerStrategyLeastConn,
+		Servers:  []dynamic.Server{{URL: server.URL}},
+	}}}
+
+	handler, err := sm.getLoadBalancerServiceHandler(context.Background(), "test", serviceInfo)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, testhelpers.MustNewRequest(http.MethodGet, "http://callme", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "server", recorder.Header().Get("X-From"))
+
+	serviceInfo.LoadBalancer.Strategy = "foo"
+
+	_, err = sm.getLoadBalancerServiceHandler(context.Background(), "test", serviceInfo)
+	assert.EqualError(t, err, "unknown load-balancing strategy: foo")
+}
+
 func Test1xxResponses(t *testing.T) {
 	sm := NewManager(nil, nil, nil, &RoundTripperManager{
 		roundTrippers: map[string]http.RoundTripper{
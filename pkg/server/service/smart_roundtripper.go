@@ -11,7 +11,7 @@ import (
 	"golang.org/x/net/http2"
 )
 
-func newSmartRoundTripper(transport *http.Transport, forwardingTimeouts *dynamic.ForwardingTimeouts) (http.RoundTripper, error) {
+func newSmartRoundTripper(transport *http.Transport, forwardingTimeouts *dynamic.ForwardingTimeouts, strictMaxConcurrentStreams bool) (http.RoundTripper, error) {
 	transportHTTP1 := transport.Clone()
 
 	transportHTTP2, err := http2.ConfigureTransports(transport)
@@ -19,6 +19,8 @@ func newSmartRoundTripper(transport *http.Transport, forwardingTimeouts *dynamic
 		return nil, err
 	}
 
+	transportHTTP2.StrictMaxConcurrentStreams = strictMaxConcurrentStreams
+
 	if forwardingTimeouts != nil {
 		transportHTTP2.ReadIdleTimeout = time.Duration(forwardingTimeouts.ReadIdleTimeout)
 		transportHTTP2.PingTimeout = time.Duration(forwardingTimeouts.PingTimeout)
@@ -29,7 +31,8 @@ func newSmartRoundTripper(transport *http.Transport, forwardingTimeouts *dynamic
 			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
 				return net.Dial(network, addr)
 			},
-			AllowHTTP: true,
+			AllowHTTP:                  true,
+			StrictMaxConcurrentStreams: strictMaxConcurrentStreams,
 		},
 	}
 
@@ -61,3 +64,8 @@ func (m *smartRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 
 	return m.http2.RoundTrip(req)
 }
+
+// GetTLSClientConfig returns the TLS configuration of the underlying transports.
+func (m *smartRoundTripper) GetTLSClientConfig() *tls.Config {
+	return m.http2.TLSClientConfig
+}
@@ -48,6 +48,32 @@ func (m *Manager) BuildUDP(rootCtx context.Context, serviceName string) (udp.Han
 	}
 
 	switch {
+	case conf.LoadBalancer != nil && conf.LoadBalancer.SourceHash:
+		loadBalancer := udp.NewHashLoadBalancer()
+
+		// The servers are not shuffled, as the server of a client IP does not depend on their order.
+		for index, server := range conf.LoadBalancer.Servers {
+			srvLogger := logger.With().
+				Int(logs.ServerIndex, index).
+				Str("serverAddress", server.Address).Logger()
+
+			if _, _, err := net.SplitHostPort(server.Address); err != nil {
+				srvLogger.Error().Err(err).Msg("Failed to split host port")
+				continue
+			}
+
+			handler, err := udp.NewProxy(server.Address)
+			if err != nil {
+				srvLogger.Error().Err(err).Msg("Failed to create server")
+				continue
+			}
+
+			loadBalancer.AddServer(server.Address, handler)
+			srvLogger.Debug().Msg("Creating UDP server")
+		}
+
+		return loadBalancer, nil
+
 	case conf.LoadBalancer != nil:
 		loadBalancer := udp.NewWRRLoadBalancer()
 
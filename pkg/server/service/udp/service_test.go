@@ -135,6 +135,28 @@ func TestManager_BuildUDP(t *testing.T) {
 			},
 			providerName: "provider-1",
 		},
+		{
+			desc:        "Servers with source hash",
+			serviceName: "serviceName",
+			configs: map[string]*runtime.UDPServiceInfo{
+				"serviceName@provider-1": {
+					UDPService: &dynamic.UDPService{
+						LoadBalancer: &dynamic.UDPServersLoadBalancer{
+							Servers: []dynamic.UDPServer{
+								{
+									Address: "192.168.0.12:80",
+								},
+								{
+									Address: "192.168.0.13:80",
+								},
+							},
+							SourceHash: true,
+						},
+					},
+				},
+			},
+			providerName: "provider-1",
+		},
 		{
 			desc:        "missing port in address with hostname, server is skipped, error is logged",
 			serviceName: "serviceName",
@@ -123,9 +123,11 @@ func (d *DialerManager) createDialers(name string, cfg *dynamic.TCPServersTransp
 			}
 
 			tlsConfig = tlsconfig.MTLSClientConfig(d.spiffeX509Source, d.spiffeX509Source, authorizer)
+			// the server name is only sent as SNI, the server SVID being verified by the authorizer.
+			tlsConfig.ServerName = cfg.TLS.ServerName
 		}
 
-		if cfg.TLS.InsecureSkipVerify || len(cfg.TLS.RootCAs) > 0 || len(cfg.TLS.ServerName) > 0 || len(cfg.TLS.Certificates) > 0 || cfg.TLS.PeerCertURI != "" {
+		if cfg.TLS.InsecureSkipVerify || len(cfg.TLS.RootCAs) > 0 || (len(cfg.TLS.ServerName) > 0 && cfg.TLS.Spiffe == nil) || len(cfg.TLS.Certificates) > 0 || cfg.TLS.PeerCertURI != "" {
 			if tlsConfig != nil {
 				return errors.New("TLS and SPIFFE configuration cannot be defined at the same time")
 			}
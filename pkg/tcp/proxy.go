@@ -53,8 +53,14 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	defer connBackend.Close()
 	errChan := make(chan error)
 
+	// the PROXY protocol of the service takes precedence over the one of the router middlewares.
+	version := proxyProtocolVersion(conn)
 	if p.proxyProtocol != nil && p.proxyProtocol.Version > 0 && p.proxyProtocol.Version < 3 {
-		header := proxyproto.HeaderProxyFromAddrs(byte(p.proxyProtocol.Version), conn.RemoteAddr(), conn.LocalAddr())
+		version = p.proxyProtocol.Version
+	}
+
+	if version > 0 {
+		header := proxyproto.HeaderProxyFromAddrs(byte(version), conn.RemoteAddr(), conn.LocalAddr())
 		if _, err := header.WriteTo(connBackend); err != nil {
 			log.Error().Err(err).Msg("Error while writing TCP proxy protocol headers to backend connection")
 			return
@@ -79,6 +85,38 @@ func (p *Proxy) ServeTCP(conn WriteCloser) {
 	<-errChan
 }
 
+// proxyProtocolConn is a connection for which the PROXY protocol header is sent to the backend.
+type proxyProtocolConn struct {
+	WriteCloser
+	version int
+}
+
+// Unwrap returns the wrapped connection.
+func (c *proxyProtocolConn) Unwrap() WriteCloser {
+	return c.WriteCloser
+}
+
+// WithProxyProtocol marks the connection for the PROXY protocol header of the given version to be sent to the backend,
+// unless the service sends its own.
+func WithProxyProtocol(conn WriteCloser, version int) WriteCloser {
+	return &proxyProtocolConn{WriteCloser: conn, version: version}
+}
+
+// proxyProtocolVersion returns the version of the PROXY protocol header the connection is marked for, or zero.
+// The connections wrapped by the middlewares give access to the connection they wrap with an Unwrap method.
+func proxyProtocolVersion(conn WriteCloser) int {
+	for {
+		switch c := conn.(type) {
+		case *proxyProtocolConn:
+			return c.version
+		case interface{ Unwrap() WriteCloser }:
+			conn = c.Unwrap()
+		default:
+			return 0
+		}
+	}
+}
+
 func (p Proxy) dialBackend() (WriteCloser, error) {
 	conn, err := p.dialer.Dial("tcp", p.address)
 	if err != nil {
@@ -90,16 +90,31 @@ func TestCloseWrite(t *testing.T) {
 
 func TestProxyProtocol(t *testing.T) {
 	testCases := []struct {
-		desc    string
-		version int
+		desc          string
+		version       int
+		markedVersion int
+		expected      int
 	}{
 		{
-			desc:    "PROXY protocol v1",
-			version: 1,
+			desc:     "PROXY protocol v1",
+			version:  1,
+			expected: 1,
 		},
 		{
-			desc:    "PROXY protocol v2",
-			version: 2,
+			desc:     "PROXY protocol v2",
+			version:  2,
+			expected: 2,
+		},
+		{
+			desc:          "PROXY protocol v2 marked by a middleware",
+			markedVersion: 2,
+			expected:      2,
+		},
+		{
+			desc:          "PROXY protocol of the service takes precedence",
+			version:       1,
+			markedVersion: 2,
+			expected:      1,
 		},
 	}
 
@@ -118,7 +133,7 @@ func TestProxyProtocol(t *testing.T) {
 					return nil
 				},
 				Policy: func(upstream net.Addr) (proxyproto.Policy, error) {
-					switch test.version {
+					switch test.expected {
 					case 1, 2:
 						return proxyproto.USE, nil
 					default:
@@ -135,7 +150,12 @@ func TestProxyProtocol(t *testing.T) {
 
 			dialer := tcpDialer{&net.Dialer{}, 10 * time.Millisecond}
 
-			proxy, err := NewProxy(":"+port, &dynamic.ProxyProtocol{Version: test.version}, dialer)
+			var proxyProtocol *dynamic.ProxyProtocol
+			if test.version > 0 {
+				proxyProtocol = &dynamic.ProxyProtocol{Version: test.version}
+			}
+
+			proxy, err := NewProxy(":"+port, proxyProtocol, dialer)
 			require.NoError(t, err)
 
 			proxyListener, err := net.Listen("tcp", ":0")
@@ -145,6 +165,11 @@ func TestProxyProtocol(t *testing.T) {
 				for {
 					conn, err := proxyListener.Accept()
 					require.NoError(t, err)
+
+					if test.markedVersion > 0 {
+						proxy.ServeTCP(WithProxyProtocol(conn.(*net.TCPConn), test.markedVersion))
+						continue
+					}
 					proxy.ServeTCP(conn.(*net.TCPConn))
 				}
 			}()
@@ -169,7 +194,7 @@ func TestProxyProtocol(t *testing.T) {
 			assert.Equal(t, int64(4), n)
 			assert.Equal(t, "PONG", buffer.String())
 
-			assert.Equal(t, test.version, version)
+			assert.Equal(t, test.expected, version)
 		})
 	}
 }
@@ -0,0 +1,157 @@
+package tls
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// oidExtensionSCTList is the OID of the X.509v3 extension holding the embedded Signed Certificate Timestamps (RFC 6962, section 3.3).
+var oidExtensionSCTList = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 11129, 2, 4, 2}
+
+// sctVersionV1 is the only SCT version defined by RFC 6962.
+const sctVersionV1 = 0
+
+// SignedCertificateTimestamp is a Signed Certificate Timestamp embedded in a certificate.
+type SignedCertificateTimestamp struct {
+	LogID     [32]byte
+	Timestamp time.Time
+}
+
+// CheckSCTs checks that the certificate embeds at least one valid Signed Certificate Timestamp.
+// An SCT is valid when it is well-formed, has a known version,
+// and has a timestamp which is neither in the future nor after the certificate expiration.
+// The SCT signatures are not verified, as it would require the public keys of the trusted CT logs.
+func CheckSCTs(cert *x509.Certificate, now time.Time) error {
+	scts, err := ParseSCTs(cert)
+	if err != nil {
+		return err
+	}
+
+	if len(scts) == 0 {
+		return errors.New("no embedded SCT")
+	}
+
+	for _, sct := range scts {
+		if sct.Timestamp.After(now) || sct.Timestamp.After(cert.NotAfter) {
+			continue
+		}
+
+		return nil
+	}
+
+	return errors.New("no embedded SCT with a valid timestamp")
+}
+
+// ParseSCTs returns the Signed Certificate Timestamps embedded in the certificate.
+func ParseSCTs(cert *x509.Certificate) ([]SignedCertificateTimestamp, error) {
+	for _, ext := range cert.Extensions {
+		if !ext.Id.Equal(oidExtensionSCTList) {
+			continue
+		}
+
+		var list []byte
+		rest, err := asn1.Unmarshal(ext.Value, &list)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SCT list extension: %w", err)
+		}
+		if len(rest) > 0 {
+			return nil, errors.New("invalid SCT list extension: trailing data")
+		}
+
+		scts, err := parseSCTList(list)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SCT list: %w", err)
+		}
+
+		return scts, nil
+	}
+
+	return nil, nil
+}
+
+// parseSCTList parses a TLS encoded SignedCertificateTimestampList (RFC 6962, section 3.3).
+func parseSCTList(data []byte) ([]SignedCertificateTimestamp, error) {
+	list, rest, err := readOpaque16(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errors.New("trailing data")
+	}
+
+	var scts []SignedCertificateTimestamp
+	for len(list) > 0 {
+		var raw []byte
+		raw, list, err = readOpaque16(list)
+		if err != nil {
+			return nil, err
+		}
+
+		sct, err := parseSCT(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		scts = append(scts, sct)
+	}
+
+	return scts, nil
+}
+
+// parseSCT parses a TLS encoded SignedCertificateTimestamp (RFC 6962, section 3.2).
+func parseSCT(data []byte) (SignedCertificateTimestamp, error) {
+	var sct SignedCertificateTimestamp
+
+	// version (1) + log ID (32) + timestamp (8).
+	if len(data) < 41 {
+		return sct, errors.New("truncated SCT")
+	}
+	if data[0] != sctVersionV1 {
+		return sct, fmt.Errorf("unsupported SCT version %d", data[0])
+	}
+
+	copy(sct.LogID[:], data[1:33])
+	sct.Timestamp = time.UnixMilli(int64(binary.BigEndian.Uint64(data[33:41])))
+
+	// extensions.
+	_, rest, err := readOpaque16(data[41:])
+	if err != nil {
+		return sct, err
+	}
+
+	// hash algorithm (1) + signature algorithm (1) + signature.
+	if len(rest) < 2 {
+		return sct, errors.New("truncated SCT signature")
+	}
+
+	signature, rest, err := readOpaque16(rest[2:])
+	if err != nil {
+		return sct, err
+	}
+	if len(signature) == 0 {
+		return sct, errors.New("empty SCT signature")
+	}
+	if len(rest) > 0 {
+		return sct, errors.New("trailing data after SCT")
+	}
+
+	return sct, nil
+}
+
+// readOpaque16 reads a TLS opaque vector prefixed by its 2-byte length.
+func readOpaque16(data []byte) ([]byte, []byte, error) {
+	if len(data) < 2 {
+		return nil, nil, errors.New("truncated length")
+	}
+
+	length := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+length {
+		return nil, nil, errors.New("truncated data")
+	}
+
+	return data[2 : 2+length], data[2+length:], nil
+}
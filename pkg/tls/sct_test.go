@@ -0,0 +1,161 @@
+package tls
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSCTs(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := now.Add(90 * 24 * time.Hour)
+
+	testCases := []struct {
+		desc        string
+		extension   []byte
+		expectedErr string
+	}{
+		{
+			desc:        "no SCT extension",
+			expectedErr: "no embedded SCT",
+		},
+		{
+			desc:      "valid SCT",
+			extension: sctListExtension(t, encodeSCT(sctVersionV1, now.Add(-time.Hour), []byte{1})),
+		},
+		{
+			desc: "one valid SCT among others",
+			extension: sctListExtension(t,
+				encodeSCT(sctVersionV1, now.Add(time.Hour), []byte{1}),
+				encodeSCT(sctVersionV1, now.Add(-time.Hour), []byte{1}),
+			),
+		},
+		{
+			desc:        "empty SCT list",
+			extension:   sctListExtension(t),
+			expectedErr: "no embedded SCT",
+		},
+		{
+			desc:        "SCT in the future",
+			extension:   sctListExtension(t, encodeSCT(sctVersionV1, now.Add(time.Hour), []byte{1})),
+			expectedErr: "no embedded SCT with a valid timestamp",
+		},
+		{
+			desc:        "unsupported SCT version",
+			extension:   sctListExtension(t, encodeSCT(1, now.Add(-time.Hour), []byte{1})),
+			expectedErr: "invalid SCT list: unsupported SCT version 1",
+		},
+		{
+			desc:        "SCT without signature",
+			extension:   sctListExtension(t, encodeSCT(sctVersionV1, now.Add(-time.Hour), nil)),
+			expectedErr: "invalid SCT list: empty SCT signature",
+		},
+		{
+			desc:        "truncated SCT",
+			extension:   sctListExtension(t, encodeSCT(sctVersionV1, now.Add(-time.Hour), []byte{1})[:20]),
+			expectedErr: "invalid SCT list: truncated SCT",
+		},
+		{
+			desc:        "not an octet string",
+			extension:   []byte{0x01, 0x02},
+			expectedErr: "invalid SCT list extension",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			cert := generateCertificate(t, notAfter, test.extension)
+
+			err := CheckSCTs(cert, now)
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestParseSCTs(t *testing.T) {
+	timestamp := time.UnixMilli(time.Now().UnixMilli())
+	cert := generateCertificate(t, time.Now().Add(time.Hour), sctListExtension(t, encodeSCT(sctVersionV1, timestamp, []byte{1, 2, 3})))
+
+	scts, err := ParseSCTs(cert)
+	require.NoError(t, err)
+
+	require.Len(t, scts, 1)
+	assert.Equal(t, [32]byte{0: 0xff, 31: 0xff}, scts[0].LogID)
+	assert.True(t, timestamp.Equal(scts[0].Timestamp))
+}
+
+// encodeSCT encodes an SCT with an empty extensions field and an ECDSA/SHA-256 signature.
+func encodeSCT(version byte, timestamp time.Time, signature []byte) []byte {
+	sct := []byte{version}
+
+	logID := [32]byte{0: 0xff, 31: 0xff}
+	sct = append(sct, logID[:]...)
+	sct = binary.BigEndian.AppendUint64(sct, uint64(timestamp.UnixMilli()))
+
+	// extensions.
+	sct = binary.BigEndian.AppendUint16(sct, 0)
+
+	// hash and signature algorithms.
+	sct = append(sct, 4, 3)
+	sct = binary.BigEndian.AppendUint16(sct, uint16(len(signature)))
+
+	return append(sct, signature...)
+}
+
+func sctListExtension(t *testing.T, scts ...[]byte) []byte {
+	t.Helper()
+
+	var list []byte
+	for _, sct := range scts {
+		list = binary.BigEndian.AppendUint16(list, uint16(len(sct)))
+		list = append(list, sct...)
+	}
+
+	value, err := asn1.Marshal(append(binary.BigEndian.AppendUint16(nil, uint16(len(list))), list...))
+	require.NoError(t, err)
+
+	return value
+}
+
+func generateCertificate(t *testing.T, notAfter time.Time, sctExtension []byte) *x509.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "foo.bar"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	if sctExtension != nil {
+		template.ExtraExtensions = []pkix.Extension{{Id: oidExtensionSCTList, Value: sctExtension}}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
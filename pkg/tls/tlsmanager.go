@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
 	"strings"
 	"sync"
 
@@ -183,6 +184,13 @@ func (m *Manager) Get(storeName, configName string) (*tls.Config, error) {
 		domainToCheck := types.CanonicalDomain(clientHello.ServerName)
 
 		if isACMETLS(clientHello) {
+			// the server name of the challenges of the IP identifiers is the reverse DNS name of the IP address (RFC 8738).
+			if ip := reverseNameIP(clientHello.ServerName); ip != nil {
+				challengeHello := *clientHello
+				challengeHello.ServerName = ip.String()
+				clientHello = &challengeHello
+			}
+
 			certificate := acmeTLSStore.GetBestCertificate(clientHello)
 			if certificate == nil {
 				log.Debug().Msgf("TLS: no certificate for TLSALPN challenge: %s", domainToCheck)
@@ -430,3 +438,46 @@ func isACMETLS(clientHello *tls.ClientHelloInfo) bool {
 
 	return false
 }
+
+// reverseNameIP returns the IP address of the reverse DNS name,
+// under in-addr.arpa for IPv4 and ip6.arpa for IPv6, nil if the name is not a reverse DNS name.
+func reverseNameIP(name string) net.IP {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+
+	switch {
+	case strings.HasSuffix(name, ".in-addr.arpa"):
+		labels := strings.Split(strings.TrimSuffix(name, ".in-addr.arpa"), ".")
+		if len(labels) != net.IPv4len {
+			return nil
+		}
+
+		for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+			labels[i], labels[j] = labels[j], labels[i]
+		}
+
+		return net.ParseIP(strings.Join(labels, ".")).To4()
+
+	case strings.HasSuffix(name, ".ip6.arpa"):
+		nibbles := strings.Split(strings.TrimSuffix(name, ".ip6.arpa"), ".")
+		if len(nibbles) != 2*net.IPv6len {
+			return nil
+		}
+
+		var address strings.Builder
+		for i := len(nibbles) - 1; i >= 0; i-- {
+			if len(nibbles[i]) != 1 {
+				return nil
+			}
+
+			address.WriteString(nibbles[i])
+			if i > 0 && i%4 == 0 {
+				address.WriteByte(':')
+			}
+		}
+
+		return net.ParseIP(address.String())
+
+	default:
+		return nil
+	}
+}
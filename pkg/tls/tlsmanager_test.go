@@ -7,6 +7,7 @@ import (
 	"encoding/pem"
 	"testing"
 
+	"github.com/go-acme/lego/v4/challenge/tlsalpn01"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -178,6 +179,116 @@ func TestManager_Get(t *testing.T) {
 	}
 }
 
+func TestManager_Get_ACMETLSIPIdentifier(t *testing.T) {
+	dynamicConfigs := []*CertAndStores{{
+		Certificate: Certificate{
+			CertFile: localhostCert,
+			KeyFile:  localhostKey,
+		},
+		Stores: []string{tlsalpn01.ACMETLS1Protocol},
+	}}
+
+	testCases := []struct {
+		desc         string
+		serverName   string
+		expectedCert bool
+	}{
+		{
+			desc:         "IPv4 reverse name",
+			serverName:   "1.0.0.127.in-addr.arpa",
+			expectedCert: true,
+		},
+		{
+			desc:         "IPv6 reverse name",
+			serverName:   "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.ip6.arpa",
+			expectedCert: true,
+		},
+		{
+			desc:         "reverse name of another IP",
+			serverName:   "2.0.0.127.in-addr.arpa",
+			expectedCert: false,
+		},
+		{
+			desc:         "domain name",
+			serverName:   "example.com",
+			expectedCert: true,
+		},
+	}
+
+	tlsManager := NewManager()
+	tlsManager.UpdateConfigs(context.Background(), nil, map[string]Options{"default": DefaultTLSOptions}, dynamicConfigs)
+
+	config, err := tlsManager.Get("default", "default")
+	require.NoError(t, err)
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			certificate, err := config.GetCertificate(&tls.ClientHelloInfo{
+				ServerName:      test.serverName,
+				SupportedProtos: []string{tlsalpn01.ACMETLS1Protocol},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedCert, certificate != nil)
+		})
+	}
+}
+
+func Test_reverseNameIP(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		name       string
+		expectedIP string
+	}{
+		{
+			desc:       "IPv4",
+			name:       "4.3.2.10.in-addr.arpa",
+			expectedIP: "10.2.3.4",
+		},
+		{
+			desc:       "IPv4 fully qualified",
+			name:       "4.3.2.10.IN-ADDR.ARPA.",
+			expectedIP: "10.2.3.4",
+		},
+		{
+			desc:       "IPv6",
+			name:       "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+			expectedIP: "2001:db8::1",
+		},
+		{
+			desc: "IPv4 network",
+			name: "2.10.in-addr.arpa",
+		},
+		{
+			desc: "IPv6 invalid nibble",
+			name: "10.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+		},
+		{
+			desc: "domain name",
+			name: "example.com",
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			t.Parallel()
+
+			ip := reverseNameIP(test.name)
+			if test.expectedIP == "" {
+				assert.Nil(t, ip)
+				return
+			}
+
+			require.NotNil(t, ip)
+			assert.Equal(t, test.expectedIP, ip.String())
+		})
+	}
+}
+
 func TestClientAuth(t *testing.T) {
 	tlsConfigs := map[string]Options{
 		"eca": {
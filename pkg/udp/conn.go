@@ -2,10 +2,13 @@ package udp
 
 import (
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"sync"
 	"time"
+
+	"github.com/traefik/traefik/v3/pkg/upgrade"
 )
 
 // maxDatagramSize is the maximum size of a UDP datagram.
@@ -38,11 +41,22 @@ func Listen(network string, laddr *net.UDPAddr, timeout time.Duration) (*Listene
 		return nil, errors.New("timeout should be greater than zero")
 	}
 
-	conn, err := net.ListenUDP(network, laddr)
+	var address string
+	if laddr != nil {
+		address = laddr.String()
+	}
+
+	packetConn, err := upgrade.ListenPacket(network, address)
 	if err != nil {
 		return nil, err
 	}
 
+	conn, ok := packetConn.(*net.UDPConn)
+	if !ok {
+		_ = packetConn.Close()
+		return nil, fmt.Errorf("unexpected %T listener", packetConn)
+	}
+
 	l := &Listener{
 		pConn:     conn,
 		acceptCh:  make(chan *Conn),
@@ -186,6 +200,7 @@ func (l *Listener) newConn(rAddr net.Addr) *Conn {
 		sizeCh:    make(chan int),
 		doneCh:    make(chan struct{}),
 		timeout:   l.timeout,
+		ticker:    time.NewTicker(l.timeout / 10),
 	}
 }
 
@@ -202,7 +217,8 @@ type Conn struct {
 	muActivity   sync.RWMutex
 	lastActivity time.Time // the last time the session saw either read or write activity
 
-	timeout  time.Duration // for timeouts
+	timeout  time.Duration // for timeouts, guarded by muActivity
+	ticker   *time.Ticker  // to check the timeout
 	doneOnce sync.Once
 	doneCh   chan struct{}
 }
@@ -212,15 +228,14 @@ type Conn struct {
 // that is to say it waits on readCh to receive the slice of bytes that the Read operation wants to read onto.
 // The Read operation receives the signal that the data has been written to the slice of bytes through the sizeCh.
 func (c *Conn) readLoop() {
-	ticker := time.NewTicker(c.timeout / 10)
-	defer ticker.Stop()
+	defer c.ticker.Stop()
 
 	for {
 		if len(c.msgs) == 0 {
 			select {
 			case msg := <-c.receiveCh:
 				c.msgs = append(c.msgs, msg)
-			case <-ticker.C:
+			case <-c.ticker.C:
 				c.muActivity.RLock()
 				deadline := c.lastActivity.Add(c.timeout)
 				c.muActivity.RUnlock()
@@ -240,7 +255,7 @@ func (c *Conn) readLoop() {
 			c.sizeCh <- n
 		case msg := <-c.receiveCh:
 			c.msgs = append(c.msgs, msg)
-		case <-ticker.C:
+		case <-c.ticker.C:
 			c.muActivity.RLock()
 			deadline := c.lastActivity.Add(c.timeout)
 			c.muActivity.RUnlock()
@@ -252,6 +267,16 @@ func (c *Conn) readLoop() {
 	}
 }
 
+// SetTimeout sets how long to wait on the session when idle, before releasing its related resources.
+// It overrides the timeout of the listener.
+func (c *Conn) SetTimeout(timeout time.Duration) {
+	c.muActivity.Lock()
+	c.timeout = timeout
+	c.muActivity.Unlock()
+
+	c.ticker.Reset(timeout / 10)
+}
+
 // Read reads up to len(p) bytes into p from the connection.
 // Each call corresponds to at most one datagram.
 // If p is smaller than the datagram, the extra bytes will be discarded.
@@ -0,0 +1,86 @@
+package udp
+
+import (
+	"errors"
+	"hash/fnv"
+	"net"
+
+	"github.com/rs/zerolog/log"
+)
+
+type hashServer struct {
+	Handler
+	key string
+}
+
+// HashLoadBalancer is a load balancer for UDP services,
+// forwarding all the sessions of a client IP to the same server.
+// The server is chosen by rendezvous hashing of the client IP with the server keys,
+// so that it does not depend on the order of the servers,
+// and that only the clients of a removed server are moved to the other servers.
+type HashLoadBalancer struct {
+	servers []hashServer
+}
+
+// NewHashLoadBalancer creates a new HashLoadBalancer.
+func NewHashLoadBalancer() *HashLoadBalancer {
+	return &HashLoadBalancer{}
+}
+
+// AddServer appends a handler, identified by the given key, e.g. its address, to the existing list.
+// Not thread safe.
+func (b *HashLoadBalancer) AddServer(key string, serverHandler Handler) {
+	b.servers = append(b.servers, hashServer{Handler: serverHandler, key: key})
+}
+
+// ServeUDP forwards the connection to the server of the client IP.
+func (b *HashLoadBalancer) ServeUDP(conn *Conn) {
+	next, err := b.next(clientIP(conn.rAddr))
+	if err != nil {
+		log.Error().Err(err).Msg("Error during load balancing")
+		conn.Close()
+		return
+	}
+
+	next.ServeUDP(conn)
+}
+
+func (b *HashLoadBalancer) next(ip string) (Handler, error) {
+	if len(b.servers) == 0 {
+		return nil, errors.New("no servers in the pool")
+	}
+
+	var (
+		next     Handler
+		maxScore uint64
+	)
+	for i, srv := range b.servers {
+		score := rendezvousScore(srv.key, ip)
+		if i == 0 || score > maxScore {
+			next, maxScore = srv.Handler, score
+		}
+	}
+
+	return next, nil
+}
+
+func rendezvousScore(key, ip string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(ip))
+	return h.Sum64()
+}
+
+// clientIP returns the IP of the address, without the port.
+func clientIP(addr net.Addr) string {
+	if udpAddr, ok := addr.(*net.UDPAddr); ok {
+		return udpAddr.IP.String()
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,70 @@
+package udp
+
+import (
+	"net"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type namedHandler string
+
+func (h namedHandler) ServeUDP(*Conn) {}
+
+func TestHashLoadBalancer_next(t *testing.T) {
+	balancer := NewHashLoadBalancer()
+	for _, name := range []string{"a", "b", "c"} {
+		balancer.AddServer(name, namedHandler(name))
+	}
+
+	reversed := NewHashLoadBalancer()
+	for _, name := range []string{"c", "b", "a"} {
+		reversed.AddServer(name, namedHandler(name))
+	}
+
+	withoutC := NewHashLoadBalancer()
+	for _, name := range []string{"a", "b"} {
+		withoutC.AddServer(name, namedHandler(name))
+	}
+
+	counts := map[Handler]int{}
+	for i := 0; i < 300; i++ {
+		ip := "10.0." + strconv.Itoa(i/256) + "." + strconv.Itoa(i%256)
+
+		handler, err := balancer.next(ip)
+		require.NoError(t, err)
+		counts[handler]++
+
+		// The server of a client IP is stable.
+		again, err := balancer.next(ip)
+		require.NoError(t, err)
+		assert.Equal(t, handler, again)
+
+		// The server of a client IP does not depend on the order of the servers.
+		other, err := reversed.next(ip)
+		require.NoError(t, err)
+		assert.Equal(t, handler, other)
+
+		// Only the clients of a removed server are moved.
+		other, err = withoutC.next(ip)
+		require.NoError(t, err)
+		if handler != namedHandler("c") {
+			assert.Equal(t, handler, other)
+		}
+	}
+
+	// All the servers get clients.
+	assert.Len(t, counts, 3)
+}
+
+func TestHashLoadBalancer_noServers(t *testing.T) {
+	_, err := NewHashLoadBalancer().next("10.0.0.1")
+	assert.Error(t, err)
+}
+
+func TestClientIP(t *testing.T) {
+	assert.Equal(t, "10.0.0.1", clientIP(&net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 53}))
+	assert.Equal(t, "::1", clientIP(&net.UDPAddr{IP: net.ParseIP("::1"), Port: 53}))
+}
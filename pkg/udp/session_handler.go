@@ -0,0 +1,49 @@
+package udp
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SessionHandler applies the session timeout and the maximum number of sessions of a router to its sessions.
+type SessionHandler struct {
+	next Handler
+
+	// timeout overrides the timeout of the entry point, if greater than zero.
+	timeout time.Duration
+	// maxSessions is the maximum number of concurrent sessions, unlimited if zero.
+	maxSessions int64
+	sessions    atomic.Int64
+}
+
+// NewSessionHandler creates a new SessionHandler.
+func NewSessionHandler(next Handler, timeout time.Duration, maxSessions int) *SessionHandler {
+	return &SessionHandler{
+		next:        next,
+		timeout:     timeout,
+		maxSessions: int64(maxSessions),
+	}
+}
+
+// ServeUDP implements the Handler interface.
+func (h *SessionHandler) ServeUDP(conn *Conn) {
+	if h.maxSessions > 0 {
+		if h.sessions.Add(1) > h.maxSessions {
+			h.sessions.Add(-1)
+
+			log.Debug().Msgf("Dropping UDP session from %s, the maximum number of sessions (%d) is reached", conn.rAddr, h.maxSessions)
+			conn.Close()
+			return
+		}
+
+		defer h.sessions.Add(-1)
+	}
+
+	if h.timeout > 0 {
+		conn.SetTimeout(h.timeout)
+	}
+
+	h.next.ServeUDP(conn)
+}
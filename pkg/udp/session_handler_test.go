@@ -0,0 +1,106 @@
+package udp
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionHandler_timeout(t *testing.T) {
+	ln := newSessionListener(t, NewSessionHandler(echoHandler(), 200*time.Millisecond, 0))
+
+	for i := 0; i < 3; i++ {
+		udpConn, err := net.Dial("udp", ln.Addr().String())
+		require.NoError(t, err)
+
+		requireEcho(t, "TEST", udpConn, time.Second)
+	}
+
+	assert.Equal(t, 3, countConns(ln))
+
+	// The sessions are released long before the timeout of the listener.
+	assert.Eventually(t, func() bool { return countConns(ln) == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestSessionHandler_maxSessions(t *testing.T) {
+	ln := newSessionListener(t, NewSessionHandler(echoHandler(), 300*time.Millisecond, 1))
+
+	udpConn, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+
+	requireEcho(t, "TEST", udpConn, time.Second)
+
+	udpConn2, err := net.Dial("udp", ln.Addr().String())
+	require.NoError(t, err)
+
+	_, err = udpConn2.Write([]byte("TEST"))
+	require.NoError(t, err)
+
+	err = udpConn2.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = udpConn2.Read(make([]byte, 4))
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+
+	// Once the first session timed out, the second client gets a session.
+	assert.Eventually(t, func() bool { return countConns(ln) == 0 }, time.Second, 10*time.Millisecond)
+
+	err = udpConn2.SetReadDeadline(time.Time{})
+	require.NoError(t, err)
+
+	requireEcho(t, "TEST2", udpConn2, time.Second)
+}
+
+func newSessionListener(t *testing.T, handler Handler) *Listener {
+	t.Helper()
+
+	addr, err := net.ResolveUDPAddr("udp", ":0")
+	require.NoError(t, err)
+
+	ln, err := Listen("udp", addr, 3*time.Second)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if errors.Is(err, errClosedListener) {
+				return
+			}
+			require.NoError(t, err)
+
+			go handler.ServeUDP(conn)
+		}
+	}()
+
+	return ln
+}
+
+func echoHandler() Handler {
+	return HandlerFunc(func(conn *Conn) {
+		b := make([]byte, 2048)
+		for {
+			n, err := conn.Read(b)
+			if err != nil {
+				return
+			}
+
+			if _, err = conn.Write(b[:n]); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func countConns(ln *Listener) int {
+	ln.mu.RLock()
+	defer ln.mu.RUnlock()
+
+	return len(ln.conns)
+}
@@ -0,0 +1,391 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// envUpgrade is set in the environment of the processes started by an upgrade.
+const envUpgrade = "TRAEFIK_UPGRADE"
+
+// The files inherited by the processes started by an upgrade, after the standard ones.
+const (
+	snapshotFD = 3 + iota
+	readyFD
+	supervisorFD
+	firstListenerFD
+)
+
+// Snapshot is the state handed off to the process started by an upgrade.
+type Snapshot struct {
+	// Listeners are the handed off listeners, in the order of their inherited files.
+	Listeners []Listener `json:"listeners,omitempty"`
+	// Providers are the providers whose configuration was applied,
+	// the new process being ready once it applied the configuration of the same providers.
+	Providers []string `json:"providers,omitempty"`
+	// States are the states registered by the components, such as the ACME stores, by name.
+	States map[string]json.RawMessage `json:"states,omitempty"`
+}
+
+// Listener is a listener handed off to the new process, identified by its network and its address as configured.
+type Listener struct {
+	Network string `json:"network"`
+	Address string `json:"address"`
+}
+
+// filer is a listener whose file descriptor can be handed off.
+type filer interface {
+	File() (*os.File, error)
+}
+
+var defaultHandoff = newHandoff()
+
+// handoff tracks the listeners and the states of the process, to hand them off on upgrade.
+type handoff struct {
+	mu sync.Mutex
+
+	listeners map[Listener]filer
+	states    map[string]func() ([]byte, error)
+
+	// snapshot is the state inherited from the previous process, nil when the process is not started by an upgrade.
+	snapshot *Snapshot
+	// inherited are the inherited listeners not listened on yet.
+	inherited map[Listener]*os.File
+	// ready reports to the previous process that the process is ready, nil once reported.
+	ready *os.File
+
+	// supervisor is the pipe announcing the serving processes to the supervisor, the process started first.
+	supervisor *os.File
+	// announcements is the end of the pipe read by the supervisor, nil in the other processes.
+	announcements *os.File
+
+	upgrading bool
+	handedOff bool
+}
+
+func newHandoff() *handoff {
+	return &handoff{
+		listeners: make(map[Listener]filer),
+		states:    make(map[string]func() ([]byte, error)),
+	}
+}
+
+// Listen announces on the local network address like net.Listen,
+// the listener being inherited from the previous process when it listened on the same network and address.
+func Listen(network, address string) (net.Listener, error) {
+	return defaultHandoff.listen(network, address)
+}
+
+// ListenPacket announces on the local network address like net.ListenPacket,
+// the connection being inherited from the previous process when it listened on the same network and address.
+func ListenPacket(network, address string) (net.PacketConn, error) {
+	return defaultHandoff.listenPacket(network, address)
+}
+
+// RegisterState registers a state to hand off on upgrade, the snapshot returning nil when there is nothing to hand off.
+func RegisterState(name string, snapshot func() ([]byte, error)) {
+	defaultHandoff.mu.Lock()
+	defer defaultHandoff.mu.Unlock()
+
+	defaultHandoff.states[name] = snapshot
+}
+
+// State returns the state handed off by the previous process under the given name.
+func State(name string) ([]byte, bool) {
+	defaultHandoff.mu.Lock()
+	defer defaultHandoff.mu.Unlock()
+
+	if defaultHandoff.snapshot == nil {
+		return nil, false
+	}
+
+	state, ok := defaultHandoff.snapshot.States[name]
+	return state, ok
+}
+
+// Inherited reports whether the process is started by an upgrade.
+func Inherited() bool {
+	defaultHandoff.mu.Lock()
+	defer defaultHandoff.mu.Unlock()
+
+	return defaultHandoff.snapshot != nil
+}
+
+// Providers returns the providers whose configuration was applied by the previous process.
+func Providers() []string {
+	defaultHandoff.mu.Lock()
+	defer defaultHandoff.mu.Unlock()
+
+	if defaultHandoff.snapshot == nil {
+		return nil
+	}
+
+	return defaultHandoff.snapshot.Providers
+}
+
+// Inherit loads the state handed off by the previous process, when the process is started by an upgrade.
+// It must be called before opening any listener.
+func Inherit() error {
+	if os.Getenv(envUpgrade) == "" {
+		return nil
+	}
+
+	// the processes started by Traefik do not inherit anything.
+	_ = os.Unsetenv(envUpgrade)
+
+	snapshotFile := os.NewFile(snapshotFD, "snapshot")
+	defer func() { _ = snapshotFile.Close() }()
+
+	var snapshot Snapshot
+	if err := json.NewDecoder(snapshotFile).Decode(&snapshot); err != nil {
+		return fmt.Errorf("reading the handed off state: %w", err)
+	}
+
+	files := make([]*os.File, len(snapshot.Listeners))
+	for i, listener := range snapshot.Listeners {
+		files[i] = os.NewFile(uintptr(firstListenerFD+i), listener.Network+":"+listener.Address)
+	}
+
+	defaultHandoff.inherit(snapshot, files, os.NewFile(readyFD, "ready"), os.NewFile(supervisorFD, "supervisor"))
+
+	return nil
+}
+
+// Ready reports to the previous process that the process serves the inherited listeners, for it to stop.
+// The inherited listeners not listened on are closed.
+func Ready() error {
+	return defaultHandoff.setReady()
+}
+
+// Upgrade starts a new process, from the current executable, and hands the listeners and the states off to it.
+// It returns once the new process reported to be ready, the calling process having to stop gracefully,
+// and stops the new process if it is not ready within the timeout.
+func Upgrade(providers []string, readyTimeout time.Duration) error {
+	return defaultHandoff.upgrade(providers, readyTimeout)
+}
+
+// Supervise waits, in the process started first, for the processes serving the listeners it handed off,
+// so that a process supervisor, such as Nomad, keeps tracking Traefik through the upgrades.
+// The signals are forwarded to the serving process, and Supervise returns once all the started processes exited.
+// It returns immediately when the process did not hand its listeners off, or is not the process started first.
+func Supervise() error {
+	return defaultHandoff.supervise()
+}
+
+func (h *handoff) listen(network, address string) (net.Listener, error) {
+	key := Listener{Network: network, Address: address}
+
+	var listener net.Listener
+	if file := h.claim(key); file != nil {
+		var err error
+		listener, err = net.FileListener(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inheriting listener: %w", err)
+		}
+
+		log.Debug().Msgf("Inherited %s listener on %s", network, address)
+	} else {
+		var err error
+		listener, err = net.Listen(network, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	h.register(key, listener)
+
+	return listener, nil
+}
+
+func (h *handoff) listenPacket(network, address string) (net.PacketConn, error) {
+	key := Listener{Network: network, Address: address}
+
+	var conn net.PacketConn
+	if file := h.claim(key); file != nil {
+		var err error
+		conn, err = net.FilePacketConn(file)
+		_ = file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inheriting listener: %w", err)
+		}
+
+		log.Debug().Msgf("Inherited %s listener on %s", network, address)
+	} else {
+		var err error
+		conn, err = net.ListenPacket(network, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	h.register(key, conn)
+
+	return conn, nil
+}
+
+func (h *handoff) claim(key Listener) *os.File {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	file, ok := h.inherited[key]
+	if !ok {
+		return nil
+	}
+
+	delete(h.inherited, key)
+
+	return file
+}
+
+func (h *handoff) register(key Listener, listener any) {
+	f, ok := listener.(filer)
+	if !ok {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// a listener closed and opened again on the same address replaces the previous one.
+	h.listeners[key] = f
+}
+
+func (h *handoff) inherit(snapshot Snapshot, files []*os.File, ready, supervisor *os.File) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.snapshot = &snapshot
+	h.inherited = make(map[Listener]*os.File, len(files))
+	for i, file := range files {
+		h.inherited[snapshot.Listeners[i]] = file
+	}
+	h.ready = ready
+	h.supervisor = supervisor
+}
+
+func (h *handoff) setReady() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.ready == nil {
+		return nil
+	}
+
+	for key, file := range h.inherited {
+		log.Debug().Msgf("Closing inherited %s listener on %s, not listened on anymore", key.Network, key.Address)
+		_ = file.Close()
+	}
+	h.inherited = nil
+
+	// the supervisor learns about the process before the previous one exits.
+	if h.supervisor != nil {
+		if _, err := h.supervisor.WriteString(strconv.Itoa(os.Getpid()) + "\n"); err != nil {
+			log.Error().Err(err).Msg("Unable to announce the process to the supervisor")
+		}
+	}
+
+	_, err := h.ready.Write([]byte{1})
+	_ = h.ready.Close()
+	h.ready = nil
+
+	if err != nil {
+		return fmt.Errorf("reporting readiness to the previous process: %w", err)
+	}
+
+	return nil
+}
+
+// handOff returns the snapshot of the state and the files of the listeners to hand off.
+// The closed listeners are not handed off.
+func (h *handoff) handOff(providers []string) (Snapshot, []*os.File, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	snapshot := Snapshot{Providers: providers}
+
+	keys := make([]Listener, 0, len(h.listeners))
+	for key := range h.listeners {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Network != keys[j].Network {
+			return keys[i].Network < keys[j].Network
+		}
+		return keys[i].Address < keys[j].Address
+	})
+
+	var files []*os.File
+	for _, key := range keys {
+		file, err := h.listeners[key].File()
+		if err != nil {
+			// the listener is closed.
+			delete(h.listeners, key)
+			continue
+		}
+
+		snapshot.Listeners = append(snapshot.Listeners, key)
+		files = append(files, file)
+	}
+
+	for name, state := range h.states {
+		data, err := state()
+		if err != nil {
+			closeFiles(files)
+			return Snapshot{}, nil, fmt.Errorf("snapshot of %s: %w", name, err)
+		}
+
+		if data == nil {
+			continue
+		}
+
+		if snapshot.States == nil {
+			snapshot.States = make(map[string]json.RawMessage)
+		}
+		snapshot.States[name] = data
+	}
+
+	return snapshot, files, nil
+}
+
+// startUpgrade reports whether an upgrade can be started, no other one being in progress.
+func (h *handoff) startUpgrade() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch {
+	case h.upgrading:
+		return errors.New("an upgrade is already in progress")
+	case h.handedOff:
+		return errors.New("the listeners are already handed off")
+	case h.ready != nil:
+		return errors.New("the process is not ready yet")
+	}
+
+	h.upgrading = true
+
+	return nil
+}
+
+func (h *handoff) endUpgrade(handedOff bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.upgrading = false
+	h.handedOff = handedOff
+}
+
+func closeFiles(files []*os.File) {
+	for _, file := range files {
+		_ = file.Close()
+	}
+}
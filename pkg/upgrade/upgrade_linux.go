@@ -0,0 +1,211 @@
+//go:build linux
+// +build linux
+
+package upgrade
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sys/unix"
+)
+
+func (h *handoff) upgrade(providers []string, readyTimeout time.Duration) error {
+	if err := h.startUpgrade(); err != nil {
+		return err
+	}
+
+	err := h.startProcess(providers, readyTimeout)
+	h.endUpgrade(err == nil)
+
+	return err
+}
+
+func (h *handoff) startProcess(providers []string, readyTimeout time.Duration) error {
+	supervisor, err := h.supervisorPipe()
+	if err != nil {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("finding the executable: %w", err)
+	}
+
+	snapshot, files, err := h.handOff(providers)
+	if err != nil {
+		return err
+	}
+	defer closeFiles(files)
+
+	snapshotReader, snapshotWriter, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = snapshotWriter.Close() }()
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		_ = snapshotReader.Close()
+		return err
+	}
+	defer func() { _ = readyReader.Close() }()
+
+	process, err := os.StartProcess(executable, os.Args, &os.ProcAttr{
+		Env:   append(os.Environ(), envUpgrade+"=1"),
+		Files: append([]*os.File{os.Stdin, os.Stdout, os.Stderr, snapshotReader, readyWriter, supervisor}, files...),
+	})
+
+	// the ends of the pipes inherited by the new process are closed, so that its exit is noticed.
+	_ = snapshotReader.Close()
+	_ = readyWriter.Close()
+
+	if err != nil {
+		return fmt.Errorf("starting the new process: %w", err)
+	}
+
+	log.Info().Msgf("Started process %d, handing %d listener(s) off", process.Pid, len(files))
+
+	err = json.NewEncoder(snapshotWriter).Encode(snapshot)
+	_ = snapshotWriter.Close()
+	if err == nil {
+		err = waitReady(readyReader, readyTimeout)
+	}
+
+	if err != nil {
+		_ = process.Signal(syscall.SIGTERM)
+		go func() { _, _ = process.Wait() }()
+		return fmt.Errorf("new process %d: %w", process.Pid, err)
+	}
+
+	return nil
+}
+
+func waitReady(ready *os.File, readyTimeout time.Duration) error {
+	if err := ready.SetReadDeadline(time.Now().Add(readyTimeout)); err != nil {
+		return err
+	}
+
+	_, err := ready.Read(make([]byte, 1))
+	switch {
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return fmt.Errorf("not ready after %s", readyTimeout)
+	case err != nil:
+		return fmt.Errorf("exited before being ready: %w", err)
+	}
+
+	return nil
+}
+
+// supervisorPipe returns the pipe announcing the serving processes to the supervisor.
+// The process started first becomes the supervisor on its first upgrade,
+// as the subreaper of the processes started afterward, which are reparented to it once their parent exits.
+func (h *handoff) supervisorPipe() (*os.File, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.supervisor != nil {
+		return h.supervisor, nil
+	}
+
+	if err := unix.Prctl(unix.PR_SET_CHILD_SUBREAPER, 1, 0, 0, 0); err != nil {
+		return nil, fmt.Errorf("becoming the subreaper of the new processes: %w", err)
+	}
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	h.supervisor = writer
+	h.announcements = reader
+
+	return writer, nil
+}
+
+func (h *handoff) supervise() error {
+	h.mu.Lock()
+	announcements := h.announcements
+	handedOff := h.handedOff
+	h.mu.Unlock()
+
+	if announcements == nil || !handedOff {
+		return nil
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2, syscall.SIGCHLD)
+	defer signal.Stop(signals)
+
+	var current atomic.Int64
+	go func() {
+		scanner := bufio.NewScanner(announcements)
+		for scanner.Scan() {
+			pid, err := strconv.Atoi(scanner.Text())
+			if err != nil {
+				continue
+			}
+
+			log.Info().Msgf("Supervising process %d", pid)
+			current.Store(int64(pid))
+		}
+	}()
+
+	log.Info().Msg("Waiting for the upgraded processes to exit")
+
+	var status syscall.WaitStatus
+	for {
+		// a process is reparented to the supervisor before its parent is reaped.
+		for {
+			var ws syscall.WaitStatus
+			pid, err := syscall.Wait4(-1, &ws, syscall.WNOHANG, nil)
+			if errors.Is(err, syscall.EINTR) {
+				continue
+			}
+			if errors.Is(err, syscall.ECHILD) {
+				return exitError(status)
+			}
+			if err != nil {
+				return fmt.Errorf("waiting for the upgraded processes: %w", err)
+			}
+			if pid <= 0 {
+				break
+			}
+
+			if int64(pid) == current.Load() {
+				status = ws
+			}
+		}
+
+		sig := <-signals
+		if sig == syscall.SIGCHLD {
+			continue
+		}
+
+		if pid := current.Load(); pid > 0 {
+			if err := syscall.Kill(int(pid), sig.(syscall.Signal)); err != nil {
+				log.Error().Err(err).Msgf("Unable to forward %s to process %d", sig, pid)
+			}
+		}
+	}
+}
+
+func exitError(status syscall.WaitStatus) error {
+	switch {
+	case status.Signaled():
+		return fmt.Errorf("upgraded process killed by %s", status.Signal())
+	case status.ExitStatus() != 0:
+		return fmt.Errorf("upgraded process exited with status %d", status.ExitStatus())
+	default:
+		return nil
+	}
+}
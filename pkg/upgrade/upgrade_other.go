@@ -0,0 +1,17 @@
+//go:build !linux
+// +build !linux
+
+package upgrade
+
+import (
+	"errors"
+	"time"
+)
+
+func (h *handoff) upgrade(_ []string, _ time.Duration) error {
+	return errors.New("upgrades are only supported on Linux")
+}
+
+func (h *handoff) supervise() error {
+	return nil
+}
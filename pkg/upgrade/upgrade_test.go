@@ -0,0 +1,140 @@
+package upgrade
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandoff_listeners(t *testing.T) {
+	previous := newHandoff()
+
+	tcpListener, err := previous.listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tcpListener.Close() })
+
+	udpConn, err := previous.listenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = udpConn.Close() })
+
+	closedListener, err := previous.listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	require.NoError(t, closedListener.Close())
+
+	snapshot, files, err := previous.handOff([]string{"file", "internal"})
+	require.NoError(t, err)
+
+	// the closed listener is not handed off.
+	assert.Equal(t, []Listener{
+		{Network: "tcp", Address: "127.0.0.1:0"},
+		{Network: "udp", Address: "127.0.0.1:0"},
+	}, snapshot.Listeners)
+	assert.Equal(t, []string{"file", "internal"}, snapshot.Providers)
+	require.Len(t, files, 2)
+
+	ready, readyWriter := pipe(t)
+	announcements, supervisor := pipe(t)
+
+	current := newHandoff()
+	current.inherit(snapshot, files, readyWriter, supervisor)
+
+	inheritedListener, err := current.listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = inheritedListener.Close() })
+	assert.IsType(t, &net.TCPListener{}, inheritedListener)
+
+	// the connections to the handed off listener are accepted by the inherited one.
+	conn, err := net.Dial("tcp", tcpListener.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	accepted, err := inheritedListener.Accept()
+	require.NoError(t, err)
+	_ = accepted.Close()
+
+	require.NoError(t, current.setReady())
+
+	// the UDP listener is not listened on, and thus closed.
+	assert.Empty(t, current.inherited)
+	assert.Error(t, files[1].Close())
+
+	readiness, err := io.ReadAll(ready)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, readiness)
+
+	require.NoError(t, supervisor.Close())
+	announcement, err := io.ReadAll(announcements)
+	require.NoError(t, err)
+	assert.Regexp(t, `^\d+\n$`, string(announcement))
+
+	// the readiness is only reported once.
+	assert.NoError(t, current.setReady())
+}
+
+func TestHandoff_listenNotInherited(t *testing.T) {
+	h := newHandoff()
+	h.inherit(Snapshot{}, nil, nil, nil)
+
+	listener, err := h.listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	assert.Contains(t, h.listeners, Listener{Network: "tcp", Address: "127.0.0.1:0"})
+}
+
+func TestHandoff_states(t *testing.T) {
+	h := newHandoff()
+	h.states["acme:acme.json"] = func() ([]byte, error) { return []byte(`{"le":{}}`), nil }
+	h.states["acme:empty.json"] = func() ([]byte, error) { return nil, nil }
+
+	snapshot, files, err := h.handOff(nil)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	// the snapshot goes through JSON, as when handed off.
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+
+	var inherited Snapshot
+	require.NoError(t, json.Unmarshal(data, &inherited))
+
+	assert.Equal(t, map[string]json.RawMessage{"acme:acme.json": json.RawMessage(`{"le":{}}`)}, inherited.States)
+}
+
+func TestHandoff_startUpgrade(t *testing.T) {
+	h := newHandoff()
+
+	require.NoError(t, h.startUpgrade())
+	assert.Error(t, h.startUpgrade())
+
+	h.endUpgrade(false)
+	require.NoError(t, h.startUpgrade())
+
+	h.endUpgrade(true)
+	assert.Error(t, h.startUpgrade())
+
+	_, readyWriter := pipe(t)
+
+	notReady := newHandoff()
+	notReady.inherit(Snapshot{}, nil, readyWriter, nil)
+	assert.Error(t, notReady.startUpgrade())
+}
+
+func pipe(t *testing.T) (*os.File, *os.File) {
+	t.Helper()
+
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = reader.Close()
+		_ = writer.Close()
+	})
+
+	return reader, writer
+}
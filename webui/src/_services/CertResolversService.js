@@ -0,0 +1,15 @@
+import { APP } from '../_helpers/APP'
+
+const apiBase = '/certresolvers'
+
+function getAll () {
+  return APP.api.get(`${apiBase}`)
+    .then(body => {
+      console.log('Success -> CertResolversService -> getAll', body.data)
+      return body.data
+    })
+}
+
+export default {
+  getAll
+}
@@ -57,11 +57,20 @@ function getMiddlewareByName (name) {
     })
 }
 
+function previewRouting (request) {
+  return APP.api.post(`${apiBase}/preview`, request)
+    .then(body => {
+      console.log('Success -> HttpService -> previewRouting', body.data)
+      return body.data
+    })
+}
+
 export default {
   getAllRouters,
   getRouterByName,
   getAllServices,
   getServiceByName,
   getAllMiddlewares,
-  getMiddlewareByName
+  getMiddlewareByName,
+  previewRouting
 }
@@ -0,0 +1,15 @@
+import { APP } from '../_helpers/APP'
+
+const apiBase = '/nomad'
+
+function getServices (params) {
+  return APP.api.get(`${apiBase}/services?search=${params.query}`)
+    .then(body => {
+      console.log('Success -> NomadService -> getServices', body.data)
+      return body.data
+    })
+}
+
+export default {
+  getServices
+}
@@ -97,6 +97,19 @@ const routes = [
           protocol: 'http',
           title: 'HTTP Middleware Detail'
         }
+      },
+      {
+        path: 'preview',
+        name: 'httpPreview',
+        components: {
+          default: () => import('pages/http/Preview.vue'),
+          NavBar: () => import('components/_commons/ToolBar.vue')
+        },
+        props: { default: true, NavBar: true },
+        meta: {
+          protocol: 'http',
+          title: 'HTTP Routing Preview'
+        }
       }
     ]
   },
@@ -243,6 +256,35 @@ const routes = [
         }
       }
     ]
+  },
+  {
+    path: '/nomad',
+    redirect: '/nomad/services',
+    component: LayoutDefault,
+    children: [
+      {
+        path: 'services',
+        name: 'nomadServices',
+        component: () => import('pages/nomad/Services.vue'),
+        meta: {
+          title: 'Nomad Services'
+        }
+      }
+    ]
+  },
+  {
+    path: '/certresolvers',
+    component: LayoutDefault,
+    children: [
+      {
+        path: '',
+        name: 'certResolvers',
+        component: () => import('pages/certresolvers/Index.vue'),
+        meta: {
+          title: 'Certificate Resolvers'
+        }
+      }
+    ]
   }
 ]
 
@@ -0,0 +1,6 @@
+import CertResolversService from '../../_services/CertResolversService'
+
+// the certificate resolvers are not kept in the store, as they only concern the page listing them.
+export function getAll () {
+  return CertResolversService.getAll()
+}
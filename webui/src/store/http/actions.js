@@ -77,3 +77,8 @@ export function getMiddlewareByName ({ commit }, name) {
       return Promise.reject(error)
     })
 }
+
+// the preview is not kept in the store, as it only concerns the page requesting it.
+export function previewRouting (_, request) {
+  return HttpService.previewRouting(request)
+}
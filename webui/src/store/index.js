@@ -6,6 +6,8 @@ import entrypoints from './entrypoints'
 import http from './http'
 import tcp from './tcp'
 import udp from './udp'
+import nomad from './nomad'
+import certresolvers from './certresolvers'
 import platform from './platform'
 
 Vue.use(Vuex)
@@ -23,6 +25,8 @@ export default function (/* { ssrContext } */) {
       http,
       tcp,
       udp,
+      nomad,
+      certresolvers,
       platform
     },
 
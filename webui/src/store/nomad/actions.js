@@ -0,0 +1,6 @@
+import NomadService from '../../_services/NomadService'
+
+// the discovered services are not kept in the store, as they only concern the page listing them.
+export function getServices (_, params) {
+  return NomadService.getServices(params)
+}
@@ -0,0 +1,7 @@
+import * as actions from './actions'
+
+export default {
+  namespaced: true,
+  actions,
+  state: {}
+}